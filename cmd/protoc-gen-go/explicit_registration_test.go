@@ -0,0 +1,43 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"go/ast"
+	"testing"
+
+	gengo "google.golang.org/protobuf/cmd/protoc-gen-go/internal_gengo"
+)
+
+func TestGenerateExplicitRegistration(t *testing.T) {
+	defer func(v bool) { gengo.GenerateExplicitRegistration = v }(gengo.GenerateExplicitRegistration)
+	const fileDesc = `
+		name: "reg.proto"
+		package: "reg"
+		syntax: "proto3"
+		options: {go_package: "example.com/reg"}
+		message_type: {name: "M"}
+	`
+	for _, explicit := range []bool{false, true} {
+		gengo.GenerateExplicitRegistration = explicit
+		f, err := generate(t, fileDesc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var register *ast.FuncDecl
+		for _, decl := range f.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == "Register_reg_proto" {
+				register = fn
+			}
+		}
+		if got := register != nil; got != explicit {
+			t.Errorf("explicit_registration=%v: has Register_reg_proto = %v, want %v", explicit, got, explicit)
+			continue
+		}
+		if register != nil && register.Type.Params.NumFields() != 2 {
+			t.Errorf("Register_reg_proto has %d parameters, want 2", register.Type.Params.NumFields())
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	gengo "google.golang.org/protobuf/cmd/protoc-gen-go/internal_gengo"
+)
+
+func TestGenerateFieldNumbers(t *testing.T) {
+	defer func(v bool) { gengo.GenerateFieldNumbers = v }(gengo.GenerateFieldNumbers)
+	gengo.GenerateFieldNumbers = true
+	f, err := generate(t, `
+		name: "numbers.proto"
+		package: "numbers"
+		syntax: "proto3"
+		options: {go_package: "example.com/numbers"}
+		message_type: {
+			name: "M"
+			field: {name: "a" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "a"}
+			field: {name: "b" number: 20 label: LABEL_OPTIONAL type: TYPE_INT32 json_name: "b" oneof_index: 0}
+			nested_type: {
+				name: "N"
+				field: {name: "c" number: 3 label: LABEL_REPEATED type: TYPE_INT64 json_name: "c"}
+			}
+			oneof_decl: {name: "choice"}
+		}
+		message_type: {name: "Empty"}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]string)
+	for _, decl := range f.Decls {
+		decl, ok := decl.(*ast.GenDecl)
+		if !ok || decl.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range decl.Specs {
+			spec := spec.(*ast.ValueSpec)
+			if name := spec.Names[0].Name; strings.HasSuffix(name, "FieldNumber") {
+				got[name] = spec.Values[0].(*ast.BasicLit).Value
+			}
+		}
+	}
+	want := map[string]string{
+		"M_AFieldNumber":   "1",
+		"M_BFieldNumber":   "20",
+		"M_N_CFieldNumber": "3",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("generated field number constants mismatch (-want +got):\n%s", diff)
+	}
+}
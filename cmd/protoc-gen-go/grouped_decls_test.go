@@ -0,0 +1,83 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+	"testing"
+
+	gengo "google.golang.org/protobuf/cmd/protoc-gen-go/internal_gengo"
+)
+
+func TestGenerateGroupedDecls(t *testing.T) {
+	defer func(v bool) { gengo.GenerateGroupedDecls = v }(gengo.GenerateGroupedDecls)
+
+	const fileDesc = `
+		name: "grouped.proto"
+		package: "grouped"
+		syntax: "proto2"
+		options: {go_package: "example.com/grouped"}
+		message_type: {
+			name: "A"
+			nested_type: {name: "Nested"}
+			enum_type: {name: "Kind" value: {name: "KIND_ZERO" number: 0}}
+		}
+		message_type: {
+			name: "B"
+			extension_range: {start: 100 end: 200}
+			extension: {name: "x" number: 100 label: LABEL_OPTIONAL type: TYPE_INT32 extendee: ".grouped.B" json_name: "x"}
+		}
+		enum_type: {name: "Color" value: {name: "COLOR_ZERO" number: 0}}
+		source_code_info: {
+			location: {path: [4, 0] span: [0, 0, 5, 1]}
+			location: {path: [4, 0, 3, 0] span: [1, 2, 20]}
+			location: {path: [4, 0, 4, 0] span: [2, 2, 4, 3]}
+			location: {path: [5, 0] span: [6, 0, 8, 1]}
+			location: {path: [4, 1] span: [9, 0, 12, 1]}
+		}
+	`
+	for _, tt := range []struct {
+		grouped bool
+		want    string
+	}{{
+		grouped: false,
+		want:    "Color A_Kind A B A_Nested E_B_X",
+	}, {
+		grouped: true,
+		want:    "A A_Nested A_Kind Color B E_B_X",
+	}} {
+		gengo.GenerateGroupedDecls = tt.grouped
+		f, err := generate(t, fileDesc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					if gd.Tok == token.TYPE {
+						got = append(got, spec.Name.Name)
+					}
+				case *ast.ValueSpec:
+					for _, name := range spec.Names {
+						if gd.Tok == token.VAR && strings.HasPrefix(name.Name, "E_") {
+							got = append(got, name.Name)
+						}
+					}
+				}
+			}
+		}
+		if got := strings.Join(got, " "); got != tt.want {
+			t.Errorf("grouped = %v: declarations = %q, want %q", tt.grouped, got, tt.want)
+		}
+	}
+}
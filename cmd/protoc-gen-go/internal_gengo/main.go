@@ -11,6 +11,7 @@ import (
 	"go/parser"
 	"go/token"
 	"math"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode"
@@ -22,11 +23,13 @@ import (
 	"google.golang.org/protobuf/internal/filedesc"
 	"google.golang.org/protobuf/internal/genid"
 	"google.golang.org/protobuf/internal/version"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/runtime/protoimpl"
 
 	"google.golang.org/protobuf/types/descriptorpb"
 	"google.golang.org/protobuf/types/gofeaturespb"
+	"google.golang.org/protobuf/types/gooptionspb"
 	"google.golang.org/protobuf/types/pluginpb"
 )
 
@@ -39,6 +42,59 @@ var SupportedEditionsMaximum = editionssupport.Maximum
 // GenerateVersionMarkers specifies whether to generate version markers.
 var GenerateVersionMarkers = true
 
+// GenerateHasMethods specifies whether to generate Has methods for fields
+// with explicit presence in messages using the Open API.
+// Messages using the Hybrid and Opaque APIs always have Has methods.
+var GenerateHasMethods = false
+
+// GenerateSetMethods specifies whether to generate Set methods for fields
+// in messages using the Open API.
+// Messages using the Hybrid and Opaque APIs always have Set methods.
+var GenerateSetMethods = false
+
+// GenerateBuilders specifies whether to generate a builder type
+// for messages using the Open API.
+// Messages using the Hybrid and Opaque APIs always have builders.
+var GenerateBuilders = false
+
+// GenerateOneofVisitors specifies whether to generate a visitor interface
+// and a Visit method for each oneof.
+var GenerateOneofVisitors = false
+
+// GenerateFieldNumbers specifies whether to generate a constant
+// for the number of each field in a message.
+var GenerateFieldNumbers = false
+
+// GenerateLazyInit specifies whether to build the descriptors and types of
+// a file on first use instead of in an init function. This reduces the
+// startup time of programs that link many generated files, but the types
+// of a file are not registered in protoregistry.GlobalFiles and
+// protoregistry.GlobalTypes, and its File_ variable is nil, until one of
+// its messages or enums is first used. Files that declare extensions are
+// always initialized eagerly.
+var GenerateLazyInit = false
+
+// GenerateExplicitRegistration specifies whether to generate an exported
+// Register_<file> function for each file that registers its descriptor and
+// types in registries chosen by the caller. The descriptors and types of
+// such files are never registered in protoregistry.GlobalFiles and
+// protoregistry.GlobalTypes by init functions.
+var GenerateExplicitRegistration = false
+
+// GenerateServiceDescriptors specifies whether to generate functions
+// that return the descriptor of every service and method in a file,
+// named <Service>_ServiceDescriptor and <Service>_<Method>_MethodDescriptor.
+// These permit transports other than gRPC to route requests by reflection.
+var GenerateServiceDescriptors = false
+
+// GenerateGroupedDecls specifies whether to emit the enums, messages,
+// and extensions of a file grouped by top-level declaration.
+// Top-level declarations are emitted in the order in which they appear
+// in the .proto file, each followed by its nested declarations.
+// By default, all enums are emitted before all messages, which are
+// emitted before all extensions.
+var GenerateGroupedDecls = false
+
 // Standard library dependencies.
 const (
 	base64Package  = protogen.GoImportPath("encoding/base64")
@@ -46,6 +102,7 @@ const (
 	mathPackage    = protogen.GoImportPath("math")
 	reflectPackage = protogen.GoImportPath("reflect")
 	sortPackage    = protogen.GoImportPath("sort")
+	strconvPackage = protogen.GoImportPath("strconv")
 	stringsPackage = protogen.GoImportPath("strings")
 	syncPackage    = protogen.GoImportPath("sync")
 	timePackage    = protogen.GoImportPath("time")
@@ -89,6 +146,7 @@ func GenerateFile(gen *protogen.Plugin, file *protogen.File) *protogen.Generated
 }
 
 func generateFiles(gen *protogen.Plugin, file *protogen.File) []*protogen.GeneratedFile {
+	checkCustomTags(gen, file.Messages)
 	f := newFileInfo(file)
 	generated := []*protogen.GeneratedFile{
 		generateOneFile(gen, file, f, ""),
@@ -138,13 +196,22 @@ func generateOneFile(gen *protogen.Plugin, file *protogen.File, f *fileInfo, var
 	for i, imps := 0, f.Desc.Imports(); i < imps.Len(); i++ {
 		genImport(gen, g, f, imps.Get(i))
 	}
-	for _, enum := range f.allEnums {
-		genEnum(g, f, enum)
+	if GenerateGroupedDecls {
+		genGroupedDecls(g, f)
+	} else {
+		for _, enum := range f.allEnums {
+			genEnum(g, f, enum)
+		}
+		for _, message := range f.allMessages {
+			genMessage(g, f, message)
+		}
+		genExtensionInfos(g, f)
+		genExtensionVars(g, f, f.allExtensions)
 	}
-	for _, message := range f.allMessages {
-		genMessage(g, f, message)
+	if GenerateServiceDescriptors {
+		genServiceDescriptors(g, f)
 	}
-	genExtensions(g, f)
+	genFileKnownFunctions(g, f)
 
 	// The descriptor contains a lot of information about the syntax which is
 	// quite different between the proto2/3 version of a file and the equivalent
@@ -170,13 +237,13 @@ func generateOneFile(gen *protogen.Plugin, file *protogen.File, f *fileInfo, var
 // genStandaloneComments prints all leading comments for a FileDescriptorProto
 // location identified by the field number n.
 func genStandaloneComments(g *protogen.GeneratedFile, f *fileInfo, n int32) {
-	loc := f.Desc.SourceLocations().ByPath(protoreflect.SourcePath{n})
-	for _, s := range loc.LeadingDetachedComments {
-		g.P(protogen.Comments(s))
+	comments := f.CommentsAt(protoreflect.SourcePath{n})
+	for _, c := range comments.LeadingDetached {
+		g.P(c)
 		g.P()
 	}
-	if s := loc.LeadingComments; s != "" {
-		g.P(protogen.Comments(s))
+	if c := comments.Leading; c != "" {
+		g.P(c)
 		g.P()
 	}
 }
@@ -578,6 +645,22 @@ func genMessageDefaultDecls(g *protogen.GeneratedFile, f *fileInfo, m *messageIn
 	g.P()
 }
 
+// genMessageFieldNumbers generates a constant for the number of each field
+// in the message, including the fields of its oneofs.
+func genMessageFieldNumbers(g *protogen.GeneratedFile, m *messageInfo) {
+	if len(m.Fields) == 0 {
+		return
+	}
+	g.P("// Field numbers for ", m.GoIdent, " fields.")
+	g.P("const (")
+	for _, field := range m.Fields {
+		name := m.GoIdent.GoName + "_" + field.GoName + "FieldNumber"
+		g.P(name, " ", protoreflectPackage.Ident("FieldNumber"), " = ", field.Desc.Number())
+	}
+	g.P(")")
+	g.P()
+}
+
 func genMessageMethods(g *protogen.GeneratedFile, f *fileInfo, m *messageInfo) {
 	genMessageBaseMethods(g, f, m)
 	genMessageGetterMethods(g, f, m)
@@ -587,6 +670,7 @@ func genMessageBaseMethods(g *protogen.GeneratedFile, f *fileInfo, m *messageInf
 	// Reset method.
 	g.P("func (x *", m.GoIdent, ") Reset() {")
 	g.P("*x = ", m.GoIdent, "{}")
+	genLazyInitCall(g, f)
 	g.P("mi := &", messageTypesVarName(f), "[", f.allMessagesByPtr[m], "]")
 	g.P("ms := ", protoimplPackage.Ident("X"), ".MessageStateOf(", protoimplPackage.Ident("Pointer"), "(x))")
 	g.P("ms.StoreMessageInfo(mi)")
@@ -759,7 +843,146 @@ func fieldJSONTagValue(field *protogen.Field) string {
 	return string(field.Desc.Name()) + ",omitempty"
 }
 
-func genExtensions(g *protogen.GeneratedFile, f *fileInfo) {
+// fieldCustomTags returns the additional struct tags for a field
+// specified by the (pb.go_field).tags option.
+func fieldCustomTags(field *protogen.Field) (structTags, error) {
+	opts, ok := field.Desc.Options().(*descriptorpb.FieldOptions)
+	if !ok || !proto.HasExtension(opts, gooptionspb.E_GoField) {
+		return nil, nil
+	}
+	s := proto.GetExtension(opts, gooptionspb.E_GoField).(*gooptionspb.GoFieldOptions).GetTags()
+	var tags structTags
+	seen := make(map[string]bool)
+	for {
+		s = strings.TrimLeft(s, " ")
+		if s == "" {
+			return tags, nil
+		}
+		i := strings.IndexByte(s, ':')
+		if i <= 0 || strings.ContainsAny(s[:i], " \"`") {
+			return nil, fmt.Errorf("invalid struct tag syntax near %q", s)
+		}
+		key := s[:i]
+		switch key {
+		case "protobuf", "protobuf_key", "protobuf_val", "protobuf_oneof", "json":
+			return nil, fmt.Errorf("struct tag key %q is reserved", key)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate struct tag key %q", key)
+		}
+		seen[key] = true
+		s = s[i+1:]
+		qs, err := strconv.QuotedPrefix(s)
+		if err != nil || qs[0] != '"' {
+			return nil, fmt.Errorf("invalid value for struct tag key %q", key)
+		}
+		val, _ := strconv.Unquote(qs)
+		tags = append(tags, [2]string{key, val})
+		s = s[len(qs):]
+	}
+}
+
+// checkCustomTags reports an error for every field in messages
+// with an invalid (pb.go_field).tags option.
+func checkCustomTags(gen *protogen.Plugin, messages []*protogen.Message) {
+	for _, message := range messages {
+		for _, field := range message.Fields {
+			if _, err := fieldCustomTags(field); err != nil {
+				gen.Error(fmt.Errorf("%v: %v", field.Desc.FullName(), err))
+			}
+		}
+		checkCustomTags(gen, message.Messages)
+	}
+}
+
+// genServiceDescriptors generates functions that return the descriptors
+// of the services of the file and their methods.
+// See GenerateServiceDescriptors.
+func genServiceDescriptors(g *protogen.GeneratedFile, f *fileInfo) {
+	for i, service := range f.Services {
+		serviceFunc := service.GoName + "_ServiceDescriptor"
+		g.P("// ", serviceFunc, " returns the descriptor of the ", service.Desc.FullName(), " service.")
+		g.P("func ", serviceFunc, "() ", protoreflectPackage.Ident("ServiceDescriptor"), " {")
+		genLazyInitCall(g, f)
+		g.P("return ", f.GoDescriptorIdent, ".Services().Get(", i, ")")
+		g.P("}")
+		g.P()
+		for j, method := range service.Methods {
+			methodFunc := service.GoName + "_" + method.GoName + "_MethodDescriptor"
+			g.P("// ", methodFunc, " returns the descriptor of the ", method.Desc.FullName(), " method.")
+			g.P("func ", methodFunc, "() ", protoreflectPackage.Ident("MethodDescriptor"), " {")
+			g.P("return ", serviceFunc, "().Methods().Get(", j, ")")
+			g.P("}")
+			g.P()
+		}
+	}
+}
+
+// genGroupedDecls generates the enums, messages, and extensions of the file
+// grouped by top-level declaration. See GenerateGroupedDecls.
+func genGroupedDecls(g *protogen.GeneratedFile, f *fileInfo) {
+	enums := make(map[*protogen.Enum]*enumInfo)
+	for _, e := range f.allEnums {
+		enums[e.Enum] = e
+	}
+	messages := make(map[*protogen.Message]*messageInfo)
+	for _, m := range f.allMessages {
+		messages[m.Message] = m
+	}
+	extensions := make(map[*protogen.Extension]*extensionInfo)
+	for _, x := range f.allExtensions {
+		extensions[x.Extension] = x
+	}
+
+	// declLine returns the line on which d is declared,
+	// or zero if the file has no source information.
+	declLine := func(d protoreflect.Descriptor) int {
+		return f.Desc.SourceLocations().ByDescriptor(d).StartLine
+	}
+	var genDecls func(es []*protogen.Enum, ms []*protogen.Message)
+	genDecls = func(es []*protogen.Enum, ms []*protogen.Message) {
+		type decl struct {
+			line int
+			enum *protogen.Enum
+			msg  *protogen.Message
+		}
+		var decls []decl
+		for _, e := range es {
+			decls = append(decls, decl{line: declLine(e.Desc), enum: e})
+		}
+		for _, m := range ms {
+			decls = append(decls, decl{line: declLine(m.Desc), msg: m})
+		}
+		sort.SliceStable(decls, func(i, j int) bool {
+			return decls[i].line < decls[j].line
+		})
+		for _, d := range decls {
+			if d.enum != nil {
+				genEnum(g, f, enums[d.enum])
+				continue
+			}
+			genMessage(g, f, messages[d.msg])
+			genDecls(d.msg.Enums, d.msg.Messages)
+			var xs []*extensionInfo
+			for _, x := range d.msg.Extensions {
+				xs = append(xs, extensions[x])
+			}
+			genExtensionVars(g, f, xs)
+		}
+	}
+	genDecls(f.Enums, f.Messages)
+
+	var xs []*extensionInfo
+	for _, x := range f.Extensions {
+		xs = append(xs, extensions[x])
+	}
+	genExtensionVars(g, f, xs)
+	genExtensionInfos(g, f)
+}
+
+// genExtensionInfos generates the table of extension types of the file,
+// which the extension variables generated by genExtensionVars refer to.
+func genExtensionInfos(g *protogen.GeneratedFile, f *fileInfo) {
 	if len(f.allExtensions) == 0 {
 		return
 	}
@@ -781,18 +1004,23 @@ func genExtensions(g *protogen.GeneratedFile, f *fileInfo) {
 	}
 	g.P("}")
 	g.P()
+}
 
-	// Group extensions by the target message.
-	var orderedTargets []protogen.GoIdent
-	allExtensionsByTarget := make(map[protogen.GoIdent][]*extensionInfo)
+// genExtensionVars generates the variables for the extensions xs,
+// grouped by the target message.
+func genExtensionVars(g *protogen.GeneratedFile, f *fileInfo, xs []*extensionInfo) {
 	allExtensionsByPtr := make(map[*extensionInfo]int)
 	for i, x := range f.allExtensions {
+		allExtensionsByPtr[x] = i
+	}
+	var orderedTargets []protogen.GoIdent
+	allExtensionsByTarget := make(map[protogen.GoIdent][]*extensionInfo)
+	for _, x := range xs {
 		target := x.Extendee.GoIdent
 		if len(allExtensionsByTarget[target]) == 0 {
 			orderedTargets = append(orderedTargets, target)
 		}
 		allExtensionsByTarget[target] = append(allExtensionsByTarget[target], x)
-		allExtensionsByPtr[x] = i
 	}
 	for _, target := range orderedTargets {
 		g.P("// Extension fields to ", target, ".")
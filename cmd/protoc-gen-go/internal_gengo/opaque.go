@@ -58,10 +58,114 @@ func opaqueGenMessage(g *protogen.GeneratedFile, f *fileInfo, message *messageIn
 	g.P()
 
 	genMessageKnownFunctions(g, f, message)
+	if GenerateFieldNumbers {
+		genMessageFieldNumbers(g, message)
+	}
 	genMessageDefaultDecls(g, f, message)
 	opaqueGenMessageMethods(g, f, message)
 	opaqueGenMessageBuilder(g, f, message)
 	opaqueGenOneofWrapperTypes(g, f, message)
+	if GenerateOneofVisitors {
+		genOneofVisitors(g, f, message)
+	}
+}
+
+// genOneofVisitors generates, for each oneof in the message, a visitor
+// interface with one method per oneof field and a Visit method that calls
+// the visitor method for the field that is set.
+//
+// Adding a field to the oneof adds a method to the visitor interface,
+// so that existing visitor implementations fail to compile until they
+// handle the new case.
+func genOneofVisitors(g *protogen.GeneratedFile, f *fileInfo, message *messageInfo) {
+	for _, oneof := range message.Oneofs {
+		if oneof.Desc.IsSynthetic() {
+			continue
+		}
+		visitName := "Visit" + oneof.GoName
+		if messageHasFieldNamed(message, visitName) {
+			continue
+		}
+		ifName := oneofVisitorInterfaceName(oneof, message.isOpaque())
+		notSetName := "Visit" + oneof.GoName + "NotSet"
+		for _, field := range oneof.Fields {
+			if "Visit"+field.GoName == notSetName {
+				notSetName += "_"
+			}
+		}
+
+		g.AnnotateSymbol(ifName.GoName, protogen.Annotation{Location: oneof.Location})
+		g.P("// ", ifName.GoName, " handles each case of the ", oneof.Desc.Name(), " oneof in ", message.GoIdent.GoName, ".")
+		g.P("type ", ifName, " interface {")
+		for _, field := range oneof.Fields {
+			goType, _ := opaqueFieldGoType(g, f, message, field)
+			g.P("Visit", field.GoName, "(", goType, ")")
+		}
+		g.P(notSetName, "()")
+		g.P("}")
+		g.P()
+
+		g.AnnotateSymbol(message.GoIdent.GoName+"."+visitName, protogen.Annotation{Location: oneof.Location})
+		g.P("// ", visitName, " calls the method of v for the field of the ", oneof.Desc.Name(), " oneof that is set,")
+		g.P("// or ", notSetName, " if none is set.")
+		g.P("func (x *", message.GoIdent, ") ", visitName, "(v ", ifName, ") {")
+		g.P("if x != nil {")
+		g.P("switch c := x.", opaqueOneofFieldName(oneof, message.isOpaque()), ".(type) {")
+		for _, field := range oneof.Fields {
+			g.P("case *", opaqueFieldOneofType(field, message.isOpaque()), ":")
+			g.P("v.Visit", field.GoName, "(c.", field.GoName, ")")
+			g.P("return")
+		}
+		g.P("}")
+		g.P("}")
+		g.P("v.", notSetName, "()")
+		g.P("}")
+		g.P()
+	}
+}
+
+// oneofVisitorInterfaceName returns the name of the visitor interface
+// generated for a oneof by genOneofVisitors.
+func oneofVisitorInterfaceName(oneof *protogen.Oneof, isOpaque bool) protogen.GoIdent {
+	parent := oneof.Parent
+	ident := protogen.GoIdent{
+		GoImportPath: parent.GoIdent.GoImportPath,
+		GoName:       parent.GoIdent.GoName + "_" + oneof.GoName + "Visitor",
+	}
+	// Check for collisions with nested messages or enums and oneof wrapper types.
+Loop:
+	for {
+		for _, message := range parent.Messages {
+			if message.GoIdent == ident {
+				ident.GoName += "_"
+				continue Loop
+			}
+		}
+		for _, enum := range parent.Enums {
+			if enum.GoIdent == ident {
+				ident.GoName += "_"
+				continue Loop
+			}
+		}
+		for _, field := range parent.Fields {
+			if field.Oneof != nil && opaqueFieldOneofType(field, isOpaque) == ident {
+				ident.GoName += "_"
+				continue Loop
+			}
+		}
+		return ident
+	}
+}
+
+// messageHasFieldNamed reports whether the generated struct for message
+// has a field named name.
+func messageHasFieldNamed(message *messageInfo, name string) bool {
+	for _, field := range message.Fields {
+		if field.GoName == name || (field.Oneof != nil && field.Oneof.GoName == name) {
+			return true
+		}
+	}
+	return false
 }
 
 // opaqueGenMessageField generates a struct field.
@@ -126,6 +230,8 @@ func opaqueGenMessageField(g *protogen.GeneratedFile, f *fileInfo, message *mess
 				{"go", "track"},
 			}...)
 		}
+		customTags, _ := fieldCustomTags(field)
+		tags = append(tags, customTags...)
 		g.AnnotateSymbol(field.Parent.GoIdent.GoName+"."+name, protogen.Annotation{Location: field.Location})
 		leadingComments := appendDeprecationSuffix(field.Comments.Leading,
 			field.Desc.ParentFile(),
@@ -225,16 +331,17 @@ func opaqueGenMessageMethods(g *protogen.GeneratedFile, f *fileInfo, message *me
 		opaqueGenGet(g, f, message, field)
 	}
 	for _, field := range message.Fields {
-		// For the plain open mode, we do not have setters.
-		if message.isOpen() {
+		// For the plain open mode, we do not have setters,
+		// unless GenerateSetMethods is set.
+		if message.isOpen() && !GenerateSetMethods {
 			continue
 		}
 		opaqueGenSet(g, f, message, field)
 	}
 	for _, field := range message.Fields {
-		// Open API does not have Has method.
+		// Open API does not have Has method, unless GenerateHasMethods is set.
 		// Repeated (includes map) fields do not have Has method.
-		if message.isOpen() || isRepeated(field) {
+		if isRepeated(field) {
 			continue
 		}
 
@@ -242,6 +349,13 @@ func opaqueGenMessageMethods(g *protogen.GeneratedFile, f *fileInfo, message *me
 			continue
 		}
 
+		if message.isOpen() {
+			if GenerateHasMethods {
+				opaqueGenHas(g, f, message, field)
+			}
+			continue
+		}
+
 		if isFirstOneofField(field) {
 			opaqueGenHasOneof(g, f, message, field.Oneof)
 		}
@@ -440,6 +554,12 @@ func opaqueGenGet(g *protogen.GeneratedFile, f *fileInfo, message *messageInfo,
 func opaqueGenSet(g *protogen.GeneratedFile, f *fileInfo, message *messageInfo, field *protogen.Field) {
 	goType, pointer := opaqueFieldGoType(g, f, message, field)
 	setterName, bcName := field.MethodName("Set")
+	if message.isOpen() {
+		setterName = openMethodName(message, field, "Set")
+		if setterName == "" {
+			return
+		}
+	}
 
 	// If we need a backwards compatible setter name, we add it now.
 	if bcName != "" {
@@ -588,6 +708,12 @@ func opaqueFieldNeedsPresenceArray(message *messageInfo, field *protogen.Field)
 // opaqueGenHas generates a Has method for a field.
 func opaqueGenHas(g *protogen.GeneratedFile, f *fileInfo, message *messageInfo, field *protogen.Field) {
 	hasserName, _ := field.MethodName("Has")
+	if message.isOpen() {
+		hasserName = openMethodName(message, field, "Has")
+		if hasserName == "" {
+			return
+		}
+	}
 
 	leadingComments := appendDeprecationSuffix("",
 		field.Desc.ParentFile(),
@@ -648,6 +774,17 @@ func opaqueGenHas(g *protogen.GeneratedFile, f *fileInfo, message *messageInfo,
 	g.P()
 }
 
+// openMethodName returns the name of the Has or Set method for a field in
+// a message using the Open API, or "" if the name would clash with the name
+// of a struct field, in which case the method is not generated.
+func openMethodName(message *messageInfo, field *protogen.Field, prefix string) string {
+	name := prefix + field.GoName
+	if messageHasFieldNamed(message, name) {
+		return ""
+	}
+	return name
+}
+
 // opaqueGenClear generates a Clear method for a field.
 func opaqueGenClear(g *protogen.GeneratedFile, f *fileInfo, message *messageInfo, field *protogen.Field) {
 	clearerName, _ := field.MethodName("Clear")
@@ -850,7 +987,7 @@ func opaqueNeedsLazyStruct(message *messageInfo) bool {
 
 // opaqueGenMessageBuilder generates a Builder type for a message.
 func opaqueGenMessageBuilder(g *protogen.GeneratedFile, f *fileInfo, message *messageInfo) {
-	if message.isOpen() {
+	if message.isOpen() && !GenerateBuilders {
 		return
 	}
 	// Builder type.
@@ -1007,6 +1144,7 @@ func opaqueGenOneofWrapperTypes(g *protogen.GeneratedFile, f *fileInfo, message
 			idx := f.allMessagesByPtr[message]
 			typesVar := messageTypesVarName(f)
 			g.P("func (x ", caseTypeName, ") String() string {")
+			genLazyInitCall(g, f)
 			g.P("md := ", typesVar, "[", idx, "].Descriptor()")
 			g.P("if x == 0 {")
 			g.P(`return "not set"`)
@@ -1038,6 +1176,10 @@ func opaqueGenOneofWrapperTypes(g *protogen.GeneratedFile, f *fileInfo, message
 			tags := structTags{
 				{"protobuf", protobufTagValue},
 			}
+			if !message.isOpaque() {
+				customTags, _ := fieldCustomTags(field)
+				tags = append(tags, customTags...)
+			}
 			leadingComments := appendDeprecationSuffix(field.Comments.Leading,
 				field.Desc.ParentFile(),
 				field.Desc.Options().(*descriptorpb.FieldOptions).GetDeprecated())
@@ -12,8 +12,7 @@ import (
 
 	"google.golang.org/protobuf/compiler/protogen"
 	"google.golang.org/protobuf/proto"
-	"google.golang.org/protobuf/reflect/protopath"
-	"google.golang.org/protobuf/reflect/protorange"
+	"google.golang.org/protobuf/reflect/protodesc"
 	"google.golang.org/protobuf/reflect/protoreflect"
 
 	"google.golang.org/protobuf/types/descriptorpb"
@@ -141,7 +140,18 @@ func genReflectFileDescriptor(gen *protogen.Plugin, g *protogen.GeneratedFile, f
 	}
 	g.P("}")
 
-	g.P("func init() { ", initFuncName(f.File), "() }")
+	if isLazyInit(f.File) {
+		onceVar := fileVarName(f.File, "initOnce")
+		g.P("var ", onceVar, " ", syncPackage.Ident("Once"))
+		g.P()
+		g.P("func ", lazyInitFuncName(f.File), "() { ", onceVar, ".Do(", initFuncName(f.File), ") }")
+	} else {
+		g.P("func init() { ", initFuncName(f.File), "() }")
+	}
+
+	if GenerateExplicitRegistration {
+		genRegisterFunc(g, f)
+	}
 
 	g.P("func ", initFuncName(f.File), "() {")
 	g.P("if ", f.GoDescriptorIdent, " != nil {")
@@ -156,6 +166,10 @@ func genReflectFileDescriptor(gen *protogen.Plugin, g *protogen.GeneratedFile, f
 		if impFile.GoImportPath != f.GoImportPath {
 			continue
 		}
+		if isLazyInit(impFile) {
+			g.P(lazyInitFuncName(impFile), "()")
+			continue
+		}
 		g.P(initFuncName(impFile), "()")
 	}
 
@@ -192,6 +206,9 @@ func genReflectFileDescriptor(gen *protogen.Plugin, g *protogen.GeneratedFile, f
 	g.P("NumMessages: ", len(f.allMessages), ",")
 	g.P("NumExtensions: ", len(f.allExtensions), ",")
 	g.P("NumServices: ", len(f.Services), ",")
+	if GenerateExplicitRegistration {
+		g.P("FileRegistry: &", registryVarName(f.File), ",")
+	}
 	g.P("},")
 	g.P("GoTypes: ", goTypesVarName(f), ",")
 	g.P("DependencyIndexes: ", depIdxsVarName(f), ",")
@@ -204,6 +221,9 @@ func genReflectFileDescriptor(gen *protogen.Plugin, g *protogen.GeneratedFile, f
 	if len(f.allExtensions) > 0 {
 		g.P("ExtensionInfos: ", extensionTypesVarName(f), ",")
 	}
+	if GenerateExplicitRegistration {
+		g.P("TypeRegistry: &", registryVarName(f.File), ",")
+	}
 	g.P("}.Build()")
 	g.P(f.GoDescriptorIdent, " = out.File")
 
@@ -213,29 +233,9 @@ func genReflectFileDescriptor(gen *protogen.Plugin, g *protogen.GeneratedFile, f
 	g.P("}")
 }
 
-// stripSourceRetentionFieldsFromMessage walks the given message tree recursively
-// and clears any fields with the field option: [retention = RETENTION_SOURCE]
-func stripSourceRetentionFieldsFromMessage(m protoreflect.Message) {
-	protorange.Range(m, func(ppv protopath.Values) error {
-		m2, ok := ppv.Index(-1).Value.Interface().(protoreflect.Message)
-		if !ok {
-			return nil
-		}
-		m2.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
-			fdo, ok := fd.Options().(*descriptorpb.FieldOptions)
-			if ok && fdo.GetRetention() == descriptorpb.FieldOptions_RETENTION_SOURCE {
-				m2.Clear(fd)
-			}
-			return true
-		})
-		return nil
-	})
-}
-
 func genFileDescriptor(gen *protogen.Plugin, g *protogen.GeneratedFile, f *fileInfo) {
 	descProto := proto.Clone(f.Proto).(*descriptorpb.FileDescriptorProto)
-	descProto.SourceCodeInfo = nil // drop source code information
-	stripSourceRetentionFieldsFromMessage(descProto.ProtoReflect())
+	protodesc.StripSourceRetention(descProto)
 	b, err := proto.MarshalOptions{AllowPartial: true, Deterministic: true}.Marshal(descProto)
 	if err != nil {
 		gen.Error(err)
@@ -295,12 +295,14 @@ func genEnumReflectMethods(g *protogen.GeneratedFile, f *fileInfo, e *enumInfo)
 
 	// Descriptor method.
 	g.P("func (", e.GoIdent, ") Descriptor() ", protoreflectPackage.Ident("EnumDescriptor"), " {")
+	genLazyInitCall(g, f)
 	g.P("return ", typesVar, "[", idx, "].Descriptor()")
 	g.P("}")
 	g.P()
 
 	// Type method.
 	g.P("func (", e.GoIdent, ") Type() ", protoreflectPackage.Ident("EnumType"), " {")
+	genLazyInitCall(g, f)
 	g.P("return &", typesVar, "[", idx, "]")
 	g.P("}")
 	g.P()
@@ -318,6 +320,7 @@ func genMessageReflectMethods(g *protogen.GeneratedFile, f *fileInfo, m *message
 
 	// ProtoReflect method.
 	g.P("func (x *", m.GoIdent, ") ProtoReflect() ", protoreflectPackage.Ident("Message"), " {")
+	genLazyInitCall(g, f)
 	g.P("mi := &", typesVar, "[", idx, "]")
 	g.P("if x != nil {")
 	g.P("ms := ", protoimplPackage.Ident("X"), ".MessageStateOf(", protoimplPackage.Ident("Pointer"), "(x))")
@@ -358,3 +361,64 @@ func extensionTypesVarName(f *fileInfo) string {
 func initFuncName(f *protogen.File) string {
 	return fileVarName(f, "init")
 }
+func lazyInitFuncName(f *protogen.File) string {
+	return fileVarName(f, "lazyInit")
+}
+
+func registryVarName(f *protogen.File) string {
+	return fileVarName(f, "registry")
+}
+func registerFuncName(f *protogen.File) string {
+	return "Register" + strings.TrimPrefix(f.GoDescriptorIdent.GoName, "File")
+}
+
+// genRegisterFunc generates the exported function that registers the
+// descriptor and types of a file, which are otherwise never registered.
+func genRegisterFunc(g *protogen.GeneratedFile, f *fileInfo) {
+	registryVar := registryVarName(f.File)
+	g.P("var ", registryVar, " ", protoimplPackage.Ident("DeferredRegistry"))
+	g.P()
+	g.P("// ", registerFuncName(f.File), " registers the descriptor of ", f.Desc.Path(), " in files")
+	g.P("// and the types that it declares in types. Either registry may be nil.")
+	g.P("// The files that it imports are not registered; they are resolved")
+	g.P("// from files when the descriptor is first used.")
+	g.P("func ", registerFuncName(f.File), "(files *", protoregistryPackage.Ident("Files"), ", types *", protoregistryPackage.Ident("Types"), ") error {")
+	if isLazyInit(f.File) {
+		g.P(lazyInitFuncName(f.File), "()")
+	} else {
+		g.P(initFuncName(f.File), "()")
+	}
+	g.P("return ", registryVar, ".Register(files, types)")
+	g.P("}")
+	g.P()
+}
+
+// isLazyInit reports whether the file descriptor and the types of a file
+// are built on first use rather than by an init function.
+//
+// Files that declare extensions are always initialized eagerly, since
+// extensions must be registered before unmarshaling any message that
+// may contain them.
+func isLazyInit(f *protogen.File) bool {
+	if !GenerateLazyInit || len(f.Extensions) > 0 {
+		return false
+	}
+	var hasExtensions func([]*protogen.Message) bool
+	hasExtensions = func(messages []*protogen.Message) bool {
+		for _, m := range messages {
+			if len(m.Extensions) > 0 || hasExtensions(m.Messages) {
+				return true
+			}
+		}
+		return false
+	}
+	return !hasExtensions(f.Messages)
+}
+
+// genLazyInitCall generates a call that initializes the file
+// if it is initialized lazily.
+func genLazyInitCall(g *protogen.GeneratedFile, f *fileInfo) {
+	if isLazyInit(f.File) {
+		g.P(lazyInitFuncName(f.File), "()")
+	}
+}
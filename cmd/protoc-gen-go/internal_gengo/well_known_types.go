@@ -36,6 +36,11 @@ func genPackageKnownComment(f *fileInfo) protogen.Comments {
 	}
 	... // make use of any
 
+ To control how the message value is serialized, such as to produce
+ deterministic output, use NewWithOptions:
+
+	any, err := anypb.NewWithOptions(m, proto.MarshalOptions{Deterministic: true})
+
 
  Unmarshaling an Any
 
@@ -326,6 +331,29 @@ func genPackageKnownComment(f *fileInfo) protogen.Comments {
  IsValid needs to be passed the target message type as an input since the
  FieldMask message itself does not store the message type that the set of paths
  are for.
+
+ The Validate method performs the same check against a message descriptor,
+ but further accepts paths that select the values of a map by key, such as
+ "labels.env", or the elements of a repeated field or map by the wildcard "*",
+ such as "books.*.title". The Canonicalize method converts such a mask to
+ the shortest set of paths that selects the same fields.
+
+
+ Updating messages with a FieldMask
+
+ The Apply method copies the fields selected by a FieldMask from one message
+ into another, which is the typical way to implement an update operation:
+
+	if err := fm.Apply(stored, update); err != nil {
+		... // handle error
+	}
+
+ The Prune method clears all fields of a message that are not selected
+ by the FieldMask:
+
+	if err := fm.Prune(m); err != nil {
+		... // handle error
+	}
 `
 	default:
 		return ""
@@ -345,12 +373,28 @@ func genMessageKnownFunctions(g *protogen.GeneratedFile, f *fileInfo, m *message
 		g.P("}")
 		g.P()
 
+		g.P("// NewWithOptions marshals src into a new Any instance")
+		g.P("// using the provided marshal options.")
+		g.P("//")
+		g.P("// This permits control over the serialization of the underlying message,")
+		g.P("// such as setting Deterministic for systems that sign the encoded value.")
+		g.P("func NewWithOptions(src ", protoPackage.Ident("Message"), ", opts ", protoPackage.Ident("MarshalOptions"), ") (*Any, error) {")
+		g.P("	dst := new(Any)")
+		g.P("	if err := MarshalFrom(dst, src, opts); err != nil {")
+		g.P("		return nil, err")
+		g.P("	}")
+		g.P("	return dst, nil")
+		g.P("}")
+		g.P()
+
 		g.P("// MarshalFrom marshals src into dst as the underlying message")
 		g.P("// using the provided marshal options.")
 		g.P("//")
+		g.P("// The type URL is formed from the prefix configured for")
+		g.P("// protoregistry.GlobalTypes, which is \"type.googleapis.com/\" by default.")
+		g.P("//")
 		g.P("// If no options are specified, call dst.MarshalFrom instead.")
 		g.P("func MarshalFrom(dst *Any, src ", protoPackage.Ident("Message"), ", opts ", protoPackage.Ident("MarshalOptions"), ") error {")
-		g.P("	const urlPrefix = \"type.googleapis.com/\"")
 		g.P("	if src == nil {")
 		g.P("		return ", protoimplPackage.Ident("X"), ".NewError(\"invalid nil source message\")")
 		g.P("	}")
@@ -358,7 +402,7 @@ func genMessageKnownFunctions(g *protogen.GeneratedFile, f *fileInfo, m *message
 		g.P("	if err != nil {")
 		g.P("		return err")
 		g.P("	}")
-		g.P("	dst.TypeUrl = urlPrefix + string(src.ProtoReflect().Descriptor().FullName())")
+		g.P("	dst.TypeUrl = ", protoregistryPackage.Ident("GlobalTypes"), ".TypeURLPrefix() + string(src.ProtoReflect().Descriptor().FullName())")
 		g.P("	dst.Value = b")
 		g.P("	return nil")
 		g.P("}")
@@ -711,11 +755,19 @@ func genMessageKnownFunctions(g *protogen.GeneratedFile, f *fileInfo, m *message
 		g.P()
 
 	case genid.Value_message_fullname:
+		g.P("// Valuer is implemented by types that can convert themselves to a Value.")
+		g.P("// NewValue uses the ToValue method of values that implement it.")
+		g.P("type Valuer interface {")
+		g.P("	ToValue() (*Value, error)")
+		g.P("}")
+		g.P()
+
 		g.P("// NewValue constructs a Value from a general-purpose Go interface.")
 		g.P("//")
 		g.P("//	╔═══════════════════════════════════════╤════════════════════════════════════════════╗")
 		g.P("//	║ Go type                               │ Conversion                                 ║")
 		g.P("//	╠═══════════════════════════════════════╪════════════════════════════════════════════╣")
+		g.P("//	║ Valuer                                │ converted by calling ToValue               ║")
 		g.P("//	║ nil                                   │ stored as NullValue                        ║")
 		g.P("//	║ bool                                  │ stored as BoolValue                        ║")
 		g.P("//	║ int, int8, int16, int32, int64        │ stored as NumberValue                      ║")
@@ -724,14 +776,20 @@ func genMessageKnownFunctions(g *protogen.GeneratedFile, f *fileInfo, m *message
 		g.P("//	║ json.Number                           │ stored as NumberValue                      ║")
 		g.P("//	║ string                                │ stored as StringValue; must be valid UTF-8 ║")
 		g.P("//	║ []byte                                │ stored as StringValue; base64-encoded      ║")
+		g.P("//	║ time.Time                             │ stored as StringValue; RFC 3339 format     ║")
 		g.P("//	║ map[string]any                        │ stored as StructValue                      ║")
 		g.P("//	║ []any                                 │ stored as ListValue                        ║")
 		g.P("//	╚═══════════════════════════════════════╧════════════════════════════════════════════╝")
 		g.P("//")
 		g.P("// When converting an int64 or uint64 to a NumberValue, numeric precision loss")
 		g.P("// is possible since they are stored as a float64.")
+		g.P("//")
+		g.P("// Values of other types, including named types with one of the")
+		g.P("// underlying types above, are reported as an error.")
 		g.P("func NewValue(v any) (*Value, error) {")
 		g.P("	switch v := v.(type) {")
+		g.P("	case Valuer:")
+		g.P("		return v.ToValue()")
 		g.P("	case nil:")
 		g.P("		return NewNullValue(), nil")
 		g.P("	case bool:")
@@ -774,6 +832,8 @@ func genMessageKnownFunctions(g *protogen.GeneratedFile, f *fileInfo, m *message
 		g.P("	case []byte:")
 		g.P("		s := ", base64Package.Ident("StdEncoding"), ".EncodeToString(v)")
 		g.P("		return NewStringValue(s), nil")
+		g.P("	case ", timePackage.Ident("Time"), ":")
+		g.P("		return NewStringValue(v.Format(", timePackage.Ident("RFC3339Nano"), ")), nil")
 		g.P("	case map[string]any:")
 		g.P("		v2, err := NewStruct(v)")
 		g.P("		if err != nil {")
@@ -787,8 +847,32 @@ func genMessageKnownFunctions(g *protogen.GeneratedFile, f *fileInfo, m *message
 		g.P("		}")
 		g.P("		return NewListValue(v2), nil")
 		g.P("	default:")
-		g.P("		return nil, ", protoimplPackage.Ident("X"), ".NewError(\"invalid type: %T\", v)")
+		g.P("		return nil, invalidValueType(v)")
+		g.P("	}")
+		g.P("}")
+		g.P()
+
+		g.P("// invalidValueType returns an error for a value of a type unsupported")
+		g.P("// by NewValue, suggesting a conversion to a supported type if possible.")
+		g.P("func invalidValueType(v any) error {")
+		g.P("	var want string")
+		g.P("	switch ", reflectPackage.Ident("TypeOf"), "(v).Kind() {")
+		g.P("	case ", reflectPackage.Ident("Bool"), ":")
+		g.P("		want = \"bool\"")
+		g.P("	case ", reflectPackage.Ident("Int"), ", ", reflectPackage.Ident("Int8"), ", ", reflectPackage.Ident("Int16"), ", ", reflectPackage.Ident("Int32"), ", ", reflectPackage.Ident("Int64"), ",")
+		g.P("		", reflectPackage.Ident("Uint"), ", ", reflectPackage.Ident("Uint8"), ", ", reflectPackage.Ident("Uint16"), ", ", reflectPackage.Ident("Uint32"), ", ", reflectPackage.Ident("Uint64"), ",")
+		g.P("		", reflectPackage.Ident("Float32"), ", ", reflectPackage.Ident("Float64"), ":")
+		g.P("		want = \"float64\"")
+		g.P("	case ", reflectPackage.Ident("String"), ":")
+		g.P("		want = \"string\"")
+		g.P("	case ", reflectPackage.Ident("Map"), ":")
+		g.P("		want = \"map[string]any\"")
+		g.P("	case ", reflectPackage.Ident("Slice"), ", ", reflectPackage.Ident("Array"), ":")
+		g.P("		want = \"[]any\"")
+		g.P("	default:")
+		g.P("		return ", protoimplPackage.Ident("X"), ".NewError(\"invalid type: %T\", v)")
 		g.P("	}")
+		g.P("	return ", protoimplPackage.Ident("X"), ".NewError(\"invalid type: %T (convert to %s or implement structpb.Valuer)\", v, want)")
 		g.P("}")
 		g.P()
 
@@ -871,6 +955,84 @@ func genMessageKnownFunctions(g *protogen.GeneratedFile, f *fileInfo, m *message
 		g.P("}")
 		g.P()
 
+		g.P("// InterfaceOptions configures the conversion of Value, Struct, and ListValue")
+		g.P("// messages to general-purpose Go values.")
+		g.P("type InterfaceOptions struct {")
+		g.P("	// UseNumber specifies that numbers are converted to json.Number")
+		g.P("	// rather than float64.")
+		g.P("	UseNumber bool")
+		g.P()
+		g.P("	// RejectNonFinite specifies that NaN and infinite numbers are reported")
+		g.P("	// as an error rather than converted to the strings \"NaN\", \"Infinity\",")
+		g.P("	// and \"-Infinity\".")
+		g.P("	RejectNonFinite bool")
+		g.P("}")
+		g.P()
+
+		g.P("// AsInterface converts x to a general-purpose Go interface")
+		g.P("// using the options in o.")
+		g.P("func (o InterfaceOptions) AsInterface(x *Value) (any, error) {")
+		g.P("	switch v := x.GetKind().(type) {")
+		g.P("	case *Value_NumberValue:")
+		g.P("		if v == nil {")
+		g.P("			break")
+		g.P("		}")
+		g.P("		n := v.NumberValue")
+		g.P("		switch {")
+		g.P("		case ", mathPackage.Ident("IsNaN"), "(n) || ", mathPackage.Ident("IsInf"), "(n, 0):")
+		g.P("			if o.RejectNonFinite {")
+		g.P("				return nil, ", protoimplPackage.Ident("X"), ".NewError(\"invalid number: %v\", n)")
+		g.P("			}")
+		g.P("		case o.UseNumber:")
+		g.P("			// Format the number as ECMAScript does, which is also")
+		g.P("			// how it is formatted by MarshalJSON.")
+		g.P("			format := byte('f')")
+		g.P("			if abs := ", mathPackage.Ident("Abs"), "(n); abs != 0 && (abs < 1e-6 || abs >= 1e21) {")
+		g.P("				format = 'e'")
+		g.P("			}")
+		g.P("			return ", jsonPackage.Ident("Number"), "(", strconvPackage.Ident("FormatFloat"), "(n, format, -1, 64)), nil")
+		g.P("		}")
+		g.P("	case *Value_StructValue:")
+		g.P("		if v != nil {")
+		g.P("			return o.AsMap(v.StructValue)")
+		g.P("		}")
+		g.P("	case *Value_ListValue:")
+		g.P("		if v != nil {")
+		g.P("			return o.AsSlice(v.ListValue)")
+		g.P("		}")
+		g.P("	}")
+		g.P("	return x.AsInterface(), nil")
+		g.P("}")
+		g.P()
+
+		g.P("// AsMap converts x to a general-purpose Go map using the options in o.")
+		g.P("func (o InterfaceOptions) AsMap(x *Struct) (map[string]any, error) {")
+		g.P("	f := x.GetFields()")
+		g.P("	vs := make(map[string]any, len(f))")
+		g.P("	for k, v := range f {")
+		g.P("		var err error")
+		g.P("		if vs[k], err = o.AsInterface(v); err != nil {")
+		g.P("			return nil, err")
+		g.P("		}")
+		g.P("	}")
+		g.P("	return vs, nil")
+		g.P("}")
+		g.P()
+
+		g.P("// AsSlice converts x to a general-purpose Go slice using the options in o.")
+		g.P("func (o InterfaceOptions) AsSlice(x *ListValue) ([]any, error) {")
+		g.P("	vals := x.GetValues()")
+		g.P("	vs := make([]any, len(vals))")
+		g.P("	for i, v := range vals {")
+		g.P("		var err error")
+		g.P("		if vs[i], err = o.AsInterface(v); err != nil {")
+		g.P("			return nil, err")
+		g.P("		}")
+		g.P("	}")
+		g.P("	return vs, nil")
+		g.P("}")
+		g.P()
+
 		g.P("func (x *Value) MarshalJSON() ([]byte, error) {")
 		g.P("	return ", protojsonPackage.Ident("Marshal"), "(x)")
 		g.P("}")
@@ -945,6 +1107,30 @@ func genMessageKnownFunctions(g *protogen.GeneratedFile, f *fileInfo, m *message
 		g.P("}")
 		g.P()
 
+		g.P("// Validate reports an error if any of the paths are not syntactically valid")
+		g.P("// or do not refer to fields reachable from the message type md.")
+		g.P("//")
+		g.P("// Unlike IsValid, Validate also accepts paths that continue past a map or")
+		g.P("// repeated field. The path component following a map field must be either")
+		g.P("// a key of the map or the wildcard \"*\", which selects all its values.")
+		g.P("// The path component following a repeated field must be the wildcard \"*\",")
+		g.P("// which selects all its elements. If the selected values are messages, the")
+		g.P("// remainder of the path may refer to fields within them.")
+		g.P("// For example, \"labels.env\" selects the value for the key \"env\" of the map")
+		g.P("// field \"labels\", and \"books.*.title\" selects the \"title\" field of every")
+		g.P("// element of the repeated field \"books\".")
+		g.P("//")
+		g.P("// Such paths are not supported by Apply and Prune.")
+		g.P("func (x *FieldMask) Validate(md ", protoreflectPackage.Ident("MessageDescriptor"), ") error {")
+		g.P("	for _, path := range x.GetPaths() {")
+		g.P("		if !validatePath(md, path) {")
+		g.P("			return ", protoimplPackage.Ident("X"), ".NewError(\"invalid path %q for message %q\", path, md.FullName())")
+		g.P("		}")
+		g.P("	}")
+		g.P("	return nil")
+		g.P("}")
+		g.P()
+
 		g.P("// Append appends a list of paths to the mask and verifies that each one")
 		g.P("// is valid according to the specified message type.")
 		g.P("// An invalid path is not appended and breaks insertion of subsequent paths.")
@@ -960,6 +1146,147 @@ func genMessageKnownFunctions(g *protogen.GeneratedFile, f *fileInfo, m *message
 		g.P("}")
 		g.P()
 
+		g.P("// Apply copies the fields selected by the mask from src into dst,")
+		g.P("// which must be messages with the same descriptor.")
+		g.P("//")
+		g.P("// For every path in the mask, the field it refers to in dst is replaced by")
+		g.P("// a deep copy of the corresponding field in src. If that field is not")
+		g.P("// populated in src, it is cleared in dst. Intermediate messages along the")
+		g.P("// path are created in dst as needed. Repeated and map fields are always")
+		g.P("// replaced in their entirety, since a path cannot select individual")
+		g.P("// list elements or map entries.")
+		g.P("//")
+		g.P("// It reports an error if any of the paths are invalid for the message type.")
+		g.P("func (x *FieldMask) Apply(dst, src ", protoPackage.Ident("Message"), ") error {")
+		g.P("	dm, sm := dst.ProtoReflect(), src.ProtoReflect()")
+		g.P("	if dm.Descriptor() != sm.Descriptor() {")
+		g.P("		return ", protoimplPackage.Ident("X"), ".NewError(\"mismatching message types %q and %q\", dm.Descriptor().FullName(), sm.Descriptor().FullName())")
+		g.P("	}")
+		g.P("	if err := x.checkValid(dst); err != nil {")
+		g.P("		return err")
+		g.P("	}")
+		g.P("	for _, path := range normalizePaths(append([]string(nil), x.GetPaths()...)) {")
+		g.P("		applyPath(dm, sm, path)")
+		g.P("	}")
+		g.P("	return nil")
+		g.P("}")
+		g.P()
+
+		g.P("func applyPath(dst, src ", protoreflectPackage.Ident("Message"), ", path string) {")
+		g.P("	for {")
+		g.P("		var field string")
+		g.P("		if i := ", stringsPackage.Ident("IndexByte"), "(path, '.'); i >= 0 {")
+		g.P("			field, path = path[:i], path[i+1:]")
+		g.P("		} else {")
+		g.P("			field, path = path, \"\"")
+		g.P("		}")
+		g.P("		fd := fieldByName(dst.Descriptor(), field)")
+		g.P("		if path == \"\" {")
+		g.P("			dst.Clear(fd)")
+		g.P("			if src.Has(fd) {")
+		g.P("				copyField(dst, src, fd)")
+		g.P("			}")
+		g.P("			return")
+		g.P("		}")
+		g.P("		if !src.Has(fd) && !dst.Has(fd) {")
+		g.P("			return")
+		g.P("		}")
+		g.P("		dst, src = dst.Mutable(fd).Message(), src.Get(fd).Message()")
+		g.P("	}")
+		g.P("}")
+		g.P()
+
+		g.P("// copyField sets the field in dst to a deep copy of the populated field in src.")
+		g.P("func copyField(dst, src ", protoreflectPackage.Ident("Message"), ", fd ", protoreflectPackage.Ident("FieldDescriptor"), ") {")
+		g.P("	switch v := src.Get(fd); {")
+		g.P("	case fd.IsList():")
+		g.P("		dl, sl := dst.Mutable(fd).List(), v.List()")
+		g.P("		for i := 0; i < sl.Len(); i++ {")
+		g.P("			dl.Append(copyValue(dl.NewElement(), sl.Get(i), fd))")
+		g.P("		}")
+		g.P("	case fd.IsMap():")
+		g.P("		dmap, vd := dst.Mutable(fd).Map(), fd.MapValue()")
+		g.P("		v.Map().Range(func(k ", protoreflectPackage.Ident("MapKey"), ", v ", protoreflectPackage.Ident("Value"), ") bool {")
+		g.P("			dmap.Set(k, copyValue(dmap.NewValue(), v, vd))")
+		g.P("			return true")
+		g.P("		})")
+		g.P("	default:")
+		g.P("		dst.Set(fd, copyValue(dst.NewField(fd), v, fd))")
+		g.P("	}")
+		g.P("}")
+		g.P()
+
+		g.P("// copyValue returns a deep copy of the singular value v,")
+		g.P("// where dv is a new value of the same type.")
+		g.P("func copyValue(dv, v ", protoreflectPackage.Ident("Value"), ", fd ", protoreflectPackage.Ident("FieldDescriptor"), ") ", protoreflectPackage.Ident("Value"), " {")
+		g.P("	switch {")
+		g.P("	case fd.Message() != nil:")
+		g.P("		", protoPackage.Ident("Merge"), "(dv.Message().Interface(), v.Message().Interface())")
+		g.P("		return dv")
+		g.P("	case fd.Kind() == ", protoreflectPackage.Ident("BytesKind"), ":")
+		g.P("		return ", protoreflectPackage.Ident("ValueOfBytes"), "(append([]byte{}, v.Bytes()...))")
+		g.P("	default:")
+		g.P("		return v")
+		g.P("	}")
+		g.P("}")
+		g.P()
+
+		g.P("// Prune clears all fields in m that are not selected by the mask,")
+		g.P("// including unknown fields and fields of nested messages.")
+		g.P("// It reports an error if any of the paths are invalid for the message type.")
+		g.P("func (x *FieldMask) Prune(m ", protoPackage.Ident("Message"), ") error {")
+		g.P("	if err := x.checkValid(m); err != nil {")
+		g.P("		return err")
+		g.P("	}")
+		g.P("	prunePaths(m.ProtoReflect(), normalizePaths(append([]string(nil), x.GetPaths()...)))")
+		g.P("	return nil")
+		g.P("}")
+		g.P()
+
+		g.P("func prunePaths(m ", protoreflectPackage.Ident("Message"), ", paths []string) {")
+		g.P("	m.Range(func(fd ", protoreflectPackage.Ident("FieldDescriptor"), ", _ ", protoreflectPackage.Ident("Value"), ") bool {")
+		g.P("		if fd.IsExtension() {")
+		g.P("			m.Clear(fd) // paths never select extension fields")
+		g.P("			return true")
+		g.P("		}")
+		g.P("		name := string(fd.Name())")
+		g.P("		if fd.Kind() == ", protoreflectPackage.Ident("GroupKind"), " {")
+		g.P("			name = string(fd.Message().Name())")
+		g.P("		}")
+		g.P("		var subpaths []string")
+		g.P("		for _, path := range paths {")
+		g.P("			if path == name {")
+		g.P("				return true // keep the entire field")
+		g.P("			}")
+		g.P("			if hasPathPrefix(path, name) {")
+		g.P("				subpaths = append(subpaths, path[len(name)+len(\".\"):])")
+		g.P("			}")
+		g.P("		}")
+		g.P("		if len(subpaths) == 0 {")
+		g.P("			m.Clear(fd)")
+		g.P("		} else {")
+		g.P("			prunePaths(m.Mutable(fd).Message(), subpaths)")
+		g.P("		}")
+		g.P("		return true")
+		g.P("	})")
+		g.P("	if len(m.GetUnknown()) > 0 {")
+		g.P("		m.SetUnknown(nil)")
+		g.P("	}")
+		g.P("}")
+		g.P()
+
+		g.P("// checkValid reports an error if any of the paths are invalid for the")
+		g.P("// specified message type.")
+		g.P("func (x *FieldMask) checkValid(m ", protoPackage.Ident("Message"), ") error {")
+		g.P("	paths := x.GetPaths()")
+		g.P("	if n := numValidPaths(m, paths); n < len(paths) {")
+		g.P("		name := m.ProtoReflect().Descriptor().FullName()")
+		g.P("		return ", protoimplPackage.Ident("X"), ".NewError(\"invalid path %q for message %q\", paths[n], name)")
+		g.P("	}")
+		g.P("	return nil")
+		g.P("}")
+		g.P()
+
 		g.P("func numValidPaths(m ", protoPackage.Ident("Message"), ", paths []string) int {")
 		g.P("	md0 := m.ProtoReflect().Descriptor()")
 		g.P("	for i, path := range paths {")
@@ -969,16 +1296,7 @@ func genMessageKnownFunctions(g *protogen.GeneratedFile, f *fileInfo, m *message
 		g.P("			if md == nil {")
 		g.P("				return false // not within a message")
 		g.P("			}")
-		g.P("			fd := md.Fields().ByName(", protoreflectPackage.Ident("Name"), "(field))")
-		g.P("			// The real field name of a group is the message name.")
-		g.P("			if fd == nil {")
-		g.P("				gd := md.Fields().ByName(", protoreflectPackage.Ident("Name"), "(", stringsPackage.Ident("ToLower"), "(field)))")
-		g.P("				if gd != nil && gd.Kind() == ", protoreflectPackage.Ident("GroupKind"), " && string(gd.Message().Name()) == field {")
-		g.P("					fd = gd")
-		g.P("				}")
-		g.P("			} else if fd.Kind() == ", protoreflectPackage.Ident("GroupKind"), " && string(fd.Message().Name()) != field {")
-		g.P("				fd = nil")
-		g.P("			}")
+		g.P("			fd := fieldByName(md, field)")
 		g.P("			if fd == nil {")
 		g.P("				return false // message has does not have this field")
 		g.P("			}")
@@ -1000,6 +1318,81 @@ func genMessageKnownFunctions(g *protogen.GeneratedFile, f *fileInfo, m *message
 		g.P("}")
 		g.P()
 
+		g.P("// fieldByName returns the field in md that is named by the path component,")
+		g.P("// or nil if there is no such field.")
+		g.P("func fieldByName(md ", protoreflectPackage.Ident("MessageDescriptor"), ", field string) ", protoreflectPackage.Ident("FieldDescriptor"), " {")
+		g.P("	fd := md.Fields().ByName(", protoreflectPackage.Ident("Name"), "(field))")
+		g.P("	// The real field name of a group is the message name.")
+		g.P("	if fd == nil {")
+		g.P("		gd := md.Fields().ByName(", protoreflectPackage.Ident("Name"), "(", stringsPackage.Ident("ToLower"), "(field)))")
+		g.P("		if gd != nil && gd.Kind() == ", protoreflectPackage.Ident("GroupKind"), " && string(gd.Message().Name()) == field {")
+		g.P("			fd = gd")
+		g.P("		}")
+		g.P("	} else if fd.Kind() == ", protoreflectPackage.Ident("GroupKind"), " && string(fd.Message().Name()) != field {")
+		g.P("		fd = nil")
+		g.P("	}")
+		g.P("	return fd")
+		g.P("}")
+		g.P()
+
+		g.P("// validatePath reports whether the path refers to a field reachable from md,")
+		g.P("// where the component following a map field may also be a map key or")
+		g.P("// a wildcard, and the component following a repeated field must be a wildcard.")
+		g.P("func validatePath(md ", protoreflectPackage.Ident("MessageDescriptor"), ", path string) bool {")
+		g.P("	var fd ", protoreflectPackage.Ident("FieldDescriptor"), " // field named by the previous component")
+		g.P("	return rangeFields(path, func(field string) bool {")
+		g.P("		switch {")
+		g.P("		case fd != nil && fd.IsMap():")
+		g.P("			if field != \"*\" && !isValidMapKey(fd.MapKey(), field) {")
+		g.P("				return false")
+		g.P("			}")
+		g.P("			md, fd = fd.MapValue().Message(), nil")
+		g.P("			return true")
+		g.P("		case fd != nil && fd.IsList():")
+		g.P("			if field != \"*\" {")
+		g.P("				return false")
+		g.P("			}")
+		g.P("			md, fd = fd.Message(), nil")
+		g.P("			return true")
+		g.P("		}")
+		g.P()
+		g.P("		// Search the field within the message.")
+		g.P("		if md == nil {")
+		g.P("			return false // not within a message")
+		g.P("		}")
+		g.P("		fd = fieldByName(md, field)")
+		g.P("		if fd == nil {")
+		g.P("			return false // message does not have this field")
+		g.P("		}")
+		g.P("		md = fd.Message() // may be nil")
+		g.P("		return true")
+		g.P("	})")
+		g.P("}")
+		g.P()
+		g.P("// isValidMapKey reports whether the path component is a valid key")
+		g.P("// for a map with the specified key field.")
+		g.P("func isValidMapKey(fd ", protoreflectPackage.Ident("FieldDescriptor"), ", field string) bool {")
+		g.P("	var err error")
+		g.P("	switch fd.Kind() {")
+		g.P("	case ", protoreflectPackage.Ident("StringKind"), ":")
+		g.P("		return field != \"\"")
+		g.P("	case ", protoreflectPackage.Ident("BoolKind"), ":")
+		g.P("		return field == \"true\" || field == \"false\"")
+		g.P("	case ", protoreflectPackage.Ident("Int32Kind"), ", ", protoreflectPackage.Ident("Sint32Kind"), ", ", protoreflectPackage.Ident("Sfixed32Kind"), ":")
+		g.P("		_, err = ", strconvPackage.Ident("ParseInt"), "(field, 10, 32)")
+		g.P("	case ", protoreflectPackage.Ident("Int64Kind"), ", ", protoreflectPackage.Ident("Sint64Kind"), ", ", protoreflectPackage.Ident("Sfixed64Kind"), ":")
+		g.P("		_, err = ", strconvPackage.Ident("ParseInt"), "(field, 10, 64)")
+		g.P("	case ", protoreflectPackage.Ident("Uint32Kind"), ", ", protoreflectPackage.Ident("Fixed32Kind"), ":")
+		g.P("		_, err = ", strconvPackage.Ident("ParseUint"), "(field, 10, 32)")
+		g.P("	case ", protoreflectPackage.Ident("Uint64Kind"), ", ", protoreflectPackage.Ident("Fixed64Kind"), ":")
+		g.P("		_, err = ", strconvPackage.Ident("ParseUint"), "(field, 10, 64)")
+		g.P("	default:")
+		g.P("		return false")
+		g.P("	}")
+		g.P("	return err == nil")
+		g.P("}")
+		g.P()
+
 		g.P("// Normalize converts the mask to its canonical form where all paths are sorted")
 		g.P("// and redundant paths are removed.")
 		g.P("func (x *FieldMask) Normalize() {")
@@ -1023,6 +1416,39 @@ func genMessageKnownFunctions(g *protogen.GeneratedFile, f *fileInfo, m *message
 		g.P("}")
 		g.P()
 
+		g.P("// Canonicalize converts the mask to the shortest set of paths that selects")
+		g.P("// the same fields. In addition to the conversion performed by Normalize,")
+		g.P("// trailing wildcards are removed, since a path ending in \"*\" selects the")
+		g.P("// same values as the path without it, and paths are removed if a path with")
+		g.P("// a wildcard in place of a map key or another wildcard selects a superset.")
+		g.P("// See Validate for the meaning of wildcards.")
+		g.P("func (x *FieldMask) Canonicalize() {")
+		g.P("	for i, path := range x.Paths {")
+		g.P("		for ", stringsPackage.Ident("HasSuffix"), "(path, \".*\") {")
+		g.P("			path = ", stringsPackage.Ident("TrimSuffix"), "(path, \".*\")")
+		g.P("		}")
+		g.P("		x.Paths[i] = path")
+		g.P("	}")
+		g.P("	paths := normalizePaths(x.Paths)")
+		g.P()
+		g.P("	// Elide any path that is matched by another path with wildcards.")
+		g.P("	out := make([]string, 0, len(paths))")
+		g.P("	for _, path := range paths {")
+		g.P("		var covered bool")
+		g.P("		for _, prefix := range paths {")
+		g.P("			if prefix != path && hasWildcardPrefix(path, prefix) {")
+		g.P("				covered = true")
+		g.P("				break")
+		g.P("			}")
+		g.P("		}")
+		g.P("		if !covered {")
+		g.P("			out = append(out, path)")
+		g.P("		}")
+		g.P("	}")
+		g.P("	x.Paths = out")
+		g.P("}")
+		g.P()
+
 		g.P("// hasPathPrefix is like strings.HasPrefix, but further checks for either")
 		g.P("// an exact matche or that the prefix is delimited by a dot.")
 		g.P("func hasPathPrefix(path, prefix string) bool {")
@@ -1030,6 +1456,26 @@ func genMessageKnownFunctions(g *protogen.GeneratedFile, f *fileInfo, m *message
 		g.P("}")
 		g.P()
 
+		g.P("// hasWildcardPrefix is like hasPathPrefix, but further treats a wildcard")
+		g.P("// component in the prefix as matching any component in the path.")
+		g.P("func hasWildcardPrefix(path, prefix string) bool {")
+		g.P("	for {")
+		g.P("		field, rest, _ := ", stringsPackage.Ident("Cut"), "(path, \".\")")
+		g.P("		prefixField, prefixRest, more := ", stringsPackage.Ident("Cut"), "(prefix, \".\")")
+		g.P("		if field != prefixField && prefixField != \"*\" {")
+		g.P("			return false")
+		g.P("		}")
+		g.P("		if !more {")
+		g.P("			return true")
+		g.P("		}")
+		g.P("		if rest == \"\" {")
+		g.P("			return false")
+		g.P("		}")
+		g.P("		path, prefix = rest, prefixRest")
+		g.P("	}")
+		g.P("}")
+		g.P()
+
 		g.P("// lessPath is a lexicographical comparison where dot is specially treated")
 		g.P("// as the smallest symbol.")
 		g.P("func lessPath(x, y string) bool {")
@@ -1065,6 +1511,52 @@ func genMessageKnownFunctions(g *protogen.GeneratedFile, f *fileInfo, m *message
 		g.P("}")
 		g.P()
 
+		g.P("// CamelCasePath converts a path from the snake_case field names used")
+		g.P("// by the FieldMask message to the lowerCamelCase field names used")
+		g.P("// by its JSON representation. For example, \"foo_bar.baz\" is converted")
+		g.P("// to \"fooBar.baz\". It reports an error if the conversion is not reversible")
+		g.P("// by SnakeCasePath, such as for \"foo__bar\" or \"fooBar\".")
+		g.P("func CamelCasePath(path string) (string, error) {")
+		g.P("	var b []byte")
+		g.P("	var wasUnderscore bool")
+		g.P("	for i := 0; i < len(path); i++ { // proto identifiers are always ASCII")
+		g.P("		c := path[i]")
+		g.P("		if c != '_' {")
+		g.P("			if wasUnderscore && 'a' <= c && c <= 'z' {")
+		g.P("				c -= 'a' - 'A' // convert to uppercase")
+		g.P("			}")
+		g.P("			b = append(b, c)")
+		g.P("		}")
+		g.P("		wasUnderscore = c == '_'")
+		g.P("	}")
+		g.P("	if s, err := SnakeCasePath(string(b)); err != nil || s != path {")
+		g.P("		return \"\", ", protoimplPackage.Ident("X"), ".NewError(\"irreversible path %q\", path)")
+		g.P("	}")
+		g.P("	return string(b), nil")
+		g.P("}")
+		g.P()
+		g.P("// SnakeCasePath converts a path from the lowerCamelCase field names used")
+		g.P("// by the JSON representation of the FieldMask message to the snake_case")
+		g.P("// field names used by the message. For example, \"fooBar.baz\" is converted")
+		g.P("// to \"foo_bar.baz\". It reports an error if the path contains an underscore,")
+		g.P("// which is not permitted in the JSON representation.")
+		g.P("func SnakeCasePath(path string) (string, error) {")
+		g.P("	var b []byte")
+		g.P("	for i := 0; i < len(path); i++ { // proto identifiers are always ASCII")
+		g.P("		c := path[i]")
+		g.P("		if c == '_' {")
+		g.P("			return \"\", ", protoimplPackage.Ident("X"), ".NewError(\"invalid path %q\", path)")
+		g.P("		}")
+		g.P("		if 'A' <= c && c <= 'Z' {")
+		g.P("			b = append(b, '_')")
+		g.P("			c += 'a' - 'A' // convert to lowercase")
+		g.P("		}")
+		g.P("		b = append(b, c)")
+		g.P("	}")
+		g.P("	return string(b), nil")
+		g.P("}")
+		g.P()
+
 	case genid.BoolValue_message_fullname,
 		genid.Int32Value_message_fullname,
 		genid.Int64Value_message_fullname,
@@ -1075,15 +1567,7 @@ func genMessageKnownFunctions(g *protogen.GeneratedFile, f *fileInfo, m *message
 		genid.StringValue_message_fullname,
 		genid.BytesValue_message_fullname:
 		funcName := strings.TrimSuffix(m.GoIdent.GoName, "Value")
-		typeName := strings.ToLower(funcName)
-		switch typeName {
-		case "float":
-			typeName = "float32"
-		case "double":
-			typeName = "float64"
-		case "bytes":
-			typeName = "[]byte"
-		}
+		typeName := wrapperValueType(funcName)
 
 		g.P("// ", funcName, " stores v in a new ", m.GoIdent, " and returns a pointer to it.")
 		g.P("func ", funcName, "(v ", typeName, ") *", m.GoIdent, " {")
@@ -1092,3 +1576,89 @@ func genMessageKnownFunctions(g *protogen.GeneratedFile, f *fileInfo, m *message
 		g.P()
 	}
 }
+
+// genFileKnownFunctions generates declarations for a well-known file
+// that are not associated with a single message.
+func genFileKnownFunctions(g *protogen.GeneratedFile, f *fileInfo) {
+	switch f.Desc.Path() {
+	case genid.File_google_protobuf_wrappers_proto:
+		g.P("// Scalar is a constraint satisfied by the types of the values")
+		g.P("// held by the wrapper messages.")
+		g.P("type Scalar interface {")
+		g.P("	float64 | float32 | int64 | uint64 | int32 | uint32 | bool | string | []byte")
+		g.P("}")
+		g.P()
+
+		g.P("// Message is a constraint satisfied by pointers to the wrapper messages")
+		g.P("// holding a value of type T.")
+		g.P("type Message[T Scalar] interface {")
+		var types []string
+		for _, m := range f.allMessages {
+			types = append(types, "*"+m.GoIdent.GoName)
+		}
+		g.P(strings.Join(types, " | "))
+		g.P("	GetValue() T")
+		g.P("}")
+		g.P()
+
+		g.P("// New stores v in a new wrapper message of type M and returns it.")
+		g.P("// For example, New[*Int32Value](5) is equivalent to Int32(5).")
+		g.P("func New[M Message[T], T Scalar](v T) M {")
+		g.P("	var m any")
+		g.P("	switch v := any(v).(type) {")
+		for _, m := range f.allMessages {
+			funcName := strings.TrimSuffix(m.GoIdent.GoName, "Value")
+			g.P("	case ", wrapperValueType(funcName), ":")
+			g.P("		m = ", funcName, "(v)")
+		}
+		g.P("	}")
+		g.P("	return m.(M)")
+		g.P("}")
+		g.P()
+
+		g.P("// FromPointer stores the value pointed to by p in a new wrapper message")
+		g.P("// of type M and returns it. It returns nil if p is nil.")
+		g.P("func FromPointer[M Message[T], T Scalar](p *T) M {")
+		g.P("	if p == nil {")
+		g.P("		return nil")
+		g.P("	}")
+		g.P("	return New[M](*p)")
+		g.P("}")
+		g.P()
+
+		g.P("// GetOrDefault returns the value held by m, or def if m is nil.")
+		g.P("func GetOrDefault[M Message[T], T Scalar](m M, def T) T {")
+		g.P("	if m == nil {")
+		g.P("		return def")
+		g.P("	}")
+		g.P("	return m.GetValue()")
+		g.P("}")
+		g.P()
+
+		g.P("// Pointer returns a pointer to a copy of the value held by m,")
+		g.P("// or nil if m is nil.")
+		g.P("func Pointer[M Message[T], T Scalar](m M) *T {")
+		g.P("	if m == nil {")
+		g.P("		return nil")
+		g.P("	}")
+		g.P("	v := m.GetValue()")
+		g.P("	return &v")
+		g.P("}")
+		g.P()
+	}
+}
+
+// wrapperValueType returns the Go type of the value held by the wrapper
+// message whose constructor function is named funcName.
+func wrapperValueType(funcName string) string {
+	switch typeName := strings.ToLower(funcName); typeName {
+	case "float":
+		return "float32"
+	case "double":
+		return "float64"
+	case "bytes":
+		return "[]byte"
+	default:
+		return typeName
+	}
+}
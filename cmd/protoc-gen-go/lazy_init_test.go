@@ -0,0 +1,77 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"go/ast"
+	"testing"
+
+	gengo "google.golang.org/protobuf/cmd/protoc-gen-go/internal_gengo"
+)
+
+func TestGenerateLazyInit(t *testing.T) {
+	defer func(v bool) { gengo.GenerateLazyInit = v }(gengo.GenerateLazyInit)
+	for _, tt := range []struct {
+		desc     string
+		lazy     bool
+		fileDesc string
+		wantLazy bool
+	}{{
+		desc: "default",
+		fileDesc: `
+			name: "lazy.proto"
+			package: "lazy"
+			syntax: "proto3"
+			options: {go_package: "example.com/lazy"}
+			message_type: {name: "M"}
+		`,
+	}, {
+		desc: "lazy",
+		lazy: true,
+		fileDesc: `
+			name: "lazy.proto"
+			package: "lazy"
+			syntax: "proto3"
+			options: {go_package: "example.com/lazy"}
+			message_type: {name: "M"}
+			enum_type: {name: "E" value: {name: "E_ZERO" number: 0}}
+		`,
+		wantLazy: true,
+	}, {
+		desc: "lazy with extensions",
+		lazy: true,
+		fileDesc: `
+			name: "lazy.proto"
+			package: "lazy"
+			syntax: "proto2"
+			options: {go_package: "example.com/lazy"}
+			message_type: {
+				name: "M"
+				extension_range: {start: 100 end: 200}
+				extension: {name: "x" number: 100 label: LABEL_OPTIONAL type: TYPE_INT32 extendee: ".lazy.M" json_name: "x"}
+			}
+		`,
+	}} {
+		gengo.GenerateLazyInit = tt.lazy
+		f, err := generate(t, tt.fileDesc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var hasInit, hasLazyInit bool
+		for _, decl := range f.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil {
+				switch fn.Name.Name {
+				case "init":
+					hasInit = true
+				case "file_lazy_proto_lazyInit":
+					hasLazyInit = true
+				}
+			}
+		}
+		if hasInit == tt.wantLazy || hasLazyInit != tt.wantLazy {
+			t.Errorf("%s: has init function = %v, has lazy init function = %v; want lazy = %v", tt.desc, hasInit, hasLazyInit, tt.wantLazy)
+		}
+	}
+}
@@ -37,6 +37,15 @@ func main() {
 	var (
 		flags                                 flag.FlagSet
 		plugins                               = flags.String("plugins", "", "deprecated option")
+		generateHasMethods                    = flags.Bool("generate_has_methods", false, "generate_has_methods true means that the plugin will emit a Has method for every field with explicit presence in messages using the Open API.")
+		generateSetMethods                    = flags.Bool("generate_set_methods", false, "generate_set_methods true means that the plugin will emit a Set method for every field in messages using the Open API.")
+		generateBuilders                      = flags.Bool("generate_builders", false, "generate_builders true means that the plugin will emit a <Message>_builder type with a Build method for every message using the Open API.")
+		generateOneofVisitors                 = flags.Bool("generate_oneof_visitors", false, "generate_oneof_visitors true means that the plugin will emit a visitor interface and a Visit method for every oneof.")
+		generateFieldNumbers                  = flags.Bool("generate_field_numbers", false, "generate_field_numbers true means that the plugin will emit a <Message>_<Field>FieldNumber constant for the number of every field.")
+		explicitRegistration                  = flags.Bool("explicit_registration", false, "explicit_registration true means that the plugin will emit a Register_<file> function for every file that registers its descriptor and types in the given registries, instead of registering them in the global registries at program initialization.")
+		lazyInit                              = flags.Bool("lazy_init", false, "lazy_init true means that the plugin will emit code that builds the descriptors and types of a file on first use instead of at program initialization, except for files that declare extensions.")
+		generateServiceDescriptors            = flags.Bool("generate_service_descriptors", false, "generate_service_descriptors true means that the plugin will emit <Service>_ServiceDescriptor and <Service>_<Method>_MethodDescriptor functions returning the descriptor of every service and method.")
+		groupedDecls                          = flags.Bool("grouped_decls", false, "grouped_decls true means that the plugin will emit enums, messages, and extensions grouped by top-level declaration in .proto order, with nested declarations following their parent.")
 		experimentalStripNonFunctionalCodegen = flags.Bool("experimental_strip_nonfunctional_codegen", false, "experimental_strip_nonfunctional_codegen true means that the plugin will not emit certain parts of the generated code in order to make it possible to compare a proto2/proto3 file with its equivalent (according to proto spec) editions file. Primarily, this is the encoded descriptor.")
 	)
 	protogen.Options{
@@ -47,6 +56,15 @@ func main() {
 			return errors.New("protoc-gen-go: plugins are not supported; use 'protoc --go-grpc_out=...' to generate gRPC\n\n" +
 				"See " + grpcDocURL + " for more information.")
 		}
+		gengo.GenerateHasMethods = *generateHasMethods
+		gengo.GenerateSetMethods = *generateSetMethods
+		gengo.GenerateBuilders = *generateBuilders
+		gengo.GenerateOneofVisitors = *generateOneofVisitors
+		gengo.GenerateFieldNumbers = *generateFieldNumbers
+		gengo.GenerateLazyInit = *lazyInit
+		gengo.GenerateExplicitRegistration = *explicitRegistration
+		gengo.GenerateGroupedDecls = *groupedDecls
+		gengo.GenerateServiceDescriptors = *generateServiceDescriptors
 		for _, f := range gen.Files {
 			if f.Generate {
 				gengo.GenerateFile(gen, f)
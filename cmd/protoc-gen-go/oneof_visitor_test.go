@@ -0,0 +1,68 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"go/ast"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	gengo "google.golang.org/protobuf/cmd/protoc-gen-go/internal_gengo"
+)
+
+func TestGenerateOneofVisitors(t *testing.T) {
+	defer func(v bool) { gengo.GenerateOneofVisitors = v }(gengo.GenerateOneofVisitors)
+	gengo.GenerateOneofVisitors = true
+	f, err := generate(t, `
+		name: "visit.proto"
+		package: "visit"
+		syntax: "proto3"
+		options: {go_package: "example.com/visit"}
+		message_type: {
+			name: "M"
+			field: {name: "a" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "a" oneof_index: 0}
+			field: {name: "b" number: 2 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".visit.M" json_name: "b" oneof_index: 0}
+			field: {name: "c" number: 3 label: LABEL_OPTIONAL type: TYPE_INT32 json_name: "c" oneof_index: 2 proto3_optional: true}
+			field: {name: "x" number: 4 label: LABEL_OPTIONAL type: TYPE_INT32 json_name: "x" oneof_index: 1}
+			field: {name: "visit_other" number: 5 label: LABEL_OPTIONAL type: TYPE_INT32 json_name: "visitOther"}
+			oneof_decl: {name: "choice"}
+			oneof_decl: {name: "other"}
+			oneof_decl: {name: "_c"}
+		}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The synthetic oneof for c has no visitor, and the Visit method for
+	// other is omitted since it would clash with the visit_other field.
+	got := make(map[string][]string)
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv != nil && strings.HasPrefix(decl.Name.Name, "Visit") {
+				got["M"] = append(got["M"], decl.Name.Name)
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok {
+					if it, ok := ts.Type.(*ast.InterfaceType); ok && ts.Name.Name != "isM_Choice" && ts.Name.Name != "isM_Other" {
+						for _, m := range it.Methods.List {
+							got[ts.Name.Name] = append(got[ts.Name.Name], m.Names[0].Name)
+						}
+					}
+				}
+			}
+		}
+	}
+	want := map[string][]string{
+		"M":               {"VisitChoice"},
+		"M_ChoiceVisitor": {"VisitA", "VisitB", "VisitChoiceNotSet"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("generated visitors mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	gengo "google.golang.org/protobuf/cmd/protoc-gen-go/internal_gengo"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// openTestFile is a proto3 file whose messages use the Open API.
+const openTestFile = `
+	name: "open.proto"
+	package: "open"
+	syntax: "proto3"
+	options: {go_package: "example.com/open"}
+	message_type: {
+		name: "M"
+		field: {name: "implicit" number: 1 label: LABEL_OPTIONAL type: TYPE_INT32 json_name: "implicit"}
+		field: {name: "explicit" number: 2 label: LABEL_OPTIONAL type: TYPE_INT32 json_name: "explicit" oneof_index: 1 proto3_optional: true}
+		field: {name: "message" number: 3 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".open.M" json_name: "message"}
+		field: {name: "repeated" number: 4 label: LABEL_REPEATED type: TYPE_INT32 json_name: "repeated"}
+		field: {name: "choice_a" number: 5 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "choiceA" oneof_index: 0}
+		field: {name: "clash" number: 6 label: LABEL_OPTIONAL type: TYPE_INT32 json_name: "clash" oneof_index: 2 proto3_optional: true}
+		field: {name: "has_clash" number: 7 label: LABEL_OPTIONAL type: TYPE_INT32 json_name: "hasClash"}
+		field: {name: "set_clash" number: 8 label: LABEL_OPTIONAL type: TYPE_INT32 json_name: "setClash"}
+		oneof_decl: {name: "choice"}
+		oneof_decl: {name: "_explicit"}
+		oneof_decl: {name: "_clash"}
+	}
+`
+
+// generate runs the generator on the file described by the text-format
+// FileDescriptorProto fileDesc and returns the parsed output.
+func generate(t *testing.T, fileDesc string) (*ast.File, error) {
+	t.Helper()
+	fd := new(descriptorpb.FileDescriptorProto)
+	if err := prototext.Unmarshal([]byte(fileDesc), fd); err != nil {
+		t.Fatal(err)
+	}
+	// Include the transitive dependencies, which must be linked into the test.
+	var files []*descriptorpb.FileDescriptorProto
+	seen := make(map[string]bool)
+	var addDeps func(deps []string)
+	addDeps = func(deps []string) {
+		for _, dep := range deps {
+			if seen[dep] {
+				continue
+			}
+			seen[dep] = true
+			d, err := protoregistry.GlobalFiles.FindFileByPath(dep)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dp := protodesc.ToFileDescriptorProto(d)
+			addDeps(dp.GetDependency())
+			files = append(files, dp)
+		}
+	}
+	addDeps(fd.GetDependency())
+	gen, err := protogen.Options{}.New(&pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{fd.GetName()},
+		ProtoFile:      append(files, fd),
+		Parameter:      proto.String("paths=source_relative"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := gengo.GenerateFile(gen, gen.FilesByPath[fd.GetName()])
+	if resp := gen.Response(); resp.Error != nil {
+		return nil, errors.New(resp.GetError())
+	}
+	b, err := g.Content()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), fd.GetName()+".go", b, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f, nil
+}
+
+// generatedMethods generates openTestFile and returns the names of
+// the generated methods with the given prefix.
+func generatedMethods(t *testing.T, prefix string) []string {
+	t.Helper()
+	f, err := generate(t, openTestFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, decl := range f.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv != nil && strings.HasPrefix(fn.Name.Name, prefix) {
+			names = append(names, fn.Name.Name)
+		}
+	}
+	return names
+}
+
+func TestGenerateHasMethods(t *testing.T) {
+	defer func(v bool) { gengo.GenerateHasMethods = v }(gengo.GenerateHasMethods)
+	for _, enabled := range []bool{false, true} {
+		gengo.GenerateHasMethods = enabled
+		var want []string
+		if enabled {
+			want = []string{"HasExplicit", "HasMessage", "HasChoiceA"}
+		}
+		if diff := cmp.Diff(want, generatedMethods(t, "Has")); diff != "" {
+			t.Errorf("GenerateHasMethods=%v: Has methods mismatch (-want +got):\n%s", enabled, diff)
+		}
+	}
+}
+
+func TestGenerateSetMethods(t *testing.T) {
+	defer func(v bool) { gengo.GenerateSetMethods = v }(gengo.GenerateSetMethods)
+	for _, enabled := range []bool{false, true} {
+		gengo.GenerateSetMethods = enabled
+		var want []string
+		if enabled {
+			want = []string{"SetImplicit", "SetExplicit", "SetMessage", "SetRepeated", "SetChoiceA", "SetHasClash", "SetSetClash"}
+		}
+		if diff := cmp.Diff(want, generatedMethods(t, "Set")); diff != "" {
+			t.Errorf("GenerateSetMethods=%v: Set methods mismatch (-want +got):\n%s", enabled, diff)
+		}
+	}
+}
+
+func TestGenerateBuilders(t *testing.T) {
+	defer func(v bool) { gengo.GenerateBuilders = v }(gengo.GenerateBuilders)
+	for _, enabled := range []bool{false, true} {
+		gengo.GenerateBuilders = enabled
+		var want []string
+		if enabled {
+			want = []string{"Build"}
+		}
+		if diff := cmp.Diff(want, generatedMethods(t, "Build")); diff != "" {
+			t.Errorf("GenerateBuilders=%v: Build methods mismatch (-want +got):\n%s", enabled, diff)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	gengo "google.golang.org/protobuf/cmd/protoc-gen-go/internal_gengo"
+)
+
+func TestGenerateServiceDescriptors(t *testing.T) {
+	defer func(v bool) { gengo.GenerateServiceDescriptors = v }(gengo.GenerateServiceDescriptors)
+	gengo.GenerateServiceDescriptors = true
+	f, err := generate(t, `
+		name: "services.proto"
+		package: "services"
+		syntax: "proto3"
+		options: {go_package: "example.com/services"}
+		message_type: {name: "Request"}
+		message_type: {name: "Response"}
+		service: {
+			name: "Greeter"
+			method: {name: "Greet" input_type: ".services.Request" output_type: ".services.Response"}
+			method: {name: "GreetAll" input_type: ".services.Request" output_type: ".services.Response" server_streaming: true}
+		}
+		service: {
+			name: "Empty"
+		}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]string)
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || !strings.HasSuffix(fn.Name.Name, "Descriptor") {
+			continue
+		}
+		var b strings.Builder
+		printer.Fprint(&b, token.NewFileSet(), fn.Body.List[len(fn.Body.List)-1])
+		got[fn.Name.Name] = b.String()
+	}
+	want := map[string]string{
+		"Greeter_ServiceDescriptor":         "return File_services_proto.Services().Get(0)",
+		"Greeter_Greet_MethodDescriptor":    "return Greeter_ServiceDescriptor().Methods().Get(0)",
+		"Greeter_GreetAll_MethodDescriptor": "return Greeter_ServiceDescriptor().Methods().Get(1)",
+		"Empty_ServiceDescriptor":           "return File_services_proto.Services().Get(1)",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("generated descriptor functions mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"go/ast"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCustomStructTags(t *testing.T) {
+	f, err := generate(t, `
+		name: "tags.proto"
+		package: "tags"
+		syntax: "proto3"
+		dependency: "google/protobuf/go_options.proto"
+		options: {go_package: "example.com/tags"}
+		message_type: {
+			name: "M"
+			field: {
+				name: "user_id" number: 1 label: LABEL_OPTIONAL type: TYPE_INT64 json_name: "userId"
+				options: {[pb.go_field]: {tags: 'db:"user_id"  validate:"required,min=1"'}}
+			}
+			field: {
+				name: "name" number: 2 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "name"
+				oneof_index: 0
+				options: {[pb.go_field]: {tags: 'form:"name"'}}
+			}
+			field: {name: "plain" number: 3 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "plain"}
+			oneof_decl: {name: "choice"}
+		}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	ast.Inspect(f, func(n ast.Node) bool {
+		if field, ok := n.(*ast.Field); ok && field.Tag != nil && len(field.Names) == 1 {
+			got[field.Names[0].Name] = field.Tag.Value
+		}
+		return true
+	})
+	want := map[string]string{
+		"UserId": "`" + `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty" db:"user_id" validate:"required,min=1"` + "`",
+		"Name":   "`" + `protobuf:"bytes,2,opt,name=name,proto3,oneof" form:"name"` + "`",
+		"Plain":  "`" + `protobuf:"bytes,3,opt,name=plain,proto3" json:"plain,omitempty"` + "`",
+		"Choice": "`" + `protobuf_oneof:"choice"` + "`",
+	}
+	for name := range got {
+		if want[name] == "" {
+			delete(got, name)
+		}
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("struct tags mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCustomStructTagsErrors(t *testing.T) {
+	for _, test := range []struct {
+		tags    string
+		wantErr string
+	}{
+		{`db`, "invalid struct tag syntax"},
+		{`db:user_id`, `invalid value for struct tag key "db"`},
+		{`db:"a" db:"b"`, `duplicate struct tag key "db"`},
+		{`json:"name"`, `struct tag key "json" is reserved`},
+		{`protobuf:"bytes"`, `struct tag key "protobuf" is reserved`},
+	} {
+		_, err := generate(t, `
+			name: "tags.proto"
+			package: "tags"
+			syntax: "proto3"
+			dependency: "google/protobuf/go_options.proto"
+			options: {go_package: "example.com/tags"}
+			message_type: {
+				name: "M"
+				field: {
+					name: "f" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "f"
+					options: {[pb.go_field]: {tags: '`+test.tags+`'}}
+				}
+			}
+		`)
+		if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+			t.Errorf("tags %q: got error %v, want %q", test.tags, err, test.wantErr)
+		}
+	}
+}
@@ -24,6 +24,7 @@ import (
 	_ "google.golang.org/protobuf/cmd/protoc-gen-go/testdata/imports/test_a_2"
 	_ "google.golang.org/protobuf/cmd/protoc-gen-go/testdata/imports/test_b_1"
 	_ "google.golang.org/protobuf/cmd/protoc-gen-go/testdata/issue780_oneof_conflict"
+	_ "google.golang.org/protobuf/cmd/protoc-gen-go/testdata/lazyinit"
 	_ "google.golang.org/protobuf/cmd/protoc-gen-go/testdata/nameclash"
 	_ "google.golang.org/protobuf/cmd/protoc-gen-go/testdata/nopackage"
 	_ "google.golang.org/protobuf/cmd/protoc-gen-go/testdata/proto2"
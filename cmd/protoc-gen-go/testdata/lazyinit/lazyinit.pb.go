@@ -0,0 +1,253 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: cmd/protoc-gen-go/testdata/lazyinit/lazyinit.proto
+
+package lazyinit
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Enum int32
+
+const (
+	Enum_ZERO Enum = 0
+	Enum_ONE  Enum = 1
+)
+
+// Enum value maps for Enum.
+var (
+	Enum_name = map[int32]string{
+		0: "ZERO",
+		1: "ONE",
+	}
+	Enum_value = map[string]int32{
+		"ZERO": 0,
+		"ONE":  1,
+	}
+)
+
+func (x Enum) Enum() *Enum {
+	p := new(Enum)
+	*p = x
+	return p
+}
+
+func (x Enum) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Enum) Descriptor() protoreflect.EnumDescriptor {
+	file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_lazyInit()
+	return file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_enumTypes[0].Descriptor()
+}
+
+func (Enum) Type() protoreflect.EnumType {
+	file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_lazyInit()
+	return &file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_enumTypes[0]
+}
+
+func (x Enum) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Enum.Descriptor instead.
+func (Enum) EnumDescriptor() ([]byte, []int) {
+	return file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_rawDescGZIP(), []int{0}
+}
+
+type Message struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	E             Enum                   `protobuf:"varint,1,opt,name=e,proto3,enum=goproto.protoc.lazyinit.Enum" json:"e,omitempty"`
+	Child         *Message               `protobuf:"bytes,2,opt,name=child,proto3" json:"child,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Message) Reset() {
+	*x = Message{}
+	file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_lazyInit()
+	mi := &file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Message) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Message) ProtoMessage() {}
+
+func (x *Message) ProtoReflect() protoreflect.Message {
+	file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_lazyInit()
+	mi := &file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Message.ProtoReflect.Descriptor instead.
+func (*Message) Descriptor() ([]byte, []int) {
+	return file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Message) GetE() Enum {
+	if x != nil {
+		return x.E
+	}
+	return Enum_ZERO
+}
+
+func (x *Message) GetChild() *Message {
+	if x != nil {
+		return x.Child
+	}
+	return nil
+}
+
+type Other struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	S             string                 `protobuf:"bytes,1,opt,name=s,proto3" json:"s,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Other) Reset() {
+	*x = Other{}
+	file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_lazyInit()
+	mi := &file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Other) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Other) ProtoMessage() {}
+
+func (x *Other) ProtoReflect() protoreflect.Message {
+	file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_lazyInit()
+	mi := &file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Other.ProtoReflect.Descriptor instead.
+func (*Other) Descriptor() ([]byte, []int) {
+	return file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Other) GetS() string {
+	if x != nil {
+		return x.S
+	}
+	return ""
+}
+
+var File_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto protoreflect.FileDescriptor
+
+var file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_rawDesc = string([]byte{
+	0x0a, 0x32, 0x63, 0x6d, 0x64, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x2d, 0x67, 0x65, 0x6e,
+	0x2d, 0x67, 0x6f, 0x2f, 0x74, 0x65, 0x73, 0x74, 0x64, 0x61, 0x74, 0x61, 0x2f, 0x6c, 0x61, 0x7a,
+	0x79, 0x69, 0x6e, 0x69, 0x74, 0x2f, 0x6c, 0x61, 0x7a, 0x79, 0x69, 0x6e, 0x69, 0x74, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x17, 0x67, 0x6f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x63, 0x2e, 0x6c, 0x61, 0x7a, 0x79, 0x69, 0x6e, 0x69, 0x74, 0x22, 0x6e, 0x0a,
+	0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x2b, 0x0a, 0x01, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x1d, 0x2e, 0x67, 0x6f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x63, 0x2e, 0x6c, 0x61, 0x7a, 0x79, 0x69, 0x6e, 0x69, 0x74, 0x2e, 0x45, 0x6e,
+	0x75, 0x6d, 0x52, 0x01, 0x65, 0x12, 0x36, 0x0a, 0x05, 0x63, 0x68, 0x69, 0x6c, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x67, 0x6f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x63, 0x2e, 0x6c, 0x61, 0x7a, 0x79, 0x69, 0x6e, 0x69, 0x74, 0x2e, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x05, 0x63, 0x68, 0x69, 0x6c, 0x64, 0x22, 0x15, 0x0a,
+	0x05, 0x4f, 0x74, 0x68, 0x65, 0x72, 0x12, 0x0c, 0x0a, 0x01, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x01, 0x73, 0x2a, 0x19, 0x0a, 0x04, 0x45, 0x6e, 0x75, 0x6d, 0x12, 0x08, 0x0a, 0x04,
+	0x5a, 0x45, 0x52, 0x4f, 0x10, 0x00, 0x12, 0x07, 0x0a, 0x03, 0x4f, 0x4e, 0x45, 0x10, 0x01, 0x42,
+	0x40, 0x5a, 0x3e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x67, 0x6f, 0x6c, 0x61, 0x6e, 0x67,
+	0x2e, 0x6f, 0x72, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x63, 0x6d,
+	0x64, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x2d, 0x67, 0x65, 0x6e, 0x2d, 0x67, 0x6f, 0x2f,
+	0x74, 0x65, 0x73, 0x74, 0x64, 0x61, 0x74, 0x61, 0x2f, 0x6c, 0x61, 0x7a, 0x79, 0x69, 0x6e, 0x69,
+	0x74, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+})
+
+var (
+	file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_rawDescOnce sync.Once
+	file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_rawDescData []byte
+)
+
+func file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_rawDescGZIP() []byte {
+	file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_rawDescOnce.Do(func() {
+		file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_rawDesc), len(file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_rawDesc)))
+	})
+	return file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_rawDescData
+}
+
+var file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_goTypes = []any{
+	(Enum)(0),       // 0: goproto.protoc.lazyinit.Enum
+	(*Message)(nil), // 1: goproto.protoc.lazyinit.Message
+	(*Other)(nil),   // 2: goproto.protoc.lazyinit.Other
+}
+var file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_depIdxs = []int32{
+	0, // 0: goproto.protoc.lazyinit.Message.e:type_name -> goproto.protoc.lazyinit.Enum
+	1, // 1: goproto.protoc.lazyinit.Message.child:type_name -> goproto.protoc.lazyinit.Message
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+var file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_initOnce sync.Once
+
+func file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_lazyInit() {
+	file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_initOnce.Do(file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_init)
+}
+func file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_init() {
+	if File_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_rawDesc), len(file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_goTypes,
+		DependencyIndexes: file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_depIdxs,
+		EnumInfos:         file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_enumTypes,
+		MessageInfos:      file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_msgTypes,
+	}.Build()
+	File_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto = out.File
+	file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_goTypes = nil
+	file_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto_depIdxs = nil
+}
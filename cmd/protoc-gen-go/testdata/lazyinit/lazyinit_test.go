@@ -0,0 +1,63 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lazyinit_test
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"google.golang.org/protobuf/cmd/protoc-gen-go/testdata/lazyinit"
+)
+
+func TestLazyInit(t *testing.T) {
+	const (
+		path  = "cmd/protoc-gen-go/testdata/lazyinit/lazyinit.proto"
+		other = "goproto.protoc.lazyinit.Other"
+	)
+	if lazyinit.File_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto != nil {
+		t.Fatalf("file descriptor was built before first use")
+	}
+	if _, err := protoregistry.GlobalFiles.FindFileByPath(path); err == nil {
+		t.Fatalf("%v was registered before first use", path)
+	}
+	if _, err := protoregistry.GlobalTypes.FindMessageByName(other); err == nil {
+		t.Fatalf("%v was registered before first use", other)
+	}
+
+	// Using one message initializes the whole file.
+	m := &lazyinit.Message{E: lazyinit.Enum_ONE, Child: &lazyinit.Message{}}
+	b, err := proto.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fd := lazyinit.File_cmd_protoc_gen_go_testdata_lazyinit_lazyinit_proto
+	if fd == nil {
+		t.Fatalf("file descriptor was not built on first use")
+	}
+	if got, err := protoregistry.GlobalFiles.FindFileByPath(path); err != nil || got != fd {
+		t.Errorf("FindFileByPath(%q) = %v, %v; want the generated file descriptor", path, got, err)
+	}
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(other)
+	if err != nil {
+		t.Fatalf("FindMessageByName(%q) = %v", other, err)
+	}
+	if got, want := mt.Descriptor(), (&lazyinit.Other{}).ProtoReflect().Descriptor(); got != want {
+		t.Errorf("FindMessageByName(%q) descriptor = %v, want %v", other, got.FullName(), want.FullName())
+	}
+	if got, want := lazyinit.Enum_ONE.Descriptor().ParentFile(), fd; got != want {
+		t.Errorf("enum descriptor belongs to %v, want %v", got.Path(), want.Path())
+	}
+
+	got := &lazyinit.Message{}
+	if err := proto.Unmarshal(b, got); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, m) {
+		t.Errorf("Unmarshal(Marshal(m)) = %v, want %v", got, m)
+	}
+}
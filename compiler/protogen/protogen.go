@@ -110,6 +110,9 @@ type Plugin struct {
 	// google.protobuf.CodeGeneratorResponse.supported_features for details.
 	SupportedFeatures uint64
 
+	// SupportedEditionsMinimum and SupportedEditionsMaximum are the range of
+	// editions supported by this generator plugin. They are only reported if
+	// both are set. See [Plugin.SupportEditions].
 	SupportedEditionsMinimum descriptorpb.Edition
 	SupportedEditionsMaximum descriptorpb.Edition
 
@@ -399,6 +402,31 @@ func (gen *Plugin) InternalStripForEditionsDiff() bool {
 	return gen.opts.InternalStripForEditionsDiff != nil && *gen.opts.InternalStripForEditionsDiff
 }
 
+// SupportEditions declares that the plugin supports the editions from
+// minimum to maximum, inclusive. It adds FEATURE_SUPPORTS_EDITIONS to
+// SupportedFeatures and sets SupportedEditionsMinimum and
+// SupportedEditionsMaximum.
+//
+// It reports an error if the range is invalid or if a file to generate
+// uses an edition outside of the range.
+func (gen *Plugin) SupportEditions(minimum, maximum descriptorpb.Edition) error {
+	if minimum == descriptorpb.Edition_EDITION_UNKNOWN || minimum > maximum {
+		return fmt.Errorf("invalid range of supported editions: %v to %v", minimum, maximum)
+	}
+	gen.SupportedFeatures |= uint64(pluginpb.CodeGeneratorResponse_FEATURE_SUPPORTS_EDITIONS)
+	gen.SupportedEditionsMinimum = minimum
+	gen.SupportedEditionsMaximum = maximum
+	for _, f := range gen.Files {
+		if !f.Generate || f.Desc.Syntax() != protoreflect.Editions {
+			continue
+		}
+		if edition := f.Proto.GetEdition(); edition < minimum || edition > maximum {
+			return fmt.Errorf("%v: %v is not supported; supported editions are %v to %v", f.Desc.Path(), edition, minimum, maximum)
+		}
+	}
+	return nil
+}
+
 // Error records an error in code generation. The generator will report the
 // error back to protoc and will not produce output.
 func (gen *Plugin) Error(err error) {
@@ -422,6 +450,10 @@ func (gen *Plugin) Response() *pluginpb.CodeGeneratorResponse {
 	if gen.SupportedEditionsMinimum != descriptorpb.Edition_EDITION_UNKNOWN && gen.SupportedEditionsMaximum != descriptorpb.Edition_EDITION_UNKNOWN {
 		resp.MinimumEdition = proto.Int32(int32(gen.SupportedEditionsMinimum))
 		resp.MaximumEdition = proto.Int32(int32(gen.SupportedEditionsMaximum))
+		if gen.SupportedEditionsMinimum > gen.SupportedEditionsMaximum && gen.err == nil {
+			resp.Error = proto.String(fmt.Sprintf("invalid range of supported editions: %v to %v", gen.SupportedEditionsMinimum, gen.SupportedEditionsMaximum))
+			return resp
+		}
 	}
 
 	if gen.err != nil {
@@ -492,6 +524,7 @@ type File struct {
 	GeneratedFilenamePrefix string
 
 	location Location
+	gen      *Plugin
 
 	// APILevel specifies which API to generate. One of OPEN, HYBRID or OPAQUE.
 	APILevel gofeaturespb.GoFeatures_APILevel
@@ -515,6 +548,7 @@ func newFile(gen *Plugin, p *descriptorpb.FileDescriptorProto, packageName GoPac
 		GoPackageName: packageName,
 		GoImportPath:  importPath,
 		location:      Location{SourceFile: desc.Path()},
+		gen:           gen,
 
 		APILevel: fileAPILevel(desc, defaultAPILevel),
 	}
@@ -579,6 +613,23 @@ func splitImportPathAndPackageName(s string) (GoImportPath, GoPackageName) {
 	return GoImportPath(s), ""
 }
 
+// CommentsAt returns the comments attached to the source location
+// with the given path, such as the syntax or package statement or an option.
+// It returns an empty set if the file has no such location.
+//
+// For declarations, the Comments field of the corresponding
+// Enum, EnumValue, Message, Field, Oneof, Service or Method
+// contains the same comments.
+func (f *File) CommentsAt(path protoreflect.SourcePath) CommentSet {
+	return makeCommentSet(f.gen, f.Desc.SourceLocations().ByPath(path))
+}
+
+// CommentsOf returns the comments attached to the declaration of d,
+// which must be declared in this file.
+func (f *File) CommentsOf(d protoreflect.Descriptor) CommentSet {
+	return makeCommentSet(f.gen, f.Desc.SourceLocations().ByDescriptor(d))
+}
+
 // An Enum describes an enum.
 type Enum struct {
 	Desc protoreflect.EnumDescriptor
@@ -1077,10 +1128,12 @@ type GeneratedFile struct {
 	filename             string
 	goImportPath         GoImportPath
 	buf                  bytes.Buffer
-	packageNames         map[GoImportPath]GoPackageName
+	packageNames         map[GoImportPath]GoPackageName // imported by this file
+	sharedPackageNames   map[GoImportPath]GoPackageName // assigned in this file or its siblings
 	usedPackageNames     map[GoPackageName]bool
 	manualImports        map[GoImportPath]bool
 	annotations          map[string][]Annotation
+	postProcessors       []func([]byte) ([]byte, error)
 	stripForEditionsDiff bool
 }
 
@@ -1092,6 +1145,7 @@ func (gen *Plugin) NewGeneratedFile(filename string, goImportPath GoImportPath)
 		filename:             filename,
 		goImportPath:         goImportPath,
 		packageNames:         make(map[GoImportPath]GoPackageName),
+		sharedPackageNames:   make(map[GoImportPath]GoPackageName),
 		usedPackageNames:     make(map[GoPackageName]bool),
 		manualImports:        make(map[GoImportPath]bool),
 		annotations:          make(map[string][]Annotation),
@@ -1107,6 +1161,24 @@ func (gen *Plugin) NewGeneratedFile(filename string, goImportPath GoImportPath)
 	return g
 }
 
+// NewSiblingFile creates a new generated file with the given filename
+// in the same Go package as g, such as a sidecar file containing helpers
+// for the types generated in g:
+//
+//	helpers := g.NewSiblingFile(file.GeneratedFilenamePrefix + "_helpers.pb.go")
+//
+// The names by which imported packages are referred to are shared by g and
+// all of its siblings. A package imported by several of the files is referred
+// to by the same name in each of them, and the name of a package imported by
+// one file never conflicts with that of a different package imported by
+// another. Each file only imports the packages that it refers to.
+func (g *GeneratedFile) NewSiblingFile(filename string) *GeneratedFile {
+	s := g.gen.NewGeneratedFile(filename, g.goImportPath)
+	s.sharedPackageNames = g.sharedPackageNames
+	s.usedPackageNames = g.usedPackageNames
+	return s
+}
+
 // P prints a line to the generated output. It converts each parameter to a
 // string following the same rules as [fmt.Print]. It never inserts spaces
 // between parameters.
@@ -1134,12 +1206,16 @@ func (g *GeneratedFile) QualifiedGoIdent(ident GoIdent) string {
 	if packageName, ok := g.packageNames[ident.GoImportPath]; ok {
 		return string(packageName) + "." + ident.GoName
 	}
-	packageName := cleanPackageName(path.Base(string(ident.GoImportPath)))
-	for i, orig := 1, packageName; g.usedPackageNames[packageName]; i++ {
-		packageName = orig + GoPackageName(strconv.Itoa(i))
+	packageName, ok := g.sharedPackageNames[ident.GoImportPath]
+	if !ok {
+		packageName = cleanPackageName(path.Base(string(ident.GoImportPath)))
+		for i, orig := 1, packageName; g.usedPackageNames[packageName]; i++ {
+			packageName = orig + GoPackageName(strconv.Itoa(i))
+		}
+		g.sharedPackageNames[ident.GoImportPath] = packageName
+		g.usedPackageNames[packageName] = true
 	}
 	g.packageNames[ident.GoImportPath] = packageName
-	g.usedPackageNames[packageName] = true
 	return string(packageName) + "." + ident.GoName
 }
 
@@ -1213,8 +1289,32 @@ func (g *GeneratedFile) AnnotateSymbol(symbol string, info Annotation) {
 	g.annotations[symbol] = append(g.annotations[symbol], info)
 }
 
+// AddPostProcessor registers a function which transforms the contents of
+// the generated file. Post-processors are called in the order in which they
+// were added, after Go source has been reformatted and its imports resolved.
+// An error returned by a post-processor is returned by Content.
+//
+// When code annotations are enabled, the contents returned by the final
+// post-processor must remain valid Go source.
+func (g *GeneratedFile) AddPostProcessor(f func([]byte) ([]byte, error)) {
+	g.postProcessors = append(g.postProcessors, f)
+}
+
 // Content returns the contents of the generated file.
 func (g *GeneratedFile) Content() ([]byte, error) {
+	content, err := g.content()
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range g.postProcessors {
+		if content, err = f(content); err != nil {
+			return nil, fmt.Errorf("%v: %v", g.filename, err)
+		}
+	}
+	return content, nil
+}
+
+func (g *GeneratedFile) content() ([]byte, error) {
 	if !strings.HasSuffix(g.filename, ".go") {
 		return g.buf.Bytes(), nil
 	}
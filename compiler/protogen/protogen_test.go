@@ -5,6 +5,8 @@
 package protogen
 
 import (
+	"bytes"
+	"errors"
 	"flag"
 	"fmt"
 	"testing"
@@ -13,10 +15,12 @@ import (
 
 	"google.golang.org/protobuf/internal/genid"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/testing/protocmp"
 
 	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/gofeaturespb"
 	"google.golang.org/protobuf/types/pluginpb"
 )
 
@@ -318,6 +322,62 @@ var _ = string1.X // "golang.org/z/string"
 	}
 }
 
+func TestSiblingFileImports(t *testing.T) {
+	gen, err := Options{}.New(&pluginpb.CodeGeneratorRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := gen.NewGeneratedFile("foo.pb.go", "golang.org/x/foo")
+	g.P("package foo")
+	g.P()
+	g.P("var _ = ", GoIdent{GoName: "X", GoImportPath: "golang.org/x/bar"})
+	s := g.NewSiblingFile("foo_helpers.pb.go")
+	s.P("package foo")
+	s.P()
+	s.P("var _ = ", GoIdent{GoName: "X", GoImportPath: "golang.org/y/bar"})
+	s.P("var _ = ", GoIdent{GoName: "X", GoImportPath: "golang.org/x/bar"})
+	s.P("var _ = ", GoIdent{GoName: "X", GoImportPath: "golang.org/x/foo"})
+	g.P("var _ = ", GoIdent{GoName: "X", GoImportPath: "golang.org/y/bar"})
+
+	for _, tt := range []struct {
+		g    *GeneratedFile
+		want string
+	}{{
+		g: g,
+		want: `package foo
+
+import (
+	bar "golang.org/x/bar"
+	bar1 "golang.org/y/bar"
+)
+
+var _ = bar.X
+var _ = bar1.X
+`,
+	}, {
+		g: s,
+		want: `package foo
+
+import (
+	bar "golang.org/x/bar"
+	bar1 "golang.org/y/bar"
+)
+
+var _ = bar1.X
+var _ = bar.X
+var _ = X
+`,
+	}} {
+		got, err := tt.g.Content()
+		if err != nil {
+			t.Fatalf("%v: Content() = %v", tt.g.filename, err)
+		}
+		if diff := cmp.Diff(tt.want, string(got)); diff != "" {
+			t.Errorf("%v: content mismatch (-want +got):\n%s", tt.g.filename, diff)
+		}
+	}
+}
+
 func TestImportRewrites(t *testing.T) {
 	gen, err := Options{
 		ImportRewriteFunc: func(i GoImportPath) GoImportPath {
@@ -416,3 +476,237 @@ func TestAnnotations(t *testing.T) {
 		t.Fatalf("GeneratedCodeInfo mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestComments(t *testing.T) {
+	loc := func(path []int32, leading, trailing string, detached ...string) *descriptorpb.SourceCodeInfo_Location {
+		return &descriptorpb.SourceCodeInfo_Location{
+			Path:                    path,
+			Span:                    []int32{0, 0, 0},
+			LeadingComments:         proto.String(leading),
+			TrailingComments:        proto.String(trailing),
+			LeadingDetachedComments: detached,
+		}
+	}
+	gen, err := Options{}.New(&pluginpb.CodeGeneratorRequest{
+		ProtoFile: []*descriptorpb.FileDescriptorProto{{
+			Name:    proto.String("dir/foo.proto"),
+			Syntax:  proto.String(protoreflect.Proto2.String()),
+			Package: proto.String("foo"),
+			Options: &descriptorpb.FileOptions{GoPackage: proto.String("golang.org/x/foo")},
+			MessageType: []*descriptorpb.DescriptorProto{{
+				Name:           proto.String("M"),
+				ExtensionRange: []*descriptorpb.DescriptorProto_ExtensionRange{{Start: proto.Int32(1), End: proto.Int32(2)}},
+			}},
+			EnumType: []*descriptorpb.EnumDescriptorProto{{
+				Name:  proto.String("E"),
+				Value: []*descriptorpb.EnumValueDescriptorProto{{Name: proto.String("V"), Number: proto.Int32(0)}},
+			}},
+			Extension: []*descriptorpb.FieldDescriptorProto{{
+				Name:     proto.String("x"),
+				Number:   proto.Int32(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(),
+				Extendee: proto.String(".foo.M"),
+			}},
+			Service: []*descriptorpb.ServiceDescriptorProto{{
+				Name: proto.String("S"),
+				Method: []*descriptorpb.MethodDescriptorProto{{
+					Name:       proto.String("Do"),
+					InputType:  proto.String(".foo.M"),
+					OutputType: proto.String(".foo.M"),
+					Options:    &descriptorpb.MethodOptions{Deprecated: proto.Bool(true)},
+				}},
+			}},
+			SourceCodeInfo: &descriptorpb.SourceCodeInfo{
+				Location: []*descriptorpb.SourceCodeInfo_Location{
+					loc([]int32{2}, " package\n", "", " detached\n"),
+					loc([]int32{5, 0, 2, 0}, " value\n", " value trailing\n"),
+					loc([]int32{7, 0}, " extension\n", " extension trailing\n"),
+					loc([]int32{6, 0}, " service\n", " service trailing\n"),
+					loc([]int32{6, 0, 2, 0, 4, 33}, " method option\n", ""),
+				},
+			},
+		}},
+		FileToGenerate: []string{"dir/foo.proto"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := gen.FilesByPath["dir/foo.proto"]
+	method := f.Services[0].Methods[0]
+	for _, test := range []struct {
+		desc string
+		got  CommentSet
+		want CommentSet
+	}{{
+		desc: "package",
+		got:  f.CommentsAt(protoreflect.SourcePath{2}),
+		want: CommentSet{LeadingDetached: []Comments{" detached\n"}, Leading: " package\n"},
+	}, {
+		desc: "enum value",
+		got:  f.Enums[0].Values[0].Comments,
+		want: CommentSet{Leading: " value\n", Trailing: " value trailing\n"},
+	}, {
+		desc: "extension",
+		got:  f.Extensions[0].Comments,
+		want: CommentSet{Leading: " extension\n", Trailing: " extension trailing\n"},
+	}, {
+		desc: "service",
+		got:  f.CommentsOf(f.Services[0].Desc),
+		want: CommentSet{Leading: " service\n", Trailing: " service trailing\n"},
+	}, {
+		desc: "method option",
+		got:  f.CommentsAt(append(method.Location.Path, 4, 33)),
+		want: CommentSet{Leading: " method option\n"},
+	}, {
+		desc: "missing",
+		got:  f.CommentsAt(protoreflect.SourcePath{8}),
+		want: CommentSet{},
+	}} {
+		if diff := cmp.Diff(test.want, test.got); diff != "" {
+			t.Errorf("%v comments mismatch (-want +got):\n%s", test.desc, diff)
+		}
+	}
+}
+
+func TestSupportEditions(t *testing.T) {
+	newPlugin := func(t *testing.T) *Plugin {
+		gen, err := Options{}.New(&pluginpb.CodeGeneratorRequest{
+			ProtoFile: []*descriptorpb.FileDescriptorProto{{
+				Name:    proto.String("foo.proto"),
+				Syntax:  proto.String("editions"),
+				Edition: descriptorpb.Edition_EDITION_2023.Enum(),
+				Options: &descriptorpb.FileOptions{GoPackage: proto.String("golang.org/x/foo")},
+			}},
+			FileToGenerate: []string{"foo.proto"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return gen
+	}
+
+	gen := newPlugin(t)
+	if err := gen.SupportEditions(descriptorpb.Edition_EDITION_PROTO2, descriptorpb.Edition_EDITION_2023); err != nil {
+		t.Fatalf("SupportEditions(PROTO2, 2023) = %v, want nil", err)
+	}
+	resp := gen.Response()
+	want := &pluginpb.CodeGeneratorResponse{
+		SupportedFeatures: proto.Uint64(uint64(pluginpb.CodeGeneratorResponse_FEATURE_SUPPORTS_EDITIONS)),
+		MinimumEdition:    proto.Int32(int32(descriptorpb.Edition_EDITION_PROTO2)),
+		MaximumEdition:    proto.Int32(int32(descriptorpb.Edition_EDITION_2023)),
+	}
+	if diff := cmp.Diff(want, resp, protocmp.Transform()); diff != "" {
+		t.Errorf("Response() mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, test := range []struct {
+		minimum, maximum descriptorpb.Edition
+	}{
+		{descriptorpb.Edition_EDITION_UNKNOWN, descriptorpb.Edition_EDITION_2023},
+		{descriptorpb.Edition_EDITION_2023, descriptorpb.Edition_EDITION_PROTO3},
+		{descriptorpb.Edition_EDITION_PROTO2, descriptorpb.Edition_EDITION_PROTO3},
+	} {
+		if err := newPlugin(t).SupportEditions(test.minimum, test.maximum); err == nil {
+			t.Errorf("SupportEditions(%v, %v) = nil, want error", test.minimum, test.maximum)
+		}
+	}
+
+	gen = newPlugin(t)
+	gen.SupportedEditionsMinimum = descriptorpb.Edition_EDITION_2023
+	gen.SupportedEditionsMaximum = descriptorpb.Edition_EDITION_PROTO2
+	if resp := gen.Response(); resp.Error == nil {
+		t.Errorf("Response() with invalid edition range: want error, got nil")
+	}
+}
+
+func TestAPILevel(t *testing.T) {
+	goFeatures := func(level gofeaturespb.GoFeatures_APILevel) *descriptorpb.FeatureSet {
+		fs := &descriptorpb.FeatureSet{}
+		proto.SetExtension(fs, gofeaturespb.E_Go, &gofeaturespb.GoFeatures{ApiLevel: level.Enum()})
+		return fs
+	}
+	newFile := func(name string, edition bool, features *descriptorpb.FeatureSet, messages ...*descriptorpb.DescriptorProto) *descriptorpb.FileDescriptorProto {
+		fd := &descriptorpb.FileDescriptorProto{
+			Name:        proto.String(name),
+			Syntax:      proto.String("proto3"),
+			Options:     &descriptorpb.FileOptions{GoPackage: proto.String("golang.org/x/foo"), Features: features},
+			MessageType: messages,
+		}
+		if edition {
+			fd.Syntax = proto.String("editions")
+			fd.Edition = descriptorpb.Edition_EDITION_2023.Enum()
+			fd.Dependency = []string{"google/protobuf/go_features.proto"}
+		}
+		return fd
+	}
+	gen, err := Options{}.New(&pluginpb.CodeGeneratorRequest{
+		Parameter: proto.String("default_api_level=API_HYBRID,apilevelMb.proto=API_OPAQUE"),
+		ProtoFile: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(descriptorpb.File_google_protobuf_descriptor_proto),
+			protodesc.ToFileDescriptorProto(gofeaturespb.File_google_protobuf_go_features_proto),
+			newFile("a.proto", false, nil, &descriptorpb.DescriptorProto{Name: proto.String("A")}),
+			newFile("b.proto", false, nil, &descriptorpb.DescriptorProto{Name: proto.String("B")}),
+			newFile("c.proto", true, goFeatures(gofeaturespb.GoFeatures_API_OPEN),
+				&descriptorpb.DescriptorProto{Name: proto.String("C1")},
+				&descriptorpb.DescriptorProto{
+					Name:       proto.String("C2"),
+					Options:    &descriptorpb.MessageOptions{Features: goFeatures(gofeaturespb.GoFeatures_API_OPAQUE)},
+					NestedType: []*descriptorpb.DescriptorProto{{Name: proto.String("Nested")}},
+				}),
+		},
+		FileToGenerate: []string{"a.proto", "b.proto", "c.proto"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		desc string
+		got  gofeaturespb.GoFeatures_APILevel
+		want gofeaturespb.GoFeatures_APILevel
+	}{
+		{"file with default_api_level", gen.FilesByPath["a.proto"].APILevel, gofeaturespb.GoFeatures_API_HYBRID},
+		{"message with default_api_level", gen.FilesByPath["a.proto"].Messages[0].APILevel, gofeaturespb.GoFeatures_API_HYBRID},
+		{"file with apilevelM", gen.FilesByPath["b.proto"].APILevel, gofeaturespb.GoFeatures_API_OPAQUE},
+		{"message with apilevelM", gen.FilesByPath["b.proto"].Messages[0].APILevel, gofeaturespb.GoFeatures_API_OPAQUE},
+		{"file with api_level feature", gen.FilesByPath["c.proto"].APILevel, gofeaturespb.GoFeatures_API_OPEN},
+		{"message inheriting file feature", gen.FilesByPath["c.proto"].Messages[0].APILevel, gofeaturespb.GoFeatures_API_OPEN},
+		{"message with api_level feature", gen.FilesByPath["c.proto"].Messages[1].APILevel, gofeaturespb.GoFeatures_API_OPAQUE},
+		{"nested message inheriting message feature", gen.FilesByPath["c.proto"].Messages[1].Messages[0].APILevel, gofeaturespb.GoFeatures_API_OPAQUE},
+	} {
+		if test.got != test.want {
+			t.Errorf("%s: APILevel = %v, want %v", test.desc, test.got, test.want)
+		}
+	}
+}
+
+func TestPostProcessors(t *testing.T) {
+	gen, err := Options{}.New(&pluginpb.CodeGeneratorRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := gen.NewGeneratedFile("foo.go", "golang.org/x/foo")
+	g.P("package foo")
+	g.P("var   X = 1")
+	g.AddPostProcessor(func(b []byte) ([]byte, error) {
+		return append([]byte("//go:build linux\n\n"), b...), nil
+	})
+	g.AddPostProcessor(func(b []byte) ([]byte, error) {
+		return bytes.ReplaceAll(b, []byte("X"), []byte("Y")), nil
+	})
+	got, err := g.Content()
+	if err != nil {
+		t.Fatalf("g.Content() = %v", err)
+	}
+	want := "//go:build linux\n\npackage foo\n\nvar Y = 1\n"
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("g.Content() mismatch (-want +got):\n%s", diff)
+	}
+
+	g.AddPostProcessor(func([]byte) ([]byte, error) {
+		return nil, errors.New("failed")
+	})
+	if _, err := g.Content(); err == nil {
+		t.Errorf("g.Content() with failing post-processor: want error, got nil")
+	}
+}
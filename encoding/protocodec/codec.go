@@ -0,0 +1,145 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package protocodec provides a common interface to the serialization formats
+// of protobuf messages, and a registry of codecs by name.
+//
+// It allows frameworks and transports to select an encoding generically,
+// such as by the subtype of a content-type header:
+//
+//	c := protocodec.Lookup("json")
+//	if c == nil {
+//		return fmt.Errorf("unsupported encoding")
+//	}
+//	b, err := c.Marshal(m)
+package protocodec
+
+import (
+	"sort"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec marshals and unmarshals messages in a particular format.
+type Codec interface {
+	// Name returns the name of the format, such as "proto" or "json".
+	Name() string
+
+	// Marshal returns the encoding of m.
+	Marshal(m proto.Message) ([]byte, error)
+
+	// Unmarshal parses b into m.
+	Unmarshal(b []byte, m proto.Message) error
+}
+
+// Wire is a [Codec] for the protobuf binary wire format,
+// implemented by the proto package. Its name is "proto".
+type Wire struct {
+	MarshalOptions   proto.MarshalOptions
+	UnmarshalOptions proto.UnmarshalOptions
+}
+
+// Name returns "proto".
+func (Wire) Name() string { return "proto" }
+
+// Marshal returns the wire-format encoding of m.
+func (c Wire) Marshal(m proto.Message) ([]byte, error) {
+	return c.MarshalOptions.Marshal(m)
+}
+
+// Unmarshal parses the wire-format message in b and places the result in m.
+func (c Wire) Unmarshal(b []byte, m proto.Message) error {
+	return c.UnmarshalOptions.Unmarshal(b, m)
+}
+
+// JSON is a [Codec] for the protobuf JSON format,
+// implemented by the protojson package. Its name is "json".
+type JSON struct {
+	MarshalOptions   protojson.MarshalOptions
+	UnmarshalOptions protojson.UnmarshalOptions
+}
+
+// Name returns "json".
+func (JSON) Name() string { return "json" }
+
+// Marshal returns the JSON encoding of m.
+func (c JSON) Marshal(m proto.Message) ([]byte, error) {
+	return c.MarshalOptions.Marshal(m)
+}
+
+// Unmarshal parses the JSON-encoded message in b and places the result in m.
+func (c JSON) Unmarshal(b []byte, m proto.Message) error {
+	return c.UnmarshalOptions.Unmarshal(b, m)
+}
+
+// Text is a [Codec] for the protobuf text format,
+// implemented by the prototext package. Its name is "textproto".
+type Text struct {
+	MarshalOptions   prototext.MarshalOptions
+	UnmarshalOptions prototext.UnmarshalOptions
+}
+
+// Name returns "textproto".
+func (Text) Name() string { return "textproto" }
+
+// Marshal returns the textproto encoding of m.
+func (c Text) Marshal(m proto.Message) ([]byte, error) {
+	return c.MarshalOptions.Marshal(m)
+}
+
+// Unmarshal parses the textproto-encoded message in b and places the result in m.
+func (c Text) Unmarshal(b []byte, m proto.Message) error {
+	return c.UnmarshalOptions.Unmarshal(b, m)
+}
+
+var (
+	mu     sync.RWMutex
+	codecs = map[string]Codec{
+		Wire{}.Name(): Wire{},
+		JSON{}.Name(): JSON{},
+		Text{}.Name(): Text{},
+	}
+)
+
+// Register registers c under its name, replacing any codec previously
+// registered with the same name. The [Wire], [JSON], and [Text] codecs with
+// default options are registered initially, and may be replaced to change
+// the options used by all users of the registry.
+//
+// Register panics if c is nil or its name is empty.
+func Register(c Codec) {
+	if c == nil {
+		panic("protocodec: Register of nil codec")
+	}
+	name := c.Name()
+	if name == "" {
+		panic("protocodec: Register of codec with empty name")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	codecs[name] = c
+}
+
+// Lookup returns the codec registered with the given name,
+// or nil if there is none.
+func Lookup(name string) Codec {
+	mu.RLock()
+	defer mu.RUnlock()
+	return codecs[name]
+}
+
+// Names returns the sorted names of all registered codecs.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(codecs))
+	for name := range codecs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
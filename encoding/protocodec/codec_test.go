@@ -0,0 +1,89 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protocodec_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/protobuf/encoding/protocodec"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/internal/detrand"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	testpb "google.golang.org/protobuf/internal/testprotos/test"
+)
+
+func init() { detrand.Disable() }
+
+func TestRoundTrip(t *testing.T) {
+	want := &testpb.TestAllTypes{
+		OptionalInt32:   proto.Int32(1),
+		OptionalString:  proto.String("hello"),
+		RepeatedInt64:   []int64{1, 2, 3},
+		MapStringString: map[string]string{"a": "b"},
+	}
+	for _, name := range []string{"proto", "json", "textproto"} {
+		t.Run(name, func(t *testing.T) {
+			c := protocodec.Lookup(name)
+			if c == nil {
+				t.Fatalf("Lookup(%q) = nil", name)
+			}
+			if got := c.Name(); got != name {
+				t.Errorf("Lookup(%q).Name() = %q", name, got)
+			}
+			b, err := c.Marshal(want)
+			if err != nil {
+				t.Fatalf("Marshal() error: %v", err)
+			}
+			got := &testpb.TestAllTypes{}
+			if err := c.Unmarshal(b, got); err != nil {
+				t.Fatalf("Unmarshal() error: %v", err)
+			}
+			if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestOptions(t *testing.T) {
+	c := protocodec.JSON{
+		MarshalOptions:   protojson.MarshalOptions{UseProtoNames: true},
+		UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+	}
+	m := &testpb.TestAllTypes{}
+	if err := c.Unmarshal([]byte(`{"optional_int32": 1, "unknown": 2}`), m); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+	b, err := c.Marshal(m)
+	if err != nil {
+		t.Fatalf("Marshal() error: %v", err)
+	}
+	if got, want := string(b), `{"optional_int32":1}`; got != want {
+		t.Errorf("Marshal() = %s, want %s", got, want)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	custom := protocodec.JSON{
+		MarshalOptions: protojson.MarshalOptions{EmitUnpopulated: true},
+	}
+	defer protocodec.Register(protocodec.Lookup("json"))
+	protocodec.Register(custom)
+	if got, ok := protocodec.Lookup("json").(protocodec.JSON); !ok || !got.MarshalOptions.EmitUnpopulated {
+		t.Errorf("Lookup(%q) = %v, want the replaced codec", "json", got)
+	}
+
+	if got := protocodec.Lookup("unknown"); got != nil {
+		t.Errorf("Lookup(%q) = %v, want nil", "unknown", got)
+	}
+	want := []string{"json", "proto", "textproto"}
+	if diff := cmp.Diff(want, protocodec.Names()); diff != "" {
+		t.Errorf("Names() mismatch (-want +got):\n%s", diff)
+	}
+}
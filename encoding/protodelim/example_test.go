@@ -0,0 +1,44 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protodelim_test
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func Example() {
+	var buf bytes.Buffer
+
+	// Write a stream of size-delimited messages.
+	for _, s := range []string{"hello", "world"} {
+		if _, err := protodelim.MarshalTo(&buf, wrapperspb.String(s)); err != nil {
+			panic(err)
+		}
+	}
+
+	// Read messages back until the end of the stream.
+	r := bufio.NewReader(&buf)
+	for {
+		m := &wrapperspb.StringValue{}
+		if err := protodelim.UnmarshalFrom(r, m); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			panic(err)
+		}
+		fmt.Println(m.GetValue())
+	}
+
+	// Output:
+	// hello
+	// world
+}
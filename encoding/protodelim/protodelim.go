@@ -3,6 +3,11 @@
 // license that can be found in the LICENSE file.
 
 // Package protodelim marshals and unmarshals varint size-delimited messages.
+//
+// Each message is preceded by its size in bytes encoded as a varint.
+// This is the same framing produced and consumed by writeDelimitedTo and
+// parseDelimitedFrom in the C++ and Java implementations, allowing streams of
+// messages to be exchanged with programs written in those languages.
 package protodelim
 
 import (
@@ -0,0 +1,66 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protojson
+
+import (
+	"encoding/json"
+
+	"google.golang.org/protobuf/internal/genid"
+	"google.golang.org/protobuf/proto"
+)
+
+// MessageMarshaler returns a [json.Marshaler] that marshals m
+// using the default options.
+//
+// It allows a message held within a larger Go value to be serialized by
+// the encoding/json package using the canonical JSON mapping for protobuf,
+// rather than the JSON encoding derived from the Go struct fields.
+func MessageMarshaler(m proto.Message) json.Marshaler {
+	return MarshalOptions{}.MessageMarshaler(m)
+}
+
+// MessageMarshaler returns a [json.Marshaler] that marshals m
+// using the options in o.
+func (o MarshalOptions) MessageMarshaler(m proto.Message) json.Marshaler {
+	return messageMarshaler{o, m}
+}
+
+type messageMarshaler struct {
+	opts MarshalOptions
+	m    proto.Message
+}
+
+func (x messageMarshaler) MarshalJSON() ([]byte, error) {
+	return x.opts.Marshal(x.m)
+}
+
+// MessageUnmarshaler returns a [json.Unmarshaler] that unmarshals into m
+// using the default options. The provided message must be mutable
+// (e.g., a non-nil pointer to a message).
+//
+// As is conventional for the encoding/json package, a JSON null leaves m
+// unchanged, unless m is a google.protobuf.Value, for which null is a valid
+// value.
+func MessageUnmarshaler(m proto.Message) json.Unmarshaler {
+	return UnmarshalOptions{}.MessageUnmarshaler(m)
+}
+
+// MessageUnmarshaler returns a [json.Unmarshaler] that unmarshals into m
+// using the options in o.
+func (o UnmarshalOptions) MessageUnmarshaler(m proto.Message) json.Unmarshaler {
+	return &messageUnmarshaler{o, m}
+}
+
+type messageUnmarshaler struct {
+	opts UnmarshalOptions
+	m    proto.Message
+}
+
+func (x *messageUnmarshaler) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" && x.m.ProtoReflect().Descriptor().FullName() != genid.Value_message_fullname {
+		return nil
+	}
+	return x.opts.Unmarshal(b, x.m)
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protojson_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	pb3 "google.golang.org/protobuf/internal/testprotos/textpb3"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestMessageMarshaler(t *testing.T) {
+	type envelope struct {
+		ID      int            `json:"id"`
+		Payload json.Marshaler `json:"payload"`
+	}
+	m := &pb3.Nested{SString: "a", SNested: &pb3.Nested{SString: "b"}}
+
+	got, err := json.Marshal(envelope{ID: 1, Payload: protojson.MessageMarshaler(m)})
+	if err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+	want := `{"id":1,"payload":{"sString":"a","sNested":{"sString":"b"}}}`
+	if string(got) != want {
+		t.Errorf("json.Marshal() = %s, want %s", got, want)
+	}
+
+	opts := protojson.MarshalOptions{UseProtoNames: true}
+	got, err = json.Marshal(envelope{ID: 1, Payload: opts.MessageMarshaler(m)})
+	if err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+	want = `{"id":1,"payload":{"s_string":"a","s_nested":{"s_string":"b"}}}`
+	if string(got) != want {
+		t.Errorf("json.Marshal() = %s, want %s", got, want)
+	}
+}
+
+func TestMessageUnmarshaler(t *testing.T) {
+	type envelope struct {
+		ID      int              `json:"id"`
+		Payload json.Unmarshaler `json:"payload"`
+	}
+
+	got := &pb3.Nested{}
+	v := envelope{Payload: protojson.MessageUnmarshaler(got)}
+	if err := json.Unmarshal([]byte(`{"id":1,"payload":{"s_string":"a","sNested":{"sString":"b"}}}`), &v); err != nil {
+		t.Fatalf("json.Unmarshal() error: %v", err)
+	}
+	want := &pb3.Nested{SString: "a", SNested: &pb3.Nested{SString: "b"}}
+	if v.ID != 1 || !proto.Equal(got, want) {
+		t.Errorf("json.Unmarshal() = %v, %v; want 1, %v", v.ID, got, want)
+	}
+
+	// A null leaves the message unchanged.
+	if err := json.Unmarshal([]byte(`null`), protojson.MessageUnmarshaler(got)); err != nil {
+		t.Fatalf("json.Unmarshal() error: %v", err)
+	}
+	if !proto.Equal(got, want) {
+		t.Errorf("json.Unmarshal(null) = %v, want %v", got, want)
+	}
+
+	// A null is a valid google.protobuf.Value.
+	value := structpb.NewStringValue("a")
+	if err := json.Unmarshal([]byte(`null`), protojson.MessageUnmarshaler(value)); err != nil {
+		t.Fatalf("json.Unmarshal() error: %v", err)
+	}
+	if !proto.Equal(value, structpb.NewNullValue()) {
+		t.Errorf("json.Unmarshal(null) = %v, want null value", value)
+	}
+
+	// Unknown fields are rejected unless DiscardUnknown is set.
+	in := []byte(`{"payload":{"unknown":1}}`)
+	v = envelope{Payload: protojson.MessageUnmarshaler(&pb3.Nested{})}
+	if err := json.Unmarshal(in, &v); err == nil {
+		t.Errorf("json.Unmarshal() with unknown field: want error, got nil")
+	}
+	v = envelope{Payload: protojson.UnmarshalOptions{DiscardUnknown: true}.MessageUnmarshaler(&pb3.Nested{})}
+	if err := json.Unmarshal(in, &v); err != nil {
+		t.Errorf("json.Unmarshal() with DiscardUnknown: %v", err)
+	}
+}
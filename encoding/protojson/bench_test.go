@@ -8,8 +8,15 @@ import (
 	"testing"
 
 	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 
+	pb2 "google.golang.org/protobuf/internal/testprotos/textpb2"
+	pb3 "google.golang.org/protobuf/internal/testprotos/textpb3"
 	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 func BenchmarkUnmarshal_Duration(b *testing.B) {
@@ -22,3 +29,75 @@ func BenchmarkUnmarshal_Duration(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkMarshal(b *testing.B) {
+	benchmarks := []struct {
+		desc string
+		m    proto.Message
+	}{{
+		desc: "Scalars",
+		m: &pb3.Scalars{
+			SBool:   true,
+			SInt32:  -12345,
+			SInt64:  1234567890123,
+			SUint32: 12345,
+			SUint64: 1234567890123,
+			SFloat:  1.5,
+			SDouble: 3.14159,
+			SString: "hello, world",
+			SBytes:  []byte("hello, world"),
+		},
+	}, {
+		desc: "Repeats",
+		m: &pb3.Repeats{
+			RptBool:   []bool{true, false, true},
+			RptInt32:  []int32{1, 2, 3, 4, 5},
+			RptInt64:  []int64{1, 2, 3, 4, 5},
+			RptString: []string{"hello", "world", "foo", "bar"},
+			RptBytes:  [][]byte{[]byte("hello"), []byte("world")},
+		},
+	}, {
+		desc: "Maps",
+		m: &pb3.Maps{
+			Int32ToStr: map[int32]string{1: "one", 2: "two", 3: "three"},
+			StrToNested: map[string]*pb3.Nested{
+				"a": {SString: "a"},
+				"b": {SString: "b"},
+			},
+		},
+	}, {
+		desc: "KnownTypes",
+		m: &pb2.KnownTypes{
+			OptBool:      wrapperspb.Bool(true),
+			OptInt32:     wrapperspb.Int32(12345),
+			OptInt64:     wrapperspb.Int64(1234567890123),
+			OptDouble:    wrapperspb.Double(3.14159),
+			OptString:    wrapperspb.String("hello"),
+			OptDuration:  &durationpb.Duration{Seconds: 3600, Nanos: 500000000},
+			OptTimestamp: &timestamppb.Timestamp{Seconds: 1700000000, Nanos: 123000000},
+			OptStruct: &structpb.Struct{Fields: map[string]*structpb.Value{
+				"name":   structpb.NewStringValue("value"),
+				"number": structpb.NewNumberValue(42),
+				"list": structpb.NewListValue(&structpb.ListValue{Values: []*structpb.Value{
+					structpb.NewBoolValue(true),
+					structpb.NewNullValue(),
+				}}),
+			}},
+			OptFieldmask: &fieldmaskpb.FieldMask{Paths: []string{"foo_bar", "baz.qux_quux"}},
+		},
+	}}
+
+	for _, bb := range benchmarks {
+		b.Run(bb.desc, func(b *testing.B) {
+			b.ReportAllocs()
+			var buf []byte
+			for i := 0; i < b.N; i++ {
+				var err error
+				buf, err = protojson.MarshalOptions{}.MarshalAppend(buf[:0], bb.m)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ package protojson
 
 import (
 	"encoding/base64"
+	stdjson "encoding/json"
 	"fmt"
 	"math"
 	"strconv"
@@ -52,8 +53,48 @@ type UnmarshalOptions struct {
 	// RecursionLimit limits how deeply messages may be nested.
 	// If zero, a default limit is applied.
 	RecursionLimit int
+
+	// OnUnknownField, if set, is called for each unknown field instead of
+	// discarding it or reporting an error according to DiscardUnknown.
+	// The path is a JSON Pointer (RFC 6901) to the object containing the field,
+	// name is the field name as it appears in the input, and value is the
+	// raw JSON value of the field. If it returns nil, the field is ignored.
+	// Otherwise, unmarshaling stops and the returned error is reported.
+	OnUnknownField func(path string, name string, value stdjson.RawMessage) error
+
+	// FieldNames specifies which names of a field are accepted as the key
+	// of the field in a JSON object. Names which are not accepted are treated
+	// as unknown fields. By default, both the JSON name and the proto name
+	// of a field are accepted.
+	FieldNames FieldNamePolicy
+
+	// CaseInsensitiveNames accepts the names of fields permitted by
+	// FieldNames regardless of the case of their letters. A name which
+	// matches a field exactly always refers to that field. Otherwise,
+	// a name which matches more than one field is reported as an error.
+	// Extension names are always case-sensitive.
+	CaseInsensitiveNames bool
 }
 
+// FieldNamePolicy specifies which names of a field are accepted
+// when unmarshaling. See [UnmarshalOptions.FieldNames].
+type FieldNamePolicy int
+
+const (
+	// AcceptAllNames accepts both the JSON name of a field, which is its
+	// lowerCamelCase name unless the json_name option is set, and the proto
+	// name of the field as declared in the .proto file. This is the default.
+	AcceptAllNames FieldNamePolicy = iota
+
+	// AcceptJSONNames accepts only the JSON name of a field,
+	// as emitted by [MarshalOptions] by default.
+	AcceptJSONNames
+
+	// AcceptProtoNames accepts only the proto name of a field,
+	// as emitted by [MarshalOptions] when UseProtoNames is set.
+	AcceptProtoNames
+)
+
 // Unmarshal reads the given []byte and populates the given [proto.Message]
 // using options in the UnmarshalOptions object.
 // It will clear the message first before setting the fields.
@@ -76,15 +117,17 @@ func (o UnmarshalOptions) unmarshal(b []byte, m proto.Message) error {
 		o.RecursionLimit = protowire.DefaultRecursionLimit
 	}
 
-	dec := decoder{json.NewDecoder(b), o}
+	// Preallocate the path so that withPath does not allocate
+	// unless messages are deeply nested.
+	dec := decoder{Decoder: json.NewDecoder(b), opts: o, path: make([]string, 0, 16)}
 	if err := dec.unmarshalMessage(m.ProtoReflect(), false); err != nil {
-		return err
+		return dec.topLevelError(err, len(b))
 	}
 
 	// Check for EOF.
 	tok, err := dec.Read()
 	if err != nil {
-		return err
+		return dec.topLevelError(err, len(b))
 	}
 	if tok.Kind() != json.EOF {
 		return dec.unexpectedTokenError(tok)
@@ -99,13 +142,90 @@ func (o UnmarshalOptions) unmarshal(b []byte, m proto.Message) error {
 type decoder struct {
 	*json.Decoder
 	opts UnmarshalOptions
+
+	// path holds the unescaped JSON Pointer reference tokens of the value
+	// currently being decoded. Its backing array is shared with the
+	// decoders for sibling and nested values, so it must be converted
+	// with pointer rather than retained.
+	path []string
+}
+
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// withPath returns a copy of d for decoding the value referenced by
+// the given JSON Pointer reference token relative to the current value.
+func (d decoder) withPath(token string) decoder {
+	d.path = append(d.path, token)
+	return d
+}
+
+// pointer returns the JSON Pointer to the value currently being decoded.
+func (d decoder) pointer() string {
+	var b strings.Builder
+	for _, token := range d.path {
+		b.WriteByte('/')
+		b.WriteString(jsonPointerEscaper.Replace(token))
+	}
+	return b.String()
+}
+
+// unmarshalUnknown handles the value of the unknown field named by tok.
+// Errors are located at the unknown field.
+func (d decoder) unmarshalUnknown(tok json.Token) error {
+	if d.opts.OnUnknownField != nil {
+		val, err := d.readJSONValue()
+		if err == nil {
+			err = d.opts.OnUnknownField(d.pointer(), tok.Name(), val)
+		}
+		if err != nil {
+			return d.withPath(tok.Name()).locateError(err)
+		}
+		return nil
+	}
+	if d.opts.DiscardUnknown {
+		if err := d.skipJSONValue(); err != nil {
+			return d.withPath(tok.Name()).locateError(err)
+		}
+		return nil
+	}
+	return d.withPath(tok.Name()).newError(tok.Pos(), "unknown field %v", tok.RawString())
+}
+
+// UnmarshalError is an error in the input to [UnmarshalOptions.Unmarshal],
+// together with its location. Errors returned by Unmarshal for invalid input
+// can be inspected with [errors.As]; errors for missing required fields
+// are not of this type, since they do not have a location in the input.
+type UnmarshalError struct {
+	// Pointer is a JSON Pointer (RFC 6901) to the value in which the error
+	// was found, such as "/foo/2/bar". It is empty for the top-level value.
+	// Reference tokens are the object member names as they appear in the
+	// input, and array indexes.
+	Pointer string
+
+	// Line and Column are the 1-based position in the input of the token
+	// at which the error was found, or zero if the position is unknown.
+	Line, Column int
+
+	err error
+}
+
+func (e *UnmarshalError) Error() string {
+	if e.Pointer == "" {
+		return e.err.Error()
+	}
+	return e.err.Error() + " (at " + e.Pointer + ")"
+}
+
+// Unwrap returns the underlying error, which matches [proto.Error].
+func (e *UnmarshalError) Unwrap() error {
+	return e.err
 }
 
 // newError returns an error object with position info.
 func (d decoder) newError(pos int, f string, x ...any) error {
 	line, column := d.Position(pos)
 	head := fmt.Sprintf("(line %d:%d): ", line, column)
-	return errors.New(head+f, x...)
+	return &UnmarshalError{Pointer: d.pointer(), Line: line, Column: column, err: errors.New(head+f, x...)}
 }
 
 // unexpectedTokenError returns a syntax error for the given unexpected token.
@@ -117,7 +237,30 @@ func (d decoder) unexpectedTokenError(tok json.Token) error {
 func (d decoder) syntaxError(pos int, f string, x ...any) error {
 	line, column := d.Position(pos)
 	head := fmt.Sprintf("syntax error (line %d:%d): ", line, column)
-	return errors.New(head+f, x...)
+	return &UnmarshalError{Pointer: d.pointer(), Line: line, Column: column, err: errors.New(head+f, x...)}
+}
+
+// locateError returns err as an *UnmarshalError. An error which does not
+// already have a location is located at the value currently being decoded.
+func (d decoder) locateError(err error) *UnmarshalError {
+	switch err := err.(type) {
+	case *UnmarshalError:
+		return err
+	case *json.SyntaxError:
+		return &UnmarshalError{Pointer: d.pointer(), Line: err.Line, Column: err.Column, err: err}
+	default:
+		return &UnmarshalError{Pointer: d.pointer(), err: err}
+	}
+}
+
+// topLevelError returns err as an *UnmarshalError for the top-level value.
+// An unexpected end of input is located at the end of the input of size n.
+func (d decoder) topLevelError(err error, n int) error {
+	e := d.locateError(err)
+	if e.Line == 0 && e.err == json.ErrUnexpectedEOF {
+		e.Line, e.Column = d.Position(n)
+	}
+	return e
 }
 
 // unmarshalMessage unmarshals a message into the given protoreflect.Message.
@@ -186,22 +329,18 @@ func (d decoder) unmarshalMessage(m protoreflect.Message, skipTypeURL bool) erro
 				}
 			}
 		} else {
-			// The name can either be the JSON name or the proto field name.
-			fd = fieldDescs.ByJSONName(name)
-			if fd == nil {
-				fd = fieldDescs.ByTextName(name)
+			var err error
+			if fd, err = d.findField(fieldDescs, name); err != nil {
+				return d.newError(tok.Pos(), "%v", err)
 			}
 		}
 
 		if fd == nil {
 			// Field is unknown.
-			if d.opts.DiscardUnknown {
-				if err := d.skipJSONValue(); err != nil {
-					return err
-				}
-				continue
+			if err := d.unmarshalUnknown(tok); err != nil {
+				return err
 			}
-			return d.newError(tok.Pos(), "unknown field %v", tok.RawString())
+			continue
 		}
 
 		// Do not allow duplicate fields.
@@ -218,16 +357,17 @@ func (d decoder) unmarshalMessage(m protoreflect.Message, skipTypeURL bool) erro
 			continue
 		}
 
+		d := d.withPath(name)
 		switch {
 		case fd.IsList():
 			list := m.Mutable(fd).List()
 			if err := d.unmarshalList(list, fd); err != nil {
-				return err
+				return d.locateError(err)
 			}
 		case fd.IsMap():
 			mmap := m.Mutable(fd).Map()
 			if err := d.unmarshalMap(mmap, fd); err != nil {
-				return err
+				return d.locateError(err)
 			}
 		default:
 			// If field is a oneof, check if it has already been set.
@@ -241,7 +381,7 @@ func (d decoder) unmarshalMessage(m protoreflect.Message, skipTypeURL bool) erro
 
 			// Required or optional fields.
 			if err := d.unmarshalSingular(m, fd); err != nil {
-				return err
+				return d.locateError(err)
 			}
 		}
 	}
@@ -257,6 +397,38 @@ func isNullValue(fd protoreflect.FieldDescriptor) bool {
 	return ed != nil && ed.FullName() == genid.NullValue_enum_fullname
 }
 
+// findField returns the field with the given name as permitted by
+// the FieldNames and CaseInsensitiveNames options, or nil if there is none.
+func (d decoder) findField(fds protoreflect.FieldDescriptors, name string) (protoreflect.FieldDescriptor, error) {
+	acceptJSON := d.opts.FieldNames != AcceptProtoNames
+	acceptProto := d.opts.FieldNames != AcceptJSONNames
+	if acceptJSON {
+		if fd := fds.ByJSONName(name); fd != nil {
+			return fd, nil
+		}
+	}
+	// The proto name of a field with a shadowed JSON name is always accepted,
+	// since it is the name with which the field is marshaled.
+	if fd := fds.ByTextName(name); fd != nil && (acceptProto || hasShadowedJSONName(fd)) {
+		return fd, nil
+	}
+	if !d.opts.CaseInsensitiveNames {
+		return nil, nil
+	}
+	var found protoreflect.FieldDescriptor
+	for i := 0; i < fds.Len(); i++ {
+		fd := fds.Get(i)
+		if (acceptJSON && strings.EqualFold(fd.JSONName(), name)) ||
+			(acceptProto && strings.EqualFold(fd.TextName(), name)) {
+			if found != nil && found != fd {
+				return nil, errors.New("ambiguous field name %q matches %v and %v", name, found.Name(), fd.Name())
+			}
+			found = fd
+		}
+	}
+	return found, nil
+}
+
 // unmarshalSingular unmarshals to the non-repeated field specified
 // by the given FieldDescriptor.
 func (d decoder) unmarshalSingular(m protoreflect.Message, fd protoreflect.FieldDescriptor) error {
@@ -522,9 +694,10 @@ func (d decoder) unmarshalList(list protoreflect.List, fd protoreflect.FieldDesc
 	switch fd.Kind() {
 	case protoreflect.MessageKind, protoreflect.GroupKind:
 		for {
+			d := d.withPath(strconv.Itoa(list.Len()))
 			tok, err := d.Peek()
 			if err != nil {
-				return err
+				return d.locateError(err)
 			}
 
 			if tok.Kind() == json.ArrayClose {
@@ -534,15 +707,16 @@ func (d decoder) unmarshalList(list protoreflect.List, fd protoreflect.FieldDesc
 
 			val := list.NewElement()
 			if err := d.unmarshalMessage(val.Message(), false); err != nil {
-				return err
+				return d.locateError(err)
 			}
 			list.Append(val)
 		}
 	default:
 		for {
+			d := d.withPath(strconv.Itoa(list.Len()))
 			tok, err := d.Peek()
 			if err != nil {
-				return err
+				return d.locateError(err)
 			}
 
 			if tok.Kind() == json.ArrayClose {
@@ -552,7 +726,7 @@ func (d decoder) unmarshalList(list protoreflect.List, fd protoreflect.FieldDesc
 
 			val, err := d.unmarshalScalar(fd)
 			if err != nil {
-				return err
+				return d.locateError(err)
 			}
 			if val.IsValid() {
 				list.Append(val)
@@ -575,10 +749,10 @@ func (d decoder) unmarshalMap(mmap protoreflect.Map, fd protoreflect.FieldDescri
 	// Determine ahead whether map entry is a scalar type or a message type in
 	// order to call the appropriate unmarshalMapValue func inside the for loop
 	// below.
-	var unmarshalMapValue func() (protoreflect.Value, error)
+	var unmarshalMapValue func(d decoder) (protoreflect.Value, error)
 	switch fd.MapValue().Kind() {
 	case protoreflect.MessageKind, protoreflect.GroupKind:
-		unmarshalMapValue = func() (protoreflect.Value, error) {
+		unmarshalMapValue = func(d decoder) (protoreflect.Value, error) {
 			val := mmap.NewValue()
 			if err := d.unmarshalMessage(val.Message(), false); err != nil {
 				return protoreflect.Value{}, err
@@ -586,7 +760,7 @@ func (d decoder) unmarshalMap(mmap protoreflect.Map, fd protoreflect.FieldDescri
 			return val, nil
 		}
 	default:
-		unmarshalMapValue = func() (protoreflect.Value, error) {
+		unmarshalMapValue = func(d decoder) (protoreflect.Value, error) {
 			return d.unmarshalScalar(fd.MapValue())
 		}
 	}
@@ -608,9 +782,10 @@ Loop:
 		}
 
 		// Unmarshal field name.
+		d := d.withPath(tok.Name())
 		pkey, err := d.unmarshalMapKey(tok, fd.MapKey())
 		if err != nil {
-			return err
+			return d.locateError(err)
 		}
 
 		// Check for duplicate field name.
@@ -619,9 +794,9 @@ Loop:
 		}
 
 		// Read and unmarshal field value.
-		pval, err := unmarshalMapValue()
+		pval, err := unmarshalMapValue(d)
 		if err != nil {
-			return err
+			return d.locateError(err)
 		}
 		if pval.IsValid() {
 			mmap.Set(pkey, pval)
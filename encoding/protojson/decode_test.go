@@ -5,15 +5,23 @@
 package protojson_test
 
 import (
+	"encoding/json"
+	"fmt"
 	"math"
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/internal/errors"
 	"google.golang.org/protobuf/internal/flags"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
 
 	testpb "google.golang.org/protobuf/internal/testprotos/test"
 	pb2 "google.golang.org/protobuf/internal/testprotos/textpb2"
@@ -84,6 +92,23 @@ func TestUnmarshal(t *testing.T) {
 			OptBytes:    []byte{},
 			OptString:   proto.String(""),
 		},
+	}, {
+		desc:         "proto2 64-bit integers as numbers",
+		inputMessage: &pb2.Scalars{},
+		inputText: `{
+  "optInt64": -9223372036854775808,
+  "optUint64": 18446744073709551615,
+  "optSint64": -1,
+  "optFixed64": 9007199254740993,
+  "optSfixed64": 9223372036854775807
+}`,
+		wantMessage: &pb2.Scalars{
+			OptInt64:    proto.Int64(math.MinInt64),
+			OptUint64:   proto.Uint64(math.MaxUint64),
+			OptSint64:   proto.Int64(-1),
+			OptFixed64:  proto.Uint64(1<<53 + 1),
+			OptSfixed64: proto.Int64(math.MaxInt64),
+		},
 	}, {
 		inputMessage: &pbeditions.Scalars{},
 		inputText:    "{}",
@@ -737,6 +762,42 @@ func TestUnmarshal(t *testing.T) {
 				},
 			},
 		},
+	}, {
+		desc:         "protoeditions delimited encoded message fields",
+		inputMessage: &pbeditions.Nests{},
+		inputText: `{
+  "optgroup": {
+    "optString": "inside a group",
+    "optnestedgroup": {
+      "optFixed32": 47
+    }
+  },
+  "delimited_field": {
+    "optString": "second group",
+    "nestedDelimitedField": {
+      "optFixed32": 48
+    }
+  }
+}`,
+		wantMessage: &pbeditions.Nests{
+			Optgroup: &pbeditions.Nests_OptGroup{
+				OptString: proto.String("inside a group"),
+				Optnestedgroup: &pbeditions.Nests_OptGroup_OptNestedGroup{
+					OptFixed32: proto.Uint32(47),
+				},
+			},
+			DelimitedField: &pbeditions.Nests_OptGroup{
+				OptString: proto.String("second group"),
+				NestedDelimitedField: &pbeditions.Nests_OptGroup_OptNestedGroup{
+					OptFixed32: proto.Uint32(48),
+				},
+			},
+		},
+	}, {
+		desc:         "protoeditions delimited encoded message field is not group-like",
+		inputMessage: &pbeditions.Nests{},
+		inputText:    `{"DelimitedField": {}}`,
+		wantErr:      `unknown field "DelimitedField"`,
 	}, {
 		desc:         "proto3 nested message not set",
 		inputMessage: &pb3.Nests{},
@@ -2674,6 +2735,72 @@ func TestUnmarshal(t *testing.T) {
 				10: 101,
 			},
 		},
+	}, {
+		desc:         "OnUnknownField: ignore",
+		inputMessage: &pb2.Nests{},
+		inputText:    `{"optNested": {"optString": "x", "unknown": [1, {}]}}`,
+		umo: protojson.UnmarshalOptions{
+			OnUnknownField: func(string, string, json.RawMessage) error { return nil },
+		},
+		wantMessage: &pb2.Nests{
+			OptNested: &pb2.Nested{OptString: proto.String("x")},
+		},
+	}, {
+		desc:         "OnUnknownField: reject",
+		inputMessage: &pb2.Nests{},
+		inputText:    `{"optNested": {"unknown": "x"}}`,
+		umo: protojson.UnmarshalOptions{
+			DiscardUnknown: true,
+			OnUnknownField: func(path, name string, _ json.RawMessage) error {
+				return fmt.Errorf("rejected %s/%s", path, name)
+			},
+		},
+		wantErr: "rejected /optNested/unknown",
+	}, {
+		desc:         "FieldNames: AcceptJSONNames accepts JSON name",
+		inputMessage: &pb3.JSONNames{},
+		inputText:    `{"foo_bar": "x"}`,
+		umo:          protojson.UnmarshalOptions{FieldNames: protojson.AcceptJSONNames},
+		wantMessage:  &pb3.JSONNames{SString: "x"},
+	}, {
+		desc:         "FieldNames: AcceptJSONNames rejects proto name",
+		inputMessage: &pb3.JSONNames{},
+		inputText:    `{"s_string": "x"}`,
+		umo:          protojson.UnmarshalOptions{FieldNames: protojson.AcceptJSONNames},
+		wantErr:      `unknown field "s_string"`,
+	}, {
+		desc:         "FieldNames: AcceptProtoNames accepts proto name",
+		inputMessage: &pb3.JSONNames{},
+		inputText:    `{"s_string": "x"}`,
+		umo:          protojson.UnmarshalOptions{FieldNames: protojson.AcceptProtoNames},
+		wantMessage:  &pb3.JSONNames{SString: "x"},
+	}, {
+		desc:         "FieldNames: AcceptProtoNames rejects JSON name",
+		inputMessage: &pb3.JSONNames{},
+		inputText:    `{"foo_bar": "x"}`,
+		umo:          protojson.UnmarshalOptions{FieldNames: protojson.AcceptProtoNames, DiscardUnknown: true},
+		wantMessage:  &pb3.JSONNames{},
+	}, {
+		desc:         "CaseInsensitiveNames",
+		inputMessage: &pb2.Nests{},
+		inputText:    `{"OPTNESTED": {"OptString": "x"}, "RPT_NESTED": [{}]}`,
+		umo:          protojson.UnmarshalOptions{CaseInsensitiveNames: true},
+		wantMessage: &pb2.Nests{
+			OptNested: &pb2.Nested{OptString: proto.String("x")},
+			RptNested: []*pb2.Nested{{}},
+		},
+	}, {
+		desc:         "CaseInsensitiveNames with AcceptJSONNames",
+		inputMessage: &pb2.Nests{},
+		inputText:    `{"OptNested": {}, "RPT_NESTED": [{}]}`,
+		umo:          protojson.UnmarshalOptions{CaseInsensitiveNames: true, FieldNames: protojson.AcceptJSONNames},
+		wantErr:      `unknown field "RPT_NESTED"`,
+	}, {
+		desc:         "CaseInsensitiveNames duplicate field",
+		inputMessage: &pb2.Nests{},
+		inputText:    `{"optNested": {}, "OPT_NESTED": {}}`,
+		umo:          protojson.UnmarshalOptions{CaseInsensitiveNames: true},
+		wantErr:      `duplicate field "OPT_NESTED"`,
 	}, {
 		desc:         "just at recursion limit: nested messages",
 		inputMessage: &testpb.TestAllTypes{},
@@ -2798,3 +2925,239 @@ func TestUnmarshal(t *testing.T) {
 		})
 	}
 }
+
+func TestUnmarshalOnUnknownField(t *testing.T) {
+	type unknown struct {
+		Path, Name, Value string
+	}
+	tests := []struct {
+		desc         string
+		inputMessage proto.Message
+		inputText    string
+		want         []unknown
+	}{{
+		desc:         "nested messages",
+		inputMessage: &pb2.Nests{},
+		inputText: `{
+  "optNested": {"optString": "x", "a/b": [1, {"c": 2}]},
+  "rptNested": [{}, {"d~": null}],
+  "top": true
+}`,
+		want: []unknown{
+			{"/optNested", "a/b", `[1, {"c": 2}]`},
+			{"/rptNested/1", "d~", "null"},
+			{"", "top", "true"},
+		},
+	}, {
+		desc:         "map values",
+		inputMessage: &pb2.Maps{},
+		inputText:    `{"strToNested": {"k/~": {"optString": "x", "e": "v"}}}`,
+		want: []unknown{
+			{"/strToNested/k~1~0", "e", `"v"`},
+		},
+	}, {
+		desc:         "Any",
+		inputMessage: &pb2.KnownTypes{},
+		inputText: `{
+  "optAny": {"@type": "type.googleapis.com/pb2.Nested", "f": {}},
+  "optValue": 1
+}`,
+		want: []unknown{
+			{"/optAny", "f", "{}"},
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var got []unknown
+			umo := protojson.UnmarshalOptions{
+				OnUnknownField: func(path, name string, value json.RawMessage) error {
+					got = append(got, unknown{path, name, string(value)})
+					return nil
+				},
+			}
+			if err := umo.Unmarshal([]byte(tt.inputText), tt.inputMessage); err != nil {
+				t.Fatalf("Unmarshal() error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("OnUnknownField calls mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUnmarshalErrorLocation(t *testing.T) {
+	tests := []struct {
+		desc         string
+		input        string
+		wantPointer  string
+		wantPosition [2]int // line and column
+	}{{
+		desc: "nested field",
+		input: `{
+  "optionalNestedMessage": {
+    "a": "x"
+  }
+}`,
+		wantPointer:  "/optionalNestedMessage/a",
+		wantPosition: [2]int{3, 10},
+	}, {
+		desc:         "list element",
+		input:        `{"repeatedInt32": [1, "x"]}`,
+		wantPointer:  "/repeatedInt32/1",
+		wantPosition: [2]int{1, 23},
+	}, {
+		desc:         "map value with escaped key",
+		input:        `{"mapStringNestedMessage": {"k/~": {"a": true}}}`,
+		wantPointer:  "/mapStringNestedMessage/k~1~0/a",
+		wantPosition: [2]int{1, 42},
+	}, {
+		desc:         "unknown field",
+		input:        `{"optionalNestedMessage": {"b": 1}}`,
+		wantPointer:  "/optionalNestedMessage/b",
+		wantPosition: [2]int{1, 28},
+	}, {
+		desc:         "duplicate map key",
+		input:        `{"mapStringString": {"k": "a", "k": "b"}}`,
+		wantPointer:  "/mapStringString/k",
+		wantPosition: [2]int{1, 32},
+	}, {
+		desc:         "syntax error",
+		input:        `{"repeatedInt32": [1, }`,
+		wantPointer:  "/repeatedInt32/1",
+		wantPosition: [2]int{1, 23},
+	}, {
+		desc:         "unexpected end of input",
+		input:        "{\n\"optionalInt32\": 1",
+		wantPointer:  "",
+		wantPosition: [2]int{2, 19},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := protojson.Unmarshal([]byte(tt.input), &testpb.TestAllTypes{})
+			uerr, ok := err.(*protojson.UnmarshalError)
+			if !ok {
+				t.Fatalf("Unmarshal() = %v, want *UnmarshalError", err)
+			}
+			if uerr.Pointer != tt.wantPointer {
+				t.Errorf("Unmarshal() error pointer = %q, want %q", uerr.Pointer, tt.wantPointer)
+			}
+			if got := [2]int{uerr.Line, uerr.Column}; got != tt.wantPosition {
+				t.Errorf("Unmarshal() error position = %v, want %v", got, tt.wantPosition)
+			}
+			if tt.wantPointer != "" && !strings.Contains(err.Error(), tt.wantPointer) {
+				t.Errorf("Unmarshal() error %q does not contain pointer %q", err, tt.wantPointer)
+			}
+		})
+	}
+
+	// Missing required fields have no location.
+	err := protojson.Unmarshal([]byte(`{}`), &testpb.TestRequired{})
+	if _, ok := err.(*protojson.UnmarshalError); ok || err == nil {
+		t.Errorf("Unmarshal(missing required field) = %v, want error other than *UnmarshalError", err)
+	}
+}
+
+func TestUnmarshalCaseInsensitiveNames(t *testing.T) {
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("names.proto"),
+		Package: proto.String("names"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Names"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:   proto.String("foo"),
+				Number: proto.Int32(1),
+				Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:   descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(),
+			}, {
+				Name:   proto.String("FOO"),
+				Number: proto.Int32(2),
+				Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:   descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(),
+			}},
+		}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := fd.Messages().Get(0)
+	opts := protojson.UnmarshalOptions{CaseInsensitiveNames: true}
+
+	// Exact matches take precedence over case-insensitive ones.
+	m := dynamicpb.NewMessage(md)
+	if err := opts.Unmarshal([]byte(`{"foo": 1, "FOO": 2}`), m); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+	for name, want := range map[string]int64{"foo": 1, "FOO": 2} {
+		if got := m.Get(md.Fields().ByName(protoreflect.Name(name))).Int(); got != want {
+			t.Errorf("after Unmarshal, %v = %v, want %v", name, got, want)
+		}
+	}
+
+	err = opts.Unmarshal([]byte(`{"Foo": 1}`), dynamicpb.NewMessage(md))
+	if err == nil || !strings.Contains(err.Error(), "ambiguous field name") {
+		t.Errorf("Unmarshal() error = %v, want ambiguous field name error", err)
+	}
+}
+
+func TestLegacyBestEffortJSONNames(t *testing.T) {
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("legacy_names.proto"),
+		Package: proto.String("names"),
+		Syntax:  proto.String("editions"),
+		Edition: descriptorpb.Edition_EDITION_2023.Enum(),
+		Options: &descriptorpb.FileOptions{
+			Features: &descriptorpb.FeatureSet{
+				JsonFormat: descriptorpb.FeatureSet_LEGACY_BEST_EFFORT.Enum(),
+			},
+		},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Names"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:   proto.String("foo_bar"),
+				Number: proto.Int32(1),
+				Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:   descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(),
+			}, {
+				Name:   proto.String("foo__bar"),
+				Number: proto.Int32(2),
+				Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:   descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(),
+			}},
+		}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := fd.Messages().Get(0)
+	m := dynamicpb.NewMessage(md)
+	m.Set(md.Fields().ByNumber(1), protoreflect.ValueOfInt32(1))
+	m.Set(md.Fields().ByNumber(2), protoreflect.ValueOfInt32(2))
+
+	b, err := protojson.Marshal(m)
+	if err != nil {
+		t.Fatalf("Marshal() error: %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"fooBar": 1.0, "foo__bar": 2.0}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Marshal() mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, opts := range []protojson.UnmarshalOptions{
+		{},
+		{FieldNames: protojson.AcceptJSONNames},
+	} {
+		m2 := dynamicpb.NewMessage(md)
+		if err := opts.Unmarshal(b, m2); err != nil {
+			t.Errorf("%+v.Unmarshal() error: %v", opts, err)
+			continue
+		}
+		if !proto.Equal(m, m2) {
+			t.Errorf("%+v.Unmarshal() = %v, want %v", opts, m2, m)
+		}
+	}
+}
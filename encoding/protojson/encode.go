@@ -5,8 +5,9 @@
 package protojson
 
 import (
-	"encoding/base64"
 	"fmt"
+	"strconv"
+	"sync"
 
 	"google.golang.org/protobuf/internal/encoding/json"
 	"google.golang.org/protobuf/internal/encoding/messageset"
@@ -64,9 +65,34 @@ type MarshalOptions struct {
 	// field names.
 	UseProtoNames bool
 
+	// SortFieldsByNumber emits the fields of a message in field number order
+	// instead of declaration order.
+	SortFieldsByNumber bool
+
+	// SortFieldsByName emits the fields of a message sorted by their JSON
+	// field names (as affected by UseProtoNames).
+	// It takes precedence over SortFieldsByNumber.
+	SortFieldsByName bool
+
 	// UseEnumNumbers emits enum values as numbers.
 	UseEnumNumbers bool
 
+	// UseEnumNumbersFor, if non-nil, reports whether enum values of the
+	// given field should be emitted as numbers. For map fields, it is called
+	// with the descriptor of the map entry's value field.
+	// It is ignored if UseEnumNumbers is set.
+	UseEnumNumbersFor func(fd protoreflect.FieldDescriptor) bool
+
+	// UseNumbersForInt64 emits the values of 64-bit integer fields
+	// (int64, sint64, sfixed64, uint64, and fixed64) as JSON numbers
+	// instead of strings. This is intended for consumers which decode
+	// JSON numbers into 64-bit or arbitrary-precision integers; many
+	// JSON parsers decode numbers as 64-bit floating point values,
+	// which cannot exactly represent integers with magnitude above 2^53.
+	// Map keys are always emitted as strings. Unmarshal accepts
+	// both forms regardless of this option.
+	UseNumbersForInt64 bool
+
 	// EmitUnpopulated specifies whether to emit unpopulated fields. It does not
 	// emit unpopulated oneof fields or unpopulated extension fields.
 	// The JSON value emitted for unpopulated fields are as follows:
@@ -102,6 +128,25 @@ type MarshalOptions struct {
 	// a strict superset of the latter.
 	EmitDefaultValues bool
 
+	// EmitUnsetDefaults specifies whether to emit unpopulated scalar fields
+	// with explicit presence, such as proto2 optional fields, which are not
+	// part of a oneof. Such fields are emitted with their default values,
+	// which are either declared in the .proto file or are the zero value
+	// for the field's type. This permits consumers which do not know the
+	// declared defaults to observe the values returned by the getters.
+	// Combined with EmitDefaultValues, every scalar field outside of a oneof
+	// is emitted. It takes precedence over EmitUnpopulated for these fields,
+	// which otherwise emits null.
+	EmitUnsetDefaults bool
+
+	// OmitSetDefaults specifies whether to omit populated scalar fields with
+	// explicit presence, such as proto2 optional fields, whose values are
+	// equal to their default values. Such fields are treated as if they were
+	// unpopulated, which may be emitted as determined by EmitUnpopulated and
+	// EmitUnsetDefaults. Fields which are part of a oneof are always emitted
+	// when populated, since they determine which field of the oneof is set.
+	OmitSetDefaults bool
+
 	// Resolver is used for looking up types when expanding google.protobuf.Any
 	// messages. If nil, this defaults to using protoregistry.GlobalTypes.
 	Resolver interface {
@@ -160,7 +205,7 @@ func (o MarshalOptions) marshal(b []byte, m proto.Message) ([]byte, error) {
 		return append(b, '{', '}'), nil
 	}
 
-	enc := encoder{internalEnc, o}
+	enc := &encoder{internalEnc, o}
 	if err := enc.marshalMessage(m.ProtoReflect(), ""); err != nil {
 		return nil, err
 	}
@@ -204,35 +249,59 @@ func (m typeURLFieldRanger) Range(f func(protoreflect.FieldDescriptor, protorefl
 type unpopulatedFieldRanger struct {
 	protoreflect.Message
 
-	skipNull bool
+	emitImplicit    bool // emit unpopulated fields without presence
+	emitNull        bool // emit unpopulated fields with presence as null
+	emitDefaults    bool // emit unpopulated scalar fields with presence as their defaults
+	omitSetDefaults bool // treat populated fields with presence set to their defaults as unpopulated
 }
 
 func (m unpopulatedFieldRanger) Range(f func(protoreflect.FieldDescriptor, protoreflect.Value) bool) {
 	fds := m.Descriptor().Fields()
 	for i := 0; i < fds.Len(); i++ {
 		fd := fds.Get(i)
-		if m.Has(fd) || fd.ContainingOneof() != nil {
-			continue // ignore populated fields and fields within a oneofs
+		if fd.ContainingOneof() != nil {
+			continue // ignore fields within a oneofs
 		}
-
 		v := m.Get(fd)
-		if fd.HasPresence() {
-			if m.skipNull {
+		if m.Has(fd) && !(m.omitSetDefaults && isSetDefault(fd, v)) {
+			continue // ignore populated fields
+		}
+
+		switch {
+		case !fd.HasPresence():
+			if !m.emitImplicit {
 				continue
 			}
+		case m.emitDefaults && fd.Message() == nil:
+			// v is the default value.
+		case m.emitNull:
 			v = protoreflect.Value{} // use invalid value to emit null
+		default:
+			continue
 		}
 		if !f(fd, v) {
 			return
 		}
 	}
-	m.Message.Range(f)
+	m.Message.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if m.omitSetDefaults && isSetDefault(fd, v) {
+			return true
+		}
+		return f(fd, v)
+	})
+}
+
+// isSetDefault reports whether fd is a scalar field with explicit presence,
+// which is not part of a oneof, and v is its default value.
+func isSetDefault(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+	return fd.HasPresence() && fd.ContainingOneof() == nil && fd.Message() == nil &&
+		v.Equal(fd.Default())
 }
 
 // marshalMessage marshals the fields in the given protoreflect.Message.
 // If the typeURL is non-empty, then a synthetic "@type" field is injected
 // containing the URL as the value.
-func (e encoder) marshalMessage(m protoreflect.Message, typeURL string) error {
+func (e *encoder) marshalMessage(m protoreflect.Message, typeURL string) error {
 	if !flags.ProtoLegacy && messageset.IsMessageSet(m.Descriptor()) {
 		return errors.New("no support for proto1 MessageSets")
 	}
@@ -245,24 +314,36 @@ func (e encoder) marshalMessage(m protoreflect.Message, typeURL string) error {
 	defer e.EndObject()
 
 	var fields order.FieldRanger = m
-	switch {
-	case e.opts.EmitUnpopulated:
-		fields = unpopulatedFieldRanger{Message: m, skipNull: false}
-	case e.opts.EmitDefaultValues:
-		fields = unpopulatedFieldRanger{Message: m, skipNull: true}
+	if e.opts.EmitUnpopulated || e.opts.EmitDefaultValues || e.opts.EmitUnsetDefaults || e.opts.OmitSetDefaults {
+		fields = unpopulatedFieldRanger{
+			Message:         m,
+			emitImplicit:    e.opts.EmitUnpopulated || e.opts.EmitDefaultValues,
+			emitNull:        e.opts.EmitUnpopulated,
+			emitDefaults:    e.opts.EmitUnsetDefaults,
+			omitSetDefaults: e.opts.OmitSetDefaults,
+		}
 	}
 	if typeURL != "" {
 		fields = typeURLFieldRanger{fields, typeURL}
 	}
 
-	var err error
-	order.RangeFields(fields, order.IndexNameFieldOrder, func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
-		name := fd.JSONName()
-		if e.opts.UseProtoNames {
-			name = fd.TextName()
+	fieldOrder := order.IndexNameFieldOrder
+	switch {
+	case e.opts.SortFieldsByName:
+		fieldOrder = func(x, y protoreflect.FieldDescriptor) bool {
+			// The synthetic "@type" field always sorts first.
+			if x == typeFieldDesc || y == typeFieldDesc {
+				return x == typeFieldDesc && y != typeFieldDesc
+			}
+			return e.fieldName(x) < e.fieldName(y)
 		}
+	case e.opts.SortFieldsByNumber:
+		fieldOrder = order.NumberFieldOrder
+	}
 
-		if err = e.WriteName(name); err != nil {
+	var err error
+	order.RangeFields(fields, fieldOrder, func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if err = e.WriteName(e.fieldName(fd)); err != nil {
 			return false
 		}
 		if err = e.marshalValue(v, fd); err != nil {
@@ -273,8 +354,66 @@ func (e encoder) marshalMessage(m protoreflect.Message, typeURL string) error {
 	return err
 }
 
+// fieldName returns the JSON object key used for the given field.
+func (e *encoder) fieldName(fd protoreflect.FieldDescriptor) string {
+	if e.opts.UseProtoNames || hasShadowedJSONName(fd) {
+		return fd.TextName()
+	}
+	return fd.JSONName()
+}
+
+// hasShadowedJSONName reports whether the JSON name of fd is also the JSON
+// name of a field declared before it in the same message. This may only
+// occur in messages that resolve the json_format feature to
+// LEGACY_BEST_EFFORT. Such a field is written with its proto name instead,
+// which is also accepted when unmarshaling, so that the output does not
+// contain the same key twice.
+func hasShadowedJSONName(fd protoreflect.FieldDescriptor) bool {
+	if fd == typeFieldDesc || fd.IsExtension() {
+		return false
+	}
+	md := fd.ContainingMessage()
+	if isJSONCompliant(md) {
+		return false
+	}
+	return shadowedJSONNames(md)[fd.Number()]
+}
+
+// shadowedJSONNameMap caches the result of shadowedJSONNames.
+var shadowedJSONNameMap sync.Map // map[protoreflect.MessageDescriptor]map[protoreflect.FieldNumber]bool
+
+// shadowedJSONNames returns the numbers of the fields of md whose JSON names
+// are shadowed by a field declared before them. It is nil if there are none.
+func shadowedJSONNames(md protoreflect.MessageDescriptor) map[protoreflect.FieldNumber]bool {
+	if v, ok := shadowedJSONNameMap.Load(md); ok {
+		return v.(map[protoreflect.FieldNumber]bool)
+	}
+	var shadowed map[protoreflect.FieldNumber]bool
+	fds := md.Fields()
+	for i := 0; i < fds.Len(); i++ {
+		fd := fds.Get(i)
+		if fds.ByJSONName(fd.JSONName()) != fd {
+			if shadowed == nil {
+				shadowed = make(map[protoreflect.FieldNumber]bool)
+			}
+			shadowed[fd.Number()] = true
+		}
+	}
+	v, _ := shadowedJSONNameMap.LoadOrStore(md, shadowed)
+	return v.(map[protoreflect.FieldNumber]bool)
+}
+
+// isJSONCompliant reports whether md resolves the json_format feature to
+// ALLOW, which is the default for proto3 and editions files.
+func isJSONCompliant(md protoreflect.MessageDescriptor) bool {
+	if md, ok := md.(*filedesc.Message); ok {
+		return md.L1.EditionFeatures.IsJSONCompliant
+	}
+	return md.ParentFile() == nil || md.ParentFile().Syntax() != protoreflect.Proto2
+}
+
 // marshalValue marshals the given protoreflect.Value.
-func (e encoder) marshalValue(val protoreflect.Value, fd protoreflect.FieldDescriptor) error {
+func (e *encoder) marshalValue(val protoreflect.Value, fd protoreflect.FieldDescriptor) error {
 	switch {
 	case fd.IsList():
 		return e.marshalList(val.List(), fd)
@@ -287,7 +426,7 @@ func (e encoder) marshalValue(val protoreflect.Value, fd protoreflect.FieldDescr
 
 // marshalSingular marshals the given non-repeated field value. This includes
 // all scalar types, enums, messages, and groups.
-func (e encoder) marshalSingular(val protoreflect.Value, fd protoreflect.FieldDescriptor) error {
+func (e *encoder) marshalSingular(val protoreflect.Value, fd protoreflect.FieldDescriptor) error {
 	if !val.IsValid() {
 		e.WriteNull()
 		return nil
@@ -308,10 +447,21 @@ func (e encoder) marshalSingular(val protoreflect.Value, fd protoreflect.FieldDe
 	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
 		e.WriteUint(val.Uint())
 
-	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Uint64Kind,
-		protoreflect.Sfixed64Kind, protoreflect.Fixed64Kind:
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		if e.opts.UseNumbersForInt64 {
+			e.WriteInt(val.Int())
+			break
+		}
 		// 64-bit integers are written out as JSON string.
-		e.WriteString(val.String())
+		e.WriteQuotedInt(val.Int())
+
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		if e.opts.UseNumbersForInt64 {
+			e.WriteUint(val.Uint())
+			break
+		}
+		// 64-bit integers are written out as JSON string.
+		e.WriteQuotedUint(val.Uint())
 
 	case protoreflect.FloatKind:
 		// Encoder.WriteFloat handles the special numbers NaN and infinites.
@@ -322,14 +472,15 @@ func (e encoder) marshalSingular(val protoreflect.Value, fd protoreflect.FieldDe
 		e.WriteFloat(val.Float(), 64)
 
 	case protoreflect.BytesKind:
-		e.WriteString(base64.StdEncoding.EncodeToString(val.Bytes()))
+		e.WriteBase64(val.Bytes())
 
 	case protoreflect.EnumKind:
 		if fd.Enum().FullName() == genid.NullValue_enum_fullname {
 			e.WriteNull()
 		} else {
 			desc := fd.Enum().Values().ByNumber(val.Enum())
-			if e.opts.UseEnumNumbers || desc == nil {
+			if e.opts.UseEnumNumbers || desc == nil ||
+				(e.opts.UseEnumNumbersFor != nil && e.opts.UseEnumNumbersFor(fd)) {
 				e.WriteInt(int64(val.Enum()))
 			} else {
 				e.WriteString(string(desc.Name()))
@@ -348,7 +499,7 @@ func (e encoder) marshalSingular(val protoreflect.Value, fd protoreflect.FieldDe
 }
 
 // marshalList marshals the given protoreflect.List.
-func (e encoder) marshalList(list protoreflect.List, fd protoreflect.FieldDescriptor) error {
+func (e *encoder) marshalList(list protoreflect.List, fd protoreflect.FieldDescriptor) error {
 	e.StartArray()
 	defer e.EndArray()
 
@@ -362,13 +513,28 @@ func (e encoder) marshalList(list protoreflect.List, fd protoreflect.FieldDescri
 }
 
 // marshalMap marshals given protoreflect.Map.
-func (e encoder) marshalMap(mmap protoreflect.Map, fd protoreflect.FieldDescriptor) error {
+func (e *encoder) marshalMap(mmap protoreflect.Map, fd protoreflect.FieldDescriptor) error {
 	e.StartObject()
 	defer e.EndObject()
 
 	var err error
+	keyKind := fd.MapKey().Kind()
 	order.RangeEntries(mmap, order.GenericKeyOrder, func(k protoreflect.MapKey, v protoreflect.Value) bool {
-		if err = e.WriteName(k.String()); err != nil {
+		// Format integer keys directly, avoiding the allocations of
+		// protoreflect.MapKey.String.
+		var buf [20]byte
+		var name string
+		switch keyKind {
+		case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+			protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+			name = string(strconv.AppendInt(buf[:0], k.Int(), 10))
+		case protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+			protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+			name = string(strconv.AppendUint(buf[:0], k.Uint(), 10))
+		default:
+			name = k.String()
+		}
+		if err = e.WriteName(name); err != nil {
 			return false
 		}
 		if err = e.marshalSingular(v, fd.MapValue()); err != nil {
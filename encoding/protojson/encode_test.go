@@ -15,11 +15,13 @@ import (
 	"google.golang.org/protobuf/internal/detrand"
 	"google.golang.org/protobuf/internal/flags"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/reflect/protoregistry"
 	"google.golang.org/protobuf/testing/protopack"
 
 	pb2 "google.golang.org/protobuf/internal/testprotos/textpb2"
 	pb3 "google.golang.org/protobuf/internal/testprotos/textpb3"
+	pbeditions "google.golang.org/protobuf/internal/testprotos/textpbeditions"
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/emptypb"
@@ -350,6 +352,36 @@ func TestMarshal(t *testing.T) {
       "optFixed32": 47
     }
   }
+}`,
+	}, {
+		desc: "protoeditions delimited encoded message fields",
+		input: &pbeditions.Nests{
+			Optgroup: &pbeditions.Nests_OptGroup{
+				OptString: proto.String("inside a group"),
+				Optnestedgroup: &pbeditions.Nests_OptGroup_OptNestedGroup{
+					OptFixed32: proto.Uint32(47),
+				},
+			},
+			DelimitedField: &pbeditions.Nests_OptGroup{
+				OptString: proto.String("second group"),
+				NestedDelimitedField: &pbeditions.Nests_OptGroup_OptNestedGroup{
+					OptFixed32: proto.Uint32(48),
+				},
+			},
+		},
+		want: `{
+  "optgroup": {
+    "optString": "inside a group",
+    "optnestedgroup": {
+      "optFixed32": 47
+    }
+  },
+  "delimitedField": {
+    "optString": "second group",
+    "nestedDelimitedField": {
+      "optFixed32": 48
+    }
+  }
 }`,
 	}, {
 		desc:  "proto3 nested message not set",
@@ -2408,6 +2440,79 @@ func TestMarshal(t *testing.T) {
   "optSfixed32": -32,
   "optFloat": 1.02,
   "optBytes": "6LC35q2M"
+}`,
+	}, {
+		desc:  "EmitUnsetDefaults: proto2 enums",
+		mo:    protojson.MarshalOptions{EmitUnsetDefaults: true},
+		input: &pb2.Enums{OptNestedEnum: pb2.Enums_DOS.Enum()},
+		want: `{
+  "optEnum": "ONE",
+  "optNestedEnum": "DOS"
+}`,
+	}, {
+		desc:  "EmitUnsetDefaults: with EmitUnpopulated",
+		mo:    protojson.MarshalOptions{EmitUnsetDefaults: true, EmitUnpopulated: true},
+		input: &pb2.Enums{},
+		want: `{
+  "optEnum": "ONE",
+  "rptEnum": [],
+  "optNestedEnum": "UNO",
+  "rptNestedEnum": []
+}`,
+	}, {
+		desc: "OmitSetDefaults",
+		mo:   protojson.MarshalOptions{OmitSetDefaults: true},
+		input: &pb2.Scalars{
+			OptBool:   proto.Bool(false),
+			OptInt32:  proto.Int32(0),
+			OptInt64:  proto.Int64(5),
+			OptBytes:  []byte{},
+			OptString: proto.String(""),
+		},
+		want: `{
+  "optInt64": "5"
+}`,
+	}, {
+		desc: "OmitSetDefaults: with EmitUnpopulated",
+		mo:   protojson.MarshalOptions{OmitSetDefaults: true, EmitUnpopulated: true},
+		input: &pb2.Enums{
+			OptEnum:       pb2.Enum_ONE.Enum(),
+			OptNestedEnum: pb2.Enums_DOS.Enum(),
+		},
+		want: `{
+  "optEnum": null,
+  "rptEnum": [],
+  "optNestedEnum": "DOS",
+  "rptNestedEnum": []
+}`,
+	}, {
+		desc: "OmitSetDefaults: with EmitUnsetDefaults",
+		mo:   protojson.MarshalOptions{OmitSetDefaults: true, EmitUnsetDefaults: true},
+		input: &pb2.Enums{
+			OptEnum: pb2.Enum_ONE.Enum(),
+		},
+		want: `{
+  "optEnum": "ONE",
+  "optNestedEnum": "UNO"
+}`,
+	}, {
+		desc: "OmitSetDefaults: extensions and oneofs",
+		mo:   protojson.MarshalOptions{OmitSetDefaults: true},
+		input: func() proto.Message {
+			m := &pb2.Extensions{}
+			proto.SetExtension(m, pb2.E_OptExtBool, false)
+			proto.SetExtension(m, pb2.E_OptExtString, "x")
+			return m
+		}(),
+		want: `{
+  "[pb2.opt_ext_string]": "x"
+}`,
+	}, {
+		desc:  "OmitSetDefaults: oneof field",
+		mo:    protojson.MarshalOptions{OmitSetDefaults: true},
+		input: &pb3.Oneofs{Union: &pb3.Oneofs_OneofString{}},
+		want: `{
+  "oneofString": ""
 }`,
 	}, {
 		desc: "UseEnumNumbers in singular field",
@@ -2458,6 +2563,137 @@ func TestMarshal(t *testing.T) {
     "10": 10,
     "47": 47
   }
+}`,
+	}, {
+		desc: "UseEnumNumbersFor selected fields",
+		mo: protojson.MarshalOptions{UseEnumNumbersFor: func(fd protoreflect.FieldDescriptor) bool {
+			return fd.Name() == "opt_nested_enum" || fd.Name() == "rpt_enum"
+		}},
+		input: &pb2.Enums{
+			OptEnum:       pb2.Enum_ONE.Enum(),
+			OptNestedEnum: pb2.Enums_UNO.Enum(),
+			RptEnum:       []pb2.Enum{pb2.Enum_ONE, pb2.Enum_TEN},
+			RptNestedEnum: []pb2.Enums_NestedEnum{pb2.Enums_DOS},
+		},
+		want: `{
+  "optEnum": "ONE",
+  "rptEnum": [
+    1,
+    10
+  ],
+  "optNestedEnum": 1,
+  "rptNestedEnum": [
+    "DOS"
+  ]
+}`,
+	}, {
+		desc: "UseEnumNumbersFor in map field",
+		mo: protojson.MarshalOptions{UseEnumNumbersFor: func(fd protoreflect.FieldDescriptor) bool {
+			return fd.ContainingMessage().FullName() == "pb3.Maps.Uint64ToEnumEntry"
+		}},
+		input: &pb3.Maps{
+			Uint64ToEnum: map[uint64]pb3.Enum{
+				1: pb3.Enum_ONE,
+			},
+		},
+		want: `{
+  "uint64ToEnum": {
+    "1": 1
+  }
+}`,
+	}, {
+		desc: "UseNumbersForInt64",
+		mo:   protojson.MarshalOptions{UseNumbersForInt64: true},
+		input: &pb2.Scalars{
+			OptInt64:    proto.Int64(math.MinInt64),
+			OptUint64:   proto.Uint64(math.MaxUint64),
+			OptSint64:   proto.Int64(-1),
+			OptFixed64:  proto.Uint64(1 << 53),
+			OptSfixed64: proto.Int64(math.MaxInt64),
+		},
+		want: `{
+  "optInt64": -9223372036854775808,
+  "optUint64": 18446744073709551615,
+  "optSint64": -1,
+  "optFixed64": 9007199254740992,
+  "optSfixed64": 9223372036854775807
+}`,
+	}, {
+		desc: "UseNumbersForInt64 in map keys",
+		mo:   protojson.MarshalOptions{UseNumbersForInt64: true},
+		input: &pb3.Maps{
+			Uint64ToEnum: map[uint64]pb3.Enum{math.MaxUint64: pb3.Enum_ONE},
+		},
+		want: `{
+  "uint64ToEnum": {
+    "18446744073709551615": "ONE"
+  }
+}`,
+	}, {
+		desc:  "UseNumbersForInt64 in UInt64Value",
+		mo:    protojson.MarshalOptions{UseNumbersForInt64: true},
+		input: &wrapperspb.UInt64Value{Value: math.MaxUint64},
+		want:  `18446744073709551615`,
+	}, {
+		desc: "SortFieldsByNumber",
+		mo:   protojson.MarshalOptions{SortFieldsByNumber: true},
+		input: &pb2.Scalars{
+			OptBool:   proto.Bool(true),
+			OptFloat:  proto.Float32(1),
+			OptDouble: proto.Float64(2),
+			OptBytes:  []byte("a"),
+			OptString: proto.String("b"),
+		},
+		want: `{
+  "optBool": true,
+  "optString": "b",
+  "optBytes": "YQ==",
+  "optFloat": 1,
+  "optDouble": 2
+}`,
+	}, {
+		desc: "SortFieldsByName",
+		mo:   protojson.MarshalOptions{SortFieldsByName: true, SortFieldsByNumber: true},
+		input: &pb2.Scalars{
+			OptBool:    proto.Bool(true),
+			OptFloat:   proto.Float32(1),
+			OptDouble:  proto.Float64(2),
+			OptBytes:   []byte("a"),
+			OptString:  proto.String("b"),
+			OptFixed32: proto.Uint32(3),
+		},
+		want: `{
+  "optBool": true,
+  "optBytes": "YQ==",
+  "optDouble": 2,
+  "optFixed32": 3,
+  "optFloat": 1,
+  "optString": "b"
+}`,
+	}, {
+		desc: "SortFieldsByName with Any",
+		mo: protojson.MarshalOptions{
+			SortFieldsByName: true,
+			UseProtoNames:    true,
+		},
+		input: func() proto.Message {
+			m := &pb2.Nested{
+				OptString: proto.String("embedded inside Any"),
+				OptNested: &pb2.Nested{},
+			}
+			b, err := proto.MarshalOptions{Deterministic: true}.Marshal(m)
+			if err != nil {
+				t.Fatalf("error in binary marshaling message for Any.value: %v", err)
+			}
+			return &anypb.Any{
+				TypeUrl: "foo/pb2.Nested",
+				Value:   b,
+			}
+		}(),
+		want: `{
+  "@type": "foo/pb2.Nested",
+  "opt_nested": {},
+  "opt_string": "embedded inside Any"
 }`,
 	}, {
 		desc: "UseProtoNames",
@@ -0,0 +1,242 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protojson
+
+import (
+	stdjson "encoding/json"
+	"math"
+	"sort"
+	"strconv"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/internal/encoding/json"
+	"google.golang.org/protobuf/internal/errors"
+	"google.golang.org/protobuf/internal/genid"
+	"google.golang.org/protobuf/internal/pragma"
+)
+
+// MarshalInterface returns the JSON encoding of v, which must be
+// a general-purpose Go value as produced by structpb.Value.AsInterface.
+// See [InterfaceOptions.MarshalInterface].
+func MarshalInterface(v any) ([]byte, error) {
+	return InterfaceOptions{}.MarshalInterface(v)
+}
+
+// UnmarshalInterface parses the JSON-encoded data b into a general-purpose
+// Go value. See [InterfaceOptions.UnmarshalInterface].
+func UnmarshalInterface(b []byte) (any, error) {
+	return InterfaceOptions{}.UnmarshalInterface(b)
+}
+
+// InterfaceOptions configures the conversion between JSON and
+// general-purpose Go values by MarshalInterface and UnmarshalInterface.
+type InterfaceOptions struct {
+	pragma.NoUnkeyedLiterals
+
+	// Multiline and Indent control the output of MarshalInterface
+	// as they do for [MarshalOptions].
+	Multiline bool
+	Indent    string
+
+	// RecursionLimit limits how deeply objects and arrays may be nested
+	// in the input of UnmarshalInterface. If zero, a default limit is applied.
+	RecursionLimit int
+
+	// UseNumber specifies that UnmarshalInterface converts numbers to
+	// json.Number rather than float64, so that integers which cannot be
+	// represented exactly as a float64 do not lose precision.
+	UseNumber bool
+
+	// AllowNonFinite specifies that MarshalInterface writes NaN and infinite
+	// float64 values as the strings "NaN", "Infinity", and "-Infinity",
+	// as does structpb.Value.AsInterface, rather than reporting an error.
+	AllowNonFinite bool
+}
+
+// MarshalInterface returns the JSON encoding of v using options in the
+// InterfaceOptions object.
+//
+// The value v must be nil or of type bool, float64, json.Number, string,
+// map[string]any, or []any, where the map values and slice elements are
+// themselves values of these types. A json.Number is written as a number
+// without being converted to a float64 if it is an integer.
+// Otherwise, the output is the same as that of marshaling the equivalent
+// google.protobuf.Value message, without constructing the message.
+func (o InterfaceOptions) MarshalInterface(v any) ([]byte, error) {
+	indent := o.Indent
+	if o.Multiline && indent == "" {
+		indent = defaultIndent
+	}
+	enc, err := json.NewEncoder(nil, indent)
+	if err != nil {
+		return nil, err
+	}
+	if err := o.marshalInterface(enc, v); err != nil {
+		return nil, err
+	}
+	return enc.Bytes(), nil
+}
+
+func (o InterfaceOptions) marshalInterface(enc *json.Encoder, v any) error {
+	switch v := v.(type) {
+	case nil:
+		enc.WriteNull()
+	case bool:
+		enc.WriteBool(v)
+	case float64:
+		if (math.IsNaN(v) || math.IsInf(v, 0)) && !o.AllowNonFinite {
+			return errors.New("%s: invalid %v value", genid.Value_NumberValue_field_fullname, v)
+		}
+		enc.WriteFloat(v, 64)
+	case stdjson.Number:
+		if n, err := v.Int64(); err == nil {
+			enc.WriteInt(n)
+		} else if n, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			enc.WriteUint(n)
+		} else if n, err := v.Float64(); err == nil {
+			return o.marshalInterface(enc, n)
+		} else {
+			return errors.New("%s: invalid number %q", genid.Value_NumberValue_field_fullname, string(v))
+		}
+	case string:
+		return enc.WriteString(v)
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		enc.StartObject()
+		for _, k := range keys {
+			if err := enc.WriteName(k); err != nil {
+				return err
+			}
+			if err := o.marshalInterface(enc, v[k]); err != nil {
+				return err
+			}
+		}
+		enc.EndObject()
+	case []any:
+		enc.StartArray()
+		for _, v := range v {
+			if err := o.marshalInterface(enc, v); err != nil {
+				return err
+			}
+		}
+		enc.EndArray()
+	default:
+		return errors.New("invalid type %T for %s", v, genid.Value_message_fullname)
+	}
+	return nil
+}
+
+// UnmarshalInterface parses the JSON-encoded data b into a general-purpose
+// Go value using options in the InterfaceOptions object.
+//
+// The result is the same as that of unmarshaling into a google.protobuf.Value
+// message and calling structpb.Value.AsInterface, without constructing the
+// message: a JSON object is converted to map[string]any, an array to []any,
+// a number to float64 (or json.Number if UseNumber is set), a string to
+// string, a boolean to bool, and null to nil.
+func (o InterfaceOptions) UnmarshalInterface(b []byte) (any, error) {
+	if o.RecursionLimit == 0 {
+		o.RecursionLimit = protowire.DefaultRecursionLimit
+	}
+	d := interfaceDecoder{
+		decoder:   decoder{Decoder: json.NewDecoder(b), opts: UnmarshalOptions{RecursionLimit: o.RecursionLimit}},
+		useNumber: o.UseNumber,
+	}
+	v, err := d.unmarshalInterface()
+	if err != nil {
+		return nil, err
+	}
+
+	// Check for EOF.
+	tok, err := d.Read()
+	if err != nil {
+		return nil, err
+	}
+	if tok.Kind() != json.EOF {
+		return nil, d.unexpectedTokenError(tok)
+	}
+	return v, nil
+}
+
+// interfaceDecoder decodes JSON values into general-purpose Go values.
+type interfaceDecoder struct {
+	decoder
+	useNumber bool
+}
+
+func (d interfaceDecoder) unmarshalInterface() (any, error) {
+	tok, err := d.Read()
+	if err != nil {
+		return nil, err
+	}
+	switch tok.Kind() {
+	case json.Null:
+		return nil, nil
+	case json.Bool:
+		return tok.Bool(), nil
+	case json.Number:
+		if d.useNumber {
+			return stdjson.Number(tok.RawString()), nil
+		}
+		v, ok := unmarshalFloat(tok, 64)
+		if !ok {
+			return nil, d.newError(tok.Pos(), "invalid %v: %v", genid.Value_message_fullname, tok.RawString())
+		}
+		return v.Float(), nil
+	case json.String:
+		return tok.ParsedString(), nil
+	case json.ObjectOpen:
+		if d.opts.RecursionLimit--; d.opts.RecursionLimit < 0 {
+			return nil, errors.New("exceeded max recursion depth")
+		}
+		m := make(map[string]any)
+		for {
+			tok, err := d.Read()
+			if err != nil {
+				return nil, err
+			}
+			switch tok.Kind() {
+			case json.ObjectClose:
+				return m, nil
+			case json.Name:
+			default:
+				return nil, d.unexpectedTokenError(tok)
+			}
+			name := tok.Name()
+			if _, ok := m[name]; ok {
+				return nil, d.newError(tok.Pos(), "duplicate map key %v", tok.RawString())
+			}
+			if m[name], err = d.unmarshalInterface(); err != nil {
+				return nil, err
+			}
+		}
+	case json.ArrayOpen:
+		if d.opts.RecursionLimit--; d.opts.RecursionLimit < 0 {
+			return nil, errors.New("exceeded max recursion depth")
+		}
+		s := []any{}
+		for {
+			tok, err := d.Peek()
+			if err != nil {
+				return nil, err
+			}
+			if tok.Kind() == json.ArrayClose {
+				d.Read()
+				return s, nil
+			}
+			v, err := d.unmarshalInterface()
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, v)
+		}
+	default:
+		return nil, d.unexpectedTokenError(tok)
+	}
+}
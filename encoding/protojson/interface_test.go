@@ -0,0 +1,146 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protojson_test
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestUnmarshalInterface(t *testing.T) {
+	for _, in := range []string{
+		`null`,
+		`true`,
+		`-1.5e3`,
+		`"aé"`,
+		`[]`,
+		`{}`,
+		`{"a": [1, "b", null, {"c": false}], "d": {"e": {}}}`,
+	} {
+		got, err := protojson.UnmarshalInterface([]byte(in))
+		if err != nil {
+			t.Errorf("UnmarshalInterface(%s) error: %v", in, err)
+			continue
+		}
+		v := &structpb.Value{}
+		if err := protojson.Unmarshal([]byte(in), v); err != nil {
+			t.Fatalf("Unmarshal(%s) error: %v", in, err)
+		}
+		if diff := cmp.Diff(v.AsInterface(), got); diff != "" {
+			t.Errorf("UnmarshalInterface(%s) mismatch (-want +got):\n%s", in, diff)
+		}
+	}
+
+	for _, in := range []string{
+		``,
+		`{"a": 1, "a": 2}`,
+		`[1,]`,
+		`{} {}`,
+		`"\xff"`,
+	} {
+		if _, err := protojson.UnmarshalInterface([]byte(in)); err == nil {
+			t.Errorf("UnmarshalInterface(%q): want error, got nil", in)
+		}
+	}
+
+	nested := strings.Repeat("[", 5) + strings.Repeat("]", 5)
+	if _, err := (protojson.InterfaceOptions{RecursionLimit: 5}).UnmarshalInterface([]byte(nested)); err != nil {
+		t.Errorf("UnmarshalInterface(%s) with RecursionLimit 5: %v", nested, err)
+	}
+	if _, err := (protojson.InterfaceOptions{RecursionLimit: 4}).UnmarshalInterface([]byte(nested)); err == nil {
+		t.Errorf("UnmarshalInterface(%s) with RecursionLimit 4: want error, got nil", nested)
+	}
+}
+
+func TestMarshalInterface(t *testing.T) {
+	in := map[string]any{
+		"b": []any{1.5, "x", nil, true},
+		"a": map[string]any{},
+	}
+	got, err := protojson.MarshalInterface(in)
+	if err != nil {
+		t.Fatalf("MarshalInterface() error: %v", err)
+	}
+	v, err := structpb.NewValue(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := protojson.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("MarshalInterface() = %s, want %s", got, want)
+	}
+
+	got, err = protojson.InterfaceOptions{Multiline: true}.MarshalInterface(in)
+	if err != nil {
+		t.Fatalf("MarshalInterface() error: %v", err)
+	}
+	want, err = protojson.MarshalOptions{Multiline: true}.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("MarshalInterface() = %s, want %s", got, want)
+	}
+
+	for _, in := range []any{math.NaN(), 1, []string{"a"}, map[string]any{"a": math.Inf(1)}} {
+		if _, err := protojson.MarshalInterface(in); err == nil {
+			t.Errorf("MarshalInterface(%v): want error, got nil", in)
+		}
+	}
+}
+
+func TestInterfaceOptionsNumbers(t *testing.T) {
+	const in = `{"big":9007199254740993,"neg":-9007199254740993,"huge":18446744073709551615,"frac":1.5}`
+	got, err := (protojson.InterfaceOptions{UseNumber: true}).UnmarshalInterface([]byte(in))
+	if err != nil {
+		t.Fatalf("UnmarshalInterface(%s) error: %v", in, err)
+	}
+	want := map[string]any{
+		"big":  json.Number("9007199254740993"),
+		"neg":  json.Number("-9007199254740993"),
+		"huge": json.Number("18446744073709551615"),
+		"frac": json.Number("1.5"),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("UnmarshalInterface(%s) mismatch (-want +got):\n%s", in, diff)
+	}
+	b, err := protojson.MarshalInterface(got)
+	if err != nil {
+		t.Fatalf("MarshalInterface() error: %v", err)
+	}
+	if roundTrip, err := (protojson.InterfaceOptions{UseNumber: true}).UnmarshalInterface(b); err != nil || !cmp.Equal(roundTrip, want) {
+		t.Errorf("MarshalInterface() = %s, which does not preserve the numbers in %s", b, in)
+	}
+	if _, err := protojson.MarshalInterface(json.Number("1e400")); err == nil {
+		t.Errorf("MarshalInterface(json.Number(1e400)): want error, got nil")
+	}
+
+	for _, tt := range []struct {
+		in   float64
+		want string
+	}{
+		{math.NaN(), `"NaN"`},
+		{math.Inf(+1), `"Infinity"`},
+		{math.Inf(-1), `"-Infinity"`},
+	} {
+		if _, err := protojson.MarshalInterface(tt.in); err == nil {
+			t.Errorf("MarshalInterface(%v): want error, got nil", tt.in)
+		}
+		got, err := protojson.InterfaceOptions{AllowNonFinite: true}.MarshalInterface(tt.in)
+		if err != nil || string(got) != tt.want {
+			t.Errorf("MarshalInterface(%v) with AllowNonFinite = %s, %v; want %s", tt.in, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protojson
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"google.golang.org/protobuf/internal/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+// Encoder writes a stream of JSON-encoded messages to an output stream.
+// Each message is followed by a newline, producing newline-delimited JSON,
+// unless MarshalOptions.Multiline is set. Multiline output spans several
+// lines per message and is thus not newline-delimited, though it can still
+// be read by a [Decoder].
+type Encoder struct {
+	w    io.Writer
+	opts MarshalOptions
+	buf  []byte
+}
+
+// NewEncoder returns a new encoder that writes to w using the default options.
+func NewEncoder(w io.Writer) *Encoder {
+	return MarshalOptions{}.NewEncoder(w)
+}
+
+// NewEncoder returns a new encoder that writes to w using the options in o.
+func (o MarshalOptions) NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w, opts: o}
+}
+
+// Encode writes the JSON encoding of m to the stream, followed by a newline.
+func (e *Encoder) Encode(m proto.Message) error {
+	b, err := e.opts.MarshalAppend(e.buf[:0], m)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	e.buf = b
+	_, err = e.w.Write(b)
+	return err
+}
+
+// Decoder reads a stream of JSON-encoded messages from an input stream.
+//
+// The stream may either be a sequence of whitespace-separated JSON objects
+// (such as newline-delimited JSON), or a single JSON array of objects.
+// In both cases, only a single message is held in memory at a time.
+type Decoder struct {
+	r       *bufio.Reader
+	dec     *json.Decoder
+	opts    UnmarshalOptions
+	inArray bool
+}
+
+// NewDecoder returns a new decoder that reads from r using the default options.
+func NewDecoder(r io.Reader) *Decoder {
+	return UnmarshalOptions{}.NewDecoder(r)
+}
+
+// NewDecoder returns a new decoder that reads from r using the options in o.
+func (o UnmarshalOptions) NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r), opts: o}
+}
+
+// Decode reads the next JSON-encoded message from the stream into m.
+// The provided message must be mutable (e.g., a non-nil pointer to a message).
+//
+// It returns [io.EOF] when there are no more messages in the stream.
+func (d *Decoder) Decode(m proto.Message) error {
+	if d.dec == nil {
+		if err := d.start(); err != nil {
+			return err
+		}
+	}
+	if d.inArray && !d.dec.More() {
+		// Consume the closing bracket of the array.
+		if _, err := d.dec.Token(); err != nil {
+			return d.wrapError(err)
+		}
+		d.inArray = false
+		if d.dec.More() {
+			return errors.New("unexpected data after top-level array")
+		}
+		return io.EOF
+	}
+	var raw json.RawMessage
+	if err := d.dec.Decode(&raw); err != nil {
+		if err == io.EOF && !d.inArray {
+			return io.EOF
+		}
+		return d.wrapError(err)
+	}
+	return d.opts.Unmarshal(raw, m)
+}
+
+// start determines whether the stream is a JSON array,
+// consuming the opening bracket if so.
+func (d *Decoder) start() error {
+	for {
+		c, err := d.r.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				// An empty stream contains no messages.
+				d.dec = json.NewDecoder(d.r)
+			}
+			return err
+		}
+		switch c {
+		case ' ', '\n', '\r', '\t':
+			continue
+		}
+		if err := d.r.UnreadByte(); err != nil {
+			return err
+		}
+		d.dec = json.NewDecoder(d.r)
+		if c == '[' {
+			if _, err := d.dec.Token(); err != nil {
+				return d.wrapError(err)
+			}
+			d.inArray = true
+		}
+		return nil
+	}
+}
+
+func (d *Decoder) wrapError(err error) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return errors.Wrap(err, "invalid JSON stream")
+}
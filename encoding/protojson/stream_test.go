@@ -0,0 +1,131 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protojson_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	pb3 "google.golang.org/protobuf/internal/testprotos/textpb3"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestDecoder(t *testing.T) {
+	want := []*pb3.Nested{
+		{SString: "a"},
+		{SString: "b", SNested: &pb3.Nested{SString: "c"}},
+		{},
+	}
+	tests := []struct {
+		desc    string
+		input   string
+		want    []*pb3.Nested
+		wantErr string
+	}{{
+		desc:  "empty stream",
+		input: "",
+	}, {
+		desc:  "whitespace only",
+		input: " \n\t ",
+	}, {
+		desc:    "non-ASCII whitespace",
+		input:   "\x85{}",
+		wantErr: "invalid JSON stream",
+	}, {
+		desc:  "newline-delimited",
+		input: "{\"sString\":\"a\"}\n{\"sString\":\"b\",\"sNested\":{\"sString\":\"c\"}}\n{}\n",
+		want:  want,
+	}, {
+		desc:  "concatenated",
+		input: `{"sString":"a"}{"sString":"b","sNested":{"sString":"c"}}{}`,
+		want:  want,
+	}, {
+		desc:  "array",
+		input: ` [ {"sString":"a"}, {"sString":"b","sNested":{"sString":"c"}}, {} ] `,
+		want:  want,
+	}, {
+		desc:  "empty array",
+		input: `[]`,
+	}, {
+		desc:    "unterminated array",
+		input:   `[{"sString":"a"}`,
+		want:    want[:1],
+		wantErr: "invalid JSON stream",
+	}, {
+		desc:    "invalid message",
+		input:   `{"sString":"a"} {"unknown":1}`,
+		want:    want[:1],
+		wantErr: "unknown field",
+	}, {
+		desc:    "invalid JSON",
+		input:   `{"sString":"a"} {`,
+		want:    want[:1],
+		wantErr: "invalid JSON stream",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			dec := protojson.NewDecoder(strings.NewReader(tt.input))
+			var got []*pb3.Nested
+			var err error
+			for {
+				m := &pb3.Nested{}
+				if err = dec.Decode(m); err != nil {
+					break
+				}
+				got = append(got, m)
+			}
+			switch {
+			case tt.wantErr == "" && err != io.EOF:
+				t.Errorf("Decode() error = %v, want io.EOF", err)
+			case tt.wantErr != "" && (err == io.EOF || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Decode() error = %v, want error containing %q", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("decoded %d messages, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if !proto.Equal(got[i], tt.want[i]) {
+					t.Errorf("message %d: got %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEncoderRoundTrip(t *testing.T) {
+	msgs := []proto.Message{
+		wrapperspb.String("hello"),
+		wrapperspb.String(""),
+		wrapperspb.String("world"),
+	}
+	for _, mo := range []protojson.MarshalOptions{{}, {Multiline: true}} {
+		var buf bytes.Buffer
+		enc := mo.NewEncoder(&buf)
+		for _, m := range msgs {
+			if err := enc.Encode(m); err != nil {
+				t.Fatalf("Encode(%v) error: %v", m, err)
+			}
+		}
+
+		dec := protojson.NewDecoder(&buf)
+		for _, want := range msgs {
+			got := &wrapperspb.StringValue{}
+			if err := dec.Decode(got); err != nil {
+				t.Fatalf("Decode() error: %v", err)
+			}
+			if !proto.Equal(got, want) {
+				t.Errorf("Decode() = %v, want %v", got, want)
+			}
+		}
+		if err := dec.Decode(&wrapperspb.StringValue{}); err != io.EOF {
+			t.Errorf("Decode() at end of stream = %v, want io.EOF", err)
+		}
+	}
+}
@@ -20,7 +20,7 @@ import (
 	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
-type marshalFunc func(encoder, protoreflect.Message) error
+type marshalFunc func(*encoder, protoreflect.Message) error
 
 // wellKnownTypeMarshaler returns a marshal function if the message type
 // has specialized serialization behavior. It returns nil otherwise.
@@ -28,11 +28,11 @@ func wellKnownTypeMarshaler(name protoreflect.FullName) marshalFunc {
 	if name.Parent() == genid.GoogleProtobuf_package {
 		switch name.Name() {
 		case genid.Any_message_name:
-			return encoder.marshalAny
+			return (*encoder).marshalAny
 		case genid.Timestamp_message_name:
-			return encoder.marshalTimestamp
+			return (*encoder).marshalTimestamp
 		case genid.Duration_message_name:
-			return encoder.marshalDuration
+			return (*encoder).marshalDuration
 		case genid.BoolValue_message_name,
 			genid.Int32Value_message_name,
 			genid.Int64Value_message_name,
@@ -42,17 +42,17 @@ func wellKnownTypeMarshaler(name protoreflect.FullName) marshalFunc {
 			genid.DoubleValue_message_name,
 			genid.StringValue_message_name,
 			genid.BytesValue_message_name:
-			return encoder.marshalWrapperType
+			return (*encoder).marshalWrapperType
 		case genid.Struct_message_name:
-			return encoder.marshalStruct
+			return (*encoder).marshalStruct
 		case genid.ListValue_message_name:
-			return encoder.marshalListValue
+			return (*encoder).marshalListValue
 		case genid.Value_message_name:
-			return encoder.marshalKnownValue
+			return (*encoder).marshalKnownValue
 		case genid.FieldMask_message_name:
-			return encoder.marshalFieldMask
+			return (*encoder).marshalFieldMask
 		case genid.Empty_message_name:
-			return encoder.marshalEmpty
+			return (*encoder).marshalEmpty
 		}
 	}
 	return nil
@@ -102,7 +102,7 @@ func wellKnownTypeUnmarshaler(name protoreflect.FullName) unmarshalFunc {
 // custom JSON representation, that representation will be embedded adding a
 // field `value` which holds the custom JSON in addition to the `@type` field.
 
-func (e encoder) marshalAny(m protoreflect.Message) error {
+func (e *encoder) marshalAny(m protoreflect.Message) error {
 	fds := m.Descriptor().Fields()
 	fdType := fds.ByNumber(genid.Any_TypeUrl_field_number)
 	fdValue := fds.ByNumber(genid.Any_Value_field_number)
@@ -176,7 +176,7 @@ func (d decoder) unmarshalAny(m protoreflect.Message) error {
 	// Use another decoder to parse the unread bytes for @type field. This
 	// avoids advancing a read from current decoder because the current JSON
 	// object may contain the fields of the embedded type.
-	dec := decoder{d.Clone(), UnmarshalOptions{RecursionLimit: d.opts.RecursionLimit}}
+	dec := decoder{Decoder: d.Clone(), opts: UnmarshalOptions{RecursionLimit: d.opts.RecursionLimit}, path: d.path}
 	tok, err := findTypeURL(dec)
 	switch err {
 	case errEmptyObject:
@@ -308,11 +308,22 @@ Loop:
 // array) in order to advance the read to the next JSON value. It relies on
 // the decoder returning an error if the types are not in valid sequence.
 func (d decoder) skipJSONValue() error {
+	_, err := d.readJSONValue()
+	return err
+}
+
+// readJSONValue is like skipJSONValue, but also returns the raw bytes of the
+// JSON value that was read.
+func (d decoder) readJSONValue() ([]byte, error) {
+	var first json.Token
 	var open int
-	for {
+	for n := 0; ; n++ {
 		tok, err := d.Read()
 		if err != nil {
-			return err
+			return nil, err
+		}
+		if n == 0 {
+			first = tok
 		}
 		switch tok.Kind() {
 		case json.ObjectClose, json.ArrayClose:
@@ -320,15 +331,15 @@ func (d decoder) skipJSONValue() error {
 		case json.ObjectOpen, json.ArrayOpen:
 			open++
 			if open > d.opts.RecursionLimit {
-				return errors.New("exceeded max recursion depth")
+				return nil, errors.New("exceeded max recursion depth")
 			}
 		case json.EOF:
 			// This can only happen if there's a bug in Decoder.Read.
 			// Avoid an infinite loop if this does happen.
-			return errors.New("unexpected EOF")
+			return nil, errors.New("unexpected EOF")
 		}
 		if open == 0 {
-			return nil
+			return d.RawSpan(first, tok), nil
 		}
 	}
 }
@@ -367,19 +378,16 @@ func (d decoder) unmarshalAnyValue(unmarshal unmarshalFunc, m protoreflect.Messa
 					return d.newError(tok.Pos(), `duplicate "value" field`)
 				}
 				// Unmarshal the field value into the given message.
-				if err := unmarshal(d, m); err != nil {
-					return err
+				vd := d.withPath("value")
+				if err := unmarshal(vd, m); err != nil {
+					return vd.locateError(err)
 				}
 				found = true
 
 			default:
-				if d.opts.DiscardUnknown {
-					if err := d.skipJSONValue(); err != nil {
-						return err
-					}
-					continue
+				if err := d.unmarshalUnknown(tok); err != nil {
+					return err
 				}
-				return d.newError(tok.Pos(), "unknown field %v", tok.RawString())
 			}
 		}
 	}
@@ -387,7 +395,7 @@ func (d decoder) unmarshalAnyValue(unmarshal unmarshalFunc, m protoreflect.Messa
 
 // Wrapper types are encoded as JSON primitives like string, number or boolean.
 
-func (e encoder) marshalWrapperType(m protoreflect.Message) error {
+func (e *encoder) marshalWrapperType(m protoreflect.Message) error {
 	fd := m.Descriptor().Fields().ByNumber(genid.WrapperValue_Value_field_number)
 	val := m.Get(fd)
 	return e.marshalSingular(val, fd)
@@ -405,7 +413,7 @@ func (d decoder) unmarshalWrapperType(m protoreflect.Message) error {
 
 // The JSON representation for Empty is an empty JSON object.
 
-func (e encoder) marshalEmpty(protoreflect.Message) error {
+func (e *encoder) marshalEmpty(protoreflect.Message) error {
 	e.StartObject()
 	e.EndObject()
 	return nil
@@ -447,7 +455,7 @@ func (d decoder) unmarshalEmpty(protoreflect.Message) error {
 // The JSON representation for Struct is a JSON object that contains the encoded
 // Struct.fields map and follows the serialization rules for a map.
 
-func (e encoder) marshalStruct(m protoreflect.Message) error {
+func (e *encoder) marshalStruct(m protoreflect.Message) error {
 	fd := m.Descriptor().Fields().ByNumber(genid.Struct_Fields_field_number)
 	return e.marshalMap(m.Get(fd).Map(), fd)
 }
@@ -461,7 +469,7 @@ func (d decoder) unmarshalStruct(m protoreflect.Message) error {
 // ListValue.values repeated field and follows the serialization rules for a
 // repeated field.
 
-func (e encoder) marshalListValue(m protoreflect.Message) error {
+func (e *encoder) marshalListValue(m protoreflect.Message) error {
 	fd := m.Descriptor().Fields().ByNumber(genid.ListValue_Values_field_number)
 	return e.marshalList(m.Get(fd).List(), fd)
 }
@@ -475,7 +483,7 @@ func (d decoder) unmarshalListValue(m protoreflect.Message) error {
 // set. Each of the field in the oneof has its own custom serialization rule. A
 // Value message needs to be a oneof field set, else it is an error.
 
-func (e encoder) marshalKnownValue(m protoreflect.Message) error {
+func (e *encoder) marshalKnownValue(m protoreflect.Message) error {
 	od := m.Descriptor().Oneofs().ByName(genid.Value_Kind_oneof_name)
 	fd := m.WhichOneof(od)
 	if fd == nil {
@@ -576,7 +584,7 @@ const (
 	maxSecondsInDuration = 315576000000
 )
 
-func (e encoder) marshalDuration(m protoreflect.Message) error {
+func (e *encoder) marshalDuration(m protoreflect.Message) error {
 	fds := m.Descriptor().Fields()
 	fdSeconds := fds.ByNumber(genid.Duration_Seconds_field_number)
 	fdNanos := fds.ByNumber(genid.Duration_Nanos_field_number)
@@ -596,18 +604,38 @@ func (e encoder) marshalDuration(m protoreflect.Message) error {
 	}
 	// Generated output always contains 0, 3, 6, or 9 fractional digits,
 	// depending on required precision, followed by the suffix "s".
-	var sign string
+	var buf [32]byte
+	b := buf[:0]
 	if secs < 0 || nanos < 0 {
-		sign, secs, nanos = "-", -1*secs, -1*nanos
+		b = append(b, '-')
+		secs, nanos = -1*secs, -1*nanos
 	}
-	x := fmt.Sprintf("%s%d.%09d", sign, secs, nanos)
-	x = strings.TrimSuffix(x, "000")
-	x = strings.TrimSuffix(x, "000")
-	x = strings.TrimSuffix(x, ".000")
-	e.WriteString(x + "s")
+	b = strconv.AppendInt(b, secs, 10)
+	b = appendFractionalNanos(b, nanos)
+	b = append(b, 's')
+	e.WriteString(string(b))
 	return nil
 }
 
+// appendFractionalNanos appends nanos as the fractional part of a number of
+// seconds, using 0, 3, 6, or 9 digits depending on the required precision.
+func appendFractionalNanos(b []byte, nanos int64) []byte {
+	if nanos == 0 {
+		return b
+	}
+	digits := 9
+	for digits > 3 && nanos%1000 == 0 {
+		nanos /= 1000
+		digits -= 3
+	}
+	b = append(b, ".000000000"[:1+digits]...)
+	for i := len(b) - 1; nanos > 0; i-- {
+		b[i] = byte('0' + nanos%10)
+		nanos /= 10
+	}
+	return b
+}
+
 func (d decoder) unmarshalDuration(m protoreflect.Message) error {
 	tok, err := d.Read()
 	if err != nil {
@@ -764,7 +792,7 @@ const (
 	minTimestampSeconds = -62135596800
 )
 
-func (e encoder) marshalTimestamp(m protoreflect.Message) error {
+func (e *encoder) marshalTimestamp(m protoreflect.Message) error {
 	fds := m.Descriptor().Fields()
 	fdSeconds := fds.ByNumber(genid.Timestamp_Seconds_field_number)
 	fdNanos := fds.ByNumber(genid.Timestamp_Nanos_field_number)
@@ -781,12 +809,11 @@ func (e encoder) marshalTimestamp(m protoreflect.Message) error {
 	}
 	// Uses RFC 3339, where generated output will be Z-normalized and uses 0, 3,
 	// 6 or 9 fractional digits.
-	t := time.Unix(secs, nanos).UTC()
-	x := t.Format("2006-01-02T15:04:05.000000000")
-	x = strings.TrimSuffix(x, "000")
-	x = strings.TrimSuffix(x, "000")
-	x = strings.TrimSuffix(x, ".000")
-	e.WriteString(x + "Z")
+	var buf [32]byte
+	b := time.Unix(secs, 0).UTC().AppendFormat(buf[:0], "2006-01-02T15:04:05")
+	b = appendFractionalNanos(b, nanos)
+	b = append(b, 'Z')
+	e.WriteString(string(b))
 	return nil
 }
 
@@ -830,7 +857,7 @@ func (d decoder) unmarshalTimestamp(m protoreflect.Message) error {
 // lower-camel naming conventions. Encoding should fail if the path name would
 // end up differently after a round-trip.
 
-func (e encoder) marshalFieldMask(m protoreflect.Message) error {
+func (e *encoder) marshalFieldMask(m protoreflect.Message) error {
 	fd := m.Descriptor().Fields().ByNumber(genid.FieldMask_Paths_field_number)
 	list := m.Get(fd).List()
 	paths := make([]string, 0, list.Len())
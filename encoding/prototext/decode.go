@@ -6,8 +6,11 @@ package prototext
 
 import (
 	"fmt"
+	"math"
+	"strings"
 	"unicode/utf8"
 
+	"google.golang.org/protobuf/encoding/protowire"
 	"google.golang.org/protobuf/internal/encoding/messageset"
 	"google.golang.org/protobuf/internal/encoding/text"
 	"google.golang.org/protobuf/internal/errors"
@@ -17,6 +20,7 @@ import (
 	"google.golang.org/protobuf/internal/set"
 	"google.golang.org/protobuf/internal/strs"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protopath"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/reflect/protoregistry"
 )
@@ -49,6 +53,41 @@ type UnmarshalOptions struct {
 		protoregistry.MessageTypeResolver
 		protoregistry.ExtensionTypeResolver
 	}
+
+	// AllowedAnyTypes, if non-nil, reports whether a google.protobuf.Any
+	// message with the given type URL is permitted in the input.
+	// It applies to both the expanded form and the type_url field.
+	// An error is reported for any Any message whose type URL is not
+	// permitted, so that inputs such as configuration files cannot embed
+	// messages of unexpected types. The type URL of an expanded Any
+	// must additionally be resolved by Resolver.
+	AllowedAnyTypes func(typeURL string) bool
+
+	// RecursionLimit limits how deeply messages may be nested.
+	// If zero, a default limit is applied.
+	RecursionLimit int
+
+	// LeadingComments, if non-nil, is called for each field in the input
+	// that is preceded by one or more lines of comments. It is called with
+	// the path of the field value and the text of each comment line,
+	// excluding the leading '#' character. Paths are constructed as described
+	// in [MarshalOptions.LeadingComments]. Comments within a map value are
+	// only reported if the map key precedes the value in the input.
+	LeadingComments func(path protopath.Path, lines []string)
+
+	// AllowFieldNumbers permits fields to be identified by field number,
+	// as unknown fields are by [MarshalOptions.EmitUnknown]. The value of
+	// such a field is interpreted according to its syntax rather than the
+	// type of the field: an unsigned or negative integer is a varint,
+	// a hexadecimal integer is a fixed32 value if it has at most 8 digits and
+	// a fixed64 value otherwise, a string is a length-delimited value,
+	// and a message of fields identified by number is a group.
+	// The resulting wire-format field is merged into the message as if
+	// by [proto.UnmarshalOptions.Unmarshal], so that it is stored as an
+	// unknown field unless the field number is known, such as when the
+	// input was produced with an older version of the message.
+	// Unknown fields are discarded if DiscardUnknown is set.
+	AllowFieldNumbers bool
 }
 
 // Unmarshal reads the given []byte and populates the given [proto.Message]
@@ -67,10 +106,16 @@ func (o UnmarshalOptions) unmarshal(b []byte, m proto.Message) error {
 	if o.Resolver == nil {
 		o.Resolver = protoregistry.GlobalTypes
 	}
+	if o.RecursionLimit == 0 {
+		o.RecursionLimit = protowire.DefaultRecursionLimit
+	}
 
-	dec := decoder{text.NewDecoder(b), o}
+	// Preallocate the path so that withStep does not allocate
+	// unless messages are deeply nested.
+	dec := decoder{Decoder: text.NewDecoder(b), opts: o, path: make(protopath.Path, 1, 8)}
+	dec.path[0] = protopath.Root(m.ProtoReflect().Descriptor())
 	if err := dec.unmarshalMessage(m.ProtoReflect(), false); err != nil {
-		return err
+		return dec.topLevelError(err, len(b))
 	}
 	if o.AllowPartial {
 		return nil
@@ -81,13 +126,69 @@ func (o UnmarshalOptions) unmarshal(b []byte, m proto.Message) error {
 type decoder struct {
 	*text.Decoder
 	opts UnmarshalOptions
+
+	// path is the path to the current value. Its backing array is shared
+	// with the decoders for sibling and nested values, so it must be
+	// copied with pathCopy before it is retained.
+	path protopath.Path
+}
+
+// withStep returns a copy of d with the given step appended to its path.
+func (d decoder) withStep(s protopath.Step) decoder {
+	d.path = append(d.path, s)
+	return d
+}
+
+// pathCopy returns a copy of the path to the current value.
+func (d decoder) pathCopy() protopath.Path {
+	return append(protopath.Path(nil), d.path...)
+}
+
+// reportComments reports the leading comments of tok for the current path.
+func (d decoder) reportComments(tok text.Token) {
+	if d.opts.LeadingComments != nil {
+		if lines := d.LeadingComments(tok); len(lines) > 0 {
+			d.opts.LeadingComments(d.pathCopy(), lines)
+		}
+	}
+}
+
+// UnmarshalError is an error in the input to [UnmarshalOptions.Unmarshal],
+// together with its location. Errors returned by Unmarshal for invalid input
+// can be inspected with [errors.As]; errors for missing required fields
+// are not of this type, since they do not have a location in the input.
+type UnmarshalError struct {
+	// Path is the path from the message being unmarshaled to the value
+	// in which the error was found. It starts with a [protopath.Root] step
+	// and is followed by the fields, list indexes, map keys and expanded
+	// Any messages leading to the value. A map key is omitted if the error
+	// was found in a map entry before its key.
+	Path protopath.Path
+
+	// Line and Column are the 1-based position in the input of the token
+	// at which the error was found, or zero if the position is unknown.
+	Line, Column int
+
+	err error
+}
+
+func (e *UnmarshalError) Error() string {
+	if len(e.Path) <= 1 {
+		return e.err.Error()
+	}
+	return e.err.Error() + " (at " + strings.TrimPrefix(e.Path[1:].String(), ".") + ")"
+}
+
+// Unwrap returns the underlying error, which matches [proto.Error].
+func (e *UnmarshalError) Unwrap() error {
+	return e.err
 }
 
 // newError returns an error object with position info.
 func (d decoder) newError(pos int, f string, x ...any) error {
 	line, column := d.Position(pos)
 	head := fmt.Sprintf("(line %d:%d): ", line, column)
-	return errors.New(head+f, x...)
+	return &UnmarshalError{Path: d.pathCopy(), Line: line, Column: column, err: errors.New(head+f, x...)}
 }
 
 // unexpectedTokenError returns a syntax error for the given unexpected token.
@@ -99,11 +200,38 @@ func (d decoder) unexpectedTokenError(tok text.Token) error {
 func (d decoder) syntaxError(pos int, f string, x ...any) error {
 	line, column := d.Position(pos)
 	head := fmt.Sprintf("syntax error (line %d:%d): ", line, column)
-	return errors.New(head+f, x...)
+	return &UnmarshalError{Path: d.pathCopy(), Line: line, Column: column, err: errors.New(head+f, x...)}
+}
+
+// locateError returns err as an *UnmarshalError. An error which does not
+// already have a location is located at the value currently being decoded.
+func (d decoder) locateError(err error) *UnmarshalError {
+	switch err := err.(type) {
+	case *UnmarshalError:
+		return err
+	case *text.SyntaxError:
+		return &UnmarshalError{Path: d.pathCopy(), Line: err.Line, Column: err.Column, err: err}
+	default:
+		return &UnmarshalError{Path: d.pathCopy(), err: err}
+	}
+}
+
+// topLevelError returns err as an *UnmarshalError for the top-level message.
+// An unexpected end of input is located at the end of the input of size n.
+func (d decoder) topLevelError(err error, n int) error {
+	e := d.locateError(err)
+	if e.Line == 0 && e.err == text.ErrUnexpectedEOF {
+		e.Line, e.Column = d.Position(n)
+	}
+	return e
 }
 
 // unmarshalMessage unmarshals into the given protoreflect.Message.
 func (d decoder) unmarshalMessage(m protoreflect.Message, checkDelims bool) error {
+	d.opts.RecursionLimit--
+	if d.opts.RecursionLimit < 0 {
+		return errors.New("exceeded max recursion depth")
+	}
 	messageDesc := m.Descriptor()
 	if !flags.ProtoLegacy && messageset.IsMessageSet(messageDesc) {
 		return errors.New("no support for proto1 MessageSets")
@@ -186,6 +314,23 @@ func (d decoder) unmarshalMessage(m protoreflect.Message, checkDelims bool) erro
 			return d.newError(tok.Pos(), "unable to resolve [%s]: %v", tok.RawString(), xtErr)
 		}
 
+		if isFieldNumberName && d.opts.AllowFieldNumbers && (fd != nil || !d.opts.DiscardUnknown) {
+			num := protowire.Number(tok.FieldNumber())
+			b, err := d.unmarshalWireField(nil, num, tok, d.opts.RecursionLimit)
+			if err != nil {
+				return err
+			}
+			if err := (proto.UnmarshalOptions{
+				Merge:          true,
+				AllowPartial:   true,
+				DiscardUnknown: d.opts.DiscardUnknown,
+				Resolver:       d.opts.Resolver,
+			}).Unmarshal(b, m.Interface()); err != nil {
+				return d.newError(tok.Pos(), "invalid value for field %v: %v", num, err)
+			}
+			continue
+		}
+
 		// Handle unknown fields.
 		if fd == nil {
 			if d.opts.DiscardUnknown || messageDesc.ReservedNames().Has(name) {
@@ -197,13 +342,6 @@ func (d decoder) unmarshalMessage(m protoreflect.Message, checkDelims bool) erro
 
 		// Handle fields identified by field number.
 		if isFieldNumberName {
-			// TODO: Add an option to permit parsing field numbers.
-			//
-			// This requires careful thought as the MarshalOptions.EmitUnknown
-			// option allows formatting unknown fields as the field number and the
-			// best-effort textual representation of the field value.  In that case,
-			// it may not be possible to unmarshal the value from a parser that does
-			// have information about the unknown field.
 			return d.newError(tok.Pos(), "cannot specify field by number: %v", tok.RawString())
 		}
 
@@ -215,14 +353,21 @@ func (d decoder) unmarshalMessage(m protoreflect.Message, checkDelims bool) erro
 			}
 
 			list := m.Mutable(fd).List()
+			d := d.withStep(protopath.FieldAccess(fd))
+			d.withStep(protopath.ListIndex(list.Len())).reportComments(tok)
 			if err := d.unmarshalList(fd, list); err != nil {
-				return err
+				return d.locateError(err)
 			}
 
 		case fd.IsMap():
 			mmap := m.Mutable(fd).Map()
-			if err := d.unmarshalMap(fd, mmap); err != nil {
-				return err
+			d := d.withStep(protopath.FieldAccess(fd))
+			key, err := d.unmarshalMap(fd, mmap)
+			if err != nil {
+				return d.locateError(err)
+			}
+			if key.IsValid() {
+				d.withStep(protopath.MapIndex(key)).reportComments(tok)
 			}
 
 		default:
@@ -231,6 +376,8 @@ func (d decoder) unmarshalMessage(m protoreflect.Message, checkDelims bool) erro
 				return d.syntaxError(tok.Pos(), "missing field separator :")
 			}
 
+			d := d.withStep(protopath.FieldAccess(fd))
+
 			// If field is a oneof, check if it has already been set.
 			if od := fd.ContainingOneof(); od != nil {
 				idx := uint64(od.Index())
@@ -245,8 +392,9 @@ func (d decoder) unmarshalMessage(m protoreflect.Message, checkDelims bool) erro
 				return d.newError(tok.Pos(), "non-repeated field %q is repeated", tok.RawString())
 			}
 
+			d.reportComments(tok)
 			if err := d.unmarshalSingular(fd, m); err != nil {
-				return err
+				return d.locateError(err)
 			}
 			seenNums.Set(num)
 		}
@@ -367,9 +515,10 @@ func (d decoder) unmarshalList(fd protoreflect.FieldDescriptor, list protoreflec
 		case text.ListOpen:
 			d.Read()
 			for {
+				d := d.withStep(protopath.ListIndex(list.Len()))
 				tok, err := d.Peek()
 				if err != nil {
-					return err
+					return d.locateError(err)
 				}
 
 				switch tok.Kind() {
@@ -379,7 +528,7 @@ func (d decoder) unmarshalList(fd protoreflect.FieldDescriptor, list protoreflec
 				case text.MessageOpen:
 					pval := list.NewElement()
 					if err := d.unmarshalMessage(pval.Message(), true); err != nil {
-						return err
+						return d.locateError(err)
 					}
 					list.Append(pval)
 				default:
@@ -388,9 +537,10 @@ func (d decoder) unmarshalList(fd protoreflect.FieldDescriptor, list protoreflec
 			}
 
 		case text.MessageOpen:
+			d := d.withStep(protopath.ListIndex(list.Len()))
 			pval := list.NewElement()
 			if err := d.unmarshalMessage(pval.Message(), true); err != nil {
-				return err
+				return d.locateError(err)
 			}
 			list.Append(pval)
 			return nil
@@ -401,9 +551,10 @@ func (d decoder) unmarshalList(fd protoreflect.FieldDescriptor, list protoreflec
 		case text.ListOpen:
 			d.Read()
 			for {
+				d := d.withStep(protopath.ListIndex(list.Len()))
 				tok, err := d.Peek()
 				if err != nil {
-					return err
+					return d.locateError(err)
 				}
 
 				switch tok.Kind() {
@@ -413,7 +564,7 @@ func (d decoder) unmarshalList(fd protoreflect.FieldDescriptor, list protoreflec
 				case text.Scalar:
 					pval, err := d.unmarshalScalar(fd)
 					if err != nil {
-						return err
+						return d.locateError(err)
 					}
 					list.Append(pval)
 				default:
@@ -422,9 +573,10 @@ func (d decoder) unmarshalList(fd protoreflect.FieldDescriptor, list protoreflec
 			}
 
 		case text.Scalar:
+			d := d.withStep(protopath.ListIndex(list.Len()))
 			pval, err := d.unmarshalScalar(fd)
 			if err != nil {
-				return err
+				return d.locateError(err)
 			}
 			list.Append(pval)
 			return nil
@@ -436,14 +588,15 @@ func (d decoder) unmarshalList(fd protoreflect.FieldDescriptor, list protoreflec
 
 // unmarshalMap unmarshals into given protoreflect.Map. A map value is a
 // textproto message containing {key: <kvalue>, value: <mvalue>}.
-func (d decoder) unmarshalMap(fd protoreflect.FieldDescriptor, mmap protoreflect.Map) error {
+// It returns the key of the first entry read.
+func (d decoder) unmarshalMap(fd protoreflect.FieldDescriptor, mmap protoreflect.Map) (protoreflect.MapKey, error) {
 	// Determine ahead whether map entry is a scalar type or a message type in
 	// order to call the appropriate unmarshalMapValue func inside
 	// unmarshalMapEntry.
-	var unmarshalMapValue func() (protoreflect.Value, error)
+	var unmarshalMapValue func(d decoder) (protoreflect.Value, error)
 	switch fd.MapValue().Kind() {
 	case protoreflect.MessageKind, protoreflect.GroupKind:
-		unmarshalMapValue = func() (protoreflect.Value, error) {
+		unmarshalMapValue = func(d decoder) (protoreflect.Value, error) {
 			pval := mmap.NewValue()
 			if err := d.unmarshalMessage(pval.Message(), true); err != nil {
 				return protoreflect.Value{}, err
@@ -451,14 +604,15 @@ func (d decoder) unmarshalMap(fd protoreflect.FieldDescriptor, mmap protoreflect
 			return pval, nil
 		}
 	default:
-		unmarshalMapValue = func() (protoreflect.Value, error) {
+		unmarshalMapValue = func(d decoder) (protoreflect.Value, error) {
 			return d.unmarshalScalar(fd.MapValue())
 		}
 	}
 
+	var first protoreflect.MapKey
 	tok, err := d.Read()
 	if err != nil {
-		return err
+		return first, err
 	}
 	switch tok.Kind() {
 	case text.MessageOpen:
@@ -468,28 +622,33 @@ func (d decoder) unmarshalMap(fd protoreflect.FieldDescriptor, mmap protoreflect
 		for {
 			tok, err := d.Read()
 			if err != nil {
-				return err
+				return first, err
 			}
 			switch tok.Kind() {
 			case text.ListClose:
-				return nil
+				return first, nil
 			case text.MessageOpen:
-				if err := d.unmarshalMapEntry(fd, mmap, unmarshalMapValue); err != nil {
-					return err
+				key, err := d.unmarshalMapEntry(fd, mmap, unmarshalMapValue)
+				if err != nil {
+					return first, err
+				}
+				if !first.IsValid() {
+					first = key
 				}
 			default:
-				return d.unexpectedTokenError(tok)
+				return first, d.unexpectedTokenError(tok)
 			}
 		}
 
 	default:
-		return d.unexpectedTokenError(tok)
+		return first, d.unexpectedTokenError(tok)
 	}
 }
 
 // unmarshalMap unmarshals into given protoreflect.Map. A map value is a
 // textproto message containing {key: <kvalue>, value: <mvalue>}.
-func (d decoder) unmarshalMapEntry(fd protoreflect.FieldDescriptor, mmap protoreflect.Map, unmarshalMapValue func() (protoreflect.Value, error)) error {
+// It returns the key of the entry.
+func (d decoder) unmarshalMapEntry(fd protoreflect.FieldDescriptor, mmap protoreflect.Map, unmarshalMapValue func(decoder) (protoreflect.Value, error)) (protoreflect.MapKey, error) {
 	var key protoreflect.MapKey
 	var pval protoreflect.Value
 Loop:
@@ -497,13 +656,13 @@ Loop:
 		// Read field name.
 		tok, err := d.Read()
 		if err != nil {
-			return err
+			return key, err
 		}
 		switch tok.Kind() {
 		case text.Name:
 			if tok.NameKind() != text.IdentName {
 				if !d.opts.DiscardUnknown {
-					return d.newError(tok.Pos(), "unknown map entry field %q", tok.RawString())
+					return key, d.newError(tok.Pos(), "unknown map entry field %q", tok.RawString())
 				}
 				d.skipValue()
 				continue Loop
@@ -512,40 +671,48 @@ Loop:
 		case text.MessageClose:
 			break Loop
 		default:
-			return d.unexpectedTokenError(tok)
+			return key, d.unexpectedTokenError(tok)
 		}
 
 		switch name := protoreflect.Name(tok.IdentName()); name {
 		case genid.MapEntry_Key_field_name:
 			if !tok.HasSeparator() {
-				return d.syntaxError(tok.Pos(), "missing field separator :")
+				return key, d.syntaxError(tok.Pos(), "missing field separator :")
 			}
 			if key.IsValid() {
-				return d.newError(tok.Pos(), "map entry %q cannot be repeated", name)
+				return key, d.newError(tok.Pos(), "map entry %q cannot be repeated", name)
 			}
 			val, err := d.unmarshalScalar(fd.MapKey())
 			if err != nil {
-				return err
+				return key, err
 			}
 			key = val.MapKey()
 
 		case genid.MapEntry_Value_field_name:
 			if kind := fd.MapValue().Kind(); (kind != protoreflect.MessageKind) && (kind != protoreflect.GroupKind) {
 				if !tok.HasSeparator() {
-					return d.syntaxError(tok.Pos(), "missing field separator :")
+					return key, d.syntaxError(tok.Pos(), "missing field separator :")
 				}
 			}
 			if pval.IsValid() {
-				return d.newError(tok.Pos(), "map entry %q cannot be repeated", name)
+				return key, d.newError(tok.Pos(), "map entry %q cannot be repeated", name)
+			}
+			// Comments within the value can only be attributed to a path
+			// if the key has already been read.
+			vd := d
+			if key.IsValid() {
+				vd = d.withStep(protopath.MapIndex(key))
+			} else {
+				vd.opts.LeadingComments = nil
 			}
-			pval, err = unmarshalMapValue()
+			pval, err = unmarshalMapValue(vd)
 			if err != nil {
-				return err
+				return key, vd.locateError(err)
 			}
 
 		default:
 			if !d.opts.DiscardUnknown {
-				return d.newError(tok.Pos(), "unknown map entry field %q", name)
+				return key, d.newError(tok.Pos(), "unknown map entry field %q", name)
 			}
 			d.skipValue()
 		}
@@ -565,7 +732,7 @@ Loop:
 		}
 	}
 	mmap.Set(key, pval)
-	return nil
+	return key, nil
 }
 
 // unmarshalAny unmarshals an Any textproto. It can either be in expanded form
@@ -631,6 +798,9 @@ Loop:
 				if !ok {
 					return d.newError(tok.Pos(), "invalid %v field value: %v", genid.Any_TypeUrl_field_fullname, tok.RawString())
 				}
+				if err := d.checkAnyType(typeURL, tok.Pos()); err != nil {
+					return err
+				}
 				seenTypeUrl = true
 
 			case genid.Any_Value_field_name:
@@ -689,7 +859,19 @@ Loop:
 	return nil
 }
 
+// checkAnyType reports an error if typeURL is not permitted
+// by the AllowedAnyTypes option.
+func (d decoder) checkAnyType(typeURL string, pos int) error {
+	if d.opts.AllowedAnyTypes != nil && !d.opts.AllowedAnyTypes(typeURL) {
+		return d.newError(pos, "%v with type URL %q is not allowed", genid.Any_message_fullname, typeURL)
+	}
+	return nil
+}
+
 func (d decoder) unmarshalExpandedAny(typeURL string, pos int) ([]byte, error) {
+	if err := d.checkAnyType(typeURL, pos); err != nil {
+		return nil, err
+	}
 	mt, err := d.opts.Resolver.FindMessageByURL(typeURL)
 	if err != nil {
 		return nil, d.newError(pos, "unable to resolve message [%v]: %v", typeURL, err)
@@ -697,8 +879,9 @@ func (d decoder) unmarshalExpandedAny(typeURL string, pos int) ([]byte, error) {
 	// Create new message for the embedded message type and unmarshal the value
 	// field into it.
 	m := mt.New()
-	if err := d.unmarshalMessage(m, true); err != nil {
-		return nil, err
+	ad := d.withStep(protopath.AnyExpand(m.Descriptor()))
+	if err := ad.unmarshalMessage(m, true); err != nil {
+		return nil, ad.locateError(err)
 	}
 	// Serialize the embedded message and return the resulting bytes.
 	b, err := proto.MarshalOptions{
@@ -765,3 +948,68 @@ func (d decoder) skipMessageValue() error {
 		}
 	}
 }
+
+// unmarshalWireField parses the value of the field identified by the
+// field number num, whose name is given by the token name, and appends
+// its wire-format encoding to b. See UnmarshalOptions.AllowFieldNumbers.
+func (d decoder) unmarshalWireField(b []byte, num protowire.Number, name text.Token, depth int) ([]byte, error) {
+	tok, err := d.Read()
+	if err != nil {
+		return nil, err
+	}
+	switch tok.Kind() {
+	case text.MessageOpen:
+		depth--
+		if depth < 0 {
+			return nil, errors.New("exceeded max recursion depth")
+		}
+		b = protowire.AppendTag(b, num, protowire.StartGroupType)
+		for {
+			tok, err := d.Read()
+			if err != nil {
+				return nil, err
+			}
+			if tok.Kind() == text.MessageClose {
+				break
+			}
+			if tok.Kind() != text.Name || tok.NameKind() != text.FieldNumber {
+				return nil, d.newError(tok.Pos(), "group field %v must only contain fields identified by number: %v", num, tok.RawString())
+			}
+			n := protowire.Number(tok.FieldNumber())
+			if !n.IsValid() {
+				return nil, d.newError(tok.Pos(), "invalid field number: %d", n)
+			}
+			if b, err = d.unmarshalWireField(b, n, tok, depth); err != nil {
+				return nil, err
+			}
+		}
+		return protowire.AppendTag(b, num, protowire.EndGroupType), nil
+
+	case text.Scalar:
+		if !name.HasSeparator() {
+			return nil, d.syntaxError(tok.Pos(), "missing field separator :")
+		}
+		if s, ok := tok.String(); ok {
+			b = protowire.AppendTag(b, num, protowire.BytesType)
+			return protowire.AppendString(b, s), nil
+		}
+		raw := tok.RawString()
+		if strings.HasPrefix(raw, "0x") || strings.HasPrefix(raw, "0X") {
+			if v, ok := tok.Uint64(); ok {
+				if len(raw) > len("0x")+8 || v > math.MaxUint32 {
+					b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+					return protowire.AppendFixed64(b, v), nil
+				}
+				b = protowire.AppendTag(b, num, protowire.Fixed32Type)
+				return protowire.AppendFixed32(b, uint32(v)), nil
+			}
+		} else if v, ok := tok.Uint64(); ok {
+			b = protowire.AppendTag(b, num, protowire.VarintType)
+			return protowire.AppendVarint(b, v), nil
+		} else if v, ok := tok.Int64(); ok {
+			b = protowire.AppendTag(b, num, protowire.VarintType)
+			return protowire.AppendVarint(b, uint64(v)), nil
+		}
+	}
+	return nil, d.newError(tok.Pos(), "invalid value for field %v: %v", num, tok.RawString())
+}
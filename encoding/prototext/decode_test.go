@@ -15,7 +15,9 @@ import (
 	"google.golang.org/protobuf/internal/protobuild"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/testing/protopack"
 
+	testpb "google.golang.org/protobuf/internal/testprotos/test"
 	pb2 "google.golang.org/protobuf/internal/testprotos/textpb2"
 	pb3 "google.golang.org/protobuf/internal/testprotos/textpb3"
 	pbeditions "google.golang.org/protobuf/internal/testprotos/textpbeditions"
@@ -129,6 +131,69 @@ func TestUnmarshal(t *testing.T) {
 		inputMessage: &pb3.Scalars{},
 		inputText:    "1: true",
 		wantErr:      "cannot specify field by number",
+	}, {
+		desc:         "AllowFieldNumbers: unknown fields",
+		umo:          prototext.UnmarshalOptions{AllowFieldNumbers: true},
+		inputMessage: &pb2.Scalars{},
+		inputText: `opt_bool: true
+101: 1
+102: -1
+103: 0x00000047
+104: 0x0000000000000047
+105: "hello"
+106: {
+  1: 0xffffffffffffffff
+  2 {}
+}`,
+		wantMessage: func() proto.Message {
+			m := &pb2.Scalars{OptBool: proto.Bool(true)}
+			m.ProtoReflect().SetUnknown(protopack.Message{
+				protopack.Tag{Number: 101, Type: protopack.VarintType}, protopack.Varint(1),
+				protopack.Tag{Number: 102, Type: protopack.VarintType}, protopack.Varint(-1),
+				protopack.Tag{Number: 103, Type: protopack.Fixed32Type}, protopack.Uint32(0x47),
+				protopack.Tag{Number: 104, Type: protopack.Fixed64Type}, protopack.Uint64(0x47),
+				protopack.Tag{Number: 105, Type: protopack.BytesType}, protopack.String("hello"),
+				protopack.Tag{Number: 106, Type: protopack.StartGroupType},
+				protopack.Tag{Number: 1, Type: protopack.Fixed64Type}, protopack.Uint64(math.MaxUint64),
+				protopack.Tag{Number: 2, Type: protopack.StartGroupType},
+				protopack.Tag{Number: 2, Type: protopack.EndGroupType},
+				protopack.Tag{Number: 106, Type: protopack.EndGroupType},
+			}.Marshal())
+			return m
+		}(),
+	}, {
+		desc:         "AllowFieldNumbers: known fields",
+		umo:          prototext.UnmarshalOptions{AllowFieldNumbers: true},
+		inputMessage: &pb2.Scalars{},
+		inputText:    `2: 5 13: "hello"`,
+		wantMessage: &pb2.Scalars{
+			OptInt32:  proto.Int32(5),
+			OptString: proto.String("hello"),
+		},
+	}, {
+		desc:         "AllowFieldNumbers: DiscardUnknown",
+		umo:          prototext.UnmarshalOptions{AllowFieldNumbers: true, DiscardUnknown: true},
+		inputMessage: &pb2.Scalars{},
+		inputText:    `101: 1 2: 5 102 { 1: 2 }`,
+		wantMessage:  &pb2.Scalars{OptInt32: proto.Int32(5)},
+	}, {
+		desc:         "AllowFieldNumbers: invalid value",
+		umo:          prototext.UnmarshalOptions{AllowFieldNumbers: true},
+		inputMessage: &pb2.Scalars{},
+		inputText:    `101: 1.5`,
+		wantErr:      "invalid value for field 101: 1.5",
+	}, {
+		desc:         "AllowFieldNumbers: named field in group",
+		umo:          prototext.UnmarshalOptions{AllowFieldNumbers: true},
+		inputMessage: &pb2.Scalars{},
+		inputText:    `101 { a: 1 }`,
+		wantErr:      "group field 101 must only contain fields identified by number",
+	}, {
+		desc:         "AllowFieldNumbers: missing separator",
+		umo:          prototext.UnmarshalOptions{AllowFieldNumbers: true},
+		inputMessage: &pb2.Scalars{},
+		inputText:    `101 1`,
+		wantErr:      "missing field separator :",
 	}, {
 		desc:         "invalid bool value",
 		inputMessage: &pb3.Scalars{},
@@ -1593,6 +1658,60 @@ value: "some bytes"
 		inputMessage: &anypb.Any{},
 		inputText:    `[SomeMessage]: {}`,
 		wantErr:      "unable to resolve message [SomeMessage]",
+	}, {
+		desc: "Any expanded with custom resolver",
+		umo: prototext.UnmarshalOptions{
+			Resolver: func() *protoregistry.Types {
+				types := new(protoregistry.Types)
+				types.RegisterMessage((*pb2.Nested)(nil).ProtoReflect().Type())
+				return types
+			}(),
+		},
+		inputMessage: &anypb.Any{},
+		inputText:    `[foo/pb2.Nested]: { opt_string: "x" }`,
+		wantMessage: &anypb.Any{
+			TypeUrl: "foo/pb2.Nested",
+			Value:   []byte("\n\x01x"),
+		},
+	}, {
+		desc: "Any expanded with type missing from custom resolver",
+		umo: prototext.UnmarshalOptions{
+			Resolver: func() *protoregistry.Types {
+				types := new(protoregistry.Types)
+				types.RegisterMessage((*pb2.Nested)(nil).ProtoReflect().Type())
+				return types
+			}(),
+		},
+		inputMessage: &anypb.Any{},
+		inputText:    `[foo/pb2.Scalars]: {}`,
+		wantErr:      "unable to resolve message [foo/pb2.Scalars]",
+	}, {
+		desc: "Any expanded with allowed type",
+		umo: prototext.UnmarshalOptions{
+			AllowedAnyTypes: func(typeURL string) bool { return typeURL == "foo/pb2.Nested" },
+		},
+		inputMessage: &anypb.Any{},
+		inputText:    `[foo/pb2.Nested]: { opt_string: "x" }`,
+		wantMessage: &anypb.Any{
+			TypeUrl: "foo/pb2.Nested",
+			Value:   []byte("\n\x01x"),
+		},
+	}, {
+		desc: "Any expanded with disallowed type",
+		umo: prototext.UnmarshalOptions{
+			AllowedAnyTypes: func(typeURL string) bool { return typeURL == "foo/pb2.Nested" },
+		},
+		inputMessage: &pb2.KnownTypes{},
+		inputText:    `opt_any: { [foo/pb2.Scalars]: {} }`,
+		wantErr:      `(line 1:12): google.protobuf.Any with type URL "foo/pb2.Scalars" is not allowed`,
+	}, {
+		desc: "Any type_url with disallowed type",
+		umo: prototext.UnmarshalOptions{
+			AllowedAnyTypes: func(typeURL string) bool { return typeURL == "foo/pb2.Nested" },
+		},
+		inputMessage: &anypb.Any{},
+		inputText:    `type_url: "foo/pb2.Scalars" value: ""`,
+		wantErr:      `google.protobuf.Any with type URL "foo/pb2.Scalars" is not allowed`,
 	}, {
 		desc:         "Any expanded with invalid value",
 		inputMessage: &anypb.Any{},
@@ -1614,6 +1733,28 @@ unknown: ""
 type_url: "pb2.Nested"
 `,
 		wantErr: "(line 3:1): conflict with [pb2.Nested] field",
+	}, {
+		desc:         "just at recursion limit: nested messages",
+		umo:          prototext.UnmarshalOptions{RecursionLimit: 3},
+		inputMessage: &pb2.Nested{},
+		inputText:    `opt_nested: {opt_nested: {opt_string: "x"}}`,
+		wantMessage: &pb2.Nested{
+			OptNested: &pb2.Nested{
+				OptNested: &pb2.Nested{OptString: proto.String("x")},
+			},
+		},
+	}, {
+		desc:         "exceed recursion limit: nested messages",
+		umo:          prototext.UnmarshalOptions{RecursionLimit: 3},
+		inputMessage: &pb2.Nested{},
+		inputText:    `opt_nested: {opt_nested: {opt_nested: {}}}`,
+		wantErr:      "exceeded max recursion depth",
+	}, {
+		desc:         "exceed recursion limit: Any",
+		umo:          prototext.UnmarshalOptions{RecursionLimit: 2},
+		inputMessage: &anypb.Any{},
+		inputText:    `[pb2.Nested]: {opt_nested: {}}`,
+		wantErr:      "exceeded max recursion depth",
 	}}
 
 	for _, msg := range makeMessages(protobuild.Message{},
@@ -1810,3 +1951,95 @@ opt_nested_enum: 1
 		})
 	}
 }
+
+func TestUnmarshalErrorLocation(t *testing.T) {
+	tests := []struct {
+		desc         string
+		input        string
+		wantPath     string
+		wantPosition [2]int // line and column
+	}{{
+		desc: "nested field",
+		input: `optional_nested_message {
+  a: "x"
+}`,
+		wantPath:     "(goproto.proto.test.TestAllTypes).optional_nested_message.a",
+		wantPosition: [2]int{2, 6},
+	}, {
+		desc:         "list element",
+		input:        `repeated_int32: [1, "x"]`,
+		wantPath:     "(goproto.proto.test.TestAllTypes).repeated_int32[1]",
+		wantPosition: [2]int{1, 21},
+	}, {
+		desc:         "map value",
+		input:        `map_string_nested_message { key: "k" value { a: true } }`,
+		wantPath:     `(goproto.proto.test.TestAllTypes).map_string_nested_message["k"].a`,
+		wantPosition: [2]int{1, 49},
+	}, {
+		desc:         "repeated singular field",
+		input:        "optional_int32: 1\noptional_int32: 2",
+		wantPath:     "(goproto.proto.test.TestAllTypes).optional_int32",
+		wantPosition: [2]int{2, 1},
+	}, {
+		desc:         "unknown field",
+		input:        `repeated_nested_message: [{}, {a: 1 b: 2}]`,
+		wantPath:     "(goproto.proto.test.TestAllTypes).repeated_nested_message[1]",
+		wantPosition: [2]int{1, 37},
+	}, {
+		desc:         "syntax error",
+		input:        `repeated_int32: [1, }`,
+		wantPath:     "(goproto.proto.test.TestAllTypes).repeated_int32[1]",
+		wantPosition: [2]int{1, 21},
+	}, {
+		desc:         "unexpected end of input",
+		input:        "optional_nested_message {\na: 1",
+		wantPath:     "(goproto.proto.test.TestAllTypes).optional_nested_message",
+		wantPosition: [2]int{2, 5},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := prototext.Unmarshal([]byte(tt.input), &testpb.TestAllTypes{})
+			uerr, ok := err.(*prototext.UnmarshalError)
+			if !ok {
+				t.Fatalf("Unmarshal() = %v, want *UnmarshalError", err)
+			}
+			if got := uerr.Path.String(); got != tt.wantPath {
+				t.Errorf("Unmarshal() error path = %s, want %s", got, tt.wantPath)
+			}
+			if got := [2]int{uerr.Line, uerr.Column}; got != tt.wantPosition {
+				t.Errorf("Unmarshal() error position = %v, want %v", got, tt.wantPosition)
+			}
+		})
+	}
+
+	// Missing required fields have no location.
+	err := prototext.Unmarshal([]byte(``), &testpb.TestRequired{})
+	if _, ok := err.(*prototext.UnmarshalError); ok || err == nil {
+		t.Errorf("Unmarshal(missing required field) = %v, want error other than *UnmarshalError", err)
+	}
+}
+
+func TestUnmarshalEmitUnknownRoundTrip(t *testing.T) {
+	want := &pb2.Scalars{OptString: proto.String("known")}
+	want.ProtoReflect().SetUnknown(protopack.Message{
+		protopack.Tag{Number: 101, Type: protopack.VarintType}, protopack.Varint(-5),
+		protopack.Tag{Number: 102, Type: protopack.Fixed32Type}, protopack.Uint32(0),
+		protopack.Tag{Number: 103, Type: protopack.Fixed64Type}, protopack.Uint64(1),
+		protopack.Tag{Number: 104, Type: protopack.BytesType}, protopack.Bytes("\xff\x00"),
+		protopack.Tag{Number: 105, Type: protopack.StartGroupType},
+		protopack.Tag{Number: 1, Type: protopack.BytesType}, protopack.String("inside a group"),
+		protopack.Tag{Number: 105, Type: protopack.EndGroupType},
+	}.Marshal())
+
+	b, err := prototext.MarshalOptions{EmitUnknown: true}.Marshal(want)
+	if err != nil {
+		t.Fatalf("Marshal() error: %v", err)
+	}
+	got := &pb2.Scalars{}
+	if err := (prototext.UnmarshalOptions{AllowFieldNumbers: true}).Unmarshal(b, got); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+	if !proto.Equal(got, want) {
+		t.Errorf("Unmarshal(%q) = %v, want %v", b, got, want)
+	}
+}
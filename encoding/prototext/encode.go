@@ -7,9 +7,11 @@ package prototext
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"unicode/utf8"
 
 	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/internal/descopts"
 	"google.golang.org/protobuf/internal/encoding/messageset"
 	"google.golang.org/protobuf/internal/encoding/text"
 	"google.golang.org/protobuf/internal/errors"
@@ -19,6 +21,7 @@ import (
 	"google.golang.org/protobuf/internal/pragma"
 	"google.golang.org/protobuf/internal/strs"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protopath"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/reflect/protoregistry"
 )
@@ -30,6 +33,9 @@ const defaultIndent = "  "
 // Do not depend on the output being stable. Its output will change across
 // different builds of your program, even when using the same version of the
 // protobuf module.
+//
+// The values of fields with the debug_redact option are replaced with a
+// placeholder; see [MarshalOptions.Format].
 func Format(m proto.Message) string {
 	return MarshalOptions{Multiline: true}.Format(m)
 }
@@ -72,23 +78,90 @@ type MarshalOptions struct {
 	AllowPartial bool
 
 	// EmitUnknown specifies whether to emit unknown fields in the output.
-	// If specified, the unmarshaler may be unable to parse the output.
+	// Unknown fields are identified by field number, and their values are
+	// written according to their wire type: varints as decimal integers,
+	// fixed32 and fixed64 values as hexadecimal integers of 8 and 16 digits,
+	// length-delimited values as strings, and groups as messages.
+	// The output may be parsed by setting [UnmarshalOptions.AllowFieldNumbers].
 	// The default is to exclude unknown fields.
 	EmitUnknown bool
 
+	// LeadingComments, if non-nil, is called with the path of each field
+	// value before it is written. Each returned line is emitted as a comment
+	// immediately preceding the field. For repeated fields, it is called for
+	// each element, with a path ending in a list index; for map fields,
+	// it is called for each entry, with a path ending in a map index.
+	// Comments are only emitted in multiline mode.
+	//
+	// Used together with [UnmarshalOptions.LeadingComments], it allows
+	// comments in hand-edited files to be preserved across a round-trip.
+	LeadingComments func(path protopath.Path) []string
+
+	// Deterministic specifies that the output must be stable.
+	//
+	// By default, the marshaler deliberately introduces minor variations
+	// in whitespace to discourage dependence on the exact output.
+	// If Deterministic is set, these variations are disabled, and the output
+	// for a given message and set of options is guaranteed to be identical
+	// across different builds and processes using the same version of the
+	// protobuf module. This is intended for use cases such as golden tests
+	// and generated configuration files. The output may still change between
+	// versions of the protobuf module.
+	Deterministic bool
+
 	// Resolver is used for looking up types when expanding google.protobuf.Any
 	// messages. If nil, this defaults to using protoregistry.GlobalTypes.
 	Resolver interface {
 		protoregistry.ExtensionTypeResolver
 		protoregistry.MessageTypeResolver
 	}
+
+	// AllowedAnyTypes, if non-nil, reports whether a google.protobuf.Any
+	// message with the given type URL may be written in expanded form.
+	// Any messages with other type URLs are written with their type_url
+	// and value fields, as they are when the type URL cannot be resolved.
+	AllowedAnyTypes func(typeURL string) bool
+
+	// Redact, if non-nil, is called for each populated field. If it reports
+	// true, the value of the field is replaced with the placeholder
+	// [REDACTED], which cannot be unmarshaled.
+	//
+	// If Redact is nil, Format redacts the fields for which [DebugRedact]
+	// reports true, while Marshal and MarshalAppend do not redact any fields.
+	Redact func(protoreflect.FieldDescriptor) bool
+}
+
+// DebugRedact reports whether the debug_redact option is set for the field,
+// indicating that its value contains sensitive data that should not be
+// printed in debug output.
+func DebugRedact(fd protoreflect.FieldDescriptor) bool {
+	if descopts.Field == nil {
+		// Options cannot be inspected without the descriptor package.
+		return false
+	}
+	opts := fd.Options()
+	if opts == nil {
+		return false
+	}
+	m := opts.(protoreflect.ProtoMessage).ProtoReflect()
+	rd := m.Descriptor().Fields().ByNumber(genid.FieldOptions_DebugRedact_field_number)
+	return rd != nil && rd.Kind() == protoreflect.BoolKind && m.Get(rd).Bool()
 }
 
+// redactedPlaceholder is written in place of the value of a redacted field.
+const redactedPlaceholder = "[REDACTED]"
+
 // Format formats the message as a string.
 // This method is only intended for human consumption and ignores errors.
 // Do not depend on the output being stable. Its output will change across
 // different builds of your program, even when using the same version of the
 // protobuf module.
+//
+// Unless Redact is set, the values of fields for which [DebugRedact] reports
+// true are replaced with the placeholder [REDACTED]. This also applies to the
+// String method of generated messages, which is implemented with Format.
+// To format the values of such fields, set Redact to a function which
+// always reports false.
 func (o MarshalOptions) Format(m proto.Message) string {
 	if m == nil || !m.ProtoReflect().IsValid() {
 		return "<nil>" // invalid syntax, but okay since this is for debugging
@@ -96,6 +169,9 @@ func (o MarshalOptions) Format(m proto.Message) string {
 	o.allowInvalidUTF8 = true
 	o.AllowPartial = true
 	o.EmitUnknown = true
+	if o.Redact == nil {
+		o.Redact = DebugRedact
+	}
 	b, _ := o.Marshal(m)
 	return string(b)
 }
@@ -114,20 +190,41 @@ func (o MarshalOptions) MarshalAppend(b []byte, m proto.Message) ([]byte, error)
 	return o.marshal(b, m)
 }
 
-// marshal is a centralized function that all marshal operations go through.
-// For profiling purposes, avoid changing the name of this function or
-// introducing other code paths for marshal that do not go through this.
-func (o MarshalOptions) marshal(b []byte, m proto.Message) ([]byte, error) {
-	var delims = [2]byte{'{', '}'}
+// Size returns the size in bytes of the textproto format encoding of m
+// using default options.
+func Size(m proto.Message) int {
+	return MarshalOptions{}.Size(m)
+}
 
-	if o.Multiline && o.Indent == "" {
-		o.Indent = defaultIndent
+// Size returns the size in bytes of the textproto format encoding of m,
+// which is the number of bytes that Marshal appends for m using the same
+// options within the same program. The encoding is measured without
+// being written out.
+//
+// Errors that Marshal would report, such as for unset required fields or
+// strings containing invalid UTF-8, are ignored. Size returns 0 if the
+// options themselves are invalid.
+func (o MarshalOptions) Size(m proto.Message) int {
+	o.allowInvalidUTF8 = true
+	enc, err := o.newEncoder(nil)
+	if err != nil || m == nil {
+		return 0
 	}
-	if o.Resolver == nil {
-		o.Resolver = protoregistry.GlobalTypes
+	enc.SetSizeOnly()
+	enc.init(m)
+	enc.marshalMessage(m.ProtoReflect(), false)
+	n := enc.Size()
+	if len(enc.opts.Indent) > 0 && n > 0 {
+		n++ // trailing newline
 	}
+	return n
+}
 
-	internalEnc, err := text.NewEncoder(b, o.Indent, delims, o.EmitASCII)
+// marshal is a centralized function that all marshal operations go through.
+// For profiling purposes, avoid changing the name of this function or
+// introducing other code paths for marshal that do not go through this.
+func (o MarshalOptions) marshal(b []byte, m proto.Message) ([]byte, error) {
+	enc, err := o.newEncoder(b)
 	if err != nil {
 		return nil, err
 	}
@@ -138,13 +235,13 @@ func (o MarshalOptions) marshal(b []byte, m proto.Message) ([]byte, error) {
 		return b, nil
 	}
 
-	enc := encoder{internalEnc, o}
+	enc.init(m)
 	err = enc.marshalMessage(m.ProtoReflect(), false)
 	if err != nil {
 		return nil, err
 	}
 	out := enc.Bytes()
-	if len(o.Indent) > 0 && len(out) > 0 {
+	if len(enc.opts.Indent) > 0 && len(out) > 0 {
 		out = append(out, '\n')
 	}
 	if o.AllowPartial {
@@ -153,9 +250,57 @@ func (o MarshalOptions) marshal(b []byte, m proto.Message) ([]byte, error) {
 	return out, proto.CheckInitialized(m)
 }
 
+// newEncoder returns an encoder which appends to b,
+// with the defaults of o applied to its options.
+func (o MarshalOptions) newEncoder(b []byte) (encoder, error) {
+	var delims = [2]byte{'{', '}'}
+
+	if o.Multiline && o.Indent == "" {
+		o.Indent = defaultIndent
+	}
+	if o.Resolver == nil {
+		o.Resolver = protoregistry.GlobalTypes
+	}
+
+	internalEnc, err := text.NewEncoder(b, o.Indent, delims, o.EmitASCII)
+	if err != nil {
+		return encoder{}, err
+	}
+	if o.Deterministic {
+		internalEnc.SetStable()
+	}
+	return encoder{Encoder: internalEnc, opts: o}, nil
+}
+
 type encoder struct {
 	*text.Encoder
 	opts MarshalOptions
+
+	// path is the path to the current value.
+	// It is only tracked if opts.LeadingComments is set.
+	path protopath.Path
+}
+
+// init prepares e to encode the top-level message m.
+func (e *encoder) init(m proto.Message) {
+	if e.opts.LeadingComments != nil {
+		e.path = protopath.Path{protopath.Root(m.ProtoReflect().Descriptor())}
+	}
+}
+
+// withStep returns a copy of e with the given step appended to its path.
+func (e encoder) withStep(s protopath.Step) encoder {
+	if e.opts.LeadingComments != nil {
+		e.path = append(e.path[:len(e.path):len(e.path)], s)
+	}
+	return e
+}
+
+// writeComments writes the leading comments for the current path.
+func (e encoder) writeComments() {
+	if e.opts.LeadingComments != nil {
+		e.WriteComments(e.opts.LeadingComments(e.path))
+	}
 }
 
 // marshalMessage marshals the given protoreflect.Message.
@@ -181,6 +326,13 @@ func (e encoder) marshalMessage(m protoreflect.Message, inclDelims bool) error {
 	// Marshal fields.
 	var err error
 	order.RangeFields(m, order.IndexNameFieldOrder, func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if e.opts.Redact != nil && e.opts.Redact(fd) {
+			e := e.withStep(protopath.FieldAccess(fd))
+			e.writeComments()
+			e.WriteName(fd.TextName())
+			e.WriteLiteral(redactedPlaceholder)
+			return true
+		}
 		if err = e.marshalField(fd.TextName(), v, fd); err != nil {
 			return false
 		}
@@ -202,10 +354,12 @@ func (e encoder) marshalMessage(m protoreflect.Message, inclDelims bool) error {
 func (e encoder) marshalField(name string, val protoreflect.Value, fd protoreflect.FieldDescriptor) error {
 	switch {
 	case fd.IsList():
-		return e.marshalList(name, val.List(), fd)
+		return e.withStep(protopath.FieldAccess(fd)).marshalList(name, val.List(), fd)
 	case fd.IsMap():
-		return e.marshalMap(name, val.Map(), fd)
+		return e.withStep(protopath.FieldAccess(fd)).marshalMap(name, val.Map(), fd)
 	default:
+		e = e.withStep(protopath.FieldAccess(fd))
+		e.writeComments()
 		e.WriteName(name)
 		return e.marshalSingular(val, fd)
 	}
@@ -268,6 +422,8 @@ func (e encoder) marshalSingular(val protoreflect.Value, fd protoreflect.FieldDe
 func (e encoder) marshalList(name string, list protoreflect.List, fd protoreflect.FieldDescriptor) error {
 	size := list.Len()
 	for i := 0; i < size; i++ {
+		e := e.withStep(protopath.ListIndex(i))
+		e.writeComments()
 		e.WriteName(name)
 		if err := e.marshalSingular(list.Get(i), fd); err != nil {
 			return err
@@ -280,6 +436,8 @@ func (e encoder) marshalList(name string, list protoreflect.List, fd protoreflec
 func (e encoder) marshalMap(name string, mmap protoreflect.Map, fd protoreflect.FieldDescriptor) error {
 	var err error
 	order.RangeEntries(mmap, order.GenericKeyOrder, func(key protoreflect.MapKey, val protoreflect.Value) bool {
+		e := e.withStep(protopath.MapIndex(key))
+		e.writeComments()
 		e.WriteName(name)
 		e.StartMessage()
 		defer e.EndMessage()
@@ -304,7 +462,6 @@ func (e encoder) marshalMap(name string, mmap protoreflect.Map, fd protoreflect.
 // This function assumes proper encoding in the given []byte.
 func (e encoder) marshalUnknown(b []byte) {
 	const dec = 10
-	const hex = 16
 	for len(b) > 0 {
 		num, wtype, n := protowire.ConsumeTag(b)
 		b = b[n:]
@@ -318,11 +475,11 @@ func (e encoder) marshalUnknown(b []byte) {
 		case protowire.Fixed32Type:
 			var v uint32
 			v, n = protowire.ConsumeFixed32(b)
-			e.WriteLiteral("0x" + strconv.FormatUint(uint64(v), hex))
+			e.WriteLiteral(formatHex(uint64(v), 8))
 		case protowire.Fixed64Type:
 			var v uint64
 			v, n = protowire.ConsumeFixed64(b)
-			e.WriteLiteral("0x" + strconv.FormatUint(v, hex))
+			e.WriteLiteral(formatHex(v, 16))
 		case protowire.BytesType:
 			var v []byte
 			v, n = protowire.ConsumeBytes(b)
@@ -341,6 +498,14 @@ func (e encoder) marshalUnknown(b []byte) {
 	}
 }
 
+// formatHex formats v as a hexadecimal literal of at least the given number
+// of digits, so that the wire type of an unknown fixed32 or fixed64 field
+// is preserved.
+func formatHex(v uint64, digits int) string {
+	s := strconv.FormatUint(v, 16)
+	return "0x" + strings.Repeat("0", digits-len(s)) + s
+}
+
 // marshalAny marshals the given google.protobuf.Any message in expanded form.
 // It returns true if it was able to marshal, else false.
 func (e encoder) marshalAny(any protoreflect.Message) bool {
@@ -348,6 +513,9 @@ func (e encoder) marshalAny(any protoreflect.Message) bool {
 	fds := any.Descriptor().Fields()
 	fdType := fds.ByNumber(genid.Any_TypeUrl_field_number)
 	typeURL := any.Get(fdType).String()
+	if e.opts.AllowedAnyTypes != nil && !e.opts.AllowedAnyTypes(typeURL) {
+		return false
+	}
 	mt, err := e.opts.Resolver.FindMessageByURL(typeURL)
 	if err != nil {
 		return false
@@ -371,7 +539,7 @@ func (e encoder) marshalAny(any protoreflect.Message) bool {
 
 	// Field name is the proto field name enclosed in [].
 	e.WriteName("[" + typeURL + "]")
-	err = e.marshalMessage(m.ProtoReflect(), true)
+	err = e.withStep(protopath.AnyExpand(m.ProtoReflect().Descriptor())).marshalMessage(m.ProtoReflect(), true)
 	if err != nil {
 		e.Reset(pos)
 		return false
@@ -17,8 +17,12 @@ import (
 	"google.golang.org/protobuf/internal/flags"
 	"google.golang.org/protobuf/internal/protobuild"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/reflect/protoregistry"
 	"google.golang.org/protobuf/testing/protopack"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
 
 	pb2 "google.golang.org/protobuf/internal/testprotos/textpb2"
 	pb3 "google.golang.org/protobuf/internal/testprotos/textpb3"
@@ -947,8 +951,8 @@ req_nested: {}
 		want: `opt_string: "this message contains unknown fields"
 101: 1
 102: 255
-103: 0x47
-104: 0xdeadbeef
+103: 0x00000047
+104: 0x00000000deadbeef
 `,
 	}, {
 		desc: "unknown length-delimited",
@@ -1269,6 +1273,35 @@ value: "\n\x13embedded inside Any\x12\x0b\n\tinception"
     opt_string: "inception"
   }
 }
+`,
+	}, {
+		desc: "Any expanded with custom resolver",
+		mo: prototext.MarshalOptions{
+			Resolver: func() *protoregistry.Types {
+				types := new(protoregistry.Types)
+				types.RegisterMessage((*pb2.Nested)(nil).ProtoReflect().Type())
+				return types
+			}(),
+		},
+		input: &anypb.Any{
+			TypeUrl: "foo/pb2.Nested",
+			Value:   []byte("\n\x01x"),
+		},
+		want: `[foo/pb2.Nested]: {
+  opt_string: "x"
+}
+`,
+	}, {
+		desc: "Any not expanded by AllowedAnyTypes",
+		mo: prototext.MarshalOptions{
+			AllowedAnyTypes: func(typeURL string) bool { return typeURL == "foo/pb2.Nested" },
+		},
+		input: &anypb.Any{
+			TypeUrl: "bar/pb2.Nested",
+			Value:   []byte("\n\x01x"),
+		},
+		want: `type_url: "bar/pb2.Nested"
+value: "\n\x01x"
 `,
 	}, {
 		desc: "Any expanded with missing required",
@@ -1578,10 +1611,44 @@ opt_string: "谷歌"
 					t.Errorf("Marshal() diff -want +got\n%v\n", diff)
 				}
 			}
+			if err == nil {
+				if n := tt.mo.Size(tt.input); n != len(b) {
+					t.Errorf("Size() = %v, want %v", n, len(b))
+				}
+			}
 		})
 	}
 }
 
+func TestMarshalDeterministic(t *testing.T) {
+	m := &pb2.Nested{
+		OptString: proto.String("a"),
+		OptNested: &pb2.Nested{OptString: proto.String("b")},
+	}
+	for _, tt := range []struct {
+		mo   prototext.MarshalOptions
+		want string
+	}{{
+		mo:   prototext.MarshalOptions{Deterministic: true},
+		want: `opt_string:"a" opt_nested:{opt_string:"b"}`,
+	}, {
+		mo: prototext.MarshalOptions{Deterministic: true, Multiline: true},
+		want: `opt_string: "a"
+opt_nested: {
+  opt_string: "b"
+}
+`,
+	}} {
+		b, err := tt.mo.Marshal(m)
+		if err != nil {
+			t.Fatalf("Marshal() returned error: %v", err)
+		}
+		if got := string(b); got != tt.want {
+			t.Errorf("Marshal()\n<got>\n%v\n<want>\n%v\n", got, tt.want)
+		}
+	}
+}
+
 func TestEncodeAppend(t *testing.T) {
 	want := []byte("prefix")
 	got := append([]byte(nil), want...)
@@ -1622,3 +1689,135 @@ func TestMarshalAppendAllocations(t *testing.T) {
 		t.Errorf("expect amortized allocs/op to be identical")
 	}
 }
+
+func TestSize(t *testing.T) {
+	m := &pb2.Nested{
+		OptString: proto.String("a\x00\"\u00e9\U0001f600\xff"),
+		OptNested: &pb2.Nested{OptString: proto.String("b")},
+	}
+	for _, mo := range []prototext.MarshalOptions{
+		{},
+		{Multiline: true},
+		{Multiline: true, Indent: "\t"},
+		{EmitASCII: true},
+		{Deterministic: true},
+	} {
+		b, err := mo.Marshal(m)
+		if err != nil {
+			t.Fatalf("Marshal() error: %v", err)
+		}
+		if got, want := mo.Size(m), len(b); got != want {
+			t.Errorf("%+v: Size() = %v, want %v", mo, got, want)
+		}
+
+		// Unset required fields are not reported by Size.
+		partial := &pb2.PartialRequired{OptString: proto.String("x")}
+		b, err = prototext.MarshalOptions{
+			Multiline:     mo.Multiline,
+			Indent:        mo.Indent,
+			EmitASCII:     mo.EmitASCII,
+			Deterministic: mo.Deterministic,
+			AllowPartial:  true,
+		}.Marshal(partial)
+		if err != nil {
+			t.Fatalf("Marshal() error: %v", err)
+		}
+		if got, want := mo.Size(partial), len(b); got != want {
+			t.Errorf("%+v: Size(partial message) = %v, want %v", mo, got, want)
+		}
+	}
+
+	// Invalid UTF-8 is not reported by Size either.
+	if got, want := (prototext.MarshalOptions{Deterministic: true}).Size(&pb3.Scalars{SString: "\xff"}), len(`s_string:"\xff"`); got != want {
+		t.Errorf("Size(invalid UTF-8) = %v, want %v", got, want)
+	}
+	if got := prototext.Size(nil); got != 0 {
+		t.Errorf("Size(nil) = %v, want 0", got)
+	}
+	if got := (prototext.MarshalOptions{Indent: "x"}).Size(m); got != 0 {
+		t.Errorf("Size() with invalid indent = %v, want 0", got)
+	}
+
+	sizeAllocs := testing.AllocsPerRun(100, func() { prototext.Size(m) })
+	marshalAllocs := testing.AllocsPerRun(100, func() { prototext.Marshal(m) })
+	if sizeAllocs >= marshalAllocs {
+		t.Errorf("Size() made %v allocations, want fewer than the %v made by Marshal()", sizeAllocs, marshalAllocs)
+	}
+}
+
+func TestMarshalRedact(t *testing.T) {
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("redact.proto"),
+		Package: proto.String("redact"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Credentials"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:   proto.String("user"),
+				Number: proto.Int32(1),
+				Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:   descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			}, {
+				Name:    proto.String("tokens"),
+				Number:  proto.Int32(2),
+				Label:   descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+				Type:    descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				Options: &descriptorpb.FieldOptions{DebugRedact: proto.Bool(true)},
+			}},
+		}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := fd.Messages().Get(0)
+	m := dynamicpb.NewMessage(md)
+	m.Set(md.Fields().ByName("user"), protoreflect.ValueOfString("gopher"))
+	tokens := m.Mutable(md.Fields().ByName("tokens")).List()
+	tokens.Append(protoreflect.ValueOfString("secret1"))
+	tokens.Append(protoreflect.ValueOfString("secret2"))
+
+	for _, tt := range []struct {
+		desc string
+		got  func() string
+		want string
+	}{{
+		desc: "Format redacts debug_redact fields",
+		got:  func() string { return prototext.MarshalOptions{}.Format(m) },
+		want: `user:"gopher" tokens:[REDACTED]`,
+	}, {
+		desc: "package Format redacts debug_redact fields",
+		got:  func() string { return prototext.Format(m) },
+		want: "user: \"gopher\"\ntokens: [REDACTED]\n",
+	}, {
+		desc: "String redacts debug_redact fields",
+		got:  m.String,
+		want: `user:"gopher" tokens:[REDACTED]`,
+	}, {
+		desc: "Marshal does not redact by default",
+		got: func() string {
+			b, _ := prototext.MarshalOptions{}.Marshal(m)
+			return string(b)
+		},
+		want: `user:"gopher" tokens:"secret1" tokens:"secret2"`,
+	}, {
+		desc: "custom Redact",
+		got: func() string {
+			b, _ := prototext.MarshalOptions{
+				Redact: func(fd protoreflect.FieldDescriptor) bool { return fd.Name() == "user" },
+			}.Marshal(m)
+			return string(b)
+		},
+		want: `user:[REDACTED] tokens:"secret1" tokens:"secret2"`,
+	}, {
+		desc: "custom Redact overrides debug_redact in Format",
+		got: func() string {
+			return prototext.MarshalOptions{
+				Redact: func(protoreflect.FieldDescriptor) bool { return false },
+			}.Format(m)
+		},
+		want: `user:"gopher" tokens:"secret1" tokens:"secret2"`,
+	}} {
+		if got := tt.got(); got != tt.want {
+			t.Errorf("%s:\ngot:  %v\nwant: %v", tt.desc, got, tt.want)
+		}
+	}
+}
@@ -5,10 +5,14 @@
 package prototext_test
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+
 	"google.golang.org/protobuf/encoding/prototext"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protopath"
 	"google.golang.org/protobuf/reflect/protoregistry"
 
 	pb2 "google.golang.org/protobuf/internal/testprotos/textpb2"
@@ -236,3 +240,61 @@ func TestRoundTrip(t *testing.T) {
 		})
 	}
 }
+
+func TestRoundTripComments(t *testing.T) {
+	const input = `# Leading comment on the first field.
+int32_to_str: {
+  key: 1
+  value: "one"
+}
+# Comment on a map entry
+# spanning two lines.
+str_to_nested: {
+  key: "a"
+  value: {
+    # Comment on a field within a map value.
+    opt_string: "nested"
+  }
+}
+str_to_nested: {
+  key: "b"  # Trailing comments are not preserved.
+  value: {}
+}
+`
+	comments := make(map[string][]string)
+	uo := prototext.UnmarshalOptions{
+		LeadingComments: func(p protopath.Path, lines []string) {
+			comments[p.String()] = lines
+		},
+	}
+	m := &pb2.Maps{}
+	if err := uo.Unmarshal([]byte(input), m); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+
+	want := map[string][]string{
+		`(pb2.Maps).int32_to_str[1]`:               {" Leading comment on the first field."},
+		`(pb2.Maps).str_to_nested["a"]`:            {" Comment on a map entry", " spanning two lines."},
+		`(pb2.Maps).str_to_nested["a"].opt_string`: {" Comment on a field within a map value."},
+	}
+	if diff := cmp.Diff(want, comments); diff != "" {
+		t.Errorf("LeadingComments mismatch (-want +got):\n%s", diff)
+	}
+
+	mo := prototext.MarshalOptions{
+		Multiline:     true,
+		Indent:        "  ",
+		Deterministic: true,
+		LeadingComments: func(p protopath.Path) []string {
+			return comments[p.String()]
+		},
+	}
+	b, err := mo.Marshal(m)
+	if err != nil {
+		t.Fatalf("Marshal() error: %v", err)
+	}
+	wantOut := strings.Replace(input, "  # Trailing comments are not preserved.", "", 1)
+	if diff := cmp.Diff(wantOut, string(b)); diff != "" {
+		t.Errorf("Marshal() mismatch (-want +got):\n%s", diff)
+	}
+}
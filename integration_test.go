@@ -156,6 +156,7 @@ func TestIntegration(t *testing.T) {
 		if goVersion == golangLatest {
 			runGo("ProtoLegacyRace", command{}, "go", "test", "-race", "-tags", "protolegacy", "./...")
 			runGo("ProtoLegacy", command{}, "go", "test", "-tags", "protolegacy", "./...")
+			runGo("NoFastPath", command{}, "go", "test", "-tags", "protonofastpath", "./...")
 			runGo("ProtocGenGo", command{Dir: "cmd/protoc-gen-go/testdata"}, "go", "test")
 			runGo("Conformance", command{Dir: "internal/conformance"}, "go", "test", "-execute")
 
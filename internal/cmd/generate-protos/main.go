@@ -377,6 +377,12 @@ func generateLocalProtos() {
 			if d.annotate[filepath.ToSlash(relPath)] {
 				opts += ",annotate_code"
 			}
+			if strings.HasPrefix(relPath, "internal/testprotos/explicitregistration/") {
+				opts += ",explicit_registration=true"
+			}
+			if strings.HasPrefix(relPath, "cmd/protoc-gen-go/testdata/lazyinit/") {
+				opts += ",lazy_init=true"
+			}
 			if strings.HasPrefix(relPath, "internal/testprotos/test3/") {
 				variant := strings.TrimPrefix(relPath, "internal/testprotos/test3/")
 				if idx := strings.IndexByte(variant, '/'); idx > -1 {
@@ -530,7 +530,7 @@ func consume{{.Name}}Value(b []byte, _ protoreflect.Value, _ protowire.Number, w
 		return protoreflect.Value{}, out, errDecode
 	}
 	out.n = n
-	return {{.ToValue}}, out, nil
+	return {{.CodecToValue}}, out, nil
 }
 
 var coder{{.Name}}Value = valueCoderFuncs{
@@ -568,7 +568,7 @@ func consume{{.Name}}ValueValidateUTF8(b []byte, _ protoreflect.Value, _ protowi
 		return protoreflect.Value{}, out, errInvalidUTF8{}
 	}
 	out.n = n
-	return {{.ToValue}}, out, nil
+	return {{.CodecToValue}}, out, nil
 }
 
 var coder{{.Name}}ValueValidateUTF8 = valueCoderFuncs{
@@ -618,7 +618,7 @@ func consume{{.Name}}SliceValue(b []byte, listv protoreflect.Value, _ protowire.
 			if n < 0 {
 				return protoreflect.Value{}, out, errDecode
 			}
-			list.Append({{.ToValue}})
+			list.Append({{.CodecToValue}})
 			b = b[n:]
 		}
 		out.n = n
@@ -632,7 +632,7 @@ func consume{{.Name}}SliceValue(b []byte, listv protoreflect.Value, _ protowire.
 	if n < 0 {
 		return protoreflect.Value{}, out, errDecode
 	}
-	list.Append({{.ToValue}})
+	list.Append({{.CodecToValue}})
 	out.n = n
 	return listv, out, nil
 }
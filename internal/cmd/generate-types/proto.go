@@ -133,6 +133,10 @@ type ProtoKind struct {
 	ToValue   Expr
 	FromValue Expr
 
+	// CodecValue, if set, is used instead of ToValue by the fast-path codecs,
+	// which allocate values according to their unmarshal options.
+	CodecValue Expr
+
 	// Conversions to/from generated structures.
 	GoType         GoType
 	ToGoType       Expr
@@ -142,6 +146,15 @@ type ProtoKind struct {
 	NoValueCodec   bool
 }
 
+// CodecToValue returns the conversion to protoreflect.Value
+// used by the fast-path codecs.
+func (k ProtoKind) CodecToValue() Expr {
+	if k.CodecValue != "" {
+		return k.CodecValue
+	}
+	return k.ToValue
+}
+
 func (k ProtoKind) Expr() Expr {
 	return "protoreflect." + Expr(k.Name) + "Kind"
 }
@@ -274,19 +287,21 @@ var ProtoKinds = []ProtoKind{
 		Name:       "String",
 		WireType:   WireBytes,
 		ToValue:    "protoreflect.ValueOfString(string(v))",
+		CodecValue: "protoreflect.ValueOfString(opts.copyString(v))",
 		FromValue:  "v.String()",
 		GoType:     GoString,
-		ToGoType:   "string(v)",
+		ToGoType:   "opts.copyString(v)",
 		FromGoType: "v",
 	},
 	{
 		Name:           "Bytes",
 		WireType:       WireBytes,
 		ToValue:        "protoreflect.ValueOfBytes(append(emptyBuf[:], v...))",
+		CodecValue:     "protoreflect.ValueOfBytes(opts.copyBytes(v))",
 		FromValue:      "v.Bytes()",
 		GoType:         GoBytes,
-		ToGoType:       "append(emptyBuf[:], v...)",
-		ToGoTypeNoZero: "append(([]byte)(nil), v...)",
+		ToGoType:       "opts.copyBytes(v)",
+		ToGoTypeNoZero: "opts.copyBytesNoZero(v)",
 		FromGoType:     "v",
 		NoPointer:      true,
 	},
@@ -330,8 +345,9 @@ func (o UnmarshalOptions) unmarshalScalar(b []byte, wtyp protowire.Type, fd prot
 			return val, 0, errDecode
 		}
 		{{if (eq .Name "String") -}}
-		if strs.EnforceUTF8(fd) && !utf8.Valid(v) {
-			return protoreflect.Value{}, 0, errors.InvalidUTF8(string(fd.FullName()))
+		v, err := o.checkUTF8(v, fd)
+		if err != nil {
+			return protoreflect.Value{}, 0, err
 		}
 		{{end -}}
 		return {{.ToValue}}, n, nil
@@ -374,8 +390,9 @@ func (o UnmarshalOptions) unmarshalList(b []byte, wtyp protowire.Type, list prot
 			return 0, errDecode
 		}
 		{{if (eq .Name "String") -}}
-		if strs.EnforceUTF8(fd) && !utf8.Valid(v) {
-			return 0, errors.InvalidUTF8(string(fd.FullName()))
+		v, err := o.checkUTF8(v, fd)
+		if err != nil {
+			return 0, err
 		}
 		{{end -}}
 		{{if or (eq .Name "Message") (eq .Name "Group") -}}
@@ -414,19 +431,17 @@ func (o MarshalOptions) marshalSingular(b []byte, fd protoreflect.FieldDescripto
 	{{- range .}}
 	case {{.Expr}}:
 		{{- if (eq .Name "String") }}
-		if strs.EnforceUTF8(fd) && !utf8.ValidString(v.String()) {
-			return b, errors.InvalidUTF8(string(fd.FullName()))
+		s, err := o.checkUTF8(v.String(), fd)
+		if err != nil {
+			return b, err
 		}
-		b = protowire.AppendString(b, {{.FromValue}})
+		b = protowire.AppendString(b, s)
 		{{- else if (eq .Name "Message") -}}
-		var pos int
 		var err error
-		b, pos = appendSpeculativeLength(b)
-		b, err = o.marshalMessage(b, v.Message())
+		b, err = o.marshalMessageBytes(b, v.Message())
 		if err != nil {
 			return b, err
 		}
-		b = finishSpeculativeLength(b, pos)
 		{{- else if (eq .Name "Group") -}}
 		var err error
 		b, err = o.marshalMessage(b, v.Message())
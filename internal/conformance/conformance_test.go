@@ -5,23 +5,21 @@
 package conformance_test
 
 import (
-	"encoding/binary"
 	"flag"
-	"fmt"
-	"io"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"testing"
 
-	"google.golang.org/protobuf/encoding/protojson"
-	"google.golang.org/protobuf/encoding/prototext"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/testing/protoconformance"
 
-	pb "google.golang.org/protobuf/internal/testprotos/conformance"
-	epb "google.golang.org/protobuf/internal/testprotos/conformance/editions"
-	empb "google.golang.org/protobuf/internal/testprotos/conformance/editionsmigration"
+	_ "google.golang.org/protobuf/internal/testprotos/conformance"
+	_ "google.golang.org/protobuf/internal/testprotos/conformance/editions"
+	_ "google.golang.org/protobuf/internal/testprotos/conformance/editionsmigration"
 )
 
 func init() {
@@ -58,127 +56,16 @@ func Test(t *testing.T) {
 }
 
 func main() {
-	var sizeBuf [4]byte
-	inbuf := make([]byte, 0, 4096)
-	for {
-		_, err := io.ReadFull(os.Stdin, sizeBuf[:])
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			log.Fatalf("conformance: read request: %v", err)
-		}
-		size := binary.LittleEndian.Uint32(sizeBuf[:])
-		if int(size) > cap(inbuf) {
-			inbuf = make([]byte, size)
-		}
-		inbuf = inbuf[:size]
-		if _, err := io.ReadFull(os.Stdin, inbuf); err != nil {
-			log.Fatalf("conformance: read request: %v", err)
-		}
-
-		req := &pb.ConformanceRequest{}
-		if err := proto.Unmarshal(inbuf, req); err != nil {
-			log.Fatalf("conformance: parse request: %v", err)
-		}
-		res := handle(req)
-
-		out, err := proto.Marshal(res)
-		if err != nil {
-			log.Fatalf("conformance: marshal response: %v", err)
-		}
-		binary.LittleEndian.PutUint32(sizeBuf[:], uint32(len(out)))
-		if _, err := os.Stdout.Write(sizeBuf[:]); err != nil {
-			log.Fatalf("conformance: write response: %v", err)
-		}
-		if _, err := os.Stdout.Write(out); err != nil {
-			log.Fatalf("conformance: write response: %v", err)
-		}
-	}
-}
-
-func handle(req *pb.ConformanceRequest) (res *pb.ConformanceResponse) {
-	var msg proto.Message = &pb.TestAllTypesProto2{}
-	switch req.GetMessageType() {
-	case "protobuf_test_messages.proto3.TestAllTypesProto3":
-		msg = &pb.TestAllTypesProto3{}
-	case "protobuf_test_messages.proto2.TestAllTypesProto2":
-		msg = &pb.TestAllTypesProto2{}
-	case "protobuf_test_messages.editions.TestAllTypesEdition2023":
-		msg = &epb.TestAllTypesEdition2023{}
-	case "protobuf_test_messages.editions.proto3.TestAllTypesProto3":
-		msg = &empb.TestAllTypesProto3{}
-	case "protobuf_test_messages.editions.proto2.TestAllTypesProto2":
-		msg = &empb.TestAllTypesProto2{}
-	default:
-		panic(fmt.Sprintf("unknown message type: %s", req.GetMessageType()))
-	}
-
-	// Unmarshal the test message.
-	var err error
-	switch p := req.Payload.(type) {
-	case *pb.ConformanceRequest_ProtobufPayload:
-		err = proto.Unmarshal(p.ProtobufPayload, msg)
-	case *pb.ConformanceRequest_JsonPayload:
-		err = protojson.UnmarshalOptions{
-			DiscardUnknown: req.TestCategory == pb.TestCategory_JSON_IGNORE_UNKNOWN_PARSING_TEST,
-		}.Unmarshal([]byte(p.JsonPayload), msg)
-	case *pb.ConformanceRequest_TextPayload:
-		err = prototext.Unmarshal([]byte(p.TextPayload), msg)
-	default:
-		return &pb.ConformanceResponse{
-			Result: &pb.ConformanceResponse_RuntimeError{
-				RuntimeError: "unknown request payload type",
-			},
-		}
-	}
-	if err != nil {
-		return &pb.ConformanceResponse{
-			Result: &pb.ConformanceResponse_ParseError{
-				ParseError: err.Error(),
-			},
-		}
+	h := protoconformance.Handler{
+		NewMessage: func(name protoreflect.FullName) proto.Message {
+			mt, err := protoregistry.GlobalTypes.FindMessageByName(name)
+			if err != nil {
+				return nil
+			}
+			return mt.New().Interface()
+		},
 	}
-
-	// Marshal the test message.
-	var b []byte
-	switch req.RequestedOutputFormat {
-	case pb.WireFormat_PROTOBUF:
-		b, err = proto.Marshal(msg)
-		res = &pb.ConformanceResponse{
-			Result: &pb.ConformanceResponse_ProtobufPayload{
-				ProtobufPayload: b,
-			},
-		}
-	case pb.WireFormat_JSON:
-		b, err = protojson.Marshal(msg)
-		res = &pb.ConformanceResponse{
-			Result: &pb.ConformanceResponse_JsonPayload{
-				JsonPayload: string(b),
-			},
-		}
-	case pb.WireFormat_TEXT_FORMAT:
-		b, err = prototext.MarshalOptions{
-			EmitUnknown: req.PrintUnknownFields,
-		}.Marshal(msg)
-		res = &pb.ConformanceResponse{
-			Result: &pb.ConformanceResponse_TextPayload{
-				TextPayload: string(b),
-			},
-		}
-	default:
-		return &pb.ConformanceResponse{
-			Result: &pb.ConformanceResponse_RuntimeError{
-				RuntimeError: "unknown output format",
-			},
-		}
-	}
-	if err != nil {
-		return &pb.ConformanceResponse{
-			Result: &pb.ConformanceResponse_SerializeError{
-				SerializeError: err.Error(),
-			},
-		}
+	if err := h.Serve(os.Stdin, os.Stdout); err != nil {
+		log.Fatalf("conformance: %v", err)
 	}
-	return res
 }
@@ -217,7 +217,25 @@ func (d *Decoder) parseNext() (Token, error) {
 func (d *Decoder) newSyntaxError(pos int, f string, x ...any) error {
 	e := errors.New(f, x...)
 	line, column := d.Position(pos)
-	return errors.New("syntax error (line %d:%d): %v", line, column, e)
+	return &SyntaxError{
+		Line:   line,
+		Column: column,
+		err:    errors.New("syntax error (line %d:%d): %v", line, column, e),
+	}
+}
+
+// SyntaxError is an error in the syntax of the input at a given position.
+type SyntaxError struct {
+	Line, Column int
+	err          error
+}
+
+func (e *SyntaxError) Error() string {
+	return e.err.Error()
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.err
 }
 
 // Position returns line and column number of given index of the original input.
@@ -338,3 +356,9 @@ func (d *Decoder) Clone() *Decoder {
 	ret.openStack = append([]Kind(nil), ret.openStack...)
 	return &ret
 }
+
+// RawSpan returns the raw input starting at token first and ending with
+// token last, inclusive. Both tokens must have been read from d.
+func (d *Decoder) RawSpan(first, last Token) []byte {
+	return d.orig[first.pos : last.pos+len(last.raw)]
+}
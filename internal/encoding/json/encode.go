@@ -5,6 +5,7 @@
 package json
 
 import (
+	"encoding/base64"
 	"math"
 	"math/bits"
 	"strconv"
@@ -187,6 +188,33 @@ func (e *Encoder) WriteUint(n uint64) {
 	e.out = strconv.AppendUint(e.out, n, 10)
 }
 
+// WriteQuotedInt writes out the given signed integer in JSON string value.
+func (e *Encoder) WriteQuotedInt(n int64) {
+	e.prepareNext(scalar)
+	e.out = append(e.out, '"')
+	e.out = strconv.AppendInt(e.out, n, 10)
+	e.out = append(e.out, '"')
+}
+
+// WriteQuotedUint writes out the given unsigned integer in JSON string value.
+func (e *Encoder) WriteQuotedUint(n uint64) {
+	e.prepareNext(scalar)
+	e.out = append(e.out, '"')
+	e.out = strconv.AppendUint(e.out, n, 10)
+	e.out = append(e.out, '"')
+}
+
+// WriteBase64 writes out the standard base64 encoding of the given bytes in
+// JSON string value.
+func (e *Encoder) WriteBase64(b []byte) {
+	e.prepareNext(scalar)
+	n := base64.StdEncoding.EncodedLen(len(b))
+	e.out = append(e.out, '"')
+	e.out = append(e.out, make([]byte, n)...)
+	base64.StdEncoding.Encode(e.out[len(e.out)-n:], b)
+	e.out = append(e.out, '"')
+}
+
 // StartObject writes out the '{' symbol.
 func (e *Encoder) StartObject() {
 	e.prepareNext(objectOpen)
@@ -157,6 +157,34 @@ func TestEncoder(t *testing.T) {
 			},
 			wantOut: `18446744073709551615`,
 		},
+		{
+			desc: "quoted int",
+			write: func(e *json.Encoder) {
+				e.WriteQuotedInt(-math.MaxInt64)
+			},
+			wantOut: `"-9223372036854775807"`,
+		},
+		{
+			desc: "quoted uint",
+			write: func(e *json.Encoder) {
+				e.WriteQuotedUint(math.MaxUint64)
+			},
+			wantOut: `"18446744073709551615"`,
+		},
+		{
+			desc: "base64",
+			write: func(e *json.Encoder) {
+				e.WriteBase64([]byte("\xff\x00hello"))
+			},
+			wantOut: `"/wBoZWxsbw=="`,
+		},
+		{
+			desc: "empty base64",
+			write: func(e *json.Encoder) {
+				e.WriteBase64(nil)
+			},
+			wantOut: `""`,
+		},
 		{
 			desc: "empty object",
 			write: func(e *json.Encoder) {
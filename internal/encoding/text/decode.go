@@ -33,6 +33,10 @@ type Decoder struct {
 	// MessageOpen kind.
 	openStack []byte
 
+	// wsStart and wsEnd are the bounds within orig of the most recently
+	// consumed run of whitespace and comments.
+	wsStart, wsEnd int
+
 	// orig is used in reporting line and column.
 	orig []byte
 	// in contains the unconsumed input.
@@ -431,6 +435,7 @@ func (d *Decoder) parseFieldName() (tok Token, err error) {
 // in between [ ], '.', '/' and the sub names.
 func (d *Decoder) parseTypeName() (Token, error) {
 	startPos := len(d.orig) - len(d.in)
+	lead := d.leadStart(startPos)
 	// Use alias s to advance first in order to use d.in for error handling.
 	// Caller already checks for [ as first character.
 	s := consume(d.in[1:], 0)
@@ -491,6 +496,7 @@ func (d *Decoder) parseTypeName() (Token, error) {
 		kind:  Name,
 		attrs: uint8(TypeName),
 		pos:   startPos,
+		lead:  lead,
 		raw:   d.orig[startPos:endPos],
 		str:   string(name),
 	}, nil
@@ -589,10 +595,12 @@ func (d *Decoder) parseLiteralValue() (Token, bool) {
 // size-length from it.
 func (d *Decoder) consumeToken(kind Kind, size int, attrs uint8) Token {
 	// Important to compute raw and pos before consuming.
+	pos := len(d.orig) - len(d.in)
 	tok := Token{
 		kind:  kind,
 		attrs: attrs,
-		pos:   len(d.orig) - len(d.in),
+		pos:   pos,
+		lead:  d.leadStart(pos),
 		raw:   d.in[:size],
 	}
 	d.consume(size)
@@ -604,7 +612,25 @@ func (d *Decoder) consumeToken(kind Kind, size int, attrs uint8) Token {
 func (d *Decoder) newSyntaxError(f string, x ...any) error {
 	e := errors.New(f, x...)
 	line, column := d.Position(len(d.orig) - len(d.in))
-	return errors.New("syntax error (line %d:%d): %v", line, column, e)
+	return &SyntaxError{
+		Line:   line,
+		Column: column,
+		err:    errors.New("syntax error (line %d:%d): %v", line, column, e),
+	}
+}
+
+// SyntaxError is an error in the syntax of the input at a given position.
+type SyntaxError struct {
+	Line, Column int
+	err          error
+}
+
+func (e *SyntaxError) Error() string {
+	return e.err.Error()
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.err
 }
 
 // Position returns line and column number of given index of the original input.
@@ -629,8 +655,48 @@ func (d *Decoder) tryConsumeChar(c byte) bool {
 
 // consume consumes n bytes of input and any subsequent whitespace or comments.
 func (d *Decoder) consume(n int) {
+	start := len(d.orig) - len(d.in) + n
 	d.in = consume(d.in, n)
-	return
+	if end := len(d.orig) - len(d.in); n > 0 || end > start {
+		d.wsStart, d.wsEnd = start, end
+	}
+}
+
+// leadStart returns the start of the whitespace and comments immediately
+// preceding the token at the given position.
+func (d *Decoder) leadStart(pos int) int {
+	if d.wsEnd == pos {
+		return d.wsStart
+	}
+	return pos
+}
+
+// LeadingComments returns the text of the comment lines immediately preceding
+// the given token, excluding the leading '#' character.
+// A comment that follows another token on the same line is not included.
+func (d *Decoder) LeadingComments(tok Token) []string {
+	b := d.orig[tok.lead:tok.pos]
+	atLineStart := tok.lead == 0
+	var lines []string
+	for len(b) > 0 {
+		switch b[0] {
+		case '\n':
+			atLineStart = true
+			b = b[1:]
+		case '#':
+			i := bytes.IndexByte(b, '\n')
+			if i < 0 {
+				i = len(b)
+			}
+			if atLineStart {
+				lines = append(lines, string(bytes.TrimSuffix(b[1:i], []byte("\r"))))
+			}
+			b = b[i:]
+		default:
+			b = b[1:]
+		}
+	}
+	return lines
 }
 
 // consume consumes n bytes of input and any subsequent whitespace or comments.
@@ -15,10 +15,12 @@ func (d *Decoder) parseNumberValue() (Token, bool) {
 	if num.neg {
 		numAttrs |= isNegative
 	}
+	pos := len(d.orig) - len(d.in)
 	tok := Token{
 		kind:     Scalar,
 		attrs:    numberValue,
-		pos:      len(d.orig) - len(d.in),
+		pos:      pos,
+		lead:     d.leadStart(pos),
 		raw:      d.in[:num.size],
 		str:      num.string(d.in),
 		numAttrs: numAttrs,
@@ -28,6 +28,8 @@ func (d *Decoder) parseStringValue() (Token, error) {
 	// Thus, the following is valid:
 	//	`"foo"'bar'"baz"` => "foobarbaz"
 	in0 := d.in
+	pos := len(d.orig) - len(in0)
+	lead := d.leadStart(pos)
 	var ss []string
 	for len(d.in) > 0 && (d.in[0] == '"' || d.in[0] == '\'') {
 		s, err := d.parseString()
@@ -40,7 +42,8 @@ func (d *Decoder) parseStringValue() (Token, error) {
 	return Token{
 		kind:  Scalar,
 		attrs: stringValue,
-		pos:   len(d.orig) - len(in0),
+		pos:   pos,
+		lead:  lead,
 		raw:   in0[:len(in0)-len(d.in)],
 		str:   strings.Join(ss, ""),
 	}, nil
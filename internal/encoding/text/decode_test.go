@@ -1953,3 +1953,38 @@ func TestPosition(t *testing.T) {
 		})
 	}
 }
+
+func TestLeadingComments(t *testing.T) {
+	dec := text.NewDecoder([]byte(`# first
+# second
+a: 1 # trailing
+b: [2] # trailing
+
+  #indented
+c {
+  # nested
+  d: "x"
+}`))
+	want := map[string][]string{
+		"a": {" first", " second"},
+		"b": nil,
+		"c": {"indented"},
+		"d": {" nested"},
+	}
+	got := make(map[string][]string)
+	for {
+		tok, err := dec.Read()
+		if err != nil {
+			t.Fatalf("Read() error: %v", err)
+		}
+		if tok.Kind() == text.EOF {
+			break
+		}
+		if tok.Kind() == text.Name {
+			got[tok.IdentName()] = dec.LeadingComments(tok)
+		}
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("LeadingComments mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -119,6 +119,9 @@ type Token struct {
 	numAttrs uint8
 	// pos provides the position of the token in the original input.
 	pos int
+	// lead is the position in the original input of the whitespace and
+	// comments immediately preceding the token.
+	lead int
 	// raw bytes of the serialized token.
 	// This is a subslice into the original input.
 	raw []byte
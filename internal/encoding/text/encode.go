@@ -34,12 +34,15 @@ type Encoder struct {
 	indent      string
 	delims      [2]byte
 	outputASCII bool
+	stable      bool
+	sizeOnly    bool
 }
 
 type encoderState struct {
 	lastType encType
 	indents  []byte
 	out      []byte
+	size     int // number of bytes counted if sizeOnly is set
 }
 
 // NewEncoder returns an Encoder.
@@ -76,28 +79,91 @@ func NewEncoder(buf []byte, indent string, delims [2]byte, outputASCII bool) (*E
 	return e, nil
 }
 
+// SetStable disables the random variations in whitespace that are otherwise
+// introduced to discourage dependence on the exact output.
+func (e *Encoder) SetStable() {
+	e.stable = true
+}
+
+// SetSizeOnly causes the Encoder to count the bytes that it would write
+// instead of writing them. The count is reported by Size.
+func (e *Encoder) SetSizeOnly() {
+	e.sizeOnly = true
+}
+
 // Bytes returns the content of the written bytes.
 func (e *Encoder) Bytes() []byte {
 	return e.out
 }
 
+// Size returns the number of bytes counted since SetSizeOnly was called.
+func (e *Encoder) Size() int {
+	return e.size
+}
+
+// writeByte writes out c, or counts it if sizeOnly is set.
+func (e *Encoder) writeByte(c byte) {
+	if e.sizeOnly {
+		e.size++
+		return
+	}
+	e.out = append(e.out, c)
+}
+
+// write writes out s, or counts it if sizeOnly is set.
+func (e *Encoder) write(s string) {
+	if e.sizeOnly {
+		e.size += len(s)
+		return
+	}
+	e.out = append(e.out, s...)
+}
+
+// writeBytes writes out b, or counts it if sizeOnly is set.
+func (e *Encoder) writeBytes(b []byte) {
+	if e.sizeOnly {
+		e.size += len(b)
+		return
+	}
+	e.out = append(e.out, b...)
+}
+
 // StartMessage writes out the '{' or '<' symbol.
 func (e *Encoder) StartMessage() {
 	e.prepareNext(messageOpen)
-	e.out = append(e.out, e.delims[0])
+	e.writeByte(e.delims[0])
 }
 
 // EndMessage writes out the '}' or '>' symbol.
 func (e *Encoder) EndMessage() {
 	e.prepareNext(messageClose)
-	e.out = append(e.out, e.delims[1])
+	e.writeByte(e.delims[1])
+}
+
+// WriteComments writes out each of the given lines as a comment preceding the
+// next field name. Lines containing newlines are split into multiple comments.
+// Comments are only written in multi-line mode.
+func (e *Encoder) WriteComments(lines []string) {
+	if len(e.indent) == 0 {
+		return
+	}
+	for _, line := range lines {
+		for _, line := range strings.Split(line, "\n") {
+			e.prepareNext(name)
+			e.writeByte('#')
+			e.write(line)
+			// Treat the comment as a complete field so that the next
+			// element begins on a new line.
+			e.lastType = scalar
+		}
+	}
 }
 
 // WriteName writes out the field name and the separator ':'.
 func (e *Encoder) WriteName(s string) {
 	e.prepareNext(name)
-	e.out = append(e.out, s...)
-	e.out = append(e.out, ':')
+	e.write(s)
+	e.writeByte(':')
 }
 
 // WriteBool writes out the given boolean value.
@@ -112,6 +178,10 @@ func (e *Encoder) WriteBool(b bool) {
 // WriteString writes out the given string value.
 func (e *Encoder) WriteString(s string) {
 	e.prepareNext(scalar)
+	if e.sizeOnly {
+		e.size += stringSize(s, e.outputASCII)
+		return
+	}
 	e.out = appendString(e.out, s, e.outputASCII)
 }
 
@@ -164,6 +234,38 @@ func appendString(out []byte, in string, outputASCII bool) []byte {
 	return out
 }
 
+// stringSize returns the number of bytes appended by appendString.
+func stringSize(in string, outputASCII bool) int {
+	size := len(`""`)
+	for len(in) > 0 {
+		i := indexNeedEscapeInString(in)
+		size += i
+		in = in[i:]
+		if len(in) == 0 {
+			break
+		}
+		r, n := utf8.DecodeRuneInString(in)
+		switch {
+		case r == utf8.RuneError && n == 1:
+			size += len(`\xff`)
+		case r == '"' || r == '\\' || r == '\n' || r == '\r' || r == '\t':
+			size += len(`\n`)
+		case r < ' ' || r == 0x7f:
+			size += len(`\x00`)
+		case r >= utf8.RuneSelf && (outputASCII || r <= 0x009f):
+			if r <= math.MaxUint16 {
+				size += len(`\u0000`)
+			} else {
+				size += len(`\U00000000`)
+			}
+		default:
+			size += n
+		}
+		in = in[n:]
+	}
+	return size
+}
+
 // indexNeedEscapeInString returns the index of the character that needs
 // escaping. If no characters need escaping, this returns the input length.
 func indexNeedEscapeInString(s string) int {
@@ -178,6 +280,11 @@ func indexNeedEscapeInString(s string) int {
 // WriteFloat writes out the given float value for given bitSize.
 func (e *Encoder) WriteFloat(n float64, bitSize int) {
 	e.prepareNext(scalar)
+	if e.sizeOnly {
+		var buf [32]byte
+		e.size += len(appendFloat(buf[:0], n, bitSize))
+		return
+	}
 	e.out = appendFloat(e.out, n, bitSize)
 }
 
@@ -197,12 +304,22 @@ func appendFloat(out []byte, n float64, bitSize int) []byte {
 // WriteInt writes out the given signed integer value.
 func (e *Encoder) WriteInt(n int64) {
 	e.prepareNext(scalar)
+	if e.sizeOnly {
+		var buf [20]byte
+		e.size += len(strconv.AppendInt(buf[:0], n, 10))
+		return
+	}
 	e.out = strconv.AppendInt(e.out, n, 10)
 }
 
 // WriteUint writes out the given unsigned integer value.
 func (e *Encoder) WriteUint(n uint64) {
 	e.prepareNext(scalar)
+	if e.sizeOnly {
+		var buf [20]byte
+		e.size += len(strconv.AppendUint(buf[:0], n, 10))
+		return
+	}
 	e.out = strconv.AppendUint(e.out, n, 10)
 }
 
@@ -210,7 +327,7 @@ func (e *Encoder) WriteUint(n uint64) {
 // This is used for writing enum literal strings.
 func (e *Encoder) WriteLiteral(s string) {
 	e.prepareNext(scalar)
-	e.out = append(e.out, s...)
+	e.write(s)
 }
 
 // prepareNext adds possible space and indentation for the next value based
@@ -224,10 +341,10 @@ func (e *Encoder) prepareNext(next encType) {
 	if len(e.indent) == 0 {
 		// Add space after each field before the next one.
 		if e.lastType&(scalar|messageClose) != 0 && next == name {
-			e.out = append(e.out, ' ')
+			e.writeByte(' ')
 			// Add a random extra space to make output unstable.
-			if detrand.Bool() {
-				e.out = append(e.out, ' ')
+			if !e.stable && detrand.Bool() {
+				e.writeByte(' ')
 			}
 		}
 		return
@@ -236,23 +353,23 @@ func (e *Encoder) prepareNext(next encType) {
 	// Multi-line.
 	switch {
 	case e.lastType == name:
-		e.out = append(e.out, ' ')
+		e.writeByte(' ')
 		// Add a random extra space after name: to make output unstable.
-		if detrand.Bool() {
-			e.out = append(e.out, ' ')
+		if !e.stable && detrand.Bool() {
+			e.writeByte(' ')
 		}
 
 	case e.lastType == messageOpen && next != messageClose:
 		e.indents = append(e.indents, e.indent...)
-		e.out = append(e.out, '\n')
-		e.out = append(e.out, e.indents...)
+		e.writeByte('\n')
+		e.writeBytes(e.indents)
 
 	case e.lastType&(scalar|messageClose) != 0:
 		if next == messageClose {
 			e.indents = e.indents[:len(e.indents)-len(e.indent)]
 		}
-		e.out = append(e.out, '\n')
-		e.out = append(e.out, e.indents...)
+		e.writeByte('\n')
+		e.writeBytes(e.indents)
 	}
 }
 
@@ -104,6 +104,12 @@ type Builder struct {
 	// Requirement: len(LegacyExtensions) == len(Build.Extensions)
 	ExtensionInfos []pimpl.ExtensionInfo
 
+	// UnknownStorage, if non-nil, is the default unknown field storage
+	// for every message in MessageInfos that does not already specify one.
+	// It is not set by code generated by protoc-gen-go.
+	// See impl.MessageInfo.UnknownStorage.
+	UnknownStorage pimpl.UnknownFieldStorage
+
 	// TypeRegistry is the registry to register each type descriptor.
 	// If nil, it uses protoregistry.GlobalTypes.
 	TypeRegistry interface {
@@ -169,6 +175,9 @@ func (tb Builder) Build() (out Out) {
 
 			tb.MessageInfos[i].GoReflectType = reflect.TypeOf(messageGoTypes[i])
 			tb.MessageInfos[i].Desc = &fbOut.Messages[i]
+			if tb.MessageInfos[i].UnknownStorage == nil {
+				tb.MessageInfos[i].UnknownStorage = tb.UnknownStorage
+			}
 
 			// Register message types.
 			if err := tb.TypeRegistry.RegisterMessage(&tb.MessageInfos[i]); err != nil {
@@ -0,0 +1,97 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package filetype
+
+import (
+	"sync/atomic"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// DeferredRegistry is a file and type registry for Builder that records
+// the file descriptor and types that are built instead of registering them,
+// so that they may later be registered in registries chosen by the caller.
+//
+// Dependencies of the file are resolved lazily, from the file registry
+// passed to Register once it has been called and from
+// protoregistry.GlobalFiles before that.
+type DeferredRegistry struct {
+	files atomic.Pointer[protoregistry.Files]
+	file  protoreflect.FileDescriptor
+	enums []protoreflect.EnumType
+	msgs  []protoreflect.MessageType
+	exts  []protoreflect.ExtensionType
+}
+
+func (r *DeferredRegistry) resolver() *protoregistry.Files {
+	if files := r.files.Load(); files != nil {
+		return files
+	}
+	return protoregistry.GlobalFiles
+}
+
+func (r *DeferredRegistry) FindFileByPath(path string) (protoreflect.FileDescriptor, error) {
+	return r.resolver().FindFileByPath(path)
+}
+
+func (r *DeferredRegistry) FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error) {
+	return r.resolver().FindDescriptorByName(name)
+}
+
+func (r *DeferredRegistry) RegisterFile(fd protoreflect.FileDescriptor) error {
+	r.file = fd
+	return nil
+}
+
+func (r *DeferredRegistry) RegisterEnum(et protoreflect.EnumType) error {
+	r.enums = append(r.enums, et)
+	return nil
+}
+
+func (r *DeferredRegistry) RegisterMessage(mt protoreflect.MessageType) error {
+	r.msgs = append(r.msgs, mt)
+	return nil
+}
+
+func (r *DeferredRegistry) RegisterExtension(xt protoreflect.ExtensionType) error {
+	r.exts = append(r.exts, xt)
+	return nil
+}
+
+// Register registers the recorded file descriptor in files and
+// the recorded types in types. Either registry may be nil,
+// in which case nothing is registered in it.
+// If files is not nil, the dependencies of the file are resolved from it.
+// It reports the first registration conflict, if any.
+func (r *DeferredRegistry) Register(files *protoregistry.Files, types *protoregistry.Types) error {
+	if files != nil {
+		r.files.Store(files)
+	}
+	if files != nil && r.file != nil {
+		if err := files.RegisterFile(r.file); err != nil {
+			return err
+		}
+	}
+	if types == nil {
+		return nil
+	}
+	for _, et := range r.enums {
+		if err := types.RegisterEnum(et); err != nil {
+			return err
+		}
+	}
+	for _, mt := range r.msgs {
+		if err := types.RegisterMessage(mt); err != nil {
+			return err
+		}
+	}
+	for _, xt := range r.exts {
+		if err := types.RegisterExtension(xt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
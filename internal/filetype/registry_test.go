@@ -0,0 +1,71 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package filetype_test
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	regpb "google.golang.org/protobuf/internal/testprotos/explicitregistration"
+)
+
+func TestDeferredRegistry(t *testing.T) {
+	const (
+		basePath = "internal/testprotos/explicitregistration/base.proto"
+		extPath  = "internal/testprotos/explicitregistration/ext.proto"
+	)
+	if _, err := protoregistry.GlobalFiles.FindFileByPath(extPath); err == nil {
+		t.Fatalf("%v is registered in protoregistry.GlobalFiles", extPath)
+	}
+
+	files := new(protoregistry.Files)
+	types := new(protoregistry.Types)
+	if err := regpb.Register_internal_testprotos_explicitregistration_ext_proto(files, types); err != nil {
+		t.Fatal(err)
+	}
+	if err := regpb.Register_internal_testprotos_explicitregistration_base_proto(files, types); err != nil {
+		t.Fatal(err)
+	}
+
+	fd, err := files.FindFileByPath(extPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, err := files.FindFileByPath(basePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fd.Imports().Get(0).FileDescriptor; got != base {
+		t.Errorf("import of %v = %v (placeholder: %v), want the registered file", extPath, got.Path(), got.IsPlaceholder())
+	}
+
+	mt, err := types.FindMessageByName("goproto.proto.explicitregistration.Holder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	xt, err := types.FindExtensionByNumber("goproto.proto.explicitregistration.Base", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if xt.TypeDescriptor().Message() != mt.Descriptor() {
+		t.Errorf("extension %v has message type %v, want %v", xt.TypeDescriptor().FullName(), xt.TypeDescriptor().Message().FullName(), mt.Descriptor().FullName())
+	}
+
+	m := &regpb.Base{}
+	proto.SetExtension(m, regpb.E_Holder, &regpb.Holder{Base: &regpb.Base{Id: proto.Int32(1)}})
+	b, err := proto.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := &regpb.Base{}
+	if err := (proto.UnmarshalOptions{Resolver: types}).Unmarshal(b, got); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, m) {
+		t.Errorf("Unmarshal with registered types: got %v, want %v", got, m)
+	}
+}
@@ -22,3 +22,13 @@ const ProtoLegacy = protoLegacy
 // extension fields at unmarshal time, but defers creating the message
 // structure until the extension is first accessed.
 const LazyUnmarshalExtensions = ProtoLegacy
+
+// NoFastPath specifies whether to omit the table-driven fast-path
+// implementations of marshaling, unmarshaling, merging, and other operations
+// on generated messages, so that they use protobuf reflection instead.
+// The tables are not constructed, which reduces the memory used by each
+// message type, and the code which implements them is not linked into the
+// binary, at the cost of slower operations.
+//
+// This is disabled by default unless built with the "protonofastpath" tag.
+const NoFastPath = noFastPath
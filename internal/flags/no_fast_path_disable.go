@@ -0,0 +1,10 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !protonofastpath
+// +build !protonofastpath
+
+package flags
+
+const noFastPath = false
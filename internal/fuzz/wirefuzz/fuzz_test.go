@@ -2,6 +2,10 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+// The protonofastpath tag omits the fast-path methods checked by Fuzz.
+//go:build !protonofastpath
+// +build !protonofastpath
+
 package wirefuzz
 
 import (
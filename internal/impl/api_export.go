@@ -172,6 +172,7 @@ func (Export) MessageTypeOf(m message) protoreflect.MessageType {
 
 // MessageStringOf returns the message value as a string,
 // which is the message serialized in the protobuf text format.
+// Fields with the debug_redact option are redacted by prototext.
 func (Export) MessageStringOf(m protoreflect.ProtoMessage) string {
 	return prototext.MarshalOptions{Multiline: false}.Format(m)
 }
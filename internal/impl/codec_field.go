@@ -221,10 +221,7 @@ func consumeMessage(b []byte, m proto.Message, wtyp protowire.Type, opts unmarsh
 	if n < 0 {
 		return out, errDecode
 	}
-	o, err := opts.Options().UnmarshalState(protoiface.UnmarshalInput{
-		Buf:     v,
-		Message: m.ProtoReflect(),
-	})
+	o, err := opts.unmarshalNested(v, m.ProtoReflect())
 	if err != nil {
 		return out, err
 	}
@@ -365,10 +362,7 @@ func consumeGroup(b []byte, m proto.Message, num protowire.Number, wtyp protowir
 	if n < 0 {
 		return out, errDecode
 	}
-	o, err := opts.Options().UnmarshalState(protoiface.UnmarshalInput{
-		Buf:     b,
-		Message: m.ProtoReflect(),
-	})
+	o, err := opts.unmarshalNested(b, m.ProtoReflect())
 	if err != nil {
 		return out, err
 	}
@@ -408,7 +402,19 @@ func makeMessageSliceFieldCoder(fd protoreflect.FieldDescriptor, ft reflect.Type
 }
 
 func sizeMessageSliceInfo(p pointer, f *coderFieldInfo, opts marshalOptions) int {
-	s := p.PointerSlice()
+	return sizeMessagePointers(p.PointerSlice(), f, opts)
+}
+
+func appendMessageSliceInfo(b []byte, p pointer, f *coderFieldInfo, opts marshalOptions) ([]byte, error) {
+	return appendMessagePointers(b, p.PointerSlice(), f, opts)
+}
+
+// sizeMessagePointers returns the size of the wire encoding of the messages
+// in s as elements of the repeated field f.
+func sizeMessagePointers(s []pointer, f *coderFieldInfo, opts marshalOptions) int {
+	if opts.parallel(len(s)) {
+		return sizeMessagePointersParallel(s, f, opts)
+	}
 	n := 0
 	for _, v := range s {
 		n += protowire.SizeBytes(f.mi.sizePointer(v, opts)) + f.tagsize
@@ -416,8 +422,12 @@ func sizeMessageSliceInfo(p pointer, f *coderFieldInfo, opts marshalOptions) int
 	return n
 }
 
-func appendMessageSliceInfo(b []byte, p pointer, f *coderFieldInfo, opts marshalOptions) ([]byte, error) {
-	s := p.PointerSlice()
+// appendMessagePointers wire encodes the messages in s
+// as elements of the repeated field f.
+func appendMessagePointers(b []byte, s []pointer, f *coderFieldInfo, opts marshalOptions) ([]byte, error) {
+	if opts.parallel(len(s)) {
+		return appendMessagePointersParallel(b, s, f, opts)
+	}
 	var err error
 	for _, v := range s {
 		b = protowire.AppendVarint(b, f.wiretag)
@@ -506,10 +516,7 @@ func consumeMessageSlice(b []byte, p pointer, goType reflect.Type, wtyp protowir
 		return out, errDecode
 	}
 	mp := reflect.New(goType.Elem())
-	o, err := opts.Options().UnmarshalState(protoiface.UnmarshalInput{
-		Buf:     v,
-		Message: asMessage(mp).ProtoReflect(),
-	})
+	o, err := opts.unmarshalNested(v, asMessage(mp).ProtoReflect())
 	if err != nil {
 		return out, err
 	}
@@ -574,10 +581,7 @@ func consumeMessageSliceValue(b []byte, listv protoreflect.Value, _ protowire.Nu
 		return protoreflect.Value{}, out, errDecode
 	}
 	m := list.NewElement()
-	o, err := opts.Options().UnmarshalState(protoiface.UnmarshalInput{
-		Buf:     v,
-		Message: m.Message(),
-	})
+	o, err := opts.unmarshalNested(v, m.Message())
 	if err != nil {
 		return protoreflect.Value{}, out, err
 	}
@@ -643,10 +647,7 @@ func consumeGroupSliceValue(b []byte, listv protoreflect.Value, num protowire.Nu
 		return protoreflect.Value{}, out, errDecode
 	}
 	m := list.NewElement()
-	o, err := opts.Options().UnmarshalState(protoiface.UnmarshalInput{
-		Buf:     b,
-		Message: m.Message(),
-	})
+	o, err := opts.unmarshalNested(b, m.Message())
 	if err != nil {
 		return protoreflect.Value{}, out, err
 	}
@@ -730,10 +731,7 @@ func consumeGroupSlice(b []byte, p pointer, num protowire.Number, wtyp protowire
 		return out, errDecode
 	}
 	mp := reflect.New(goType.Elem())
-	o, err := opts.Options().UnmarshalState(protoiface.UnmarshalInput{
-		Buf:     b,
-		Message: asMessage(mp).ProtoReflect(),
-	})
+	o, err := opts.unmarshalNested(b, asMessage(mp).ProtoReflect())
 	if err != nil {
 		return out, err
 	}
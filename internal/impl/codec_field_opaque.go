@@ -147,31 +147,11 @@ func makeOpaqueRepeatedMessageFieldCoder(fd protoreflect.FieldDescriptor, ft ref
 }
 
 func sizeOpaqueMessageSlice(p pointer, f *coderFieldInfo, opts marshalOptions) (size int) {
-	s := p.AtomicGetPointer().PointerSlice()
-	n := 0
-	for _, v := range s {
-		n += protowire.SizeBytes(f.mi.sizePointer(v, opts)) + f.tagsize
-	}
-	return n
+	return sizeMessagePointers(p.AtomicGetPointer().PointerSlice(), f, opts)
 }
 
 func appendOpaqueMessageSlice(b []byte, p pointer, f *coderFieldInfo, opts marshalOptions) ([]byte, error) {
-	s := p.AtomicGetPointer().PointerSlice()
-	var err error
-	for _, v := range s {
-		b = protowire.AppendVarint(b, f.wiretag)
-		siz := f.mi.sizePointer(v, opts)
-		b = protowire.AppendVarint(b, uint64(siz))
-		before := len(b)
-		b, err = f.mi.marshalAppendPointer(b, v, opts)
-		if err != nil {
-			return b, err
-		}
-		if measuredSize := len(b) - before; siz != measuredSize {
-			return nil, errors.MismatchedSizeCalculation(siz, measuredSize)
-		}
-	}
-	return b, nil
+	return appendMessagePointers(b, p.AtomicGetPointer().PointerSlice(), f, opts)
 }
 
 func consumeOpaqueMessageSlice(b []byte, p pointer, wtyp protowire.Type, f *coderFieldInfo, opts unmarshalOptions) (out unmarshalOutput, err error) {
@@ -5026,7 +5026,7 @@ func consumeString(b []byte, p pointer, wtyp protowire.Type, f *coderFieldInfo,
 	if n < 0 {
 		return out, errDecode
 	}
-	*p.String() = string(v)
+	*p.String() = opts.copyString(v)
 	out.n = n
 	return out, nil
 }
@@ -5061,7 +5061,7 @@ func consumeStringValidateUTF8(b []byte, p pointer, wtyp protowire.Type, f *code
 	if !utf8.Valid(v) {
 		return out, errInvalidUTF8{}
 	}
-	*p.String() = string(v)
+	*p.String() = opts.copyString(v)
 	out.n = n
 	return out, nil
 }
@@ -5153,7 +5153,7 @@ func consumeStringPtr(b []byte, p pointer, wtyp protowire.Type, f *coderFieldInf
 	if *vp == nil {
 		*vp = new(string)
 	}
-	**vp = string(v)
+	**vp = opts.copyString(v)
 	out.n = n
 	return out, nil
 }
@@ -5193,7 +5193,7 @@ func consumeStringPtrValidateUTF8(b []byte, p pointer, wtyp protowire.Type, f *c
 	if *vp == nil {
 		*vp = new(string)
 	}
-	**vp = string(v)
+	**vp = opts.copyString(v)
 	out.n = n
 	return out, nil
 }
@@ -5234,7 +5234,7 @@ func consumeStringSlice(b []byte, p pointer, wtyp protowire.Type, f *coderFieldI
 	if n < 0 {
 		return out, errDecode
 	}
-	*sp = append(*sp, string(v))
+	*sp = append(*sp, opts.copyString(v))
 	out.n = n
 	return out, nil
 }
@@ -5272,7 +5272,7 @@ func consumeStringSliceValidateUTF8(b []byte, p pointer, wtyp protowire.Type, f
 		return out, errInvalidUTF8{}
 	}
 	sp := p.StringSlice()
-	*sp = append(*sp, string(v))
+	*sp = append(*sp, opts.copyString(v))
 	out.n = n
 	return out, nil
 }
@@ -5306,7 +5306,7 @@ func consumeStringValue(b []byte, _ protoreflect.Value, _ protowire.Number, wtyp
 		return protoreflect.Value{}, out, errDecode
 	}
 	out.n = n
-	return protoreflect.ValueOfString(string(v)), out, nil
+	return protoreflect.ValueOfString(opts.copyString(v)), out, nil
 }
 
 var coderStringValue = valueCoderFuncs{
@@ -5339,7 +5339,7 @@ func consumeStringValueValidateUTF8(b []byte, _ protoreflect.Value, _ protowire.
 		return protoreflect.Value{}, out, errInvalidUTF8{}
 	}
 	out.n = n
-	return protoreflect.ValueOfString(string(v)), out, nil
+	return protoreflect.ValueOfString(opts.copyString(v)), out, nil
 }
 
 var coderStringValueValidateUTF8 = valueCoderFuncs{
@@ -5380,7 +5380,7 @@ func consumeStringSliceValue(b []byte, listv protoreflect.Value, _ protowire.Num
 	if n < 0 {
 		return protoreflect.Value{}, out, errDecode
 	}
-	list.Append(protoreflect.ValueOfString(string(v)))
+	list.Append(protoreflect.ValueOfString(opts.copyString(v)))
 	out.n = n
 	return listv, out, nil
 }
@@ -5415,7 +5415,7 @@ func consumeBytes(b []byte, p pointer, wtyp protowire.Type, f *coderFieldInfo, o
 	if n < 0 {
 		return out, errDecode
 	}
-	*p.Bytes() = append(emptyBuf[:], v...)
+	*p.Bytes() = opts.copyBytes(v)
 	out.n = n
 	return out, nil
 }
@@ -5450,7 +5450,7 @@ func consumeBytesValidateUTF8(b []byte, p pointer, wtyp protowire.Type, f *coder
 	if !utf8.Valid(v) {
 		return out, errInvalidUTF8{}
 	}
-	*p.Bytes() = append(emptyBuf[:], v...)
+	*p.Bytes() = opts.copyBytes(v)
 	out.n = n
 	return out, nil
 }
@@ -5494,7 +5494,7 @@ func consumeBytesNoZero(b []byte, p pointer, wtyp protowire.Type, f *coderFieldI
 	if n < 0 {
 		return out, errDecode
 	}
-	*p.Bytes() = append(([]byte)(nil), v...)
+	*p.Bytes() = opts.copyBytesNoZero(v)
 	out.n = n
 	return out, nil
 }
@@ -5533,7 +5533,7 @@ func consumeBytesNoZeroValidateUTF8(b []byte, p pointer, wtyp protowire.Type, f
 	if !utf8.Valid(v) {
 		return out, errInvalidUTF8{}
 	}
-	*p.Bytes() = append(([]byte)(nil), v...)
+	*p.Bytes() = opts.copyBytesNoZero(v)
 	out.n = n
 	return out, nil
 }
@@ -5574,7 +5574,7 @@ func consumeBytesSlice(b []byte, p pointer, wtyp protowire.Type, f *coderFieldIn
 	if n < 0 {
 		return out, errDecode
 	}
-	*sp = append(*sp, append(emptyBuf[:], v...))
+	*sp = append(*sp, opts.copyBytes(v))
 	out.n = n
 	return out, nil
 }
@@ -5612,7 +5612,7 @@ func consumeBytesSliceValidateUTF8(b []byte, p pointer, wtyp protowire.Type, f *
 		return out, errInvalidUTF8{}
 	}
 	sp := p.BytesSlice()
-	*sp = append(*sp, append(emptyBuf[:], v...))
+	*sp = append(*sp, opts.copyBytes(v))
 	out.n = n
 	return out, nil
 }
@@ -5646,7 +5646,7 @@ func consumeBytesValue(b []byte, _ protoreflect.Value, _ protowire.Number, wtyp
 		return protoreflect.Value{}, out, errDecode
 	}
 	out.n = n
-	return protoreflect.ValueOfBytes(append(emptyBuf[:], v...)), out, nil
+	return protoreflect.ValueOfBytes(opts.copyBytes(v)), out, nil
 }
 
 var coderBytesValue = valueCoderFuncs{
@@ -5687,7 +5687,7 @@ func consumeBytesSliceValue(b []byte, listv protoreflect.Value, _ protowire.Numb
 	if n < 0 {
 		return protoreflect.Value{}, out, errDecode
 	}
-	list.Append(protoreflect.ValueOfBytes(append(emptyBuf[:], v...)))
+	list.Append(protoreflect.ValueOfBytes(opts.copyBytes(v)))
 	out.n = n
 	return listv, out, nil
 }
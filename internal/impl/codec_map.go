@@ -5,6 +5,8 @@
 package impl
 
 import (
+	"bytes"
+	"math"
 	"reflect"
 	"sort"
 
@@ -81,6 +83,10 @@ func encoderFuncsForMap(fd protoreflect.FieldDescriptor, ft reflect.Type) (value
 			return isInitMap(p.AsValueOf(ft).Elem(), mapi, f)
 		}
 	}
+	valKind := valField.Kind()
+	funcs.equal = func(x, y pointer, f *coderFieldInfo) bool {
+		return equalMap(x.AsValueOf(ft).Elem(), y.AsValueOf(ft).Elem(), valKind, mapi)
+	}
 	return valueMessage, funcs
 }
 
@@ -397,3 +403,45 @@ func mergeMapOfMessage(dst, src pointer, f *coderFieldInfo, opts mergeOptions) {
 		dstm.SetMapIndex(iter.Key(), val)
 	}
 }
+
+// equalMap reports whether two Go maps of the same type are equal
+// according to the semantics of proto.Equal.
+func equalMap(mx, my reflect.Value, valKind protoreflect.Kind, mapi *mapInfo) bool {
+	if mx.Len() != my.Len() {
+		return false
+	}
+	if mx.Len() == 0 {
+		return true
+	}
+	key := reflect.New(mapi.goType.Key()).Elem()
+	vx := reflect.New(mapi.goType.Elem()).Elem()
+	iter := mx.MapRange()
+	for iter.Next() {
+		key.SetIterKey(iter)
+		vy := my.MapIndex(key)
+		if !vy.IsValid() {
+			return false
+		}
+		vx.SetIterValue(iter)
+		switch valKind {
+		case protoreflect.MessageKind:
+			if !equalMessage(mapi.conv.valConv.PBValueOf(vx).Message(), mapi.conv.valConv.PBValueOf(vy).Message()) {
+				return false
+			}
+		case protoreflect.BytesKind:
+			if !bytes.Equal(vx.Bytes(), vy.Bytes()) {
+				return false
+			}
+		case protoreflect.FloatKind, protoreflect.DoubleKind:
+			x, y := vx.Float(), vy.Float()
+			if x != y && !(math.IsNaN(x) && math.IsNaN(y)) {
+				return false
+			}
+		default:
+			if !vx.Equal(vy) {
+				return false
+			}
+		}
+	}
+	return true
+}
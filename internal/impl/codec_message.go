@@ -11,6 +11,7 @@ import (
 
 	"google.golang.org/protobuf/encoding/protowire"
 	"google.golang.org/protobuf/internal/encoding/messageset"
+	"google.golang.org/protobuf/internal/flags"
 	"google.golang.org/protobuf/internal/order"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/runtime/protoiface"
@@ -28,6 +29,7 @@ type coderMessageInfo struct {
 	sizecacheOffset    offset
 	unknownOffset      offset
 	unknownPtrKind     bool
+	retainsUnknown     bool
 	extensionOffset    offset
 	needsInitCheck     bool
 	isMessageSet       bool
@@ -70,10 +72,17 @@ func (mi *MessageInfo) makeCoderMethods(t reflect.Type, si structInfo) {
 		mi.unknownOffset = si.unknownOffset
 		mi.unknownPtrKind = si.unknownType.Kind() == reflect.Ptr
 	}
+	mi.retainsUnknown = mi.unknownOffset.IsValid() || mi.UnknownStorage != nil
 	if si.extensionOffset.IsValid() && si.extensionType == extensionFieldsType {
 		mi.extensionOffset = si.extensionOffset
 	}
 
+	// Leave the fast-path methods unset,
+	// so that all operations fall back to protobuf reflection.
+	if flags.NoFastPath {
+		return
+	}
+
 	mi.coderFields = make(map[protowire.Number]*coderFieldInfo)
 	fields := mi.Desc.Fields()
 	preallocFields := make([]coderFieldInfo, fields.Len())
@@ -150,7 +159,7 @@ func (mi *MessageInfo) makeCoderMethods(t reflect.Type, si structInfo) {
 		if !mi.extensionOffset.IsValid() {
 			panic(fmt.Sprintf("%v: MessageSet with no extensions field", mi.Desc.FullName()))
 		}
-		if !mi.unknownOffset.IsValid() {
+		if !mi.retainsUnknown {
 			panic(fmt.Sprintf("%v: MessageSet with no unknown field", mi.Desc.FullName()))
 		}
 		mi.isMessageSet = true
@@ -228,3 +237,46 @@ func (mi *MessageInfo) mutableUnknownBytes(p pointer) *[]byte {
 		return p.Apply(mi.unknownOffset).Bytes()
 	}
 }
+
+// unknownBytes returns the unknown fields of p.
+func (mi *MessageInfo) unknownBytes(p pointer) []byte {
+	if mi.UnknownStorage != nil {
+		return mi.UnknownStorage.Get(mi.protoMessageOf(p))
+	}
+	if !mi.unknownOffset.IsValid() {
+		return nil
+	}
+	if u := mi.getUnknownBytes(p); u != nil {
+		return *u
+	}
+	return nil
+}
+
+// appendUnknown appends an unknown field with the given number, wire type,
+// and encoded value to the unknown fields of p.
+func (mi *MessageInfo) appendUnknown(p pointer, num protowire.Number, wtyp protowire.Type, v []byte) {
+	if mi.UnknownStorage != nil {
+		b := make([]byte, 0, protowire.SizeTag(num)+len(v))
+		b = protowire.AppendTag(b, num, wtyp)
+		mi.UnknownStorage.Append(mi.protoMessageOf(p), append(b, v...))
+		return
+	}
+	u := mi.mutableUnknownBytes(p)
+	*u = protowire.AppendTag(*u, num, wtyp)
+	*u = append(*u, v...)
+}
+
+// appendUnknownBytes appends raw unknown fields to the unknown fields of p.
+func (mi *MessageInfo) appendUnknownBytes(p pointer, b []byte) {
+	if mi.UnknownStorage != nil {
+		mi.UnknownStorage.Append(mi.protoMessageOf(p), b)
+		return
+	}
+	u := mi.mutableUnknownBytes(p)
+	*u = append(*u, b...)
+}
+
+// protoMessageOf returns the message that p points to.
+func (mi *MessageInfo) protoMessageOf(p pointer) protoreflect.ProtoMessage {
+	return p.AsIfaceOf(mi.GoReflectType.Elem()).(protoreflect.ProtoMessage)
+}
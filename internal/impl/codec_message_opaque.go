@@ -11,6 +11,7 @@ import (
 
 	"google.golang.org/protobuf/encoding/protowire"
 	"google.golang.org/protobuf/internal/encoding/messageset"
+	"google.golang.org/protobuf/internal/flags"
 	"google.golang.org/protobuf/internal/order"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	piface "google.golang.org/protobuf/runtime/protoiface"
@@ -20,10 +21,17 @@ func (mi *MessageInfo) makeOpaqueCoderMethods(t reflect.Type, si opaqueStructInf
 	mi.sizecacheOffset = si.sizecacheOffset
 	mi.unknownOffset = si.unknownOffset
 	mi.unknownPtrKind = si.unknownType.Kind() == reflect.Ptr
+	mi.retainsUnknown = mi.unknownOffset.IsValid() || mi.UnknownStorage != nil
 	mi.extensionOffset = si.extensionOffset
 	mi.lazyOffset = si.lazyOffset
 	mi.presenceOffset = si.presenceOffset
 
+	// Leave the fast-path methods unset,
+	// so that all operations fall back to protobuf reflection.
+	if flags.NoFastPath {
+		return
+	}
+
 	mi.coderFields = make(map[protowire.Number]*coderFieldInfo)
 	fields := mi.Desc.Fields()
 	for i := 0; i < fields.Len(); i++ {
@@ -98,7 +106,7 @@ func (mi *MessageInfo) makeOpaqueCoderMethods(t reflect.Type, si opaqueStructInf
 		if !mi.extensionOffset.IsValid() {
 			panic(fmt.Sprintf("%v: MessageSet with no extensions field", mi.Desc.FullName()))
 		}
-		if !mi.unknownOffset.IsValid() {
+		if !mi.retainsUnknown {
 			panic(fmt.Sprintf("%v: MessageSet with no unknown field", mi.Desc.FullName()))
 		}
 		mi.isMessageSet = true
@@ -38,8 +38,8 @@ func sizeMessageSet(mi *MessageInfo, p pointer, opts marshalOptions) (size int)
 		size += xi.funcs.size(x.Value(), protowire.SizeTag(messageset.FieldMessage), opts)
 	}
 
-	if u := mi.getUnknownBytes(p); u != nil {
-		size += messageset.SizeUnknown(*u)
+	if u := mi.unknownBytes(p); len(u) > 0 {
+		size += messageset.SizeUnknown(u)
 	}
 
 	return size
@@ -79,9 +79,9 @@ func marshalMessageSet(mi *MessageInfo, b []byte, p pointer, opts marshalOptions
 		}
 	}
 
-	if u := mi.getUnknownBytes(p); u != nil {
+	if u := mi.unknownBytes(p); len(u) > 0 {
 		var err error
-		b, err = messageset.AppendUnknown(b, *u)
+		b, err = messageset.AppendUnknown(b, u)
 		if err != nil {
 			return b, err
 		}
@@ -129,9 +129,7 @@ func unmarshalMessageSet(mi *MessageInfo, b []byte, p pointer, opts unmarshalOpt
 	err = messageset.Unmarshal(b, true, func(num protowire.Number, v []byte) error {
 		o, err := mi.unmarshalExtension(v, num, protowire.BytesType, ext, opts)
 		if err == errUnknown {
-			u := mi.mutableUnknownBytes(p)
-			*u = protowire.AppendTag(*u, num, protowire.BytesType)
-			*u = append(*u, v...)
+			mi.appendUnknown(p, num, protowire.BytesType, v)
 			return nil
 		}
 		if !o.initialized {
@@ -0,0 +1,79 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package impl
+
+import "sync"
+
+// minParallelMessages is the minimum number of elements of a repeated
+// message field for the field to be sized or marshaled concurrently.
+const minParallelMessages = 256
+
+// parallel reports whether to size or marshal n elements of a repeated
+// message field concurrently.
+func (o marshalOptions) parallel(n int) bool {
+	return o.concurrency > 1 && n >= minParallelMessages
+}
+
+// splitPointers divides s into n contiguous chunks of nearly equal length,
+// or into len(s) chunks of one element if n is greater than len(s).
+func splitPointers(s []pointer, n int) [][]pointer {
+	if n > len(s) {
+		n = len(s)
+	}
+	chunks := make([][]pointer, n)
+	for i := range chunks {
+		chunks[i] = s[i*len(s)/n : (i+1)*len(s)/n]
+	}
+	return chunks
+}
+
+func sizeMessagePointersParallel(s []pointer, f *coderFieldInfo, opts marshalOptions) int {
+	chunks := splitPointers(s, opts.concurrency)
+	sizes := make([]int, len(chunks))
+	opts.concurrency = 0 // nested fields are sized serially
+	var wg sync.WaitGroup
+	for i, c := range chunks {
+		wg.Add(1)
+		go func(i int, c []pointer) {
+			defer wg.Done()
+			sizes[i] = sizeMessagePointers(c, f, opts)
+		}(i, c)
+	}
+	wg.Wait()
+	n := 0
+	for _, size := range sizes {
+		n += size
+	}
+	return n
+}
+
+// appendMessagePointersParallel encodes each chunk of s into a separate
+// buffer, which are then appended to b in order. Since the encoding of each
+// element is independent of the others, the result is identical to that of
+// encoding the elements serially.
+func appendMessagePointersParallel(b []byte, s []pointer, f *coderFieldInfo, opts marshalOptions) ([]byte, error) {
+	chunks := splitPointers(s, opts.concurrency)
+	bufs := make([][]byte, len(chunks))
+	errs := make([]error, len(chunks))
+	opts.concurrency = 0 // nested fields are marshaled serially
+	var wg sync.WaitGroup
+	for i, c := range chunks {
+		wg.Add(1)
+		go func(i int, c []pointer) {
+			defer wg.Done()
+			bufs[i], errs[i] = appendMessagePointers(nil, c, f, opts)
+		}(i, c)
+	}
+	wg.Wait()
+	for i, buf := range bufs {
+		if errs[i] != nil {
+			// Report the same error as a serial marshal would,
+			// which is the first error in order of the elements.
+			return b, errs[i]
+		}
+		b = append(b, buf...)
+	}
+	return b, nil
+}
@@ -0,0 +1,27 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package impl
+
+import "testing"
+
+func TestSplitPointers(t *testing.T) {
+	s := make([]pointer, 5)
+	for _, n := range []int{1, 2, 3, 5, 8} {
+		chunks := splitPointers(s, n)
+		if want := min(n, len(s)); len(chunks) != want {
+			t.Errorf("splitPointers(%d elements, %d) returned %d chunks, want %d", len(s), n, len(chunks), want)
+		}
+		total := 0
+		for _, c := range chunks {
+			if len(c) == 0 {
+				t.Errorf("splitPointers(%d elements, %d) returned an empty chunk", len(s), n)
+			}
+			total += len(c)
+		}
+		if total != len(s) {
+			t.Errorf("splitPointers(%d elements, %d) returned %d elements in total, want %d", len(s), n, total, len(s))
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"reflect"
 
 	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/runtime/protoiface"
 )
 
 func newListConverter(t reflect.Type, fd protoreflect.FieldDescriptor) Converter {
@@ -139,3 +140,11 @@ func (ls *listReflect) IsValid() bool {
 func (ls *listReflect) protoUnwrap() any {
 	return ls.v.Interface()
 }
+
+var _ protoiface.ListSlicePointer = (*listReflect)(nil)
+
+// ProtoSlicePointer returns the pointer to the backing slice,
+// for use by [protoreflect.ListSlice].
+func (ls *listReflect) ProtoSlicePointer() any {
+	return ls.v.Interface()
+}
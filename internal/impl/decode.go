@@ -10,6 +10,7 @@ import (
 	"google.golang.org/protobuf/encoding/protowire"
 	"google.golang.org/protobuf/internal/errors"
 	"google.golang.org/protobuf/internal/flags"
+	"google.golang.org/protobuf/internal/strs"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/reflect/protoregistry"
@@ -26,6 +27,12 @@ type unmarshalOptions struct {
 		FindExtensionByNumber(message protoreflect.FullName, field protoreflect.FieldNumber) (protoreflect.ExtensionType, error)
 	}
 	depth int
+
+	// aliasValues reports whether the caller permitted string and bytes
+	// values to alias the input buffer. This differs from the
+	// UnmarshalAliasBuffer flag, which is also set internally by lazy
+	// decoding once it has copied the input.
+	aliasValues bool
 }
 
 func (o unmarshalOptions) Options() proto.UnmarshalOptions {
@@ -36,9 +43,26 @@ func (o unmarshalOptions) Options() proto.UnmarshalOptions {
 		Resolver:       o.resolver,
 
 		NoLazyDecoding: o.NoLazyDecoding(),
+		AliasBuffer:    o.aliasValues,
 	}
 }
 
+// unmarshalNested unmarshals a message which does not support the fast-path
+// unmarshaler (or is of unknown type) through the proto package, passing
+// along the remaining recursion depth.
+func (o unmarshalOptions) unmarshalNested(b []byte, m protoreflect.Message) (protoiface.UnmarshalOutput, error) {
+	// A depth of zero means "unset" to UnmarshalState,
+	// so an exhausted depth must be reported here.
+	if o.depth <= 0 {
+		return protoiface.UnmarshalOutput{}, errRecursionDepth
+	}
+	return o.Options().UnmarshalState(protoiface.UnmarshalInput{
+		Buf:     b,
+		Message: m,
+		Depth:   o.depth,
+	})
+}
+
 func (o unmarshalOptions) DiscardUnknown() bool {
 	return o.flags&protoiface.UnmarshalDiscardUnknown != 0
 }
@@ -57,6 +81,42 @@ func (o unmarshalOptions) CanBeLazy() bool {
 	return (o.flags & ^(protoiface.UnmarshalAliasBuffer | protoiface.UnmarshalValidated | protoiface.UnmarshalCheckRequired)) == 0
 }
 
+// copyString returns b as a string. The string references b if the
+// input buffer may be aliased, and is newly allocated otherwise.
+func (o unmarshalOptions) copyString(b []byte) string {
+	if o.aliasValues {
+		return strs.UnsafeString(b)
+	}
+	return string(b)
+}
+
+// copyBytes returns a copy of b, which is non-nil even if b is empty.
+// If the input buffer may be aliased, b itself is returned instead.
+func (o unmarshalOptions) copyBytes(b []byte) []byte {
+	if o.aliasValues && b != nil {
+		return aliasBytes(b)
+	}
+	return append(emptyBuf[:], b...)
+}
+
+// copyBytesNoZero returns a copy of b, which is nil if b is empty.
+// If the input buffer may be aliased, b itself is returned instead.
+func (o unmarshalOptions) copyBytesNoZero(b []byte) []byte {
+	if o.aliasValues {
+		if len(b) == 0 {
+			return nil
+		}
+		return aliasBytes(b)
+	}
+	return append(([]byte)(nil), b...)
+}
+
+// aliasBytes returns b with its capacity limited to its length,
+// so that appending to the result never overwrites the input buffer.
+func aliasBytes(b []byte) []byte {
+	return b[:len(b):len(b)]
+}
+
 var lazyUnmarshalOptions = unmarshalOptions{
 	resolver: protoregistry.GlobalTypes,
 
@@ -82,6 +142,8 @@ func (mi *MessageInfo) unmarshal(in protoiface.UnmarshalInput) (protoiface.Unmar
 		flags:    in.Flags,
 		resolver: in.Resolver,
 		depth:    in.Depth,
+
+		aliasValues: in.Flags&protoiface.UnmarshalAliasBuffer != 0,
 	})
 	var flags protoiface.UnmarshalOutputFlags
 	if out.initialized {
@@ -224,10 +286,8 @@ func (mi *MessageInfo) unmarshalPointerEager(b []byte, p pointer, groupTag proto
 			if n < 0 {
 				return out, errDecode
 			}
-			if !opts.DiscardUnknown() && mi.unknownOffset.IsValid() {
-				u := mi.mutableUnknownBytes(p)
-				*u = protowire.AppendTag(*u, num, wtyp)
-				*u = append(*u, b[:n]...)
+			if !opts.DiscardUnknown() && mi.retainsUnknown {
+				mi.appendUnknown(p, num, wtyp, b[:n])
 			}
 		}
 		b = b[n:]
@@ -16,7 +16,8 @@ import (
 )
 
 type marshalOptions struct {
-	flags piface.MarshalInputFlags
+	flags       piface.MarshalInputFlags
+	concurrency int
 }
 
 func (o marshalOptions) Options() proto.MarshalOptions {
@@ -24,6 +25,7 @@ func (o marshalOptions) Options() proto.MarshalOptions {
 		AllowPartial:  true,
 		Deterministic: o.Deterministic(),
 		UseCachedSize: o.UseCachedSize(),
+		Concurrency:   o.concurrency,
 	}
 }
 
@@ -39,7 +41,8 @@ func (mi *MessageInfo) size(in piface.SizeInput) piface.SizeOutput {
 		p = in.Message.(*messageReflectWrapper).pointer()
 	}
 	size := mi.sizePointer(p, marshalOptions{
-		flags: in.Flags,
+		flags:       in.Flags,
+		concurrency: in.Concurrency,
 	})
 	return piface.SizeOutput{Size: size}
 }
@@ -110,10 +113,8 @@ func (mi *MessageInfo) sizePointerSlow(p pointer, opts marshalOptions) (size int
 		}
 		size += f.funcs.size(fptr, f, opts)
 	}
-	if mi.unknownOffset.IsValid() {
-		if u := mi.getUnknownBytes(p); u != nil {
-			size += len(*u)
-		}
+	if mi.retainsUnknown {
+		size += len(mi.unknownBytes(p))
 	}
 	if mi.sizecacheOffset.IsValid() {
 		if size > (math.MaxInt32 - 1) {
@@ -140,7 +141,8 @@ func (mi *MessageInfo) marshal(in piface.MarshalInput) (out piface.MarshalOutput
 		p = in.Message.(*messageReflectWrapper).pointer()
 	}
 	b, err := mi.marshalAppendPointer(in.Buf, p, marshalOptions{
-		flags: in.Flags,
+		flags:       in.Flags,
+		concurrency: in.Concurrency,
 	})
 	return piface.MarshalOutput{Buf: b}, err
 }
@@ -217,10 +219,8 @@ func (mi *MessageInfo) marshalAppendPointer(b []byte, p pointer, opts marshalOpt
 			return b, err
 		}
 	}
-	if mi.unknownOffset.IsValid() && !mi.isMessageSet {
-		if u := mi.getUnknownBytes(p); u != nil {
-			b = append(b, (*u)...)
-		}
+	if mi.retainsUnknown && !mi.isMessageSet {
+		b = append(b, mi.unknownBytes(p)...)
 	}
 	return b, nil
 }
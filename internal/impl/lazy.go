@@ -362,10 +362,8 @@ func (mi *MessageInfo) unmarshalPointerLazy(b []byte, p pointer, groupTag protow
 			if n < 0 {
 				return out, errDecode
 			}
-			if !discardUnknown && !opts.DiscardUnknown() && mi.unknownOffset.IsValid() {
-				u := mi.mutableUnknownBytes(p)
-				*u = protowire.AppendTag(*u, num, wtyp)
-				*u = append(*u, b[:n]...)
+			if !discardUnknown && !opts.DiscardUnknown() && mi.retainsUnknown {
+				mi.appendUnknown(p, num, wtyp, b[:n])
 			}
 		}
 		b = b[n:]
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	mixedpb "google.golang.org/protobuf/internal/testprotos/mixed"
+	testopaquepb "google.golang.org/protobuf/internal/testprotos/testeditions/testeditions_opaque"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -149,3 +150,35 @@ func testNoExcessiveCopy(t *testing.T, unmarshalOpts proto.UnmarshalOptions) {
 	}
 
 }
+
+// TestNoAliasOfLazyCopy tests that string and bytes values do not share
+// the copy of the buffer made for lazy unmarshaling, which would otherwise
+// retain all of it, unless AliasBuffer is set.
+func TestNoAliasOfLazyCopy(t *testing.T) {
+	m := testopaquepb.TestAllTypes_builder{
+		OptionalString: proto.String("abc"),
+		OptionalBytes:  []byte("abc"),
+	}.Build()
+	b, err := proto.Marshal(m)
+	if err != nil {
+		t.Fatalf("Could not marshal healthy proto %v.", m)
+	}
+	m2 := &testopaquepb.TestAllTypes{}
+	if err := enableLazy.Unmarshal(b, m2); err != nil {
+		t.Fatalf("Could not unmarshal healthy proto buffer: %v.", b)
+	}
+	if m2.XXX_lazyUnmarshalInfo == nil {
+		// nothing to check, we don't have backing store
+		return
+	}
+	b = (*m2.XXX_lazyUnmarshalInfo).Protobuf
+	for i := 0; i < len(b); i++ {
+		b[i] = byte(0xFF)
+	}
+	if got, want := m2.GetOptionalString(), "abc"; got != want {
+		t.Errorf("optional_string referred to lazy buffer: got %q, want %q", got, want)
+	}
+	if got, want := string(m2.GetOptionalBytes()), "abc"; got != want {
+		t.Errorf("optional_bytes referred to lazy buffer: got %q, want %q", got, want)
+	}
+}
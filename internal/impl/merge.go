@@ -103,11 +103,9 @@ func (mi *MessageInfo) mergePointer(dst, src pointer, opts mergeOptions) {
 			(*dext)[num] = dx
 		}
 	}
-	if mi.unknownOffset.IsValid() {
-		su := mi.getUnknownBytes(src)
-		if su != nil && len(*su) > 0 {
-			du := mi.mutableUnknownBytes(dst)
-			*du = append(*du, *su...)
+	if mi.retainsUnknown {
+		if su := mi.unknownBytes(src); len(su) > 0 {
+			mi.appendUnknownBytes(dst, su)
 		}
 	}
 }
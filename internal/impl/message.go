@@ -36,6 +36,17 @@ type MessageInfo struct {
 	// OneofWrappers is list of pointers to oneof wrapper struct types.
 	OneofWrappers []any
 
+	// UnknownStorage, if non-nil, stores the unknown fields of messages
+	// in place of the unknown fields field of the message struct.
+	// It must be set before the message type is first used.
+	//
+	// It is not set by protoc-gen-go, which has no option to do so;
+	// it is only used by message types whose MessageInfo is constructed
+	// by other means. The unknown fields of a message which embeds a
+	// MessageState are cleared by Set when its MessageInfo is stored,
+	// which the Reset method of generated messages does after zeroing it.
+	UnknownStorage UnknownFieldStorage
+
 	initMu   sync.Mutex // protects all unexported fields
 	initDone uint32
 
@@ -43,6 +54,30 @@ type MessageInfo struct {
 	coderMessageInfo   // for fast-path method implementations
 }
 
+// UnknownFieldStorage is a storage strategy for the unknown fields of
+// messages, such as one that keeps them in segments or that discards them
+// once some size limit is reached. Each method is passed the message m
+// whose unknown fields it accesses, which is of the type that uses it.
+//
+// The fast-path unmarshal and merge implementations call Append with the
+// unknown fields that they encounter. The reflection-based implementation,
+// which is used when the fast-path methods are unavailable, calls Set with
+// all of the unknown fields of the message through
+// protoreflect.Message.SetUnknown, so a strategy must apply to both.
+type UnknownFieldStorage interface {
+	// Get returns the unknown fields of m as a sequence of
+	// wire-encoded fields. The caller must not modify the result.
+	Get(m protoreflect.ProtoMessage) protoreflect.RawFields
+
+	// Set replaces the unknown fields of m with b.
+	// It must not retain b after it returns.
+	Set(m protoreflect.ProtoMessage, b protoreflect.RawFields)
+
+	// Append adds the wire-encoded fields b to the unknown fields of m.
+	// It must not retain b after it returns.
+	Append(m protoreflect.ProtoMessage, b protoreflect.RawFields)
+}
+
 // exporter is a function that returns a reference to the ith field of v,
 // where v is a pointer to a struct. It returns nil if it does not support
 // exporting the requested field (e.g., already exported).
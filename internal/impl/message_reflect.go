@@ -113,6 +113,20 @@ func (mi *MessageInfo) makeKnownFieldsFunc(si structInfo) {
 
 func (mi *MessageInfo) makeUnknownFieldsFunc(t reflect.Type, si structInfo) {
 	switch {
+	case mi.UnknownStorage != nil:
+		// Handle with the custom storage.
+		mi.getUnknown = func(p pointer) protoreflect.RawFields {
+			if p.IsNil() {
+				return nil
+			}
+			return mi.UnknownStorage.Get(mi.protoMessageOf(p))
+		}
+		mi.setUnknown = func(p pointer, b protoreflect.RawFields) {
+			if p.IsNil() {
+				panic("invalid SetUnknown on nil Message")
+			}
+			mi.UnknownStorage.Set(mi.protoMessageOf(p), b)
+		}
 	case si.unknownOffset.IsValid() && si.unknownType == unknownFieldsAType:
 		// Handle as []byte.
 		mi.getUnknown = func(p pointer) protoreflect.RawFields {
@@ -5,6 +5,7 @@
 package impl_test
 
 import (
+	"bytes"
 	"fmt"
 	"math"
 	"reflect"
@@ -17,11 +18,14 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 
 	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/internal/flags"
 	pimpl "google.golang.org/protobuf/internal/impl"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protodesc"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/runtime/protoiface"
 	"google.golang.org/protobuf/testing/protopack"
 
 	proto2_20180125 "google.golang.org/protobuf/internal/testprotos/legacy/proto2_20180125_92554152"
@@ -1463,6 +1467,186 @@ func TestUnknownFields(t *testing.T) {
 	}
 }
 
+type UnknownFieldsC struct {
+	unknown [][]byte
+}
+
+// unknownFieldsCType retains at most 8 bytes of unknown fields,
+// stored in a segment per field.
+var unknownFieldsCType = pimpl.MessageInfo{
+	GoReflectType:  reflect.TypeOf(new(UnknownFieldsC)),
+	Desc:           mustMakeMessageDesc("unknown.proto", protoreflect.Proto2, "", `name: "UnknownFieldsC"`, nil),
+	UnknownStorage: segmentedUnknown{max: 8},
+}
+
+func (m *UnknownFieldsC) ProtoReflect() protoreflect.Message { return unknownFieldsCType.MessageOf(m) }
+
+type segmentedUnknown struct{ max int }
+
+func (s segmentedUnknown) Get(m protoreflect.ProtoMessage) protoreflect.RawFields {
+	var b []byte
+	for _, seg := range m.(*UnknownFieldsC).unknown {
+		b = append(b, seg...)
+	}
+	return b
+}
+
+func (s segmentedUnknown) Set(m protoreflect.ProtoMessage, b protoreflect.RawFields) {
+	m.(*UnknownFieldsC).unknown = nil
+	s.Append(m, b)
+}
+
+func (s segmentedUnknown) Append(m protoreflect.ProtoMessage, b protoreflect.RawFields) {
+	mc := m.(*UnknownFieldsC)
+	size := len(s.Get(m))
+	for len(b) > 0 {
+		_, _, n := protowire.ConsumeField(b)
+		if n < 0 {
+			return
+		}
+		if size+n <= s.max {
+			mc.unknown = append(mc.unknown, append([]byte(nil), b[:n]...))
+			size += n
+		}
+		b = b[n:]
+	}
+}
+
+func TestUnknownStorage(t *testing.T) {
+	in := protopack.Message{
+		protopack.Tag{1, protopack.VarintType}, protopack.Varint(1),
+		protopack.Tag{2, protopack.BytesType}, protopack.String("long string"),
+		protopack.Tag{3, protopack.Fixed32Type}, protopack.Uint32(3),
+	}.Marshal()
+	want := protopack.Message{
+		protopack.Tag{1, protopack.VarintType}, protopack.Varint(1),
+		protopack.Tag{3, protopack.Fixed32Type}, protopack.Uint32(3),
+	}.Marshal()
+
+	// The proto package uses the fast-path methods if they are available
+	// and calls SetUnknown through reflection otherwise.
+	m := new(UnknownFieldsC)
+	if err := proto.Unmarshal(in, m); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if diff := cmp.Diff(want, []byte(m.ProtoReflect().GetUnknown())); diff != "" {
+		t.Errorf("unknown fields after Unmarshal mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := len(m.unknown), 2; got != want {
+		t.Errorf("after Unmarshal, unknown fields are stored in %v segments, want %v", got, want)
+	}
+	if b, err := proto.Marshal(m); err != nil || !bytes.Equal(b, want) {
+		t.Errorf("Marshal = %x, %v; want %x, nil", b, err, want)
+	}
+
+	m = new(UnknownFieldsC)
+	m.ProtoReflect().SetUnknown(in)
+	if diff := cmp.Diff(want, []byte(m.ProtoReflect().GetUnknown())); diff != "" {
+		t.Errorf("unknown fields after SetUnknown mismatch (-want +got):\n%s", diff)
+	}
+
+	methods := m.ProtoReflect().ProtoMethods()
+	if methods == nil || flags.NoFastPath {
+		t.Skip("fast-path methods are unavailable")
+	}
+	m = new(UnknownFieldsC)
+	if _, err := methods.Unmarshal(protoiface.UnmarshalInput{
+		Message: m.ProtoReflect(),
+		Buf:     in,
+		Depth:   protowire.DefaultRecursionLimit,
+	}); err != nil {
+		t.Fatalf("fast-path Unmarshal error: %v", err)
+	}
+	if diff := cmp.Diff(want, []byte(m.ProtoReflect().GetUnknown())); diff != "" {
+		t.Errorf("unknown fields after fast-path Unmarshal mismatch (-want +got):\n%s", diff)
+	}
+
+	src := new(UnknownFieldsC)
+	src.unknown = [][]byte{in}
+	dst := new(UnknownFieldsC)
+	methods.Merge(protoiface.MergeInput{
+		Source:      src.ProtoReflect(),
+		Destination: dst.ProtoReflect(),
+	})
+	if diff := cmp.Diff(want, []byte(dst.ProtoReflect().GetUnknown())); diff != "" {
+		t.Errorf("unknown fields after fast-path Merge mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// UnknownFieldsD embeds a MessageState like generated messages do,
+// but stores its unknown fields outside of the message struct.
+type UnknownFieldsD struct {
+	state pimpl.MessageState
+}
+
+var unknownFieldsDType = pimpl.MessageInfo{
+	GoReflectType:  reflect.TypeOf(new(UnknownFieldsD)),
+	Desc:           mustMakeMessageDesc("unknown.proto", protoreflect.Proto2, "", `name: "UnknownFieldsD"`, nil),
+	UnknownStorage: externalUnknown{},
+}
+
+func (m *UnknownFieldsD) Reset() {
+	*m = UnknownFieldsD{}
+	ms := pimpl.Export{}.MessageStateOf(pimpl.Pointer(m))
+	ms.StoreMessageInfo(&unknownFieldsDType)
+}
+
+func (m *UnknownFieldsD) ProtoReflect() protoreflect.Message {
+	ms := pimpl.Export{}.MessageStateOf(pimpl.Pointer(m))
+	if ms.LoadMessageInfo() == nil {
+		ms.StoreMessageInfo(&unknownFieldsDType)
+	}
+	return ms
+}
+
+// externalUnknownFields holds the unknown fields of each UnknownFieldsD.
+var externalUnknownFields sync.Map // map[*UnknownFieldsD][]byte
+
+type externalUnknown struct{}
+
+func (externalUnknown) Get(m protoreflect.ProtoMessage) protoreflect.RawFields {
+	b, _ := externalUnknownFields.Load(m)
+	b2, _ := b.([]byte)
+	return b2
+}
+
+func (externalUnknown) Set(m protoreflect.ProtoMessage, b protoreflect.RawFields) {
+	if len(b) == 0 {
+		externalUnknownFields.Delete(m)
+		return
+	}
+	externalUnknownFields.Store(m, append([]byte(nil), b...))
+}
+
+func (s externalUnknown) Append(m protoreflect.ProtoMessage, b protoreflect.RawFields) {
+	s.Set(m, append(s.Get(m), b...))
+}
+
+func TestUnknownStorageReset(t *testing.T) {
+	in := protopack.Message{
+		protopack.Tag{1, protopack.VarintType}, protopack.Varint(1),
+	}.Marshal()
+	for _, reset := range []struct {
+		desc  string
+		reset func(proto.Message)
+	}{
+		{"Reset method", func(m proto.Message) { m.(interface{ Reset() }).Reset() }},
+		{"proto.Reset", proto.Reset},
+	} {
+		m := new(UnknownFieldsD)
+		if err := proto.Unmarshal(in, m); err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+		if diff := cmp.Diff(in, []byte(m.ProtoReflect().GetUnknown())); diff != "" {
+			t.Errorf("unknown fields after Unmarshal mismatch (-want +got):\n%s", diff)
+		}
+		reset.reset(m)
+		if got := m.ProtoReflect().GetUnknown(); len(got) != 0 {
+			t.Errorf("unknown fields after %v = %x, want none", reset.desc, got)
+		}
+	}
+}
+
 func TestReset(t *testing.T) {
 	mi := new(testpb.TestAllTypes)
 
@@ -206,6 +206,12 @@ func (ms *messageState) LoadMessageInfo() *MessageInfo {
 }
 func (ms *messageState) StoreMessageInfo(mi *MessageInfo) {
 	atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&ms.atomicMessageInfo)), unsafe.Pointer(mi))
+	if mi.UnknownStorage != nil {
+		// The Reset method of generated messages stores the message info
+		// after zeroing the message, which does not clear unknown fields
+		// kept outside of the message struct.
+		mi.UnknownStorage.Set(mi.protoMessageOf(ms.pointer()), nil)
+	}
 }
 
 type atomicNilMessage struct{ p unsafe.Pointer } // p is a *messageReflectWrapper
@@ -62,7 +62,7 @@ func (v ValidationStatus) String() string {
 // This function is exposed for testing.
 func Validate(mt protoreflect.MessageType, in protoiface.UnmarshalInput) (out protoiface.UnmarshalOutput, _ ValidationStatus) {
 	mi, ok := mt.(*MessageInfo)
-	if !ok {
+	if !ok || flags.NoFastPath {
 		return out, ValidationUnknown
 	}
 	if in.Resolver == nil {
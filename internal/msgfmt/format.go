@@ -20,13 +20,18 @@ import (
 	"google.golang.org/protobuf/internal/detrand"
 	"google.golang.org/protobuf/internal/genid"
 	"google.golang.org/protobuf/internal/order"
-	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/reflect/protoregistry"
 )
 
+// Unmarshal is used to unmarshal the contents of google.protobuf.Any messages.
+// It is set by the proto package, which cannot be imported here since it
+// depends on reflect/protopath, which in turn depends on this package.
+// If nil, the contents of Any messages are not formatted.
+var Unmarshal func(b []byte, m protoreflect.ProtoMessage) error
+
 // Format returns a formatted string for the message.
-func Format(m proto.Message) string {
+func Format(m protoreflect.ProtoMessage) string {
 	return string(appendMessage(nil, m.ProtoReflect()))
 }
 
@@ -99,19 +104,22 @@ func appendKnownMessage(b []byte, m protoreflect.Message) []byte {
 			// For protocmp.Message, directly obtain the sub-message value
 			// which is stored in structured form, rather than as raw bytes.
 			m2 := v.Convert(protocmpMessageType).Interface().(map[string]any)
-			v, ok := m2[string(genid.Any_Value_field_name)].(proto.Message)
+			v, ok := m2[string(genid.Any_Value_field_name)].(protoreflect.ProtoMessage)
 			if !ok {
 				return nil
 			}
 			msgVal = v.ProtoReflect()
 		} else {
+			if Unmarshal == nil {
+				return nil
+			}
 			val := m.Get(fds.ByNumber(genid.Any_Value_field_number)).Bytes()
 			mt, err := protoregistry.GlobalTypes.FindMessageByURL(url)
 			if err != nil {
 				return nil
 			}
 			msgVal = mt.New()
-			err = proto.UnmarshalOptions{AllowPartial: true}.Unmarshal(val, msgVal.Interface())
+			err = Unmarshal(val, msgVal.Interface())
 			if err != nil {
 				return nil
 			}
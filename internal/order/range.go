@@ -6,7 +6,7 @@
 package order
 
 import (
-	"sort"
+	"slices"
 	"sync"
 
 	"google.golang.org/protobuf/reflect/protoreflect"
@@ -51,8 +51,8 @@ func RangeFields(fs FieldRanger, less FieldOrder, fn VisitField) {
 		fields = append(fields, messageField{fd, v})
 		return true
 	})
-	sort.Slice(fields, func(i, j int) bool {
-		return less(fields[i].fd, fields[j].fd)
+	slices.SortFunc(fields, func(x, y messageField) int {
+		return compare(less(x.fd, y.fd), less(y.fd, x.fd))
 	})
 
 	// Visit the fields in the specified ordering.
@@ -102,8 +102,8 @@ func RangeEntries(es EntryRanger, less KeyOrder, fn VisitEntry) {
 		entries = append(entries, mapEntry{k, v})
 		return true
 	})
-	sort.Slice(entries, func(i, j int) bool {
-		return less(entries[i].k, entries[j].k)
+	slices.SortFunc(entries, func(x, y mapEntry) int {
+		return compare(less(x.k, y.k), less(y.k, x.k))
 	})
 
 	// Visit the entries in the specified ordering.
@@ -113,3 +113,16 @@ func RangeEntries(es EntryRanger, less KeyOrder, fn VisitEntry) {
 		}
 	}
 }
+
+// compare converts the results of less(x, y) and less(y, x)
+// into a three-way comparison result.
+func compare(xLess, yLess bool) int {
+	switch {
+	case xLess:
+		return -1
+	case yLess:
+		return +1
+	default:
+		return 0
+	}
+}
@@ -11,8 +11,9 @@
 // as the race error got propagated from the subprocess and failed the test case in the parent process.
 // Instead we create the subprocess where the test is supposed to fail by ourselves.
 
-// Lazy decoding is only available in the fast path, which the protoreflect tag disables.
-//go:build !protoreflect
+// Lazy decoding is only available in the fast path,
+// which the protoreflect and protonofastpath tags disable.
+//go:build !protoreflect && !protonofastpath
 
 package lazy_race_test
 
@@ -0,0 +1,47 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package rawfields provides functionality for sequences of encoded fields,
+// such as the unknown fields of a message.
+package rawfields
+
+import (
+	"bytes"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Equal compares unknown fields by direct comparison on the raw bytes
+// of each individual field number.
+func Equal(x, y []byte) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	if bytes.Equal(x, y) {
+		return true
+	}
+
+	mx := make(map[protowire.Number][]byte)
+	my := make(map[protowire.Number][]byte)
+	for len(x) > 0 {
+		fnum, _, n := protowire.ConsumeField(x)
+		mx[fnum] = append(mx[fnum], x[:n]...)
+		x = x[n:]
+	}
+	for len(y) > 0 {
+		fnum, _, n := protowire.ConsumeField(y)
+		my[fnum] = append(my[fnum], y[:n]...)
+		y = y[n:]
+	}
+	if len(mx) != len(my) {
+		return false
+	}
+
+	for k, v1 := range mx {
+		if v2, ok := my[k]; !ok || !bytes.Equal(v1, v2) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,184 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: internal/testprotos/explicitregistration/ext.proto
+
+package explicitregistration
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoregistry "google.golang.org/protobuf/reflect/protoregistry"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Holder struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Base          *Base                  `protobuf:"bytes,1,opt,name=base" json:"base,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Holder) Reset() {
+	*x = Holder{}
+	mi := &file_internal_testprotos_explicitregistration_ext_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Holder) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Holder) ProtoMessage() {}
+
+func (x *Holder) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_testprotos_explicitregistration_ext_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Holder.ProtoReflect.Descriptor instead.
+func (*Holder) Descriptor() ([]byte, []int) {
+	return file_internal_testprotos_explicitregistration_ext_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Holder) GetBase() *Base {
+	if x != nil {
+		return x.Base
+	}
+	return nil
+}
+
+var file_internal_testprotos_explicitregistration_ext_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*Base)(nil),
+		ExtensionType: (*Holder)(nil),
+		Field:         100,
+		Name:          "goproto.proto.explicitregistration.holder",
+		Tag:           "bytes,100,opt,name=holder",
+		Filename:      "internal/testprotos/explicitregistration/ext.proto",
+	},
+}
+
+// Extension fields to Base.
+var (
+	// optional goproto.proto.explicitregistration.Holder holder = 100;
+	E_Holder = &file_internal_testprotos_explicitregistration_ext_proto_extTypes[0]
+)
+
+var File_internal_testprotos_explicitregistration_ext_proto protoreflect.FileDescriptor
+
+var file_internal_testprotos_explicitregistration_ext_proto_rawDesc = string([]byte{
+	0x0a, 0x32, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x74, 0x65, 0x73, 0x74, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x73, 0x2f, 0x65, 0x78, 0x70, 0x6c, 0x69, 0x63, 0x69, 0x74, 0x72, 0x65,
+	0x67, 0x69, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x65, 0x78, 0x74, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x22, 0x67, 0x6f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x2e, 0x65, 0x78, 0x70, 0x6c, 0x69, 0x63, 0x69, 0x74, 0x72, 0x65, 0x67, 0x69,
+	0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x33, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e,
+	0x61, 0x6c, 0x2f, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x2f, 0x65, 0x78,
+	0x70, 0x6c, 0x69, 0x63, 0x69, 0x74, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x2f, 0x62, 0x61, 0x73, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x46, 0x0a,
+	0x06, 0x48, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x12, 0x3c, 0x0a, 0x04, 0x62, 0x61, 0x73, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x67, 0x6f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x65, 0x78, 0x70, 0x6c, 0x69, 0x63, 0x69, 0x74, 0x72, 0x65,
+	0x67, 0x69, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x42, 0x61, 0x73, 0x65, 0x52,
+	0x04, 0x62, 0x61, 0x73, 0x65, 0x3a, 0x6c, 0x0a, 0x06, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x12,
+	0x28, 0x2e, 0x67, 0x6f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x65, 0x78, 0x70, 0x6c, 0x69, 0x63, 0x69, 0x74, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x42, 0x61, 0x73, 0x65, 0x18, 0x64, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x2a, 0x2e, 0x67, 0x6f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x65, 0x78, 0x70, 0x6c, 0x69, 0x63, 0x69, 0x74, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x48, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x52, 0x06, 0x68, 0x6f, 0x6c,
+	0x64, 0x65, 0x72, 0x42, 0x45, 0x5a, 0x43, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x67, 0x6f,
+	0x6c, 0x61, 0x6e, 0x67, 0x2e, 0x6f, 0x72, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x74, 0x65, 0x73, 0x74, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x73, 0x2f, 0x65, 0x78, 0x70, 0x6c, 0x69, 0x63, 0x69, 0x74, 0x72, 0x65,
+	0x67, 0x69, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x62, 0x08, 0x65, 0x64, 0x69, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x70, 0xe8, 0x07,
+})
+
+var (
+	file_internal_testprotos_explicitregistration_ext_proto_rawDescOnce sync.Once
+	file_internal_testprotos_explicitregistration_ext_proto_rawDescData []byte
+)
+
+func file_internal_testprotos_explicitregistration_ext_proto_rawDescGZIP() []byte {
+	file_internal_testprotos_explicitregistration_ext_proto_rawDescOnce.Do(func() {
+		file_internal_testprotos_explicitregistration_ext_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_internal_testprotos_explicitregistration_ext_proto_rawDesc), len(file_internal_testprotos_explicitregistration_ext_proto_rawDesc)))
+	})
+	return file_internal_testprotos_explicitregistration_ext_proto_rawDescData
+}
+
+var file_internal_testprotos_explicitregistration_ext_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_internal_testprotos_explicitregistration_ext_proto_goTypes = []any{
+	(*Holder)(nil), // 0: goproto.proto.explicitregistration.Holder
+	(*Base)(nil),   // 1: goproto.proto.explicitregistration.Base
+}
+var file_internal_testprotos_explicitregistration_ext_proto_depIdxs = []int32{
+	1, // 0: goproto.proto.explicitregistration.Holder.base:type_name -> goproto.proto.explicitregistration.Base
+	1, // 1: goproto.proto.explicitregistration.holder:extendee -> goproto.proto.explicitregistration.Base
+	0, // 2: goproto.proto.explicitregistration.holder:type_name -> goproto.proto.explicitregistration.Holder
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	2, // [2:3] is the sub-list for extension type_name
+	1, // [1:2] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_internal_testprotos_explicitregistration_ext_proto_init() }
+
+var file_internal_testprotos_explicitregistration_ext_proto_registry protoimpl.DeferredRegistry
+
+// Register_internal_testprotos_explicitregistration_ext_proto registers the descriptor of internal/testprotos/explicitregistration/ext.proto in files
+// and the types that it declares in types. Either registry may be nil.
+// The files that it imports are not registered; they are resolved
+// from files when the descriptor is first used.
+func Register_internal_testprotos_explicitregistration_ext_proto(files *protoregistry.Files, types *protoregistry.Types) error {
+	file_internal_testprotos_explicitregistration_ext_proto_init()
+	return file_internal_testprotos_explicitregistration_ext_proto_registry.Register(files, types)
+}
+
+func file_internal_testprotos_explicitregistration_ext_proto_init() {
+	if File_internal_testprotos_explicitregistration_ext_proto != nil {
+		return
+	}
+	file_internal_testprotos_explicitregistration_base_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_testprotos_explicitregistration_ext_proto_rawDesc), len(file_internal_testprotos_explicitregistration_ext_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 1,
+			NumServices:   0,
+			FileRegistry:  &file_internal_testprotos_explicitregistration_ext_proto_registry,
+		},
+		GoTypes:           file_internal_testprotos_explicitregistration_ext_proto_goTypes,
+		DependencyIndexes: file_internal_testprotos_explicitregistration_ext_proto_depIdxs,
+		MessageInfos:      file_internal_testprotos_explicitregistration_ext_proto_msgTypes,
+		ExtensionInfos:    file_internal_testprotos_explicitregistration_ext_proto_extTypes,
+		TypeRegistry:      &file_internal_testprotos_explicitregistration_ext_proto_registry,
+	}.Build()
+	File_internal_testprotos_explicitregistration_ext_proto = out.File
+	file_internal_testprotos_explicitregistration_ext_proto_goTypes = nil
+	file_internal_testprotos_explicitregistration_ext_proto_depIdxs = nil
+}
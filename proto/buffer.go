@@ -0,0 +1,62 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package proto
+
+import (
+	"google.golang.org/protobuf/internal/pragma"
+)
+
+// maxRetainedBufferSize is the largest buffer capacity that Buffer.Reset
+// will retain for reuse. Larger buffers are released to the garbage collector
+// so that a single unusually large message does not pin memory indefinitely.
+const maxRetainedBufferSize = 1 << 20 // 1 MiB
+
+// Buffer is a reusable scratch buffer for marshaling messages.
+//
+// Each call to [Buffer.Marshal] reuses the memory of the previous call,
+// so that marshaling many messages in sequence does not allocate once the
+// buffer has grown large enough to hold the largest message.
+// Buffers may be stored in a [sync.Pool] to share them across goroutines.
+//
+// The zero value is ready to use. A Buffer must not be used concurrently
+// from multiple goroutines.
+type Buffer struct {
+	_ pragma.DoNotCopy
+
+	// Options configures how messages are marshaled.
+	Options MarshalOptions
+
+	buf []byte
+}
+
+// Marshal returns the wire-format encoding of m.
+//
+// The returned slice aliases the memory of the buffer and is only valid
+// until the next call to Marshal or Reset.
+func (b *Buffer) Marshal(m Message) ([]byte, error) {
+	var err error
+	b.buf, err = b.Options.MarshalAppend(b.buf[:0], m)
+	return b.buf, err
+}
+
+// Bytes returns the result of the most recent call to [Buffer.Marshal].
+//
+// The returned slice aliases the memory of the buffer and is only valid
+// until the next call to Marshal or Reset.
+func (b *Buffer) Bytes() []byte {
+	return b.buf
+}
+
+// Reset discards the contents of the buffer.
+//
+// The underlying memory is retained for reuse unless it has grown
+// unusually large, in which case it is released.
+func (b *Buffer) Reset() {
+	if cap(b.buf) > maxRetainedBufferSize {
+		b.buf = nil
+		return
+	}
+	b.buf = b.buf[:0]
+}
@@ -0,0 +1,89 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package proto_test
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	test3pb "google.golang.org/protobuf/internal/testprotos/test3"
+)
+
+func TestBuffer(t *testing.T) {
+	var buf proto.Buffer
+	for _, m := range []*test3pb.TestAllTypes{
+		{SingularString: "hello, world"},
+		{SingularInt32: 1},
+		{RepeatedInt32: []int32{1, 2, 3, 4, 5}},
+	} {
+		got, err := buf.Marshal(m)
+		if err != nil {
+			t.Fatalf("Buffer.Marshal(%v) error: %v", m, err)
+		}
+		want, err := proto.Marshal(m)
+		if err != nil {
+			t.Fatalf("Marshal(%v) error: %v", m, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("Buffer.Marshal(%v) = %x, want %x", m, got, want)
+		}
+		if !bytes.Equal(buf.Bytes(), got) {
+			t.Errorf("Buffer.Bytes() = %x, want %x", buf.Bytes(), got)
+		}
+	}
+
+	buf.Reset()
+	if got := buf.Bytes(); len(got) != 0 {
+		t.Errorf("after Reset, Buffer.Bytes() = %x, want empty", got)
+	}
+}
+
+func TestBufferAllocations(t *testing.T) {
+	// This test ensures that once a Buffer has grown large enough,
+	// it performs no more allocations than marshaling into a preallocated slice.
+	m := &test3pb.TestAllTypes{SingularString: "hello, world"}
+	const count = 1000
+	b := make([]byte, proto.Size(m))
+	marshalAllocs := testing.AllocsPerRun(count, func() {
+		if _, err := (proto.MarshalOptions{}).MarshalAppend(b[:0], m); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var buf proto.Buffer
+	bufferAllocs := testing.AllocsPerRun(count, func() {
+		if _, err := buf.Marshal(m); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if marshalAllocs != bufferAllocs {
+		t.Errorf("%v allocs/op when writing to a preallocated buffer", marshalAllocs)
+		t.Errorf("%v allocs/op when reusing a Buffer", bufferAllocs)
+		t.Errorf("expect amortized allocs/op to be identical")
+	}
+}
+
+// This example illustrates how to share marshal buffers between goroutines
+// using a [sync.Pool], reducing allocations in servers that marshal many
+// messages concurrently.
+func ExampleBuffer() {
+	pool := sync.Pool{
+		New: func() any { return new(proto.Buffer) },
+	}
+
+	var m proto.Message
+
+	buf := pool.Get().(*proto.Buffer)
+	b, err := buf.Marshal(m)
+	if err != nil {
+		panic(err)
+	}
+	_ = b // write b to disk, network, etc.
+
+	buf.Reset()
+	pool.Put(buf)
+}
@@ -5,7 +5,12 @@
 package proto
 
 import (
+	"strings"
+
 	"google.golang.org/protobuf/internal/errors"
+	"google.golang.org/protobuf/internal/order"
+	"google.golang.org/protobuf/internal/pragma"
+	"google.golang.org/protobuf/reflect/protopath"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/runtime/protoiface"
 )
@@ -21,51 +26,163 @@ func CheckInitialized(m Message) error {
 	return checkInitialized(m.ProtoReflect())
 }
 
+// CheckInitializedOptions configures the check for missing required fields.
+type CheckInitializedOptions struct {
+	pragma.NoUnkeyedLiterals
+
+	// AllMissing reports every required field which is not set,
+	// rather than only the first one found.
+	// The error returned is then a [*MissingFieldsError], unless the
+	// missing fields cannot be located, in which case the error is the
+	// same as that reported by [CheckInitialized].
+	AllMissing bool
+}
+
 // CheckInitialized returns an error if any required fields in m are not set.
-func checkInitialized(m protoreflect.Message) error {
-	if methods := protoMethods(m); methods != nil && methods.CheckInitialized != nil {
-		_, err := methods.CheckInitialized(protoiface.CheckInitializedInput{
-			Message: m,
-		})
+func (o CheckInitializedOptions) CheckInitialized(m Message) error {
+	err := CheckInitialized(m)
+	if err == nil || !o.AllMissing {
 		return err
 	}
-	return checkInitializedSlow(m)
+	mr := m.ProtoReflect()
+	r := missingFields{path: protopath.Path{protopath.Root(mr.Descriptor())}}
+	r.checkMessage(mr)
+	if len(r.paths) == 0 {
+		// The message reported an error through its own methods
+		// which cannot be traced to a field.
+		return err
+	}
+	return &MissingFieldsError{Paths: r.paths}
 }
 
-func checkInitializedSlow(m protoreflect.Message) error {
+// MissingFieldsError is the error returned by
+// [CheckInitializedOptions.CheckInitialized] when AllMissing is set.
+// It matches [Error] according to [errors.Is].
+type MissingFieldsError struct {
+	// Paths are the paths to the missing required fields from the
+	// checked message, each starting with a [protopath.Root] step.
+	// For each message, its own missing fields are listed first,
+	// followed by those in its fields in order of field number.
+	Paths []protopath.Path
+}
+
+func (e *MissingFieldsError) Error() string {
+	if len(e.Paths) == 1 {
+		return errors.New("required field %v not set", e.Paths[0]).Error()
+	}
+	names := make([]string, len(e.Paths))
+	for i, p := range e.Paths {
+		names[i] = p.String()
+	}
+	return errors.New("required fields %v not set", strings.Join(names, ", ")).Error()
+}
+
+func (e *MissingFieldsError) Unwrap() error {
+	return errors.Error
+}
+
+type missingFields struct {
+	path  protopath.Path
+	paths []protopath.Path
+}
+
+func (r *missingFields) checkMessage(m protoreflect.Message) {
 	md := m.Descriptor()
 	fds := md.Fields()
 	for i, nums := 0, md.RequiredNumbers(); i < nums.Len(); i++ {
 		fd := fds.ByNumber(nums.Get(i))
 		if !m.Has(fd) {
-			return errors.RequiredNotSet(string(fd.FullName()))
+			p := append(protopath.Path(nil), r.path...)
+			r.paths = append(r.paths, append(p, protopath.FieldAccess(fd)))
 		}
 	}
-	var err error
-	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+	order.RangeFields(m, order.NumberFieldOrder, func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
 		switch {
 		case fd.IsList():
 			if fd.Message() == nil {
 				return true
 			}
-			for i, list := 0, v.List(); i < list.Len() && err == nil; i++ {
-				err = checkInitialized(list.Get(i).Message())
+			for i, list := 0, v.List(); i < list.Len(); i++ {
+				r.checkChild(list.Get(i).Message(), protopath.FieldAccess(fd), protopath.ListIndex(i))
 			}
 		case fd.IsMap():
 			if fd.MapValue().Message() == nil {
 				return true
 			}
-			v.Map().Range(func(key protoreflect.MapKey, v protoreflect.Value) bool {
-				err = checkInitialized(v.Message())
-				return err == nil
-			})
-		default:
-			if fd.Message() == nil {
+			order.RangeEntries(v.Map(), order.GenericKeyOrder, func(k protoreflect.MapKey, v protoreflect.Value) bool {
+				r.checkChild(v.Message(), protopath.FieldAccess(fd), protopath.MapIndex(k))
 				return true
-			}
-			err = checkInitialized(v.Message())
+			})
+		case fd.Message() != nil:
+			r.checkChild(v.Message(), protopath.FieldAccess(fd))
+		}
+		return true
+	})
+}
+
+func (r *missingFields) checkChild(m protoreflect.Message, steps ...protopath.Step) {
+	n := len(r.path)
+	r.path = append(r.path, steps...)
+	r.checkMessage(m)
+	r.path = r.path[:n]
+}
+
+// CheckInitialized returns an error if any required fields in m are not set.
+func checkInitialized(m protoreflect.Message) error {
+	if methods := protoMethods(m); methods != nil && methods.CheckInitialized != nil {
+		_, err := methods.CheckInitialized(protoiface.CheckInitializedInput{
+			Message: m,
+		})
+		return err
+	}
+	return checkInitializedSlow(m)
+}
+
+func checkInitializedSlow(m protoreflect.Message) error {
+	md := m.Descriptor()
+	fds := md.Fields()
+	for i, nums := 0, md.RequiredNumbers(); i < nums.Len(); i++ {
+		fd := fds.ByNumber(nums.Get(i))
+		if !m.Has(fd) {
+			return errors.RequiredNotSet(string(fd.FullName()))
 		}
+	}
+	var err error
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		err = checkFieldInitialized(fd, v)
 		return err == nil
 	})
 	return err
 }
+
+// checkFieldInitialized returns an error if any required fields are not set
+// in the message values of the field fd with value v.
+func checkFieldInitialized(fd protoreflect.FieldDescriptor, v protoreflect.Value) error {
+	switch {
+	case fd.IsList():
+		if fd.Message() == nil {
+			return nil
+		}
+		for i, list := 0, v.List(); i < list.Len(); i++ {
+			if err := checkInitialized(list.Get(i).Message()); err != nil {
+				return err
+			}
+		}
+		return nil
+	case fd.IsMap():
+		if fd.MapValue().Message() == nil {
+			return nil
+		}
+		var err error
+		v.Map().Range(func(key protoreflect.MapKey, v protoreflect.Value) bool {
+			err = checkInitialized(v.Message())
+			return err == nil
+		})
+		return err
+	default:
+		if fd.Message() == nil {
+			return nil
+		}
+		return checkInitialized(v.Message())
+	}
+}
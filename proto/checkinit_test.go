@@ -5,7 +5,9 @@
 package proto_test
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -87,3 +89,50 @@ func TestCheckInitializedErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckInitializedAllMissing(t *testing.T) {
+	m := &testpb.TestRequiredForeign{
+		OptionalMessage: &testpb.TestRequired{},
+		RepeatedMessage: []*testpb.TestRequired{
+			{RequiredField: proto.Int32(1)},
+			{},
+		},
+		MapMessage: map[int32]*testpb.TestRequired{
+			2: {},
+			1: {},
+			3: {RequiredField: proto.Int32(1)},
+		},
+	}
+	opts := proto.CheckInitializedOptions{AllMissing: true}
+	err := opts.CheckInitialized(m)
+	var missing *proto.MissingFieldsError
+	if !errors.As(err, &missing) {
+		t.Fatalf("CheckInitialized() = %v, want *MissingFieldsError", err)
+	}
+	var got []string
+	for _, p := range missing.Paths {
+		got = append(got, p.String())
+	}
+	want := []string{
+		"(goproto.proto.test.TestRequiredForeign).optional_message.required_field",
+		"(goproto.proto.test.TestRequiredForeign).repeated_message[1].required_field",
+		"(goproto.proto.test.TestRequiredForeign).map_message[1].required_field",
+		"(goproto.proto.test.TestRequiredForeign).map_message[2].required_field",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MissingFieldsError.Paths = %q, want %q", got, want)
+	}
+	if !errors.Is(err, proto.Error) {
+		t.Errorf("errors.Is(%v, proto.Error) = false, want true", err)
+	}
+	if !strings.Contains(err.Error(), strings.Join(want, ", ")) {
+		t.Errorf("error %q does not list all missing fields", err)
+	}
+
+	if err := opts.CheckInitialized(&testpb.TestRequiredForeign{}); err != nil {
+		t.Errorf("CheckInitialized(initialized message) = %v, want nil", err)
+	}
+	if err := (proto.CheckInitializedOptions{}).CheckInitialized(m); errors.As(err, &missing) || err == nil {
+		t.Errorf("CheckInitialized() without AllMissing = %v, want single missing field error", err)
+	}
+}
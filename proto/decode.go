@@ -20,6 +20,9 @@ import (
 // Example usage:
 //
 //	err := UnmarshalOptions{DiscardUnknown: true}.Unmarshal(b, m)
+//
+// Setting InvalidUTF8 to any policy other than UTF8Strict disables the
+// optimized fast-path unmarshaler and may substantially reduce performance.
 type UnmarshalOptions struct {
 	pragma.NoUnkeyedLiterals
 
@@ -47,11 +50,84 @@ type UnmarshalOptions struct {
 	// If zero, a default limit is applied.
 	RecursionLimit int
 
-	//
+	// MaxSize, if positive, is the maximum size in bytes of the wire-format
+	// input. Larger inputs are rejected before any decoding takes place.
+	MaxSize int
+
+	// MaxFieldSize, if positive, is the maximum size in bytes of any
+	// individual bytes or string field value, including length-delimited
+	// unknown fields. The entire input is validated against this limit
+	// before any decoding takes place, so that untrusted input cannot force
+	// large allocations for fields that will later be rejected.
+	MaxFieldSize int
+
+	// ErrorOnUnknownEnumValue reports an error if a field of a closed enum type
+	// holds a value that is not declared by the enum. Such values are
+	// otherwise stored in the field as is, regardless of DiscardUnknown,
+	// since the Go implementation treats all enums as open.
+	ErrorOnUnknownEnumValue bool
+
+	// Fields, if non-nil, restricts decoding to the top-level fields with
+	// the listed field numbers. All other fields are skipped without being
+	// decoded and are stored as unknown fields, unless DiscardUnknown is set.
+	// Required fields that are not selected are reported as missing
+	// unless AllowPartial is set.
+	Fields []protoreflect.FieldNumber
+
 	// NoLazyDecoding turns off lazy decoding, which otherwise is enabled by
 	// default. Lazy decoding only affects submessages (annotated with [lazy =
 	// true] in the .proto file) within messages that use the Opaque API.
+	// The wire-format bytes of such submessages are retained as is and only
+	// decoded when the field is first accessed, either through the generated
+	// accessor methods or through protoreflect.
 	NoLazyDecoding bool
+
+	// Validator, if non-nil, is called with the top-level message after
+	// it has been successfully unmarshaled and, unless AllowPartial is set,
+	// checked for missing required fields. If the validator returns an error,
+	// Unmarshal returns it as is,
+	// and the message is left in its unmarshaled state.
+	// This permits constraint checks to be applied as part of unmarshaling
+	// requests, without a separate step at every call site.
+	Validator Validator
+
+	// InvalidUTF8 specifies how string fields that are required to contain
+	// valid UTF-8 but do not are parsed. By default, an error is reported.
+	// See the performance note on UnmarshalOptions.
+	InvalidUTF8 UTF8Policy
+
+	// AliasBuffer permits the unmarshaler to store string and bytes values
+	// which reference the input buffer rather than copies of it. This avoids
+	// copying when decoding from a buffer which outlives the message,
+	// such as a memory-mapped file.
+	//
+	// If AliasBuffer is set, the caller must not modify the input buffer
+	// for as long as the message or any of the values read from it are in
+	// use, since doing so changes those values. This includes string values,
+	// which are otherwise immutable, and map keys, whose maps may be
+	// corrupted as a result. The bytes values of the message must
+	// likewise be treated as read-only, as must the input buffer if it is
+	// mapped read-only. Aliased values retain the entire input buffer,
+	// which is not garbage collected until no values reference it.
+	//
+	// AliasBuffer is ignored by messages which do not support the optimized fast-path
+	// unmarshaler, which always copy.
+	AliasBuffer bool
+}
+
+// Validator validates the contents of an unmarshaled message.
+// See [UnmarshalOptions.Validator].
+type Validator interface {
+	Validate(Message) error
+}
+
+// ValidatorFunc is an adapter to allow the use of an ordinary function
+// as a [Validator].
+type ValidatorFunc func(Message) error
+
+// Validate returns f(m).
+func (f ValidatorFunc) Validate(m Message) error {
+	return f(m)
 }
 
 // Unmarshal parses the wire-format message in b and places the result in m.
@@ -59,29 +135,82 @@ type UnmarshalOptions struct {
 //
 // See the [UnmarshalOptions] type if you need more control.
 func Unmarshal(b []byte, m Message) error {
-	_, err := UnmarshalOptions{RecursionLimit: protowire.DefaultRecursionLimit}.unmarshal(b, m.ProtoReflect())
-	return err
+	o := UnmarshalOptions{RecursionLimit: protowire.DefaultRecursionLimit}
+	if _, err := o.unmarshal(b, m.ProtoReflect()); err != nil {
+		return o.decodeError(b, m.ProtoReflect().Descriptor(), err)
+	}
+	return nil
 }
 
 // Unmarshal parses the wire-format message in b and places the result in m.
 // The provided message must be mutable (e.g., a non-nil pointer to a message).
 func (o UnmarshalOptions) Unmarshal(b []byte, m Message) error {
-	if o.RecursionLimit == 0 {
-		o.RecursionLimit = protowire.DefaultRecursionLimit
+	_, err := o.unmarshalTop(b, m.ProtoReflect())
+	if err != nil {
+		return o.decodeError(b, m.ProtoReflect().Descriptor(), err)
 	}
-	_, err := o.unmarshal(b, m.ProtoReflect())
-	return err
+	return nil
 }
 
 // UnmarshalState parses a wire-format message and places the result in m.
 //
 // This method permits fine-grained control over the unmarshaler.
 // Most users should use [Unmarshal] instead.
+//
+// The input flags, resolver, and depth are combined with the options:
+// the UnmarshalDiscardUnknown, UnmarshalNoLazyDecoding, and
+// UnmarshalAliasBuffer flags enable the corresponding options, while in.Resolver and in.Depth are used
+// in place of Resolver and RecursionLimit only if those options are unset.
+// This permits callers to reuse a single UnmarshalOptions value for
+// every call, while varying the input per call.
 func (o UnmarshalOptions) UnmarshalState(in protoiface.UnmarshalInput) (protoiface.UnmarshalOutput, error) {
+	if in.Flags&protoiface.UnmarshalDiscardUnknown != 0 {
+		o.DiscardUnknown = true
+	}
+	if in.Flags&protoiface.UnmarshalNoLazyDecoding != 0 {
+		o.NoLazyDecoding = true
+	}
+	if in.Flags&protoiface.UnmarshalAliasBuffer != 0 {
+		o.AliasBuffer = true
+	}
+	if o.Resolver == nil && in.Resolver != nil {
+		o.Resolver = in.Resolver
+	}
+	if o.RecursionLimit == 0 && in.Depth > 0 {
+		o.RecursionLimit = in.Depth
+	}
+	return o.unmarshalTop(in.Buf, in.Message)
+}
+
+// unmarshalTop unmarshals the top-level message, applying the options
+// which only concern the input as a whole.
+func (o UnmarshalOptions) unmarshalTop(b []byte, m protoreflect.Message) (out protoiface.UnmarshalOutput, err error) {
 	if o.RecursionLimit == 0 {
 		o.RecursionLimit = protowire.DefaultRecursionLimit
 	}
-	return o.unmarshal(in.Buf, in.Message)
+	if err := o.checkSizeLimits(b, m.Descriptor()); err != nil {
+		return out, err
+	}
+	var skipped []byte
+	if o.Fields != nil {
+		b, skipped = selectFields(b, o.Fields)
+	}
+	out, err = o.unmarshal(b, m)
+	if err != nil {
+		return out, err
+	}
+	if len(skipped) > 0 && !o.DiscardUnknown {
+		m.SetUnknown(append(m.GetUnknown(), skipped...))
+	}
+	if o.ErrorOnUnknownEnumValue {
+		if err = checkEnumValues(m); err != nil {
+			return out, err
+		}
+	}
+	if o.Validator != nil {
+		err = o.Validator.Validate(m.Interface())
+	}
+	return out, err
 }
 
 // unmarshal is a centralized function that all unmarshal operations go through.
@@ -98,7 +227,7 @@ func (o UnmarshalOptions) unmarshal(b []byte, m protoreflect.Message) (out proto
 	o.Merge = true
 	o.AllowPartial = true
 	methods := protoMethods(m)
-	if methods != nil && methods.Unmarshal != nil &&
+	if methods != nil && methods.Unmarshal != nil && o.InvalidUTF8 == UTF8Strict &&
 		!(o.DiscardUnknown && methods.Flags&protoiface.SupportUnmarshalDiscardUnknown == 0) {
 		in := protoiface.UnmarshalInput{
 			Message:  m,
@@ -118,6 +247,9 @@ func (o UnmarshalOptions) unmarshal(b []byte, m protoreflect.Message) (out proto
 		if o.NoLazyDecoding {
 			in.Flags |= protoiface.UnmarshalNoLazyDecoding
 		}
+		if o.AliasBuffer {
+			in.Flags |= protoiface.UnmarshalAliasBuffer
+		}
 
 		out, err = methods.Unmarshal(in)
 	} else {
@@ -0,0 +1,47 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package proto
+
+import (
+	"google.golang.org/protobuf/internal/errors"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// checkEnumValues reports an error if any closed enum field in m,
+// or in any message reachable from m, holds an undeclared value.
+func checkEnumValues(m protoreflect.Message) (err error) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsList():
+			list := v.List()
+			for i := 0; i < list.Len() && err == nil; i++ {
+				err = checkEnumValue(fd, list.Get(i))
+			}
+		case fd.IsMap():
+			vd := fd.MapValue()
+			v.Map().Range(func(_ protoreflect.MapKey, v protoreflect.Value) bool {
+				err = checkEnumValue(vd, v)
+				return err == nil
+			})
+		default:
+			err = checkEnumValue(fd, v)
+		}
+		return err == nil
+	})
+	return err
+}
+
+func checkEnumValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) error {
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		ed := fd.Enum()
+		if n := v.Enum(); ed.IsClosed() && ed.Values().ByNumber(n) == nil {
+			return errors.New("%v: unknown value %v for closed enum %v", fd.FullName(), n, ed.FullName())
+		}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return checkEnumValues(v.Message())
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package proto
+
+import (
+	"io"
+	"strconv"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/internal/encoding/messageset"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// DecodeErrorKind is the kind of problem reported by a [DecodeError].
+type DecodeErrorKind int
+
+const (
+	// DecodeTruncated indicates that the input ended in the middle of a
+	// field, or that a length prefix exceeds the remaining input.
+	DecodeTruncated DecodeErrorKind = iota + 1
+	// DecodeOverflow indicates that a varint does not fit in 64 bits.
+	DecodeOverflow
+	// DecodeInvalidFieldNumber indicates that a tag has a field number
+	// outside the valid range.
+	DecodeInvalidFieldNumber
+	// DecodeInvalidWireType indicates that a tag has a reserved wire type,
+	// or that a group is not terminated by a matching end group tag.
+	DecodeInvalidWireType
+	// DecodeInvalidUTF8 indicates that a string field which requires
+	// valid UTF-8 contains invalid UTF-8.
+	DecodeInvalidUTF8
+	// DecodeRecursionLimit indicates that messages are nested more deeply
+	// than permitted by [UnmarshalOptions.RecursionLimit].
+	DecodeRecursionLimit
+)
+
+func (k DecodeErrorKind) String() string {
+	switch k {
+	case DecodeTruncated:
+		return "truncated"
+	case DecodeOverflow:
+		return "varint overflow"
+	case DecodeInvalidFieldNumber:
+		return "invalid field number"
+	case DecodeInvalidWireType:
+		return "invalid wire type"
+	case DecodeInvalidUTF8:
+		return "invalid UTF-8"
+	case DecodeRecursionLimit:
+		return "recursion limit"
+	default:
+		return "DecodeErrorKind(" + strconv.Itoa(int(k)) + ")"
+	}
+}
+
+// DecodeError describes the location and kind of malformed wire data.
+// Errors returned by [Unmarshal] and [UnmarshalOptions.Unmarshal] for
+// malformed input can be inspected with [errors.As]:
+//
+//	var derr *proto.DecodeError
+//	if errors.As(err, &derr) {
+//		log.Printf("bad field %v at offset %d", derr.FullName, derr.Offset)
+//	}
+//
+// A DecodeError wraps the error originally reported by the unmarshaler,
+// and thus matches [Error] according to [errors.Is].
+type DecodeError struct {
+	Kind DecodeErrorKind
+
+	// Offset is the offset in bytes from the start of the input
+	// of the tag or value in which the problem was found.
+	Offset int
+
+	// FieldNumber is the number of the field in which the problem was found,
+	// or zero if the problem is in a tag.
+	FieldNumber protowire.Number
+
+	// FullName is the full name of the field in which the problem was found,
+	// if the field is known; otherwise it is empty.
+	FullName protoreflect.FullName
+
+	// Err is the error originally reported by the unmarshaler.
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	s := e.Err.Error() + " (" + e.Kind.String() + " at offset " + strconv.Itoa(e.Offset)
+	switch {
+	case e.FullName != "":
+		s += " in field " + string(e.FullName)
+	case e.FieldNumber != 0:
+		s += " in field " + strconv.Itoa(int(e.FieldNumber))
+	}
+	return s + ")"
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// decodeError returns a *DecodeError describing the first problem in b,
+// which was reported by unmarshaling b into a message of type md as err.
+// If no problem with the wire data is found, err is returned as is,
+// since it may instead concern missing required fields or resolution
+// of extensions.
+//
+// This only runs after unmarshaling has failed, so that the location
+// of the problem costs nothing when unmarshaling succeeds.
+func (o UnmarshalOptions) decodeError(b []byte, md protoreflect.MessageDescriptor, err error) error {
+	if o.Resolver == nil {
+		o.Resolver = protoregistry.GlobalTypes
+	}
+	if o.RecursionLimit == 0 {
+		o.RecursionLimit = protowire.DefaultRecursionLimit
+	}
+	d := wireDiagnoser{opts: o, in: b}
+	_, derr := d.message(b, md, 0, o.RecursionLimit)
+	if derr == nil {
+		return err
+	}
+	derr.Err = err
+	return derr
+}
+
+type wireDiagnoser struct {
+	opts UnmarshalOptions
+	in   []byte
+}
+
+// offset returns the offset of b within the input.
+// Since b is always resliced from the input, its capacity extends to the
+// end of the input, even if it is the contents of a length-delimited field.
+func (d *wireDiagnoser) offset(b []byte) int {
+	return cap(d.in) - cap(b)
+}
+
+func (d *wireDiagnoser) errorAt(b []byte, kind DecodeErrorKind, num protowire.Number, fd protoreflect.FieldDescriptor) *DecodeError {
+	e := &DecodeError{Kind: kind, Offset: d.offset(b), FieldNumber: num}
+	if fd != nil {
+		e.FullName = fd.FullName()
+	}
+	return e
+}
+
+// varintErrorKind classifies the error code returned by ConsumeVarint.
+func varintErrorKind(n int) DecodeErrorKind {
+	if protowire.ParseError(n) == io.ErrUnexpectedEOF {
+		return DecodeTruncated
+	}
+	return DecodeOverflow
+}
+
+// message checks the fields in b, which are the contents of a message
+// of type md, or of a group with field number group if it is non-zero.
+// The descriptor md is nil if the type of the message is unknown.
+// For a group, it returns the input following the end group tag.
+func (d *wireDiagnoser) message(b []byte, md protoreflect.MessageDescriptor, group protowire.Number, depth int) (rest []byte, derr *DecodeError) {
+	if depth--; depth < 0 {
+		return nil, d.errorAt(b, DecodeRecursionLimit, 0, nil)
+	}
+	if md != nil && messageset.IsMessageSet(md) {
+		md = nil
+	}
+	for len(b) > 0 {
+		start := b
+		tag, n := protowire.ConsumeVarint(b)
+		if n < 0 {
+			return nil, d.errorAt(start, varintErrorKind(n), 0, nil)
+		}
+		num, typ := protowire.DecodeTag(tag)
+		if num < protowire.MinValidNumber || num > protowire.MaxValidNumber {
+			return nil, d.errorAt(start, DecodeInvalidFieldNumber, 0, nil)
+		}
+		b = b[n:]
+		fd := d.field(md, num)
+		switch typ {
+		case protowire.VarintType:
+			if _, n := protowire.ConsumeVarint(b); n < 0 {
+				return nil, d.errorAt(start, varintErrorKind(n), num, fd)
+			} else {
+				b = b[n:]
+			}
+		case protowire.Fixed32Type:
+			if _, n := protowire.ConsumeFixed32(b); n < 0 {
+				return nil, d.errorAt(start, DecodeTruncated, num, fd)
+			} else {
+				b = b[n:]
+			}
+		case protowire.Fixed64Type:
+			if _, n := protowire.ConsumeFixed64(b); n < 0 {
+				return nil, d.errorAt(start, DecodeTruncated, num, fd)
+			} else {
+				b = b[n:]
+			}
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				if _, m := protowire.ConsumeVarint(b); m < 0 {
+					return nil, d.errorAt(start, varintErrorKind(m), num, fd)
+				}
+				return nil, d.errorAt(start, DecodeTruncated, num, fd)
+			}
+			b = b[n:]
+			if derr := d.bytesValue(start, v, fd, num, depth); derr != nil {
+				return nil, derr
+			}
+		case protowire.StartGroupType:
+			var gmd protoreflect.MessageDescriptor
+			if fd != nil && fd.Kind() == protoreflect.GroupKind {
+				gmd = fd.Message()
+			}
+			rest, derr := d.message(b, gmd, num, depth)
+			if derr != nil {
+				return nil, derr
+			}
+			b = rest
+		case protowire.EndGroupType:
+			if group != 0 && num == group {
+				return b, nil
+			}
+			return nil, d.errorAt(start, DecodeInvalidWireType, num, fd)
+		default:
+			return nil, d.errorAt(start, DecodeInvalidWireType, num, fd)
+		}
+	}
+	if group != 0 {
+		return nil, d.errorAt(b, DecodeTruncated, group, nil)
+	}
+	return nil, nil
+}
+
+// bytesValue checks the contents of a length-delimited field.
+func (d *wireDiagnoser) bytesValue(start, v []byte, fd protoreflect.FieldDescriptor, num protowire.Number, depth int) *DecodeError {
+	if fd == nil {
+		return nil
+	}
+	switch kind := fd.Kind(); {
+	case kind == protoreflect.MessageKind:
+		_, derr := d.message(v, fd.Message(), 0, depth)
+		return derr
+	case kind == protoreflect.StringKind:
+		if _, err := d.opts.checkUTF8(v, fd); err != nil {
+			return d.errorAt(start, DecodeInvalidUTF8, num, fd)
+		}
+	case fd.IsList() && kind != protoreflect.BytesKind && kind != protoreflect.GroupKind:
+		// Packed repeated scalars.
+		for len(v) > 0 {
+			var n int
+			switch wireTypes[kind] {
+			case protowire.VarintType:
+				_, n = protowire.ConsumeVarint(v)
+			case protowire.Fixed32Type:
+				_, n = protowire.ConsumeFixed32(v)
+			case protowire.Fixed64Type:
+				_, n = protowire.ConsumeFixed64(v)
+			}
+			if n < 0 {
+				return d.errorAt(v, DecodeTruncated, num, fd)
+			}
+			v = v[n:]
+		}
+	}
+	return nil
+}
+
+// field returns the descriptor of the field numbered num in md,
+// or nil if it is unknown.
+func (d *wireDiagnoser) field(md protoreflect.MessageDescriptor, num protowire.Number) protoreflect.FieldDescriptor {
+	if md == nil {
+		return nil
+	}
+	if fd := md.Fields().ByNumber(num); fd != nil {
+		return fd
+	}
+	if md.ExtensionRanges().Has(num) {
+		if xt, err := d.opts.Resolver.FindExtensionByNumber(md.FullName(), num); err == nil {
+			return xt.TypeDescriptor()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package proto
+
+import (
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// selectFields splits the wire-format message b into the fields with
+// one of the given field numbers and all other fields.
+//
+// Malformed input is kept in the selected part;
+// it is left to the unmarshaler to produce the appropriate error.
+func selectFields(b []byte, nums []protoreflect.FieldNumber) (selected, skipped []byte) {
+	for len(b) > 0 {
+		num, wtyp, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return append(selected, b...), skipped
+		}
+		m := protowire.ConsumeFieldValue(num, wtyp, b[n:])
+		if m < 0 {
+			return append(selected, b...), skipped
+		}
+		if hasFieldNumber(nums, num) {
+			selected = append(selected, b[:n+m]...)
+		} else {
+			skipped = append(skipped, b[:n+m]...)
+		}
+		b = b[n+m:]
+	}
+	return selected, skipped
+}
+
+func hasFieldNumber(nums []protoreflect.FieldNumber, num protoreflect.FieldNumber) bool {
+	for _, n := range nums {
+		if n == num {
+			return true
+		}
+	}
+	return false
+}
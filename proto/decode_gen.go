@@ -8,11 +8,8 @@ package proto
 
 import (
 	"math"
-	"unicode/utf8"
 
 	"google.golang.org/protobuf/encoding/protowire"
-	"google.golang.org/protobuf/internal/errors"
-	"google.golang.org/protobuf/internal/strs"
 	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
@@ -155,8 +152,9 @@ func (o UnmarshalOptions) unmarshalScalar(b []byte, wtyp protowire.Type, fd prot
 		if n < 0 {
 			return val, 0, errDecode
 		}
-		if strs.EnforceUTF8(fd) && !utf8.Valid(v) {
-			return protoreflect.Value{}, 0, errors.InvalidUTF8(string(fd.FullName()))
+		v, err := o.checkUTF8(v, fd)
+		if err != nil {
+			return protoreflect.Value{}, 0, err
 		}
 		return protoreflect.ValueOfString(string(v)), n, nil
 	case protoreflect.BytesKind:
@@ -551,8 +549,9 @@ func (o UnmarshalOptions) unmarshalList(b []byte, wtyp protowire.Type, list prot
 		if n < 0 {
 			return 0, errDecode
 		}
-		if strs.EnforceUTF8(fd) && !utf8.Valid(v) {
-			return 0, errors.InvalidUTF8(string(fd.FullName()))
+		v, err := o.checkUTF8(v, fd)
+		if err != nil {
+			return 0, err
 		}
 		list.Append(protoreflect.ValueOfString(string(v)))
 		return n, nil
@@ -0,0 +1,100 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package proto
+
+import (
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/internal/errors"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// checkSizeLimits validates b against the MaxSize and MaxFieldSize limits.
+//
+// Malformed input is not reported by this function;
+// it is left to the unmarshaler to produce the appropriate error.
+func (o UnmarshalOptions) checkSizeLimits(b []byte, md protoreflect.MessageDescriptor) error {
+	if o.MaxSize > 0 && len(b) > o.MaxSize {
+		return errors.New("input size %d exceeds maximum size %d", len(b), o.MaxSize)
+	}
+	if o.MaxFieldSize <= 0 {
+		return nil
+	}
+	if o.Resolver == nil {
+		o.Resolver = protoregistry.GlobalTypes
+	}
+	return o.checkFieldSizes(b, md, o.RecursionLimit)
+}
+
+func (o UnmarshalOptions) checkFieldSizes(b []byte, md protoreflect.MessageDescriptor, depth int) error {
+	if depth < 0 {
+		return nil
+	}
+	for len(b) > 0 {
+		num, wtyp, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil
+		}
+		b = b[n:]
+
+		var fd protoreflect.FieldDescriptor
+		if md != nil {
+			fd = md.Fields().ByNumber(num)
+			if fd == nil && md.ExtensionRanges().Has(num) {
+				if xt, err := o.Resolver.FindExtensionByNumber(md.FullName(), num); err == nil {
+					fd = xt.TypeDescriptor()
+				}
+			}
+		}
+		var sub protoreflect.MessageDescriptor
+		if fd != nil {
+			switch {
+			case fd.IsMap():
+				sub = fd.Message()
+			case fd.Kind() == protoreflect.MessageKind, fd.Kind() == protoreflect.GroupKind:
+				sub = fd.Message()
+			}
+		}
+
+		switch wtyp {
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return nil
+			}
+			switch {
+			case sub != nil:
+				if err := o.checkFieldSizes(v, sub, depth-1); err != nil {
+					return err
+				}
+			case fd != nil && fd.IsList() && fd.Kind() != protoreflect.BytesKind && fd.Kind() != protoreflect.StringKind:
+				// Packed repeated scalars are not subject to the limit.
+			case len(v) > o.MaxFieldSize:
+				name := protoreflect.FullName("unknown")
+				if fd != nil {
+					name = fd.FullName()
+				}
+				return errors.New("field %v (number %d): size %d exceeds maximum field size %d", name, num, len(v), o.MaxFieldSize)
+			}
+			b = b[n:]
+		case protowire.StartGroupType:
+			v, n := protowire.ConsumeGroup(num, b)
+			if n < 0 {
+				return nil
+			}
+			if err := o.checkFieldSizes(v, sub, depth-1); err != nil {
+				return err
+			}
+			b = b[n:]
+		default:
+			n := protowire.ConsumeFieldValue(num, wtyp, b)
+			if n < 0 {
+				return nil
+			}
+			b = b[n:]
+		}
+	}
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	"testing"
 
 	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/internal/flags"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/testing/protopack"
@@ -136,6 +137,101 @@ func TestDecodeEmptyBytes(t *testing.T) {
 	}
 }
 
+func TestDecodeRecursionLimitExtension(t *testing.T) {
+	// Repeated message extensions are decoded through the proto package
+	// rather than directly by the fast-path unmarshaler, which must not
+	// reset the recursion limit.
+	if flags.LazyUnmarshalExtensions {
+		t.Skip("extensions are decoded lazily")
+	}
+	b := protopack.Message{
+		protopack.Tag{48, protopack.BytesType}, protopack.LengthPrefix{
+			protopack.Tag{2, protopack.BytesType}, protopack.LengthPrefix{
+				protopack.Tag{48, protopack.BytesType}, protopack.LengthPrefix{},
+			},
+		},
+	}.Marshal()
+	if err := (proto.UnmarshalOptions{RecursionLimit: 4}).Unmarshal(b, &testpb.TestAllExtensions{}); err != nil {
+		t.Fatalf("Unmarshal(RecursionLimit: 4) error: %v", err)
+	}
+	if err := (proto.UnmarshalOptions{RecursionLimit: 3}).Unmarshal(b, &testpb.TestAllExtensions{}); err == nil {
+		t.Errorf("Unmarshal(RecursionLimit: 3) succeeded, want recursion error")
+	}
+}
+
+func TestDecodeSizeLimits(t *testing.T) {
+	long := bytes.Repeat([]byte("x"), 100)
+	for _, test := range []struct {
+		desc    string
+		opts    proto.UnmarshalOptions
+		m       proto.Message
+		wantErr bool
+	}{{
+		desc: "under MaxSize",
+		opts: proto.UnmarshalOptions{MaxSize: 1000},
+		m:    &test3pb.TestAllTypes{SingularBytes: long},
+	}, {
+		desc:    "over MaxSize",
+		opts:    proto.UnmarshalOptions{MaxSize: 50},
+		m:       &test3pb.TestAllTypes{SingularBytes: long},
+		wantErr: true,
+	}, {
+		desc: "bytes field at MaxFieldSize",
+		opts: proto.UnmarshalOptions{MaxFieldSize: 100},
+		m:    &test3pb.TestAllTypes{SingularBytes: long},
+	}, {
+		desc:    "bytes field over MaxFieldSize",
+		opts:    proto.UnmarshalOptions{MaxFieldSize: 99},
+		m:       &test3pb.TestAllTypes{SingularBytes: long},
+		wantErr: true,
+	}, {
+		desc:    "string field over MaxFieldSize",
+		opts:    proto.UnmarshalOptions{MaxFieldSize: 99},
+		m:       &test3pb.TestAllTypes{RepeatedString: []string{"a", string(long)}},
+		wantErr: true,
+	}, {
+		desc: "message field larger than MaxFieldSize",
+		opts: proto.UnmarshalOptions{MaxFieldSize: 50},
+		m: &test3pb.TestAllTypes{
+			RepeatedNestedMessage: []*test3pb.TestAllTypes_NestedMessage{{
+				Corecursive: &test3pb.TestAllTypes{
+					SingularString: string(long[:40]),
+					SingularBytes:  long[:40],
+				},
+			}},
+		},
+	}, {
+		desc:    "nested bytes field over MaxFieldSize",
+		opts:    proto.UnmarshalOptions{MaxFieldSize: 50},
+		m:       &test3pb.TestAllTypes{SingularNestedMessage: &test3pb.TestAllTypes_NestedMessage{Corecursive: &test3pb.TestAllTypes{SingularBytes: long}}},
+		wantErr: true,
+	}, {
+		desc:    "map value over MaxFieldSize",
+		opts:    proto.UnmarshalOptions{MaxFieldSize: 50},
+		m:       &test3pb.TestAllTypes{MapStringBytes: map[string][]byte{"k": long}},
+		wantErr: true,
+	}, {
+		desc: "packed field larger than MaxFieldSize",
+		opts: proto.UnmarshalOptions{MaxFieldSize: 10},
+		m:    &test3pb.TestAllTypes{RepeatedInt32: []int32{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}},
+	}} {
+		t.Run(test.desc, func(t *testing.T) {
+			b, err := proto.Marshal(test.m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := test.m.ProtoReflect().New().Interface()
+			err = test.opts.Unmarshal(b, got)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("Unmarshal error = %v, want error %v", err, test.wantErr)
+			}
+			if err == nil && !proto.Equal(got, test.m) {
+				t.Errorf("Unmarshal returned unexpected result; got:\n%v\nwant:\n%v", prototext.Format(got), prototext.Format(test.m))
+			}
+		})
+	}
+}
+
 func build(m proto.Message, opts ...buildOpt) proto.Message {
 	for _, opt := range opts {
 		opt(m)
@@ -173,3 +269,280 @@ func ExampleUnmarshal() {
 
 	// Output: Protobuf wire format decoded to duration 125ns
 }
+
+func TestDecodeErrorOnUnknownEnumValue(t *testing.T) {
+	for _, test := range []struct {
+		desc    string
+		m       proto.Message
+		wantErr bool
+	}{{
+		desc: "declared value",
+		m:    &testpb.TestAllTypes{OptionalNestedEnum: testpb.TestAllTypes_BAR.Enum()},
+	}, {
+		desc:    "undeclared value",
+		m:       &testpb.TestAllTypes{OptionalNestedEnum: testpb.TestAllTypes_NestedEnum(99).Enum()},
+		wantErr: true,
+	}, {
+		desc:    "undeclared value in list",
+		m:       &testpb.TestAllTypes{RepeatedNestedEnum: []testpb.TestAllTypes_NestedEnum{testpb.TestAllTypes_FOO, 99}},
+		wantErr: true,
+	}, {
+		desc:    "undeclared value in map",
+		m:       &testpb.TestAllTypes{MapStringNestedEnum: map[string]testpb.TestAllTypes_NestedEnum{"k": 99}},
+		wantErr: true,
+	}, {
+		desc: "undeclared value in nested message",
+		m: &testpb.TestAllTypes{OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{
+			Corecursive: &testpb.TestAllTypes{OptionalForeignEnum: testpb.ForeignEnum(99).Enum()},
+		}},
+		wantErr: true,
+	}, {
+		desc: "undeclared value of open enum",
+		m:    &test3pb.TestAllTypes{SingularNestedEnum: 99},
+	}} {
+		t.Run(test.desc, func(t *testing.T) {
+			b, err := proto.Marshal(test.m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := test.m.ProtoReflect().New().Interface()
+			if err := (proto.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(b, got); err != nil {
+				t.Fatalf("Unmarshal without ErrorOnUnknownEnumValue: %v", err)
+			}
+			if !proto.Equal(got, test.m) {
+				t.Errorf("Unmarshal did not retain enum values; got:\n%v\nwant:\n%v", prototext.Format(got), prototext.Format(test.m))
+			}
+			opts := proto.UnmarshalOptions{DiscardUnknown: true, ErrorOnUnknownEnumValue: true}
+			err = opts.Unmarshal(b, test.m.ProtoReflect().New().Interface())
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Errorf("Unmarshal with ErrorOnUnknownEnumValue error = %v, want error %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecodeFields(t *testing.T) {
+	m := &testpb.TestAllTypes{
+		OptionalInt32:         proto.Int32(1),
+		OptionalString:        proto.String("s"),
+		RepeatedInt32:         []int32{1, 2},
+		OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{A: proto.Int32(2)},
+		Optionalgroup:         &testpb.TestAllTypes_OptionalGroup{A: proto.Int32(3)},
+	}
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := []protoreflect.FieldNumber{1, 31}
+
+	got := &testpb.TestAllTypes{}
+	if err := (proto.UnmarshalOptions{Fields: fields}).Unmarshal(b, got); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+	if got.GetOptionalInt32() != 1 || len(got.GetRepeatedInt32()) != 2 {
+		t.Errorf("Unmarshal() did not decode selected fields:\n%v", prototext.Format(got))
+	}
+	if got.OptionalString != nil || got.OptionalNestedMessage != nil || got.Optionalgroup != nil {
+		t.Errorf("Unmarshal() decoded fields that were not selected:\n%v", prototext.Format(got))
+	}
+	if len(got.ProtoReflect().GetUnknown()) == 0 {
+		t.Errorf("Unmarshal() did not retain fields that were not selected as unknown fields")
+	}
+	// Reparsing the unknown fields must result in the original message.
+	b2, err := proto.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	full := &testpb.TestAllTypes{}
+	if err := proto.Unmarshal(b2, full); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(full, m) {
+		t.Errorf("round-trip mismatch; got:\n%v\nwant:\n%v", prototext.Format(full), prototext.Format(m))
+	}
+
+	got = &testpb.TestAllTypes{}
+	if err := (proto.UnmarshalOptions{Fields: fields, DiscardUnknown: true}).Unmarshal(b, got); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+	want := &testpb.TestAllTypes{OptionalInt32: proto.Int32(1), RepeatedInt32: []int32{1, 2}}
+	if !proto.Equal(got, want) {
+		t.Errorf("Unmarshal() with DiscardUnknown; got:\n%v\nwant:\n%v", prototext.Format(got), prototext.Format(want))
+	}
+
+	if err := (proto.UnmarshalOptions{Fields: fields}).Unmarshal(append(b, 0xff), &testpb.TestAllTypes{}); err == nil {
+		t.Errorf("Unmarshal() of malformed input succeeded, want error")
+	}
+}
+
+func TestDecodeValidator(t *testing.T) {
+	errNegative := errors.New("negative value")
+	validator := proto.ValidatorFunc(func(m proto.Message) error {
+		if m.(*testpb.TestAllTypes).GetOptionalInt32() < 0 {
+			return errNegative
+		}
+		return nil
+	})
+	opts := proto.UnmarshalOptions{Validator: validator}
+
+	b, err := proto.Marshal(&testpb.TestAllTypes{OptionalInt32: proto.Int32(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := opts.Unmarshal(b, &testpb.TestAllTypes{}); err != nil {
+		t.Errorf("Unmarshal(valid message) = %v, want nil", err)
+	}
+
+	b, err = proto.Marshal(&testpb.TestAllTypes{OptionalInt32: proto.Int32(-1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &testpb.TestAllTypes{}
+	if err := opts.Unmarshal(b, m); err != errNegative {
+		t.Errorf("Unmarshal(invalid message) = %v, want %v", err, errNegative)
+	}
+	if got := m.GetOptionalInt32(); got != -1 {
+		t.Errorf("Unmarshal(invalid message) left optional_int32 = %v, want -1", got)
+	}
+
+	// The validator is not called if unmarshaling fails.
+	var called bool
+	opts.Validator = proto.ValidatorFunc(func(proto.Message) error {
+		called = true
+		return nil
+	})
+	if err := opts.Unmarshal([]byte{0x08}, &testpb.TestAllTypes{}); err == nil {
+		t.Errorf("Unmarshal(truncated input) = nil, want error")
+	}
+	if err := opts.Unmarshal(nil, &testpb.TestRequired{}); err == nil {
+		t.Errorf("Unmarshal(missing required field) = nil, want error")
+	}
+	if called {
+		t.Errorf("validator called after unmarshal error")
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	for _, test := range []struct {
+		desc string
+		opts proto.UnmarshalOptions
+		m    proto.Message
+		wire []byte
+		want *proto.DecodeError // nil if the error is not a DecodeError
+	}{{
+		desc: "truncated varint",
+		m:    &testpb.TestAllTypes{},
+		wire: protopack.Message{
+			protopack.Tag{1, protopack.VarintType}, protopack.Varint(1),
+			protopack.Tag{1, protopack.VarintType}, protopack.Raw{0x80},
+		}.Marshal(),
+		want: &proto.DecodeError{Kind: proto.DecodeTruncated, Offset: 2, FieldNumber: 1, FullName: "goproto.proto.test.TestAllTypes.optional_int32"},
+	}, {
+		desc: "varint overflow",
+		m:    &testpb.TestAllTypes{},
+		wire: protopack.Message{
+			protopack.Tag{1, protopack.VarintType}, protopack.Raw("\xff\xff\xff\xff\xff\xff\xff\xff\xff\xff\x01"),
+		}.Marshal(),
+		want: &proto.DecodeError{Kind: proto.DecodeOverflow, Offset: 0, FieldNumber: 1, FullName: "goproto.proto.test.TestAllTypes.optional_int32"},
+	}, {
+		desc: "truncated field in nested message",
+		m:    &testpb.TestAllTypes{},
+		wire: protopack.Message{
+			protopack.Tag{18, protopack.BytesType}, protopack.LengthPrefix{
+				protopack.Tag{1, protopack.Fixed32Type}, protopack.Raw{0x01},
+			},
+		}.Marshal(),
+		want: &proto.DecodeError{Kind: proto.DecodeTruncated, Offset: 3, FieldNumber: 1, FullName: "goproto.proto.test.TestAllTypes.NestedMessage.a"},
+	}, {
+		desc: "length prefix exceeds input",
+		m:    &testpb.TestAllTypes{},
+		wire: protopack.Message{
+			protopack.Tag{14, protopack.BytesType}, protopack.Uvarint(10), protopack.Raw("ab"),
+		}.Marshal(),
+		want: &proto.DecodeError{Kind: proto.DecodeTruncated, Offset: 0, FieldNumber: 14, FullName: "goproto.proto.test.TestAllTypes.optional_string"},
+	}, {
+		desc: "invalid field number",
+		m:    &testpb.TestAllTypes{},
+		wire: protopack.Message{
+			protopack.Tag{1, protopack.VarintType}, protopack.Varint(1),
+			protopack.Raw{0x00, 0x00},
+		}.Marshal(),
+		want: &proto.DecodeError{Kind: proto.DecodeInvalidFieldNumber, Offset: 2},
+	}, {
+		desc: "reserved wire type",
+		m:    &testpb.TestAllTypes{},
+		wire: protopack.Message{
+			protopack.Tag{1000, 6},
+		}.Marshal(),
+		want: &proto.DecodeError{Kind: proto.DecodeInvalidWireType, Offset: 0, FieldNumber: 1000},
+	}, {
+		desc: "unterminated group",
+		m:    &testpb.TestAllTypes{},
+		wire: protopack.Message{
+			protopack.Tag{16, protopack.StartGroupType},
+			protopack.Tag{17, protopack.VarintType}, protopack.Varint(1),
+		}.Marshal(),
+		want: &proto.DecodeError{Kind: proto.DecodeTruncated, Offset: 5, FieldNumber: 16},
+	}, {
+		desc: "invalid UTF-8",
+		m:    &test3pb.TestAllTypes{},
+		wire: protopack.Message{
+			protopack.Tag{14, protopack.BytesType}, protopack.String("\xff"),
+		}.Marshal(),
+		want: &proto.DecodeError{Kind: proto.DecodeInvalidUTF8, Offset: 0, FieldNumber: 14, FullName: "goproto.proto.test3.TestAllTypes.optional_string"},
+	}, {
+		desc: "recursion limit",
+		opts: proto.UnmarshalOptions{RecursionLimit: 2},
+		m:    &testpb.TestAllTypes{},
+		wire: protopack.Message{
+			protopack.Tag{18, protopack.BytesType}, protopack.LengthPrefix{
+				protopack.Tag{2, protopack.BytesType}, protopack.LengthPrefix{
+					protopack.Tag{1, protopack.VarintType}, protopack.Varint(1),
+				},
+			},
+		}.Marshal(),
+		want: &proto.DecodeError{Kind: proto.DecodeRecursionLimit, Offset: 5},
+	}, {
+		desc: "truncated in repeated message extension",
+		m:    &testpb.TestAllExtensions{},
+		wire: protopack.Message{
+			protopack.Tag{48, protopack.BytesType}, protopack.LengthPrefix{
+				protopack.Tag{2, protopack.BytesType}, protopack.LengthPrefix{
+					protopack.Tag{1, protopack.VarintType}, protopack.Raw{0x80},
+				},
+			},
+		}.Marshal(),
+		want: &proto.DecodeError{Kind: proto.DecodeTruncated, Offset: 5, FieldNumber: 1, FullName: "goproto.proto.test.optional_int32"},
+	}, {
+		desc: "missing required field",
+		m:    &testpb.TestRequired{},
+		wire: nil,
+	}} {
+		t.Run(test.desc, func(t *testing.T) {
+			err := test.opts.Unmarshal(test.wire, test.m)
+			if err == nil {
+				t.Fatalf("Unmarshal() = nil, want error")
+			}
+			if !errors.Is(err, proto.Error) {
+				t.Errorf("errors.Is(%v, proto.Error) = false, want true", err)
+			}
+			var got *proto.DecodeError
+			if !errors.As(err, &got) {
+				if test.want != nil {
+					t.Fatalf("Unmarshal() = %v, want *DecodeError", err)
+				}
+				return
+			}
+			if test.want == nil {
+				t.Fatalf("Unmarshal() = %v, want error other than *DecodeError", err)
+			}
+			if errors.As(got.Err, new(*proto.DecodeError)) {
+				t.Errorf("Unmarshal() error = %v, wraps more than one *DecodeError", err)
+			}
+			test.want.Err = got.Err
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Unmarshal() error = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
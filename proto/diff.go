@@ -0,0 +1,155 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package proto
+
+import (
+	"sort"
+
+	"google.golang.org/protobuf/internal/order"
+	"google.golang.org/protobuf/internal/rawfields"
+	"google.golang.org/protobuf/reflect/protopath"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// DiffEntry is a single difference between two messages reported by [Diff].
+type DiffEntry struct {
+	// Path is the path from the root message to the differing values,
+	// starting with a [protopath.Root] step. The unknown fields of a message
+	// are accessed with a [protopath.UnknownAccess] step.
+	// It is empty if the root messages themselves differ,
+	// such as when they are of different types.
+	Path protopath.Path
+
+	// X and Y are the differing values in the first and second message.
+	// A value is invalid if it is not populated in the respective message.
+	//
+	// For a difference in unknown fields,
+	// X and Y are bytes values holding the raw unknown fields.
+	X, Y protoreflect.Value
+}
+
+// Diff reports the differences between two messages,
+// ordered by field number and then by map key.
+// It reports no differences if and only if [Equal] reports true.
+//
+// Message fields populated in both messages are compared recursively,
+// as are the message values of map entries present in both maps.
+// Maps are compared entry by entry.
+// Any other field that differs is reported as a whole,
+// including repeated fields.
+//
+// If x and y are of different types, or only one of them is nil or invalid,
+// Diff reports a single entry with an empty path.
+func Diff(x, y Message) []DiffEntry {
+	if x == nil || y == nil {
+		if x == nil && y == nil {
+			return nil
+		}
+		return []DiffEntry{{X: valueOfMessage(x), Y: valueOfMessage(y)}}
+	}
+	mx := x.ProtoReflect()
+	my := y.ProtoReflect()
+	if mx.Descriptor() != my.Descriptor() || mx.IsValid() != my.IsValid() {
+		return []DiffEntry{{X: valueOfMessage(x), Y: valueOfMessage(y)}}
+	}
+	d := differ{path: protopath.Path{protopath.Root(mx.Descriptor())}}
+	d.diffMessage(mx, my)
+	return d.entries
+}
+
+func valueOfMessage(m Message) protoreflect.Value {
+	if m == nil {
+		return protoreflect.Value{}
+	}
+	return protoreflect.ValueOfMessage(m.ProtoReflect())
+}
+
+type differ struct {
+	path    protopath.Path
+	entries []DiffEntry
+}
+
+func (d *differ) report(x, y protoreflect.Value) {
+	d.entries = append(d.entries, DiffEntry{
+		Path: append(protopath.Path(nil), d.path...),
+		X:    x,
+		Y:    y,
+	})
+}
+
+func (d *differ) diffMessage(mx, my protoreflect.Message) {
+	var fds []protoreflect.FieldDescriptor
+	mx.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		fds = append(fds, fd)
+		return true
+	})
+	my.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if !mx.Has(fd) {
+			fds = append(fds, fd)
+		}
+		return true
+	})
+	sort.Slice(fds, func(i, j int) bool {
+		return order.NumberFieldOrder(fds[i], fds[j])
+	})
+
+	for _, fd := range fds {
+		if fd.IsMap() {
+			d.diffMap(fd, mx.Get(fd).Map(), my.Get(fd).Map())
+			continue
+		}
+		var vx, vy protoreflect.Value
+		if mx.Has(fd) {
+			vx = mx.Get(fd)
+		}
+		if my.Has(fd) {
+			vy = my.Get(fd)
+		}
+		d.path = append(d.path, protopath.FieldAccess(fd))
+		switch {
+		case fd.Message() != nil && !fd.IsList() && vx.IsValid() && vy.IsValid():
+			d.diffMessage(vx.Message(), vy.Message())
+		case !vx.IsValid() || !vy.IsValid() || !vx.Equal(vy):
+			d.report(vx, vy)
+		}
+		d.path = d.path[:len(d.path)-1]
+	}
+
+	if ux, uy := mx.GetUnknown(), my.GetUnknown(); !rawfields.Equal(ux, uy) {
+		d.path = append(d.path, protopath.UnknownAccess())
+		d.report(protoreflect.ValueOfBytes(ux), protoreflect.ValueOfBytes(uy))
+		d.path = d.path[:len(d.path)-1]
+	}
+}
+
+func (d *differ) diffMap(fd protoreflect.FieldDescriptor, mx, my protoreflect.Map) {
+	var keys []protoreflect.MapKey
+	mx.Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+		keys = append(keys, k)
+		return true
+	})
+	my.Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+		if !mx.Has(k) {
+			keys = append(keys, k)
+		}
+		return true
+	})
+	sort.Slice(keys, func(i, j int) bool {
+		return order.GenericKeyOrder(keys[i], keys[j])
+	})
+
+	isMessage := fd.MapValue().Message() != nil
+	for _, k := range keys {
+		vx, vy := mx.Get(k), my.Get(k)
+		d.path = append(d.path, protopath.FieldAccess(fd), protopath.MapIndex(k))
+		switch {
+		case isMessage && vx.IsValid() && vy.IsValid():
+			d.diffMessage(vx.Message(), vy.Message())
+		case !vx.IsValid() || !vy.IsValid() || !vx.Equal(vy):
+			d.report(vx, vy)
+		}
+		d.path = d.path[:len(d.path)-2]
+	}
+}
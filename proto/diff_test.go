@@ -0,0 +1,210 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package proto_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/testing/protopack"
+
+	testpb "google.golang.org/protobuf/internal/testprotos/test"
+)
+
+func TestDiff(t *testing.T) {
+	// formatEntry formats a DiffEntry as "path: x -> y", where the path
+	// omits its root step and unpopulated values are formatted as "<none>".
+	formatEntry := func(e proto.DiffEntry) string {
+		format := func(v protoreflect.Value) string {
+			if !v.IsValid() {
+				return "<none>"
+			}
+			switch v := v.Interface().(type) {
+			case protoreflect.Message:
+				return "message"
+			case protoreflect.List:
+				return fmt.Sprintf("list[%d]", v.Len())
+			case []byte:
+				return fmt.Sprintf("%q", v)
+			default:
+				return fmt.Sprint(v)
+			}
+		}
+		var path string
+		if len(e.Path) > 0 {
+			path = strings.TrimPrefix(e.Path[1:].String(), ".")
+		}
+		return fmt.Sprintf("%v: %v -> %v", path, format(e.X), format(e.Y))
+	}
+
+	tests := []struct {
+		desc string
+		x, y proto.Message
+		want []string
+	}{{
+		desc: "nil messages",
+		x:    nil,
+		y:    nil,
+	}, {
+		desc: "equal messages",
+		x: &testpb.TestAllTypes{
+			OptionalInt32:   proto.Int32(1),
+			MapStringString: map[string]string{"a": "b"},
+		},
+		y: &testpb.TestAllTypes{
+			OptionalInt32:   proto.Int32(1),
+			MapStringString: map[string]string{"a": "b"},
+		},
+	}, {
+		desc: "nil and non-nil message",
+		x:    nil,
+		y:    &testpb.TestAllTypes{},
+		want: []string{": <none> -> message"},
+	}, {
+		desc: "different types",
+		x:    &testpb.TestAllTypes{},
+		y:    &testpb.ForeignMessage{},
+		want: []string{": message -> message"},
+	}, {
+		desc: "invalid and valid message",
+		x:    (*testpb.TestAllTypes)(nil),
+		y:    &testpb.TestAllTypes{},
+		want: []string{": message -> message"},
+	}, {
+		desc: "scalar fields",
+		x: &testpb.TestAllTypes{
+			OptionalInt32:  proto.Int32(1),
+			OptionalString: proto.String("x"),
+		},
+		y: &testpb.TestAllTypes{
+			OptionalInt32: proto.Int32(2),
+			OptionalBool:  proto.Bool(true),
+		},
+		want: []string{
+			"optional_int32: 1 -> 2",
+			"optional_bool: <none> -> true",
+			"optional_string: x -> <none>",
+		},
+	}, {
+		desc: "nested message fields",
+		x: &testpb.TestAllTypes{
+			OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{
+				A: proto.Int32(1),
+				Corecursive: &testpb.TestAllTypes{
+					OptionalInt64: proto.Int64(1),
+				},
+			},
+		},
+		y: &testpb.TestAllTypes{
+			OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{
+				A: proto.Int32(2),
+			},
+			OptionalForeignMessage: &testpb.ForeignMessage{},
+		},
+		want: []string{
+			"optional_nested_message.a: 1 -> 2",
+			"optional_nested_message.corecursive: message -> <none>",
+			"optional_foreign_message: <none> -> message",
+		},
+	}, {
+		desc: "repeated fields",
+		x: &testpb.TestAllTypes{
+			RepeatedInt32: []int32{1, 2},
+			RepeatedBytes: [][]byte{{1}},
+		},
+		y: &testpb.TestAllTypes{
+			RepeatedInt32: []int32{1, 3, 4},
+			RepeatedBytes: [][]byte{{1}},
+		},
+		want: []string{
+			"repeated_int32: list[2] -> list[3]",
+		},
+	}, {
+		desc: "map fields",
+		x: &testpb.TestAllTypes{
+			MapInt32Int32:   map[int32]int32{1: 1, 2: 2},
+			MapStringString: map[string]string{"a": "a", "b": "b"},
+			MapStringNestedMessage: map[string]*testpb.TestAllTypes_NestedMessage{
+				"x": {A: proto.Int32(1)},
+				"y": {},
+			},
+		},
+		y: &testpb.TestAllTypes{
+			MapInt32Int32:   map[int32]int32{1: 1, 2: 3, 3: 3},
+			MapStringString: map[string]string{"b": "c"},
+			MapStringNestedMessage: map[string]*testpb.TestAllTypes_NestedMessage{
+				"x": {A: proto.Int32(2)},
+			},
+		},
+		want: []string{
+			"map_int32_int32[2]: 2 -> 3",
+			"map_int32_int32[3]: <none> -> 3",
+			`map_string_string["a"]: a -> <none>`,
+			`map_string_string["b"]: b -> c`,
+			`map_string_nested_message["x"].a: 1 -> 2`,
+			`map_string_nested_message["y"]: message -> <none>`,
+		},
+	}, {
+		desc: "oneof fields",
+		x:    &testpb.TestAllTypes{OneofField: &testpb.TestAllTypes_OneofUint32{1}},
+		y:    &testpb.TestAllTypes{OneofField: &testpb.TestAllTypes_OneofString{"1"}},
+		want: []string{
+			"oneof_uint32: 1 -> <none>",
+			"oneof_string: <none> -> 1",
+		},
+	}, {
+		desc: "extension fields",
+		x: func() proto.Message {
+			m := &testpb.TestAllExtensions{}
+			proto.SetExtension(m, testpb.E_OptionalInt32, int32(1))
+			return m
+		}(),
+		y: func() proto.Message {
+			m := &testpb.TestAllExtensions{}
+			proto.SetExtension(m, testpb.E_OptionalInt32, int32(2))
+			return m
+		}(),
+		want: []string{
+			"(goproto.proto.test.optional_int32): 1 -> 2",
+		},
+	}, {
+		desc: "unknown fields",
+		x: &testpb.TestAllTypes{
+			OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{},
+		},
+		y: func() proto.Message {
+			m := &testpb.TestAllTypes{
+				OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{},
+			}
+			m.OptionalNestedMessage.ProtoReflect().SetUnknown(protopack.Message{
+				protopack.Tag{Number: 1000, Type: protopack.VarintType}, protopack.Varint(1),
+			}.Marshal())
+			return m
+		}(),
+		want: []string{
+			`optional_nested_message.?: "" -> "\xc0>\x01"`,
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var got []string
+			for _, e := range proto.Diff(tt.x, tt.y) {
+				got = append(got, formatEntry(e))
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Diff() mismatch (-want +got):\n%s", diff)
+			}
+			if gotEq := len(got) == 0; gotEq != proto.Equal(tt.x, tt.y) {
+				t.Errorf("Diff() reported %d differences, but Equal() = %v", len(got), !gotEq)
+			}
+		})
+	}
+}
@@ -36,6 +36,9 @@
 //     and detailed reporting of differences, see package
 //     [google.golang.org/protobuf/testing/protocmp].
 //
+//   - [Diff] reports the paths and values of the fields that differ
+//     between two messages.
+//
 //   - [Reset] clears the content of a message.
 //
 //   - [CheckInitialized] reports whether all required fields in a message are set.
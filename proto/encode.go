@@ -7,9 +7,12 @@ package proto
 import (
 	"errors"
 	"fmt"
+	"io"
+	"sort"
 
 	"google.golang.org/protobuf/encoding/protowire"
 	"google.golang.org/protobuf/internal/encoding/messageset"
+	"google.golang.org/protobuf/internal/flags"
 	"google.golang.org/protobuf/internal/order"
 	"google.golang.org/protobuf/internal/pragma"
 	"google.golang.org/protobuf/reflect/protoreflect"
@@ -23,6 +26,10 @@ import (
 // Example usage:
 //
 //	b, err := MarshalOptions{Deterministic: true}.Marshal(m)
+//
+// Setting MapKeyLess or Canonical, or setting InvalidUTF8 to any policy
+// other than UTF8Strict, disables the optimized fast-path marshaler
+// and may substantially reduce performance.
 type MarshalOptions struct {
 	pragma.NoUnkeyedLiterals
 
@@ -45,9 +52,8 @@ type MarshalOptions struct {
 	// languages. It is not guaranteed to remain stable over time. It is
 	// unstable across different builds with schema changes due to unknown
 	// fields. Users who need canonical serialization (e.g., persistent
-	// storage in a canonical form, fingerprinting, etc.) must define
-	// their own canonicalization specification and implement their own
-	// serializer rather than relying on this API.
+	// storage in a canonical form, fingerprinting, etc.) should use the
+	// Canonical option rather than relying on this API.
 	//
 	// If deterministic serialization is requested, map entries will be
 	// sorted by keys in lexographical order. This is an implementation
@@ -55,7 +61,9 @@ type MarshalOptions struct {
 	Deterministic bool
 
 	// UseCachedSize indicates that the result of a previous Size call
-	// may be reused.
+	// may be reused. Messages without generated code may support this
+	// by implementing [protoiface.SizeCacher], as dynamic messages from
+	// [google.golang.org/protobuf/types/dynamicpb] do.
 	//
 	// Setting this option asserts that:
 	//
@@ -74,6 +82,52 @@ type MarshalOptions struct {
 	// There is absolutely no guarantee that Size followed by Marshal with
 	// UseCachedSize set will perform equivalently to Marshal alone.
 	UseCachedSize bool
+
+	// MapKeyLess, if non-nil, controls the order in which map entries are
+	// serialized. It reports whether the entry with key x must be written
+	// before the entry with key y. Both keys are always of the same kind.
+	//
+	// This permits users producing content-addressed encodings to define
+	// (and later verify) their own map ordering, independent of the
+	// unspecified ordering used by Deterministic.
+	// See the performance note on MarshalOptions.
+	MapKeyLess func(x, y protoreflect.MapKey) bool
+
+	// Canonical specifies that messages are serialized in a canonical form,
+	// which depends only on the contents of the message and not on the
+	// particular build, release, or language implementation.
+	//
+	// In the canonical form, the known fields, extension fields, and unknown
+	// fields of each message are written in a single sequence sorted by field
+	// number, where unknown fields with the same number retain their relative
+	// order and are written after a known field with that number.
+	// Map entries are sorted by key, where false sorts before true, numeric
+	// keys are sorted by value, and string keys are sorted by their UTF-8 bytes,
+	// unless MapKeyLess is set. Each map entry contains both its key and value,
+	// repeated fields are packed according to the field's encoding, and the
+	// varints and lengths of known fields use the minimal encoding.
+	// The contents of each unknown field are written as they were parsed.
+	//
+	// As a result, parsing a canonical encoding with a different version of
+	// the schema and marshaling it again in canonical form reproduces the
+	// original bytes, which makes the canonical form suitable for computing
+	// signatures and hashes. See the performance note on MarshalOptions.
+	Canonical bool
+
+	// InvalidUTF8 specifies how string fields that are required to contain
+	// valid UTF-8 but do not are serialized. By default, an error is reported.
+	// See the performance note on MarshalOptions.
+	InvalidUTF8 UTF8Policy
+
+	// Concurrency, if greater than one, is the maximum number of goroutines
+	// used to size and marshal each repeated message field with many
+	// elements. The elements are divided into contiguous chunks, which are
+	// encoded concurrently and then concatenated, so that the output is
+	// identical to that of a serial marshal. This may reduce the latency of
+	// marshaling messages dominated by large repeated message fields,
+	// at the cost of additional copying. It is ignored by messages which do
+	// not support the optimized fast-path marshaler.
+	Concurrency int
 }
 
 // flags turns the specified MarshalOptions (user-facing) into
@@ -160,11 +214,159 @@ func (o MarshalOptions) MarshalAppend(b []byte, m Message) ([]byte, error) {
 	return out.Buf, err
 }
 
+// AppendField appends the wire-format encoding of a single field to b,
+// returning the result. The field is described by fd, which may be an
+// extension field, and v is its value, as returned by [protoreflect.Message.Get].
+//
+// The encoding is that of the field within a message, including its tag:
+// a repeated field is encoded as a packed or unpacked sequence of elements
+// and a map field as a sequence of entries. An empty list or map appends
+// nothing; other values are appended even if they are the default.
+//
+// AppendField permits handcrafted encoders to produce the encoding of
+// a message field by field without constructing the message itself.
+// Concatenating the encodings of the fields of a message, each appended
+// at most once, produces a valid encoding of that message, though not
+// necessarily the same bytes as [MarshalOptions.Marshal].
+// The fields of a MessageSet are encoded as MessageSet items.
+//
+// Unless AllowPartial is set, AppendField returns an error if a message
+// value of the field is missing required fields. Whether the required
+// fields of the message containing fd are appended is not checked.
+func (o MarshalOptions) AppendField(b []byte, fd protoreflect.FieldDescriptor, v protoreflect.Value) ([]byte, error) {
+	var err error
+	if messageset.IsMessageSet(fd.ContainingMessage()) {
+		if !flags.ProtoLegacy {
+			return b, protoerrors.New("no support for message_set_wire_format")
+		}
+		b, err = o.marshalMessageSetField(b, fd, v)
+	} else {
+		b, err = o.marshalField(b, fd, v)
+	}
+	if err != nil || o.AllowPartial {
+		return b, err
+	}
+	return b, checkFieldInitialized(fd, v)
+}
+
+// SizeAndMarshal returns the size in bytes of the wire-format encoding of m,
+// together with a function which appends that encoding to b.
+//
+// This permits callers to act on the size before marshaling, such as to
+// reject messages exceeding a size limit, write a length prefix,
+// or obtain a buffer of sufficient capacity, without the message being
+// traversed a second time to compute its size when it is marshaled.
+// If b has sufficient capacity, the marshal function does not allocate it.
+//
+// The marshal function reuses the sizes computed by SizeAndMarshal, as if
+// by setting [MarshalOptions.UseCachedSize]. The message must therefore not
+// be modified between the call to SizeAndMarshal and the call to the
+// returned function.
+//
+// As with [MarshalOptions.Size], the size may be larger than the number of
+// bytes appended in the case of lazily decoded messages.
+func (o MarshalOptions) SizeAndMarshal(m Message) (size int, marshal func(b []byte) ([]byte, error)) {
+	size = o.Size(m)
+	o.UseCachedSize = true
+	return size, func(b []byte) ([]byte, error) {
+		return o.MarshalAppend(b, m)
+	}
+}
+
+// MarshalTo writes the wire-format encoding of m to w,
+// returning the number of bytes written.
+//
+// Unlike [MarshalOptions.Marshal], MarshalTo does not build the encoding of
+// the entire message in a single contiguous buffer. Instead, each top-level
+// field (and each element of a non-packed repeated field) is encoded and
+// written to w separately, bounding the amount of memory held at any one time
+// to the size of the largest such field.
+//
+// The output is a valid encoding of m, but is not guaranteed to be
+// byte-for-byte identical to the output of [MarshalOptions.Marshal].
+// If w returns an error, MarshalTo returns it unchanged.
+func (o MarshalOptions) MarshalTo(w io.Writer, m Message) (int, error) {
+	// Treat nil message interface as an empty message; nothing to output.
+	if m == nil {
+		return 0, nil
+	}
+	mr := m.ProtoReflect()
+	if !o.AllowPartial {
+		// Check required fields up front to avoid writing partial output.
+		if err := checkInitialized(mr); err != nil {
+			return 0, err
+		}
+	}
+	o.AllowPartial = true
+	if o.Canonical || messageset.IsMessageSet(mr.Descriptor()) {
+		b, err := o.marshalMessage(nil, mr)
+		if err != nil {
+			return 0, err
+		}
+		return w.Write(b)
+	}
+
+	var n int
+	var b []byte
+	flush := func() error {
+		nn, err := w.Write(b)
+		n += nn
+		b = b[:0]
+		return err
+	}
+	fieldOrder := order.AnyFieldOrder
+	if o.Deterministic {
+		fieldOrder = order.LegacyFieldOrder
+	}
+	var err error
+	order.RangeFields(mr, fieldOrder, func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.IsList() && !fd.IsPacked() {
+			list := v.List()
+			wtyp := wireTypes[fd.Kind()]
+			for i, llen := 0, list.Len(); i < llen; i++ {
+				b = protowire.AppendTag(b, fd.Number(), wtyp)
+				if b, err = o.marshalSingular(b, fd, list.Get(i)); err != nil {
+					return false
+				}
+				if err = flush(); err != nil {
+					return false
+				}
+			}
+			return true
+		}
+		if b, err = o.marshalField(b, fd, v); err != nil {
+			return false
+		}
+		err = flush()
+		return err == nil
+	})
+	if err != nil {
+		return n, err
+	}
+	if u := mr.GetUnknown(); len(u) > 0 {
+		nn, err := w.Write(u)
+		return n + nn, err
+	}
+	return n, nil
+}
+
 // MarshalState returns the wire-format encoding of a message.
 //
 // This method permits fine-grained control over the marshaler.
 // Most users should use [Marshal] instead.
+//
+// The output is appended to in.Buf. The MarshalDeterministic and
+// MarshalUseCachedSize input flags enable the corresponding options.
 func (o MarshalOptions) MarshalState(in protoiface.MarshalInput) (protoiface.MarshalOutput, error) {
+	if in.Flags&protoiface.MarshalDeterministic != 0 {
+		o.Deterministic = true
+	}
+	if in.Flags&protoiface.MarshalUseCachedSize != 0 {
+		o.UseCachedSize = true
+	}
+	if in.Concurrency > 1 {
+		o.Concurrency = in.Concurrency
+	}
 	return o.marshal(in.Buf, in.Message)
 }
 
@@ -174,17 +376,19 @@ func (o MarshalOptions) MarshalState(in protoiface.MarshalInput) (protoiface.Mar
 func (o MarshalOptions) marshal(b []byte, m protoreflect.Message) (out protoiface.MarshalOutput, err error) {
 	allowPartial := o.AllowPartial
 	o.AllowPartial = true
-	if methods := protoMethods(m); methods != nil && methods.Marshal != nil &&
+	if methods := protoMethods(m); methods != nil && methods.Marshal != nil && o.MapKeyLess == nil && !o.Canonical && o.InvalidUTF8 == UTF8Strict &&
 		!(o.Deterministic && methods.Flags&protoiface.SupportMarshalDeterministic == 0) {
 		in := protoiface.MarshalInput{
-			Message: m,
-			Buf:     b,
-			Flags:   o.flags(),
+			Message:     m,
+			Buf:         b,
+			Flags:       o.flags(),
+			Concurrency: o.Concurrency,
 		}
 		if methods.Size != nil {
 			sout := methods.Size(protoiface.SizeInput{
-				Message: m,
-				Flags:   in.Flags,
+				Message:     m,
+				Flags:       in.Flags,
+				Concurrency: o.Concurrency,
 			})
 			if cap(b) < len(b)+sout.Size {
 				in.Buf = make([]byte, len(b), growcap(cap(b), len(b)+sout.Size))
@@ -214,6 +418,30 @@ func (o MarshalOptions) marshalMessage(b []byte, m protoreflect.Message) ([]byte
 	return out.Buf, err
 }
 
+// marshalMessageBytes appends the length-prefixed encoding of m.
+// The length is taken from the cached size of m if one is available,
+// in which case it is an error for the encoding to have a different size.
+func (o MarshalOptions) marshalMessageBytes(b []byte, m protoreflect.Message) ([]byte, error) {
+	if size, ok := o.cachedSize(m); ok {
+		b = protowire.AppendVarint(b, uint64(size))
+		start := len(b)
+		b, err := o.marshalMessage(b, m)
+		if err != nil {
+			return b, err
+		}
+		if measured := len(b) - start; measured != size {
+			return b, protoerrors.MismatchedSizeCalculation(size, measured)
+		}
+		return b, nil
+	}
+	b, pos := appendSpeculativeLength(b)
+	b, err := o.marshalMessage(b, m)
+	if err != nil {
+		return b, err
+	}
+	return finishSpeculativeLength(b, pos), nil
+}
+
 // growcap scales up the capacity of a slice.
 //
 // Given a slice with a current capacity of oldcap and a desired
@@ -244,6 +472,9 @@ func (o MarshalOptions) marshalMessageSlow(b []byte, m protoreflect.Message) ([]
 	if messageset.IsMessageSet(m.Descriptor()) {
 		return o.marshalMessageSet(b, m)
 	}
+	if o.Canonical {
+		return o.marshalMessageCanonical(b, m)
+	}
 	fieldOrder := order.AnyFieldOrder
 	if o.Deterministic {
 		// TODO: This should use a more natural ordering like NumberFieldOrder,
@@ -263,6 +494,52 @@ func (o MarshalOptions) marshalMessageSlow(b []byte, m protoreflect.Message) ([]
 	return b, nil
 }
 
+// marshalMessageCanonical marshals the known and unknown fields of m
+// in a single sequence sorted by field number.
+func (o MarshalOptions) marshalMessageCanonical(b []byte, m protoreflect.Message) ([]byte, error) {
+	unknown := splitUnknown(m.GetUnknown())
+	var err error
+	order.RangeFields(m, order.NumberFieldOrder, func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		for len(unknown) > 0 && unknown[0].num < fd.Number() {
+			b = append(b, unknown[0].raw...)
+			unknown = unknown[1:]
+		}
+		b, err = o.marshalField(b, fd, v)
+		return err == nil
+	})
+	if err != nil {
+		return b, err
+	}
+	for _, f := range unknown {
+		b = append(b, f.raw...)
+	}
+	return b, nil
+}
+
+type unknownField struct {
+	num protowire.Number
+	raw []byte
+}
+
+// splitUnknown splits unknown fields into individual fields, stably sorted
+// by field number. Any malformed trailing data is kept as a final field.
+func splitUnknown(b []byte) []unknownField {
+	var fields []unknownField
+	for len(b) > 0 {
+		num, _, n := protowire.ConsumeField(b)
+		if n < 0 {
+			fields = append(fields, unknownField{protowire.MaxValidNumber + 1, b})
+			break
+		}
+		fields = append(fields, unknownField{num, b[:n]})
+		b = b[n:]
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i].num < fields[j].num
+	})
+	return fields
+}
+
 func (o MarshalOptions) marshalField(b []byte, fd protoreflect.FieldDescriptor, value protoreflect.Value) ([]byte, error) {
 	switch {
 	case fd.IsList():
@@ -306,7 +583,10 @@ func (o MarshalOptions) marshalMap(b []byte, fd protoreflect.FieldDescriptor, ma
 	keyf := fd.MapKey()
 	valf := fd.MapValue()
 	keyOrder := order.AnyKeyOrder
-	if o.Deterministic {
+	switch {
+	case o.MapKeyLess != nil:
+		keyOrder = order.KeyOrder(o.MapKeyLess)
+	case o.Deterministic, o.Canonical:
 		keyOrder = order.GenericKeyOrder
 	}
 	var err error
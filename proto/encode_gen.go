@@ -8,11 +8,9 @@ package proto
 
 import (
 	"math"
-	"unicode/utf8"
 
 	"google.golang.org/protobuf/encoding/protowire"
 	"google.golang.org/protobuf/internal/errors"
-	"google.golang.org/protobuf/internal/strs"
 	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
@@ -68,21 +66,19 @@ func (o MarshalOptions) marshalSingular(b []byte, fd protoreflect.FieldDescripto
 	case protoreflect.DoubleKind:
 		b = protowire.AppendFixed64(b, math.Float64bits(v.Float()))
 	case protoreflect.StringKind:
-		if strs.EnforceUTF8(fd) && !utf8.ValidString(v.String()) {
-			return b, errors.InvalidUTF8(string(fd.FullName()))
+		s, err := o.checkUTF8(v.String(), fd)
+		if err != nil {
+			return b, err
 		}
-		b = protowire.AppendString(b, v.String())
+		b = protowire.AppendString(b, s)
 	case protoreflect.BytesKind:
 		b = protowire.AppendBytes(b, v.Bytes())
 	case protoreflect.MessageKind:
-		var pos int
 		var err error
-		b, pos = appendSpeculativeLength(b)
-		b, err = o.marshalMessage(b, v.Message())
+		b, err = o.marshalMessageBytes(b, v.Message())
 		if err != nil {
 			return b, err
 		}
-		b = finishSpeculativeLength(b, pos)
 	case protoreflect.GroupKind:
 		var err error
 		b, err = o.marshalMessage(b, v.Message())
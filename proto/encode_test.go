@@ -16,13 +16,17 @@ import (
 
 	"google.golang.org/protobuf/encoding/prototext"
 	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/internal/encoding/messageset"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/testing/protopack"
 	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
 
 	orderpb "google.golang.org/protobuf/internal/testprotos/order"
 	testpb "google.golang.org/protobuf/internal/testprotos/test"
 	test3pb "google.golang.org/protobuf/internal/testprotos/test3"
+	testopaquepb "google.golang.org/protobuf/internal/testprotos/testeditions/testeditions_opaque"
 )
 
 func TestEncode(t *testing.T) {
@@ -124,6 +128,174 @@ func TestEncodeAppend(t *testing.T) {
 	}
 }
 
+func TestEncodeAppendField(t *testing.T) {
+	for _, test := range testValidMessages {
+		for _, want := range test.decodeTo {
+			t.Run(fmt.Sprintf("%s (%T)", test.desc, want), func(t *testing.T) {
+				if !want.ProtoReflect().IsValid() {
+					return
+				}
+				opts := proto.MarshalOptions{AllowPartial: true}
+				var b []byte
+				var err error
+				want.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+					b, err = opts.AppendField(b, fd, v)
+					return err == nil
+				})
+				if err != nil {
+					t.Fatalf("AppendField() error: %v", err)
+				}
+				if unknown := want.ProtoReflect().GetUnknown(); messageset.IsMessageSet(want.ProtoReflect().Descriptor()) {
+					b, err = messageset.AppendUnknown(b, unknown)
+					if err != nil {
+						t.Fatal(err)
+					}
+				} else {
+					b = append(b, unknown...)
+				}
+
+				got := want.ProtoReflect().New().Interface()
+				uopts := proto.UnmarshalOptions{AllowPartial: true}
+				if err := uopts.Unmarshal(b, got); err != nil {
+					t.Fatalf("Unmarshal() error: %v", err)
+				}
+				if !proto.Equal(got, want) {
+					t.Errorf("Unmarshal() of fields from AppendField returned unexpected result; got:\n%v\nwant:\n%v", prototext.Format(got), prototext.Format(want))
+				}
+			})
+		}
+	}
+
+	m := &test3pb.TestAllTypes{
+		SingularInt32:  0,
+		RepeatedInt32:  []int32{1, 2},
+		MapInt32Int32:  map[int32]int32{3: 4},
+		SingularString: "x",
+	}
+	fds := m.ProtoReflect().Descriptor().Fields()
+	for _, tt := range []struct {
+		name protoreflect.Name
+		want protopack.Message
+	}{{
+		name: "singular_int32",
+		want: protopack.Message{protopack.Tag{81, protopack.VarintType}, protopack.Varint(0)},
+	}, {
+		name: "singular_string",
+		want: protopack.Message{protopack.Tag{94, protopack.BytesType}, protopack.String("x")},
+	}, {
+		name: "repeated_int32",
+		want: protopack.Message{protopack.Tag{31, protopack.BytesType}, protopack.LengthPrefix{protopack.Varint(1), protopack.Varint(2)}},
+	}, {
+		name: "map_int32_int32",
+		want: protopack.Message{protopack.Tag{56, protopack.BytesType}, protopack.LengthPrefix(protopack.Message{
+			protopack.Tag{1, protopack.VarintType}, protopack.Varint(3),
+			protopack.Tag{2, protopack.VarintType}, protopack.Varint(4),
+		})},
+	}} {
+		fd := fds.ByName(tt.name)
+		got, err := proto.MarshalOptions{}.AppendField([]byte("prefix"), fd, m.ProtoReflect().Get(fd))
+		if err != nil {
+			t.Fatalf("AppendField(%v) error: %v", tt.name, err)
+		}
+		if want := append([]byte("prefix"), tt.want.Marshal()...); !bytes.Equal(got, want) {
+			t.Errorf("AppendField(%v) = %x, want %x", tt.name, got, want)
+		}
+	}
+
+	partial := &testpb.TestRequiredForeign{
+		OptionalMessage: &testpb.TestRequired{},
+		RepeatedMessage: []*testpb.TestRequired{{}},
+		MapMessage:      map[int32]*testpb.TestRequired{1: {}},
+	}
+	partial.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if _, err := (proto.MarshalOptions{}).AppendField(nil, fd, v); err == nil {
+			t.Errorf("AppendField(%v) with missing required fields succeeded, want error", fd.Name())
+		}
+		if _, err := (proto.MarshalOptions{AllowPartial: true}).AppendField(nil, fd, v); err != nil {
+			t.Errorf("AppendField(%v) with AllowPartial error: %v", fd.Name(), err)
+		}
+		return true
+	})
+}
+
+func TestEncodeSizeAndMarshal(t *testing.T) {
+	for _, test := range testValidMessages {
+		for _, m := range test.decodeTo {
+			t.Run(fmt.Sprintf("%s (%T)", test.desc, m), func(t *testing.T) {
+				opts := proto.MarshalOptions{Deterministic: true, AllowPartial: test.partial}
+				want, err := opts.Marshal(m)
+				if err != nil {
+					t.Fatalf("Marshal() error: %v", err)
+				}
+				size, marshal := opts.SizeAndMarshal(m)
+				if size != len(want) {
+					t.Errorf("SizeAndMarshal() size = %v, want %v", size, len(want))
+				}
+				prefix := []byte("prefix")
+				b := make([]byte, len(prefix), len(prefix)+size)
+				copy(b, prefix)
+				got, err := marshal(b)
+				if err != nil {
+					t.Fatalf("SizeAndMarshal() marshal error: %v", err)
+				}
+				if !bytes.Equal(got[:len(prefix)], prefix) || !bytes.Equal(got[len(prefix):], want) {
+					t.Errorf("SizeAndMarshal() marshal = %x, want %x followed by %x", got, prefix, want)
+				}
+				if len(got) > 0 && &got[0] != &b[0] {
+					t.Errorf("SizeAndMarshal() marshal reallocated buffer of sufficient capacity")
+				}
+			})
+		}
+	}
+}
+
+func TestEncodeTo(t *testing.T) {
+	for _, test := range testValidMessages {
+		for _, want := range test.decodeTo {
+			t.Run(fmt.Sprintf("%s (%T)", test.desc, want), func(t *testing.T) {
+				opts := proto.MarshalOptions{
+					AllowPartial: test.partial,
+				}
+				var buf bytes.Buffer
+				n, err := opts.MarshalTo(&buf, want)
+				if err != nil {
+					t.Fatalf("MarshalTo error: %v\nMessage:\n%v", err, prototext.Format(want))
+				}
+				if n != buf.Len() {
+					t.Errorf("MarshalTo returned %v bytes written, but wrote %v", n, buf.Len())
+				}
+				if size := proto.Size(want); size != n {
+					t.Errorf("Size and MarshalTo disagree: Size(m)=%v; MarshalTo(m)=%v\nMessage:\n%v", size, n, prototext.Format(want))
+				}
+
+				got := want.ProtoReflect().New().Interface()
+				uopts := proto.UnmarshalOptions{
+					AllowPartial: test.partial,
+				}
+				if err := uopts.Unmarshal(buf.Bytes(), got); err != nil {
+					t.Errorf("Unmarshal error: %v\nMessage:\n%v", err, prototext.Format(want))
+					return
+				}
+				if !proto.Equal(got, want) && got.ProtoReflect().IsValid() && want.ProtoReflect().IsValid() {
+					t.Errorf("Unmarshal returned unexpected result; got:\n%v\nwant:\n%v", prototext.Format(got), prototext.Format(want))
+				}
+			})
+		}
+	}
+}
+
+type errWriter struct{ err error }
+
+func (w errWriter) Write([]byte) (int, error) { return 0, w.err }
+
+func TestEncodeToWriterError(t *testing.T) {
+	wantErr := errors.New("write failed")
+	m := &test3pb.TestAllTypes{SingularString: "value"}
+	if _, err := (proto.MarshalOptions{}).MarshalTo(errWriter{wantErr}, m); err != wantErr {
+		t.Errorf("MarshalTo error = %v, want %v", err, wantErr)
+	}
+}
+
 func TestEncodeInvalidMessages(t *testing.T) {
 	for _, test := range testInvalidMessages {
 		for _, m := range test.decodeTo {
@@ -232,6 +404,157 @@ func TestEncodeOrder(t *testing.T) {
 	}
 }
 
+func TestEncodeMapKeyLess(t *testing.T) {
+	m := &test3pb.TestAllTypes{
+		MapInt32Int32: map[int32]int32{1: 1, 2: 2, 3: 3, 4: 4, 5: 5},
+		SingularNestedMessage: &test3pb.TestAllTypes_NestedMessage{
+			Corecursive: &test3pb.TestAllTypes{
+				MapInt32Int32: map[int32]int32{6: 6, 7: 7, 8: 8},
+			},
+		},
+	}
+	descending := func(x, y protoreflect.MapKey) bool {
+		return x.Int() > y.Int()
+	}
+	b, err := proto.MarshalOptions{MapKeyLess: descending}.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// mapKeys returns the keys of map_int32_int32 in the order they appear
+	// and the contents of the given submessage field.
+	mapKeys := func(b []byte, sub protowire.Number) (keys []int64, subb []byte) {
+		for len(b) > 0 {
+			num, typ, n := protowire.ConsumeTag(b)
+			b = b[n:]
+			if typ != protowire.BytesType {
+				b = b[protowire.ConsumeFieldValue(num, typ, b):]
+				continue
+			}
+			v, n := protowire.ConsumeBytes(b)
+			b = b[n:]
+			switch num {
+			case 56: // map_int32_int32
+				_, _, n := protowire.ConsumeTag(v)
+				k, _ := protowire.ConsumeVarint(v[n:])
+				keys = append(keys, int64(k))
+			case sub:
+				subb = v
+			}
+		}
+		return keys, subb
+	}
+	got, nested := mapKeys(b, 98) // singular_nested_message
+	if want := []int64{5, 4, 3, 2, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("map keys serialized in order %v, want %v", got, want)
+	}
+	// Verify the order is also applied to nested messages.
+	_, corecursive := mapKeys(nested, 2) // corecursive
+	got, _ = mapKeys(corecursive, 0)
+	if want := []int64{8, 7, 6}; !reflect.DeepEqual(got, want) {
+		t.Errorf("nested map keys serialized in order %v, want %v", got, want)
+	}
+
+	m2 := &test3pb.TestAllTypes{}
+	if err := proto.Unmarshal(b, m2); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(m, m2) {
+		t.Errorf("round-trip mismatch:\ngot:  %v\nwant: %v", m2, m)
+	}
+}
+
+func TestEncodeCanonical(t *testing.T) {
+	m := &orderpb.Message{
+		Field_1:  proto.String("one"),
+		Field_2:  proto.String("two"),
+		Field_20: proto.String("twenty"),
+		Oneof_1:  &orderpb.Message_Field_10{"ten"},
+	}
+	proto.SetExtension(m, orderpb.E_Field_30, "thirty")
+	proto.SetExtension(m, orderpb.E_Field_31, "thirty-one")
+	proto.SetExtension(m, orderpb.E_Field_32, "thirty-two")
+	m.ProtoReflect().SetUnknown(protopack.Message{
+		protopack.Tag{25, protopack.VarintType}, protopack.Varint(1),
+		protopack.Tag{5, protopack.VarintType}, protopack.Varint(2),
+		protopack.Tag{25, protopack.VarintType}, protopack.Varint(3),
+	}.Marshal())
+	want := []protoreflect.FieldNumber{1, 2, 5, 10, 20, 25, 25, 30, 31, 32}
+
+	b, err := proto.MarshalOptions{Canonical: true}.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []protoreflect.FieldNumber
+	for b := b; len(b) > 0; {
+		num, _, n := protowire.ConsumeField(b)
+		if n < 0 {
+			t.Fatal(protowire.ParseError(n))
+		}
+		b = b[n:]
+		got = append(got, num)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected field marshal order:\ngot:  %v\nwant: %v\nmessage:\n%v", got, want, m)
+	}
+	// Unknown fields with the same number retain their relative order.
+	var got25 []uint64
+	for b := b; len(b) > 0; {
+		num, typ, n := protowire.ConsumeTag(b)
+		b = b[n:]
+		if num == 25 {
+			v, _ := protowire.ConsumeVarint(b)
+			got25 = append(got25, v)
+		}
+		b = b[protowire.ConsumeFieldValue(num, typ, b):]
+	}
+	if want := []uint64{1, 3}; !reflect.DeepEqual(got25, want) {
+		t.Errorf("unknown field 25 values = %v, want %v", got25, want)
+	}
+
+	// Re-encoding canonical data parsed with a different schema
+	// must not depend on whether fields are known.
+	m3 := &test3pb.TestAllTypes{
+		SingularInt32:  1,
+		SingularString: "x",
+		RepeatedInt32:  []int32{1, 2, 3},
+		MapStringString: map[string]string{
+			"c": "3", "a": "1", "b": "2", "d": "4",
+		},
+		SingularNestedMessage: &test3pb.TestAllTypes_NestedMessage{A: 5},
+	}
+	b1, err := proto.MarshalOptions{Canonical: true}.Marshal(m3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Scramble the field order by marshaling the map field first.
+	canonical := proto.MarshalOptions{Canonical: true}
+	b2, err := canonical.Marshal(&test3pb.TestAllTypes{MapStringString: m3.MapStringString})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b3, err := canonical.Marshal(&test3pb.TestAllTypes{
+		SingularInt32:         m3.SingularInt32,
+		SingularString:        m3.SingularString,
+		RepeatedInt32:         m3.RepeatedInt32,
+		SingularNestedMessage: m3.SingularNestedMessage,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	empty := &emptypb.Empty{}
+	if err := proto.Unmarshal(append(b2, b3...), empty); err != nil {
+		t.Fatal(err)
+	}
+	b4, err := canonical.Marshal(empty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b1, b4) {
+		t.Errorf("canonical encoding with unknown fields differs:\ngot:  %x\nwant: %x", b4, b1)
+	}
+}
+
 func TestEncodeLarge(t *testing.T) {
 	// Encode/decode a message large enough to overflow a 32-bit size cache.
 	t.Skip("too slow and memory-hungry to run all the time")
@@ -256,6 +579,59 @@ func TestEncodeLarge(t *testing.T) {
 	}
 }
 
+func TestEncodeConcurrency(t *testing.T) {
+	const n = 1000
+	var open []*testpb.TestAllTypes_NestedMessage
+	var opaque []*testopaquepb.TestAllTypes_NestedMessage
+	for i := 0; i < n; i++ {
+		open = append(open, &testpb.TestAllTypes_NestedMessage{
+			A: proto.Int32(int32(i)),
+			Corecursive: &testpb.TestAllTypes{
+				MapInt32Int32: map[int32]int32{int32(i): 1, int32(-i): 2, 3: 3},
+			},
+		})
+		opaque = append(opaque, testopaquepb.TestAllTypes_NestedMessage_builder{
+			A: proto.Int32(int32(i)),
+		}.Build())
+	}
+	for _, m := range []proto.Message{
+		&testpb.TestAllTypes{RepeatedNestedMessage: open},
+		testopaquepb.TestAllTypes_builder{RepeatedNestedMessage: opaque}.Build(),
+	} {
+		for _, concurrency := range []int{2, 3, 8} {
+			opts := proto.MarshalOptions{Deterministic: true}
+			want, err := opts.Marshal(m)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			opts.Concurrency = concurrency
+			got, err := opts.Marshal(m)
+			if err != nil {
+				t.Fatalf("Marshal with Concurrency %v: %v", concurrency, err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%T: Marshal with Concurrency %v differs from serial Marshal", m, concurrency)
+			}
+			if got, want := opts.Size(m), len(want); got != want {
+				t.Errorf("%T: Size with Concurrency %v = %v, want %v", m, concurrency, got, want)
+			}
+		}
+	}
+
+	// An error in any element is reported as it is by a serial marshal.
+	var required []*testpb.TestRequired
+	for i := 0; i < n; i++ {
+		required = append(required, &testpb.TestRequired{RequiredField: proto.Int32(1)})
+	}
+	required[n/2].RequiredField = nil
+	m := &testpb.TestRequiredForeign{RepeatedMessage: required}
+	_, want := proto.Marshal(m)
+	_, got := proto.MarshalOptions{Concurrency: 4}.Marshal(m)
+	if got == nil || want == nil || got.Error() != want.Error() {
+		t.Errorf("Marshal with Concurrency 4 = %v, want %v", got, want)
+	}
+}
+
 // TestEncodeEmpty tests for boundary conditions when producing an empty output.
 // These tests are not necessarily a statement of proper behavior,
 // but exist to detect accidental changes in behavior.
@@ -5,8 +5,11 @@
 package proto
 
 import (
+	"math"
 	"reflect"
 
+	"google.golang.org/protobuf/internal/pragma"
+	"google.golang.org/protobuf/internal/rawfields"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/runtime/protoiface"
 )
@@ -64,3 +67,123 @@ func Equal(x, y Message) bool {
 	vy := protoreflect.ValueOfMessage(my)
 	return vx.Equal(vy)
 }
+
+// EqualOptions configures the comparison performed by [EqualOptions.Equal].
+//
+// The zero value compares messages identically to [Equal].
+type EqualOptions struct {
+	pragma.NoUnkeyedLiterals
+
+	// FloatFraction and FloatMargin specify a tolerance for comparing
+	// floating-point fields. Two finite values x and y are considered equal if
+	//
+	//	|x-y| ≤ max(FloatMargin, FloatFraction*min(|x|, |y|))
+	//
+	// Infinities are only equal to infinities of the same sign,
+	// and a NaN is equal to another NaN, as with [Equal].
+	// Both values must be non-negative.
+	FloatFraction float64
+	FloatMargin   float64
+}
+
+// Equal reports whether two messages are equal according to the options.
+// See [Equal] for the rules used to compare messages.
+func (o EqualOptions) Equal(x, y Message) bool {
+	if o.FloatFraction < 0 || o.FloatMargin < 0 {
+		panic("proto: EqualOptions: FloatFraction and FloatMargin must be non-negative")
+	}
+	if o.FloatFraction == 0 && o.FloatMargin == 0 {
+		return Equal(x, y)
+	}
+	if x == nil || y == nil {
+		return x == nil && y == nil
+	}
+	if reflect.TypeOf(x).Kind() == reflect.Ptr && x == y {
+		return true
+	}
+	mx := x.ProtoReflect()
+	my := y.ProtoReflect()
+	if mx.IsValid() != my.IsValid() {
+		return false
+	}
+	return o.equalMessage(mx, my)
+}
+
+func (o EqualOptions) equalMessage(mx, my protoreflect.Message) bool {
+	if mx.Descriptor() != my.Descriptor() {
+		return false
+	}
+
+	nx := 0
+	equal := true
+	mx.Range(func(fd protoreflect.FieldDescriptor, vx protoreflect.Value) bool {
+		nx++
+		equal = my.Has(fd) && o.equalField(fd, vx, my.Get(fd))
+		return equal
+	})
+	if !equal {
+		return false
+	}
+	ny := 0
+	my.Range(func(protoreflect.FieldDescriptor, protoreflect.Value) bool {
+		ny++
+		return true
+	})
+	if nx != ny {
+		return false
+	}
+
+	return rawfields.Equal(mx.GetUnknown(), my.GetUnknown())
+}
+
+func (o EqualOptions) equalField(fd protoreflect.FieldDescriptor, x, y protoreflect.Value) bool {
+	switch {
+	case fd.IsList():
+		lx, ly := x.List(), y.List()
+		if lx.Len() != ly.Len() {
+			return false
+		}
+		for i := lx.Len() - 1; i >= 0; i-- {
+			if !o.equalSingular(fd, lx.Get(i), ly.Get(i)) {
+				return false
+			}
+		}
+		return true
+	case fd.IsMap():
+		mx, my := x.Map(), y.Map()
+		if mx.Len() != my.Len() {
+			return false
+		}
+		vfd := fd.MapValue()
+		equal := true
+		mx.Range(func(k protoreflect.MapKey, vx protoreflect.Value) bool {
+			equal = my.Has(k) && o.equalSingular(vfd, vx, my.Get(k))
+			return equal
+		})
+		return equal
+	default:
+		return o.equalSingular(fd, x, y)
+	}
+}
+
+func (o EqualOptions) equalSingular(fd protoreflect.FieldDescriptor, x, y protoreflect.Value) bool {
+	switch fd.Kind() {
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return o.equalFloat(x.Float(), y.Float())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return o.equalMessage(x.Message(), y.Message())
+	default:
+		return x.Equal(y)
+	}
+}
+
+func (o EqualOptions) equalFloat(x, y float64) bool {
+	switch {
+	case math.IsNaN(x) || math.IsNaN(y):
+		return math.IsNaN(x) && math.IsNaN(y)
+	case math.IsInf(x, 0) || math.IsInf(y, 0):
+		return x == y
+	}
+	tol := o.FloatFraction * math.Min(math.Abs(x), math.Abs(y))
+	return math.Abs(x-y) <= math.Max(o.FloatMargin, tol)
+}
@@ -7,6 +7,7 @@ package proto_test
 import (
 	"fmt"
 	"math"
+	"strconv"
 	"testing"
 
 	"google.golang.org/protobuf/encoding/prototext"
@@ -856,6 +857,45 @@ func TestEqual(t *testing.T) {
 		allTypesNoExt...,
 	))...)
 
+	tests = append(tests, makeTest(test{
+		desc: "MapsTypes",
+		eq:   true,
+	}, makeXY(
+		protobuild.Message{"map_int32_double": map[int32]float64{1: math.NaN(), 2: 2}},
+		protobuild.Message{"map_int32_double": map[int32]float64{1: math.NaN(), 2: 2}},
+		allTypesNoExt...,
+	))...)
+
+	tests = append(tests, makeTest(test{
+		desc: "MapsTypes",
+		eq:   true,
+	}, makeXY(
+		protobuild.Message{"map_string_bytes": map[string][]byte{"a": nil}},
+		protobuild.Message{"map_string_bytes": map[string][]byte{"a": {}}},
+		allTypesNoExt...,
+	))...)
+
+	tests = append(tests, makeTest(test{
+		desc: "MapsTypes",
+		eq:   true,
+	}, makeXY(
+		protobuild.Message{"map_string_nested_message": map[string]protobuild.Message{
+			"a": {"a": int32(1)},
+			"b": {},
+		}},
+		protobuild.Message{"map_string_nested_message": map[string]protobuild.Message{
+			"a": {"a": int32(1)},
+			"b": {},
+		}},
+		allTypesNoExt...,
+	))...)
+
+	tests = append(tests, makeTest(test{desc: "MapsTypes"}, makeXY(
+		protobuild.Message{"map_string_string": map[string]string{"a": "b"}},
+		protobuild.Message{"map_string_string": map[string]string{"c": "b"}},
+		allTypesNoExt...,
+	))...)
+
 	// Unknown fields.
 
 	tests = append(tests, makeTest(test{desc: "Unknown"}, makeXY(
@@ -908,6 +948,92 @@ func TestEqual(t *testing.T) {
 			if eq := proto.Equal(tt.x, tt.y); eq != tt.eq {
 				t.Errorf("Equal(x, y) = %v, want %v\n==== x ====\n%v==== y ====\n%v", eq, tt.eq, prototext.Format(tt.x), prototext.Format(tt.y))
 			}
+			// A negligible tolerance forces the reflective implementation
+			// of EqualOptions, which must agree with Equal.
+			opts := proto.EqualOptions{FloatMargin: math.SmallestNonzeroFloat64}
+			if eq := opts.Equal(tt.x, tt.y); eq != tt.eq {
+				t.Errorf("EqualOptions.Equal(x, y) = %v, want %v\n==== x ====\n%v==== y ====\n%v", eq, tt.eq, prototext.Format(tt.x), prototext.Format(tt.y))
+			}
+		})
+	}
+}
+
+func TestEqualOptions(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts proto.EqualOptions
+		x, y proto.Message
+		eq   bool
+	}{{
+		desc: "within margin",
+		opts: proto.EqualOptions{FloatMargin: 0.01},
+		x:    &testpb.TestAllTypes{OptionalDouble: proto.Float64(1.0)},
+		y:    &testpb.TestAllTypes{OptionalDouble: proto.Float64(1.005)},
+		eq:   true,
+	}, {
+		desc: "outside margin",
+		opts: proto.EqualOptions{FloatMargin: 0.01},
+		x:    &testpb.TestAllTypes{OptionalDouble: proto.Float64(1.0)},
+		y:    &testpb.TestAllTypes{OptionalDouble: proto.Float64(1.02)},
+		eq:   false,
+	}, {
+		desc: "within fraction",
+		opts: proto.EqualOptions{FloatFraction: 0.01},
+		x:    &testpb.TestAllTypes{OptionalFloat: proto.Float32(1000)},
+		y:    &testpb.TestAllTypes{OptionalFloat: proto.Float32(1005)},
+		eq:   true,
+	}, {
+		desc: "outside fraction",
+		opts: proto.EqualOptions{FloatFraction: 0.01},
+		x:    &testpb.TestAllTypes{OptionalFloat: proto.Float32(1000)},
+		y:    &testpb.TestAllTypes{OptionalFloat: proto.Float32(1020)},
+		eq:   false,
+	}, {
+		desc: "repeated",
+		opts: proto.EqualOptions{FloatMargin: 0.01},
+		x:    &testpb.TestAllTypes{RepeatedDouble: []float64{1, 2, 3}},
+		y:    &testpb.TestAllTypes{RepeatedDouble: []float64{1.001, 2.001, 2.999}},
+		eq:   true,
+	}, {
+		desc: "map",
+		opts: proto.EqualOptions{FloatMargin: 0.01},
+		x:    &testpb.TestAllTypes{MapInt32Double: map[int32]float64{1: 1}},
+		y:    &testpb.TestAllTypes{MapInt32Double: map[int32]float64{1: 1.001}},
+		eq:   true,
+	}, {
+		desc: "nested",
+		opts: proto.EqualOptions{FloatMargin: 0.01},
+		x: &testpb.TestAllTypes{OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{
+			Corecursive: &testpb.TestAllTypes{OptionalDouble: proto.Float64(1)},
+		}},
+		y: &testpb.TestAllTypes{OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{
+			Corecursive: &testpb.TestAllTypes{OptionalDouble: proto.Float64(1.001)},
+		}},
+		eq: true,
+	}, {
+		desc: "NaN",
+		opts: proto.EqualOptions{FloatMargin: 0.01},
+		x:    &testpb.TestAllTypes{OptionalDouble: proto.Float64(math.NaN())},
+		y:    &testpb.TestAllTypes{OptionalDouble: proto.Float64(math.NaN())},
+		eq:   true,
+	}, {
+		desc: "infinities",
+		opts: proto.EqualOptions{FloatMargin: math.MaxFloat64},
+		x:    &testpb.TestAllTypes{OptionalDouble: proto.Float64(math.Inf(+1))},
+		y:    &testpb.TestAllTypes{OptionalDouble: proto.Float64(math.Inf(-1))},
+		eq:   false,
+	}, {
+		desc: "other fields still compared exactly",
+		opts: proto.EqualOptions{FloatMargin: 10},
+		x:    &testpb.TestAllTypes{OptionalInt32: proto.Int32(1)},
+		y:    &testpb.TestAllTypes{OptionalInt32: proto.Int32(2)},
+		eq:   false,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if eq := tt.opts.Equal(tt.x, tt.y); eq != tt.eq {
+				t.Errorf("EqualOptions.Equal(x, y) = %v, want %v\n==== x ====\n%v==== y ====\n%v", eq, tt.eq, prototext.Format(tt.x), prototext.Format(tt.y))
+			}
 		})
 	}
 }
@@ -966,6 +1092,31 @@ func BenchmarkEqualWithLargeEmpty(b *testing.B) {
 	}
 }
 
+func BenchmarkEqualWithMaps(b *testing.B) {
+	b.ReportAllocs()
+	makeMaps := func() *testpb.TestAllTypes {
+		m := &testpb.TestAllTypes{
+			MapStringString:        map[string]string{},
+			MapInt32Int32:          map[int32]int32{},
+			MapStringNestedMessage: map[string]*testpb.TestAllTypes_NestedMessage{},
+		}
+		for i := 0; i < 100; i++ {
+			s := strconv.Itoa(i)
+			m.MapStringString[s] = s
+			m.MapInt32Int32[int32(i)] = int32(i)
+			m.MapStringNestedMessage[s] = &testpb.TestAllTypes_NestedMessage{A: proto.Int32(int32(i))}
+		}
+		return m
+	}
+	x := makeMaps()
+	y := makeMaps()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		proto.Equal(x, y)
+	}
+}
+
 func makeNested(depth int) *testpb.TestAllTypes {
 	if depth <= 0 {
 		return nil
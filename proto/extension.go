@@ -5,6 +5,8 @@
 package proto
 
 import (
+	"fmt"
+
 	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
@@ -35,6 +37,27 @@ func ClearExtension(m Message, xt protoreflect.ExtensionType) {
 	m.ProtoReflect().Clear(xt.TypeDescriptor())
 }
 
+// ClearAllExtensions clears every populated extension field in m,
+// such as to strip extensions from a message before it is sent elsewhere.
+// It does not clear the extension fields of nested messages, nor unknown
+// fields, which may hold extension fields whose types were not resolved
+// when m was unmarshaled.
+// It does nothing if m is nil or invalid.
+func ClearAllExtensions(m Message) {
+	// Treat nil message interface as an empty message; nothing to clear.
+	if m == nil {
+		return
+	}
+
+	mr := m.ProtoReflect()
+	mr.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if fd.IsExtension() {
+			mr.Clear(fd)
+		}
+		return true
+	})
+}
+
 // GetExtension retrieves the value for an extension field.
 // If the field is unpopulated, it returns the default value for
 // scalars and an immutable, empty value for lists or messages.
@@ -144,6 +167,33 @@ func SetExtension(m Message, xt protoreflect.ExtensionType, v any) {
 	m.ProtoReflect().Set(xd, pv)
 }
 
+// GetExtensionT is a generic variant of [GetExtension], which returns
+// the value of the extension field as a T. The ok result reports whether
+// the value is of type T, as with a type assertion; if it is not,
+// the zero value of T is returned. The value is returned regardless of
+// whether the field is populated, as [GetExtension] does.
+//
+// For example:
+//
+//	mm, ok := proto.GetExtensionT[*foopb.MyMessage](m, foopb.E_MyExtension)
+func GetExtensionT[T any](m Message, xt protoreflect.ExtensionType) (v T, ok bool) {
+	v, ok = GetExtension(m, xt).(T)
+	return v, ok
+}
+
+// SetExtensionT is a generic variant of [SetExtension], which stores v
+// as the value of the extension field. It panics if T is not the Go type
+// of the values of xt, as listed for [SetExtension], or an interface
+// implemented by that type, in addition to the conditions under which
+// [SetExtension] panics.
+func SetExtensionT[T any](m Message, xt protoreflect.ExtensionType, v T) {
+	if _, ok := xt.InterfaceOf(xt.Zero()).(T); !ok {
+		typeName := fmt.Sprintf("%T", (*T)(nil))[len("*"):]
+		panic(fmt.Sprintf("invalid type %v for extension %v", typeName, xt.TypeDescriptor().FullName()))
+	}
+	SetExtension(m, xt, v)
+}
+
 // RangeExtensions iterates over every populated extension field in m in an
 // undefined order, calling f for each extension type and value encountered.
 // It returns immediately if f returns false.
@@ -17,6 +17,7 @@ import (
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/runtime/protoimpl"
 	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/dynamicpb"
 
 	extpb "google.golang.org/protobuf/internal/testprotos/examples/ext"
 	legacy1pb "google.golang.org/protobuf/internal/testprotos/legacy/proto2_20160225_2fc053c5"
@@ -351,6 +352,81 @@ func TestExtensionRanger(t *testing.T) {
 	}
 }
 
+func TestExtensionGeneric(t *testing.T) {
+	m := &testpb.TestAllExtensions{}
+	proto.SetExtensionT(m, testpb.E_OptionalInt32, int32(5))
+	proto.SetExtensionT(m, testpb.E_RepeatedInt32, []int32{1, 2})
+	proto.SetExtensionT(m, testpb.E_OptionalNestedEnum, testpb.TestAllTypes_BAZ)
+	proto.SetExtensionT[proto.Message](m, testpb.E_OptionalNestedMessage, &testpb.TestAllExtensions_NestedMessage{A: proto.Int32(1)})
+
+	if got, ok := proto.GetExtensionT[int32](m, testpb.E_OptionalInt32); !ok || got != 5 {
+		t.Errorf("GetExtensionT[int32](E_OptionalInt32) = %v, %v; want 5, true", got, ok)
+	}
+	if got, ok := proto.GetExtensionT[[]int32](m, testpb.E_RepeatedInt32); !ok || !cmp.Equal(got, []int32{1, 2}) {
+		t.Errorf("GetExtensionT[[]int32](E_RepeatedInt32) = %v, %v; want [1 2], true", got, ok)
+	}
+	if got, ok := proto.GetExtensionT[testpb.TestAllTypes_NestedEnum](m, testpb.E_OptionalNestedEnum); !ok || got != testpb.TestAllTypes_BAZ {
+		t.Errorf("GetExtensionT[TestAllTypes_NestedEnum](E_OptionalNestedEnum) = %v, %v; want BAZ, true", got, ok)
+	}
+	if got, ok := proto.GetExtensionT[*testpb.TestAllExtensions_NestedMessage](m, testpb.E_OptionalNestedMessage); !ok || got.GetA() != 1 {
+		t.Errorf("GetExtensionT[*TestAllExtensions_NestedMessage](E_OptionalNestedMessage) = %v, %v; want a:1, true", got, ok)
+	}
+	if got, ok := proto.GetExtensionT[int64](m, testpb.E_OptionalInt32); ok || got != 0 {
+		t.Errorf("GetExtensionT[int64](E_OptionalInt32) = %v, %v; want 0, false", got, ok)
+	}
+	if got, ok := proto.GetExtensionT[string](m, testpb.E_OptionalString); !ok || got != "" {
+		t.Errorf("GetExtensionT[string](unpopulated E_OptionalString) = %q, %v; want \"\", true", got, ok)
+	}
+
+	for _, test := range []struct {
+		desc string
+		set  func()
+	}{
+		{"int64 for int32 extension", func() { proto.SetExtensionT(m, testpb.E_OptionalInt32, int64(5)) }},
+		{"int32 for repeated extension", func() { proto.SetExtensionT(m, testpb.E_RepeatedInt32, int32(5)) }},
+		{"wrong message type", func() { proto.SetExtensionT[proto.Message](m, testpb.E_OptionalNestedMessage, &testpb.TestAllTypes{}) }},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("SetExtensionT with %v did not panic", test.desc)
+				}
+			}()
+			test.set()
+		}()
+	}
+}
+
+func TestClearAllExtensions(t *testing.T) {
+	nested := &testpb.TestAllExtensions{}
+	proto.SetExtension(nested, testpb.E_OptionalInt32, int32(1))
+	for _, m := range []proto.Message{
+		&testpb.TestAllExtensions{},
+		dynamicpb.NewMessage((&testpb.TestAllExtensions{}).ProtoReflect().Descriptor()),
+	} {
+		proto.SetExtension(m, testpb.E_OptionalInt32, int32(5))
+		proto.SetExtension(m, testpb.E_RepeatedString, []string{"a"})
+		proto.SetExtension(m, testpb.E_OptionalNestedMessage, &testpb.TestAllExtensions_NestedMessage{
+			Corecursive: nested,
+		})
+		m.ProtoReflect().SetUnknown(protoreflect.RawFields{0x98, 0x06, 0x01}) // field 99: 1
+
+		proto.ClearAllExtensions(m)
+		proto.RangeExtensions(m, func(xt protoreflect.ExtensionType, _ any) bool {
+			t.Errorf("%T: after ClearAllExtensions, extension %v is populated", m, xt.TypeDescriptor().FullName())
+			return true
+		})
+		if got := m.ProtoReflect().GetUnknown(); len(got) == 0 {
+			t.Errorf("%T: ClearAllExtensions cleared unknown fields", m)
+		}
+		if !proto.HasExtension(nested, testpb.E_OptionalInt32) {
+			t.Errorf("%T: ClearAllExtensions cleared extension of nested message", m)
+		}
+	}
+	proto.ClearAllExtensions(nil)
+	proto.ClearAllExtensions((*testpb.TestAllExtensions)(nil))
+}
+
 func TestExtensionGetRace(t *testing.T) {
 	// Concurrently fetch an extension value while marshaling the message containing it.
 	// Create the message with proto.Unmarshal to give lazy extension decoding (if present)
@@ -5,6 +5,7 @@
 package proto_test
 
 import (
+	"errors"
 	"math"
 	"testing"
 
@@ -31,7 +32,15 @@ func compareEquivalentProtos(t *testing.T, wireBytes []byte, m0, m1 proto.Messag
 	// Check that the error are the same (possible nil)
 	errorsMatch := (errM1 != nil) == (errM0 != nil)
 	if errM1 != nil && errM0 != nil {
-		errorsMatch = errM1.Error() == errM0.Error()
+		// The full names of the fields in decode errors differ,
+		// so compare the location and underlying error instead.
+		var derr0, derr1 *proto.DecodeError
+		if errors.As(errM0, &derr0) && errors.As(errM1, &derr1) {
+			errorsMatch = derr0.Kind == derr1.Kind && derr0.Offset == derr1.Offset &&
+				derr0.FieldNumber == derr1.FieldNumber && derr0.Err.Error() == derr1.Err.Error()
+		} else {
+			errorsMatch = errM1.Error() == errM0.Error()
+		}
 	}
 	if !errorsMatch {
 		t.Fatalf("errors not equal:\n%T error: %v\n%T error:%v", m0, errM0, m1, errM1)
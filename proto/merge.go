@@ -7,6 +7,7 @@ package proto
 import (
 	"fmt"
 
+	"google.golang.org/protobuf/internal/pragma"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/runtime/protoiface"
 )
@@ -22,7 +23,34 @@ import (
 //
 // It is semantically equivalent to unmarshaling the encoded form of src
 // into dst with the [UnmarshalOptions.Merge] option specified.
+//
+// See the [MergeOptions] type if you need more control.
 func Merge(dst, src Message) {
+	MergeOptions{}.Merge(dst, src)
+}
+
+// MergeOptions configures the merger.
+//
+// Example usage:
+//
+//	MergeOptions{ReplaceRepeated: true}.Merge(dst, src)
+type MergeOptions struct {
+	pragma.NoUnkeyedLiterals
+
+	// ReplaceRepeated specifies that every populated list field in src
+	// replaces the corresponding list field in dst, rather than being
+	// appended to it. Empty lists in src leave dst unchanged.
+	ReplaceRepeated bool
+
+	// ReplaceMaps specifies that every populated map field in src
+	// replaces the corresponding map field in dst, rather than having its
+	// entries copied into it. Empty maps in src leave dst unchanged.
+	ReplaceMaps bool
+}
+
+// Merge merges src into dst, which must be a message with the same descriptor.
+// It behaves as the top-level [Merge] function, subject to the options in o.
+func (o MergeOptions) Merge(dst, src Message) {
 	// TODO: Should nil src be treated as semantically equivalent to a
 	// untyped, read-only, empty message? What about a nil dst?
 
@@ -33,7 +61,7 @@ func Merge(dst, src Message) {
 		}
 		panic("descriptor mismatch")
 	}
-	mergeOptions{}.mergeMessage(dstMsg, srcMsg)
+	o.mergeMessage(dstMsg, srcMsg)
 }
 
 // Clone returns a deep copy of m.
@@ -55,17 +83,112 @@ func Clone(m Message) Message {
 		return src.Type().Zero().Interface()
 	}
 	dst := src.New()
-	mergeOptions{}.mergeMessage(dst, src)
+	MergeOptions{}.mergeMessage(dst, src)
 	return dst.Interface()
 }
 
-// mergeOptions provides a namespace for merge functions, and can be
-// exported in the future if we add user-visible merge options.
-type mergeOptions struct{}
+// CloneInto resets dst and deep copies src into it, such that dst becomes
+// equal to src. The destination must be a message with the same descriptor.
+//
+// Unlike [Clone], CloneInto reuses the memory already allocated by dst
+// where possible, such as the backing arrays of repeated scalar fields,
+// maps, and nested messages that are populated in both dst and src.
+// This makes it suitable for recycling messages obtained from a pool.
+//
+// The destination and source may be the same message or share nested
+// messages, in which case src is copied before dst is reset.
+// They must not share map fields.
+func CloneInto(dst, src Message) {
+	dstMsg, srcMsg := dst.ProtoReflect(), src.ProtoReflect()
+	if dstMsg.Descriptor() != srcMsg.Descriptor() {
+		if got, want := dstMsg.Descriptor().FullName(), srcMsg.Descriptor().FullName(); got != want {
+			panic(fmt.Sprintf("descriptor mismatch: %v != %v", got, want))
+		}
+		panic("descriptor mismatch")
+	}
+	if dstMsg.Interface() == srcMsg.Interface() {
+		return
+	}
+	if containsAny(srcMsg, retainedMessages(nil, dstMsg, srcMsg)) {
+		srcMsg = Clone(src).ProtoReflect()
+	}
+	resetRetained(dstMsg, srcMsg)
+	MergeOptions{}.mergeMessage(dstMsg, srcMsg)
+}
+
+// retainedMessages appends dst and the nested messages of dst which
+// resetRetained retains, and which are thus modified by CloneInto.
+func retainedMessages(ms []protoreflect.ProtoMessage, dst, src protoreflect.Message) []protoreflect.ProtoMessage {
+	ms = append(ms, dst.Interface())
+	dst.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Message() != nil && !fd.IsList() && !fd.IsMap() && src.Has(fd) {
+			ms = retainedMessages(ms, v.Message(), src.Get(fd).Message())
+		}
+		return true
+	})
+	return ms
+}
+
+// containsAny reports whether m or any message nested within it is in ms.
+func containsAny(m protoreflect.Message, ms []protoreflect.ProtoMessage) bool {
+	for _, x := range ms {
+		if m.Interface() == x {
+			return true
+		}
+	}
+	found := false
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsList() && fd.Message() != nil:
+			list := v.List()
+			for i := 0; i < list.Len() && !found; i++ {
+				found = containsAny(list.Get(i).Message(), ms)
+			}
+		case fd.IsMap() && fd.MapValue().Message() != nil:
+			v.Map().Range(func(_ protoreflect.MapKey, v protoreflect.Value) bool {
+				found = containsAny(v.Message(), ms)
+				return !found
+			})
+		case fd.Message() != nil && !fd.IsList() && !fd.IsMap():
+			found = containsAny(v.Message(), ms)
+		}
+		return !found
+	})
+	return found
+}
+
+// resetRetained clears every field of dst while retaining allocated memory.
+// Lists of messages are not retained since merging always allocates new
+// elements for them. Nested messages in dst are only retained if they are
+// also populated in src, so that the subsequent merge leaves them in the
+// same state as in src.
+func resetRetained(dst, src protoreflect.Message) {
+	dst.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		switch {
+		case fd.IsList() && fd.Message() == nil:
+			dst.Mutable(fd).List().Truncate(0)
+		case fd.IsMap():
+			mmap := dst.Mutable(fd).Map()
+			mmap.Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+				mmap.Clear(k)
+				return true
+			})
+		case fd.Message() != nil && !fd.IsList() && src.Has(fd):
+			resetRetained(dst.Mutable(fd).Message(), src.Get(fd).Message())
+		default:
+			dst.Clear(fd)
+		}
+		return true
+	})
+	if b := dst.GetUnknown(); len(b) > 0 {
+		dst.SetUnknown(b[:0])
+	}
+}
 
-func (o mergeOptions) mergeMessage(dst, src protoreflect.Message) {
+func (o MergeOptions) mergeMessage(dst, src protoreflect.Message) {
+	// The fast-path merge implementations do not support any options.
 	methods := protoMethods(dst)
-	if methods != nil && methods.Merge != nil {
+	if methods != nil && methods.Merge != nil && !o.ReplaceRepeated && !o.ReplaceMaps {
 		in := protoiface.MergeInput{
 			Destination: dst,
 			Source:      src,
@@ -83,8 +206,14 @@ func (o mergeOptions) mergeMessage(dst, src protoreflect.Message) {
 	src.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
 		switch {
 		case fd.IsList():
+			if o.ReplaceRepeated {
+				dst.Clear(fd)
+			}
 			o.mergeList(dst.Mutable(fd).List(), v.List(), fd)
 		case fd.IsMap():
+			if o.ReplaceMaps {
+				dst.Clear(fd)
+			}
 			o.mergeMap(dst.Mutable(fd).Map(), v.Map(), fd.MapValue())
 		case fd.Message() != nil:
 			o.mergeMessage(dst.Mutable(fd).Message(), v.Message())
@@ -101,7 +230,7 @@ func (o mergeOptions) mergeMessage(dst, src protoreflect.Message) {
 	}
 }
 
-func (o mergeOptions) mergeList(dst, src protoreflect.List, fd protoreflect.FieldDescriptor) {
+func (o MergeOptions) mergeList(dst, src protoreflect.List, fd protoreflect.FieldDescriptor) {
 	// Merge semantics appends to the end of the existing list.
 	for i, n := 0, src.Len(); i < n; i++ {
 		switch v := src.Get(i); {
@@ -117,7 +246,7 @@ func (o mergeOptions) mergeList(dst, src protoreflect.List, fd protoreflect.Fiel
 	}
 }
 
-func (o mergeOptions) mergeMap(dst, src protoreflect.Map, fd protoreflect.FieldDescriptor) {
+func (o MergeOptions) mergeMap(dst, src protoreflect.Map, fd protoreflect.FieldDescriptor) {
 	// Merge semantics replaces, rather than merges into existing entries.
 	src.Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
 		switch {
@@ -134,6 +263,6 @@ func (o mergeOptions) mergeMap(dst, src protoreflect.Map, fd protoreflect.FieldD
 	})
 }
 
-func (o mergeOptions) cloneBytes(v protoreflect.Value) protoreflect.Value {
+func (o MergeOptions) cloneBytes(v protoreflect.Value) protoreflect.Value {
 	return protoreflect.ValueOfBytes(append([]byte{}, v.Bytes()...))
 }
@@ -659,6 +659,78 @@ func TestMergeFromNil(t *testing.T) {
 	}
 }
 
+func TestMergeOptions(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts proto.MergeOptions
+		dst  protobuild.Message
+		src  protobuild.Message
+		want protobuild.Message
+	}{{
+		desc: "ReplaceRepeated",
+		opts: proto.MergeOptions{ReplaceRepeated: true},
+		dst: protobuild.Message{
+			"repeated_int32":          []int32{1, 2},
+			"repeated_string":         []string{"a"},
+			"map_int32_int32":         map[int32]int32{1: 1},
+			"optional_nested_message": protobuild.Message{"corecursive": protobuild.Message{"repeated_int32": []int32{3}}},
+		},
+		src: protobuild.Message{
+			"repeated_int32":          []int32{4},
+			"map_int32_int32":         map[int32]int32{2: 2},
+			"optional_nested_message": protobuild.Message{"corecursive": protobuild.Message{"repeated_int32": []int32{5}}},
+		},
+		want: protobuild.Message{
+			"repeated_int32":          []int32{4},
+			"repeated_string":         []string{"a"},
+			"map_int32_int32":         map[int32]int32{1: 1, 2: 2},
+			"optional_nested_message": protobuild.Message{"corecursive": protobuild.Message{"repeated_int32": []int32{5}}},
+		},
+	}, {
+		desc: "ReplaceMaps",
+		opts: proto.MergeOptions{ReplaceMaps: true},
+		dst: protobuild.Message{
+			"repeated_int32":  []int32{1, 2},
+			"map_int32_int32": map[int32]int32{1: 1},
+			"map_string_nested_message": map[string]protobuild.Message{
+				"a": {"a": 1},
+			},
+		},
+		src: protobuild.Message{
+			"repeated_int32":  []int32{4},
+			"map_int32_int32": map[int32]int32{2: 2},
+		},
+		want: protobuild.Message{
+			"repeated_int32":  []int32{1, 2, 4},
+			"map_int32_int32": map[int32]int32{2: 2},
+			"map_string_nested_message": map[string]protobuild.Message{
+				"a": {"a": 1},
+			},
+		},
+	}}
+	for _, tt := range tests {
+		for _, mt := range templateMessages(&testpb.TestAllTypes{}, &test3pb.TestAllTypes{}) {
+			t.Run(fmt.Sprintf("%s (%v)", tt.desc, mt.Descriptor().FullName()), func(t *testing.T) {
+				dst := mt.New().Interface()
+				tt.dst.Build(dst.ProtoReflect())
+				src := mt.New().Interface()
+				tt.src.Build(src.ProtoReflect())
+				want := mt.New().Interface()
+				tt.want.Build(want.ProtoReflect())
+
+				tt.opts.Merge(dst, src)
+				if !proto.Equal(dst, want) {
+					t.Fatalf("Merge() mismatch:\n got %v\nwant %v\ndiff (-want,+got):\n%v", dst, want, cmp.Diff(want, dst, protocmp.Transform()))
+				}
+				mutateValue(protoreflect.ValueOfMessage(src.ProtoReflect()))
+				if !proto.Equal(dst, want) {
+					t.Fatalf("mutation observed after modifying source:\n got %v\nwant %v\ndiff (-want,+got):\n%v", dst, want, cmp.Diff(want, dst, protocmp.Transform()))
+				}
+			})
+		}
+	}
+}
+
 // TestMergeAberrant tests inputs that are beyond the protobuf data model.
 // Just because there is a test for the current behavior does not mean that
 // this will behave the same way in the future.
@@ -853,6 +925,97 @@ func TestClone(t *testing.T) {
 	}
 }
 
+func TestCloneInto(t *testing.T) {
+	for _, tt := range testMerges {
+		for _, mt := range templateMessages(tt.types...) {
+			t.Run(fmt.Sprintf("%s (%v)", tt.desc, mt.Descriptor().FullName()), func(t *testing.T) {
+				dst := mt.New().Interface()
+				tt.dst.Build(dst.ProtoReflect())
+				src := mt.New().Interface()
+				tt.src.Build(src.ProtoReflect())
+				want := proto.Clone(src)
+
+				proto.CloneInto(dst, src)
+				if !proto.Equal(dst, want) {
+					t.Fatalf("CloneInto() mismatch:\n got %v\nwant %v\ndiff (-want,+got):\n%v", dst, want, cmp.Diff(want, dst, protocmp.Transform()))
+				}
+				mutateValue(protoreflect.ValueOfMessage(src.ProtoReflect()))
+				if !proto.Equal(dst, want) {
+					t.Fatalf("mutation observed after modifying source:\n got %v\nwant %v\ndiff (-want,+got):\n%v", dst, want, cmp.Diff(want, dst, protocmp.Transform()))
+				}
+			})
+		}
+	}
+}
+
+func TestCloneIntoReusesMemory(t *testing.T) {
+	dst := &testpb.TestAllTypes{
+		OptionalInt64:          proto.Int64(1),
+		RepeatedInt32:          []int32{1, 2, 3, 4},
+		OptionalNestedMessage:  &testpb.TestAllTypes_NestedMessage{A: proto.Int32(1)},
+		OptionalForeignMessage: &testpb.ForeignMessage{C: proto.Int32(1)},
+	}
+	repeated := dst.RepeatedInt32
+	nested := dst.OptionalNestedMessage
+	src := &testpb.TestAllTypes{
+		RepeatedInt32:         []int32{5, 6},
+		OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{Corecursive: &testpb.TestAllTypes{}},
+	}
+
+	proto.CloneInto(dst, src)
+	if !proto.Equal(dst, src) {
+		t.Fatalf("CloneInto() mismatch:\n got %v\nwant %v", dst, src)
+	}
+	if &dst.RepeatedInt32[0] != &repeated[0] {
+		t.Errorf("CloneInto() did not reuse the backing array of a repeated field")
+	}
+	if dst.OptionalNestedMessage != nested {
+		t.Errorf("CloneInto() did not reuse a nested message")
+	}
+}
+
+func TestCloneIntoAliased(t *testing.T) {
+	newMessage := func() *testpb.TestAllTypes {
+		return &testpb.TestAllTypes{
+			OptionalInt32:         proto.Int32(1),
+			RepeatedInt32:         []int32{1, 2},
+			OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{A: proto.Int32(2)},
+			RepeatedNestedMessage: []*testpb.TestAllTypes_NestedMessage{{A: proto.Int32(3)}},
+		}
+	}
+
+	t.Run("same message", func(t *testing.T) {
+		m := newMessage()
+		proto.CloneInto(m, m)
+		if want := newMessage(); !proto.Equal(m, want) {
+			t.Errorf("CloneInto(m, m) mismatch:\n got %v\nwant %v", m, want)
+		}
+	})
+
+	t.Run("shared nested message", func(t *testing.T) {
+		src := newMessage()
+		dst := &testpb.TestAllTypes{
+			OptionalInt64:         proto.Int64(4),
+			OptionalNestedMessage: src.OptionalNestedMessage,
+		}
+		proto.CloneInto(dst, src)
+		if want := newMessage(); !proto.Equal(src, want) || !proto.Equal(dst, want) {
+			t.Errorf("CloneInto(dst, src) mismatch:\n got dst %v, src %v\nwant %v", dst, src, want)
+		}
+	})
+
+	t.Run("destination nested in source", func(t *testing.T) {
+		dst := &testpb.TestAllTypes{OptionalInt64: proto.Int64(4)}
+		src := newMessage()
+		src.OptionalNestedMessage.Corecursive = dst
+		want := proto.Clone(src)
+		proto.CloneInto(dst, src)
+		if !proto.Equal(dst, want) {
+			t.Errorf("CloneInto(dst, src) mismatch:\n got %v\nwant %v", dst, want)
+		}
+	})
+}
+
 // mutateValue changes a Value, returning a new value.
 //
 // For scalar values, it returns a value different from the input.
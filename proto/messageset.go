@@ -30,7 +30,7 @@ func (o MarshalOptions) marshalMessageSet(b []byte, m protoreflect.Message) ([]b
 		return b, errors.New("no support for message_set_wire_format")
 	}
 	fieldOrder := order.AnyFieldOrder
-	if o.Deterministic {
+	if o.Deterministic || o.Canonical {
 		fieldOrder = order.NumberFieldOrder
 	}
 	var err error
@@ -2,9 +2,10 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// The protoreflect tag disables fast-path methods, including legacy ones.
-//go:build !protoreflect
-// +build !protoreflect
+// The protoreflect tag disables fast-path methods, including legacy ones,
+// and the protonofastpath tag omits them from generated messages.
+//go:build !protoreflect && !protonofastpath
+// +build !protoreflect,!protonofastpath
 
 package proto_test
 
@@ -16,7 +17,11 @@ import (
 
 	"google.golang.org/protobuf/internal/impl"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
 	"google.golang.org/protobuf/runtime/protoiface"
+	"google.golang.org/protobuf/testing/protopack"
+
+	testpb "google.golang.org/protobuf/internal/testprotos/test"
 
 	legacypb "google.golang.org/protobuf/internal/testprotos/legacy"
 )
@@ -183,3 +188,103 @@ func TestLegacyMergeMethod(t *testing.T) {
 		t.Errorf("Merge(dst, src): want src.src = nil, got %v", got)
 	}
 }
+
+func TestStateInput(t *testing.T) {
+	unknown := protopack.Message{
+		protopack.Tag{100000, protopack.VarintType}, protopack.Varint(1),
+	}.Marshal()
+	m := new(testpb.TestAllTypes)
+	if _, err := (proto.UnmarshalOptions{}).UnmarshalState(protoiface.UnmarshalInput{
+		Message: m.ProtoReflect(),
+		Buf:     unknown,
+		Flags:   protoiface.UnmarshalDiscardUnknown,
+	}); err != nil {
+		t.Fatalf("UnmarshalState(UnmarshalDiscardUnknown) error: %v", err)
+	}
+	if got := m.ProtoReflect().GetUnknown(); len(got) > 0 {
+		t.Errorf("UnmarshalState(UnmarshalDiscardUnknown) kept unknown fields %x", got)
+	}
+
+	ext := protopack.Message{
+		protopack.Tag{1, protopack.VarintType}, protopack.Varint(1),
+	}.Marshal()
+	xm := new(testpb.TestAllExtensions)
+	if _, err := (proto.UnmarshalOptions{}).UnmarshalState(protoiface.UnmarshalInput{
+		Message:  xm.ProtoReflect(),
+		Buf:      ext,
+		Resolver: new(protoregistry.Types),
+	}); err != nil {
+		t.Fatalf("UnmarshalState(Resolver) error: %v", err)
+	}
+	if proto.HasExtension(xm, testpb.E_OptionalInt32) {
+		t.Errorf("UnmarshalState used the global registry instead of in.Resolver")
+	}
+
+	nested := protopack.Message{
+		protopack.Tag{18, protopack.BytesType}, protopack.LengthPrefix{
+			protopack.Tag{2, protopack.BytesType}, protopack.LengthPrefix{},
+		},
+	}.Marshal()
+	if _, err := (proto.UnmarshalOptions{}).UnmarshalState(protoiface.UnmarshalInput{
+		Message: new(testpb.TestAllTypes).ProtoReflect(),
+		Buf:     nested,
+		Depth:   1,
+	}); err == nil {
+		t.Errorf("UnmarshalState(Depth: 1) succeeded, want recursion error")
+	}
+
+	mm := &testpb.TestAllTypes{MapStringString: map[string]string{"a": "1", "b": "2", "c": "3", "d": "4"}}
+	want, err := proto.MarshalOptions{Deterministic: true}.Marshal(mm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := (proto.MarshalOptions{}).MarshalState(protoiface.MarshalInput{
+		Message: mm.ProtoReflect(),
+		Buf:     []byte("prefix"),
+		Flags:   protoiface.MarshalDeterministic,
+	})
+	if err != nil {
+		t.Fatalf("MarshalState(MarshalDeterministic) error: %v", err)
+	}
+	if got := out.Buf; !bytes.Equal(got, append([]byte("prefix"), want...)) {
+		t.Errorf("MarshalState(MarshalDeterministic) = %x, want prefix followed by %x", got, want)
+	}
+}
+
+func TestUnmarshalAliasBuffer(t *testing.T) {
+	b, err := proto.Marshal(&testpb.TestAllTypes{
+		OptionalString: proto.String("abc"),
+		OptionalBytes:  []byte("abc"),
+		RepeatedString: []string{"abc"},
+		RepeatedBytes:  [][]byte{[]byte("abc")},
+		OneofField:     &testpb.TestAllTypes_OneofBytes{OneofBytes: []byte{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &testpb.TestAllTypes{}
+	if err := (proto.UnmarshalOptions{AliasBuffer: true}).Unmarshal(b, m); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetOneofBytes(); got == nil {
+		t.Errorf("Unmarshal with AliasBuffer: oneof_bytes = nil, want empty bytes")
+	}
+	appended := append(m.OptionalBytes, 'x')
+	if got, want := string(appended), "abcx"; got != want {
+		t.Errorf("append(m.OptionalBytes, 'x') = %q, want %q", got, want)
+	}
+
+	// Values reference the input buffer, so modifying it modifies them.
+	copy(b, bytes.ReplaceAll(b, []byte("abc"), []byte("xyz")))
+	want := &testpb.TestAllTypes{
+		OptionalString: proto.String("xyz"),
+		OptionalBytes:  []byte("xyz"),
+		RepeatedString: []string{"xyz"},
+		RepeatedBytes:  [][]byte{[]byte("xyz")},
+		OneofField:     &testpb.TestAllTypes_OneofBytes{OneofBytes: []byte{}},
+	}
+	if !proto.Equal(m, want) {
+		t.Errorf("after modifying input buffer, Unmarshal with AliasBuffer =\n%v\nwant\n%v", m, want)
+	}
+}
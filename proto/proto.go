@@ -6,6 +6,7 @@ package proto
 
 import (
 	"google.golang.org/protobuf/internal/errors"
+	"google.golang.org/protobuf/internal/msgfmt"
 	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
@@ -33,6 +34,7 @@ var Error error
 
 func init() {
 	Error = errors.Error
+	msgfmt.Unmarshal = UnmarshalOptions{AllowPartial: true}.Unmarshal
 }
 
 // MessageName returns the full name of m.
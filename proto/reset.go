@@ -21,6 +21,24 @@ func Reset(m Message) {
 	resetMessage(m.ProtoReflect())
 }
 
+// Recycle clears every field in the message like [Reset], but retains the
+// memory that the message has already allocated, such as the backing arrays
+// of repeated scalar fields and the storage of map fields.
+// Unknown fields and extension fields are always removed.
+//
+// A recycled message may be reused by merging into it, for example with
+// [UnmarshalOptions.Merge] or [CloneInto], which fill the retained memory
+// before allocating more. Since the retained memory is shared with the
+// previous state of the message, references into it that were obtained
+// before the call (such as a repeated field slice) must not be used afterwards.
+func Recycle(m Message) {
+	mr := m.ProtoReflect()
+	if !mr.IsValid() {
+		panic(fmt.Sprintf("cannot recycle invalid %v message", mr.Descriptor().FullName()))
+	}
+	resetRetained(mr, mr.Type().Zero())
+}
+
 func resetMessage(m protoreflect.Message) {
 	if !m.IsValid() {
 		panic(fmt.Sprintf("cannot reset invalid %v message", m.Descriptor().FullName()))
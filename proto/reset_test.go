@@ -5,9 +5,12 @@
 package proto_test
 
 import (
+	"reflect"
 	"testing"
 
+	"google.golang.org/protobuf/encoding/prototext"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protopack"
 
 	testpb "google.golang.org/protobuf/internal/testprotos/test"
 )
@@ -56,3 +59,43 @@ func TestReset(t *testing.T) {
 		t.Errorf("m.ProtoReflect().GetUnknown() = %d, want nil", got)
 	}
 }
+
+func TestRecycle(t *testing.T) {
+	m := &testpb.TestAllExtensions{}
+	proto.SetExtension(m, testpb.E_OptionalInt32, int32(1))
+	m.ProtoReflect().SetUnknown(protopack.Message{
+		protopack.Tag{Number: 50000, Type: protopack.VarintType}, protopack.Varint(1),
+	}.Marshal())
+	proto.Recycle(m)
+	if !proto.Equal(m, &testpb.TestAllExtensions{}) {
+		t.Errorf("Recycle() did not clear extensions and unknown fields:\n%v", prototext.Format(m))
+	}
+
+	m2 := &testpb.TestAllTypes{
+		OptionalInt32:          proto.Int32(1),
+		RepeatedInt32:          []int32{1, 2, 3},
+		RepeatedNestedMessage:  []*testpb.TestAllTypes_NestedMessage{{A: proto.Int32(1)}},
+		MapStringString:        map[string]string{"a": "b"},
+		OptionalForeignMessage: &testpb.ForeignMessage{C: proto.Int32(1)},
+		OneofField:             &testpb.TestAllTypes_OneofUint32{OneofUint32: 1},
+	}
+	repeated := m2.RepeatedInt32
+	proto.Recycle(m2)
+	if !proto.Equal(m2, &testpb.TestAllTypes{}) {
+		t.Errorf("Recycle() did not clear all fields:\n%v", prototext.Format(m2))
+	}
+
+	b, err := proto.Marshal(&testpb.TestAllTypes{RepeatedInt32: []int32{4, 5}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := (proto.UnmarshalOptions{Merge: true}).Unmarshal(b, m2); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := m2.RepeatedInt32, []int32{4, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("m.RepeatedInt32 = %v, want %v", got, want)
+	}
+	if &m2.RepeatedInt32[0] != &repeated[0] {
+		t.Errorf("Unmarshal() after Recycle() did not reuse the backing array of a repeated field")
+	}
+}
@@ -38,11 +38,18 @@ func (o MarshalOptions) Size(m Message) int {
 // For profiling purposes, avoid changing the name of this function or
 // introducing other code paths for size that do not go through this.
 func (o MarshalOptions) size(m protoreflect.Message) (size int) {
+	if o.InvalidUTF8 == UTF8Replace {
+		// Replacing invalid UTF-8 may change the length of strings,
+		// so the size is that of the actual output.
+		out, _ := o.marshal(nil, m)
+		return len(out.Buf)
+	}
 	methods := protoMethods(m)
 	if methods != nil && methods.Size != nil {
 		out := methods.Size(protoiface.SizeInput{
-			Message: m,
-			Flags:   o.flags(),
+			Message:     m,
+			Flags:       o.flags(),
+			Concurrency: o.Concurrency,
 		})
 		return out.Size
 	}
@@ -55,7 +62,31 @@ func (o MarshalOptions) size(m protoreflect.Message) (size int) {
 		})
 		return len(out.Buf)
 	}
-	return o.sizeMessageSlow(m)
+	if size, ok := o.cachedSize(m); ok {
+		return size
+	}
+	size = o.sizeMessageSlow(m)
+	if c, ok := m.Interface().(protoiface.SizeCacher); ok {
+		c.ProtoSetCachedSize(size)
+	}
+	return size
+}
+
+// cachedSize returns the size of m recorded by a previous call to size,
+// provided that UseCachedSize is set and that m implements
+// protoiface.SizeCacher instead of providing fast-path methods.
+func (o MarshalOptions) cachedSize(m protoreflect.Message) (size int, ok bool) {
+	if !o.UseCachedSize || o.InvalidUTF8 == UTF8Replace {
+		return 0, false
+	}
+	if methods := protoMethods(m); methods != nil && (methods.Size != nil || methods.Marshal != nil) {
+		return 0, false
+	}
+	c, ok := m.Interface().(protoiface.SizeCacher)
+	if !ok {
+		return 0, false
+	}
+	return c.ProtoCachedSize()
 }
 
 func (o MarshalOptions) sizeMessageSlow(m protoreflect.Message) (size int) {
@@ -229,6 +229,22 @@ var testValidMessages = []testProto{
 		}.Marshal(),
 	},
 
+	{
+		desc: "delimited encoded message field with a name that is not group-like",
+		decodeTo: makeMessages(protobuild.Message{
+			"not_group_like_delimited": protobuild.Message{
+				"a":                 1017,
+				"same_field_number": 1016,
+			},
+		}, &testeditionspb.TestAllTypes{}),
+		wire: protopack.Message{
+			protopack.Tag{17, protopack.StartGroupType},
+			protopack.Tag{17, protopack.VarintType}, protopack.Varint(1017),
+			protopack.Tag{16, protopack.VarintType}, protopack.Varint(1016),
+			protopack.Tag{17, protopack.EndGroupType},
+		}.Marshal(),
+	},
+
 	{
 		desc: "messages",
 		decodeTo: makeMessages(protobuild.Message{
@@ -0,0 +1,56 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package proto
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/internal/errors"
+	"google.golang.org/protobuf/internal/strs"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// UTF8Policy specifies how invalid UTF-8 is handled in string fields
+// that are required to contain valid UTF-8. Whether a field requires valid
+// UTF-8 is determined by its syntax or by the utf8_validation feature;
+// fields that do not require it are never validated.
+type UTF8Policy int
+
+const (
+	// UTF8Strict reports an error for a string field
+	// that contains invalid UTF-8. This is the default.
+	UTF8Strict UTF8Policy = iota
+
+	// UTF8Replace replaces each maximal run of invalid UTF-8 bytes in
+	// a string field with the Unicode replacement character U+FFFD.
+	// This permits data produced by systems that do not validate UTF-8
+	// to be sanitized as it is parsed or serialized.
+	UTF8Replace
+
+	// UTF8Skip passes strings through as is without validating them.
+	UTF8Skip
+)
+
+func (o MarshalOptions) checkUTF8(s string, fd protoreflect.FieldDescriptor) (string, error) {
+	if o.InvalidUTF8 == UTF8Skip || !strs.EnforceUTF8(fd) || utf8.ValidString(s) {
+		return s, nil
+	}
+	if o.InvalidUTF8 == UTF8Replace {
+		return strings.ToValidUTF8(s, string(utf8.RuneError)), nil
+	}
+	return s, errors.InvalidUTF8(string(fd.FullName()))
+}
+
+func (o UnmarshalOptions) checkUTF8(b []byte, fd protoreflect.FieldDescriptor) ([]byte, error) {
+	if o.InvalidUTF8 == UTF8Skip || !strs.EnforceUTF8(fd) || utf8.Valid(b) {
+		return b, nil
+	}
+	if o.InvalidUTF8 == UTF8Replace {
+		return bytes.ToValidUTF8(b, []byte(string(utf8.RuneError))), nil
+	}
+	return b, errors.InvalidUTF8(string(fd.FullName()))
+}
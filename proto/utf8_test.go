@@ -0,0 +1,94 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package proto_test
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protopack"
+
+	test3pb "google.golang.org/protobuf/internal/testprotos/test3"
+)
+
+func TestUTF8Policy(t *testing.T) {
+	const invalid = "a\xffb\xfe\xfdc"
+	wire := protopack.Message{
+		protopack.Tag{Number: 94, Type: protopack.BytesType}, protopack.String(invalid),
+		protopack.Tag{Number: 44, Type: protopack.BytesType}, protopack.String(invalid),
+		protopack.Tag{Number: 69, Type: protopack.BytesType}, protopack.LengthPrefix(protopack.Message{
+			protopack.Tag{Number: 1, Type: protopack.BytesType}, protopack.String(invalid),
+			protopack.Tag{Number: 2, Type: protopack.BytesType}, protopack.String(invalid),
+		}),
+		protopack.Tag{Number: 98, Type: protopack.BytesType}, protopack.LengthPrefix(protopack.Message{
+			protopack.Tag{Number: 1, Type: protopack.VarintType}, protopack.Varint(1),
+		}),
+	}.Marshal()
+	newMessage := func(s string) *test3pb.TestAllTypes {
+		return &test3pb.TestAllTypes{
+			SingularString:        s,
+			RepeatedString:        []string{s},
+			MapStringString:       map[string]string{s: s},
+			SingularNestedMessage: &test3pb.TestAllTypes_NestedMessage{A: 1},
+		}
+	}
+
+	t.Run("Strict", func(t *testing.T) {
+		if err := proto.Unmarshal(wire, &test3pb.TestAllTypes{}); err == nil {
+			t.Errorf("Unmarshal() = nil, want error")
+		}
+		if _, err := proto.Marshal(newMessage(invalid)); err == nil {
+			t.Errorf("Marshal() = nil, want error")
+		}
+	})
+
+	t.Run("Replace", func(t *testing.T) {
+		const want = "a\uFFFDb\uFFFDc"
+		got := &test3pb.TestAllTypes{}
+		if err := (proto.UnmarshalOptions{InvalidUTF8: proto.UTF8Replace}).Unmarshal(wire, got); err != nil {
+			t.Fatalf("Unmarshal() error: %v", err)
+		}
+		if !proto.Equal(got, newMessage(want)) {
+			t.Errorf("Unmarshal() = %v, want %v", got, newMessage(want))
+		}
+
+		opts := proto.MarshalOptions{InvalidUTF8: proto.UTF8Replace}
+		b, err := opts.Marshal(newMessage(invalid))
+		if err != nil {
+			t.Fatalf("Marshal() error: %v", err)
+		}
+		if size := opts.Size(newMessage(invalid)); size != len(b) {
+			t.Errorf("Size() = %v, want %v", size, len(b))
+		}
+		got.Reset()
+		if err := proto.Unmarshal(b, got); err != nil {
+			t.Fatalf("Unmarshal(Marshal()) error: %v", err)
+		}
+		if !proto.Equal(got, newMessage(want)) {
+			t.Errorf("Unmarshal(Marshal()) = %v, want %v", got, newMessage(want))
+		}
+	})
+
+	t.Run("Skip", func(t *testing.T) {
+		got := &test3pb.TestAllTypes{}
+		if err := (proto.UnmarshalOptions{InvalidUTF8: proto.UTF8Skip}).Unmarshal(wire, got); err != nil {
+			t.Fatalf("Unmarshal() error: %v", err)
+		}
+		if !proto.Equal(got, newMessage(invalid)) {
+			t.Errorf("Unmarshal() = %v, want %v", got, newMessage(invalid))
+		}
+		b, err := proto.MarshalOptions{InvalidUTF8: proto.UTF8Skip}.Marshal(got)
+		if err != nil {
+			t.Fatalf("Marshal() error: %v", err)
+		}
+		got.Reset()
+		if err := (proto.UnmarshalOptions{InvalidUTF8: proto.UTF8Skip}).Unmarshal(b, got); err != nil {
+			t.Fatalf("Unmarshal(Marshal()) error: %v", err)
+		}
+		if !proto.Equal(got, newMessage(invalid)) {
+			t.Errorf("Unmarshal(Marshal()) = %v, want %v", got, newMessage(invalid))
+		}
+	})
+}
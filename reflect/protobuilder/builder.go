@@ -0,0 +1,399 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package protobuilder constructs file descriptors from Go values that
+// describe their declarations, without building a
+// [descriptorpb.FileDescriptorProto] by hand.
+//
+// For example, the following builds a file declaring a single message:
+//
+//	fd, err := protobuilder.NewFile(&protobuilder.File{
+//		Path:    "example/person.proto",
+//		Package: "example",
+//		Syntax:  protoreflect.Proto3,
+//		Messages: []protobuilder.Message{{
+//			Name: "Person",
+//			Fields: []protobuilder.Field{
+//				{Name: "name", Number: 1, Kind: protoreflect.StringKind},
+//				{Name: "emails", Number: 2, Kind: protoreflect.StringKind, Cardinality: protoreflect.Repeated},
+//			},
+//		}},
+//	}, nil)
+//
+// The constructed descriptor is validated by [protodesc.NewFile].
+package protobuilder
+
+import (
+	"google.golang.org/protobuf/internal/strs"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// File describes a .proto file.
+type File struct {
+	// Path is the path of the file, such as "example/person.proto".
+	Path string
+	// Package is the protobuf package of the declarations in the file.
+	Package protoreflect.FullName
+	// Syntax is the syntax of the file.
+	// If zero, proto2 is used, as for a .proto file without a syntax statement.
+	Syntax protoreflect.Syntax
+	// Edition is the edition of the file if Syntax is protoreflect.Editions.
+	// If zero, EDITION_2023 is used.
+	Edition descriptorpb.Edition
+	// Imports are the paths of the files imported by the file.
+	Imports []string
+
+	Messages   []Message
+	Enums      []Enum
+	Extensions []Field
+	Services   []Service
+
+	Options *descriptorpb.FileOptions
+}
+
+// Message describes a message declaration.
+type Message struct {
+	Name protoreflect.Name
+
+	// Fields are the fields of the message in declaration order.
+	// Oneofs are declared in the order in which they are first referenced
+	// by the Oneof of a field.
+	Fields []Field
+
+	Messages   []Message
+	Enums      []Enum
+	Extensions []Field
+
+	// ExtensionRanges and ReservedRanges are half-open ranges of field
+	// numbers in the form [start, end), as in protoreflect.FieldRanges.
+	ExtensionRanges [][2]protoreflect.FieldNumber
+	ReservedRanges  [][2]protoreflect.FieldNumber
+	ReservedNames   []protoreflect.Name
+
+	Options *descriptorpb.MessageOptions
+}
+
+// Field describes a field declaration or an extension declaration.
+type Field struct {
+	Name   protoreflect.Name
+	Number protoreflect.FieldNumber
+	// Cardinality is the cardinality of the field.
+	// If zero, protoreflect.Optional is used.
+	// In a file using editions, a required field is declared using the
+	// LEGACY_REQUIRED field_presence feature, as there is no required label.
+	Cardinality protoreflect.Cardinality
+	// Kind is the kind of the field, or the kind of the map value if
+	// MapKey is set.
+	// In a file using editions, a group field is declared as a message field
+	// using the DELIMITED message_encoding feature, as there are no groups.
+	Kind protoreflect.Kind
+	// TypeName is the full name of the message or enum type of the field
+	// (or of the map value) if Kind is MessageKind, GroupKind, or EnumKind.
+	TypeName protoreflect.FullName
+
+	// MapKey is the kind of the key if the field is a map.
+	// The map entry message is declared implicitly within the message
+	// containing the field and the Cardinality must be zero or Repeated.
+	MapKey protoreflect.Kind
+
+	// Oneof is the name of the oneof containing the field, if any.
+	Oneof protoreflect.Name
+	// Proto3Optional reports whether an optional field in a proto3 file
+	// was declared with the optional keyword and thus has explicit presence.
+	Proto3Optional bool
+
+	// Extendee is the full name of the extended message
+	// if the field is an extension.
+	Extendee protoreflect.FullName
+
+	// JSONName is the JSON name of the field.
+	// If empty, it is derived from the field name.
+	JSONName string
+	// Default is the default value of the field in the textual form used
+	// in .proto files, without quotes for strings.
+	Default string
+
+	Options *descriptorpb.FieldOptions
+}
+
+// Enum describes an enum declaration.
+type Enum struct {
+	Name   protoreflect.Name
+	Values []EnumValue
+
+	// ReservedRanges are inclusive ranges of enum numbers in the form
+	// [start, end], as in protoreflect.EnumRanges.
+	ReservedRanges [][2]protoreflect.EnumNumber
+	ReservedNames  []protoreflect.Name
+
+	Options *descriptorpb.EnumOptions
+}
+
+// EnumValue describes an enum value declaration.
+type EnumValue struct {
+	Name   protoreflect.Name
+	Number protoreflect.EnumNumber
+
+	Options *descriptorpb.EnumValueOptions
+}
+
+// Service describes a service declaration.
+type Service struct {
+	Name    protoreflect.Name
+	Methods []Method
+
+	Options *descriptorpb.ServiceOptions
+}
+
+// Method describes a method declaration.
+type Method struct {
+	Name protoreflect.Name
+	// Input and Output are the full names of the request and response messages.
+	Input  protoreflect.FullName
+	Output protoreflect.FullName
+
+	ClientStreaming bool
+	ServerStreaming bool
+
+	Options *descriptorpb.MethodOptions
+}
+
+// NewFile constructs and validates the file descriptor described by f.
+// Imported files and the types they declare are resolved using r,
+// which may be nil if the file has no imports.
+// See [protodesc.NewFile] for more information.
+func NewFile(f *File, r protodesc.Resolver) (protoreflect.FileDescriptor, error) {
+	return protodesc.NewFile(ToFileDescriptorProto(f), r)
+}
+
+// ToFileDescriptorProto converts f to a FileDescriptorProto.
+// Map entry messages and the synthetic oneofs of proto3 optional fields
+// are declared in the same way as by protoc.
+// The result is not validated and shares the Options messages of f,
+// except for those of fields in a file using editions which are copied
+// in order to add features (see [Field]).
+func ToFileDescriptorProto(f *File) *descriptorpb.FileDescriptorProto {
+	fd := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String(f.Path),
+		Dependency: f.Imports,
+		Options:    f.Options,
+	}
+	if f.Package != "" {
+		fd.Package = proto.String(string(f.Package))
+	}
+	switch f.Syntax {
+	case protoreflect.Proto3:
+		fd.Syntax = proto.String("proto3")
+	case protoreflect.Editions:
+		fd.Syntax = proto.String("editions")
+		fd.Edition = f.Edition.Enum()
+		if f.Edition == 0 {
+			fd.Edition = descriptorpb.Edition_EDITION_2023.Enum()
+		}
+	}
+	for i := range f.Messages {
+		fd.MessageType = append(fd.MessageType, toDescriptorProto(&f.Messages[i], f.Package, f.Syntax))
+	}
+	for i := range f.Enums {
+		fd.EnumType = append(fd.EnumType, toEnumDescriptorProto(&f.Enums[i]))
+	}
+	for i := range f.Extensions {
+		fd.Extension = append(fd.Extension, toFieldDescriptorProto(&f.Extensions[i], f.Syntax))
+	}
+	for i := range f.Services {
+		fd.Service = append(fd.Service, toServiceDescriptorProto(&f.Services[i]))
+	}
+	protodesc.SynthesizeProto3Optional(fd)
+	return fd
+}
+
+func toDescriptorProto(m *Message, parent protoreflect.FullName, syntax protoreflect.Syntax) *descriptorpb.DescriptorProto {
+	fullName := parent.Append(m.Name)
+	md := &descriptorpb.DescriptorProto{
+		Name:         proto.String(string(m.Name)),
+		ReservedName: namesToStrings(m.ReservedNames),
+		Options:      m.Options,
+	}
+	oneofIndexes := make(map[protoreflect.Name]int32)
+	for i := range m.Fields {
+		f := &m.Fields[i]
+		field := toFieldDescriptorProto(f, syntax)
+		if f.MapKey != 0 {
+			entry := toMapEntryProto(f)
+			field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+			field.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+			field.TypeName = proto.String("." + string(fullName.Append(protoreflect.Name(entry.GetName()))))
+			field.DefaultValue = nil
+			md.NestedType = append(md.NestedType, entry)
+		}
+		if f.Oneof != "" {
+			idx, ok := oneofIndexes[f.Oneof]
+			if !ok {
+				idx = int32(len(md.OneofDecl))
+				oneofIndexes[f.Oneof] = idx
+				md.OneofDecl = append(md.OneofDecl, &descriptorpb.OneofDescriptorProto{
+					Name: proto.String(string(f.Oneof)),
+				})
+			}
+			field.OneofIndex = proto.Int32(idx)
+		}
+		md.Field = append(md.Field, field)
+	}
+	for i := range m.Messages {
+		md.NestedType = append(md.NestedType, toDescriptorProto(&m.Messages[i], fullName, syntax))
+	}
+	for i := range m.Enums {
+		md.EnumType = append(md.EnumType, toEnumDescriptorProto(&m.Enums[i]))
+	}
+	for i := range m.Extensions {
+		md.Extension = append(md.Extension, toFieldDescriptorProto(&m.Extensions[i], syntax))
+	}
+	for _, r := range m.ExtensionRanges {
+		md.ExtensionRange = append(md.ExtensionRange, &descriptorpb.DescriptorProto_ExtensionRange{
+			Start: proto.Int32(int32(r[0])),
+			End:   proto.Int32(int32(r[1])),
+		})
+	}
+	for _, r := range m.ReservedRanges {
+		md.ReservedRange = append(md.ReservedRange, &descriptorpb.DescriptorProto_ReservedRange{
+			Start: proto.Int32(int32(r[0])),
+			End:   proto.Int32(int32(r[1])),
+		})
+	}
+	return md
+}
+
+// toMapEntryProto returns the implicit map entry message for a map field,
+// declared in the same way as by protoc.
+func toMapEntryProto(f *Field) *descriptorpb.DescriptorProto {
+	key := &descriptorpb.FieldDescriptorProto{
+		Name:   proto.String("key"),
+		Number: proto.Int32(1),
+		Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:   descriptorpb.FieldDescriptorProto_Type(f.MapKey).Enum(),
+	}
+	value := &descriptorpb.FieldDescriptorProto{
+		Name:   proto.String("value"),
+		Number: proto.Int32(2),
+		Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:   descriptorpb.FieldDescriptorProto_Type(f.Kind).Enum(),
+	}
+	if f.TypeName != "" {
+		value.TypeName = proto.String("." + string(f.TypeName))
+	}
+	return &descriptorpb.DescriptorProto{
+		Name:    proto.String(strs.MapEntryName(string(f.Name))),
+		Field:   []*descriptorpb.FieldDescriptorProto{key, value},
+		Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+	}
+}
+
+func toFieldDescriptorProto(f *Field, syntax protoreflect.Syntax) *descriptorpb.FieldDescriptorProto {
+	card := f.Cardinality
+	if card == 0 {
+		card = protoreflect.Optional
+	}
+	kind := f.Kind
+	opts := f.Options
+	if syntax == protoreflect.Editions && (card == protoreflect.Required || kind == protoreflect.GroupKind) {
+		// Editions have neither required fields nor groups,
+		// so they are declared using the equivalent features instead.
+		// The options are copied so as not to modify those of f.
+		if opts == nil {
+			opts = new(descriptorpb.FieldOptions)
+		} else {
+			opts = proto.Clone(opts).(*descriptorpb.FieldOptions)
+		}
+		if opts.Features == nil {
+			opts.Features = new(descriptorpb.FeatureSet)
+		}
+		if card == protoreflect.Required {
+			card = protoreflect.Optional
+			opts.Features.FieldPresence = descriptorpb.FeatureSet_LEGACY_REQUIRED.Enum()
+		}
+		if kind == protoreflect.GroupKind {
+			kind = protoreflect.MessageKind
+			opts.Features.MessageEncoding = descriptorpb.FeatureSet_DELIMITED.Enum()
+		}
+	}
+	fd := &descriptorpb.FieldDescriptorProto{
+		Name:    proto.String(string(f.Name)),
+		Number:  proto.Int32(int32(f.Number)),
+		Label:   descriptorpb.FieldDescriptorProto_Label(card).Enum(),
+		Type:    descriptorpb.FieldDescriptorProto_Type(kind).Enum(),
+		Options: opts,
+	}
+	if f.TypeName != "" {
+		fd.TypeName = proto.String("." + string(f.TypeName))
+	}
+	if f.Extendee != "" {
+		fd.Extendee = proto.String("." + string(f.Extendee))
+	}
+	if f.JSONName != "" {
+		fd.JsonName = proto.String(f.JSONName)
+	}
+	if f.Default != "" {
+		fd.DefaultValue = proto.String(f.Default)
+	}
+	if f.Proto3Optional {
+		fd.Proto3Optional = proto.Bool(true)
+	}
+	return fd
+}
+
+func toEnumDescriptorProto(e *Enum) *descriptorpb.EnumDescriptorProto {
+	ed := &descriptorpb.EnumDescriptorProto{
+		Name:         proto.String(string(e.Name)),
+		ReservedName: namesToStrings(e.ReservedNames),
+		Options:      e.Options,
+	}
+	for _, v := range e.Values {
+		ed.Value = append(ed.Value, &descriptorpb.EnumValueDescriptorProto{
+			Name:    proto.String(string(v.Name)),
+			Number:  proto.Int32(int32(v.Number)),
+			Options: v.Options,
+		})
+	}
+	for _, r := range e.ReservedRanges {
+		ed.ReservedRange = append(ed.ReservedRange, &descriptorpb.EnumDescriptorProto_EnumReservedRange{
+			Start: proto.Int32(int32(r[0])),
+			End:   proto.Int32(int32(r[1])),
+		})
+	}
+	return ed
+}
+
+func toServiceDescriptorProto(s *Service) *descriptorpb.ServiceDescriptorProto {
+	sd := &descriptorpb.ServiceDescriptorProto{
+		Name:    proto.String(string(s.Name)),
+		Options: s.Options,
+	}
+	for _, m := range s.Methods {
+		md := &descriptorpb.MethodDescriptorProto{
+			Name:       proto.String(string(m.Name)),
+			InputType:  proto.String("." + string(m.Input)),
+			OutputType: proto.String("." + string(m.Output)),
+			Options:    m.Options,
+		}
+		if m.ClientStreaming {
+			md.ClientStreaming = proto.Bool(true)
+		}
+		if m.ServerStreaming {
+			md.ServerStreaming = proto.Bool(true)
+		}
+		sd.Method = append(sd.Method, md)
+	}
+	return sd
+}
+
+func namesToStrings(names []protoreflect.Name) []string {
+	var ss []string
+	for _, s := range names {
+		ss = append(ss, string(s))
+	}
+	return ss
+}
@@ -0,0 +1,278 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protobuilder_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protobuilder"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestNewFile(t *testing.T) {
+	f := &protobuilder.File{
+		Path:    "test/builder.proto",
+		Package: "test.builder",
+		Syntax:  protoreflect.Proto3,
+		Imports: []string{"google/protobuf/descriptor.proto"},
+		Messages: []protobuilder.Message{{
+			Name: "Person",
+			Fields: []protobuilder.Field{
+				{Name: "name", Number: 1, Kind: protoreflect.StringKind},
+				{Name: "nickname", Number: 2, Kind: protoreflect.StringKind, Proto3Optional: true},
+				{Name: "emails", Number: 3, Kind: protoreflect.StringKind, Cardinality: protoreflect.Repeated},
+				{Name: "phone_numbers", Number: 4, MapKey: protoreflect.StringKind, Kind: protoreflect.MessageKind, TypeName: "test.builder.Person.PhoneNumber"},
+				{Name: "email", Number: 5, Kind: protoreflect.StringKind, Oneof: "contact"},
+				{Name: "phone", Number: 6, Kind: protoreflect.MessageKind, TypeName: "test.builder.Person.PhoneNumber", Oneof: "contact"},
+			},
+			Messages: []protobuilder.Message{{
+				Name: "PhoneNumber",
+				Fields: []protobuilder.Field{
+					{Name: "number", Number: 1, Kind: protoreflect.StringKind},
+					{Name: "type", Number: 2, Kind: protoreflect.EnumKind, TypeName: "test.builder.PhoneType"},
+				},
+			}},
+			ReservedRanges: [][2]protoreflect.FieldNumber{{10, 20}},
+			ReservedNames:  []protoreflect.Name{"age"},
+		}},
+		Enums: []protobuilder.Enum{{
+			Name: "PhoneType",
+			Values: []protobuilder.EnumValue{
+				{Name: "PHONE_TYPE_UNSPECIFIED", Number: 0},
+				{Name: "PHONE_TYPE_MOBILE", Number: 1},
+			},
+		}},
+		Extensions: []protobuilder.Field{
+			{Name: "sensitive", Number: 50000, Kind: protoreflect.BoolKind, Extendee: "google.protobuf.FieldOptions", Proto3Optional: true},
+		},
+		Services: []protobuilder.Service{{
+			Name: "Directory",
+			Methods: []protobuilder.Method{
+				{Name: "Lookup", Input: "test.builder.Person", Output: "test.builder.Person"},
+				{Name: "Watch", Input: "test.builder.Person", Output: "test.builder.Person", ServerStreaming: true},
+			},
+		}},
+	}
+	fd, err := protobuilder.NewFile(f, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatalf("NewFile() error: %v", err)
+	}
+
+	want := new(descriptorpb.FileDescriptorProto)
+	if err := prototext.Unmarshal([]byte(`
+		name: "test/builder.proto"
+		package: "test.builder"
+		dependency: "google/protobuf/descriptor.proto"
+		message_type: {
+			name: "Person"
+			field: {name: "name" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING}
+			field: {name: "nickname" number: 2 label: LABEL_OPTIONAL type: TYPE_STRING oneof_index: 1 proto3_optional: true}
+			field: {name: "emails" number: 3 label: LABEL_REPEATED type: TYPE_STRING}
+			field: {name: "phone_numbers" number: 4 label: LABEL_REPEATED type: TYPE_MESSAGE type_name: ".test.builder.Person.PhoneNumbersEntry"}
+			field: {name: "email" number: 5 label: LABEL_OPTIONAL type: TYPE_STRING oneof_index: 0}
+			field: {name: "phone" number: 6 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".test.builder.Person.PhoneNumber" oneof_index: 0}
+			nested_type: {
+				name: "PhoneNumbersEntry"
+				field: {name: "key" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING}
+				field: {name: "value" number: 2 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".test.builder.Person.PhoneNumber"}
+				options: {map_entry: true}
+			}
+			nested_type: {
+				name: "PhoneNumber"
+				field: {name: "number" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING}
+				field: {name: "type" number: 2 label: LABEL_OPTIONAL type: TYPE_ENUM type_name: ".test.builder.PhoneType"}
+			}
+			oneof_decl: {name: "contact"}
+			oneof_decl: {name: "_nickname"}
+			reserved_range: {start: 10 end: 20}
+			reserved_name: "age"
+		}
+		enum_type: {
+			name: "PhoneType"
+			value: {name: "PHONE_TYPE_UNSPECIFIED" number: 0}
+			value: {name: "PHONE_TYPE_MOBILE" number: 1}
+		}
+		service: {
+			name: "Directory"
+			method: {name: "Lookup" input_type: ".test.builder.Person" output_type: ".test.builder.Person"}
+			method: {name: "Watch" input_type: ".test.builder.Person" output_type: ".test.builder.Person" server_streaming: true}
+		}
+		extension: {name: "sensitive" number: 50000 label: LABEL_OPTIONAL type: TYPE_BOOL extendee: ".google.protobuf.FieldOptions" proto3_optional: true}
+		syntax: "proto3"
+	`), want); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, protodesc.ToFileDescriptorProto(fd), protocmp.Transform()); diff != "" {
+		t.Errorf("NewFile() mismatch (-want +got):\n%s", diff)
+	}
+
+	person := fd.Messages().ByName("Person")
+	if phones := person.Fields().ByName("phone_numbers"); !phones.IsMap() || phones.MapValue().Message() != person.Messages().ByName("PhoneNumber") {
+		t.Errorf("phone_numbers is not a map of PhoneNumber")
+	}
+	if nickname := person.Fields().ByName("nickname"); !nickname.HasPresence() {
+		t.Errorf("nickname does not have presence")
+	}
+}
+
+func TestNewFileEditions(t *testing.T) {
+	fd, err := protobuilder.NewFile(&protobuilder.File{
+		Path:    "test/editions.proto",
+		Package: "test.editions",
+		Syntax:  protoreflect.Editions,
+		Messages: []protobuilder.Message{{
+			Name: "M",
+			Fields: []protobuilder.Field{
+				{Name: "a", Number: 1, Kind: protoreflect.Int32Kind, Default: "5"},
+			},
+			ExtensionRanges: [][2]protoreflect.FieldNumber{{100, 200}},
+		}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewFile() error: %v", err)
+	}
+	if got, want := fd.Syntax(), protoreflect.Editions; got != want {
+		t.Errorf("Syntax() = %v, want %v", got, want)
+	}
+	md := fd.Messages().ByName("M")
+	if got, want := md.Fields().ByName("a").Default().Int(), int64(5); got != want {
+		t.Errorf("default of a = %v, want %v", got, want)
+	}
+	if !md.Fields().ByName("a").HasPresence() {
+		t.Errorf("a does not have presence")
+	}
+	if !md.ExtensionRanges().Has(150) {
+		t.Errorf("extension ranges do not include 150")
+	}
+}
+
+func TestNewFileEditionsFeatures(t *testing.T) {
+	opts := &descriptorpb.FieldOptions{Deprecated: proto.Bool(true)}
+	f := &protobuilder.File{
+		Path:    "test/editions.proto",
+		Package: "test.editions",
+		Syntax:  protoreflect.Editions,
+		Messages: []protobuilder.Message{{
+			Name: "M",
+			Fields: []protobuilder.Field{
+				{Name: "req", Number: 1, Kind: protoreflect.Int32Kind, Cardinality: protoreflect.Required, Options: opts},
+				{Name: "grp", Number: 2, Kind: protoreflect.GroupKind, TypeName: "test.editions.M.Grp"},
+			},
+			Messages: []protobuilder.Message{{
+				Name: "Grp",
+				Fields: []protobuilder.Field{
+					{Name: "a", Number: 1, Kind: protoreflect.Int32Kind},
+				},
+			}},
+		}},
+	}
+	fd, err := protobuilder.NewFile(f, nil)
+	if err != nil {
+		t.Fatalf("NewFile() error: %v", err)
+	}
+	if opts.Features != nil {
+		t.Errorf("NewFile() modified the options of a field: %v", opts)
+	}
+
+	want := new(descriptorpb.FileDescriptorProto)
+	if err := prototext.Unmarshal([]byte(`
+		name: "test/editions.proto"
+		package: "test.editions"
+		message_type: {
+			name: "M"
+			field: {
+				name: "req" number: 1 label: LABEL_OPTIONAL type: TYPE_INT32
+				options: {deprecated: true features: {field_presence: LEGACY_REQUIRED}}
+			}
+			field: {
+				name: "grp" number: 2 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".test.editions.M.Grp"
+				options: {features: {message_encoding: DELIMITED}}
+			}
+			nested_type: {
+				name: "Grp"
+				field: {name: "a" number: 1 label: LABEL_OPTIONAL type: TYPE_INT32}
+			}
+		}
+		syntax: "editions"
+		edition: EDITION_2023
+	`), want); err != nil {
+		t.Fatal(err)
+	}
+	got := protodesc.ToFileDescriptorProto(fd)
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("NewFile() mismatch (-want +got):\n%s", diff)
+	}
+
+	// The file is unchanged when constructed again from its descriptor.
+	fd2, err := protodesc.NewFile(got, nil)
+	if err != nil {
+		t.Fatalf("protodesc.NewFile() error: %v", err)
+	}
+	if diff := cmp.Diff(got, protodesc.ToFileDescriptorProto(fd2), protocmp.Transform()); diff != "" {
+		t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+	}
+	for _, fd := range []protoreflect.FileDescriptor{fd, fd2} {
+		fields := fd.Messages().ByName("M").Fields()
+		if got := fields.ByName("req").Cardinality(); got != protoreflect.Required {
+			t.Errorf("cardinality of req = %v, want required", got)
+		}
+		if got := fields.ByName("grp").Kind(); got != protoreflect.GroupKind {
+			t.Errorf("kind of grp = %v, want group", got)
+		}
+	}
+}
+
+func TestNewFileInvalid(t *testing.T) {
+	for _, tt := range []struct {
+		desc string
+		file *protobuilder.File
+	}{{
+		desc: "duplicate field number",
+		file: &protobuilder.File{
+			Path: "test/invalid.proto",
+			Messages: []protobuilder.Message{{
+				Name: "M",
+				Fields: []protobuilder.Field{
+					{Name: "a", Number: 1, Kind: protoreflect.Int32Kind},
+					{Name: "b", Number: 1, Kind: protoreflect.Int32Kind},
+				},
+			}},
+		},
+	}, {
+		desc: "unresolvable type",
+		file: &protobuilder.File{
+			Path: "test/invalid.proto",
+			Messages: []protobuilder.Message{{
+				Name: "M",
+				Fields: []protobuilder.Field{
+					{Name: "a", Number: 1, Kind: protoreflect.MessageKind, TypeName: "test.Missing"},
+				},
+			}},
+		},
+	}, {
+		desc: "invalid map key",
+		file: &protobuilder.File{
+			Path: "test/invalid.proto",
+			Messages: []protobuilder.Message{{
+				Name: "M",
+				Fields: []protobuilder.Field{
+					{Name: "a", Number: 1, MapKey: protoreflect.DoubleKind, Kind: protoreflect.Int32Kind},
+				},
+			}},
+		},
+	}} {
+		if _, err := protobuilder.NewFile(tt.file, nil); err == nil {
+			t.Errorf("%s: NewFile() succeeded, want error", tt.desc)
+		}
+	}
+}
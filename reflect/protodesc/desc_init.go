@@ -214,6 +214,7 @@ func (r descsByName) initExtensionDeclarations(xds []*descriptorpb.FieldDescript
 		if xd.JsonName != nil {
 			x.L2.StringName.InitJSON(xd.GetJsonName())
 		}
+		x.L2.IsProto3Optional = xd.GetProto3Optional()
 		if x.L1.Kind == protoreflect.MessageKind && x.L1.EditionFeatures.IsDelimitedEncoded {
 			x.L1.Kind = protoreflect.GroupKind
 		}
@@ -49,8 +49,10 @@ func validateEnumDeclarations(es []filedesc.Enum, eds []*descriptorpb.EnumDescri
 			if v := e.Values().Get(0); v.Number() != 0 {
 				return errors.New("enum %q using open semantics must have zero number for the first value", v.FullName())
 			}
-			// Verify that value names in open enums do not conflict if the
-			// case-insensitive prefix is removed.
+		}
+		if e.L1.EditionFeatures.IsJSONCompliant && !ed.GetOptions().GetDeprecatedLegacyJsonFieldConflicts() {
+			// Verify that value names in JSON-compliant enums do not conflict
+			// if the case-insensitive prefix is removed.
 			// See protoc v3.8.0: src/google/protobuf/descriptor.cc:4991-5055
 			names := map[string]protoreflect.EnumValueDescriptor{}
 			prefix := strings.Replace(strings.ToLower(string(e.Name())), "_", "", -1)
@@ -58,7 +60,7 @@ func validateEnumDeclarations(es []filedesc.Enum, eds []*descriptorpb.EnumDescri
 				v1 := e.Values().Get(i)
 				s := strs.EnumValueName(strs.TrimEnumPrefix(string(v1.Name()), prefix))
 				if v2, ok := names[s]; ok && v1.Number() != v2.Number() {
-					return errors.New("enum %q using open semantics has conflict: %q with %q", e.FullName(), v1.Name(), v2.Name())
+					return errors.New("enum %q using JSON-compliant format has conflict: %q with %q", e.FullName(), v1.Name(), v2.Name())
 				}
 				names[s] = v1
 			}
@@ -80,6 +82,27 @@ func validateEnumDeclarations(es []filedesc.Enum, eds []*descriptorpb.EnumDescri
 	return nil
 }
 
+// validateJSONNames verifies that the fields of a JSON-compliant message
+// have distinct JSON names, both with and without custom JSON names.
+// See protoc v26.0: src/google/protobuf/descriptor.cc:CheckFieldJsonNameUniqueness
+func validateJSONNames(m *filedesc.Message) error {
+	defaultNames := map[string]protoreflect.FieldDescriptor{}
+	names := map[string]protoreflect.FieldDescriptor{}
+	for i := 0; i < m.Fields().Len(); i++ {
+		f1 := m.Fields().Get(i)
+		s := strs.JSONCamelCase(string(f1.Name()))
+		if f2, ok := defaultNames[s]; ok {
+			return errors.New("message %q using JSON-compliant format has conflicting default JSON names: %q with %q", m.FullName(), f1.Name(), f2.Name())
+		}
+		defaultNames[s] = f1
+		if f2, ok := names[f1.JSONName()]; ok {
+			return errors.New("message %q using JSON-compliant format has conflicting JSON names: %q with %q", m.FullName(), f1.Name(), f2.Name())
+		}
+		names[f1.JSONName()] = f1
+	}
+	return nil
+}
+
 func validateMessageDeclarations(file *filedesc.File, ms []filedesc.Message, mds []*descriptorpb.DescriptorProto) error {
 	// There are a few limited exceptions only for proto3
 	isProto3 := file.L1.Edition == fromEditionProto(descriptorpb.Edition_EDITION_PROTO3)
@@ -112,6 +135,11 @@ func validateMessageDeclarations(file *filedesc.File, ms []filedesc.Message, mds
 		if isMessageSet && (isProto3 || m.Fields().Len() > 0 || m.ExtensionRanges().Len() == 0) {
 			return errors.New("message %q is an invalid proto1 MessageSet", m.FullName())
 		}
+		if m.L1.EditionFeatures.IsJSONCompliant && !md.GetOptions().GetDeprecatedLegacyJsonFieldConflicts() {
+			if err := validateJSONNames(m); err != nil {
+				return err
+			}
+		}
 		if isProto3 {
 			if m.ExtensionRanges().Len() > 0 {
 				return errors.New("message %q using proto3 semantics cannot have extension ranges", m.FullName())
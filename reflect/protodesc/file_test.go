@@ -633,7 +633,7 @@ func TestNewFile(t *testing.T) {
 				value: [{name:"e_Foo" number:0}, {name:"fOo" number:1}]
 			}]}]
 		`),
-		wantErr: `enum "M.E" using open semantics has conflict: "fOo" with "e_Foo"`,
+		wantErr: `enum "M.E" using JSON-compliant format has conflict: "fOo" with "e_Foo"`,
 	}, {
 		label: "proto2 enum has name prefix check",
 		inDesc: mustParseFile(`
@@ -643,6 +643,85 @@ func TestNewFile(t *testing.T) {
 				value: [{name:"e_Foo" number:0}, {name:"fOo" number:1}]
 			}]}]
 		`),
+	}, {
+		label: "editions enum name prefix conflict",
+		inDesc: mustParseFile(`
+			syntax:  "editions"
+			edition: EDITION_2023
+			name:    "test.proto"
+			enum_type: [{
+				name:  "E"
+				value: [{name:"e_Foo" number:0}, {name:"fOo" number:1}]
+				options: {features: {enum_type: CLOSED}}
+			}]
+		`),
+		wantErr: `enum "E" using JSON-compliant format has conflict: "fOo" with "e_Foo"`,
+	}, {
+		label: "editions legacy best effort enum name prefix conflict",
+		inDesc: mustParseFile(`
+			syntax:  "editions"
+			edition: EDITION_2023
+			name:    "test.proto"
+			options: {features: {json_format: LEGACY_BEST_EFFORT}}
+			enum_type: [{
+				name:  "E"
+				value: [{name:"e_Foo" number:0}, {name:"fOo" number:1}]
+			}]
+		`),
+	}, {
+		label: "proto3 enum legacy JSON field conflicts",
+		inDesc: mustParseFile(`
+			syntax:  "proto3"
+			name:    "test.proto"
+			enum_type: [{
+				name:  "E"
+				value: [{name:"e_Foo" number:0}, {name:"fOo" number:1}]
+				options: {deprecated_legacy_json_field_conflicts: true}
+			}]
+		`),
+	}, {
+		label: "proto3 message default JSON name conflict",
+		inDesc: mustParseFile(`
+			syntax:  "proto3"
+			name:    "test.proto"
+			message_type: [{name:"M" field:[
+				{name:"foo_bar" number:1 label:LABEL_OPTIONAL type:TYPE_STRING},
+				{name:"fooBar" number:2 label:LABEL_OPTIONAL type:TYPE_STRING json_name:"other"}
+			]}]
+		`),
+		wantErr: `message "M" using JSON-compliant format has conflicting default JSON names: "fooBar" with "foo_bar"`,
+	}, {
+		label: "editions message custom JSON name conflict",
+		inDesc: mustParseFile(`
+			syntax:  "editions"
+			edition: EDITION_2023
+			name:    "test.proto"
+			message_type: [{name:"M" field:[
+				{name:"a" number:1 label:LABEL_OPTIONAL type:TYPE_STRING json_name:"x"},
+				{name:"b" number:2 label:LABEL_OPTIONAL type:TYPE_STRING json_name:"x"}
+			]}]
+		`),
+		wantErr: `message "M" using JSON-compliant format has conflicting JSON names: "b" with "a"`,
+	}, {
+		label: "editions legacy best effort message JSON name conflict",
+		inDesc: mustParseFile(`
+			syntax:  "editions"
+			edition: EDITION_2023
+			name:    "test.proto"
+			message_type: [{name:"M" field:[
+				{name:"a" number:1 label:LABEL_OPTIONAL type:TYPE_STRING json_name:"x"},
+				{name:"b" number:2 label:LABEL_OPTIONAL type:TYPE_STRING json_name:"x"}
+			] options: {features: {json_format: LEGACY_BEST_EFFORT}}}]
+		`),
+	}, {
+		label: "proto2 message JSON name conflict",
+		inDesc: mustParseFile(`
+			name:    "test.proto"
+			message_type: [{name:"M" field:[
+				{name:"foo_bar" number:1 label:LABEL_OPTIONAL type:TYPE_STRING},
+				{name:"fooBar" number:2 label:LABEL_OPTIONAL type:TYPE_STRING}
+			]}]
+		`),
 	}, {
 		label: "proto3 enum same name prefix with number conflict",
 		inDesc: mustParseFile(`
@@ -1016,6 +1095,33 @@ func TestNewFilesImportCycle(t *testing.T) {
 	}
 }
 
+func TestProto3OptionalExtension(t *testing.T) {
+	fd, err := NewFile(mustParseFile(`
+		name: "test.proto"
+		package: "fizz"
+		syntax: "proto3"
+		dependency: "google/protobuf/descriptor.proto"
+		extension: [{
+			name: "ext"
+			number: 1000
+			label: LABEL_OPTIONAL
+			type: TYPE_INT32
+			extendee: ".google.protobuf.MessageOptions"
+			proto3_optional: true
+		}]
+	`), protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatalf("NewFile() error: %v", err)
+	}
+	xd := fd.Extensions().ByName("ext")
+	if !xd.HasOptionalKeyword() {
+		t.Errorf("HasOptionalKeyword() = false, want true")
+	}
+	if !ToFieldDescriptorProto(xd).GetProto3Optional() {
+		t.Errorf("ToFieldDescriptorProto().Proto3Optional = false, want true")
+	}
+}
+
 func TestSourceLocations(t *testing.T) {
 	fd := mustParseFile(`
 		name: "comments.proto"
@@ -1262,3 +1368,67 @@ func TestToFileDescriptorProtoPlaceHolder(t *testing.T) {
 		t.Errorf("placeholder file descriptor proto is not valid: %s", err)
 	}
 }
+
+func TestSynthesizeProto3Optional(t *testing.T) {
+	in := mustParseFile(`
+		syntax:  "proto3"
+		name:    "proto3_optional.proto"
+		package: "test.proto3"
+		message_type: [{
+			name: "M"
+			field: [
+				{name:"a" number:1 label:LABEL_OPTIONAL type:TYPE_INT32 proto3_optional:true},
+				{name:"_b" number:2 label:LABEL_OPTIONAL type:TYPE_INT32},
+				{name:"b" number:3 label:LABEL_OPTIONAL type:TYPE_INT32 proto3_optional:true},
+				{name:"c" number:4 label:LABEL_OPTIONAL type:TYPE_INT32 oneof_index:0},
+				{name:"d" number:5 label:LABEL_OPTIONAL type:TYPE_INT32 proto3_optional:true oneof_index:1}
+			]
+			oneof_decl: [{name:"o"}, {name:"_d"}]
+			nested_type: [{
+				name: "N"
+				field: [{name:"_e" number:1 label:LABEL_OPTIONAL type:TYPE_STRING proto3_optional:true}]
+			}]
+		}]
+	`)
+	want := mustParseFile(`
+		syntax:  "proto3"
+		name:    "proto3_optional.proto"
+		package: "test.proto3"
+		message_type: [{
+			name: "M"
+			field: [
+				{name:"a" number:1 label:LABEL_OPTIONAL type:TYPE_INT32 proto3_optional:true oneof_index:2},
+				{name:"_b" number:2 label:LABEL_OPTIONAL type:TYPE_INT32},
+				{name:"b" number:3 label:LABEL_OPTIONAL type:TYPE_INT32 proto3_optional:true oneof_index:3},
+				{name:"c" number:4 label:LABEL_OPTIONAL type:TYPE_INT32 oneof_index:0},
+				{name:"d" number:5 label:LABEL_OPTIONAL type:TYPE_INT32 proto3_optional:true oneof_index:1}
+			]
+			oneof_decl: [{name:"o"}, {name:"_d"}, {name:"_a"}, {name:"X_b"}]
+			nested_type: [{
+				name: "N"
+				field: [{name:"_e" number:1 label:LABEL_OPTIONAL type:TYPE_STRING proto3_optional:true oneof_index:0}]
+				oneof_decl: [{name:"X_e"}]
+			}]
+		}]
+	`)
+
+	got := cloneFile(in)
+	if !SynthesizeProto3Optional(got) {
+		t.Errorf("SynthesizeProto3Optional() = false, want true")
+	}
+	if !proto.Equal(got, want) {
+		t.Errorf("SynthesizeProto3Optional() mismatch:\ngot  %v\nwant %v", got, want)
+	}
+	if SynthesizeProto3Optional(got) {
+		t.Errorf("SynthesizeProto3Optional() on normalized file = true, want false")
+	}
+
+	fd, err := NewFile(got, nil)
+	if err != nil {
+		t.Fatalf("NewFile() error: %v", err)
+	}
+	field := fd.Messages().ByName("M").Fields().ByName("a")
+	if od := field.ContainingOneof(); od == nil || !od.IsSynthetic() {
+		t.Errorf("field %v is not in a synthetic oneof", field.FullName())
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protodesc
+
+import (
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// SynthesizeProto3Optional adds the synthetic oneofs for proto3 optional
+// fields that are missing from the provided file descriptor message,
+// which it modifies in place. It reports whether any oneofs were added.
+//
+// The protobuf compiler declares a synthetic oneof for every field in
+// a proto3 file that is marked with proto3_optional, but descriptors produced
+// by other tools may omit them. Normalizing such descriptors before calling
+// [NewFile] ensures that the resulting fields are reported as belonging to
+// a synthetic oneof, as they would be for a descriptor produced by protoc.
+//
+// The synthetic oneofs are named in the same way as by protoc: the name of
+// the field prefixed by an underscore, with further "X" prefixes until the
+// name does not conflict with any field or oneof in the message. They are
+// declared after all other oneofs, in the order of their fields.
+func SynthesizeProto3Optional(fd *descriptorpb.FileDescriptorProto) bool {
+	if fd.GetSyntax() != "proto3" {
+		return false
+	}
+	var changed bool
+	for _, md := range fd.GetMessageType() {
+		changed = synthesizeMessageProto3Optional(md) || changed
+	}
+	return changed
+}
+
+func synthesizeMessageProto3Optional(md *descriptorpb.DescriptorProto) bool {
+	var changed bool
+	for _, nmd := range md.GetNestedType() {
+		changed = synthesizeMessageProto3Optional(nmd) || changed
+	}
+
+	var names map[string]bool
+	for _, f := range md.GetField() {
+		if !f.GetProto3Optional() || f.OneofIndex != nil {
+			continue
+		}
+		if names == nil {
+			names = make(map[string]bool)
+			for _, f := range md.GetField() {
+				names[f.GetName()] = true
+			}
+			for _, od := range md.GetOneofDecl() {
+				names[od.GetName()] = true
+			}
+		}
+		name := f.GetName()
+		if !strings.HasPrefix(name, "_") {
+			name = "_" + name
+		}
+		for names[name] {
+			name = "X" + name
+		}
+		names[name] = true
+		f.OneofIndex = proto.Int32(int32(len(md.OneofDecl)))
+		md.OneofDecl = append(md.OneofDecl, &descriptorpb.OneofDescriptorProto{Name: proto.String(name)})
+		changed = true
+	}
+	return changed
+}
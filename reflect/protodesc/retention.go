@@ -0,0 +1,46 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protodesc
+
+import (
+	"google.golang.org/protobuf/internal/genid"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protopath"
+	"google.golang.org/protobuf/reflect/protorange"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// StripSourceRetention removes the information in a descriptor message that
+// is only meant to be retained in source and not at runtime, which it
+// modifies in place. It clears the source code info, which holds the
+// comments and locations of declarations, and the value of every field
+// that is declared with the [retention = RETENTION_SOURCE] option, such as
+// a custom option that is only used by code generators.
+//
+// The message is typically a FileDescriptorProto or a FileDescriptorSet,
+// such as one obtained from [ToFileDescriptorProto] before publishing it.
+// Custom options are only inspected if their extension was resolved when
+// the message was unmarshaled; options held as unknown fields are retained.
+func StripSourceRetention(m proto.Message) {
+	protorange.Range(m.ProtoReflect(), func(p protopath.Values) error {
+		m, ok := p.Index(-1).Value.Interface().(protoreflect.Message)
+		if !ok {
+			return nil
+		}
+		isFile := m.Descriptor().FullName() == genid.FileDescriptorProto_message_fullname
+		m.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+			opts, ok := fd.Options().(*descriptorpb.FieldOptions)
+			switch {
+			case isFile && fd.Number() == genid.FileDescriptorProto_SourceCodeInfo_field_number:
+				m.Clear(fd)
+			case ok && opts.GetRetention() == descriptorpb.FieldOptions_RETENTION_SOURCE:
+				m.Clear(fd)
+			}
+			return true
+		})
+		return nil
+	})
+}
@@ -0,0 +1,83 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protodesc
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestStripSourceRetention(t *testing.T) {
+	optsFile := new(descriptorpb.FileDescriptorProto)
+	if err := prototext.Unmarshal([]byte(`
+		name: "retention_options.proto"
+		package: "test"
+		dependency: "google/protobuf/descriptor.proto"
+		extension: {name: "source_option" number: 50000 label: LABEL_OPTIONAL type: TYPE_INT32 extendee: ".google.protobuf.FieldOptions" options: {retention: RETENTION_SOURCE}}
+		extension: {name: "runtime_option" number: 50001 label: LABEL_OPTIONAL type: TYPE_INT32 extendee: ".google.protobuf.FieldOptions" options: {retention: RETENTION_RUNTIME}}
+	`), optsFile); err != nil {
+		t.Fatal(err)
+	}
+	fd, err := NewFile(optsFile, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sourceOption := dynamicpb.NewExtensionType(fd.Extensions().ByName("source_option"))
+	runtimeOption := dynamicpb.NewExtensionType(fd.Extensions().ByName("runtime_option"))
+
+	newOptions := func(withSource bool) *descriptorpb.FieldOptions {
+		opts := &descriptorpb.FieldOptions{Deprecated: proto.Bool(true)}
+		if withSource {
+			proto.SetExtension(opts, sourceOption, int32(1))
+		}
+		proto.SetExtension(opts, runtimeOption, int32(2))
+		return opts
+	}
+	newFile := func(stripped bool) *descriptorpb.FileDescriptorProto {
+		file := &descriptorpb.FileDescriptorProto{
+			Name: proto.String("retention.proto"),
+			MessageType: []*descriptorpb.DescriptorProto{{
+				Name: proto.String("M"),
+				Field: []*descriptorpb.FieldDescriptorProto{{
+					Name:    proto.String("f"),
+					Number:  proto.Int32(1),
+					Options: newOptions(!stripped),
+				}},
+				NestedType: []*descriptorpb.DescriptorProto{{
+					Name: proto.String("N"),
+					Field: []*descriptorpb.FieldDescriptorProto{{
+						Name:    proto.String("g"),
+						Number:  proto.Int32(1),
+						Options: newOptions(!stripped),
+					}},
+				}},
+			}},
+		}
+		if !stripped {
+			file.SourceCodeInfo = &descriptorpb.SourceCodeInfo{
+				Location: []*descriptorpb.SourceCodeInfo_Location{{
+					Path:            []int32{4, 0},
+					Span:            []int32{1, 0, 3, 1},
+					LeadingComments: proto.String(" Internal comment.\n"),
+				}},
+			}
+		}
+		return file
+	}
+
+	got := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{newFile(false)}}
+	StripSourceRetention(got)
+	want := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{newFile(true)}}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("StripSourceRetention() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,719 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package typedesc converts between descriptors and the older
+// google.protobuf.Type, google.protobuf.Enum, and google.protobuf.Api
+// representations of message, enum, and service types.
+//
+// These messages are used by some APIs that predate descriptors. Each of them
+// describes a single type without its nested declarations, and references
+// the types of message and enum fields and of method inputs and outputs
+// by type URL.
+//
+// Options are represented as a list of name and value pairs. The name of a
+// built-in option is its field name, such as "deprecated", and the name of a
+// custom option is the full name of its extension field. The value is packed
+// in an Any message; scalar values are wrapped in the corresponding type of
+// the wrapperspb package, and enum values in an Int32Value.
+package typedesc
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/internal/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/apipb"
+	"google.golang.org/protobuf/types/known/sourcecontextpb"
+	"google.golang.org/protobuf/types/known/typepb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// ToType copies a [protoreflect.MessageDescriptor] into a
+// google.protobuf.Type message.
+//
+// Type URLs are formed with the prefix configured for
+// [protoregistry.GlobalTypes]. Nested declarations are not copied,
+// and options stored as unknown fields are omitted.
+// It returns an error if the value of an option cannot be marshaled.
+func ToType(message protoreflect.MessageDescriptor) (*typepb.Type, error) {
+	options, err := toOptions(message.Options())
+	if err != nil {
+		return nil, err
+	}
+	t := &typepb.Type{
+		Name:          string(message.FullName()),
+		Options:       options,
+		SourceContext: toSourceContext(message),
+	}
+	t.Syntax, t.Edition = toSyntax(message.ParentFile())
+	for i, fields := 0, message.Fields(); i < fields.Len(); i++ {
+		f, err := toField(fields.Get(i))
+		if err != nil {
+			return nil, err
+		}
+		t.Fields = append(t.Fields, f)
+	}
+	for i, oneofs := 0, message.Oneofs(); i < oneofs.Len(); i++ {
+		t.Oneofs = append(t.Oneofs, string(oneofs.Get(i).Name()))
+	}
+	return t, nil
+}
+
+func toField(field protoreflect.FieldDescriptor) (*typepb.Field, error) {
+	options, err := toOptions(field.Options())
+	if err != nil {
+		return nil, err
+	}
+	f := &typepb.Field{
+		Kind:        typepb.Field_Kind(field.Kind()),
+		Cardinality: typepb.Field_Cardinality(field.Cardinality()),
+		Number:      int32(field.Number()),
+		Name:        string(field.Name()),
+		Packed:      field.IsPacked(),
+		Options:     options,
+		JsonName:    field.JSONName(),
+	}
+	switch {
+	case field.Message() != nil:
+		f.TypeUrl = typeURL(field.Message())
+	case field.Enum() != nil:
+		f.TypeUrl = typeURL(field.Enum())
+	}
+	if oneof := field.ContainingOneof(); oneof != nil {
+		f.OneofIndex = int32(oneof.Index()) + 1
+	}
+	if field.HasDefault() {
+		f.DefaultValue = protodesc.ToFieldDescriptorProto(field).GetDefaultValue()
+	}
+	return f, nil
+}
+
+// ToEnum copies a [protoreflect.EnumDescriptor] into a
+// google.protobuf.Enum message.
+// It returns an error if the value of an option cannot be marshaled.
+func ToEnum(enum protoreflect.EnumDescriptor) (*typepb.Enum, error) {
+	options, err := toOptions(enum.Options())
+	if err != nil {
+		return nil, err
+	}
+	e := &typepb.Enum{
+		Name:          string(enum.FullName()),
+		Options:       options,
+		SourceContext: toSourceContext(enum),
+	}
+	e.Syntax, e.Edition = toSyntax(enum.ParentFile())
+	for i, values := 0, enum.Values(); i < values.Len(); i++ {
+		value := values.Get(i)
+		options, err := toOptions(value.Options())
+		if err != nil {
+			return nil, err
+		}
+		e.Enumvalue = append(e.Enumvalue, &typepb.EnumValue{
+			Name:    string(value.Name()),
+			Number:  int32(value.Number()),
+			Options: options,
+		})
+	}
+	return e, nil
+}
+
+// ToApi copies a [protoreflect.ServiceDescriptor] into a
+// google.protobuf.Api message.
+// It returns an error if the value of an option cannot be marshaled.
+func ToApi(service protoreflect.ServiceDescriptor) (*apipb.Api, error) {
+	options, err := toOptions(service.Options())
+	if err != nil {
+		return nil, err
+	}
+	a := &apipb.Api{
+		Name:          string(service.FullName()),
+		Options:       options,
+		SourceContext: toSourceContext(service),
+	}
+	a.Syntax, _ = toSyntax(service.ParentFile())
+	for i, methods := 0, service.Methods(); i < methods.Len(); i++ {
+		method := methods.Get(i)
+		options, err := toOptions(method.Options())
+		if err != nil {
+			return nil, err
+		}
+		a.Methods = append(a.Methods, &apipb.Method{
+			Name:              string(method.Name()),
+			RequestTypeUrl:    typeURL(method.Input()),
+			RequestStreaming:  method.IsStreamingClient(),
+			ResponseTypeUrl:   typeURL(method.Output()),
+			ResponseStreaming: method.IsStreamingServer(),
+			Options:           options,
+			Syntax:            a.Syntax,
+		})
+	}
+	return a, nil
+}
+
+func typeURL(d protoreflect.Descriptor) string {
+	return protoregistry.GlobalTypes.TypeURLPrefix() + string(d.FullName())
+}
+
+func toSourceContext(d protoreflect.Descriptor) *sourcecontextpb.SourceContext {
+	if file := d.ParentFile(); file != nil && file.Path() != "" {
+		return &sourcecontextpb.SourceContext{FileName: file.Path()}
+	}
+	return nil
+}
+
+func toSyntax(file protoreflect.FileDescriptor) (typepb.Syntax, string) {
+	if file == nil {
+		return typepb.Syntax_SYNTAX_PROTO2, ""
+	}
+	switch file.Syntax() {
+	case protoreflect.Proto3:
+		return typepb.Syntax_SYNTAX_PROTO3, ""
+	case protoreflect.Editions:
+		edition := protodesc.ToFileDescriptorProto(file).GetEdition()
+		return typepb.Syntax_SYNTAX_EDITIONS, strings.TrimPrefix(edition.String(), "EDITION_")
+	default:
+		return typepb.Syntax_SYNTAX_PROTO2, ""
+	}
+}
+
+// toOptions converts the populated fields of an options message into
+// a list of options, ordered by field number.
+func toOptions(opts protoreflect.ProtoMessage) ([]*typepb.Option, error) {
+	if opts == nil {
+		return nil, nil
+	}
+	m := opts.ProtoReflect()
+	if !m.IsValid() {
+		return nil, nil
+	}
+	var fds []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if !fd.IsMap() {
+			fds = append(fds, fd)
+		}
+		return true
+	})
+	sort.Slice(fds, func(i, j int) bool {
+		return fds[i].Number() < fds[j].Number()
+	})
+
+	var options []*typepb.Option
+	for _, fd := range fds {
+		name := string(fd.Name())
+		if fd.IsExtension() {
+			name = string(fd.FullName())
+		}
+		v := m.Get(fd)
+		if !fd.IsList() {
+			a, err := toOptionValue(fd, v)
+			if err != nil {
+				return nil, err
+			}
+			options = append(options, &typepb.Option{Name: name, Value: a})
+			continue
+		}
+		for i, list := 0, v.List(); i < list.Len(); i++ {
+			a, err := toOptionValue(fd, list.Get(i))
+			if err != nil {
+				return nil, err
+			}
+			options = append(options, &typepb.Option{Name: name, Value: a})
+		}
+	}
+	return options, nil
+}
+
+func toOptionValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) (*anypb.Any, error) {
+	var m proto.Message
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		m = wrapperspb.Bool(v.Bool())
+	case protoreflect.EnumKind:
+		m = wrapperspb.Int32(int32(v.Enum()))
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		m = wrapperspb.Int32(int32(v.Int()))
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		m = wrapperspb.Int64(v.Int())
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		m = wrapperspb.UInt32(uint32(v.Uint()))
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		m = wrapperspb.UInt64(v.Uint())
+	case protoreflect.FloatKind:
+		m = wrapperspb.Float(float32(v.Float()))
+	case protoreflect.DoubleKind:
+		m = wrapperspb.Double(v.Float())
+	case protoreflect.StringKind:
+		m = wrapperspb.String(v.String())
+	case protoreflect.BytesKind:
+		m = wrapperspb.Bytes(v.Bytes())
+	default:
+		m = v.Message().Interface()
+	}
+	a, err := anypb.NewWithOptions(m, proto.MarshalOptions{AllowPartial: true, Deterministic: true})
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid value for option %v", fd.FullName())
+	}
+	return a, nil
+}
+
+// NewMessage creates a new [protoreflect.MessageDescriptor] from the provided
+// google.protobuf.Type message.
+//
+// The message is declared in a new file of its own, which is not registered
+// anywhere. The types referenced by type URL are resolved by full name using r,
+// as are custom options. The types of map fields and proto2 group fields are
+// copied into the new message as nested declarations, as required for such
+// fields; all other referenced types remain those found in r.
+//
+// Some information cannot be represented by a google.protobuf.Type,
+// such as whether a singular field in an editions file tracks presence,
+// so the result may not be identical to the descriptor it was created from.
+func NewMessage(t *typepb.Type, r protodesc.Resolver) (protoreflect.MessageDescriptor, error) {
+	c, err := newTypeConverter(t.GetName(), t.GetSyntax(), t.GetEdition(), r)
+	if err != nil {
+		return nil, err
+	}
+	name := protoreflect.FullName(t.GetName())
+	m := &descriptorpb.DescriptorProto{
+		Name: proto.String(string(name.Name())),
+	}
+	if opts := t.GetOptions(); len(opts) > 0 {
+		m.Options = &descriptorpb.MessageOptions{}
+		if err := c.unmarshalOptions(opts, m.Options); err != nil {
+			return nil, err
+		}
+	}
+	for _, o := range t.GetOneofs() {
+		m.OneofDecl = append(m.OneofDecl, &descriptorpb.OneofDescriptorProto{Name: proto.String(o)})
+	}
+	for _, f := range t.GetFields() {
+		fd, err := c.newField(m, f)
+		if err != nil {
+			return nil, err
+		}
+		m.Field = append(m.Field, fd)
+	}
+	c.file.MessageType = []*descriptorpb.DescriptorProto{m}
+	file, err := c.newFile()
+	if err != nil {
+		return nil, err
+	}
+	return file.Messages().Get(0), nil
+}
+
+// NewEnum creates a new [protoreflect.EnumDescriptor] from the provided
+// google.protobuf.Enum message. Custom options are resolved using r.
+// See [NewMessage] for more information.
+func NewEnum(e *typepb.Enum, r protodesc.Resolver) (protoreflect.EnumDescriptor, error) {
+	c, err := newTypeConverter(e.GetName(), e.GetSyntax(), e.GetEdition(), r)
+	if err != nil {
+		return nil, err
+	}
+	name := protoreflect.FullName(e.GetName())
+	ed := &descriptorpb.EnumDescriptorProto{
+		Name: proto.String(string(name.Name())),
+	}
+	if opts := e.GetOptions(); len(opts) > 0 {
+		ed.Options = &descriptorpb.EnumOptions{}
+		if err := c.unmarshalOptions(opts, ed.Options); err != nil {
+			return nil, err
+		}
+	}
+	for _, v := range e.GetEnumvalue() {
+		vd := &descriptorpb.EnumValueDescriptorProto{
+			Name:   proto.String(v.GetName()),
+			Number: proto.Int32(v.GetNumber()),
+		}
+		if opts := v.GetOptions(); len(opts) > 0 {
+			vd.Options = &descriptorpb.EnumValueOptions{}
+			if err := c.unmarshalOptions(opts, vd.Options); err != nil {
+				return nil, err
+			}
+		}
+		ed.Value = append(ed.Value, vd)
+	}
+	c.file.EnumType = []*descriptorpb.EnumDescriptorProto{ed}
+	file, err := c.newFile()
+	if err != nil {
+		return nil, err
+	}
+	return file.Enums().Get(0), nil
+}
+
+// NewService creates a new [protoreflect.ServiceDescriptor] from the provided
+// google.protobuf.Api message. The input and output types of methods and
+// custom options are resolved using r. Mixins are not supported.
+// See [NewMessage] for more information.
+func NewService(a *apipb.Api, r protodesc.Resolver) (protoreflect.ServiceDescriptor, error) {
+	if len(a.GetMixins()) > 0 {
+		return nil, errors.New("%v: mixins are not supported", a.GetName())
+	}
+	c, err := newTypeConverter(a.GetName(), a.GetSyntax(), "", r)
+	if err != nil {
+		return nil, err
+	}
+	name := protoreflect.FullName(a.GetName())
+	sd := &descriptorpb.ServiceDescriptorProto{
+		Name: proto.String(string(name.Name())),
+	}
+	if opts := a.GetOptions(); len(opts) > 0 {
+		sd.Options = &descriptorpb.ServiceOptions{}
+		if err := c.unmarshalOptions(opts, sd.Options); err != nil {
+			return nil, err
+		}
+	}
+	for _, m := range a.GetMethods() {
+		md := &descriptorpb.MethodDescriptorProto{
+			Name: proto.String(m.GetName()),
+		}
+		input, err := c.resolve(m.GetRequestTypeUrl())
+		if err != nil {
+			return nil, err
+		}
+		output, err := c.resolve(m.GetResponseTypeUrl())
+		if err != nil {
+			return nil, err
+		}
+		md.InputType = fullNameOf(input)
+		md.OutputType = fullNameOf(output)
+		if m.GetRequestStreaming() {
+			md.ClientStreaming = proto.Bool(true)
+		}
+		if m.GetResponseStreaming() {
+			md.ServerStreaming = proto.Bool(true)
+		}
+		if opts := m.GetOptions(); len(opts) > 0 {
+			md.Options = &descriptorpb.MethodOptions{}
+			if err := c.unmarshalOptions(opts, md.Options); err != nil {
+				return nil, err
+			}
+		}
+		sd.Method = append(sd.Method, md)
+	}
+	c.file.Service = []*descriptorpb.ServiceDescriptorProto{sd}
+	file, err := c.newFile()
+	if err != nil {
+		return nil, err
+	}
+	return file.Services().Get(0), nil
+}
+
+// typeConverter builds a file declaring a single type
+// from its google.protobuf.Type or similar representation.
+type typeConverter struct {
+	r    protodesc.Resolver
+	name protoreflect.FullName
+	file *descriptorpb.FileDescriptorProto
+	deps map[string]bool
+}
+
+func newTypeConverter(name string, syntax typepb.Syntax, edition string, r protodesc.Resolver) (*typeConverter, error) {
+	if r == nil {
+		r = (*protoregistry.Files)(nil) // empty resolver
+	}
+	fullName := protoreflect.FullName(name)
+	if !fullName.IsValid() {
+		return nil, errors.New("invalid type name: %q", name)
+	}
+	c := &typeConverter{
+		r:    r,
+		name: fullName,
+		file: &descriptorpb.FileDescriptorProto{
+			Name: proto.String(strings.ReplaceAll(name, ".", "/") + ".proto"),
+		},
+		deps: make(map[string]bool),
+	}
+	if pkg := fullName.Parent(); pkg != "" {
+		c.file.Package = proto.String(string(pkg))
+	}
+	switch syntax {
+	case typepb.Syntax_SYNTAX_PROTO2:
+	case typepb.Syntax_SYNTAX_PROTO3:
+		c.file.Syntax = proto.String("proto3")
+	case typepb.Syntax_SYNTAX_EDITIONS:
+		e, ok := descriptorpb.Edition_value["EDITION_"+edition]
+		if !ok {
+			return nil, errors.New("%v: invalid edition %q", name, edition)
+		}
+		c.file.Syntax = proto.String("editions")
+		c.file.Edition = descriptorpb.Edition(e).Enum()
+	default:
+		return nil, errors.New("%v: invalid syntax %v", name, syntax)
+	}
+	return c, nil
+}
+
+func (c *typeConverter) newFile() (protoreflect.FileDescriptor, error) {
+	for dep := range c.deps {
+		c.file.Dependency = append(c.file.Dependency, dep)
+	}
+	sort.Strings(c.file.Dependency)
+	return protodesc.NewFile(c.file, c.r)
+}
+
+// resolve resolves the message or enum with the given type URL,
+// and records its file as a dependency.
+func (c *typeConverter) resolve(url string) (protoreflect.Descriptor, error) {
+	name := protoreflect.FullName(url[strings.LastIndexByte(url, '/')+1:])
+	d, err := c.r.FindDescriptorByName(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "%v: could not resolve %q", c.name, url)
+	}
+	switch d.(type) {
+	case protoreflect.MessageDescriptor, protoreflect.EnumDescriptor:
+	default:
+		return nil, errors.New("%v: %q is not a message or enum", c.name, url)
+	}
+	c.addDependency(d)
+	return d, nil
+}
+
+func fullNameOf(d protoreflect.Descriptor) *string {
+	return proto.String("." + string(d.FullName()))
+}
+
+func (c *typeConverter) addDependency(d protoreflect.Descriptor) {
+	if file := d.ParentFile(); file != nil && file.Path() != c.file.GetName() {
+		c.deps[file.Path()] = true
+	}
+}
+
+// addNested copies the message md into m as a nested declaration,
+// recording the dependencies of the types referenced by its fields.
+func (c *typeConverter) addNested(m *descriptorpb.DescriptorProto, md protoreflect.MessageDescriptor) string {
+	for _, nested := range m.NestedType {
+		if nested.GetName() == string(md.Name()) {
+			return "." + string(c.name) + "." + nested.GetName()
+		}
+	}
+	var walk func(protoreflect.MessageDescriptor)
+	walk = func(md protoreflect.MessageDescriptor) {
+		for i, fields := 0, md.Fields(); i < fields.Len(); i++ {
+			fd := fields.Get(i)
+			switch {
+			case fd.Message() != nil && fd.Message().FullName().Parent() != md.FullName():
+				c.addDependency(fd.Message())
+			case fd.Enum() != nil:
+				c.addDependency(fd.Enum())
+			}
+		}
+		for i, messages := 0, md.Messages(); i < messages.Len(); i++ {
+			walk(messages.Get(i))
+		}
+	}
+	walk(md)
+	m.NestedType = append(m.NestedType, protodesc.ToDescriptorProto(md))
+	return "." + string(c.name) + "." + string(md.Name())
+}
+
+func (c *typeConverter) newField(m *descriptorpb.DescriptorProto, f *typepb.Field) (*descriptorpb.FieldDescriptorProto, error) {
+	fd := &descriptorpb.FieldDescriptorProto{
+		Name:    proto.String(f.GetName()),
+		Number:  proto.Int32(f.GetNumber()),
+		Label:   descriptorpb.FieldDescriptorProto_Label(f.GetCardinality()).Enum(),
+		Type:    descriptorpb.FieldDescriptorProto_Type(f.GetKind()).Enum(),
+		Options: &descriptorpb.FieldOptions{},
+	}
+	if f.GetJsonName() != "" {
+		fd.JsonName = proto.String(f.GetJsonName())
+	}
+	if f.GetDefaultValue() != "" {
+		fd.DefaultValue = proto.String(f.GetDefaultValue())
+	}
+	if i := f.GetOneofIndex(); i > 0 {
+		if int(i) > len(m.OneofDecl) {
+			return nil, errors.New("%v.%v: invalid oneof index %v", c.name, f.GetName(), i)
+		}
+		fd.OneofIndex = proto.Int32(i - 1)
+		// Synthetic oneofs for proto3 optional fields are named by protoc
+		// after the field with a leading underscore.
+		if c.file.GetSyntax() == "proto3" && m.OneofDecl[i-1].GetName() == "_"+f.GetName() {
+			fd.Proto3Optional = proto.Bool(true)
+		}
+	}
+	if err := c.unmarshalOptions(f.GetOptions(), fd.Options); err != nil {
+		return nil, err
+	}
+
+	switch f.GetKind() {
+	case typepb.Field_TYPE_MESSAGE, typepb.Field_TYPE_GROUP, typepb.Field_TYPE_ENUM:
+		d, err := c.resolve(f.GetTypeUrl())
+		if err != nil {
+			return nil, err
+		}
+		fd.TypeName = fullNameOf(d)
+		if md, ok := d.(protoreflect.MessageDescriptor); ok {
+			isGroup := f.GetKind() == typepb.Field_TYPE_GROUP && c.file.GetSyntax() == ""
+			if md.IsMapEntry() || isGroup {
+				fd.TypeName = proto.String(c.addNested(m, md))
+			}
+		}
+	}
+
+	var isPackable bool
+	switch protoreflect.Kind(fd.GetType()) {
+	case protoreflect.StringKind, protoreflect.BytesKind, protoreflect.MessageKind, protoreflect.GroupKind:
+	default:
+		isPackable = fd.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+	}
+	switch c.file.GetSyntax() {
+	case "":
+		if isPackable && f.GetPacked() {
+			fd.Options.Packed = proto.Bool(true)
+		}
+	case "proto3":
+		if isPackable && !f.GetPacked() {
+			fd.Options.Packed = proto.Bool(false)
+		}
+	case "editions":
+		features := &descriptorpb.FeatureSet{}
+		if fd.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REQUIRED {
+			fd.Label = descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+			features.FieldPresence = descriptorpb.FeatureSet_LEGACY_REQUIRED.Enum()
+		}
+		if fd.GetType() == descriptorpb.FieldDescriptorProto_TYPE_GROUP {
+			fd.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+			features.MessageEncoding = descriptorpb.FeatureSet_DELIMITED.Enum()
+		}
+		// Repeated scalar fields are packed by default in all editions.
+		if isPackable && !f.GetPacked() {
+			features.RepeatedFieldEncoding = descriptorpb.FeatureSet_EXPANDED.Enum()
+		}
+		if proto.Size(features) > 0 {
+			if fd.Options.Features != nil {
+				proto.Merge(fd.Options.Features, features)
+			} else {
+				fd.Options.Features = features
+			}
+		}
+	}
+	if proto.Size(fd.Options) == 0 {
+		fd.Options = nil
+	}
+	return fd, nil
+}
+
+// unmarshalOptions sets the given options in the options message m.
+func (c *typeConverter) unmarshalOptions(options []*typepb.Option, m proto.Message) error {
+	md := m.ProtoReflect().Descriptor()
+	var b []byte
+	for _, opt := range options {
+		var fd protoreflect.FieldDescriptor
+		if name := protoreflect.FullName(opt.GetName()); !strings.Contains(string(name), ".") {
+			fd = md.Fields().ByName(name.Name())
+		} else if d, err := c.r.FindDescriptorByName(name); err == nil {
+			if xd, ok := d.(protoreflect.ExtensionDescriptor); ok && xd.ContainingMessage().FullName() == md.FullName() {
+				fd = xd
+			}
+		}
+		if fd == nil {
+			return errors.New("%v: unknown option %q", c.name, opt.GetName())
+		}
+		var err error
+		b, err = appendOptionValue(b, fd, opt.GetValue())
+		if err != nil {
+			return errors.Wrap(err, "%v: invalid value for option %q", c.name, opt.GetName())
+		}
+	}
+	return proto.UnmarshalOptions{AllowPartial: true}.Unmarshal(b, m)
+}
+
+// appendOptionValue appends the wire encoding of the option field fd
+// with the value packed in a.
+func appendOptionValue(b []byte, fd protoreflect.FieldDescriptor, a *anypb.Any) ([]byte, error) {
+	num := fd.Number()
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		if a.GetTypeUrl() == "" || a.MessageName() != fd.Message().FullName() {
+			return nil, errors.New("got %v, want %v", a.MessageName(), fd.Message().FullName())
+		}
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		return protowire.AppendBytes(b, a.GetValue()), nil
+	case protoreflect.GroupKind:
+		if a.GetTypeUrl() == "" || a.MessageName() != fd.Message().FullName() {
+			return nil, errors.New("got %v, want %v", a.MessageName(), fd.Message().FullName())
+		}
+		b = protowire.AppendTag(b, num, protowire.StartGroupType)
+		b = append(b, a.GetValue()...)
+		return protowire.AppendTag(b, num, protowire.EndGroupType), nil
+	}
+
+	var w proto.Message
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		w = &wrapperspb.BoolValue{}
+	case protoreflect.EnumKind, protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		w = &wrapperspb.Int32Value{}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		w = &wrapperspb.Int64Value{}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		w = &wrapperspb.UInt32Value{}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		w = &wrapperspb.UInt64Value{}
+	case protoreflect.FloatKind:
+		w = &wrapperspb.FloatValue{}
+	case protoreflect.DoubleKind:
+		w = &wrapperspb.DoubleValue{}
+	case protoreflect.StringKind:
+		w = &wrapperspb.StringValue{}
+	case protoreflect.BytesKind:
+		w = &wrapperspb.BytesValue{}
+	default:
+		return nil, errors.New("invalid kind %v", fd.Kind())
+	}
+	if err := a.UnmarshalTo(w); err != nil {
+		return nil, err
+	}
+	wm := w.ProtoReflect()
+	v := wm.Get(wm.Descriptor().Fields().ByNumber(1))
+
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		b = protowire.AppendTag(b, num, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(v.Bool()))
+	case protoreflect.EnumKind, protoreflect.Int32Kind, protoreflect.Int64Kind:
+		b = protowire.AppendTag(b, num, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(v.Int()))
+	case protoreflect.Uint32Kind, protoreflect.Uint64Kind:
+		b = protowire.AppendTag(b, num, protowire.VarintType)
+		b = protowire.AppendVarint(b, v.Uint())
+	case protoreflect.Sint32Kind, protoreflect.Sint64Kind:
+		b = protowire.AppendTag(b, num, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeZigZag(v.Int()))
+	case protoreflect.Sfixed32Kind:
+		b = protowire.AppendTag(b, num, protowire.Fixed32Type)
+		b = protowire.AppendFixed32(b, uint32(v.Int()))
+	case protoreflect.Fixed32Kind:
+		b = protowire.AppendTag(b, num, protowire.Fixed32Type)
+		b = protowire.AppendFixed32(b, uint32(v.Uint()))
+	case protoreflect.FloatKind:
+		b = protowire.AppendTag(b, num, protowire.Fixed32Type)
+		b = protowire.AppendFixed32(b, math.Float32bits(float32(v.Float())))
+	case protoreflect.Sfixed64Kind:
+		b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, uint64(v.Int()))
+	case protoreflect.Fixed64Kind:
+		b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, v.Uint())
+	case protoreflect.DoubleKind:
+		b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(v.Float()))
+	case protoreflect.StringKind:
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		b = protowire.AppendString(b, v.String())
+	case protoreflect.BytesKind:
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		b = protowire.AppendBytes(b, v.Bytes())
+	}
+	return b, nil
+}
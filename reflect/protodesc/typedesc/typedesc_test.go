@@ -0,0 +1,279 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package typedesc_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protodesc/typedesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/apipb"
+	"google.golang.org/protobuf/types/known/typepb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	testpb "google.golang.org/protobuf/internal/testprotos/test"
+	test3pb "google.golang.org/protobuf/internal/testprotos/test3"
+	testeditionspb "google.golang.org/protobuf/internal/testprotos/testeditions"
+)
+
+var ignoreSourceContext = cmp.Options{
+	protocmp.Transform(),
+	protocmp.IgnoreFields(&typepb.Type{}, "source_context"),
+	protocmp.IgnoreFields(&typepb.Enum{}, "source_context"),
+	protocmp.IgnoreFields(&apipb.Api{}, "source_context"),
+}
+
+func TestTypeRoundTrip(t *testing.T) {
+	for _, m := range []proto.Message{
+		&testpb.TestAllTypes{},
+		&testpb.TestDeprecatedMessage{},
+		&test3pb.TestAllTypes{},
+		&testeditionspb.TestAllTypes{},
+	} {
+		md := m.ProtoReflect().Descriptor()
+		t.Run(string(md.FullName()), func(t *testing.T) {
+			typ, err := typedesc.ToType(md)
+			if err != nil {
+				t.Fatalf("ToType() error: %v", err)
+			}
+			got, err := typedesc.NewMessage(typ, protoregistry.GlobalFiles)
+			if err != nil {
+				t.Fatalf("NewMessage() error: %v", err)
+			}
+			typ2, err := typedesc.ToType(got)
+			if err != nil {
+				t.Fatalf("ToType(NewMessage()) error: %v", err)
+			}
+			if diff := cmp.Diff(typ, typ2, ignoreSourceContext); diff != "" {
+				t.Errorf("ToType(NewMessage()) mismatch (-want +got):\n%s", diff)
+			}
+			if got.FullName() != md.FullName() {
+				t.Errorf("NewMessage().FullName() = %v, want %v", got.FullName(), md.FullName())
+			}
+			for i := 0; i < md.Fields().Len(); i++ {
+				want := md.Fields().Get(i)
+				got := got.Fields().ByNumber(want.Number())
+				if got == nil {
+					t.Errorf("field %v: missing", want.FullName())
+					continue
+				}
+				for _, c := range []struct {
+					name      string
+					got, want any
+				}{
+					{"Name", got.Name(), want.Name()},
+					{"Kind", got.Kind(), want.Kind()},
+					{"Cardinality", got.Cardinality(), want.Cardinality()},
+					{"JSONName", got.JSONName(), want.JSONName()},
+					{"HasPresence", got.HasPresence(), want.HasPresence()},
+					{"IsPacked", got.IsPacked(), want.IsPacked()},
+					{"IsMap", got.IsMap(), want.IsMap()},
+					{"Default", got.Default().Interface(), want.Default().Interface()},
+					{"ContainingOneof", oneofName(got), oneofName(want)},
+				} {
+					if !cmp.Equal(c.got, c.want) {
+						t.Errorf("field %v: %v = %v, want %v", want.FullName(), c.name, c.got, c.want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func oneofName(fd protoreflect.FieldDescriptor) protoreflect.Name {
+	if od := fd.ContainingOneof(); od != nil {
+		return od.Name()
+	}
+	return ""
+}
+
+func TestEnumRoundTrip(t *testing.T) {
+	ed := testpb.TestDeprecatedMessage_DEPRECATED.Descriptor()
+	e, err := typedesc.ToEnum(ed)
+	if err != nil {
+		t.Fatalf("ToEnum() error: %v", err)
+	}
+	got, err := typedesc.NewEnum(e, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatalf("NewEnum() error: %v", err)
+	}
+	e2, err := typedesc.ToEnum(got)
+	if err != nil {
+		t.Fatalf("ToEnum(NewEnum()) error: %v", err)
+	}
+	if diff := cmp.Diff(e, e2, ignoreSourceContext); diff != "" {
+		t.Errorf("ToEnum(NewEnum()) mismatch (-want +got):\n%s", diff)
+	}
+	if !got.Values().Get(0).Options().(interface{ GetDeprecated() bool }).GetDeprecated() {
+		t.Errorf("NewEnum() did not preserve the deprecated option")
+	}
+}
+
+func TestApiRoundTrip(t *testing.T) {
+	for _, sd := range []protoreflect.ServiceDescriptor{
+		testpb.File_internal_testprotos_test_test_proto.Services().ByName("TestService"),
+		testpb.File_internal_testprotos_test_test_proto.Services().ByName("TestDeprecatedService"),
+	} {
+		t.Run(string(sd.FullName()), func(t *testing.T) {
+			a, err := typedesc.ToApi(sd)
+			if err != nil {
+				t.Fatalf("ToApi() error: %v", err)
+			}
+			got, err := typedesc.NewService(a, protoregistry.GlobalFiles)
+			if err != nil {
+				t.Fatalf("NewService() error: %v", err)
+			}
+			a2, err := typedesc.ToApi(got)
+			if err != nil {
+				t.Fatalf("ToApi(NewService()) error: %v", err)
+			}
+			if diff := cmp.Diff(a, a2, ignoreSourceContext); diff != "" {
+				t.Errorf("ToApi(NewService()) mismatch (-want +got):\n%s", diff)
+			}
+			for i := 0; i < sd.Methods().Len(); i++ {
+				want := sd.Methods().Get(i)
+				got := got.Methods().Get(i)
+				if got.Input().FullName() != want.Input().FullName() ||
+					got.Output().FullName() != want.Output().FullName() ||
+					got.IsStreamingClient() != want.IsStreamingClient() ||
+					got.IsStreamingServer() != want.IsStreamingServer() {
+					t.Errorf("method %v does not match the original", want.FullName())
+				}
+			}
+		})
+	}
+}
+
+func TestToType(t *testing.T) {
+	typ, err := typedesc.ToType((&testpb.TestDeprecatedMessage{}).ProtoReflect().Descriptor())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &typepb.Type{
+		Name:   "goproto.proto.test.TestDeprecatedMessage",
+		Fields: typ.GetFields(),
+		Oneofs: []string{"deprecated_oneof"},
+		Options: []*typepb.Option{{
+			Name:  "deprecated",
+			Value: mustNewAny(t, wrapperspb.Bool(true)),
+		}},
+		SourceContext: typ.GetSourceContext(),
+		Syntax:        typepb.Syntax_SYNTAX_PROTO2,
+	}
+	if diff := cmp.Diff(want, typ, protocmp.Transform()); diff != "" {
+		t.Errorf("ToType() mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := typ.GetSourceContext().GetFileName(), "internal/testprotos/test/test.proto"; got != want {
+		t.Errorf("ToType().SourceContext.FileName = %q, want %q", got, want)
+	}
+}
+
+func TestToTypeInvalidOption(t *testing.T) {
+	// A proto2 string option may hold invalid UTF-8,
+	// which cannot be packed in a StringValue.
+	extFile, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("ext.proto"),
+		Package:    proto.String("ext"),
+		Dependency: []string{"google/protobuf/descriptor.proto"},
+		Extension: []*descriptorpb.FieldDescriptorProto{{
+			Name:     proto.String("string_option"),
+			Number:   proto.Int32(50000),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			Extendee: proto.String(".google.protobuf.MessageOptions"),
+		}},
+	}, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := &descriptorpb.MessageOptions{}
+	proto.SetExtension(opts, dynamicpb.NewExtensionType(extFile.Extensions().Get(0)), "\xff")
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:        proto.String("message.proto"),
+		MessageType: []*descriptorpb.DescriptorProto{{Name: proto.String("M"), Options: opts}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := typedesc.ToType(file.Messages().Get(0)); err == nil {
+		t.Errorf("ToType() with invalid option value succeeded, want error")
+	}
+}
+
+func TestNewMessageErrors(t *testing.T) {
+	for _, tt := range []struct {
+		desc string
+		typ  *typepb.Type
+	}{{
+		desc: "unresolvable type URL",
+		typ: &typepb.Type{
+			Name: "test.Message",
+			Fields: []*typepb.Field{{
+				Kind:        typepb.Field_TYPE_MESSAGE,
+				Cardinality: typepb.Field_CARDINALITY_OPTIONAL,
+				Number:      1,
+				Name:        "field",
+				TypeUrl:     "type.googleapis.com/test.Missing",
+			}},
+		},
+	}, {
+		desc: "unknown option",
+		typ: &typepb.Type{
+			Name: "test.Message",
+			Options: []*typepb.Option{{
+				Name:  "no_such_option",
+				Value: mustNewAny(t, wrapperspb.Bool(true)),
+			}},
+		},
+	}, {
+		desc: "mismatched option type",
+		typ: &typepb.Type{
+			Name: "test.Message",
+			Options: []*typepb.Option{{
+				Name:  "deprecated",
+				Value: mustNewAny(t, wrapperspb.String("true")),
+			}},
+		},
+	}, {
+		desc: "duplicate field number",
+		typ: &typepb.Type{
+			Name: "test.Message",
+			Fields: []*typepb.Field{{
+				Kind:        typepb.Field_TYPE_INT32,
+				Cardinality: typepb.Field_CARDINALITY_OPTIONAL,
+				Number:      1,
+				Name:        "a",
+			}, {
+				Kind:        typepb.Field_TYPE_INT32,
+				Cardinality: typepb.Field_CARDINALITY_OPTIONAL,
+				Number:      1,
+				Name:        "b",
+			}},
+		},
+	}} {
+		t.Run(tt.desc, func(t *testing.T) {
+			if _, err := typedesc.NewMessage(tt.typ, protoregistry.GlobalFiles); err == nil {
+				t.Errorf("NewMessage() succeeded, want error")
+			}
+		})
+	}
+}
+
+func mustNewAny(t *testing.T, m proto.Message) *anypb.Any {
+	a, err := anypb.New(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
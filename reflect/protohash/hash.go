@@ -0,0 +1,166 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package protohash computes hashes of the contents of protobuf messages.
+//
+// The hash of a message depends only on its semantic content, so that
+// messages which are equal according to [proto.Equal] have the same hash.
+// In particular:
+//
+//   - Fields are hashed in order of field number, regardless of the order
+//     in which they were set or parsed, and map entries are hashed in order
+//     of their keys.
+//
+//   - All NaN values of a floating-point field hash the same,
+//     as do positive and negative zero.
+//
+//   - Unknown fields are hashed in order of field number. Unknown fields
+//     with the same number are hashed in the order in which they appear.
+//
+//   - Empty and nil bytes values hash the same.
+//
+// The hash is stable across releases of this module and does not depend
+// on the wire encoding of the message. Unequal messages may have the same
+// hash, so hashes are suitable for use as cache keys or for deduplication
+// only when paired with a comparison of the messages themselves,
+// or when collisions are otherwise tolerable.
+package protohash
+
+import (
+	"crypto/sha256"
+	"hash/fnv"
+	"math"
+	"sort"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/internal/order"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Sum64 returns a 64-bit FNV-1a hash of the contents of m.
+func Sum64(m proto.Message) uint64 {
+	h := fnv.New64a()
+	h.Write(appendMessage(nil, m))
+	return h.Sum64()
+}
+
+// Sum256 returns the SHA-256 hash of the contents of m.
+func Sum256(m proto.Message) [sha256.Size]byte {
+	return sha256.Sum256(appendMessage(nil, m))
+}
+
+// appendMessage appends an unambiguous representation of m to b,
+// from which the hashes are computed.
+func appendMessage(b []byte, m proto.Message) []byte {
+	if m == nil {
+		return append(b, 0)
+	}
+	mr := m.ProtoReflect()
+	if !mr.IsValid() {
+		b = append(b, 1)
+	} else {
+		b = append(b, 2)
+	}
+	b = protowire.AppendString(b, string(mr.Descriptor().FullName()))
+	return appendFields(b, mr)
+}
+
+// appendFields appends the populated fields of m, followed by a field
+// number of zero and the unknown fields of m.
+func appendFields(b []byte, m protoreflect.Message) []byte {
+	order.RangeFields(m, order.NumberFieldOrder, func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		b = protowire.AppendVarint(b, uint64(fd.Number()))
+		switch {
+		case fd.IsList():
+			b = appendList(b, fd, v.List())
+		case fd.IsMap():
+			b = appendMap(b, fd, v.Map())
+		default:
+			b = appendValue(b, fd, v)
+		}
+		return true
+	})
+	b = protowire.AppendVarint(b, 0)
+	return appendUnknown(b, m.GetUnknown())
+}
+
+func appendList(b []byte, fd protoreflect.FieldDescriptor, list protoreflect.List) []byte {
+	b = protowire.AppendVarint(b, uint64(list.Len()))
+	for i := 0; i < list.Len(); i++ {
+		b = appendValue(b, fd, list.Get(i))
+	}
+	return b
+}
+
+func appendMap(b []byte, fd protoreflect.FieldDescriptor, mapv protoreflect.Map) []byte {
+	b = protowire.AppendVarint(b, uint64(mapv.Len()))
+	keyf, valf := fd.MapKey(), fd.MapValue()
+	order.RangeEntries(mapv, order.GenericKeyOrder, func(k protoreflect.MapKey, v protoreflect.Value) bool {
+		b = appendValue(b, keyf, k.Value())
+		b = appendValue(b, valf, v)
+		return true
+	})
+	return b
+}
+
+func appendValue(b []byte, fd protoreflect.FieldDescriptor, v protoreflect.Value) []byte {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return protowire.AppendVarint(b, protowire.EncodeBool(v.Bool()))
+	case protoreflect.EnumKind:
+		return protowire.AppendVarint(b, uint64(v.Enum()))
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return protowire.AppendVarint(b, uint64(v.Int()))
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return protowire.AppendVarint(b, v.Uint())
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		f := v.Float()
+		switch {
+		case math.IsNaN(f):
+			f = math.NaN()
+		case f == 0:
+			f = 0 // normalize negative zero
+		}
+		return protowire.AppendFixed64(b, math.Float64bits(f))
+	case protoreflect.StringKind:
+		return protowire.AppendString(b, v.String())
+	case protoreflect.BytesKind:
+		return protowire.AppendBytes(b, v.Bytes())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		// Messages are length-prefixed so that the end of the
+		// nested unknown fields is unambiguous.
+		return protowire.AppendBytes(b, appendFields(nil, v.Message()))
+	}
+	panic("invalid kind")
+}
+
+// appendUnknown appends the unknown fields in raw, grouped by field number
+// in ascending order, in the same manner that [proto.Equal] compares them.
+func appendUnknown(b []byte, raw protoreflect.RawFields) []byte {
+	type field struct {
+		num protowire.Number
+		raw []byte
+	}
+	var fields []field
+	for len(raw) > 0 {
+		num, _, n := protowire.ConsumeField(raw)
+		if n < 0 {
+			fields = append(fields, field{protowire.MaxValidNumber + 1, raw})
+			break
+		}
+		fields = append(fields, field{num, raw[:n]})
+		raw = raw[n:]
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i].num < fields[j].num
+	})
+	b = protowire.AppendVarint(b, uint64(len(fields)))
+	for _, f := range fields {
+		b = protowire.AppendBytes(b, f.raw)
+	}
+	return b
+}
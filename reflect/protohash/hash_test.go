@@ -0,0 +1,155 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protohash_test
+
+import (
+	"math"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protohash"
+	"google.golang.org/protobuf/testing/protopack"
+
+	testpb "google.golang.org/protobuf/internal/testprotos/test"
+	test3pb "google.golang.org/protobuf/internal/testprotos/test3"
+)
+
+func TestEqualHashes(t *testing.T) {
+	tests := []struct {
+		desc string
+		x, y proto.Message
+	}{{
+		desc: "empty",
+		x:    &testpb.TestAllTypes{},
+		y:    &testpb.TestAllTypes{},
+	}, {
+		desc: "NaN",
+		x:    &testpb.TestAllTypes{OptionalDouble: proto.Float64(math.NaN())},
+		y:    &testpb.TestAllTypes{OptionalDouble: proto.Float64(math.Float64frombits(0x7ff8000000000001))},
+	}, {
+		desc: "float NaN",
+		x:    &testpb.TestAllTypes{OptionalFloat: proto.Float32(float32(math.NaN()))},
+		y:    &testpb.TestAllTypes{OptionalFloat: proto.Float32(math.Float32frombits(0x7fc00001))},
+	}, {
+		desc: "negative zero",
+		x:    &testpb.TestAllTypes{RepeatedDouble: []float64{0}},
+		y:    &testpb.TestAllTypes{RepeatedDouble: []float64{math.Copysign(0, -1)}},
+	}, {
+		desc: "nil and empty bytes",
+		x:    &testpb.TestAllTypes{RepeatedBytes: [][]byte{nil}},
+		y:    &testpb.TestAllTypes{RepeatedBytes: [][]byte{{}}},
+	}, {
+		desc: "map",
+		x: &test3pb.TestAllTypes{MapStringString: map[string]string{
+			"a": "1", "b": "2", "c": "3", "d": "4", "e": "5",
+		}},
+		y: &test3pb.TestAllTypes{MapStringString: map[string]string{
+			"e": "5", "d": "4", "c": "3", "b": "2", "a": "1",
+		}},
+	}, {
+		desc: "unknown field order",
+		x: unmarshal(t, protopack.Message{
+			protopack.Tag{1000, protopack.VarintType}, protopack.Varint(1),
+			protopack.Tag{2000, protopack.VarintType}, protopack.Varint(2),
+			protopack.Tag{1000, protopack.VarintType}, protopack.Varint(3),
+		}.Marshal()),
+		y: unmarshal(t, protopack.Message{
+			protopack.Tag{2000, protopack.VarintType}, protopack.Varint(2),
+			protopack.Tag{1000, protopack.VarintType}, protopack.Varint(1),
+			protopack.Tag{1000, protopack.VarintType}, protopack.Varint(3),
+		}.Marshal()),
+	}, {
+		desc: "field order",
+		x: unmarshal(t, protopack.Message{
+			protopack.Tag{1, protopack.VarintType}, protopack.Varint(1),
+			protopack.Tag{14, protopack.BytesType}, protopack.String("x"),
+		}.Marshal()),
+		y: unmarshal(t, protopack.Message{
+			protopack.Tag{14, protopack.BytesType}, protopack.String("x"),
+			protopack.Tag{1, protopack.VarintType}, protopack.Varint(1),
+		}.Marshal()),
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if !proto.Equal(tt.x, tt.y) {
+				t.Fatalf("test messages are not equal:\n%v\n%v", tt.x, tt.y)
+			}
+			if x, y := protohash.Sum64(tt.x), protohash.Sum64(tt.y); x != y {
+				t.Errorf("Sum64() = %x, %x; want equal hashes", x, y)
+			}
+			if x, y := protohash.Sum256(tt.x), protohash.Sum256(tt.y); x != y {
+				t.Errorf("Sum256() = %x, %x; want equal hashes", x, y)
+			}
+		})
+	}
+}
+
+func TestUnequalHashes(t *testing.T) {
+	tests := []struct {
+		desc string
+		x, y proto.Message
+	}{{
+		desc: "nil and empty message",
+		x:    (*testpb.TestAllTypes)(nil),
+		y:    &testpb.TestAllTypes{},
+	}, {
+		desc: "different types",
+		x:    &testpb.TestAllTypes{},
+		y:    &test3pb.TestAllTypes{},
+	}, {
+		desc: "unset and zero",
+		x:    &testpb.TestAllTypes{},
+		y:    &testpb.TestAllTypes{OptionalInt32: proto.Int32(0)},
+	}, {
+		desc: "different values",
+		x:    &testpb.TestAllTypes{OptionalInt32: proto.Int32(1)},
+		y:    &testpb.TestAllTypes{OptionalInt32: proto.Int32(2)},
+	}, {
+		desc: "different fields",
+		x:    &testpb.TestAllTypes{OptionalInt32: proto.Int32(1)},
+		y:    &testpb.TestAllTypes{OptionalInt64: proto.Int64(1)},
+	}, {
+		desc: "list boundaries",
+		x:    &testpb.TestAllTypes{RepeatedString: []string{"ab", "c"}},
+		y:    &testpb.TestAllTypes{RepeatedString: []string{"a", "bc"}},
+	}, {
+		desc: "nested message boundaries",
+		x: &testpb.TestAllTypes{
+			OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{A: proto.Int32(1)},
+		},
+		y: &testpb.TestAllTypes{
+			OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{},
+			OptionalInt32:         proto.Int32(1),
+		},
+	}, {
+		desc: "unknown fields with the same number",
+		x: unmarshal(t, protopack.Message{
+			protopack.Tag{1000, protopack.VarintType}, protopack.Varint(1),
+			protopack.Tag{1000, protopack.VarintType}, protopack.Varint(3),
+		}.Marshal()),
+		y: unmarshal(t, protopack.Message{
+			protopack.Tag{1000, protopack.VarintType}, protopack.Varint(3),
+			protopack.Tag{1000, protopack.VarintType}, protopack.Varint(1),
+		}.Marshal()),
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if x, y := protohash.Sum64(tt.x), protohash.Sum64(tt.y); x == y {
+				t.Errorf("Sum64() = %x for both messages, want different hashes", x)
+			}
+			if x, y := protohash.Sum256(tt.x), protohash.Sum256(tt.y); x == y {
+				t.Errorf("Sum256() = %x for both messages, want different hashes", x)
+			}
+		})
+	}
+}
+
+func unmarshal(t *testing.T, b []byte) proto.Message {
+	m := &testpb.TestAllTypes{}
+	if err := proto.Unmarshal(b, m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
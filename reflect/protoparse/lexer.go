@@ -0,0 +1,270 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protoparse
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type tokenKind uint8
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenInt
+	tokenFloat
+	tokenString
+	tokenPunct
+)
+
+// token is a single lexical element of a .proto source file.
+type token struct {
+	kind tokenKind
+	raw  string // the token as it appears in the source
+	str  string // the unescaped value of a string token
+	pos  position
+}
+
+// position is a location within a source file.
+type position struct {
+	line, col int
+}
+
+func (p position) String() string {
+	return strconv.Itoa(p.line) + ":" + strconv.Itoa(p.col)
+}
+
+// lexer splits a .proto source file into tokens.
+type lexer struct {
+	src  string
+	off  int
+	pos  position
+	peek *token
+}
+
+func newLexer(src []byte) *lexer {
+	return &lexer{src: string(src), pos: position{line: 1, col: 1}}
+}
+
+// Peek returns the next token without consuming it.
+func (l *lexer) Peek() (token, error) {
+	if l.peek == nil {
+		tok, err := l.read()
+		if err != nil {
+			return token{}, err
+		}
+		l.peek = &tok
+	}
+	return *l.peek, nil
+}
+
+// Next consumes and returns the next token.
+func (l *lexer) Next() (token, error) {
+	tok, err := l.Peek()
+	l.peek = nil
+	return tok, err
+}
+
+func (l *lexer) advance(n int) {
+	for _, r := range l.src[l.off : l.off+n] {
+		if r == '\n' {
+			l.pos.line++
+			l.pos.col = 1
+		} else {
+			l.pos.col++
+		}
+	}
+	l.off += n
+}
+
+func (l *lexer) read() (token, error) {
+	if err := l.skipSpaceAndComments(); err != nil {
+		return token{}, err
+	}
+	pos := l.pos
+	s := l.src[l.off:]
+	if len(s) == 0 {
+		return token{kind: tokenEOF, pos: pos}, nil
+	}
+	tok := token{pos: pos}
+	switch c := s[0]; {
+	case isLetter(c):
+		n := 1
+		for n < len(s) && (isLetter(s[n]) || isDigit(s[n])) {
+			n++
+		}
+		tok.kind, tok.raw = tokenIdent, s[:n]
+	case isDigit(c) || (c == '.' && len(s) > 1 && isDigit(s[1])):
+		n, isFloat := scanNumber(s)
+		tok.kind, tok.raw = tokenInt, s[:n]
+		if isFloat {
+			tok.kind = tokenFloat
+		}
+	case c == '"' || c == '\'':
+		n, str, err := scanString(s)
+		if err != nil {
+			return token{}, newError(pos, "%v", err)
+		}
+		tok.kind, tok.raw, tok.str = tokenString, s[:n], str
+	default:
+		switch c {
+		case '=', ';', '{', '}', '[', ']', '(', ')', '<', '>', ',', '.', ':', '-', '+', '/':
+			tok.kind, tok.raw = tokenPunct, s[:1]
+		default:
+			r, _ := utf8.DecodeRuneInString(s)
+			return token{}, newError(pos, "invalid character %q", r)
+		}
+	}
+	l.advance(len(tok.raw))
+	return tok, nil
+}
+
+func (l *lexer) skipSpaceAndComments() error {
+	for l.off < len(l.src) {
+		s := l.src[l.off:]
+		switch {
+		case s[0] == ' ' || s[0] == '\t' || s[0] == '\n' || s[0] == '\r' || s[0] == '\v' || s[0] == '\f':
+			l.advance(1)
+		case strings.HasPrefix(s, "//"):
+			n := strings.IndexByte(s, '\n')
+			if n < 0 {
+				n = len(s)
+			}
+			l.advance(n)
+		case strings.HasPrefix(s, "/*"):
+			n := strings.Index(s[2:], "*/")
+			if n < 0 {
+				return newError(l.pos, "unterminated block comment")
+			}
+			l.advance(n + 4)
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// scanNumber returns the length of the numeric literal at the start of s
+// and whether it is a floating-point literal.
+func scanNumber(s string) (n int, isFloat bool) {
+	if len(s) > 1 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		n = 2
+		for n < len(s) && isHexDigit(s[n]) {
+			n++
+		}
+		return n, false
+	}
+	for n < len(s) && isDigit(s[n]) {
+		n++
+	}
+	if n < len(s) && s[n] == '.' {
+		isFloat = true
+		n++
+		for n < len(s) && isDigit(s[n]) {
+			n++
+		}
+	}
+	if n < len(s) && (s[n] == 'e' || s[n] == 'E') {
+		isFloat = true
+		n++
+		if n < len(s) && (s[n] == '+' || s[n] == '-') {
+			n++
+		}
+		for n < len(s) && isDigit(s[n]) {
+			n++
+		}
+	}
+	return n, isFloat
+}
+
+// scanString returns the length and the unescaped value of
+// the quoted string literal at the start of s.
+func scanString(s string) (int, string, error) {
+	quote := s[0]
+	var b []byte
+	for i := 1; i < len(s); {
+		switch c := s[i]; c {
+		case quote:
+			return i + 1, string(b), nil
+		case '\n', 0:
+			return 0, "", errorf("unterminated string literal")
+		case '\\':
+			if i+1 >= len(s) {
+				return 0, "", errorf("unterminated string literal")
+			}
+			i++
+			switch c := s[i]; c {
+			case 'a':
+				b, i = append(b, '\a'), i+1
+			case 'b':
+				b, i = append(b, '\b'), i+1
+			case 'f':
+				b, i = append(b, '\f'), i+1
+			case 'n':
+				b, i = append(b, '\n'), i+1
+			case 'r':
+				b, i = append(b, '\r'), i+1
+			case 't':
+				b, i = append(b, '\t'), i+1
+			case 'v':
+				b, i = append(b, '\v'), i+1
+			case '\\', '\'', '"', '?':
+				b, i = append(b, c), i+1
+			case '0', '1', '2', '3', '4', '5', '6', '7':
+				n := 1
+				for n < 3 && i+n < len(s) && s[i+n] >= '0' && s[i+n] <= '7' {
+					n++
+				}
+				v, _ := strconv.ParseUint(s[i:i+n], 8, 32)
+				if v > 0xff {
+					return 0, "", errorf("invalid octal escape \\%s", s[i:i+n])
+				}
+				b, i = append(b, byte(v)), i+n
+			case 'x', 'X':
+				n := 0
+				for n < 2 && i+1+n < len(s) && isHexDigit(s[i+1+n]) {
+					n++
+				}
+				if n == 0 {
+					return 0, "", errorf("invalid hex escape")
+				}
+				v, _ := strconv.ParseUint(s[i+1:i+1+n], 16, 8)
+				b, i = append(b, byte(v)), i+1+n
+			case 'u', 'U':
+				n := 4
+				if c == 'U' {
+					n = 8
+				}
+				if i+1+n > len(s) {
+					return 0, "", errorf("invalid unicode escape")
+				}
+				v, err := strconv.ParseUint(s[i+1:i+1+n], 16, 32)
+				if err != nil || !utf8.ValidRune(rune(v)) {
+					return 0, "", errorf("invalid unicode escape \\%s", s[i:i+1+n])
+				}
+				b, i = utf8.AppendRune(b, rune(v)), i+1+n
+			default:
+				return 0, "", errorf("invalid escape \\%c", c)
+			}
+		default:
+			b, i = append(b, c), i+1
+		}
+	}
+	return 0, "", errorf("unterminated string literal")
+}
+
+func isLetter(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
@@ -0,0 +1,212 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protoparse
+
+import (
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// applyOptions sets the values of all options in the file whose names
+// can be resolved in files. Options that refer to an extension not present
+// in files remain in p.options.
+func (p *parser) applyOptions(files *protoregistry.Files) error {
+	r := &optionResolver{
+		files:    files,
+		types:    dynamicpb.NewTypes(files),
+		extTypes: make(map[protoreflect.FullName]protoreflect.ExtensionTypeDescriptor),
+	}
+	var unresolved []*option
+	for _, o := range p.options {
+		fds, err := o.resolve(r)
+		if err != nil {
+			return err
+		}
+		if fds == nil {
+			unresolved = append(unresolved, o)
+			continue
+		}
+		if err := o.apply(fds, r.types); err != nil {
+			return err
+		}
+		p.touched = append(p.touched, o.opts)
+	}
+	p.options = unresolved
+	return nil
+}
+
+// unresolvedOptionError reports the first option that could not be resolved.
+func (p *parser) unresolvedOptionError() error {
+	o := p.options[0]
+	for _, name := range o.name {
+		if name.isExtension {
+			return newError(o.pos, "unknown extension %q", name.name)
+		}
+	}
+	return newError(o.pos, "unknown option")
+}
+
+// normalizeOptions round-trips every options message that has been set
+// through the wire format. Custom options are set using dynamic extension
+// types; after this, extensions with a generated type in the global registry
+// use that type (as they would for a descriptor produced by protoc) and all
+// others are stored as unknown fields.
+func (p *parser) normalizeOptions() error {
+	for _, opts := range p.touched {
+		b, err := proto.MarshalOptions{Deterministic: true}.Marshal(opts)
+		if err != nil {
+			return err
+		}
+		proto.Reset(opts)
+		if err := proto.Unmarshal(b, opts); err != nil {
+			return err
+		}
+	}
+	p.touched = nil
+	return nil
+}
+
+// optionResolver resolves the extensions used as custom options.
+type optionResolver struct {
+	files *protoregistry.Files
+	types *dynamicpb.Types
+
+	// extTypes caches the type of each extension so that every option
+	// setting the same extension uses the same type.
+	extTypes map[protoreflect.FullName]protoreflect.ExtensionTypeDescriptor
+}
+
+// resolve returns the field for each component of the option name.
+// It returns nil if an extension in the name cannot be found.
+func (o *option) resolve(r *optionResolver) ([]protoreflect.FieldDescriptor, error) {
+	md := o.opts.ProtoReflect().Descriptor()
+	var fds []protoreflect.FieldDescriptor
+	for i, name := range o.name {
+		fd, err := o.field(md, name, r)
+		if fd == nil || err != nil {
+			return nil, err
+		}
+		if i < len(o.name)-1 {
+			if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+				return nil, newError(o.pos, "option %q is not a singular message", fd.FullName())
+			}
+			md = fd.Message()
+		} else if fd.IsMap() {
+			return nil, newError(o.pos, "map option %q is not supported", fd.FullName())
+		}
+		fds = append(fds, fd)
+	}
+	return fds, nil
+}
+
+// apply sets the option value in the options message,
+// where fds are the resolved fields of the option name.
+func (o *option) apply(fds []protoreflect.FieldDescriptor, types *dynamicpb.Types) error {
+	m := o.opts.ProtoReflect()
+	for _, fd := range fds[:len(fds)-1] {
+		m = m.Mutable(fd).Message()
+	}
+	fd := fds[len(fds)-1]
+	v, err := o.fieldValue(m, fd, types)
+	if err != nil {
+		return err
+	}
+	if fd.IsList() {
+		m.Mutable(fd).List().Append(v)
+		return nil
+	}
+	if m.Has(fd) && fd.Message() == nil {
+		return newError(o.pos, "option %q is already set", fd.FullName())
+	}
+	m.Set(fd, v)
+	return nil
+}
+
+// field resolves a single component of the option name
+// within the message md. It returns nil if an extension is not found.
+func (o *option) field(md protoreflect.MessageDescriptor, name optionName, r *optionResolver) (protoreflect.FieldDescriptor, error) {
+	if !name.isExtension {
+		fd := md.Fields().ByName(protoreflect.Name(name.name))
+		if fd == nil {
+			return nil, newError(o.pos, "unknown option %q for %v", name.name, md.FullName())
+		}
+		return fd, nil
+	}
+
+	// Resolve the extension name relative to the scope of the option.
+	var xd protoreflect.ExtensionDescriptor
+	if full := protoreflect.FullName(name.name); len(full) > 0 && full[0] == '.' {
+		xd = findExtension(r.files, full[1:])
+	} else {
+		for scope := o.scope; xd == nil; scope = scope.Parent() {
+			candidate := full
+			if scope != "" {
+				candidate = scope + "." + full
+			}
+			xd = findExtension(r.files, candidate)
+			if scope == "" {
+				break
+			}
+		}
+	}
+	if xd == nil {
+		return nil, nil
+	}
+	if xd.ContainingMessage().FullName() != md.FullName() {
+		return nil, newError(o.pos, "extension %q does not extend %v", xd.FullName(), md.FullName())
+	}
+	xtd := r.extTypes[xd.FullName()]
+	if xtd == nil {
+		xtd = dynamicpb.NewExtensionType(xd).TypeDescriptor()
+		r.extTypes[xd.FullName()] = xtd
+	}
+	return xtd, nil
+}
+
+func findExtension(files *protoregistry.Files, name protoreflect.FullName) protoreflect.ExtensionDescriptor {
+	d, _ := files.FindDescriptorByName(name)
+	xd, _ := d.(protoreflect.ExtensionDescriptor)
+	return xd
+}
+
+// fieldValue converts the option value to a value for the field fd of m.
+func (o *option) fieldValue(m protoreflect.Message, fd protoreflect.FieldDescriptor, types *dynamicpb.Types) (protoreflect.Value, error) {
+	v := o.value
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if !v.isAggregate {
+			return protoreflect.Value{}, unexpected(v.tok, "message value")
+		}
+		var mv protoreflect.Message
+		if fd.IsList() {
+			mv = m.Mutable(fd).List().NewElement().Message()
+		} else {
+			mv = m.Mutable(fd).Message()
+		}
+		src := mv.New().Interface()
+		uo := prototext.UnmarshalOptions{Resolver: types}
+		if err := uo.Unmarshal([]byte(v.aggregate), src); err != nil {
+			return protoreflect.Value{}, newError(o.pos, "invalid value for option %q: %v", fd.FullName(), err)
+		}
+		proto.Merge(mv.Interface(), src)
+		return protoreflect.ValueOfMessage(mv), nil
+	case protoreflect.EnumKind:
+		if !v.isAggregate && v.tok.kind == tokenIdent && !v.neg {
+			if ev := fd.Enum().Values().ByName(protoreflect.Name(v.tok.raw)); ev != nil {
+				return protoreflect.ValueOfEnum(ev.Number()), nil
+			}
+			return protoreflect.Value{}, newError(v.tok.pos, "unknown value %q for enum %v", v.tok.raw, fd.Enum().FullName())
+		}
+		return protoreflect.Value{}, unexpected(v.tok, "enum value name")
+	default:
+		if v.isAggregate {
+			return protoreflect.Value{}, unexpected(v.tok, "scalar value")
+		}
+		return scalarValue(fd.Kind(), v)
+	}
+}
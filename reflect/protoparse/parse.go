@@ -0,0 +1,251 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package protoparse parses .proto source files into file descriptors
+// without invoking protoc.
+//
+// The parser supports the proto2, proto3, and editions syntaxes, including
+// messages, enums, services, extensions, maps, groups, oneofs, reserved
+// ranges and names, and options. Custom options may use any extension
+// declared in the file itself or in an imported file.
+//
+// The parser does not populate source code info, and it does not
+// implement all of the validation performed by protoc beyond that
+// performed by [protodesc.NewFile].
+package protoparse
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/protobuf/internal/errors"
+	"google.golang.org/protobuf/internal/pragma"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// Options configures the parser.
+type Options struct {
+	pragma.NoUnkeyedLiterals
+
+	// ImportPaths is a list of directories searched, in order, for the files
+	// to parse and the files they import. Paths of files are relative to
+	// the import path in which they are found.
+	// If empty, the current directory is used.
+	ImportPaths []string
+
+	// Resolver resolves imported files that are not found in any of
+	// the ImportPaths, such as the well-known types.
+	// If nil, [protoregistry.GlobalFiles] is used.
+	Resolver interface {
+		FindFileByPath(string) (protoreflect.FileDescriptor, error)
+	}
+}
+
+// ParseFiles parses the .proto files with the provided paths along with
+// all the files they transitively import.
+//
+// The returned registry contains the parsed files and all their dependencies.
+func (o Options) ParseFiles(paths ...string) (*protoregistry.Files, error) {
+	l := &loader{
+		opts:    o,
+		files:   new(protoregistry.Files),
+		loading: make(map[string]bool),
+	}
+	if len(l.opts.ImportPaths) == 0 {
+		l.opts.ImportPaths = []string{"."}
+	}
+	if l.opts.Resolver == nil {
+		l.opts.Resolver = protoregistry.GlobalFiles
+	}
+	for _, p := range paths {
+		if _, err := l.load(p); err != nil {
+			return nil, err
+		}
+	}
+	return l.files, nil
+}
+
+// ParseDir parses all .proto files within the directory dir and
+// its subdirectories, along with all the files they transitively import.
+// The directory is searched for imports before any of the ImportPaths.
+//
+// The returned registry contains the parsed files and all their dependencies.
+func (o Options) ParseDir(dir string) (*protoregistry.Files, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(p) != ".proto" {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	o.ImportPaths = append([]string{dir}, o.ImportPaths...)
+	return o.ParseFiles(paths...)
+}
+
+// loader loads files and their dependencies into a registry.
+type loader struct {
+	opts    Options
+	files   *protoregistry.Files
+	loading map[string]bool // files currently being loaded
+}
+
+func (l *loader) load(p string) (protoreflect.FileDescriptor, error) {
+	if fd, err := l.files.FindFileByPath(p); err == nil {
+		return fd, nil
+	}
+	if l.loading[p] {
+		return nil, errors.New("import cycle involving %q", p)
+	}
+	l.loading[p] = true
+	defer delete(l.loading, p)
+
+	src, err := l.read(p)
+	if err != nil {
+		return nil, err
+	}
+	if src == nil {
+		fd, err := l.opts.Resolver.FindFileByPath(p)
+		if err == protoregistry.NotFound {
+			return nil, errors.New("could not find file %q", p)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := l.register(fd); err != nil {
+			return nil, err
+		}
+		return fd, nil
+	}
+
+	ps, err := parseFile(p, src)
+	if err != nil {
+		return nil, wrapError(p, err)
+	}
+	for _, dep := range ps.fd.GetDependency() {
+		if _, err := l.load(dep); err != nil {
+			return nil, err
+		}
+	}
+	if err := ps.applyOptions(l.files); err != nil {
+		return nil, wrapError(p, err)
+	}
+	register := l.files.RegisterFile
+	if len(ps.options) > 0 {
+		// The remaining options use extensions declared in the file itself.
+		// Register a preliminary version of the file to resolve them.
+		fd, err := protodesc.NewFile(ps.fd, l.files)
+		if err != nil {
+			return nil, errors.Wrap(err, "%s", p)
+		}
+		if err := l.files.RegisterFile(fd); err != nil {
+			return nil, err
+		}
+		register = l.files.ReplaceFile
+		if err := ps.applyOptions(l.files); err != nil {
+			l.files.DeleteFile(p)
+			return nil, wrapError(p, err)
+		}
+		if len(ps.options) > 0 {
+			l.files.DeleteFile(p)
+			return nil, wrapError(p, ps.unresolvedOptionError())
+		}
+	}
+	if err := ps.normalizeOptions(); err != nil {
+		return nil, err
+	}
+	fd, err := protodesc.NewFile(ps.fd, l.files)
+	if err != nil {
+		return nil, errors.Wrap(err, "%s", p)
+	}
+	if err := register(fd); err != nil {
+		return nil, err
+	}
+	return fd, nil
+}
+
+// read returns the contents of the file with the provided path,
+// or nil if it is not found in any of the import paths.
+func (l *loader) read(p string) ([]byte, error) {
+	if path.IsAbs(p) || strings.HasPrefix(path.Clean(p), "../") {
+		return nil, errors.New("file path %q must be relative to an import path", p)
+	}
+	for _, dir := range l.opts.ImportPaths {
+		b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(p)))
+		if err == nil {
+			return b, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// register registers a file obtained from the resolver
+// along with all the files it transitively imports.
+func (l *loader) register(fd protoreflect.FileDescriptor) error {
+	if _, err := l.files.FindFileByPath(fd.Path()); err == nil {
+		return nil
+	}
+	imports := fd.Imports()
+	for i := 0; i < imports.Len(); i++ {
+		if imp := imports.Get(i).FileDescriptor; !imp.IsPlaceholder() {
+			if err := l.register(imp); err != nil {
+				return err
+			}
+		}
+	}
+	return l.files.RegisterFile(fd)
+}
+
+// posError is an error at a position within a source file.
+type posError struct {
+	pos position
+	msg string
+}
+
+func (e *posError) Error() string {
+	return e.pos.String() + ": " + e.msg
+}
+
+func newError(pos position, f string, x ...any) error {
+	return &posError{pos: pos, msg: fmt.Sprintf(f, x...)}
+}
+
+// unexpected reports that tok was found where something else was expected.
+func unexpected(tok token, f string, x ...any) error {
+	found := fmt.Sprintf("%q", tok.raw)
+	if tok.kind == tokenEOF {
+		found = "end of file"
+	}
+	return newError(tok.pos, "unexpected %s, expected %s", found, fmt.Sprintf(f, x...))
+}
+
+// errorf returns an error without a position,
+// which is added by the caller.
+func errorf(f string, x ...any) error {
+	return fmt.Errorf(f, x...)
+}
+
+// wrapError prefixes an error with the path of the file in which it occurred.
+func wrapError(p string, err error) error {
+	return errors.New("%s:%v", p, err)
+}
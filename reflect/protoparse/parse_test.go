@@ -0,0 +1,214 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protoparse_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoparse"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	proto2pb "google.golang.org/protobuf/cmd/protoc-gen-go/testdata/proto2"
+	annotationpb "google.golang.org/protobuf/internal/testprotos/annotation"
+	newspb "google.golang.org/protobuf/internal/testprotos/news"
+	testpb "google.golang.org/protobuf/internal/testprotos/test"
+	test3pb "google.golang.org/protobuf/internal/testprotos/test3"
+	testeditionspb "google.golang.org/protobuf/internal/testprotos/testeditions"
+)
+
+func TestParseFilesMatchesProtoc(t *testing.T) {
+	for _, want := range []protoreflect.FileDescriptor{
+		testpb.File_internal_testprotos_test_test_proto,
+		testpb.File_internal_testprotos_test_ext_proto,
+		test3pb.File_internal_testprotos_test3_test_proto,
+		test3pb.File_internal_testprotos_test3_test_extension_proto,
+		testeditionspb.File_internal_testprotos_testeditions_test_proto,
+		annotationpb.File_internal_testprotos_annotation_annotation_proto,
+		newspb.File_internal_testprotos_news_news_proto,
+		proto2pb.File_cmd_protoc_gen_go_testdata_proto2_fields_proto,
+	} {
+		t.Run(want.Path(), func(t *testing.T) {
+			files, err := protoparse.Options{ImportPaths: []string{"../.."}}.ParseFiles(want.Path())
+			if err != nil {
+				t.Fatalf("ParseFiles() error: %v", err)
+			}
+			got, err := files.FindFileByPath(want.Path())
+			if err != nil {
+				t.Fatalf("FindFileByPath() error: %v", err)
+			}
+			if diff := cmp.Diff(protodesc.ToFileDescriptorProto(want), protodesc.ToFileDescriptorProto(got), protocmp.Transform()); diff != "" {
+				t.Errorf("parsed file mismatch (-protoc +parsed):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"foo/options.proto": `
+			syntax = "proto3";
+			package foo;
+			import "google/protobuf/descriptor.proto";
+			message Label {
+				string text = 1;
+				repeated int32 ids = 2;
+			}
+			extend google.protobuf.MessageOptions {
+				Label label = 50000;
+			}
+		`,
+		"bar/bar.proto": `
+			edition = "2023";
+			package bar;
+			import "foo/options.proto";
+			import "google/protobuf/descriptor.proto";
+			import "google/protobuf/timestamp.proto";
+			extend google.protobuf.FieldOptions {
+				string tag = 50001;
+			}
+			message Bar {
+				option (foo.label) = { text: "bar" ids: [1, 2] };
+				option (foo.label).ids = 3;
+				google.protobuf.Timestamp time = 1 [(tag) = "when"];
+				int32 count = 5 [features.field_presence = IMPLICIT];
+				map<string, Bar> children = 2;
+				oneof kind {
+					string name = 3;
+					int64 id = 4 [json_name = "ident"];
+				}
+				reserved 10 to 20, 100 to max;
+				reserved old_field;
+			}
+			service Service {
+				rpc Get(Bar) returns (stream .bar.Bar) { option deprecated = true; }
+			}
+		`,
+	})
+	files, err := protoparse.Options{}.ParseDir(dir)
+	if err != nil {
+		t.Fatalf("ParseDir() error: %v", err)
+	}
+	d, err := files.FindDescriptorByName("bar.Bar")
+	if err != nil {
+		t.Fatalf("FindDescriptorByName() error: %v", err)
+	}
+	md := d.(protoreflect.MessageDescriptor)
+
+	// Custom options are stored as unknown fields,
+	// since their extensions are not linked into the binary.
+	opts := md.Options().(*descriptorpb.MessageOptions)
+	xt := dynamicpb.NewExtensionType(md.ParentFile().Imports().Get(0).Extensions().ByName("label"))
+	opts = cloneWithResolver(t, opts, xt)
+	label := proto.GetExtension(opts, xt).(protoreflect.Message)
+	if got, want := label.Get(label.Descriptor().Fields().ByName("text")).String(), "bar"; got != want {
+		t.Errorf("label text = %q, want %q", got, want)
+	}
+	if got, want := label.Get(label.Descriptor().Fields().ByName("ids")).List().Len(), 3; got != want {
+		t.Errorf("len(label ids) = %v, want %v", got, want)
+	}
+
+	fields := md.Fields()
+	if fd := fields.ByName("time"); fd.Message().FullName() != "google.protobuf.Timestamp" {
+		t.Errorf("field time: Message() = %v", fd.Message().FullName())
+	}
+	if fd := fields.ByName("count"); fd.HasPresence() {
+		t.Errorf("field count: HasPresence() = true, want false")
+	}
+	if fd := fields.ByName("children"); !fd.IsMap() || fd.MapValue().Message() != md {
+		t.Errorf("field children is not a map of Bar")
+	}
+	if fd := fields.ByName("id"); fd.ContainingOneof().Name() != "kind" || fd.JSONName() != "ident" {
+		t.Errorf("field id: ContainingOneof() = %v, JSONName() = %q", fd.ContainingOneof().Name(), fd.JSONName())
+	}
+	if got := md.ReservedRanges().Get(1); got != [2]protoreflect.FieldNumber{100, 536870912} {
+		t.Errorf("ReservedRanges().Get(1) = %v", got)
+	}
+	if !md.ReservedNames().Has("old_field") {
+		t.Errorf("ReservedNames() does not contain old_field")
+	}
+	method := md.ParentFile().Services().ByName("Service").Methods().ByName("Get")
+	if method.IsStreamingClient() || !method.IsStreamingServer() || method.Output() != md {
+		t.Errorf("method Get has wrong streaming or output type")
+	}
+}
+
+// cloneWithResolver round-trips m through the wire format,
+// resolving the provided extension type.
+func cloneWithResolver(t *testing.T, m *descriptorpb.MessageOptions, xt protoreflect.ExtensionType) *descriptorpb.MessageOptions {
+	var types protoregistry.Types
+	types.RegisterExtension(xt)
+	b, err := proto.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := new(descriptorpb.MessageOptions)
+	if err := (proto.UnmarshalOptions{Resolver: &types}).Unmarshal(b, out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src     string
+		wantErr string
+	}{{
+		src:     "syntax = \"proto4\";",
+		wantErr: `a.proto:1:10: unknown syntax "proto4"`,
+	}, {
+		src:     "syntax = \"proto2\";\nmessage M {\n  int32 x = 1;\n}",
+		wantErr: "a.proto:3:3: field must have a label in proto2",
+	}, {
+		src:     "syntax = \"proto3\";\nmessage M {\n  int32 x = 1\n}",
+		wantErr: `a.proto:4:1: unexpected "}", expected ";"`,
+	}, {
+		src:     "syntax = \"proto3\";\nmessage M {\n  string s = 1 [default = \"x\"];\n}",
+		wantErr: `a.proto: message field "M.s" has invalid default`,
+	}, {
+		src:     "syntax = \"proto3\";\nmessage M {\n  option (unknown) = 1;\n}",
+		wantErr: `a.proto:3:10: unknown extension "unknown"`,
+	}, {
+		src:     "syntax = \"proto3\";\nmessage M {\n  Missing m = 1;\n}",
+		wantErr: `a.proto: message field "M.m" cannot resolve type`,
+	}, {
+		src:     "syntax = \"proto3\";\nimport \"missing.proto\";",
+		wantErr: `could not find file "missing.proto"`,
+	}, {
+		src:     "syntax = \"proto3\";\nmessage M { string s = 1; } /* unterminated",
+		wantErr: "a.proto:2:29: unterminated block comment",
+	}}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{"a.proto": tt.src})
+		_, err := protoparse.Options{ImportPaths: []string{dir}}.ParseFiles("a.proto")
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ParseFiles(%q) error = %v, want %q", tt.src, err, tt.wantErr)
+		}
+	}
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, src := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(src), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
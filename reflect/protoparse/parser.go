@@ -0,0 +1,1374 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protoparse
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/internal/encoding/defval"
+	"google.golang.org/protobuf/internal/strs"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const (
+	// maxFieldNumber is the value of "max" in a range of field numbers.
+	// Field numbers above it are only valid for MessageSet extensions,
+	// which is checked by protodesc.
+	maxFieldNumber = 536870911
+	maxEnumNumber  = math.MaxInt32
+)
+
+var scalarTypes = map[string]descriptorpb.FieldDescriptorProto_Type{
+	"double":   descriptorpb.FieldDescriptorProto_TYPE_DOUBLE,
+	"float":    descriptorpb.FieldDescriptorProto_TYPE_FLOAT,
+	"int64":    descriptorpb.FieldDescriptorProto_TYPE_INT64,
+	"uint64":   descriptorpb.FieldDescriptorProto_TYPE_UINT64,
+	"int32":    descriptorpb.FieldDescriptorProto_TYPE_INT32,
+	"fixed64":  descriptorpb.FieldDescriptorProto_TYPE_FIXED64,
+	"fixed32":  descriptorpb.FieldDescriptorProto_TYPE_FIXED32,
+	"bool":     descriptorpb.FieldDescriptorProto_TYPE_BOOL,
+	"string":   descriptorpb.FieldDescriptorProto_TYPE_STRING,
+	"bytes":    descriptorpb.FieldDescriptorProto_TYPE_BYTES,
+	"uint32":   descriptorpb.FieldDescriptorProto_TYPE_UINT32,
+	"sfixed32": descriptorpb.FieldDescriptorProto_TYPE_SFIXED32,
+	"sfixed64": descriptorpb.FieldDescriptorProto_TYPE_SFIXED64,
+	"sint32":   descriptorpb.FieldDescriptorProto_TYPE_SINT32,
+	"sint64":   descriptorpb.FieldDescriptorProto_TYPE_SINT64,
+}
+
+// parser converts the tokens of a single .proto source file
+// into a FileDescriptorProto.
+type parser struct {
+	lex     *lexer
+	fd      *descriptorpb.FileDescriptorProto
+	syntax  string          // "proto2", "proto3", or "editions"
+	options []*option       // options not yet applied
+	touched []proto.Message // options messages with applied options
+}
+
+// option is a single option statement, which is applied to the
+// options message once all dependencies of the file are known.
+type option struct {
+	pos   position
+	scope protoreflect.FullName // scope in which extension names are resolved
+	opts  proto.Message         // the options message, such as *descriptorpb.FileOptions
+	name  []optionName
+	value optionValue
+}
+
+// optionName is a single component of an option name.
+// An extension name appears in parentheses in the source.
+type optionName struct {
+	name        string
+	isExtension bool
+}
+
+// optionValue is the literal value of an option.
+type optionValue struct {
+	tok         token
+	neg         bool
+	isAggregate bool
+	aggregate   string // the text of a message literal, excluding the braces
+}
+
+func parseFile(path string, src []byte) (*parser, error) {
+	p := &parser{
+		lex:    newLexer(src),
+		fd:     &descriptorpb.FileDescriptorProto{Name: proto.String(path)},
+		syntax: "proto2",
+	}
+	if err := p.parseFile(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *parser) next() (token, error) {
+	return p.lex.Next()
+}
+
+// accept consumes the next token if it is the punctuation or keyword s.
+func (p *parser) accept(s string) (bool, error) {
+	tok, err := p.lex.Peek()
+	if err != nil {
+		return false, err
+	}
+	if (tok.kind == tokenPunct || tok.kind == tokenIdent) && tok.raw == s {
+		p.lex.Next()
+		return true, nil
+	}
+	return false, nil
+}
+
+// expect consumes the next token, which must be the punctuation or keyword s.
+func (p *parser) expect(s string) error {
+	tok, err := p.next()
+	if err != nil {
+		return err
+	}
+	if (tok.kind != tokenPunct && tok.kind != tokenIdent) || tok.raw != s {
+		return unexpected(tok, "%q", s)
+	}
+	return nil
+}
+
+func (p *parser) ident() (string, error) {
+	tok, err := p.next()
+	if err != nil {
+		return "", err
+	}
+	if tok.kind != tokenIdent {
+		return "", unexpected(tok, "identifier")
+	}
+	return tok.raw, nil
+}
+
+// typeName parses a possibly qualified name, such as "foo.Bar" or ".foo.Bar".
+func (p *parser) typeName() (string, error) {
+	var b strings.Builder
+	ok, err := p.accept(".")
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		b.WriteByte('.')
+	}
+	for {
+		s, err := p.ident()
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(s)
+		if ok, err := p.accept("."); err != nil {
+			return "", err
+		} else if !ok {
+			return b.String(), nil
+		}
+		b.WriteByte('.')
+	}
+}
+
+func (p *parser) stringLit() (string, error) {
+	tok, err := p.next()
+	if err != nil {
+		return "", err
+	}
+	if tok.kind != tokenString {
+		return "", unexpected(tok, "string")
+	}
+	s := tok.str
+	// Adjacent string literals are concatenated.
+	for {
+		tok, err := p.lex.Peek()
+		if err != nil {
+			return "", err
+		}
+		if tok.kind != tokenString {
+			return s, nil
+		}
+		p.lex.Next()
+		s += tok.str
+	}
+}
+
+// intLit parses an optionally negative integer in the range [min, max].
+func (p *parser) intLit(min, max int64) (int64, error) {
+	neg, err := p.accept("-")
+	if err != nil {
+		return 0, err
+	}
+	tok, err := p.next()
+	if err != nil {
+		return 0, err
+	}
+	if tok.kind != tokenInt {
+		return 0, unexpected(tok, "integer")
+	}
+	v, err := strconv.ParseUint(tok.raw, 0, 64)
+	if err != nil || v > math.MaxInt64 {
+		return 0, newError(tok.pos, "integer %s out of range", tok.raw)
+	}
+	n := int64(v)
+	if neg {
+		n = -n
+	}
+	if n < min || n > max {
+		return 0, newError(tok.pos, "integer %s out of range", tok.raw)
+	}
+	return n, nil
+}
+
+func (p *parser) parseFile() error {
+	tok, err := p.lex.Peek()
+	if err != nil {
+		return err
+	}
+	if tok.kind == tokenIdent && (tok.raw == "syntax" || tok.raw == "edition") {
+		p.next()
+		if err := p.expect("="); err != nil {
+			return err
+		}
+		pos := p.pos()
+		s, err := p.stringLit()
+		if err != nil {
+			return err
+		}
+		if tok.raw == "syntax" {
+			switch s {
+			case "proto2":
+			case "proto3":
+				p.fd.Syntax = proto.String(s)
+			default:
+				return newError(pos, "unknown syntax %q", s)
+			}
+			p.syntax = s
+		} else {
+			v, ok := descriptorpb.Edition_value["EDITION_"+s]
+			if !ok {
+				return newError(pos, "unknown edition %q", s)
+			}
+			p.fd.Syntax = proto.String("editions")
+			p.fd.Edition = descriptorpb.Edition(v).Enum()
+			p.syntax = "editions"
+		}
+		if err := p.expect(";"); err != nil {
+			return err
+		}
+	}
+
+	scope := protoreflect.FullName("")
+	for {
+		tok, err := p.next()
+		if err != nil {
+			return err
+		}
+		switch {
+		case tok.kind == tokenEOF:
+			return nil
+		case tok.raw == ";" && tok.kind == tokenPunct:
+		case tok.kind != tokenIdent:
+			return unexpected(tok, "declaration")
+		case tok.raw == "package":
+			if p.fd.Package != nil {
+				return newError(tok.pos, "multiple package declarations")
+			}
+			name, err := p.typeName()
+			if err != nil {
+				return err
+			}
+			p.fd.Package = proto.String(name)
+			scope = protoreflect.FullName(name)
+			if err := p.expect(";"); err != nil {
+				return err
+			}
+		case tok.raw == "import":
+			if err := p.parseImport(); err != nil {
+				return err
+			}
+		case tok.raw == "option":
+			if p.fd.Options == nil {
+				p.fd.Options = &descriptorpb.FileOptions{}
+			}
+			if err := p.parseOptionStatement(p.fd.Options, scope); err != nil {
+				return err
+			}
+		case tok.raw == "message":
+			md, err := p.parseMessage(scope)
+			if err != nil {
+				return err
+			}
+			p.fd.MessageType = append(p.fd.MessageType, md)
+		case tok.raw == "enum":
+			ed, err := p.parseEnum(scope)
+			if err != nil {
+				return err
+			}
+			p.fd.EnumType = append(p.fd.EnumType, ed)
+		case tok.raw == "service":
+			sd, err := p.parseService(scope)
+			if err != nil {
+				return err
+			}
+			p.fd.Service = append(p.fd.Service, sd)
+		case tok.raw == "extend":
+			if err := p.parseExtend(scope, &p.fd.Extension, &p.fd.MessageType); err != nil {
+				return err
+			}
+		default:
+			return unexpected(tok, "declaration")
+		}
+	}
+}
+
+func (p *parser) parseImport() error {
+	var public, weak bool
+	tok, err := p.lex.Peek()
+	if err != nil {
+		return err
+	}
+	if tok.kind == tokenIdent {
+		switch tok.raw {
+		case "public":
+			public = true
+		case "weak":
+			weak = true
+		default:
+			return unexpected(tok, "string")
+		}
+		p.next()
+	}
+	path, err := p.stringLit()
+	if err != nil {
+		return err
+	}
+	index := int32(len(p.fd.Dependency))
+	p.fd.Dependency = append(p.fd.Dependency, path)
+	if public {
+		p.fd.PublicDependency = append(p.fd.PublicDependency, index)
+	}
+	if weak {
+		p.fd.WeakDependency = append(p.fd.WeakDependency, index)
+	}
+	return p.expect(";")
+}
+
+// parseOptionStatement parses the remainder of an option statement
+// after the "option" keyword.
+func (p *parser) parseOptionStatement(opts proto.Message, scope protoreflect.FullName) error {
+	if err := p.parseOption(opts, scope); err != nil {
+		return err
+	}
+	return p.expect(";")
+}
+
+// parseOption parses a single "name = value" option.
+func (p *parser) parseOption(opts proto.Message, scope protoreflect.FullName) error {
+	pos := p.pos()
+	name, err := p.parseOptionName()
+	if err != nil {
+		return err
+	}
+	if err := p.expect("="); err != nil {
+		return err
+	}
+	value, err := p.parseOptionValue()
+	if err != nil {
+		return err
+	}
+	p.options = append(p.options, &option{
+		pos:   pos,
+		scope: scope,
+		opts:  opts,
+		name:  name,
+		value: value,
+	})
+	return nil
+}
+
+func (p *parser) parseOptionName() ([]optionName, error) {
+	var names []optionName
+	for {
+		if ok, err := p.accept("("); err != nil {
+			return nil, err
+		} else if ok {
+			s, err := p.typeName()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			names = append(names, optionName{name: s, isExtension: true})
+		} else {
+			s, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, optionName{name: s})
+		}
+		if ok, err := p.accept("."); err != nil {
+			return nil, err
+		} else if !ok {
+			return names, nil
+		}
+	}
+}
+
+func (p *parser) parseOptionValue() (optionValue, error) {
+	var v optionValue
+	tok, err := p.lex.Peek()
+	if err != nil {
+		return v, err
+	}
+	switch {
+	case tok.kind == tokenPunct && tok.raw == "{":
+		p.next()
+		start := p.lex.off
+		end := start
+		for depth := 1; depth > 0; {
+			tok, err := p.next()
+			if err != nil {
+				return v, err
+			}
+			switch {
+			case tok.kind == tokenEOF:
+				return v, unexpected(tok, "%q", "}")
+			case tok.kind == tokenPunct && tok.raw == "{":
+				depth++
+			case tok.kind == tokenPunct && tok.raw == "}":
+				depth--
+				end = p.lex.off - 1
+			}
+		}
+		v.tok = tok
+		v.isAggregate = true
+		v.aggregate = p.lex.src[start:end]
+		return v, nil
+	case tok.kind == tokenPunct && (tok.raw == "-" || tok.raw == "+"):
+		p.next()
+		v.neg = tok.raw == "-"
+	}
+	tok, err = p.next()
+	if err != nil {
+		return v, err
+	}
+	switch tok.kind {
+	case tokenIdent, tokenInt, tokenFloat:
+	case tokenString:
+		if v.neg {
+			return v, unexpected(tok, "number")
+		}
+		s := tok.str
+		for {
+			next, err := p.lex.Peek()
+			if err != nil {
+				return v, err
+			}
+			if next.kind != tokenString {
+				break
+			}
+			p.next()
+			s += next.str
+		}
+		tok.str = s
+	default:
+		return v, unexpected(tok, "option value")
+	}
+	v.tok = tok
+	return v, nil
+}
+
+// parseCompactOptions parses the options in brackets following a field,
+// enum value, or range. It reports whether any options were present.
+// The newOpts function is called to obtain the options message
+// only if there is at least one option.
+func (p *parser) parseCompactOptions(scope protoreflect.FullName, newOpts func() proto.Message, pseudo func(name string, v optionValue) (bool, error)) error {
+	if ok, err := p.accept("["); err != nil || !ok {
+		return err
+	}
+	var opts proto.Message
+	for {
+		tok, err := p.lex.Peek()
+		if err != nil {
+			return err
+		}
+		handled := false
+		if pseudo != nil && tok.kind == tokenIdent && (tok.raw == "default" || tok.raw == "json_name") {
+			p.next()
+			if err := p.expect("="); err != nil {
+				return err
+			}
+			v, err := p.parseOptionValue()
+			if err != nil {
+				return err
+			}
+			if handled, err = pseudo(tok.raw, v); err != nil {
+				return err
+			}
+			if !handled {
+				return newError(tok.pos, "option %q is not allowed here", tok.raw)
+			}
+		}
+		if !handled {
+			if opts == nil {
+				opts = newOpts()
+			}
+			if err := p.parseOption(opts, scope); err != nil {
+				return err
+			}
+		}
+		if ok, err := p.accept(","); err != nil {
+			return err
+		} else if !ok {
+			break
+		}
+	}
+	return p.expect("]")
+}
+
+func (p *parser) parseMessage(scope protoreflect.FullName) (*descriptorpb.DescriptorProto, error) {
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	md := &descriptorpb.DescriptorProto{Name: proto.String(name)}
+	if err := p.parseMessageBody(md, scope.Append(protoreflect.Name(name))); err != nil {
+		return nil, err
+	}
+	return md, nil
+}
+
+func (p *parser) parseMessageBody(md *descriptorpb.DescriptorProto, scope protoreflect.FullName) error {
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for {
+		tok, err := p.lex.Peek()
+		if err != nil {
+			return err
+		}
+		if tok.kind == tokenPunct && tok.raw == "}" {
+			p.next()
+			break
+		}
+		if tok.kind == tokenPunct && tok.raw == ";" {
+			p.next()
+			continue
+		}
+		if tok.kind != tokenIdent && !(tok.kind == tokenPunct && tok.raw == ".") {
+			return unexpected(tok, "field or declaration")
+		}
+		switch tok.raw {
+		case "message":
+			p.next()
+			nested, err := p.parseMessage(scope)
+			if err != nil {
+				return err
+			}
+			md.NestedType = append(md.NestedType, nested)
+		case "enum":
+			p.next()
+			ed, err := p.parseEnum(scope)
+			if err != nil {
+				return err
+			}
+			md.EnumType = append(md.EnumType, ed)
+		case "extend":
+			p.next()
+			if err := p.parseExtend(scope, &md.Extension, &md.NestedType); err != nil {
+				return err
+			}
+		case "option":
+			p.next()
+			if md.Options == nil {
+				md.Options = &descriptorpb.MessageOptions{}
+			}
+			if err := p.parseOptionStatement(md.Options, scope); err != nil {
+				return err
+			}
+		case "oneof":
+			p.next()
+			if err := p.parseOneof(md, scope); err != nil {
+				return err
+			}
+		case "reserved":
+			p.next()
+			if err := p.parseMessageReserved(md); err != nil {
+				return err
+			}
+		case "extensions":
+			p.next()
+			if err := p.parseExtensionRanges(md, scope); err != nil {
+				return err
+			}
+		default:
+			fd, err := p.parseField(scope, &md.NestedType, nil)
+			if err != nil {
+				return err
+			}
+			md.Field = append(md.Field, fd)
+		}
+	}
+
+	// Synthesize a oneof for every proto3 optional field.
+	// These are declared after all other oneofs.
+	for _, fd := range md.Field {
+		if !fd.GetProto3Optional() {
+			continue
+		}
+		name := "_" + fd.GetName()
+		for hasName(md, name) {
+			name = "X" + name
+		}
+		fd.OneofIndex = proto.Int32(int32(len(md.OneofDecl)))
+		md.OneofDecl = append(md.OneofDecl, &descriptorpb.OneofDescriptorProto{Name: proto.String(name)})
+	}
+	return nil
+}
+
+// hasName reports whether the message declares a field, oneof,
+// nested message, or nested enum with the given name.
+func hasName(md *descriptorpb.DescriptorProto, name string) bool {
+	for _, fd := range md.Field {
+		if fd.GetName() == name {
+			return true
+		}
+	}
+	for _, od := range md.OneofDecl {
+		if od.GetName() == name {
+			return true
+		}
+	}
+	for _, nd := range md.NestedType {
+		if nd.GetName() == name {
+			return true
+		}
+	}
+	for _, ed := range md.EnumType {
+		if ed.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) parseOneof(md *descriptorpb.DescriptorProto, scope protoreflect.FullName) error {
+	name, err := p.ident()
+	if err != nil {
+		return err
+	}
+	od := &descriptorpb.OneofDescriptorProto{Name: proto.String(name)}
+	index := int32(len(md.OneofDecl))
+	md.OneofDecl = append(md.OneofDecl, od)
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for {
+		tok, err := p.lex.Peek()
+		if err != nil {
+			return err
+		}
+		switch {
+		case tok.kind == tokenPunct && tok.raw == "}":
+			p.next()
+			return nil
+		case tok.kind == tokenPunct && tok.raw == ";":
+			p.next()
+		case tok.kind == tokenIdent && tok.raw == "option":
+			p.next()
+			if od.Options == nil {
+				od.Options = &descriptorpb.OneofOptions{}
+			}
+			if err := p.parseOptionStatement(od.Options, scope); err != nil {
+				return err
+			}
+		default:
+			fd, err := p.parseField(scope, &md.NestedType, &index)
+			if err != nil {
+				return err
+			}
+			md.Field = append(md.Field, fd)
+		}
+	}
+}
+
+// parseField parses a field declaration. Nested types implied by the field
+// (map entries and groups) are appended to nested. If oneofIndex is non-nil,
+// the field belongs to that oneof and must not have a label.
+func (p *parser) parseField(scope protoreflect.FullName, nested *[]*descriptorpb.DescriptorProto, oneofIndex *int32) (*descriptorpb.FieldDescriptorProto, error) {
+	fd := &descriptorpb.FieldDescriptorProto{OneofIndex: oneofIndex}
+	tok, err := p.lex.Peek()
+	if err != nil {
+		return nil, err
+	}
+	var label descriptorpb.FieldDescriptorProto_Label
+	hasLabel := false
+	if tok.kind == tokenIdent {
+		switch tok.raw {
+		case "optional":
+			label, hasLabel = descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL, true
+		case "required":
+			label, hasLabel = descriptorpb.FieldDescriptorProto_LABEL_REQUIRED, true
+		case "repeated":
+			label, hasLabel = descriptorpb.FieldDescriptorProto_LABEL_REPEATED, true
+		}
+	}
+	if hasLabel {
+		if oneofIndex != nil {
+			return nil, newError(tok.pos, "fields in oneofs must not have labels")
+		}
+		if label == descriptorpb.FieldDescriptorProto_LABEL_REQUIRED && p.syntax != "proto2" {
+			return nil, newError(tok.pos, "required fields are not allowed in %s", p.syntax)
+		}
+		if label == descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL && p.syntax == "editions" {
+			return nil, newError(tok.pos, "label %q is not allowed in editions", tok.raw)
+		}
+		p.next()
+	}
+	fd.Label = descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	if hasLabel {
+		fd.Label = label.Enum()
+	}
+	if p.syntax == "proto3" && label == descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL && hasLabel {
+		fd.Proto3Optional = proto.Bool(true)
+	}
+
+	typePos := p.pos()
+	typ, err := p.typeName()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case typ == "map" && !hasLabel && oneofIndex == nil:
+		if ok, err := p.accept("<"); err != nil {
+			return nil, err
+		} else if ok {
+			return p.parseMapField(fd, scope, nested)
+		}
+	case typ == "group" && p.syntax == "proto2":
+		return p.parseGroupField(fd, scope, nested, hasLabel || oneofIndex != nil)
+	}
+	if !hasLabel && oneofIndex == nil && p.syntax == "proto2" {
+		return nil, newError(typePos, "field must have a label in proto2")
+	}
+	if t, ok := scalarTypes[typ]; ok {
+		fd.Type = t.Enum()
+	} else {
+		fd.TypeName = proto.String(typ)
+	}
+
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	fd.Name = proto.String(name)
+	fd.JsonName = proto.String(strs.JSONCamelCase(name))
+	if err := p.expect("="); err != nil {
+		return nil, err
+	}
+	num, err := p.intLit(1, math.MaxInt32)
+	if err != nil {
+		return nil, err
+	}
+	fd.Number = proto.Int32(int32(num))
+	if err := p.parseFieldOptions(fd, scope); err != nil {
+		return nil, err
+	}
+	return fd, p.expect(";")
+}
+
+func (p *parser) parseFieldOptions(fd *descriptorpb.FieldDescriptorProto, scope protoreflect.FullName) error {
+	return p.parseCompactOptions(scope, func() proto.Message {
+		fd.Options = &descriptorpb.FieldOptions{}
+		return fd.Options
+	}, func(name string, v optionValue) (bool, error) {
+		switch name {
+		case "json_name":
+			if v.tok.kind != tokenString || v.isAggregate {
+				return false, unexpected(v.tok, "string")
+			}
+			fd.JsonName = proto.String(v.tok.str)
+		case "default":
+			s, err := formatDefault(fd, v)
+			if err != nil {
+				return false, err
+			}
+			fd.DefaultValue = proto.String(s)
+		}
+		return true, nil
+	})
+}
+
+// formatDefault formats the default value of a field
+// as it appears in a FieldDescriptorProto.
+func formatDefault(fd *descriptorpb.FieldDescriptorProto, v optionValue) (string, error) {
+	if v.isAggregate {
+		return "", unexpected(v.tok, "scalar value")
+	}
+	if fd.Type == nil {
+		// The field is an enum (or message) whose type is not yet resolved.
+		if v.tok.kind != tokenIdent || v.neg {
+			return "", unexpected(v.tok, "enum value name")
+		}
+		return v.tok.raw, nil
+	}
+	k := protoreflect.Kind(fd.GetType())
+	pv, err := scalarValue(k, v)
+	if err != nil {
+		return "", err
+	}
+	s, err := defval.Marshal(pv, nil, k, defval.Descriptor)
+	if err != nil {
+		return "", newError(v.tok.pos, "%v", err)
+	}
+	return s, nil
+}
+
+// scalarValue converts the literal v into a value of kind k.
+// Enum and message kinds are not supported.
+func scalarValue(k protoreflect.Kind, v optionValue) (protoreflect.Value, error) {
+	tok := v.tok
+	switch k {
+	case protoreflect.BoolKind:
+		if tok.kind == tokenIdent && !v.neg {
+			switch tok.raw {
+			case "true":
+				return protoreflect.ValueOfBool(true), nil
+			case "false":
+				return protoreflect.ValueOfBool(false), nil
+			}
+		}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		if tok.kind == tokenInt {
+			bits := 64
+			if k == protoreflect.Int32Kind || k == protoreflect.Sint32Kind || k == protoreflect.Sfixed32Kind {
+				bits = 32
+			}
+			s := tok.raw
+			if v.neg {
+				s = "-" + s
+			}
+			n, err := strconv.ParseInt(s, 0, bits)
+			if err != nil {
+				return protoreflect.Value{}, newError(tok.pos, "integer %s out of range for %v", s, k)
+			}
+			if bits == 32 {
+				return protoreflect.ValueOfInt32(int32(n)), nil
+			}
+			return protoreflect.ValueOfInt64(n), nil
+		}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		if tok.kind == tokenInt && !v.neg {
+			bits := 64
+			if k == protoreflect.Uint32Kind || k == protoreflect.Fixed32Kind {
+				bits = 32
+			}
+			n, err := strconv.ParseUint(tok.raw, 0, bits)
+			if err != nil {
+				return protoreflect.Value{}, newError(tok.pos, "integer %s out of range for %v", tok.raw, k)
+			}
+			if bits == 32 {
+				return protoreflect.ValueOfUint32(uint32(n)), nil
+			}
+			return protoreflect.ValueOfUint64(n), nil
+		}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		var f float64
+		switch {
+		case tok.kind == tokenIdent && (tok.raw == "inf" || tok.raw == "infinity"):
+			f = math.Inf(+1)
+		case tok.kind == tokenIdent && tok.raw == "nan":
+			f = math.NaN()
+		case tok.kind == tokenInt || tok.kind == tokenFloat:
+			var err error
+			if tok.kind == tokenInt {
+				var n uint64
+				n, err = strconv.ParseUint(tok.raw, 0, 64)
+				f = float64(n)
+			} else {
+				f, err = strconv.ParseFloat(tok.raw, 64)
+			}
+			if err != nil {
+				return protoreflect.Value{}, newError(tok.pos, "invalid number %s", tok.raw)
+			}
+		default:
+			return protoreflect.Value{}, unexpected(tok, "number")
+		}
+		if v.neg {
+			f = -f
+		}
+		if k == protoreflect.FloatKind {
+			return protoreflect.ValueOfFloat32(float32(f)), nil
+		}
+		return protoreflect.ValueOfFloat64(f), nil
+	case protoreflect.StringKind:
+		if tok.kind == tokenString {
+			return protoreflect.ValueOfString(tok.str), nil
+		}
+	case protoreflect.BytesKind:
+		if tok.kind == tokenString {
+			return protoreflect.ValueOfBytes([]byte(tok.str)), nil
+		}
+	}
+	return protoreflect.Value{}, unexpected(tok, "%v value", k)
+}
+
+func (p *parser) parseMapField(fd *descriptorpb.FieldDescriptorProto, scope protoreflect.FullName, nested *[]*descriptorpb.DescriptorProto) (*descriptorpb.FieldDescriptorProto, error) {
+	keyPos := p.pos()
+	keyType, err := p.typeName()
+	if err != nil {
+		return nil, err
+	}
+	kt, ok := scalarTypes[keyType]
+	if !ok || kt == descriptorpb.FieldDescriptorProto_TYPE_DOUBLE || kt == descriptorpb.FieldDescriptorProto_TYPE_FLOAT || kt == descriptorpb.FieldDescriptorProto_TYPE_BYTES {
+		return nil, newError(keyPos, "invalid map key type %q", keyType)
+	}
+	if err := p.expect(","); err != nil {
+		return nil, err
+	}
+	valType, err := p.typeName()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(">"); err != nil {
+		return nil, err
+	}
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("="); err != nil {
+		return nil, err
+	}
+	num, err := p.intLit(1, math.MaxInt32)
+	if err != nil {
+		return nil, err
+	}
+
+	entryName := mapEntryName(name)
+	key := &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String("key"),
+		JsonName: proto.String("key"),
+		Number:   proto.Int32(1),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:     kt.Enum(),
+	}
+	val := &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String("value"),
+		JsonName: proto.String("value"),
+		Number:   proto.Int32(2),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+	}
+	if t, ok := scalarTypes[valType]; ok {
+		val.Type = t.Enum()
+	} else {
+		val.TypeName = proto.String(valType)
+	}
+	*nested = append(*nested, &descriptorpb.DescriptorProto{
+		Name:    proto.String(entryName),
+		Field:   []*descriptorpb.FieldDescriptorProto{key, val},
+		Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+	})
+
+	fd.Name = proto.String(name)
+	fd.JsonName = proto.String(strs.JSONCamelCase(name))
+	fd.Number = proto.Int32(int32(num))
+	fd.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	fd.TypeName = proto.String(entryName)
+	if err := p.parseFieldOptions(fd, scope); err != nil {
+		return nil, err
+	}
+	return fd, p.expect(";")
+}
+
+// mapEntryName derives the name of the synthetic map entry message
+// for a map field in the same way as protoc.
+func mapEntryName(field string) string {
+	var b []byte
+	upperNext := true
+	for i := 0; i < len(field); i++ {
+		switch c := field[i]; {
+		case c == '_':
+			upperNext = true
+		case upperNext && 'a' <= c && c <= 'z':
+			b = append(b, c-'a'+'A')
+			upperNext = false
+		default:
+			b = append(b, c)
+			upperNext = false
+		}
+	}
+	return string(b) + "Entry"
+}
+
+func (p *parser) parseGroupField(fd *descriptorpb.FieldDescriptorProto, scope protoreflect.FullName, nested *[]*descriptorpb.DescriptorProto, labelled bool) (*descriptorpb.FieldDescriptorProto, error) {
+	tok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if tok.kind != tokenIdent || tok.raw[0] < 'A' || tok.raw[0] > 'Z' {
+		return nil, unexpected(tok, "group name starting with a capital letter")
+	}
+	if !labelled {
+		return nil, newError(tok.pos, "field must have a label in proto2")
+	}
+	name := tok.raw
+	if err := p.expect("="); err != nil {
+		return nil, err
+	}
+	num, err := p.intLit(1, math.MaxInt32)
+	if err != nil {
+		return nil, err
+	}
+	fd.Name = proto.String(strings.ToLower(name))
+	fd.JsonName = proto.String(strs.JSONCamelCase(fd.GetName()))
+	fd.Number = proto.Int32(int32(num))
+	fd.Type = descriptorpb.FieldDescriptorProto_TYPE_GROUP.Enum()
+	fd.TypeName = proto.String(name)
+	if err := p.parseFieldOptions(fd, scope); err != nil {
+		return nil, err
+	}
+	md := &descriptorpb.DescriptorProto{Name: proto.String(name)}
+	if err := p.parseMessageBody(md, scope.Append(protoreflect.Name(name))); err != nil {
+		return nil, err
+	}
+	*nested = append(*nested, md)
+	return fd, nil
+}
+
+func (p *parser) parseExtend(scope protoreflect.FullName, fields *[]*descriptorpb.FieldDescriptorProto, nested *[]*descriptorpb.DescriptorProto) error {
+	extendee, err := p.typeName()
+	if err != nil {
+		return err
+	}
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for {
+		tok, err := p.lex.Peek()
+		if err != nil {
+			return err
+		}
+		switch {
+		case tok.kind == tokenPunct && tok.raw == "}":
+			p.next()
+			return nil
+		case tok.kind == tokenPunct && tok.raw == ";":
+			p.next()
+		default:
+			fd, err := p.parseField(scope, nested, nil)
+			if err != nil {
+				return err
+			}
+			fd.Extendee = proto.String(extendee)
+			*fields = append(*fields, fd)
+		}
+	}
+}
+
+// parseRange parses a single range of numbers in [min, max],
+// such as "5", "5 to 10", or "5 to max", where "max" is maxKeyword.
+func (p *parser) parseRange(min, max, maxKeyword int64) (start, end int64, err error) {
+	if start, err = p.intLit(min, max); err != nil {
+		return 0, 0, err
+	}
+	if ok, err := p.accept("to"); err != nil {
+		return 0, 0, err
+	} else if !ok {
+		return start, start, nil
+	}
+	pos := p.pos()
+	if ok, err := p.accept("max"); err != nil {
+		return 0, 0, err
+	} else if ok {
+		end = maxKeyword
+	} else if end, err = p.intLit(min, max); err != nil {
+		return 0, 0, err
+	}
+	if end < start {
+		return 0, 0, newError(pos, "range end %d is before start %d", end, start)
+	}
+	return start, end, nil
+}
+
+// parseReservedNames parses a list of reserved names, which are
+// identifiers in editions and string literals otherwise.
+func (p *parser) parseReservedNames() ([]string, error) {
+	var names []string
+	for {
+		var name string
+		var err error
+		if p.syntax == "editions" {
+			name, err = p.ident()
+		} else {
+			name, err = p.stringLit()
+		}
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		if ok, err := p.accept(","); err != nil {
+			return nil, err
+		} else if !ok {
+			return names, p.expect(";")
+		}
+	}
+}
+
+func (p *parser) isReservedName() (bool, error) {
+	tok, err := p.lex.Peek()
+	if err != nil {
+		return false, err
+	}
+	return tok.kind == tokenString || tok.kind == tokenIdent, nil
+}
+
+func (p *parser) parseMessageReserved(md *descriptorpb.DescriptorProto) error {
+	if ok, err := p.isReservedName(); err != nil {
+		return err
+	} else if ok {
+		names, err := p.parseReservedNames()
+		md.ReservedName = append(md.ReservedName, names...)
+		return err
+	}
+	for {
+		start, end, err := p.parseRange(1, math.MaxInt32, maxFieldNumber)
+		if err != nil {
+			return err
+		}
+		md.ReservedRange = append(md.ReservedRange, &descriptorpb.DescriptorProto_ReservedRange{
+			Start: proto.Int32(int32(start)),
+			End:   proto.Int32(int32(end + 1)), // exclusive
+		})
+		if ok, err := p.accept(","); err != nil {
+			return err
+		} else if !ok {
+			return p.expect(";")
+		}
+	}
+}
+
+func (p *parser) parseExtensionRanges(md *descriptorpb.DescriptorProto, scope protoreflect.FullName) error {
+	var ranges []*descriptorpb.DescriptorProto_ExtensionRange
+	for {
+		start, end, err := p.parseRange(1, math.MaxInt32, maxFieldNumber)
+		if err != nil {
+			return err
+		}
+		ranges = append(ranges, &descriptorpb.DescriptorProto_ExtensionRange{
+			Start: proto.Int32(int32(start)),
+			End:   proto.Int32(int32(end + 1)), // exclusive
+		})
+		if ok, err := p.accept(","); err != nil {
+			return err
+		} else if !ok {
+			break
+		}
+	}
+	err := p.parseCompactOptions(scope, func() proto.Message {
+		// All ranges in the statement share the same options.
+		opts := &descriptorpb.ExtensionRangeOptions{}
+		for _, r := range ranges {
+			r.Options = opts
+		}
+		return opts
+	}, nil)
+	if err != nil {
+		return err
+	}
+	md.ExtensionRange = append(md.ExtensionRange, ranges...)
+	return p.expect(";")
+}
+
+func (p *parser) parseEnum(scope protoreflect.FullName) (*descriptorpb.EnumDescriptorProto, error) {
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	ed := &descriptorpb.EnumDescriptorProto{Name: proto.String(name)}
+	enumScope := scope.Append(protoreflect.Name(name))
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	for {
+		tok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case tok.kind == tokenPunct && tok.raw == "}":
+			return ed, nil
+		case tok.kind == tokenPunct && tok.raw == ";":
+		case tok.kind != tokenIdent:
+			return nil, unexpected(tok, "enum value")
+		case tok.raw == "option":
+			if ed.Options == nil {
+				ed.Options = &descriptorpb.EnumOptions{}
+			}
+			if err := p.parseOptionStatement(ed.Options, enumScope); err != nil {
+				return nil, err
+			}
+		case tok.raw == "reserved":
+			if err := p.parseEnumReserved(ed); err != nil {
+				return nil, err
+			}
+		default:
+			if err := p.expect("="); err != nil {
+				return nil, err
+			}
+			num, err := p.intLit(math.MinInt32, maxEnumNumber)
+			if err != nil {
+				return nil, err
+			}
+			vd := &descriptorpb.EnumValueDescriptorProto{
+				Name:   proto.String(tok.raw),
+				Number: proto.Int32(int32(num)),
+			}
+			err = p.parseCompactOptions(enumScope, func() proto.Message {
+				vd.Options = &descriptorpb.EnumValueOptions{}
+				return vd.Options
+			}, nil)
+			if err != nil {
+				return nil, err
+			}
+			ed.Value = append(ed.Value, vd)
+			if err := p.expect(";"); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func (p *parser) parseEnumReserved(ed *descriptorpb.EnumDescriptorProto) error {
+	if ok, err := p.isReservedName(); err != nil {
+		return err
+	} else if ok {
+		names, err := p.parseReservedNames()
+		ed.ReservedName = append(ed.ReservedName, names...)
+		return err
+	}
+	for {
+		start, end, err := p.parseRange(math.MinInt32, maxEnumNumber, maxEnumNumber)
+		if err != nil {
+			return err
+		}
+		ed.ReservedRange = append(ed.ReservedRange, &descriptorpb.EnumDescriptorProto_EnumReservedRange{
+			Start: proto.Int32(int32(start)),
+			End:   proto.Int32(int32(end)), // inclusive
+		})
+		if ok, err := p.accept(","); err != nil {
+			return err
+		} else if !ok {
+			return p.expect(";")
+		}
+	}
+}
+
+func (p *parser) parseService(scope protoreflect.FullName) (*descriptorpb.ServiceDescriptorProto, error) {
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	sd := &descriptorpb.ServiceDescriptorProto{Name: proto.String(name)}
+	serviceScope := scope.Append(protoreflect.Name(name))
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	for {
+		tok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case tok.kind == tokenPunct && tok.raw == "}":
+			return sd, nil
+		case tok.kind == tokenPunct && tok.raw == ";":
+		case tok.kind == tokenIdent && tok.raw == "option":
+			if sd.Options == nil {
+				sd.Options = &descriptorpb.ServiceOptions{}
+			}
+			if err := p.parseOptionStatement(sd.Options, serviceScope); err != nil {
+				return nil, err
+			}
+		case tok.kind == tokenIdent && tok.raw == "rpc":
+			md, err := p.parseMethod(serviceScope)
+			if err != nil {
+				return nil, err
+			}
+			sd.Method = append(sd.Method, md)
+		default:
+			return nil, unexpected(tok, "rpc")
+		}
+	}
+}
+
+func (p *parser) parseMethod(scope protoreflect.FullName) (*descriptorpb.MethodDescriptorProto, error) {
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	md := &descriptorpb.MethodDescriptorProto{Name: proto.String(name)}
+	parseType := func() (string, bool, error) {
+		if err := p.expect("("); err != nil {
+			return "", false, err
+		}
+		// The "stream" keyword may also be the name of a message type.
+		stream := false
+		if tok, err := p.lex.Peek(); err != nil {
+			return "", false, err
+		} else if tok.kind == tokenIdent && tok.raw == "stream" {
+			p.next()
+			if next, err := p.lex.Peek(); err != nil {
+				return "", false, err
+			} else if next.kind == tokenPunct && next.raw == ")" {
+				p.next()
+				return "stream", false, nil
+			}
+			stream = true
+		}
+		typ, err := p.typeName()
+		if err != nil {
+			return "", false, err
+		}
+		return typ, stream, p.expect(")")
+	}
+	in, clientStreaming, err := parseType()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("returns"); err != nil {
+		return nil, err
+	}
+	out, serverStreaming, err := parseType()
+	if err != nil {
+		return nil, err
+	}
+	md.InputType = proto.String(in)
+	md.OutputType = proto.String(out)
+	if clientStreaming {
+		md.ClientStreaming = proto.Bool(true)
+	}
+	if serverStreaming {
+		md.ServerStreaming = proto.Bool(true)
+	}
+	if ok, err := p.accept(";"); err != nil || ok {
+		return md, err
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	for {
+		tok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case tok.kind == tokenPunct && tok.raw == "}":
+			return md, nil
+		case tok.kind == tokenPunct && tok.raw == ";":
+		case tok.kind == tokenIdent && tok.raw == "option":
+			if md.Options == nil {
+				md.Options = &descriptorpb.MethodOptions{}
+			}
+			if err := p.parseOptionStatement(md.Options, scope); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, unexpected(tok, "option")
+		}
+	}
+}
+
+// pos returns the position of the next token.
+func (p *parser) pos() position {
+	tok, _ := p.lex.Peek()
+	return tok.pos
+}
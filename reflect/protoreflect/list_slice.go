@@ -0,0 +1,56 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protoreflect
+
+import "fmt"
+
+// ListElement is the set of Go types which may be used with [ListSlice].
+// Each is the Go type of a [Value] holding a list element
+// of the corresponding kind.
+type ListElement interface {
+	bool | int32 | int64 | uint32 | uint64 | float32 | float64 | string | []byte | EnumNumber
+}
+
+// slicePointer is the same as protoiface.ListSlicePointer,
+// which cannot be referenced here since protoiface depends on this package.
+type slicePointer interface {
+	ProtoSlicePointer() any
+}
+
+// ListSlice returns the elements of l as a slice of type T,
+// which must be the Go type of the elements as described by [Value],
+// such as []int32 for a repeated sint32 field.
+// It panics if T does not match the type of the elements.
+//
+// If l is backed by a []T, as are the repeated scalar fields of generated
+// messages, the backing slice is returned without copying or boxing
+// the individual elements as [Value]. Lists indicate this by implementing
+// [google.golang.org/protobuf/runtime/protoiface.ListSlicePointer].
+// Otherwise, the elements are copied into a newly allocated slice.
+// Since the result may share storage with l, it must be treated as
+// read-only and is only valid until l is next mutated.
+func ListSlice[T ListElement](l List) []T {
+	if sp, ok := l.(slicePointer); ok {
+		if p, ok := sp.ProtoSlicePointer().(*[]T); ok {
+			if p == nil {
+				return nil
+			}
+			return *p
+		}
+	}
+	n := l.Len()
+	if n == 0 {
+		return nil
+	}
+	s := make([]T, n)
+	for i := range s {
+		v, ok := l.Get(i).Interface().(T)
+		if !ok {
+			panic(fmt.Sprintf("invalid list element type: got %T, want %T", l.Get(i).Interface(), v))
+		}
+		s[i] = v
+	}
+	return s
+}
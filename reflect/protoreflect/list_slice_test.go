@@ -0,0 +1,65 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protoreflect_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	testpb "google.golang.org/protobuf/internal/testprotos/test"
+)
+
+func TestListSlice(t *testing.T) {
+	m := &testpb.TestAllTypes{
+		RepeatedInt32:      []int32{1, 2, 3},
+		RepeatedDouble:     []float64{1.5, -2},
+		RepeatedString:     []string{"a", "b"},
+		RepeatedNestedEnum: []testpb.TestAllTypes_NestedEnum{testpb.TestAllTypes_BAR, testpb.TestAllTypes_NEG},
+	}
+	list := func(m proto.Message, name protoreflect.Name) protoreflect.List {
+		mr := m.ProtoReflect()
+		return mr.Get(mr.Descriptor().Fields().ByName(name)).List()
+	}
+
+	ints := protoreflect.ListSlice[int32](list(m, "repeated_int32"))
+	if want := []int32{1, 2, 3}; !cmp.Equal(ints, want) {
+		t.Errorf("ListSlice[int32] = %v, want %v", ints, want)
+	}
+	if &ints[0] != &m.RepeatedInt32[0] {
+		t.Errorf("ListSlice[int32] copied the elements of a generated message field")
+	}
+	if got, want := protoreflect.ListSlice[float64](list(m, "repeated_double")), []float64{1.5, -2}; !cmp.Equal(got, want) {
+		t.Errorf("ListSlice[float64] = %v, want %v", got, want)
+	}
+	if got, want := protoreflect.ListSlice[string](list(m, "repeated_string")), []string{"a", "b"}; !cmp.Equal(got, want) {
+		t.Errorf("ListSlice[string] = %v, want %v", got, want)
+	}
+	if got, want := protoreflect.ListSlice[protoreflect.EnumNumber](list(m, "repeated_nested_enum")), []protoreflect.EnumNumber{1, -1}; !cmp.Equal(got, want) {
+		t.Errorf("ListSlice[EnumNumber] = %v, want %v", got, want)
+	}
+	if got := protoreflect.ListSlice[int64](list(&testpb.TestAllTypes{}, "repeated_int64")); got != nil {
+		t.Errorf("ListSlice[int64] of empty list = %v, want nil", got)
+	}
+
+	dm := dynamicpb.NewMessage(m.ProtoReflect().Descriptor())
+	proto.Merge(dm, m)
+	if got, want := protoreflect.ListSlice[int32](list(dm, "repeated_int32")), []int32{1, 2, 3}; !cmp.Equal(got, want) {
+		t.Errorf("ListSlice[int32] of dynamic message = %v, want %v", got, want)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("ListSlice[int64] of int32 list did not panic")
+			}
+		}()
+		protoreflect.ListSlice[int64](list(dm, "repeated_int32"))
+	}()
+}
@@ -28,8 +28,9 @@ type (
 	supportFlags = uint64
 	sizeInput    = struct {
 		pragma.NoUnkeyedLiterals
-		Message Message
-		Flags   uint8
+		Message     Message
+		Flags       uint8
+		Concurrency int
 	}
 	sizeOutput = struct {
 		pragma.NoUnkeyedLiterals
@@ -37,9 +38,10 @@ type (
 	}
 	marshalInput = struct {
 		pragma.NoUnkeyedLiterals
-		Message Message
-		Buf     []byte
-		Flags   uint8
+		Message     Message
+		Buf         []byte
+		Flags       uint8
+		Concurrency int
 	}
 	marshalOutput = struct {
 		pragma.NoUnkeyedLiterals
@@ -0,0 +1,77 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protoreflect
+
+import "sort"
+
+// RangeOrdered is like [Message.Range], but visits the populated fields of m
+// in ascending order of field number, including extension fields.
+// It returns immediately if f returns false.
+//
+// The fields are collected before any of them are visited, so the order does
+// not depend on the implementation of m, and f may mutate any field of m.
+func RangeOrdered(m Message, f func(FieldDescriptor, Value) bool) {
+	type field struct {
+		fd FieldDescriptor
+		v  Value
+	}
+	var fields []field
+	m.Range(func(fd FieldDescriptor, v Value) bool {
+		fields = append(fields, field{fd, v})
+		return true
+	})
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].fd.Number() < fields[j].fd.Number()
+	})
+	for _, e := range fields {
+		if !f(e.fd, e.v) {
+			return
+		}
+	}
+}
+
+// RangeMapOrdered is like [Map.Range], but visits the entries of m
+// in ascending order of key. Bool keys are ordered false before true,
+// integer keys numerically, and string keys lexicographically
+// by their UTF-8 encoding.
+// It returns immediately if f returns false.
+//
+// The entries are collected before any of them are visited, so the order does
+// not depend on the implementation of m, and f may mutate any entry of m.
+func RangeMapOrdered(m Map, f func(MapKey, Value) bool) {
+	type entry struct {
+		k MapKey
+		v Value
+	}
+	var entries []entry
+	m.Range(func(k MapKey, v Value) bool {
+		entries = append(entries, entry{k, v})
+		return true
+	})
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].k.less(entries[j].k)
+	})
+	for _, e := range entries {
+		if !f(e.k, e.v) {
+			return
+		}
+	}
+}
+
+// less reports whether k is ordered before k2, which has the same type.
+func (k MapKey) less(k2 MapKey) bool {
+	switch k.typ {
+	case boolType:
+		return !k.Bool() && k2.Bool()
+	case int32Type, int64Type:
+		return k.Int() < k2.Int()
+	case uint32Type, uint64Type:
+		return k.Uint() < k2.Uint()
+	case stringType:
+		return k.String() < k2.String()
+	default:
+		panic("invalid map key type")
+	}
+}
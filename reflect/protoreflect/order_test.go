@@ -0,0 +1,77 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protoreflect_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	testpb "google.golang.org/protobuf/internal/testprotos/test"
+)
+
+func TestRangeOrdered(t *testing.T) {
+	m := &testpb.TestAllTypes{
+		MapInt32Int32:   map[int32]int32{3: 0, -1: 0, 2: 0, -10: 0},
+		RepeatedInt32:   []int32{1},
+		OptionalString:  proto.String(""),
+		MapStringString: map[string]string{"b": "", "a": "", "ab": "", "": ""},
+		OptionalInt32:   proto.Int32(1),
+		MapBoolBool:     map[bool]bool{true: false, false: true},
+	}
+	mr := m.ProtoReflect()
+
+	var got []protoreflect.FieldNumber
+	protoreflect.RangeOrdered(mr, func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		got = append(got, fd.Number())
+		return true
+	})
+	if want := []protoreflect.FieldNumber{1, 14, 31, 56, 68, 69}; !cmp.Equal(got, want) {
+		t.Errorf("RangeOrdered visited fields %v, want %v", got, want)
+	}
+
+	got = nil
+	protoreflect.RangeOrdered(mr, func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		got = append(got, fd.Number())
+		return len(got) < 2
+	})
+	if want := []protoreflect.FieldNumber{1, 14}; !cmp.Equal(got, want) {
+		t.Errorf("RangeOrdered with early return visited fields %v, want %v", got, want)
+	}
+
+	xm := &testpb.TestAllExtensions{}
+	proto.SetExtension(xm, testpb.E_OptionalString, "")
+	proto.SetExtension(xm, testpb.E_OptionalInt64, int64(0))
+	got = nil
+	protoreflect.RangeOrdered(xm.ProtoReflect(), func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		got = append(got, fd.Number())
+		return true
+	})
+	if want := []protoreflect.FieldNumber{2, 14}; !cmp.Equal(got, want) {
+		t.Errorf("RangeOrdered visited extensions %v, want %v", got, want)
+	}
+
+	for _, tt := range []struct {
+		name string
+		want []any
+	}{
+		{"map_int32_int32", []any{int32(-10), int32(-1), int32(2), int32(3)}},
+		{"map_string_string", []any{"", "a", "ab", "b"}},
+		{"map_bool_bool", []any{false, true}},
+	} {
+		fd := mr.Descriptor().Fields().ByName(protoreflect.Name(tt.name))
+		var keys []any
+		protoreflect.RangeMapOrdered(mr.Get(fd).Map(), func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+			keys = append(keys, k.Interface())
+			return true
+		})
+		if !cmp.Equal(keys, tt.want) {
+			t.Errorf("RangeMapOrdered(%v) visited keys %v, want %v", tt.name, keys, tt.want)
+		}
+	}
+}
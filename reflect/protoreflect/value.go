@@ -59,6 +59,7 @@ type Message interface {
 	// Range returns immediately if f returns false.
 	// While iterating, mutating operations may only be performed
 	// on the current field descriptor.
+	// Use [RangeOrdered] to iterate in order of field number.
 	Range(f func(FieldDescriptor, Value) bool)
 
 	// Has reports whether a field is populated.
@@ -238,6 +239,7 @@ type Map interface {
 	// Range calls f Len times unless f returns false, which stops iteration.
 	// While iterating, mutating operations may only be performed
 	// on the current map key.
+	// Use [RangeMapOrdered] to iterate in order of key.
 	Range(f func(MapKey, Value) bool)
 
 	// Has reports whether an entry with the given key is in the map.
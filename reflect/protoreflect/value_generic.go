@@ -0,0 +1,80 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protoreflect
+
+import "fmt"
+
+// Get returns the value of the field fd in m as a T, which must be the
+// Go type used by [Value] to represent the field, such as int64 for an
+// int64 field, [EnumNumber] for an enum field, [Message] for a message field,
+// or [List] or [Map] for a repeated or map field.
+// It panics if T does not match the field.
+//
+// Get is a convenience for calling [Message.Get] and unwrapping the result:
+//
+//	n := protoreflect.Get[int64](m, fd) // equivalent to m.Get(fd).Int()
+func Get[T any](m Message, fd FieldDescriptor) T {
+	checkFieldType[T](fd)
+	return m.Get(fd).Interface().(T)
+}
+
+// Set stores v as the value of the field fd in m.
+// T must be the Go type used by [Value] to represent the field,
+// as described for [Get]. It panics if T does not match the field.
+//
+// Set is a convenience for wrapping v as a [Value] and calling [Message.Set]:
+//
+//	protoreflect.Set(m, fd, int64(5)) // equivalent to m.Set(fd, ValueOfInt64(5))
+func Set[T any](m Message, fd FieldDescriptor, v T) {
+	checkFieldType[T](fd)
+	m.Set(fd, ValueOf(v))
+}
+
+// checkFieldType panics if T is not the Go type used to represent
+// values of the field fd.
+func checkFieldType[T any](fd FieldDescriptor) {
+	var kinds []Kind
+	switch any((*T)(nil)).(type) {
+	case *List:
+		if fd.IsList() {
+			return
+		}
+	case *Map:
+		if fd.IsMap() {
+			return
+		}
+	case *Message:
+		kinds = []Kind{MessageKind, GroupKind}
+	case *bool:
+		kinds = []Kind{BoolKind}
+	case *EnumNumber:
+		kinds = []Kind{EnumKind}
+	case *int32:
+		kinds = []Kind{Int32Kind, Sint32Kind, Sfixed32Kind}
+	case *int64:
+		kinds = []Kind{Int64Kind, Sint64Kind, Sfixed64Kind}
+	case *uint32:
+		kinds = []Kind{Uint32Kind, Fixed32Kind}
+	case *uint64:
+		kinds = []Kind{Uint64Kind, Fixed64Kind}
+	case *float32:
+		kinds = []Kind{FloatKind}
+	case *float64:
+		kinds = []Kind{DoubleKind}
+	case *string:
+		kinds = []Kind{StringKind}
+	case *[]byte:
+		kinds = []Kind{BytesKind}
+	}
+	if fd.Cardinality() != Repeated {
+		for _, k := range kinds {
+			if fd.Kind() == k {
+				return
+			}
+		}
+	}
+	typeName := fmt.Sprintf("%T", (*T)(nil))[len("*"):]
+	panic(fmt.Sprintf("invalid type %v for field %v", typeName, fd.FullName()))
+}
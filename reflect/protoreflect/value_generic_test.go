@@ -0,0 +1,87 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protoreflect_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	testpb "google.golang.org/protobuf/internal/testprotos/test"
+)
+
+func TestGetSet(t *testing.T) {
+	m := &testpb.TestAllTypes{}
+	mr := m.ProtoReflect()
+	fields := mr.Descriptor().Fields()
+
+	protoreflect.Set(mr, fields.ByName("optional_int64"), int64(5))
+	protoreflect.Set(mr, fields.ByName("optional_sint32"), int32(-3))
+	protoreflect.Set(mr, fields.ByName("optional_string"), "hello")
+	protoreflect.Set(mr, fields.ByName("optional_bytes"), []byte("x"))
+	protoreflect.Set(mr, fields.ByName("optional_nested_enum"), protoreflect.EnumNumber(testpb.TestAllTypes_BAZ))
+	nested := &testpb.TestAllTypes_NestedMessage{A: proto.Int32(1)}
+	protoreflect.Set(mr, fields.ByName("optional_nested_message"), protoreflect.Message(nested.ProtoReflect()))
+	list := mr.NewField(fields.ByName("repeated_int32")).List()
+	list.Append(protoreflect.ValueOfInt32(7))
+	protoreflect.Set(mr, fields.ByName("repeated_int32"), list)
+
+	want := &testpb.TestAllTypes{
+		OptionalInt64:         proto.Int64(5),
+		OptionalSint32:        proto.Int32(-3),
+		OptionalString:        proto.String("hello"),
+		OptionalBytes:         []byte("x"),
+		OptionalNestedEnum:    testpb.TestAllTypes_BAZ.Enum(),
+		OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{A: proto.Int32(1)},
+		RepeatedInt32:         []int32{7},
+	}
+	if diff := cmp.Diff(want, m, protocmp.Transform()); diff != "" {
+		t.Fatalf("message mismatch after Set (-want +got):\n%v", diff)
+	}
+
+	if got := protoreflect.Get[int64](mr, fields.ByName("optional_int64")); got != 5 {
+		t.Errorf("Get[int64] = %v, want 5", got)
+	}
+	if got := protoreflect.Get[string](mr, fields.ByName("optional_string")); got != "hello" {
+		t.Errorf("Get[string] = %q, want %q", got, "hello")
+	}
+	if got := protoreflect.Get[protoreflect.EnumNumber](mr, fields.ByName("optional_nested_enum")); got != protoreflect.EnumNumber(testpb.TestAllTypes_BAZ) {
+		t.Errorf("Get[EnumNumber] = %v, want %v", got, testpb.TestAllTypes_BAZ)
+	}
+	if got := protoreflect.Get[protoreflect.Message](mr, fields.ByName("optional_nested_message")); got.Interface() != nested {
+		t.Errorf("Get[Message] = %v, want %v", got.Interface(), nested)
+	}
+	if got := protoreflect.Get[protoreflect.List](mr, fields.ByName("repeated_int32")); got.Len() != 1 {
+		t.Errorf("Get[List].Len() = %v, want 1", got.Len())
+	}
+	if got := protoreflect.Get[protoreflect.Map](mr, fields.ByName("map_int32_int32")); got.Len() != 0 {
+		t.Errorf("Get[Map].Len() = %v, want 0", got.Len())
+	}
+
+	for _, tt := range []struct {
+		name string
+		f    func()
+	}{
+		{"Get[int32] of int64 field", func() { protoreflect.Get[int32](mr, fields.ByName("optional_int64")) }},
+		{"Get[int32] of repeated field", func() { protoreflect.Get[int32](mr, fields.ByName("repeated_int32")) }},
+		{"Get[List] of map field", func() { protoreflect.Get[protoreflect.List](mr, fields.ByName("map_int32_int32")) }},
+		{"Get[Message] of repeated message field", func() { protoreflect.Get[protoreflect.Message](mr, fields.ByName("repeated_nested_message")) }},
+		{"Set[uint64] of int64 field", func() { protoreflect.Set(mr, fields.ByName("optional_int64"), uint64(1)) }},
+		{"Set[int] of int64 field", func() { protoreflect.Set(mr, fields.ByName("optional_int64"), 1) }},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%v did not panic", tt.name)
+				}
+			}()
+			tt.f()
+		}()
+	}
+}
@@ -18,6 +18,7 @@ package protoregistry
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 
@@ -81,7 +82,9 @@ var NotFound = errors.New("not found")
 
 // Files is a registry for looking up or iterating over files and the
 // descriptors contained within them.
-// The Find and Range methods are safe for concurrent use.
+// All methods are safe for concurrent use. In particular, files may be
+// registered, deleted, or replaced while other goroutines look up descriptors.
+// The function passed to a Range method must not modify the registry.
 type Files struct {
 	// The map of descsByName contains:
 	//	EnumDescriptor
@@ -98,6 +101,17 @@ type Files struct {
 	descsByName map[protoreflect.FullName]any
 	filesByPath map[string][]protoreflect.FileDescriptor
 	numFiles    int
+
+	mu sync.RWMutex
+}
+
+// mutex returns the lock guarding r.
+// The global registries share a single lock.
+func (r *Files) mutex() *sync.RWMutex {
+	if r == GlobalFiles {
+		return &globalMutex
+	}
+	return &r.mu
 }
 
 type packageDescriptor struct {
@@ -112,10 +126,13 @@ type packageDescriptor struct {
 //
 // It is permitted for multiple files to have the same file path.
 func (r *Files) RegisterFile(file protoreflect.FileDescriptor) error {
-	if r == GlobalFiles {
-		globalMutex.Lock()
-		defer globalMutex.Unlock()
-	}
+	mu := r.mutex()
+	mu.Lock()
+	defer mu.Unlock()
+	return r.registerFile(file)
+}
+
+func (r *Files) registerFile(file protoreflect.FileDescriptor) error {
 	if r.descsByName == nil {
 		r.descsByName = map[protoreflect.FullName]any{
 			"": &packageDescriptor{},
@@ -175,6 +192,83 @@ func (r *Files) RegisterFile(file protoreflect.FileDescriptor) error {
 	return nil
 }
 
+// DeleteFile removes all files registered with the provided path,
+// along with the descriptors declared within them.
+//
+// This returns [NotFound] if no file with the path is registered.
+func (r *Files) DeleteFile(path string) error {
+	mu := r.mutex()
+	mu.Lock()
+	defer mu.Unlock()
+	if len(r.filesByPath[path]) == 0 {
+		return NotFound
+	}
+	r.deleteFiles(path)
+	return nil
+}
+
+// ReplaceFile registers the provided file descriptor in place of
+// any files previously registered with the same path.
+// The replacement happens atomically with respect to other methods.
+//
+// If any descriptor within the file conflicts with the descriptor of any
+// other registered file, then the registry is left unchanged
+// and an error is returned.
+func (r *Files) ReplaceFile(file protoreflect.FileDescriptor) error {
+	mu := r.mutex()
+	mu.Lock()
+	defer mu.Unlock()
+	prev := r.deleteFiles(file.Path())
+	if err := r.registerFile(file); err != nil {
+		for _, fd := range prev {
+			r.registerFile(fd) // cannot conflict since it was previously registered
+		}
+		return err
+	}
+	return nil
+}
+
+// deleteFiles removes all files with the provided path and
+// returns the removed files in registration order.
+func (r *Files) deleteFiles(path string) []protoreflect.FileDescriptor {
+	files := r.filesByPath[path]
+	for _, file := range files {
+		rangeTopLevelDescriptors(file, func(d protoreflect.Descriptor) {
+			if prev, _ := r.descsByName[d.FullName()].(protoreflect.Descriptor); prev == d {
+				delete(r.descsByName, d.FullName())
+			}
+		})
+		p := r.descsByName[file.Package()].(*packageDescriptor)
+		for i, fd := range p.files {
+			if fd == file {
+				p.files = append(p.files[:i:i], p.files[i+1:]...)
+				break
+			}
+		}
+		r.deletePackages(file.Package())
+		r.numFiles--
+	}
+	delete(r.filesByPath, path)
+	return files
+}
+
+// deletePackages removes the package and its parent packages
+// if they no longer contain any files or declarations.
+func (r *Files) deletePackages(name protoreflect.FullName) {
+	for ; name != ""; name = name.Parent() {
+		if p := r.descsByName[name].(*packageDescriptor); len(p.files) > 0 {
+			return
+		}
+		prefix := name + "."
+		for other := range r.descsByName {
+			if strings.HasPrefix(string(other), string(prefix)) {
+				return
+			}
+		}
+		delete(r.descsByName, name)
+	}
+}
+
 // Several well-known types were hosted in the google.golang.org/genproto module
 // but were later moved to this module. To avoid a weak dependency on the
 // genproto module (and its relatively large set of transitive dependencies),
@@ -223,10 +317,9 @@ func (r *Files) FindDescriptorByName(name protoreflect.FullName) (protoreflect.D
 	if r == nil {
 		return nil, NotFound
 	}
-	if r == GlobalFiles {
-		globalMutex.RLock()
-		defer globalMutex.RUnlock()
-	}
+	mu := r.mutex()
+	mu.RLock()
+	defer mu.RUnlock()
 	prefix := name
 	suffix := nameSuffix("")
 	for prefix != "" {
@@ -316,10 +409,9 @@ func (r *Files) FindFileByPath(path string) (protoreflect.FileDescriptor, error)
 	if r == nil {
 		return nil, NotFound
 	}
-	if r == GlobalFiles {
-		globalMutex.RLock()
-		defer globalMutex.RUnlock()
-	}
+	mu := r.mutex()
+	mu.RLock()
+	defer mu.RUnlock()
 	fds := r.filesByPath[path]
 	switch len(fds) {
 	case 0:
@@ -337,10 +429,9 @@ func (r *Files) NumFiles() int {
 	if r == nil {
 		return 0
 	}
-	if r == GlobalFiles {
-		globalMutex.RLock()
-		defer globalMutex.RUnlock()
-	}
+	mu := r.mutex()
+	mu.RLock()
+	defer mu.RUnlock()
 	return r.numFiles
 }
 
@@ -351,10 +442,9 @@ func (r *Files) RangeFiles(f func(protoreflect.FileDescriptor) bool) {
 	if r == nil {
 		return
 	}
-	if r == GlobalFiles {
-		globalMutex.RLock()
-		defer globalMutex.RUnlock()
-	}
+	mu := r.mutex()
+	mu.RLock()
+	defer mu.RUnlock()
 	for _, files := range r.filesByPath {
 		for _, file := range files {
 			if !f(file) {
@@ -369,10 +459,9 @@ func (r *Files) NumFilesByPackage(name protoreflect.FullName) int {
 	if r == nil {
 		return 0
 	}
-	if r == GlobalFiles {
-		globalMutex.RLock()
-		defer globalMutex.RUnlock()
-	}
+	mu := r.mutex()
+	mu.RLock()
+	defer mu.RUnlock()
 	p, ok := r.descsByName[name].(*packageDescriptor)
 	if !ok {
 		return 0
@@ -386,10 +475,9 @@ func (r *Files) RangeFilesByPackage(name protoreflect.FullName, f func(protorefl
 	if r == nil {
 		return
 	}
-	if r == GlobalFiles {
-		globalMutex.RLock()
-		defer globalMutex.RUnlock()
-	}
+	mu := r.mutex()
+	mu.RLock()
+	defer mu.RUnlock()
 	p, ok := r.descsByName[name].(*packageDescriptor)
 	if !ok {
 		return
@@ -474,7 +562,9 @@ var (
 )
 
 // Types is a registry for looking up or iterating over descriptor types.
-// The Find and Range methods are safe for concurrent use.
+// All methods are safe for concurrent use. In particular, types may be
+// registered, deleted, or replaced while other goroutines look up types.
+// The function passed to a Range method must not modify the registry.
 type Types struct {
 	typesByName         typesByName
 	extensionsByMessage extensionsByMessage
@@ -482,6 +572,118 @@ type Types struct {
 	numEnums      int
 	numMessages   int
 	numExtensions int
+
+	mu     sync.RWMutex
+	parent *Types
+
+	// typeURLPrefix and typeURLParser configure the handling of type URLs.
+	// If unset, those of the parent are used.
+	typeURLPrefix string
+	typeURLParser func(url string) (protoreflect.FullName, error)
+}
+
+// DefaultTypeURLPrefix is the prefix of type URLs constructed for messages
+// if no other prefix is configured with [Types.SetTypeURLPrefix].
+const DefaultTypeURLPrefix = "type.googleapis.com/"
+
+// SetTypeURLPrefix sets the prefix of the type URLs constructed for messages
+// resolved by r, such as by [google.golang.org/protobuf/types/known/anypb.New]
+// for [GlobalTypes]. The prefix should end with a '/'.
+//
+// Organizations that run their own type servers may use this to have
+// google.protobuf.Any messages refer to them. The prefix is inherited by
+// child registries that do not set their own.
+func (r *Types) SetTypeURLPrefix(prefix string) {
+	mu := r.mutex()
+	mu.Lock()
+	defer mu.Unlock()
+	r.typeURLPrefix = prefix
+}
+
+// TypeURLPrefix returns the prefix of the type URLs constructed for messages
+// resolved by r. It is [DefaultTypeURLPrefix] unless set otherwise with
+// [Types.SetTypeURLPrefix] on r or on one of its parents.
+func (r *Types) TypeURLPrefix() string {
+	for ; r != nil; r = r.parent {
+		mu := r.mutex()
+		mu.RLock()
+		prefix := r.typeURLPrefix
+		mu.RUnlock()
+		if prefix != "" {
+			return prefix
+		}
+	}
+	return DefaultTypeURLPrefix
+}
+
+// SetTypeURLParser sets the function used by [Types.FindMessageByURL]
+// to derive the full name of a message from its type URL.
+// If the function returns an error, FindMessageByURL returns it as is;
+// it should return [NotFound] for URLs that it does not accept.
+// Setting a nil function restores the default behavior, which accepts
+// any prefix and uses the portion of the URL after the last '/'.
+//
+// For example, to accept only URLs with known prefixes:
+//
+//	r.SetTypeURLParser(func(url string) (protoreflect.FullName, error) {
+//		for _, prefix := range []string{"type.googleapis.com/", "types.example.com/"} {
+//			if name, ok := strings.CutPrefix(url, prefix); ok {
+//				return protoreflect.FullName(name), nil
+//			}
+//		}
+//		return "", protoregistry.NotFound
+//	})
+//
+// The parser is inherited by child registries that do not set their own.
+func (r *Types) SetTypeURLParser(f func(url string) (protoreflect.FullName, error)) {
+	mu := r.mutex()
+	mu.Lock()
+	defer mu.Unlock()
+	r.typeURLParser = f
+}
+
+// ParseTypeURL returns the full name of the message identified by a type URL,
+// using the parser set with [Types.SetTypeURLParser] on r or on one of its
+// parents, or the default behavior if none is set.
+func (r *Types) ParseTypeURL(url string) (protoreflect.FullName, error) {
+	for p := r; p != nil; p = p.parent {
+		mu := p.mutex()
+		mu.RLock()
+		parse := p.typeURLParser
+		mu.RUnlock()
+		if parse != nil {
+			return parse(url)
+		}
+	}
+	message := protoreflect.FullName(url)
+	if i := strings.LastIndexByte(url, '/'); i >= 0 {
+		message = message[i+len("/"):]
+	}
+	return message, nil
+}
+
+// NewChildTypes returns a new, empty registry layered on top of parent
+// (typically [GlobalTypes]).
+//
+// The Find methods of the child consult the parent for any type
+// that is not registered in the child itself. Types registered in the child
+// never conflict with types in the parent; instead they shadow any type of
+// the same name (or extension of the same field number) in the parent.
+// Registering types in the child has no effect on the parent.
+//
+// The Num and Range methods of the child only consider the types
+// registered directly in the child.
+func NewChildTypes(parent *Types) *Types {
+	return &Types{parent: parent}
+}
+
+// mutex returns the lock guarding r.
+// The global registries share a single lock.
+func (r *Types) mutex() *sync.RWMutex {
+	if r == GlobalTypes {
+		return &globalMutex
+	}
+	return &r.mu
 }
 
 type (
@@ -498,11 +700,13 @@ func (r *Types) RegisterMessage(mt protoreflect.MessageType) error {
 	// examine the registry, so fetch it before locking.
 	md := mt.Descriptor()
 
-	if r == GlobalTypes {
-		globalMutex.Lock()
-		defer globalMutex.Unlock()
-	}
+	mu := r.mutex()
+	mu.Lock()
+	defer mu.Unlock()
+	return r.registerMessage(md, mt)
+}
 
+func (r *Types) registerMessage(md protoreflect.MessageDescriptor, mt protoreflect.MessageType) error {
 	if err := r.register("message", md, mt); err != nil {
 		return err
 	}
@@ -518,11 +722,13 @@ func (r *Types) RegisterEnum(et protoreflect.EnumType) error {
 	// examine the registry, so fetch it before locking.
 	ed := et.Descriptor()
 
-	if r == GlobalTypes {
-		globalMutex.Lock()
-		defer globalMutex.Unlock()
-	}
+	mu := r.mutex()
+	mu.Lock()
+	defer mu.Unlock()
+	return r.registerEnum(ed, et)
+}
 
+func (r *Types) registerEnum(ed protoreflect.EnumDescriptor, et protoreflect.EnumType) error {
 	if err := r.register("enum", ed, et); err != nil {
 		return err
 	}
@@ -541,11 +747,13 @@ func (r *Types) RegisterExtension(xt protoreflect.ExtensionType) error {
 	// legacy ExtensionDesc can consult the global registry.
 	xd := xt.TypeDescriptor()
 
-	if r == GlobalTypes {
-		globalMutex.Lock()
-		defer globalMutex.Unlock()
-	}
+	mu := r.mutex()
+	mu.Lock()
+	defer mu.Unlock()
+	return r.registerExtension(xd, xt)
+}
 
+func (r *Types) registerExtension(xd protoreflect.ExtensionTypeDescriptor, xt protoreflect.ExtensionType) error {
 	field := xd.Number()
 	message := xd.ContainingMessage().FullName()
 	if prev := r.extensionsByMessage[message][field]; prev != nil {
@@ -587,6 +795,133 @@ func (r *Types) register(kind string, desc protoreflect.Descriptor, typ any) err
 	return nil
 }
 
+// DeleteMessage removes the message type with the provided full name.
+//
+// This returns [NotFound] if no message with the name is registered.
+func (r *Types) DeleteMessage(message protoreflect.FullName) error {
+	mu := r.mutex()
+	mu.Lock()
+	defer mu.Unlock()
+	if _, err := r.deleteType("message", message); err != nil {
+		return err
+	}
+	r.numMessages--
+	return nil
+}
+
+// DeleteEnum removes the enum type with the provided full name.
+//
+// This returns [NotFound] if no enum with the name is registered.
+func (r *Types) DeleteEnum(enum protoreflect.FullName) error {
+	mu := r.mutex()
+	mu.Lock()
+	defer mu.Unlock()
+	if _, err := r.deleteType("enum", enum); err != nil {
+		return err
+	}
+	r.numEnums--
+	return nil
+}
+
+// DeleteExtension removes the extension type with the provided full name.
+//
+// This returns [NotFound] if no extension with the name is registered.
+func (r *Types) DeleteExtension(field protoreflect.FullName) error {
+	mu := r.mutex()
+	mu.Lock()
+	defer mu.Unlock()
+	_, err := r.deleteExtension(field)
+	return err
+}
+
+func (r *Types) deleteExtension(field protoreflect.FullName) (protoreflect.ExtensionType, error) {
+	v, err := r.deleteType("extension", field)
+	if err != nil {
+		return nil, err
+	}
+	xt := v.(protoreflect.ExtensionType)
+	xd := xt.TypeDescriptor()
+	message := xd.ContainingMessage().FullName()
+	if r.extensionsByMessage[message][xd.Number()] == xt {
+		delete(r.extensionsByMessage[message], xd.Number())
+		if len(r.extensionsByMessage[message]) == 0 {
+			delete(r.extensionsByMessage, message)
+		}
+	}
+	r.numExtensions--
+	return xt, nil
+}
+
+// deleteType removes the type of the given kind with the provided name
+// and returns it.
+func (r *Types) deleteType(kind string, name protoreflect.FullName) (any, error) {
+	v := r.typesByName[name]
+	if v == nil {
+		return nil, NotFound
+	}
+	if got := typeName(v); got != kind {
+		return nil, errors.New("found wrong type: got %v, want %v", got, kind)
+	}
+	delete(r.typesByName, name)
+	return v, nil
+}
+
+// ReplaceMessage registers the provided message type in place of
+// any message type previously registered with the same full name.
+//
+// If a naming conflict occurs with a type of a different kind,
+// the registry is left unchanged and an error is returned.
+func (r *Types) ReplaceMessage(mt protoreflect.MessageType) error {
+	md := mt.Descriptor()
+
+	mu := r.mutex()
+	mu.Lock()
+	defer mu.Unlock()
+	if _, err := r.deleteType("message", md.FullName()); err == nil {
+		r.numMessages--
+	}
+	return r.registerMessage(md, mt)
+}
+
+// ReplaceEnum registers the provided enum type in place of
+// any enum type previously registered with the same full name.
+//
+// If a naming conflict occurs with a type of a different kind,
+// the registry is left unchanged and an error is returned.
+func (r *Types) ReplaceEnum(et protoreflect.EnumType) error {
+	ed := et.Descriptor()
+
+	mu := r.mutex()
+	mu.Lock()
+	defer mu.Unlock()
+	if _, err := r.deleteType("enum", ed.FullName()); err == nil {
+		r.numEnums--
+	}
+	return r.registerEnum(ed, et)
+}
+
+// ReplaceExtension registers the provided extension type in place of
+// any extension type previously registered with the same full name.
+//
+// If a naming conflict occurs with a type of a different kind or with
+// another extension of the same number on the same message,
+// the registry is left unchanged and an error is returned.
+func (r *Types) ReplaceExtension(xt protoreflect.ExtensionType) error {
+	xd := xt.TypeDescriptor()
+
+	mu := r.mutex()
+	mu.Lock()
+	defer mu.Unlock()
+	prev, _ := r.deleteExtension(xd.FullName())
+	if err := r.registerExtension(xd, xt); err != nil {
+		if prev != nil {
+			r.registerExtension(prev.TypeDescriptor(), prev) // cannot conflict since it was previously registered
+		}
+		return err
+	}
+	return nil
+}
+
 // FindEnumByName looks up an enum by its full name.
 // E.g., "google.protobuf.Field.Kind".
 //
@@ -595,17 +930,16 @@ func (r *Types) FindEnumByName(enum protoreflect.FullName) (protoreflect.EnumTyp
 	if r == nil {
 		return nil, NotFound
 	}
-	if r == GlobalTypes {
-		globalMutex.RLock()
-		defer globalMutex.RUnlock()
-	}
+	mu := r.mutex()
+	mu.RLock()
+	defer mu.RUnlock()
 	if v := r.typesByName[enum]; v != nil {
 		if et, _ := v.(protoreflect.EnumType); et != nil {
 			return et, nil
 		}
 		return nil, errors.New("found wrong type: got %v, want enum", typeName(v))
 	}
-	return nil, NotFound
+	return r.parent.FindEnumByName(enum)
 }
 
 // FindMessageByName looks up a message by its full name,
@@ -616,45 +950,33 @@ func (r *Types) FindMessageByName(message protoreflect.FullName) (protoreflect.M
 	if r == nil {
 		return nil, NotFound
 	}
-	if r == GlobalTypes {
-		globalMutex.RLock()
-		defer globalMutex.RUnlock()
-	}
+	mu := r.mutex()
+	mu.RLock()
+	defer mu.RUnlock()
 	if v := r.typesByName[message]; v != nil {
 		if mt, _ := v.(protoreflect.MessageType); mt != nil {
 			return mt, nil
 		}
 		return nil, errors.New("found wrong type: got %v, want message", typeName(v))
 	}
-	return nil, NotFound
+	return r.parent.FindMessageByName(message)
 }
 
 // FindMessageByURL looks up a message by a URL identifier.
 // See documentation on google.protobuf.Any.type_url for the URL format.
+// The full name of the message is derived from the URL as configured
+// with [Types.SetTypeURLParser].
 //
 // This returns (nil, [NotFound]) if not found.
 func (r *Types) FindMessageByURL(url string) (protoreflect.MessageType, error) {
-	// This function is similar to FindMessageByName but
-	// truncates anything before and including '/' in the URL.
 	if r == nil {
 		return nil, NotFound
 	}
-	if r == GlobalTypes {
-		globalMutex.RLock()
-		defer globalMutex.RUnlock()
-	}
-	message := protoreflect.FullName(url)
-	if i := strings.LastIndexByte(url, '/'); i >= 0 {
-		message = message[i+len("/"):]
+	message, err := r.ParseTypeURL(url)
+	if err != nil {
+		return nil, err
 	}
-
-	if v := r.typesByName[message]; v != nil {
-		if mt, _ := v.(protoreflect.MessageType); mt != nil {
-			return mt, nil
-		}
-		return nil, errors.New("found wrong type: got %v, want message", typeName(v))
-	}
-	return nil, NotFound
+	return r.FindMessageByName(message)
 }
 
 // FindExtensionByName looks up a extension field by the field's full name.
@@ -667,10 +989,9 @@ func (r *Types) FindExtensionByName(field protoreflect.FullName) (protoreflect.E
 	if r == nil {
 		return nil, NotFound
 	}
-	if r == GlobalTypes {
-		globalMutex.RLock()
-		defer globalMutex.RUnlock()
-	}
+	mu := r.mutex()
+	mu.RLock()
+	defer mu.RUnlock()
 	if v := r.typesByName[field]; v != nil {
 		if xt, _ := v.(protoreflect.ExtensionType); xt != nil {
 			return xt, nil
@@ -697,7 +1018,7 @@ func (r *Types) FindExtensionByName(field protoreflect.FullName) (protoreflect.E
 
 		return nil, errors.New("found wrong type: got %v, want extension", typeName(v))
 	}
-	return nil, NotFound
+	return r.parent.FindExtensionByName(field)
 }
 
 // FindExtensionByNumber looks up a extension field by the field number
@@ -708,14 +1029,13 @@ func (r *Types) FindExtensionByNumber(message protoreflect.FullName, field proto
 	if r == nil {
 		return nil, NotFound
 	}
-	if r == GlobalTypes {
-		globalMutex.RLock()
-		defer globalMutex.RUnlock()
-	}
+	mu := r.mutex()
+	mu.RLock()
+	defer mu.RUnlock()
 	if xt, ok := r.extensionsByMessage[message][field]; ok {
 		return xt, nil
 	}
-	return nil, NotFound
+	return r.parent.FindExtensionByNumber(message, field)
 }
 
 // NumEnums reports the number of registered enums.
@@ -723,10 +1043,9 @@ func (r *Types) NumEnums() int {
 	if r == nil {
 		return 0
 	}
-	if r == GlobalTypes {
-		globalMutex.RLock()
-		defer globalMutex.RUnlock()
-	}
+	mu := r.mutex()
+	mu.RLock()
+	defer mu.RUnlock()
 	return r.numEnums
 }
 
@@ -736,10 +1055,9 @@ func (r *Types) RangeEnums(f func(protoreflect.EnumType) bool) {
 	if r == nil {
 		return
 	}
-	if r == GlobalTypes {
-		globalMutex.RLock()
-		defer globalMutex.RUnlock()
-	}
+	mu := r.mutex()
+	mu.RLock()
+	defer mu.RUnlock()
 	for _, typ := range r.typesByName {
 		if et, ok := typ.(protoreflect.EnumType); ok {
 			if !f(et) {
@@ -754,10 +1072,9 @@ func (r *Types) NumMessages() int {
 	if r == nil {
 		return 0
 	}
-	if r == GlobalTypes {
-		globalMutex.RLock()
-		defer globalMutex.RUnlock()
-	}
+	mu := r.mutex()
+	mu.RLock()
+	defer mu.RUnlock()
 	return r.numMessages
 }
 
@@ -767,10 +1084,9 @@ func (r *Types) RangeMessages(f func(protoreflect.MessageType) bool) {
 	if r == nil {
 		return
 	}
-	if r == GlobalTypes {
-		globalMutex.RLock()
-		defer globalMutex.RUnlock()
-	}
+	mu := r.mutex()
+	mu.RLock()
+	defer mu.RUnlock()
 	for _, typ := range r.typesByName {
 		if mt, ok := typ.(protoreflect.MessageType); ok {
 			if !f(mt) {
@@ -785,10 +1101,9 @@ func (r *Types) NumExtensions() int {
 	if r == nil {
 		return 0
 	}
-	if r == GlobalTypes {
-		globalMutex.RLock()
-		defer globalMutex.RUnlock()
-	}
+	mu := r.mutex()
+	mu.RLock()
+	defer mu.RUnlock()
 	return r.numExtensions
 }
 
@@ -798,10 +1113,9 @@ func (r *Types) RangeExtensions(f func(protoreflect.ExtensionType) bool) {
 	if r == nil {
 		return
 	}
-	if r == GlobalTypes {
-		globalMutex.RLock()
-		defer globalMutex.RUnlock()
-	}
+	mu := r.mutex()
+	mu.RLock()
+	defer mu.RUnlock()
 	for _, typ := range r.typesByName {
 		if xt, ok := typ.(protoreflect.ExtensionType); ok {
 			if !f(xt) {
@@ -817,10 +1131,9 @@ func (r *Types) NumExtensionsByMessage(message protoreflect.FullName) int {
 	if r == nil {
 		return 0
 	}
-	if r == GlobalTypes {
-		globalMutex.RLock()
-		defer globalMutex.RUnlock()
-	}
+	mu := r.mutex()
+	mu.RLock()
+	defer mu.RUnlock()
 	return len(r.extensionsByMessage[message])
 }
 
@@ -830,10 +1143,9 @@ func (r *Types) RangeExtensionsByMessage(message protoreflect.FullName, f func(p
 	if r == nil {
 		return
 	}
-	if r == GlobalTypes {
-		globalMutex.RLock()
-		defer globalMutex.RUnlock()
-	}
+	mu := r.mutex()
+	mu.RLock()
+	defer mu.RUnlock()
 	for _, xt := range r.extensionsByMessage[message] {
 		if !f(xt) {
 			return
@@ -841,6 +1153,84 @@ func (r *Types) RangeExtensionsByMessage(message protoreflect.FullName, f func(p
 	}
 }
 
+// MessageExtensions describes the extensions registered for a message,
+// as reported by [Types.ExtensionsByMessage].
+type MessageExtensions struct {
+	// Message is the full name of the extended message.
+	Message protoreflect.FullName
+
+	// Ranges are the extension ranges declared by the extended message.
+	// It is nil if the descriptor of the message is unavailable, which is
+	// the case if no extensions are registered for it and the message
+	// itself is not registered.
+	Ranges protoreflect.FieldRanges
+
+	// Extensions are the registered extensions, ordered by field number.
+	Extensions []ExtensionInfo
+}
+
+// ExtensionInfo describes a registered extension.
+type ExtensionInfo struct {
+	// Type is the registered extension type.
+	Type protoreflect.ExtensionType
+
+	// Number is the field number of the extension.
+	Number protoreflect.FieldNumber
+
+	// FullName is the full name of the extension field.
+	FullName protoreflect.FullName
+
+	// File is the path of the .proto file which declares the extension.
+	File string
+
+	// GoPackagePath is the import path of the Go package which contains
+	// the generated code for the extension, if known.
+	GoPackagePath string
+
+	// InRange reports whether Number is within one of the extension ranges
+	// declared by the extended message. An extension outside of those ranges
+	// cannot be set on the message.
+	InRange bool
+}
+
+// ExtensionsByMessage reports the extensions registered for a given
+// message type, together with the extension ranges declared by the message
+// and the files which declare the extensions. It is intended for debugging
+// problems such as conflicting or unexpected extensions.
+// As with [Types.RangeExtensionsByMessage], only extensions registered
+// directly in r are reported.
+func (r *Types) ExtensionsByMessage(message protoreflect.FullName) MessageExtensions {
+	info := MessageExtensions{Message: message}
+	var xts []protoreflect.ExtensionType
+	r.RangeExtensionsByMessage(message, func(xt protoreflect.ExtensionType) bool {
+		xts = append(xts, xt)
+		return true
+	})
+	if len(xts) > 0 {
+		info.Ranges = xts[0].TypeDescriptor().ContainingMessage().ExtensionRanges()
+	} else if mt, err := r.FindMessageByName(message); err == nil {
+		info.Ranges = mt.Descriptor().ExtensionRanges()
+	}
+	for _, xt := range xts {
+		xd := xt.TypeDescriptor()
+		x := ExtensionInfo{
+			Type:          xt,
+			Number:        xd.Number(),
+			FullName:      xd.FullName(),
+			GoPackagePath: goPackage(xt),
+			InRange:       info.Ranges.Has(xd.Number()),
+		}
+		if fd := xd.ParentFile(); fd != nil {
+			x.File = fd.Path()
+		}
+		info.Extensions = append(info.Extensions, x)
+	}
+	sort.Slice(info.Extensions, func(i, j int) bool {
+		return info.Extensions[i].Number < info.Extensions[j].Number
+	})
+	return info
+}
+
 func typeName(t any) string {
 	switch t.(type) {
 	case protoreflect.EnumType:
@@ -7,12 +7,14 @@ package protoregistry_test
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 
 	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/encoding/protowire"
 	pimpl "google.golang.org/protobuf/internal/impl"
 	"google.golang.org/protobuf/reflect/protodesc"
 	"google.golang.org/protobuf/reflect/protoreflect"
@@ -20,6 +22,7 @@ import (
 
 	testpb "google.golang.org/protobuf/internal/testprotos/registry"
 	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
 )
 
 func mustMakeFile(s string) protoreflect.FileDescriptor {
@@ -653,3 +656,263 @@ func TestTypes(t *testing.T) {
 		}
 	})
 }
+
+func TestFilesDeleteReplace(t *testing.T) {
+	registry := new(protoregistry.Files)
+	for _, fd := range []protoreflect.FileDescriptor{
+		mustMakeFile(`syntax:"proto2" name:"a.proto" package:"foo.bar" message_type:[{name:"A"}]`),
+		mustMakeFile(`syntax:"proto2" name:"b.proto" package:"foo" message_type:[{name:"B"}]`),
+	} {
+		if err := registry.RegisterFile(fd); err != nil {
+			t.Fatalf("RegisterFile(%q) = %v", fd.Path(), err)
+		}
+	}
+
+	if err := registry.DeleteFile("missing.proto"); err != protoregistry.NotFound {
+		t.Errorf("DeleteFile(%q) = %v, want NotFound", "missing.proto", err)
+	}
+	if err := registry.DeleteFile("a.proto"); err != nil {
+		t.Fatalf("DeleteFile(%q) = %v", "a.proto", err)
+	}
+	if got := registry.NumFiles(); got != 1 {
+		t.Errorf("NumFiles() = %v, want 1", got)
+	}
+	if _, err := registry.FindDescriptorByName("foo.bar.A"); err != protoregistry.NotFound {
+		t.Errorf("FindDescriptorByName(%q) = %v, want NotFound", "foo.bar.A", err)
+	}
+	if _, err := registry.FindDescriptorByName("foo.B"); err != nil {
+		t.Errorf("FindDescriptorByName(%q) = %v", "foo.B", err)
+	}
+	// The package "foo.bar" is no longer in use, so a message may take its name.
+	fd := mustMakeFile(`syntax:"proto2" name:"bar.proto" package:"foo" message_type:[{name:"bar"}]`)
+	if err := registry.RegisterFile(fd); err != nil {
+		t.Errorf("RegisterFile(%q) = %v", fd.Path(), err)
+	}
+
+	// Replace a file with a new version that renames its message.
+	fd = mustMakeFile(`syntax:"proto2" name:"b.proto" package:"foo" message_type:[{name:"C"}]`)
+	if err := registry.ReplaceFile(fd); err != nil {
+		t.Fatalf("ReplaceFile(%q) = %v", fd.Path(), err)
+	}
+	if _, err := registry.FindDescriptorByName("foo.B"); err != protoregistry.NotFound {
+		t.Errorf("FindDescriptorByName(%q) = %v, want NotFound", "foo.B", err)
+	}
+	if _, err := registry.FindDescriptorByName("foo.C"); err != nil {
+		t.Errorf("FindDescriptorByName(%q) = %v", "foo.C", err)
+	}
+
+	// A conflicting replacement leaves the registry unchanged.
+	fd = mustMakeFile(`syntax:"proto2" name:"b.proto" package:"foo" message_type:[{name:"bar"}]`)
+	if err := registry.ReplaceFile(fd); err == nil || !strings.Contains(err.Error(), "name conflict") {
+		t.Errorf("ReplaceFile(%q) = %v, want name conflict", fd.Path(), err)
+	}
+	if _, err := registry.FindDescriptorByName("foo.C"); err != nil {
+		t.Errorf("FindDescriptorByName(%q) = %v", "foo.C", err)
+	}
+	if got := registry.NumFiles(); got != 2 {
+		t.Errorf("NumFiles() = %v, want 2", got)
+	}
+}
+
+func TestTypesDeleteReplace(t *testing.T) {
+	mt1 := pimpl.Export{}.MessageTypeOf(&testpb.Message1{})
+	et1 := pimpl.Export{}.EnumTypeOf(testpb.Enum1_ONE)
+	xt1 := testpb.E_StringField
+	registry := new(protoregistry.Types)
+	registry.RegisterMessage(mt1)
+	registry.RegisterEnum(et1)
+	registry.RegisterExtension(xt1)
+
+	if err := registry.DeleteMessage("testprotos.Enum1"); err == nil || err == protoregistry.NotFound {
+		t.Errorf("DeleteMessage(%q) = %v, want wrong type error", "testprotos.Enum1", err)
+	}
+	if err := registry.DeleteMessage("testprotos.Message1"); err != nil {
+		t.Errorf("DeleteMessage(%q) = %v", "testprotos.Message1", err)
+	}
+	if err := registry.DeleteMessage("testprotos.Message1"); err != protoregistry.NotFound {
+		t.Errorf("DeleteMessage(%q) = %v, want NotFound", "testprotos.Message1", err)
+	}
+	if err := registry.DeleteEnum("testprotos.Enum1"); err != nil {
+		t.Errorf("DeleteEnum(%q) = %v", "testprotos.Enum1", err)
+	}
+	if err := registry.DeleteExtension("testprotos.string_field"); err != nil {
+		t.Errorf("DeleteExtension(%q) = %v", "testprotos.string_field", err)
+	}
+	if _, err := registry.FindExtensionByNumber("testprotos.Message1", 11); err != protoregistry.NotFound {
+		t.Errorf("FindExtensionByNumber(%q, 11) = %v, want NotFound", "testprotos.Message1", err)
+	}
+	if n := registry.NumMessages() + registry.NumEnums() + registry.NumExtensions(); n != 0 {
+		t.Errorf("registry contains %v types after deletion, want 0", n)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := registry.ReplaceMessage(mt1); err != nil {
+			t.Errorf("ReplaceMessage(%v) = %v", mt1.Descriptor().FullName(), err)
+		}
+		if err := registry.ReplaceEnum(et1); err != nil {
+			t.Errorf("ReplaceEnum(%v) = %v", et1.Descriptor().FullName(), err)
+		}
+		if err := registry.ReplaceExtension(xt1); err != nil {
+			t.Errorf("ReplaceExtension(%v) = %v", xt1.TypeDescriptor().FullName(), err)
+		}
+	}
+	if got := registry.NumMessages(); got != 1 {
+		t.Errorf("NumMessages() = %v, want 1", got)
+	}
+	if got := registry.NumEnums(); got != 1 {
+		t.Errorf("NumEnums() = %v, want 1", got)
+	}
+	if got := registry.NumExtensions(); got != 1 {
+		t.Errorf("NumExtensions() = %v, want 1", got)
+	}
+	if _, err := registry.FindExtensionByNumber("testprotos.Message1", 11); err != nil {
+		t.Errorf("FindExtensionByNumber(%q, 11) = %v", "testprotos.Message1", err)
+	}
+}
+
+func TestConcurrentMutation(t *testing.T) {
+	files := new(protoregistry.Files)
+	fd := mustMakeFile(`syntax:"proto2" name:"a.proto" package:"foo" message_type:[{name:"A"}]`)
+	files.RegisterFile(fd)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				files.FindDescriptorByName("foo.A")
+				files.RangeFiles(func(protoreflect.FileDescriptor) bool { return true })
+			}
+		}()
+	}
+	for j := 0; j < 100; j++ {
+		if err := files.ReplaceFile(fd); err != nil {
+			t.Errorf("ReplaceFile(%q) = %v", fd.Path(), err)
+		}
+	}
+	wg.Wait()
+}
+
+func TestChildTypes(t *testing.T) {
+	mt1 := pimpl.Export{}.MessageTypeOf(&testpb.Message1{})
+	xt1 := testpb.E_StringField
+	parent := new(protoregistry.Types)
+	parent.RegisterMessage(mt1)
+	parent.RegisterExtension(xt1)
+
+	child := protoregistry.NewChildTypes(parent)
+	if got, err := child.FindMessageByName("testprotos.Message1"); err != nil || got != mt1 {
+		t.Errorf("child.FindMessageByName(%q) = %v, %v; want parent type", "testprotos.Message1", got, err)
+	}
+	if got, err := child.FindExtensionByNumber("testprotos.Message1", 11); err != nil || got != xt1 {
+		t.Errorf("child.FindExtensionByNumber(%q, 11) = %v, %v; want parent type", "testprotos.Message1", got, err)
+	}
+	if _, err := child.FindEnumByName("testprotos.Enum1"); err != protoregistry.NotFound {
+		t.Errorf("child.FindEnumByName(%q) = %v, want NotFound", "testprotos.Enum1", err)
+	}
+
+	// Registering a type of the same name shadows the parent type.
+	mt2 := dynamicpb.NewMessageType(mt1.Descriptor())
+	if err := child.RegisterMessage(mt2); err != nil {
+		t.Fatalf("child.RegisterMessage(%v) = %v", mt2.Descriptor().FullName(), err)
+	}
+	xt2 := dynamicpb.NewExtensionType(xt1.TypeDescriptor().Descriptor())
+	if err := child.RegisterExtension(xt2); err != nil {
+		t.Fatalf("child.RegisterExtension(%v) = %v", xt2.TypeDescriptor().FullName(), err)
+	}
+	if got, err := child.FindMessageByURL("type.googleapis.com/testprotos.Message1"); err != nil || got != mt2 {
+		t.Errorf("child.FindMessageByURL() = %v, %v; want child type", got, err)
+	}
+	if got, err := child.FindExtensionByName("testprotos.string_field"); err != nil || got != xt2 {
+		t.Errorf("child.FindExtensionByName() = %v, %v; want child type", got, err)
+	}
+	if got, err := parent.FindMessageByName("testprotos.Message1"); err != nil || got != mt1 {
+		t.Errorf("parent.FindMessageByName() = %v, %v; want parent type", got, err)
+	}
+	if got := child.NumMessages(); got != 1 {
+		t.Errorf("child.NumMessages() = %v, want 1", got)
+	}
+}
+
+func TestExtensionsByMessage(t *testing.T) {
+	registry := new(protoregistry.Types)
+	for _, xt := range []protoreflect.ExtensionType{
+		testpb.E_Message4_StringField,
+		testpb.E_StringField,
+		testpb.E_EnumField,
+	} {
+		if err := registry.RegisterExtension(xt); err != nil {
+			t.Fatalf("registry.RegisterExtension(%v) = %v", xt.TypeDescriptor().FullName(), err)
+		}
+	}
+
+	got := registry.ExtensionsByMessage("testprotos.Message1")
+	if got.Message != "testprotos.Message1" {
+		t.Errorf("ExtensionsByMessage().Message = %v, want testprotos.Message1", got.Message)
+	}
+	if got.Ranges == nil || got.Ranges.Len() != 1 || got.Ranges.Get(0) != [2]protoreflect.FieldNumber{10, protowire.MaxValidNumber + 1} {
+		t.Errorf("ExtensionsByMessage().Ranges = %v, want [10, max]", got.Ranges)
+	}
+	const path = "internal/testprotos/registry/test.proto"
+	const goPkg = "google.golang.org/protobuf/internal/testprotos/registry"
+	want := []protoregistry.ExtensionInfo{
+		{Type: testpb.E_StringField, Number: 11, FullName: "testprotos.string_field", File: path, GoPackagePath: goPkg, InRange: true},
+		{Type: testpb.E_EnumField, Number: 12, FullName: "testprotos.enum_field", File: path, GoPackagePath: goPkg, InRange: true},
+		{Type: testpb.E_Message4_StringField, Number: 23, FullName: "testprotos.Message4.string_field", File: path, GoPackagePath: goPkg, InRange: true},
+	}
+	if diff := cmp.Diff(want, got.Extensions, cmp.Comparer(func(x, y protoreflect.ExtensionType) bool { return x == y })); diff != "" {
+		t.Errorf("ExtensionsByMessage().Extensions mismatch (-want +got):\n%v", diff)
+	}
+
+	// The ranges of a registered message are reported
+	// even if no extensions are registered for it.
+	if err := registry.RegisterMessage(pimpl.Export{}.MessageTypeOf(&testpb.Message2{})); err != nil {
+		t.Fatal(err)
+	}
+	if got := registry.ExtensionsByMessage("testprotos.Message2"); got.Ranges == nil || got.Ranges.Len() != 0 || got.Extensions != nil {
+		t.Errorf("ExtensionsByMessage(testprotos.Message2) = %+v, want empty ranges and no extensions", got)
+	}
+	if got := registry.ExtensionsByMessage("testprotos.NoSuchMessage"); got.Ranges != nil || got.Extensions != nil {
+		t.Errorf("ExtensionsByMessage(testprotos.NoSuchMessage) = %+v, want nil ranges and no extensions", got)
+	}
+}
+
+func TestTypeURL(t *testing.T) {
+	mt := pimpl.Export{}.MessageTypeOf(&testpb.Message1{})
+	parent := new(protoregistry.Types)
+	parent.RegisterMessage(mt)
+	child := protoregistry.NewChildTypes(parent)
+
+	if got := child.TypeURLPrefix(); got != protoregistry.DefaultTypeURLPrefix {
+		t.Errorf("child.TypeURLPrefix() = %q, want %q", got, protoregistry.DefaultTypeURLPrefix)
+	}
+	parent.SetTypeURLPrefix("types.example.com/")
+	if got := child.TypeURLPrefix(); got != "types.example.com/" {
+		t.Errorf("child.TypeURLPrefix() = %q, want parent prefix", got)
+	}
+	child.SetTypeURLPrefix("types.example.org/")
+	if got := child.TypeURLPrefix(); got != "types.example.org/" {
+		t.Errorf("child.TypeURLPrefix() = %q, want child prefix", got)
+	}
+
+	if got, err := child.FindMessageByURL("example.net/a/b/testprotos.Message1"); err != nil || got != mt {
+		t.Errorf("FindMessageByURL() = %v, %v; want %v", got, err, mt.Descriptor().FullName())
+	}
+	parent.SetTypeURLParser(func(url string) (protoreflect.FullName, error) {
+		if name, ok := strings.CutPrefix(url, "types.example.com/"); ok {
+			return protoreflect.FullName(name), nil
+		}
+		return "", protoregistry.NotFound
+	})
+	if got, err := child.FindMessageByURL("types.example.com/testprotos.Message1"); err != nil || got != mt {
+		t.Errorf("FindMessageByURL(accepted prefix) = %v, %v; want %v", got, err, mt.Descriptor().FullName())
+	}
+	if _, err := child.FindMessageByURL("example.net/testprotos.Message1"); err != protoregistry.NotFound {
+		t.Errorf("FindMessageByURL(rejected prefix) error = %v, want NotFound", err)
+	}
+	parent.SetTypeURLParser(nil)
+	if got, err := child.FindMessageByURL("example.net/testprotos.Message1"); err != nil || got != mt {
+		t.Errorf("FindMessageByURL(default parser) = %v, %v; want %v", got, err, mt.Descriptor().FullName())
+	}
+}
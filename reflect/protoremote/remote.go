@@ -0,0 +1,421 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package protoremote resolves message types by fetching their descriptors
+// from a type server.
+//
+// The type URL of a google.protobuf.Any message may refer to a type server
+// from which the definition of the contained message can be obtained.
+// A [Resolver] fetches such definitions on demand, which permits services
+// to decode Any messages whose types are not linked into the program:
+//
+//	r := &protoremote.Resolver{Fetcher: protoremote.HTTPFetcher{
+//		AllowedPrefixes: []string{"https://types.example.com/"},
+//	}}
+//	err := protojson.UnmarshalOptions{Resolver: r}.Unmarshal(b, m)
+//
+// Messages of fetched types are represented by [dynamicpb.Message].
+//
+// # Trust
+//
+// The type URLs passed to a Resolver usually come from the input being
+// unmarshaled. If that input is untrusted, so are the URLs: without
+// restriction, whoever produced the input could direct the program to
+// issue requests to any host it can reach, including internal services.
+// For this reason, [HTTPFetcher] only fetches URLs which start with one of
+// its AllowedPrefixes, and only over HTTPS unless AllowHTTP is set.
+// Other implementations of [Fetcher] should apply similar restrictions.
+//
+// The fetched descriptors are trusted to describe the types correctly,
+// so type servers should only be used if they are trusted at least as much
+// as the input. The size of each response is limited, and the results of
+// fetches, including failures, are cached to limit the number of requests
+// which a single input can cause.
+package protoremote
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/internal/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// A Fetcher fetches the descriptors of the message type identified by
+// a type URL. The returned set must contain the file declaring the message
+// and all files it depends on.
+//
+// Implementations for transports other than HTTP, such as gRPC,
+// may be provided by users of this package.
+type Fetcher interface {
+	Fetch(ctx context.Context, url string) (*descriptorpb.FileDescriptorSet, error)
+}
+
+// FetcherFunc is an adapter to allow the use of an ordinary function
+// as a [Fetcher].
+type FetcherFunc func(ctx context.Context, url string) (*descriptorpb.FileDescriptorSet, error)
+
+// Fetch returns f(ctx, url).
+func (f FetcherFunc) Fetch(ctx context.Context, url string) (*descriptorpb.FileDescriptorSet, error) {
+	return f(ctx, url)
+}
+
+// DefaultMaxSize is the maximum size of a response fetched by an
+// [HTTPFetcher] whose MaxSize is not set.
+const DefaultMaxSize = 4 << 20
+
+// HTTPFetcher is a [Fetcher] that issues an HTTP GET request for the type URL.
+// URLs without a scheme use https, as described in the documentation of
+// google.protobuf.Any. The response body must contain a google.protobuf.FileDescriptorSet
+// in the binary wire format.
+type HTTPFetcher struct {
+	// Client is used to issue requests.
+	// If nil, http.DefaultClient is used.
+	Client *http.Client
+
+	// AllowedPrefixes lists the URLs from which types may be fetched,
+	// such as "https://types.example.com/". A type URL is only fetched
+	// if it starts with one of them, after the https scheme is added to
+	// a URL without a scheme; this also applies to redirects.
+	// Each prefix should end with a "/", so that it does not match
+	// other hosts whose names have the same prefix.
+	// If empty, no URLs are fetched.
+	AllowedPrefixes []string
+
+	// AllowHTTP permits URLs with the http scheme.
+	// By default, only URLs with the https scheme are fetched.
+	AllowHTTP bool
+
+	// MaxSize, if positive, is the maximum size in bytes of a response body.
+	// If zero, DefaultMaxSize is used.
+	MaxSize int64
+}
+
+// Fetch fetches the descriptors for url.
+func (f HTTPFetcher) Fetch(ctx context.Context, url string) (*descriptorpb.FileDescriptorSet, error) {
+	if !strings.Contains(url, "://") {
+		url = "https://" + url
+	}
+	if err := f.checkURL(url); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := http.DefaultClient
+	if f.Client != nil {
+		client = f.Client
+	}
+	// Check redirects against the allowed prefixes as well,
+	// using a copy of the client to avoid modifying it.
+	c := *client
+	checkRedirect := c.CheckRedirect
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if err := f.checkURL(req.URL.String()); err != nil {
+			return err
+		}
+		if checkRedirect != nil {
+			return checkRedirect(req, via)
+		}
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: %s", url, resp.Status)
+	}
+	maxSize := f.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > maxSize {
+		return nil, fmt.Errorf("fetching %s: response exceeds %d bytes", url, maxSize)
+	}
+	fds := new(descriptorpb.FileDescriptorSet)
+	if err := proto.Unmarshal(b, fds); err != nil {
+		return nil, err
+	}
+	return fds, nil
+}
+
+// checkURL reports an error if url may not be fetched.
+func (f HTTPFetcher) checkURL(url string) error {
+	if !strings.HasPrefix(url, "https://") && !(f.AllowHTTP && strings.HasPrefix(url, "http://")) {
+		return fmt.Errorf("fetching %s: scheme not allowed", url)
+	}
+	for _, p := range f.AllowedPrefixes {
+		if strings.HasPrefix(url, p) {
+			return nil
+		}
+	}
+	return fmt.Errorf("fetching %s: URL not allowed", url)
+}
+
+// DefaultCacheSize is the number of type URLs whose results are cached
+// by a [Resolver] whose CacheSize is not set.
+const DefaultCacheSize = 1000
+
+// DefaultFailureTTL is the duration for which failures are cached by a
+// [Resolver] whose FailureTTL is not set.
+const DefaultFailureTTL = time.Minute
+
+// A Resolver resolves message types by fetching their descriptors.
+// It implements [protoregistry.MessageTypeResolver] and
+// [protoregistry.ExtensionTypeResolver], and may be used as the Resolver
+// of the unmarshal options of the proto, protojson, and prototext packages.
+//
+// Types are first looked up in Local. Types that are not found there are
+// fetched by URL and cached. Its methods are safe for concurrent use,
+// but its fields must not be modified after first use.
+type Resolver struct {
+	// Local resolves types without fetching them.
+	// If nil, protoregistry.GlobalTypes is used.
+	Local interface {
+		protoregistry.MessageTypeResolver
+		protoregistry.ExtensionTypeResolver
+	}
+
+	// Fetcher fetches the descriptors of types not found in Local.
+	// If nil, types are only resolved using Local.
+	Fetcher Fetcher
+
+	// Timeout, if positive, limits the duration of each fetch.
+	Timeout time.Duration
+
+	// TTL, if positive, is the duration for which fetched types are cached.
+	// Otherwise, fetched types are cached until they are evicted.
+	TTL time.Duration
+
+	// FailureTTL is the duration for which a failure to fetch a type is
+	// cached, during which lookups of its URL fail without fetching it
+	// again. If zero, DefaultFailureTTL is used.
+	// If negative, failures are not cached.
+	FailureTTL time.Duration
+
+	// CacheSize, if positive, is the maximum number of type URLs whose
+	// results are cached. The least recently used entries are evicted
+	// first. If zero, DefaultCacheSize is used.
+	CacheSize int
+
+	mu       sync.Mutex
+	lru      list.List                               // of *cacheEntry, most recently used first
+	byURL    map[string]*list.Element                // keyed by type URL
+	byName   map[protoreflect.FullName]*list.Element // successful fetches only
+	inflight map[string]*fetchCall                   // keyed by type URL
+}
+
+// fetchCall is a fetch in progress, which concurrent lookups
+// of the same type URL wait for rather than fetching it again.
+type fetchCall struct {
+	done chan struct{} // closed once mt and err are set
+	mt   protoreflect.MessageType
+	err  error
+}
+
+type cacheEntry struct {
+	url     string
+	mt      protoreflect.MessageType // nil if the fetch failed
+	err     error
+	expires time.Time // zero if the entry does not expire
+}
+
+func (e *cacheEntry) expired(now time.Time) bool {
+	return !e.expires.IsZero() && !now.Before(e.expires)
+}
+
+func (r *Resolver) local() interface {
+	protoregistry.MessageTypeResolver
+	protoregistry.ExtensionTypeResolver
+} {
+	if r.Local == nil {
+		return protoregistry.GlobalTypes
+	}
+	return r.Local
+}
+
+// FindMessageByName looks up a message by its full name, either in Local
+// or among the types fetched so far.
+func (r *Resolver) FindMessageByName(message protoreflect.FullName) (protoreflect.MessageType, error) {
+	mt, err := r.local().FindMessageByName(message)
+	if err != protoregistry.NotFound {
+		return mt, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e := r.lookup(r.byName[message]); e != nil {
+		return e.mt, nil
+	}
+	return nil, protoregistry.NotFound
+}
+
+// FindMessageByURL looks up a message by a type URL. If it is not found in
+// Local or in the cache, its descriptors are fetched using the Fetcher.
+// Concurrent lookups of the same type URL share a single fetch.
+// The message name is derived from the type URL using the parser of Local,
+// if it is a [protoregistry.Types], so URLs rejected by its parser
+// are not fetched.
+func (r *Resolver) FindMessageByURL(url string) (protoreflect.MessageType, error) {
+	mt, err := r.local().FindMessageByURL(url)
+	if err != protoregistry.NotFound || r.Fetcher == nil {
+		return mt, err
+	}
+
+	r.mu.Lock()
+	if e := r.lookup(r.byURL[url]); e != nil {
+		r.mu.Unlock()
+		return e.mt, e.err
+	}
+	if c := r.inflight[url]; c != nil {
+		r.mu.Unlock()
+		<-c.done
+		return c.mt, c.err
+	}
+	c := &fetchCall{done: make(chan struct{})}
+	if r.inflight == nil {
+		r.inflight = make(map[string]*fetchCall)
+	}
+	r.inflight[url] = c
+	r.mu.Unlock()
+
+	c.mt, c.err = r.fetch(url)
+	e := &cacheEntry{url: url, mt: c.mt, err: c.err}
+	ttl := r.TTL
+	if c.err != nil {
+		if ttl = r.FailureTTL; ttl == 0 {
+			ttl = DefaultFailureTTL
+		}
+	}
+	if ttl > 0 {
+		e.expires = time.Now().Add(ttl)
+	}
+	r.mu.Lock()
+	delete(r.inflight, url)
+	if c.err == nil || ttl > 0 {
+		r.add(e)
+	}
+	r.mu.Unlock()
+	close(c.done)
+	return c.mt, c.err
+}
+
+// lookup returns the entry of elem, or nil if elem is nil or has expired.
+// It must be called with r.mu held.
+func (r *Resolver) lookup(elem *list.Element) *cacheEntry {
+	if elem == nil {
+		return nil
+	}
+	e := elem.Value.(*cacheEntry)
+	if e.expired(time.Now()) {
+		r.remove(elem)
+		return nil
+	}
+	r.lru.MoveToFront(elem)
+	return e
+}
+
+// add adds e to the cache, replacing any entry for the same URL and
+// evicting the least recently used entries if the cache is full.
+// It must be called with r.mu held.
+func (r *Resolver) add(e *cacheEntry) {
+	if r.byURL == nil {
+		r.byURL = make(map[string]*list.Element)
+		r.byName = make(map[protoreflect.FullName]*list.Element)
+	}
+	if elem := r.byURL[e.url]; elem != nil {
+		r.remove(elem)
+	}
+	elem := r.lru.PushFront(e)
+	r.byURL[e.url] = elem
+	if e.mt != nil {
+		r.byName[e.mt.Descriptor().FullName()] = elem
+	}
+	size := r.CacheSize
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+	for r.lru.Len() > size {
+		r.remove(r.lru.Back())
+	}
+}
+
+// remove removes elem from the cache.
+// It must be called with r.mu held.
+func (r *Resolver) remove(elem *list.Element) {
+	e := r.lru.Remove(elem).(*cacheEntry)
+	delete(r.byURL, e.url)
+	if e.mt != nil {
+		if name := e.mt.Descriptor().FullName(); r.byName[name] == elem {
+			delete(r.byName, name)
+		}
+	}
+}
+
+// fetch fetches the message type identified by url.
+func (r *Resolver) fetch(url string) (protoreflect.MessageType, error) {
+	name, err := r.parseTypeURL(url)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+	fds, err := r.Fetcher.Fetch(ctx, url)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to fetch type %v", url)
+	}
+	files, err := protodesc.NewFiles(fds)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid descriptors for type %v", url)
+	}
+	mt, err := dynamicpb.NewTypes(files).FindMessageByName(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "descriptors for type %v do not declare %v", url, name)
+	}
+	return mt, nil
+}
+
+// parseTypeURL returns the full name of the message identified by url,
+// as derived by the type URL parser of Local if it is a protoregistry.Types.
+func (r *Resolver) parseTypeURL(url string) (protoreflect.FullName, error) {
+	if types, ok := r.local().(*protoregistry.Types); ok {
+		return types.ParseTypeURL(url)
+	}
+	return (*protoregistry.Types)(nil).ParseTypeURL(url)
+}
+
+// FindExtensionByName looks up an extension field by its full name in Local.
+func (r *Resolver) FindExtensionByName(field protoreflect.FullName) (protoreflect.ExtensionType, error) {
+	return r.local().FindExtensionByName(field)
+}
+
+// FindExtensionByNumber looks up an extension field by the full name of
+// the extended message and the field number in Local.
+func (r *Resolver) FindExtensionByNumber(message protoreflect.FullName, field protoreflect.FieldNumber) (protoreflect.ExtensionType, error) {
+	return r.local().FindExtensionByNumber(message, field)
+}
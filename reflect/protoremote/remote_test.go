@@ -0,0 +1,315 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protoremote_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/reflect/protoremote"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func mustParseSet(s string) *descriptorpb.FileDescriptorSet {
+	fds := new(descriptorpb.FileDescriptorSet)
+	if err := prototext.Unmarshal([]byte(s), fds); err != nil {
+		panic(err)
+	}
+	return fds
+}
+
+var remoteFiles = mustParseSet(`
+	file: {
+		name: "remote.proto"
+		package: "remote"
+		syntax: "proto3"
+		message_type: [{
+			name: "Greeting"
+			field: [{name:"text" number:1 label:LABEL_OPTIONAL type:TYPE_STRING json_name:"text"}]
+		}]
+	}
+`)
+
+func newServer(t *testing.T, fetches *atomic.Int32) *httptest.Server {
+	b, err := proto.Marshal(remoteFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		switch r.URL.Path {
+		case "/types/remote.Greeting":
+			w.Write(b)
+		case "/redirect/remote.Greeting":
+			http.Redirect(w, r, "/other/remote.Greeting", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newFetcher(s *httptest.Server) protoremote.HTTPFetcher {
+	return protoremote.HTTPFetcher{
+		Client:          s.Client(),
+		AllowedPrefixes: []string{s.URL + "/types/", s.URL + "/redirect/"},
+	}
+}
+
+func TestResolver(t *testing.T) {
+	var fetches atomic.Int32
+	s := newServer(t, &fetches)
+	r := &protoremote.Resolver{Fetcher: newFetcher(s)}
+
+	url := s.URL + "/types/remote.Greeting"
+	in := `{"@type":"` + url + `","text":"hello"}`
+	m := new(anypb.Any)
+	if err := (protojson.UnmarshalOptions{Resolver: r}).Unmarshal([]byte(in), m); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+	got, err := m.UnmarshalNew()
+	if err == nil {
+		t.Errorf("Any.UnmarshalNew() without resolver = %v, want error", got)
+	}
+	got, err = anypb.UnmarshalNew(m, proto.UnmarshalOptions{Resolver: r})
+	if err != nil {
+		t.Fatalf("anypb.UnmarshalNew() error: %v", err)
+	}
+	if text := got.ProtoReflect().Get(got.ProtoReflect().Descriptor().Fields().ByName("text")).String(); text != "hello" {
+		t.Errorf("text = %q, want %q", text, "hello")
+	}
+	if _, err := r.FindMessageByName("remote.Greeting"); err != nil {
+		t.Errorf("FindMessageByName() error: %v", err)
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("fetched %d times, want 1", n)
+	}
+
+	// Locally known types are not fetched.
+	if _, err := r.FindMessageByURL(s.URL + "/types/google.protobuf.Any"); err != nil {
+		t.Errorf("FindMessageByURL(local type) error: %v", err)
+	}
+	if _, err := r.FindMessageByURL(s.URL + "/types/remote.Unknown"); err == nil {
+		t.Errorf("FindMessageByURL(unknown type) = nil error, want error")
+	}
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("fetched %d times, want 2", n)
+	}
+}
+
+func TestResolverTTL(t *testing.T) {
+	var fetches atomic.Int32
+	s := newServer(t, &fetches)
+	r := &protoremote.Resolver{
+		Fetcher: newFetcher(s),
+		TTL:     time.Nanosecond,
+	}
+	url := s.URL + "/types/remote.Greeting"
+	for i := 0; i < 2; i++ {
+		if _, err := r.FindMessageByURL(url); err != nil {
+			t.Fatalf("FindMessageByURL() error: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("fetched %d times, want 2", n)
+	}
+}
+
+func TestFetcherFunc(t *testing.T) {
+	r := &protoremote.Resolver{
+		Fetcher: protoremote.FetcherFunc(func(ctx context.Context, url string) (*descriptorpb.FileDescriptorSet, error) {
+			if !strings.HasPrefix(url, "types.example.com/") {
+				t.Errorf("Fetch(%q): unexpected URL", url)
+			}
+			return remoteFiles, nil
+		}),
+	}
+	mt, err := r.FindMessageByURL("types.example.com/remote.Greeting")
+	if err != nil {
+		t.Fatalf("FindMessageByURL() error: %v", err)
+	}
+	if got := mt.Descriptor().FullName(); got != "remote.Greeting" {
+		t.Errorf("FindMessageByURL() = %v, want remote.Greeting", got)
+	}
+}
+
+func TestHTTPFetcherRestrictions(t *testing.T) {
+	var fetches atomic.Int32
+	s := newServer(t, &fetches)
+	ctx := context.Background()
+	if _, err := newFetcher(s).Fetch(ctx, s.URL+"/types/remote.Greeting"); err != nil {
+		t.Fatalf("Fetch() error: %v", err)
+	}
+	fetches.Store(0)
+
+	for _, tt := range []struct {
+		desc    string
+		fetcher protoremote.HTTPFetcher
+		url     string
+	}{{
+		desc:    "no allowed prefixes",
+		fetcher: protoremote.HTTPFetcher{Client: s.Client()},
+		url:     s.URL + "/types/remote.Greeting",
+	}, {
+		desc:    "prefix not allowed",
+		fetcher: newFetcher(s),
+		url:     s.URL + "/other/remote.Greeting",
+	}, {
+		desc: "http not allowed",
+		fetcher: protoremote.HTTPFetcher{
+			AllowedPrefixes: []string{"http://types.example.com/"},
+		},
+		url: "http://types.example.com/remote.Greeting",
+	}, {
+		desc: "other scheme",
+		fetcher: protoremote.HTTPFetcher{
+			AllowedPrefixes: []string{""},
+			AllowHTTP:       true,
+		},
+		url: "file:///etc/passwd",
+	}} {
+		if _, err := tt.fetcher.Fetch(ctx, tt.url); err == nil {
+			t.Errorf("%s: Fetch(%q) succeeded, want error", tt.desc, tt.url)
+		}
+	}
+	if n := fetches.Load(); n != 0 {
+		t.Errorf("fetched %d times, want 0", n)
+	}
+
+	// Redirects are checked against the allowed prefixes.
+	if _, err := newFetcher(s).Fetch(ctx, s.URL+"/redirect/remote.Greeting"); err == nil {
+		t.Errorf("Fetch(redirect to URL not allowed) succeeded, want error")
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("fetched %d times, want 1", n)
+	}
+
+	f := newFetcher(s)
+	f.MaxSize = 10
+	if _, err := f.Fetch(ctx, s.URL+"/types/remote.Greeting"); err == nil {
+		t.Errorf("Fetch(MaxSize: 10) succeeded, want error")
+	}
+}
+
+func TestResolverCache(t *testing.T) {
+	var fetches atomic.Int32
+	s := newServer(t, &fetches)
+	r := &protoremote.Resolver{Fetcher: newFetcher(s), CacheSize: 1}
+
+	// Failures are cached.
+	unknown := s.URL + "/types/remote.Unknown"
+	for i := 0; i < 2; i++ {
+		if _, err := r.FindMessageByURL(unknown); err == nil {
+			t.Fatalf("FindMessageByURL(unknown type) = nil error, want error")
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("fetched %d times, want 1", n)
+	}
+
+	// The failure is evicted by the next entry.
+	if _, err := r.FindMessageByURL(s.URL + "/types/remote.Greeting"); err != nil {
+		t.Fatalf("FindMessageByURL() error: %v", err)
+	}
+	if _, err := r.FindMessageByURL(unknown); err == nil {
+		t.Fatalf("FindMessageByURL(unknown type) = nil error, want error")
+	}
+	if n := fetches.Load(); n != 3 {
+		t.Errorf("fetched %d times, want 3", n)
+	}
+	if _, err := r.FindMessageByName("remote.Greeting"); err == nil {
+		t.Errorf("FindMessageByName(evicted type) = nil error, want error")
+	}
+
+	// Failures are not cached if FailureTTL is negative.
+	r = &protoremote.Resolver{Fetcher: newFetcher(s), FailureTTL: -1}
+	fetches.Store(0)
+	for i := 0; i < 2; i++ {
+		if _, err := r.FindMessageByURL(unknown); err == nil {
+			t.Fatalf("FindMessageByURL(unknown type) = nil error, want error")
+		}
+	}
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("fetched %d times, want 2", n)
+	}
+}
+
+func TestResolverConcurrentFetch(t *testing.T) {
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	r := &protoremote.Resolver{
+		Fetcher: protoremote.FetcherFunc(func(ctx context.Context, url string) (*descriptorpb.FileDescriptorSet, error) {
+			fetches.Add(1)
+			<-release
+			return remoteFiles, nil
+		}),
+	}
+
+	const n = 10
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := r.FindMessageByURL("types.example.com/remote.Greeting"); err != nil {
+				t.Errorf("FindMessageByURL() error: %v", err)
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("fetched %d times, want 1", n)
+	}
+}
+
+func TestResolverTypeURLParser(t *testing.T) {
+	var fetches atomic.Int32
+	local := new(protoregistry.Types)
+	local.SetTypeURLParser(func(url string) (protoreflect.FullName, error) {
+		name, ok := strings.CutPrefix(url, "custom:")
+		if !ok {
+			return "", errors.New("unsupported type URL")
+		}
+		return protoreflect.FullName(name), nil
+	})
+	r := &protoremote.Resolver{
+		Local: local,
+		Fetcher: protoremote.FetcherFunc(func(ctx context.Context, url string) (*descriptorpb.FileDescriptorSet, error) {
+			fetches.Add(1)
+			return remoteFiles, nil
+		}),
+	}
+
+	mt, err := r.FindMessageByURL("custom:remote.Greeting")
+	if err != nil {
+		t.Fatalf("FindMessageByURL() error: %v", err)
+	}
+	if got := mt.Descriptor().FullName(); got != "remote.Greeting" {
+		t.Errorf("FindMessageByURL() = %v, want remote.Greeting", got)
+	}
+	if _, err := r.FindMessageByURL("types.example.com/remote.Greeting"); err == nil {
+		t.Errorf("FindMessageByURL(rejected URL) = nil error, want error")
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("fetched %d times, want 1", n)
+	}
+}
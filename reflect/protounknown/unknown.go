@@ -0,0 +1,147 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package protounknown provides functionality to inspect and modify the
+// unknown fields of a message.
+//
+// Unknown fields are fields that were parsed from the wire format,
+// but are not declared in the message descriptor. They are preserved as
+// raw bytes and are written out again when the message is marshaled.
+// This package identifies them by field number and wire type, so that
+// programs such as proxies can inspect or strip specific pass-through
+// fields without knowledge of their schema:
+//
+//	// Remove field 1000 before forwarding the message.
+//	if err := protounknown.Remove(m, 1000); err != nil {
+//		return err
+//	}
+//
+// The functions in this package only operate on the unknown fields of the
+// given message itself, not on those of messages nested within it.
+package protounknown
+
+import (
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Field is a single unknown field.
+type Field struct {
+	// Number is the field number.
+	Number protowire.Number
+
+	// Type is the wire type of the field.
+	Type protowire.Type
+
+	// Raw is the complete encoding of the field, including its tag.
+	Raw protoreflect.RawFields
+
+	// Value is the encoding of the field value, which follows the tag in Raw.
+	// It may be parsed with the protowire function for the wire type,
+	// such as protowire.ConsumeVarint for protowire.VarintType.
+	// For a group, it includes the terminating END_GROUP tag.
+	Value []byte
+}
+
+// Parse parses b into a sequence of unknown fields.
+// It reports an error if b is not a valid sequence of fields.
+func Parse(b protoreflect.RawFields) ([]Field, error) {
+	var fields []Field
+	err := rangeFields(b, func(f Field) bool {
+		fields = append(fields, f)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// Marshal concatenates the encodings of the given fields.
+func Marshal(fields []Field) protoreflect.RawFields {
+	var b protoreflect.RawFields
+	for _, f := range fields {
+		b = append(b, f.Raw...)
+	}
+	return b
+}
+
+// Range calls f for each unknown field of m in the order they are stored.
+// Iteration stops if f returns false.
+// It reports an error if the unknown fields are malformed,
+// after calling f for the fields preceding the malformed data.
+func Range(m proto.Message, f func(Field) bool) error {
+	if m == nil {
+		return nil
+	}
+	return rangeFields(m.ProtoReflect().GetUnknown(), f)
+}
+
+// Filter removes all unknown fields of m for which keep returns false.
+// It reports an error and leaves m unmodified if the unknown fields
+// are malformed.
+func Filter(m proto.Message, keep func(Field) bool) error {
+	if m == nil {
+		return nil
+	}
+	mr := m.ProtoReflect()
+	unknown := mr.GetUnknown()
+	fields, err := Parse(unknown)
+	if err != nil {
+		return err
+	}
+	var b protoreflect.RawFields
+	removed := false
+	for _, f := range fields {
+		if keep(f) {
+			b = append(b, f.Raw...)
+		} else {
+			removed = true
+		}
+	}
+	if removed {
+		mr.SetUnknown(b)
+	}
+	return nil
+}
+
+// Remove removes all unknown fields of m with any of the given field numbers.
+// It reports an error and leaves m unmodified if the unknown fields
+// are malformed.
+func Remove(m proto.Message, nums ...protowire.Number) error {
+	return Filter(m, func(f Field) bool {
+		for _, num := range nums {
+			if f.Number == num {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// rangeFields calls f for each field in b.
+func rangeFields(b protoreflect.RawFields, f func(Field) bool) error {
+	for len(b) > 0 {
+		num, typ, tagLen := protowire.ConsumeTag(b)
+		if tagLen < 0 {
+			return protowire.ParseError(tagLen)
+		}
+		valLen := protowire.ConsumeFieldValue(num, typ, b[tagLen:])
+		if valLen < 0 {
+			return protowire.ParseError(valLen)
+		}
+		n := tagLen + valLen
+		if !f(Field{
+			Number: num,
+			Type:   typ,
+			Raw:    b[:n:n],
+			Value:  b[tagLen:n:n],
+		}) {
+			return nil
+		}
+		b = b[n:]
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protounknown_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protounknown"
+	"google.golang.org/protobuf/testing/protopack"
+
+	testpb "google.golang.org/protobuf/internal/testprotos/test"
+)
+
+var (
+	varintField = protopack.Message{protopack.Tag{Number: 1000, Type: protopack.VarintType}, protopack.Varint(1)}
+	bytesField  = protopack.Message{protopack.Tag{Number: 1001, Type: protopack.BytesType}, protopack.String("hello")}
+	groupField  = protopack.Message{
+		protopack.Tag{Number: 1002, Type: protopack.StartGroupType},
+		protopack.Tag{Number: 1, Type: protopack.Fixed32Type}, protopack.Uint32(2),
+		protopack.Tag{Number: 1002, Type: protopack.EndGroupType},
+	}
+	varintField2 = protopack.Message{protopack.Tag{Number: 1000, Type: protopack.VarintType}, protopack.Varint(3)}
+)
+
+func newMessage(fields ...protopack.Message) *testpb.TestAllTypes {
+	m := &testpb.TestAllTypes{OptionalInt32: proto.Int32(1)}
+	var b []byte
+	for _, f := range fields {
+		b = append(b, f.Marshal()...)
+	}
+	m.ProtoReflect().SetUnknown(b)
+	return m
+}
+
+func TestRange(t *testing.T) {
+	m := newMessage(varintField, bytesField, groupField, varintField2)
+
+	type field struct {
+		Number protowire.Number
+		Type   protowire.Type
+		Raw    []byte
+		Value  []byte
+	}
+	var got []field
+	err := protounknown.Range(m, func(f protounknown.Field) bool {
+		got = append(got, field{f.Number, f.Type, f.Raw, f.Value})
+		return true
+	})
+	if err != nil {
+		t.Fatalf("Range() error: %v", err)
+	}
+	groupRaw := groupField.Marshal()
+	want := []field{
+		{1000, protowire.VarintType, varintField.Marshal(), []byte{1}},
+		{1001, protowire.BytesType, bytesField.Marshal(), append([]byte{5}, "hello"...)},
+		{1002, protowire.StartGroupType, groupRaw, groupRaw[2:]},
+		{1000, protowire.VarintType, varintField2.Marshal(), []byte{3}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Range() mismatch (-want +got):\n%s", diff)
+	}
+
+	var n int
+	protounknown.Range(m, func(protounknown.Field) bool {
+		n++
+		return false
+	})
+	if n != 1 {
+		t.Errorf("Range() called f %d times after it returned false, want 1", n)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	m := newMessage(varintField, bytesField, groupField, varintField2)
+	if err := protounknown.Remove(m, 1000, 1002); err != nil {
+		t.Fatalf("Remove() error: %v", err)
+	}
+	if got, want := []byte(m.ProtoReflect().GetUnknown()), bytesField.Marshal(); !bytes.Equal(got, want) {
+		t.Errorf("unknown fields after Remove() = %x, want %x", got, want)
+	}
+	if got := m.GetOptionalInt32(); got != 1 {
+		t.Errorf("known field after Remove() = %v, want 1", got)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	m := newMessage(varintField, bytesField, groupField, varintField2)
+	err := protounknown.Filter(m, func(f protounknown.Field) bool {
+		return f.Type == protowire.VarintType
+	})
+	if err != nil {
+		t.Fatalf("Filter() error: %v", err)
+	}
+	want := append(varintField.Marshal(), varintField2.Marshal()...)
+	if got := []byte(m.ProtoReflect().GetUnknown()); !bytes.Equal(got, want) {
+		t.Errorf("unknown fields after Filter() = %x, want %x", got, want)
+	}
+}
+
+func TestMalformed(t *testing.T) {
+	malformed := append(varintField.Marshal(), protopack.Message{
+		protopack.Tag{Number: 1001, Type: protopack.BytesType}, protopack.Varint(10),
+	}.Marshal()...)
+	m := &testpb.TestAllTypes{}
+	m.ProtoReflect().SetUnknown(malformed)
+
+	if err := protounknown.Remove(m, 1000); err == nil {
+		t.Errorf("Remove() with malformed unknown fields succeeded, want error")
+	}
+	if got := []byte(m.ProtoReflect().GetUnknown()); !bytes.Equal(got, malformed) {
+		t.Errorf("Remove() with malformed unknown fields modified the message")
+	}
+	var n int
+	err := protounknown.Range(m, func(protounknown.Field) bool {
+		n++
+		return true
+	})
+	if err == nil || n != 1 {
+		t.Errorf("Range() with malformed unknown fields = %v after %d fields, want error after 1 field", err, n)
+	}
+	if _, err := protounknown.Parse(malformed); err == nil {
+		t.Errorf("Parse() with malformed unknown fields succeeded, want error")
+	}
+}
+
+func TestParseMarshal(t *testing.T) {
+	b := newMessage(varintField, bytesField, groupField).ProtoReflect().GetUnknown()
+	fields, err := protounknown.Parse(b)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if len(fields) != 3 {
+		t.Fatalf("Parse() returned %d fields, want 3", len(fields))
+	}
+	fields[0], fields[2] = fields[2], fields[0]
+	got := protounknown.Marshal(fields)
+	want := append(append(groupField.Marshal(), bytesField.Marshal()...), varintField.Marshal()...)
+	if !bytes.Equal(got, want) {
+		t.Errorf("Marshal() = %x, want %x", got, want)
+	}
+}
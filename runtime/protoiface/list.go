@@ -0,0 +1,15 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protoiface
+
+// ListSlicePointer may be implemented by a [protoreflect.List] which is
+// backed by a Go slice whose elements have the Go type of a [protoreflect.Value]
+// of the list's kind, such as the repeated scalar fields of generated messages.
+// It permits [protoreflect.ListSlice] to return the elements without copying them.
+type ListSlicePointer interface {
+	// ProtoSlicePointer returns a pointer to the backing slice,
+	// such as a *[]int32. The slice is not modified through the pointer.
+	ProtoSlicePointer() any
+}
@@ -61,6 +61,10 @@ type SizeInput = struct {
 
 	Message protoreflect.Message
 	Flags   MarshalInputFlags
+
+	// Concurrency, if greater than one, is the number of goroutines
+	// which may be used. It is set by proto.MarshalOptions.Concurrency.
+	Concurrency int
 }
 
 // SizeOutput is output from the Size method.
@@ -77,6 +81,10 @@ type MarshalInput = struct {
 	Message protoreflect.Message
 	Buf     []byte // output is appended to this buffer
 	Flags   MarshalInputFlags
+
+	// Concurrency, if greater than one, is the number of goroutines
+	// which may be used. It is set by proto.MarshalOptions.Concurrency.
+	Concurrency int
 }
 
 // MarshalOutput is output from the Marshal method.
@@ -200,3 +208,22 @@ type EqualOutput = struct {
 
 	Equal bool
 }
+
+// SizeCacher is an optional interface for messages that do not provide a
+// fast-path Size method, but are able to store the size of their wire-format
+// encoding. Sizes computed by the proto package are recorded with
+// ProtoSetCachedSize and are returned by ProtoCachedSize when the
+// MarshalUseCachedSize flag is set.
+//
+// Implementations must be safe for concurrent use, since the size of a
+// message may be computed concurrently with other read-only operations.
+// An implementation may discard the cached size at any time, and should
+// do so whenever the message itself is modified.
+type SizeCacher interface {
+	// ProtoCachedSize returns the most recently recorded size,
+	// and reports whether one is available.
+	ProtoCachedSize() (size int, ok bool)
+
+	// ProtoSetCachedSize records the size of the message.
+	ProtoSetCachedSize(size int)
+}
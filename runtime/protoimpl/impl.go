@@ -26,19 +26,30 @@ type (
 	DescBuilder = filedesc.Builder
 	TypeBuilder = filetype.Builder
 
+	// Type used by generated code that registers its descriptors and types
+	// explicitly instead of in init functions.
+	DeferredRegistry = filetype.DeferredRegistry
+
 	// Types used by generated code to implement EnumType, MessageType, and ExtensionType.
 	EnumInfo      = impl.EnumInfo
 	MessageInfo   = impl.MessageInfo
 	ExtensionInfo = impl.ExtensionInfo
 
+	// Interface of the custom storage for the unknown fields of messages.
+	// It is not used by code generated by protoc-gen-go.
+	UnknownFieldStorage = impl.UnknownFieldStorage
+
 	// Types embedded in generated messages.
 	MessageState     = impl.MessageState
 	SizeCache        = impl.SizeCache
-	WeakFields       = impl.WeakFields
 	UnknownFields    = impl.UnknownFields
 	ExtensionFields  = impl.ExtensionFields
 	ExtensionFieldV1 = impl.ExtensionField
 
+	// Deprecated: support for weak fields has been removed.
+	// WeakFields remains only so that older generated code continues to build.
+	WeakFields = impl.WeakFields
+
 	Pointer = impl.Pointer
 
 	LazyUnmarshalInfo  = *protolazy.XXX_lazyUnmarshalInfo
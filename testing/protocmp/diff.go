@@ -0,0 +1,209 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protocmp
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// DiffOp is the kind of change described by a [FieldDiff].
+type DiffOp int
+
+const (
+	// FieldAdded indicates that a field or element is only present in y.
+	FieldAdded DiffOp = iota + 1
+	// FieldRemoved indicates that a field or element is only present in x.
+	FieldRemoved
+	// FieldModified indicates that a field or element has different values.
+	FieldModified
+)
+
+// String returns "+" for FieldAdded, "-" for FieldRemoved,
+// and "~" for FieldModified.
+func (op DiffOp) String() string {
+	switch op {
+	case FieldAdded:
+		return "+"
+	case FieldRemoved:
+		return "-"
+	case FieldModified:
+		return "~"
+	default:
+		return "DiffOp(" + strconv.Itoa(int(op)) + ")"
+	}
+}
+
+// FieldDiff is a difference between two messages in a single field,
+// or in a single element of a repeated or map field.
+type FieldDiff struct {
+	Op DiffOp
+
+	// Path is the path to the field from the root of the compared values,
+	// such as "a.b[2]" for the third element of the repeated field b
+	// within the message field a, or `m["k"]` for the entry of
+	// the map field m with the key "k".
+	// Extension fields are named by their full name in brackets,
+	// and unknown fields by their field number.
+	Path string
+
+	// X and Y are the values in x and y, formatted according to the
+	// text format. X is empty if Op is FieldAdded, and Y is empty
+	// if Op is FieldRemoved.
+	X, Y string
+}
+
+// DiffReporter is a [cmp.Reporter] which records the differences between
+// messages as a list of changes to individual fields.
+// It must be used in conjunction with [Transform].
+//
+// For example:
+//
+//	var r protocmp.DiffReporter
+//	if !cmp.Equal(want, got, protocmp.Transform(), cmp.Reporter(&r)) {
+//		t.Errorf("mismatch (-want +got):\n%v", &r)
+//	}
+type DiffReporter struct {
+	path  cmp.Path
+	diffs []FieldDiff
+}
+
+// PushStep is called by cmp when descending into a value.
+func (r *DiffReporter) PushStep(ps cmp.PathStep) {
+	r.path = append(r.path, ps)
+}
+
+// Report is called by cmp with the result of comparing the current value.
+func (r *DiffReporter) Report(rs cmp.Result) {
+	if rs.Equal() {
+		return
+	}
+	vx, vy := r.path.Last().Values()
+	d := FieldDiff{Path: formatPath(r.path)}
+	switch {
+	case !vx.IsValid():
+		d.Op = FieldAdded
+		d.Y = formatValue(vy)
+	case !vy.IsValid():
+		d.Op = FieldRemoved
+		d.X = formatValue(vx)
+	default:
+		d.Op = FieldModified
+		d.X = formatValue(vx)
+		d.Y = formatValue(vy)
+	}
+	r.diffs = append(r.diffs, d)
+}
+
+// PopStep is called by cmp when ascending from a value.
+func (r *DiffReporter) PopStep() {
+	r.path = r.path[:len(r.path)-1]
+}
+
+// Diffs returns the differences recorded so far,
+// in the order in which they were found.
+func (r *DiffReporter) Diffs() []FieldDiff {
+	return r.diffs
+}
+
+// String formats the differences as one line per removed or added value,
+// prefixed with "-" or "+", respectively; for example, `+ m["k"]: "v"`.
+// A modified value is formatted as a removal followed by an addition.
+func (r *DiffReporter) String() string {
+	var b strings.Builder
+	for _, d := range r.diffs {
+		if d.Op != FieldAdded {
+			fmt.Fprintf(&b, "- %s: %s\n", d.Path, d.X)
+		}
+		if d.Op != FieldRemoved {
+			fmt.Fprintf(&b, "+ %s: %s\n", d.Path, d.Y)
+		}
+	}
+	return b.String()
+}
+
+func formatPath(p cmp.Path) string {
+	var b strings.Builder
+	for i, ps := range p {
+		switch ps := ps.(type) {
+		case cmp.MapIndex:
+			if i > 0 && p[i-1].Type() == messageReflectType {
+				if b.Len() > 0 {
+					b.WriteByte('.')
+				}
+				b.WriteString(ps.Key().String())
+			} else {
+				b.WriteString("[" + formatValue(ps.Key()) + "]")
+			}
+		case cmp.SliceIndex:
+			ix, iy := ps.SplitKeys()
+			if ix < 0 {
+				ix = iy
+			}
+			b.WriteString("[" + strconv.Itoa(ix) + "]")
+		case cmp.StructField:
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(ps.Name())
+		}
+	}
+	return b.String()
+}
+
+var deterministicText = prototext.MarshalOptions{Deterministic: true}
+
+func formatValue(v reflect.Value) string {
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "<nil>"
+		}
+		v = v.Elem()
+	}
+	switch x := v.Interface().(type) {
+	case Message:
+		if x == nil {
+			return "<nil>"
+		}
+		b, err := deterministicText.Marshal(x)
+		if err != nil {
+			return x.String()
+		}
+		return "{" + string(b) + "}"
+	case Enum, messageMeta:
+		return fmt.Sprint(x)
+	case protoreflect.RawFields:
+		return strconv.Quote(string(x))
+	case string:
+		return strconv.Quote(x)
+	case []byte:
+		return strconv.Quote(string(x))
+	}
+	switch v.Kind() {
+	case reflect.Slice:
+		s := make([]string, v.Len())
+		for i := range s {
+			s[i] = formatValue(v.Index(i))
+		}
+		return "[" + strings.Join(s, ", ") + "]"
+	case reflect.Map:
+		s := make([]string, 0, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			s = append(s, formatValue(iter.Key())+": "+formatValue(iter.Value()))
+		}
+		sort.Strings(s)
+		return "{" + strings.Join(s, ", ") + "}"
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protocmp
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"google.golang.org/protobuf/proto"
+
+	testpb "google.golang.org/protobuf/internal/testprotos/test"
+)
+
+func TestDiffReporter(t *testing.T) {
+	x := &testpb.TestAllTypes{
+		OptionalInt32:         proto.Int32(1),
+		OptionalString:        proto.String("a"),
+		OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{A: proto.Int32(1)},
+		RepeatedInt32:         []int32{1, 2},
+		MapStringString:       map[string]string{"k1": "v1", "k2": "v2"},
+		OptionalNestedEnum:    testpb.TestAllTypes_FOO.Enum(),
+	}
+	y := &testpb.TestAllTypes{
+		OptionalInt32:         proto.Int32(1),
+		OptionalString:        proto.String("b"),
+		OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{A: proto.Int32(2)},
+		RepeatedInt32:         []int32{1, 2, 3},
+		MapStringString:       map[string]string{"k1": "v1", "k3": "v3"},
+		OptionalNestedEnum:    testpb.TestAllTypes_BAR.Enum(),
+		OptionalBool:          proto.Bool(true),
+		RepeatedNestedMessage: []*testpb.TestAllTypes_NestedMessage{{A: proto.Int32(3)}},
+	}
+
+	var r DiffReporter
+	if cmp.Equal(x, y, Transform(), cmp.Reporter(&r)) {
+		t.Fatal("cmp.Equal() = true, want false")
+	}
+	want := []FieldDiff{
+		{Op: FieldModified, Path: "optional_nested_message.a", X: "1", Y: "2"},
+		{Op: FieldAdded, Path: "optional_bool", Y: "true"},
+		{Op: FieldModified, Path: "optional_string", X: `"a"`, Y: `"b"`},
+		{Op: FieldModified, Path: "optional_nested_enum", X: "FOO", Y: "BAR"},
+		{Op: FieldAdded, Path: "repeated_int32[2]", Y: "3"},
+		{Op: FieldAdded, Path: "repeated_nested_message", Y: "[{a:3}]"},
+		{Op: FieldRemoved, Path: `map_string_string["k2"]`, X: `"v2"`},
+		{Op: FieldAdded, Path: `map_string_string["k3"]`, Y: `"v3"`},
+	}
+	sortDiffs := cmpopts.SortSlices(func(a, b FieldDiff) bool { return a.Path < b.Path })
+	if diff := cmp.Diff(want, r.Diffs(), sortDiffs); diff != "" {
+		t.Errorf("Diffs() mismatch (-want +got):\n%s", diff)
+	}
+
+	var r2 DiffReporter
+	cmp.Equal(
+		&testpb.TestAllTypes{OptionalInt32: proto.Int32(1)},
+		&testpb.TestAllTypes{OptionalInt32: proto.Int32(2), OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{A: proto.Int32(3)}},
+		Transform(), cmp.Reporter(&r2))
+	wantString := "- optional_int32: 1\n+ optional_int32: 2\n+ optional_nested_message: {a:3}\n"
+	if got := r2.String(); got != wantString {
+		t.Errorf("String() = %q, want %q", got, wantString)
+	}
+}
@@ -16,11 +16,13 @@ import (
 
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
 )
 
 var (
 	enumReflectType    = reflect.TypeOf(Enum{})
 	messageReflectType = reflect.TypeOf(Message{})
+	messageIfaceType   = reflect.TypeOf((*protoreflect.Message)(nil)).Elem()
 )
 
 // FilterEnum filters opt to only be applicable on a standalone [Enum],
@@ -57,6 +59,8 @@ func FilterMessage(message proto.Message, opt cmp.Option) cmp.Option {
 
 // FilterField filters opt to only be applicable on the specified field
 // in the message. It panics if a field of the given name does not exist.
+// An extension field may be specified by its full name,
+// as described for [IgnoreFields].
 //
 // The Go type of the last path step may be an:
 //   - T for singular fields
@@ -133,6 +137,10 @@ func IgnoreMessages(messages ...proto.Message) cmp.Option {
 // It is equivalent to [FilterField](message, name, [cmp.Ignore]()) for each field
 // in the message.
 //
+// An extension field may be specified by its full name,
+// optionally enclosed in brackets (e.g., "[pkg.ext]").
+// The extension must be registered in [protoregistry.GlobalTypes].
+//
 // This must be used in conjunction with [Transform].
 func IgnoreFields(message proto.Message, names ...protoreflect.Name) cmp.Option {
 	var ds []protoreflect.Descriptor
@@ -165,7 +173,56 @@ func IgnoreDescriptors(descs ...protoreflect.Descriptor) cmp.Option {
 	return cmp.FilterPath(newNameFilters(descs...).Filter, cmp.Ignore())
 }
 
+// IgnoreMapKeys ignores the entries with the specified keys in the
+// specified map field of the message. It panics if the field does not exist
+// or is not a map field, or if a key is not of the Go type for the
+// map key kind (i.e., bool, int32, int64, uint32, uint64, or string).
+//
+// This must be used in conjunction with [Transform].
+func IgnoreMapKeys(message proto.Message, name protoreflect.Name, keys ...any) cmp.Option {
+	md := message.ProtoReflect().Descriptor()
+	fd := mustFindFieldDescriptor(md, name)
+	if !fd.IsMap() {
+		panic(fmt.Sprintf("message field %q is not a map field", fd.FullName()))
+	}
+	kt := protoKindToGoType(fd.MapKey().Kind())
+	keySet := make(map[any]bool)
+	for _, k := range keys {
+		if reflect.TypeOf(k) != kt {
+			panic(fmt.Sprintf("invalid key type for map field %q: got %T, want %v", fd.FullName(), k, kt))
+		}
+		keySet[k] = true
+	}
+	return cmp.FilterPath(func(p cmp.Path) bool {
+		// Filter for entries of the map field, where the path is of the form:
+		//	Message → MapIndex(field) → TypeAssertion(map[K]V) → MapIndex(key)
+		mk, ok := p.Index(-1).(cmp.MapIndex)
+		if !ok || mk.Key().Type() != kt || !keySet[mk.Key().Interface()] {
+			return false
+		}
+		if _, ok := p.Index(-2).(cmp.TypeAssertion); !ok {
+			return false
+		}
+		mf, ok := p.Index(-3).(cmp.MapIndex)
+		if !ok || mf.Key().String() != fd.TextName() {
+			return false
+		}
+		ps := p.Index(-4)
+		if ps.Type() != messageReflectType {
+			return false
+		}
+		vx, vy := ps.Values()
+		return vx.Interface().(Message).Descriptor() == md && vy.Interface().(Message).Descriptor() == md
+	}, cmp.Ignore())
+}
+
 func mustFindFieldDescriptor(md protoreflect.MessageDescriptor, s protoreflect.Name) protoreflect.FieldDescriptor {
+	if xd, ok := findExtensionDescriptor(md, s); ok {
+		if xd == nil {
+			panic(fmt.Sprintf("message %q has no extension %q", md.FullName(), s))
+		}
+		return xd
+	}
 	d := findDescriptor(md, s)
 	if fd, ok := d.(protoreflect.FieldDescriptor); ok && fd.TextName() == string(s) {
 		return fd
@@ -197,6 +254,29 @@ func mustFindOneofDescriptor(md protoreflect.MessageDescriptor, s protoreflect.N
 	panic(fmt.Sprintf("message %q has no oneof %q%s", md.FullName(), s, suggestion))
 }
 
+// findExtensionDescriptor resolves s as the name of an extension field of md
+// in protoregistry.GlobalTypes, if s is a full name (e.g., "pkg.ext") or
+// a full name enclosed in brackets as in the text format (e.g., "[pkg.ext]").
+// It reports false if s is not of either form.
+func findExtensionDescriptor(md protoreflect.MessageDescriptor, s protoreflect.Name) (protoreflect.FieldDescriptor, bool) {
+	name := string(s)
+	switch {
+	case strings.HasPrefix(name, "[") && strings.HasSuffix(name, "]"):
+		name = name[1 : len(name)-1]
+	case !strings.Contains(name, "."):
+		return nil, false
+	}
+	xt, err := protoregistry.GlobalTypes.FindExtensionByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, true
+	}
+	xd := xt.TypeDescriptor()
+	if xd.ContainingMessage().FullName() != md.FullName() {
+		return nil, true
+	}
+	return xd, true
+}
+
 func findDescriptor(md protoreflect.MessageDescriptor, s protoreflect.Name) protoreflect.Descriptor {
 	// Exact match.
 	if fd := md.Fields().ByTextName(string(s)); fd != nil {
@@ -489,6 +569,8 @@ func isEmptyMessage(v reflect.Value) bool {
 }
 
 // IgnoreUnknown ignores unknown fields in all messages.
+// This includes nested messages, and unknown fields that hold the encoded
+// value of an extension that is not known to the message.
 //
 // This must be used in conjunction with [Transform].
 func IgnoreUnknown() cmp.Option {
@@ -524,6 +606,11 @@ func IgnoreUnknown() cmp.Option {
 // handled by this option. To sort Go slices that are not repeated fields,
 // consider using [github.com/google/go-cmp/cmp/cmpopts.SortSlices] instead.
 //
+// The element type T may also be [protoreflect.Message], in which case
+// the less function is used to sort repeated fields of any message type.
+// To limit sorting to particular fields or message types,
+// use it with [FilterField] or [FilterMessage].
+//
 // The sorting of messages does not take into account ignored fields or oneofs
 // as a result of [IgnoreFields] or [IgnoreOneofs].
 //
@@ -537,6 +624,13 @@ func SortRepeated(lessFunc any) cmp.Option {
 	var opt cmp.Option
 	var sliceType reflect.Type
 	switch vf := reflect.ValueOf(lessFunc); {
+	case t == messageIfaceType:
+		less := lessFunc.(func(x, y protoreflect.Message) bool)
+		lessFunc = func(x, y Message) bool {
+			return less(x.ProtoReflect(), y.ProtoReflect())
+		}
+		opt = cmpopts.SortSlices(lessFunc)
+		sliceType = reflect.SliceOf(messageReflectType)
 	case t.Implements(enumV2Type):
 		et := reflect.Zero(t).Interface().(protoreflect.Enum).Type()
 		lessFunc = func(x, y Enum) bool {
@@ -672,6 +766,81 @@ func SortRepeatedFields(message proto.Message, names ...protoreflect.Name) cmp.O
 	return opts
 }
 
+// SortRepeatedFieldByKey sorts the specified repeated message field
+// by the values of the given key fields within each element,
+// comparing the first key field, then the second to break ties, and so on.
+// Sorting a repeated field is useful for treating the list as a multiset
+// (i.e., a set where each value can appear multiple times).
+// It panics if the field does not exist or is not a repeated message field,
+// or if a key field does not exist or is not a singular scalar or enum field.
+//
+// Key values are sorted according to the ordering used by
+// [SortRepeatedFields]. An unpopulated key field has its default value.
+//
+// For example, to sort the repeated "users" field by the "id" field
+// of each user:
+//
+//	SortRepeatedFieldByKey(new(foopb.Directory), "users", "id")
+//
+// This must be used in conjunction with [Transform].
+func SortRepeatedFieldByKey(message proto.Message, name protoreflect.Name, keys ...protoreflect.Name) cmp.Option {
+	fd := mustFindFieldDescriptor(message.ProtoReflect().Descriptor(), name)
+	if !fd.IsList() || fd.Message() == nil {
+		panic(fmt.Sprintf("message field %q is not a repeated message field", fd.FullName()))
+	}
+	if len(keys) == 0 {
+		panic("no key fields specified")
+	}
+	var keyFields []protoreflect.FieldDescriptor
+	for _, key := range keys {
+		kd := mustFindFieldDescriptor(fd.Message(), key)
+		if kd.Cardinality() == protoreflect.Repeated || kd.Message() != nil {
+			panic(fmt.Sprintf("message field %q is not a singular scalar field", kd.FullName()))
+		}
+		keyFields = append(keyFields, kd)
+	}
+
+	lessFunc := func(x, y Message) bool {
+		mx, my := x.ProtoReflect(), y.ProtoReflect()
+		for _, kd := range keyFields {
+			vx, vy := mx.Get(kd), my.Get(kd)
+			switch {
+			case lessValue(kd, vx, vy):
+				return true
+			case lessValue(kd, vy, vx):
+				return false
+			}
+		}
+		return false
+	}
+	return FilterDescriptor(fd, cmpopts.SortSlices(lessFunc))
+}
+
+// lessValue reports whether x is less than y, where both are values
+// of the singular scalar or enum field fd.
+func lessValue(fd protoreflect.FieldDescriptor, x, y protoreflect.Value) bool {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return !x.Bool() && y.Bool()
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return x.Int() < y.Int()
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return x.Uint() < y.Uint()
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return lessF64(x.Float(), y.Float())
+	case protoreflect.StringKind:
+		return x.String() < y.String()
+	case protoreflect.BytesKind:
+		return bytes.Compare(x.Bytes(), y.Bytes()) < 0
+	case protoreflect.EnumKind:
+		return x.Enum() < y.Enum()
+	default:
+		panic(fmt.Sprintf("invalid kind: %v", fd.Kind()))
+	}
+}
+
 func lessF32(x, y float32) bool {
 	// Bit-wise implementation of IEEE-754, section 5.10.
 	xi := int32(math.Float32bits(x))
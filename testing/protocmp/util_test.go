@@ -236,6 +236,24 @@ func TestEqual(t *testing.T) {
 		y:    apply(dynamicpb.NewMessage(allTypesDesc), setField{6, int64(5)}),
 		opts: cmp.Options{Transform(), IgnoreUnknown()},
 		want: true,
+	}, {
+		x: &testpb.TestAllTypes{
+			OptionalNestedMessage: apply(&testpb.TestAllTypes_NestedMessage{A: proto.Int32(1)}, setUnknown{raw}).(*testpb.TestAllTypes_NestedMessage),
+		},
+		y: &testpb.TestAllTypes{
+			OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{A: proto.Int32(1)},
+		},
+		opts: cmp.Options{Transform()},
+		want: false,
+	}, {
+		x: &testpb.TestAllTypes{
+			OptionalNestedMessage: apply(&testpb.TestAllTypes_NestedMessage{A: proto.Int32(1)}, setUnknown{raw}).(*testpb.TestAllTypes_NestedMessage),
+		},
+		y: &testpb.TestAllTypes{
+			OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{A: proto.Int32(1)},
+		},
+		opts: cmp.Options{Transform(), IgnoreUnknown()},
+		want: true,
 	}}...)
 
 	// Test IgnoreDefaultScalars.
@@ -594,6 +612,77 @@ func TestEqual(t *testing.T) {
 		opts: cmp.Options{Transform(),
 			IgnoreDescriptors(testpb.E_OptionalInt32.TypeDescriptor())},
 		want: false,
+	}, {
+		x: apply(new(testpb.TestAllExtensions),
+			setExtension{testpb.E_OptionalString, "hello"}),
+		y: apply(new(testpb.TestAllExtensions),
+			setExtension{testpb.E_OptionalString, "goodbye"}),
+		opts: cmp.Options{Transform(),
+			IgnoreFields(new(testpb.TestAllExtensions), "[goproto.proto.test.optional_string]")},
+		want: true,
+	}, {
+		x: apply(new(testpb.TestAllExtensions),
+			setExtension{testpb.E_OptionalString, "hello"}),
+		y: new(testpb.TestAllExtensions),
+		opts: cmp.Options{Transform(),
+			IgnoreFields(new(testpb.TestAllExtensions), "goproto.proto.test.optional_string")},
+		want: true,
+	}, {
+		x: apply(new(testpb.TestAllExtensions),
+			setExtension{testpb.E_OptionalString, "hello"}),
+		y: apply(new(testpb.TestAllExtensions),
+			setExtension{testpb.E_OptionalString, "goodbye"}),
+		opts: cmp.Options{Transform(),
+			IgnoreFields(new(testpb.TestAllExtensions), "[goproto.proto.test.optional_int32]")},
+		want: false,
+	}}...)
+
+	// Test IgnoreMapKeys.
+	tests = append(tests, []test{{
+		x:    &testpb.TestAllTypes{MapStringString: map[string]string{"a": "1", "b": "2"}},
+		y:    &testpb.TestAllTypes{MapStringString: map[string]string{"a": "1", "b": "3"}},
+		opts: cmp.Options{Transform()},
+		want: false,
+	}, {
+		x: &testpb.TestAllTypes{MapStringString: map[string]string{"a": "1", "b": "2"}},
+		y: &testpb.TestAllTypes{MapStringString: map[string]string{"a": "1", "b": "3"}},
+		opts: cmp.Options{Transform(),
+			IgnoreMapKeys(new(testpb.TestAllTypes), "map_string_string", "b")},
+		want: true,
+	}, {
+		x: &testpb.TestAllTypes{MapStringString: map[string]string{"a": "1", "b": "2"}},
+		y: &testpb.TestAllTypes{MapStringString: map[string]string{"a": "1"}},
+		opts: cmp.Options{Transform(),
+			IgnoreMapKeys(new(testpb.TestAllTypes), "map_string_string", "b")},
+		want: true,
+	}, {
+		x: &testpb.TestAllTypes{MapStringString: map[string]string{"a": "1", "b": "2"}},
+		y: &testpb.TestAllTypes{MapStringString: map[string]string{"a": "2", "b": "3"}},
+		opts: cmp.Options{Transform(),
+			IgnoreMapKeys(new(testpb.TestAllTypes), "map_string_string", "b")},
+		want: false,
+	}, {
+		x: &testpb.TestAllTypes{
+			MapInt32Int32:   map[int32]int32{1: 1},
+			MapStringString: map[string]string{"1": "1"},
+		},
+		y: &testpb.TestAllTypes{
+			MapInt32Int32:   map[int32]int32{1: 2},
+			MapStringString: map[string]string{"1": "2"},
+		},
+		opts: cmp.Options{Transform(),
+			IgnoreMapKeys(new(testpb.TestAllTypes), "map_int32_int32", int32(1))},
+		want: false, // map_string_string is not ignored
+	}, {
+		x: &testpb.TestAllTypes{MapStringNestedMessage: map[string]*testpb.TestAllTypes_NestedMessage{
+			"a": {A: proto.Int32(1)},
+		}},
+		y: &testpb.TestAllTypes{MapStringNestedMessage: map[string]*testpb.TestAllTypes_NestedMessage{
+			"a": {A: proto.Int32(2)},
+		}},
+		opts: cmp.Options{Transform(),
+			IgnoreMapKeys(new(testpb.TestAllTypes), "map_string_nested_message", "a")},
+		want: true,
 	}}...)
 
 	// Test FilterEnum.
@@ -1173,6 +1262,36 @@ func TestEqual(t *testing.T) {
 		want: true,
 	}}...)
 
+	tests = append(tests, []test{{
+		x: &testpb.TestAllTypes{RepeatedForeignMessage: []*testpb.ForeignMessage{{}, {C: proto.Int32(3)}, nil, {C: proto.Int32(5)}, {C: proto.Int32(4)}}},
+		y: &testpb.TestAllTypes{RepeatedForeignMessage: []*testpb.ForeignMessage{nil, {C: proto.Int32(4)}, {}, {C: proto.Int32(3)}, {C: proto.Int32(5)}}},
+		opts: cmp.Options{
+			Transform(),
+			SortRepeated(func(x, y protoreflect.Message) bool {
+				fd := x.Descriptor().Fields().ByName("c")
+				return x.Get(fd).Int() < y.Get(fd).Int()
+			}),
+		},
+		want: true,
+	}, {
+		x: &testpb.TestAllTypes{
+			RepeatedForeignMessage: []*testpb.ForeignMessage{{C: proto.Int32(1)}, {C: proto.Int32(2)}},
+			RepeatedNestedMessage:  []*testpb.TestAllTypes_NestedMessage{{A: proto.Int32(1)}, {A: proto.Int32(2)}},
+		},
+		y: &testpb.TestAllTypes{
+			RepeatedForeignMessage: []*testpb.ForeignMessage{{C: proto.Int32(2)}, {C: proto.Int32(1)}},
+			RepeatedNestedMessage:  []*testpb.TestAllTypes_NestedMessage{{A: proto.Int32(2)}, {A: proto.Int32(1)}},
+		},
+		opts: cmp.Options{
+			Transform(),
+			FilterMessage(new(testpb.ForeignMessage), SortRepeated(func(x, y protoreflect.Message) bool {
+				fd := x.Descriptor().Fields().ByName("c")
+				return x.Get(fd).Int() < y.Get(fd).Int()
+			})),
+		},
+		want: false, // repeated_nested_message is not sorted
+	}}...)
+
 	// Test SortRepeatedFields.
 	tests = append(tests, []test{{
 		x:    &testpb.TestAllTypes{RepeatedInt32: []int32{3, 2, 1, 2, 3, 3}},
@@ -1283,6 +1402,41 @@ func TestEqual(t *testing.T) {
 		want: true,
 	}}...)
 
+	// Test SortRepeatedFieldByKey.
+	tests = append(tests, []test{{
+		x: &testpb.TestAllTypes{RepeatedForeignMessage: []*testpb.ForeignMessage{{C: proto.Int32(3)}, {}, {C: proto.Int32(5)}, {C: proto.Int32(4)}}},
+		y: &testpb.TestAllTypes{RepeatedForeignMessage: []*testpb.ForeignMessage{{C: proto.Int32(4)}, {C: proto.Int32(3)}, {}, {C: proto.Int32(5)}}},
+		opts: cmp.Options{
+			Transform(),
+			SortRepeatedFieldByKey(new(testpb.TestAllTypes), "repeated_foreign_message", "c"),
+		},
+		want: true,
+	}, {
+		x: &testpb.TestAllTypes{RepeatedForeignMessage: []*testpb.ForeignMessage{{C: proto.Int32(1), D: proto.Int32(1)}, {C: proto.Int32(1), D: proto.Int32(2)}}},
+		y: &testpb.TestAllTypes{RepeatedForeignMessage: []*testpb.ForeignMessage{{C: proto.Int32(1), D: proto.Int32(2)}, {C: proto.Int32(1), D: proto.Int32(1)}}},
+		opts: cmp.Options{
+			Transform(),
+			SortRepeatedFieldByKey(new(testpb.TestAllTypes), "repeated_foreign_message", "c"),
+		},
+		want: false, // elements with equal keys are not reordered
+	}, {
+		x: &testpb.TestAllTypes{RepeatedForeignMessage: []*testpb.ForeignMessage{{C: proto.Int32(1), D: proto.Int32(1)}, {C: proto.Int32(1), D: proto.Int32(2)}}},
+		y: &testpb.TestAllTypes{RepeatedForeignMessage: []*testpb.ForeignMessage{{C: proto.Int32(1), D: proto.Int32(2)}, {C: proto.Int32(1), D: proto.Int32(1)}}},
+		opts: cmp.Options{
+			Transform(),
+			SortRepeatedFieldByKey(new(testpb.TestAllTypes), "repeated_foreign_message", "c", "d"),
+		},
+		want: true,
+	}, {
+		x: &testpb.TestAllTypes{RepeatedNestedMessage: []*testpb.TestAllTypes_NestedMessage{{A: proto.Int32(2)}, {A: proto.Int32(1)}}},
+		y: &testpb.TestAllTypes{RepeatedNestedMessage: []*testpb.TestAllTypes_NestedMessage{{A: proto.Int32(1)}, {A: proto.Int32(2)}}},
+		opts: cmp.Options{
+			Transform(),
+			SortRepeatedFieldByKey(new(testpb.TestAllTypes), "repeated_foreign_message", "c"),
+		},
+		want: false, // wrong field: repeated_nested_message != repeated_foreign_message
+	}}...)
+
 	for _, tt := range tests {
 		t.Run("", func(t *testing.T) {
 			got := cmp.Equal(tt.x, tt.y, tt.opts)
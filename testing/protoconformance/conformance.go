@@ -0,0 +1,333 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package protoconformance implements a testee for the protobuf
+// conformance test suite.
+//
+// The conformance test runner from the main protobuf repository starts the
+// testee as a subprocess and exchanges length-prefixed ConformanceRequest and
+// ConformanceResponse messages with it over stdin and stdout.
+// A [Handler] implements this protocol in-process, so that custom message
+// implementations and codecs can be tested against the official suite:
+//
+//	func main() {
+//		h := &protoconformance.Handler{
+//			Formats: protoconformance.Wire | protoconformance.JSON,
+//			NewMessage: func(name protoreflect.FullName) proto.Message {
+//				mt, err := protoregistry.GlobalTypes.FindMessageByName(name)
+//				if err != nil {
+//					return nil
+//				}
+//				return mt.New().Interface()
+//			},
+//		}
+//		if err := h.Serve(os.Stdin, os.Stdout); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+//
+// The test messages are not provided by this package: the caller links in
+// the implementation of the protobuf_test_messages.* messages under test.
+// The requests and responses themselves are encoded without registering
+// any message type.
+package protoconformance
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Format is a set of serialization formats exercised by the conformance suite.
+type Format uint
+
+const (
+	// Wire is the protobuf binary wire format.
+	Wire Format = 1 << iota
+	// JSON is the protobuf JSON format.
+	JSON
+	// Text is the protobuf text format.
+	Text
+
+	// AllFormats is the set of all formats.
+	AllFormats = Wire | JSON | Text
+)
+
+// Options are options for a single Marshal or Unmarshal operation
+// requested by the conformance suite.
+type Options struct {
+	// DiscardUnknown specifies whether to ignore unknown fields when parsing.
+	// It is set for JSON tests that ignore unknown fields.
+	DiscardUnknown bool
+
+	// EmitUnknown specifies whether to output unknown fields.
+	// It is set for text format tests that print unknown fields.
+	EmitUnknown bool
+}
+
+// Handler handles conformance test requests.
+// NewMessage must be set; the other fields default to testing every format
+// using the proto, protojson, and prototext packages.
+type Handler struct {
+	// Formats is the set of formats to test. Requests that parse or output
+	// any other format are reported to the test runner as skipped.
+	// If zero, all formats are tested.
+	Formats Format
+
+	// NewMessage returns a new empty message with the given full name
+	// to use for a test, such as protobuf_test_messages.proto3.TestAllTypesProto3.
+	// It returns nil if the message type is not tested, in which case
+	// the test is reported as failed.
+	NewMessage func(name protoreflect.FullName) proto.Message
+
+	// Marshal, if non-nil, serializes m in the given format.
+	// If nil, the proto, protojson, or prototext package is used.
+	Marshal func(f Format, m proto.Message, opts Options) ([]byte, error)
+
+	// Unmarshal, if non-nil, parses b in the given format into m.
+	// If nil, the proto, protojson, or prototext package is used.
+	Unmarshal func(f Format, b []byte, m proto.Message, opts Options) error
+}
+
+// Serve handles the conformance test requests read from r,
+// writing the responses to w, until r reports io.EOF.
+// Each request and response is prefixed by its length
+// as a 4-byte little-endian integer.
+func (h *Handler) Serve(r io.Reader, w io.Writer) error {
+	var sizeBuf [4]byte
+	var inbuf []byte
+	for {
+		_, err := io.ReadFull(r, sizeBuf[:])
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read request: %w", err)
+		}
+		size := binary.LittleEndian.Uint32(sizeBuf[:])
+		if int(size) > cap(inbuf) {
+			inbuf = make([]byte, size)
+		}
+		inbuf = inbuf[:size]
+		if _, err := io.ReadFull(r, inbuf); err != nil {
+			return fmt.Errorf("read request: %w", err)
+		}
+
+		out, err := h.Handle(inbuf)
+		if err != nil {
+			return err
+		}
+		binary.LittleEndian.PutUint32(sizeBuf[:], uint32(len(out)))
+		if _, err := w.Write(sizeBuf[:]); err != nil {
+			return fmt.Errorf("write response: %w", err)
+		}
+		if _, err := w.Write(out); err != nil {
+			return fmt.Errorf("write response: %w", err)
+		}
+	}
+}
+
+// Handle handles a single wire-encoded conformance.ConformanceRequest
+// and returns the wire-encoded conformance.ConformanceResponse.
+// Failures of the code under test are reported in the response;
+// an error is only returned if the request cannot be parsed.
+func (h *Handler) Handle(req []byte) ([]byte, error) {
+	r, err := parseRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+	num, v := h.handle(r)
+	b := protowire.AppendTag(nil, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v), nil
+}
+
+// Field numbers of conformance.ConformanceRequest.
+const (
+	requestProtobufPayload       protowire.Number = 1
+	requestJSONPayload           protowire.Number = 2
+	requestRequestedOutputFormat protowire.Number = 3
+	requestMessageType           protowire.Number = 4
+	requestTestCategory          protowire.Number = 5
+	requestJSPBPayload           protowire.Number = 7
+	requestTextPayload           protowire.Number = 8
+	requestPrintUnknownFields    protowire.Number = 9
+)
+
+// Field numbers of conformance.ConformanceResponse.
+const (
+	responseParseError      protowire.Number = 1
+	responseRuntimeError    protowire.Number = 2
+	responseProtobufPayload protowire.Number = 3
+	responseJSONPayload     protowire.Number = 4
+	responseSkipped         protowire.Number = 5
+	responseSerializeError  protowire.Number = 6
+	responseTextPayload     protowire.Number = 8
+)
+
+// Values of the conformance.WireFormat and conformance.TestCategory enums.
+const (
+	wireFormatProtobuf   = 1
+	wireFormatJSON       = 2
+	wireFormatTextFormat = 4
+
+	testCategoryJSONIgnoreUnknownParsing = 3
+)
+
+// request is a parsed conformance.ConformanceRequest.
+// The conformance.proto types are not used, so that handling requests
+// does not register them or the test message types that they are
+// generated with.
+type request struct {
+	payloadFormat      Format // zero for unsupported payloads
+	payload            []byte
+	outputFormat       uint64
+	messageType        string
+	testCategory       uint64
+	printUnknownFields bool
+}
+
+func parseRequest(b []byte) (request, error) {
+	var r request
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return r, protowire.ParseError(n)
+		}
+		b = b[n:]
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		if n < 0 {
+			return r, protowire.ParseError(n)
+		}
+		field := b[:n]
+		b = b[n:]
+
+		switch typ {
+		case protowire.BytesType:
+			v, _ := protowire.ConsumeBytes(field)
+			switch num {
+			case requestProtobufPayload:
+				r.payloadFormat, r.payload = Wire, v
+			case requestJSONPayload:
+				r.payloadFormat, r.payload = JSON, v
+			case requestJSPBPayload:
+				// JSPB is not supported.
+				r.payloadFormat, r.payload = 0, nil
+			case requestTextPayload:
+				r.payloadFormat, r.payload = Text, v
+			case requestMessageType:
+				r.messageType = string(v)
+			}
+		case protowire.VarintType:
+			v, _ := protowire.ConsumeVarint(field)
+			switch num {
+			case requestRequestedOutputFormat:
+				r.outputFormat = v
+			case requestTestCategory:
+				r.testCategory = v
+			case requestPrintUnknownFields:
+				r.printUnknownFields = protowire.DecodeBool(v)
+			}
+		}
+	}
+	return r, nil
+}
+
+// handle returns the field number and value of the result
+// of the conformance.ConformanceResponse for req.
+func (h *Handler) handle(req request) (protowire.Number, []byte) {
+	name := protoreflect.FullName(req.messageType)
+	var msg proto.Message
+	if h.NewMessage != nil {
+		msg = h.NewMessage(name)
+	}
+	if msg == nil {
+		return responseRuntimeError, []byte(fmt.Sprintf("unknown message type: %s", name))
+	}
+
+	// Unmarshal the test message.
+	in := req.payloadFormat
+	if in == 0 {
+		return responseRuntimeError, []byte("unknown request payload type")
+	}
+	var opts Options
+	opts.DiscardUnknown = in == JSON && req.testCategory == testCategoryJSONIgnoreUnknownParsing
+
+	var out Format
+	switch req.outputFormat {
+	case wireFormatProtobuf:
+		out = Wire
+	case wireFormatJSON:
+		out = JSON
+	case wireFormatTextFormat:
+		out = Text
+		opts.EmitUnknown = req.printUnknownFields
+	default:
+		return responseRuntimeError, []byte("unknown output format")
+	}
+
+	formats := h.Formats
+	if formats == 0 {
+		formats = AllFormats
+	}
+	if in&formats == 0 || out&formats == 0 {
+		return responseSkipped, []byte("format not tested")
+	}
+
+	if err := h.unmarshal(in, req.payload, msg, opts); err != nil {
+		return responseParseError, []byte(err.Error())
+	}
+
+	// Marshal the test message.
+	b, err := h.marshal(out, msg, opts)
+	if err != nil {
+		return responseSerializeError, []byte(err.Error())
+	}
+	switch out {
+	case Wire:
+		return responseProtobufPayload, b
+	case JSON:
+		return responseJSONPayload, b
+	default:
+		return responseTextPayload, b
+	}
+}
+
+func (h *Handler) unmarshal(f Format, b []byte, m proto.Message, opts Options) error {
+	if h.Unmarshal != nil {
+		return h.Unmarshal(f, b, m, opts)
+	}
+	switch f {
+	case Wire:
+		return proto.Unmarshal(b, m)
+	case JSON:
+		return protojson.UnmarshalOptions{DiscardUnknown: opts.DiscardUnknown}.Unmarshal(b, m)
+	case Text:
+		return prototext.UnmarshalOptions{DiscardUnknown: opts.DiscardUnknown}.Unmarshal(b, m)
+	default:
+		return errors.New("unknown format")
+	}
+}
+
+func (h *Handler) marshal(f Format, m proto.Message, opts Options) ([]byte, error) {
+	if h.Marshal != nil {
+		return h.Marshal(f, m, opts)
+	}
+	switch f {
+	case Wire:
+		return proto.Marshal(m)
+	case JSON:
+		return protojson.Marshal(m)
+	case Text:
+		return prototext.MarshalOptions{EmitUnknown: opts.EmitUnknown}.Marshal(m)
+	default:
+		return nil, errors.New("unknown format")
+	}
+}
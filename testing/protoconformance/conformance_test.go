@@ -0,0 +1,196 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protoconformance_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/protobuf/internal/detrand"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/testing/protoconformance"
+
+	pb "google.golang.org/protobuf/internal/testprotos/conformance"
+)
+
+func init() { detrand.Disable() }
+
+// newMessage returns a new message of the generated type with the given name.
+func newMessage(name protoreflect.FullName) proto.Message {
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(name)
+	if err != nil {
+		return nil
+	}
+	return mt.New().Interface()
+}
+
+func TestHandle(t *testing.T) {
+	const proto3Name = "protobuf_test_messages.proto3.TestAllTypesProto3"
+	wirePayload, err := proto.Marshal(&pb.TestAllTypesProto3{OptionalInt32: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		desc    string
+		handler protoconformance.Handler
+		req     *pb.ConformanceRequest
+		want    *pb.ConformanceResponse
+	}{{
+		desc: "wire to JSON",
+		req: &pb.ConformanceRequest{
+			MessageType:           proto3Name,
+			Payload:               &pb.ConformanceRequest_ProtobufPayload{ProtobufPayload: wirePayload},
+			RequestedOutputFormat: pb.WireFormat_JSON,
+		},
+		want: &pb.ConformanceResponse{
+			Result: &pb.ConformanceResponse_JsonPayload{JsonPayload: `{"optionalInt32":5}`},
+		},
+	}, {
+		desc: "JSON to wire",
+		req: &pb.ConformanceRequest{
+			MessageType:           proto3Name,
+			Payload:               &pb.ConformanceRequest_JsonPayload{JsonPayload: `{"optionalInt32": 5}`},
+			RequestedOutputFormat: pb.WireFormat_PROTOBUF,
+		},
+		want: &pb.ConformanceResponse{
+			Result: &pb.ConformanceResponse_ProtobufPayload{ProtobufPayload: wirePayload},
+		},
+	}, {
+		desc: "JSON ignoring unknown fields",
+		req: &pb.ConformanceRequest{
+			MessageType:           proto3Name,
+			Payload:               &pb.ConformanceRequest_JsonPayload{JsonPayload: `{"optionalInt32": 5, "unknownField": 1}`},
+			RequestedOutputFormat: pb.WireFormat_PROTOBUF,
+			TestCategory:          pb.TestCategory_JSON_IGNORE_UNKNOWN_PARSING_TEST,
+		},
+		want: &pb.ConformanceResponse{
+			Result: &pb.ConformanceResponse_ProtobufPayload{ProtobufPayload: wirePayload},
+		},
+	}, {
+		desc: "untested format",
+		handler: protoconformance.Handler{
+			Formats: protoconformance.Wire | protoconformance.Text,
+		},
+		req: &pb.ConformanceRequest{
+			MessageType:           proto3Name,
+			Payload:               &pb.ConformanceRequest_ProtobufPayload{ProtobufPayload: wirePayload},
+			RequestedOutputFormat: pb.WireFormat_JSON,
+		},
+		want: &pb.ConformanceResponse{
+			Result: &pb.ConformanceResponse_Skipped{Skipped: "format not tested"},
+		},
+	}, {
+		desc: "custom codec",
+		handler: protoconformance.Handler{
+			Marshal: func(f protoconformance.Format, m proto.Message, opts protoconformance.Options) ([]byte, error) {
+				return nil, errors.New("marshal failure")
+			},
+		},
+		req: &pb.ConformanceRequest{
+			MessageType:           proto3Name,
+			Payload:               &pb.ConformanceRequest_ProtobufPayload{ProtobufPayload: wirePayload},
+			RequestedOutputFormat: pb.WireFormat_PROTOBUF,
+		},
+		want: &pb.ConformanceResponse{
+			Result: &pb.ConformanceResponse_SerializeError{SerializeError: "marshal failure"},
+		},
+	}, {
+		desc: "custom message",
+		handler: protoconformance.Handler{
+			NewMessage: func(name protoreflect.FullName) proto.Message {
+				if name == "custom.Message" {
+					return &pb.TestAllTypesProto3{}
+				}
+				return nil
+			},
+		},
+		req: &pb.ConformanceRequest{
+			MessageType:           "custom.Message",
+			Payload:               &pb.ConformanceRequest_ProtobufPayload{ProtobufPayload: wirePayload},
+			RequestedOutputFormat: pb.WireFormat_JSON,
+		},
+		want: &pb.ConformanceResponse{
+			Result: &pb.ConformanceResponse_JsonPayload{JsonPayload: `{"optionalInt32":5}`},
+		},
+	}, {
+		desc: "unknown message type",
+		req: &pb.ConformanceRequest{
+			MessageType:           "unknown.Message",
+			Payload:               &pb.ConformanceRequest_ProtobufPayload{},
+			RequestedOutputFormat: pb.WireFormat_PROTOBUF,
+		},
+		want: &pb.ConformanceResponse{
+			Result: &pb.ConformanceResponse_RuntimeError{RuntimeError: "unknown message type: unknown.Message"},
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			req, err := proto.Marshal(tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			h := tt.handler
+			if h.NewMessage == nil {
+				h.NewMessage = newMessage
+			}
+			b, err := h.Handle(req)
+			if err != nil {
+				t.Fatalf("Handle() error: %v", err)
+			}
+			got := &pb.ConformanceResponse{}
+			if err := proto.Unmarshal(b, got); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Handle() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestServe(t *testing.T) {
+	appendMessage := func(b []byte, m proto.Message) []byte {
+		msg, err := proto.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(msg)))
+		return append(b, msg...)
+	}
+
+	var in, want []byte
+	for _, s := range []string{`{"optionalString":"a"}`, `{"optionalString":"b"}`} {
+		in = appendMessage(in, &pb.ConformanceRequest{
+			MessageType:           "protobuf_test_messages.proto3.TestAllTypesProto3",
+			Payload:               &pb.ConformanceRequest_JsonPayload{JsonPayload: s},
+			RequestedOutputFormat: pb.WireFormat_JSON,
+		})
+		want = appendMessage(want, &pb.ConformanceResponse{
+			Result: &pb.ConformanceResponse_JsonPayload{JsonPayload: s},
+		})
+	}
+
+	h := &protoconformance.Handler{NewMessage: newMessage}
+	var out bytes.Buffer
+	if err := h.Serve(bytes.NewReader(in), &out); err != nil {
+		t.Fatalf("Serve() error: %v", err)
+	}
+	if !bytes.Equal(out.Bytes(), want) {
+		t.Errorf("Serve() output mismatch:\ngot  %x\nwant %x", out.Bytes(), want)
+	}
+
+	if err := h.Serve(bytes.NewReader(in[:len(in)-1]), &out); err == nil {
+		t.Errorf("Serve() with truncated input succeeded, want error")
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protopack_test
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protopack"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// This example constructs wire data by hand, including inputs which are
+// valid but unusual, and inputs which are malformed.
+func Example() {
+	// A google.protobuf.StringValue with its length prefix encoded
+	// using two more bytes than necessary, which is still valid.
+	b := protopack.Message{
+		protopack.Tag{1, protopack.BytesType},
+		protopack.Denormalized{2, protopack.String("hello")},
+	}.Marshal()
+	fmt.Printf("% x\n", b)
+
+	m := new(wrapperspb.StringValue)
+	if err := proto.Unmarshal(b, m); err == nil {
+		fmt.Println(m.GetValue())
+	}
+
+	// A length prefix which claims more bytes than are present.
+	b = protopack.Message{
+		protopack.Tag{1, protopack.BytesType},
+		protopack.Uvarint(10),
+		protopack.Raw("hello"),
+	}.Marshal()
+	if err := proto.Unmarshal(b, m); err != nil {
+		fmt.Println("truncated input rejected")
+	}
+
+	// Output:
+	// 0a 85 80 00 68 65 6c 6c 6f
+	// hello
+	// truncated input rejected
+}
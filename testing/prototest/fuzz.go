@@ -0,0 +1,331 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package prototest
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/internal/genid"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// defaultFuzzMaxDepth is the default value of Fuzz.MaxDepth.
+const defaultFuzzMaxDepth = 10
+
+// Fuzz tests a message implementation with messages generated from
+// arbitrary input, such as that provided by a Go fuzz test:
+//
+//	func FuzzMyMessage(f *testing.F) {
+//		mt := (*mypb.MyMessage)(nil).ProtoReflect().Type()
+//		f.Fuzz(func(t *testing.T, data []byte) {
+//			prototest.Fuzz{}.Test(t, mt, data)
+//		})
+//	}
+type Fuzz struct {
+	// Resolver is used to determine the list of extension fields to populate,
+	// and to resolve extensions when unmarshaling.
+	// If nil, this defaults to using protoregistry.GlobalTypes.
+	Resolver interface {
+		protoregistry.MessageTypeResolver
+		protoregistry.ExtensionTypeResolver
+		RangeExtensionsByMessage(message protoreflect.FullName, f func(protoreflect.ExtensionType) bool)
+	}
+
+	// MaxDepth limits how deeply generated messages are nested.
+	// Fields of message type are left unpopulated beyond this depth,
+	// unless they are required. If zero, a default limit is applied.
+	MaxDepth int
+}
+
+// NewMessage returns a new message of type mt populated with values derived
+// from data. The same data always produces the same message.
+//
+// The message has all required fields populated, at most one field of each
+// oneof populated, and only values which are valid in all of the wire,
+// JSON, and text formats: strings are valid UTF-8, enum values are declared
+// by the enum, floating-point values are not NaN, and well-known types
+// hold values within the range allowed by their JSON representation.
+// Once data is exhausted, no further optional fields are populated.
+func (f Fuzz) NewMessage(mt protoreflect.MessageType, data []byte) proto.Message {
+	f = f.withDefaults()
+	g := &fuzzGenerator{opts: f, data: data}
+	m := mt.New()
+	g.populateMessage(m, 0)
+	return m.Interface()
+}
+
+// Test generates a message of type mt from data using NewMessage and checks
+// that it is preserved by a round trip through the wire, JSON, and text
+// formats, and that the size reported by proto.Size matches its encoding.
+func (f Fuzz) Test(t testing.TB, mt protoreflect.MessageType, data []byte) {
+	t.Helper()
+	f = f.withDefaults()
+	m := f.NewMessage(mt, data)
+
+	b, err := proto.Marshal(m)
+	if err != nil {
+		t.Fatalf("proto.Marshal() = %v, want nil\n%v", err, prototext.Format(m))
+	}
+	if size := proto.Size(m); size != len(b) {
+		t.Errorf("proto.Size() = %v, want %v\n%v", size, len(b), prototext.Format(m))
+	}
+	got := mt.New().Interface()
+	if err := (proto.UnmarshalOptions{Resolver: f.Resolver}).Unmarshal(b, got); err != nil {
+		t.Errorf("proto.Unmarshal() = %v, want nil\n%v", err, prototext.Format(m))
+	} else if !proto.Equal(m, got) {
+		t.Errorf("round-trip through the wire format did not preserve message\nOriginal:\n%v\nNew:\n%v", prototext.Format(m), prototext.Format(got))
+	}
+
+	b, err = protojson.MarshalOptions{Resolver: f.Resolver}.Marshal(m)
+	if err != nil {
+		t.Errorf("protojson.Marshal() = %v, want nil\n%v", err, prototext.Format(m))
+	} else {
+		got := mt.New().Interface()
+		if err := (protojson.UnmarshalOptions{Resolver: f.Resolver}).Unmarshal(b, got); err != nil {
+			t.Errorf("protojson.Unmarshal() = %v, want nil\n%s", err, b)
+		} else if !proto.Equal(m, got) {
+			t.Errorf("round-trip through the JSON format did not preserve message\nOriginal:\n%v\nNew:\n%v", prototext.Format(m), prototext.Format(got))
+		}
+	}
+
+	b, err = prototext.MarshalOptions{Resolver: f.Resolver}.Marshal(m)
+	if err != nil {
+		t.Errorf("prototext.Marshal() = %v, want nil\n%v", err, prototext.Format(m))
+	} else {
+		got := mt.New().Interface()
+		if err := (prototext.UnmarshalOptions{Resolver: f.Resolver}).Unmarshal(b, got); err != nil {
+			t.Errorf("prototext.Unmarshal() = %v, want nil\n%s", err, b)
+		} else if !proto.Equal(m, got) {
+			t.Errorf("round-trip through the text format did not preserve message\nOriginal:\n%v\nNew:\n%v", prototext.Format(m), prototext.Format(got))
+		}
+	}
+}
+
+func (f Fuzz) withDefaults() Fuzz {
+	if f.Resolver == nil {
+		f.Resolver = protoregistry.GlobalTypes
+	}
+	if f.MaxDepth == 0 {
+		f.MaxDepth = defaultFuzzMaxDepth
+	}
+	return f
+}
+
+// fuzzGenerator populates messages with values read from data.
+// Once data is exhausted, every value read is zero.
+type fuzzGenerator struct {
+	opts Fuzz
+	data []byte
+}
+
+func (g *fuzzGenerator) bytes(n int) []byte {
+	b := make([]byte, n)
+	g.data = g.data[copy(b, g.data):]
+	return b
+}
+
+func (g *fuzzGenerator) byte() byte           { return g.bytes(1)[0] }
+func (g *fuzzGenerator) bool() bool           { return g.byte()&1 == 1 }
+func (g *fuzzGenerator) uint32() uint32       { return binary.LittleEndian.Uint32(g.bytes(4)) }
+func (g *fuzzGenerator) uint64() uint64       { return binary.LittleEndian.Uint64(g.bytes(8)) }
+func (g *fuzzGenerator) intn(n int) int       { return int(g.byte()) % n }
+func (g *fuzzGenerator) int64n(n int64) int64 { return int64(g.uint64() % uint64(n)) }
+
+func (g *fuzzGenerator) populateMessage(m protoreflect.Message, depth int) {
+	md := m.Descriptor()
+	if g.populateKnownMessage(m, depth) {
+		return
+	}
+
+	fds := md.Fields()
+	for i := 0; i < fds.Len(); i++ {
+		fd := fds.Get(i)
+		if od := fd.ContainingOneof(); od != nil && !od.IsSynthetic() {
+			continue
+		}
+		if fd.Cardinality() == protoreflect.Required || g.bool() {
+			g.populateField(m, fd, depth)
+		}
+	}
+	ods := md.Oneofs()
+	for i := 0; i < ods.Len(); i++ {
+		od := ods.Get(i)
+		if od.IsSynthetic() {
+			continue
+		}
+		if n := g.intn(od.Fields().Len() + 1); n > 0 {
+			g.populateField(m, od.Fields().Get(n-1), depth)
+		}
+	}
+	var xts []protoreflect.ExtensionType
+	g.opts.Resolver.RangeExtensionsByMessage(md.FullName(), func(xt protoreflect.ExtensionType) bool {
+		xts = append(xts, xt)
+		return true
+	})
+	for _, xt := range xts {
+		if g.bool() {
+			g.populateField(m, xt.TypeDescriptor(), depth)
+		}
+	}
+}
+
+func (g *fuzzGenerator) populateField(m protoreflect.Message, fd protoreflect.FieldDescriptor, depth int) {
+	// Beyond the maximum depth, only populate required message fields,
+	// and stop entirely if the message has a cycle of required fields.
+	isMessage := fd.Message() != nil && (!fd.IsMap() || fd.MapValue().Message() != nil)
+	if isMessage && depth >= g.opts.MaxDepth && (fd.Cardinality() != protoreflect.Required || depth >= 2*g.opts.MaxDepth) {
+		return
+	}
+	switch {
+	case fd.IsList():
+		list := m.Mutable(fd).List()
+		for n := g.intn(4); n > 0; n-- {
+			if fd.Message() != nil {
+				v := list.NewElement()
+				g.populateMessage(v.Message(), depth+1)
+				list.Append(v)
+			} else {
+				list.Append(g.scalarValue(fd))
+			}
+		}
+	case fd.IsMap():
+		mapv := m.Mutable(fd).Map()
+		for n := g.intn(4); n > 0; n-- {
+			k := g.scalarValue(fd.MapKey()).MapKey()
+			if fd.MapValue().Message() != nil {
+				v := mapv.NewValue()
+				g.populateMessage(v.Message(), depth+1)
+				mapv.Set(k, v)
+			} else {
+				mapv.Set(k, g.scalarValue(fd.MapValue()))
+			}
+		}
+	case fd.Message() != nil:
+		g.populateMessage(m.Mutable(fd).Message(), depth+1)
+	default:
+		m.Set(fd, g.scalarValue(fd))
+	}
+}
+
+func (g *fuzzGenerator) scalarValue(fd protoreflect.FieldDescriptor) protoreflect.Value {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return protoreflect.ValueOfBool(g.bool())
+	case protoreflect.EnumKind:
+		vals := fd.Enum().Values()
+		return protoreflect.ValueOfEnum(vals.Get(g.intn(vals.Len())).Number())
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return protoreflect.ValueOfInt32(int32(g.uint32()))
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return protoreflect.ValueOfUint32(g.uint32())
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return protoreflect.ValueOfInt64(int64(g.uint64()))
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return protoreflect.ValueOfUint64(g.uint64())
+	case protoreflect.FloatKind:
+		v := math.Float32frombits(g.uint32())
+		if v != v {
+			v = 0
+		}
+		return protoreflect.ValueOfFloat32(v)
+	case protoreflect.DoubleKind:
+		return protoreflect.ValueOfFloat64(g.finiteFloat64())
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(g.string())
+	case protoreflect.BytesKind:
+		return protoreflect.ValueOfBytes(g.bytes(g.intn(16)))
+	}
+	panic("unhandled kind")
+}
+
+func (g *fuzzGenerator) finiteFloat64() float64 {
+	v := math.Float64frombits(g.uint64())
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0
+	}
+	return v
+}
+
+// string returns a valid UTF-8 string.
+func (g *fuzzGenerator) string() string {
+	n := g.intn(16)
+	b := make([]byte, 0, n)
+	for i := 0; i < n; i++ {
+		r := rune(binary.LittleEndian.Uint16(g.bytes(2)))
+		if !utf8.ValidRune(r) {
+			r = utf8.RuneError
+		}
+		b = utf8.AppendRune(b, r)
+	}
+	return string(b)
+}
+
+// populateKnownMessage populates well-known types whose JSON representation
+// restricts their values. It reports whether m is such a type.
+func (g *fuzzGenerator) populateKnownMessage(m protoreflect.Message, depth int) bool {
+	md := m.Descriptor()
+	if md.FullName().Parent() != genid.GoogleProtobuf_package {
+		return false
+	}
+	fds := md.Fields()
+	switch md.FullName() {
+	case genid.Any_message_fullname:
+		// Leave the Any empty, since its contents must be resolvable.
+	case genid.Timestamp_message_fullname:
+		const minSecs, maxSecs = -62135596800, 253402300799 // 0001-01-01 to 9999-12-31
+		m.Set(fds.ByNumber(genid.Timestamp_Seconds_field_number), protoreflect.ValueOfInt64(minSecs+g.int64n(maxSecs-minSecs+1)))
+		m.Set(fds.ByNumber(genid.Timestamp_Nanos_field_number), protoreflect.ValueOfInt32(int32(g.int64n(1e9))))
+	case genid.Duration_message_fullname:
+		const maxSecs = 315576000000 // 10000 years
+		secs := g.int64n(2*maxSecs+1) - maxSecs
+		nanos := int32(g.int64n(1e9))
+		if secs < 0 || (secs == 0 && g.bool()) {
+			nanos = -nanos
+		}
+		m.Set(fds.ByNumber(genid.Duration_Seconds_field_number), protoreflect.ValueOfInt64(secs))
+		m.Set(fds.ByNumber(genid.Duration_Nanos_field_number), protoreflect.ValueOfInt32(nanos))
+	case genid.FieldMask_message_fullname:
+		// Paths must consist of lowercase letters to survive conversion
+		// to and from the lowerCamelCase JSON representation.
+		paths := m.Mutable(fds.ByNumber(genid.FieldMask_Paths_field_number)).List()
+		for n := g.intn(4); n > 0; n-- {
+			b := make([]byte, 1+g.intn(8))
+			for i := range b {
+				b[i] = 'a' + byte(g.intn(26))
+			}
+			paths.Append(protoreflect.ValueOfString(string(b)))
+		}
+	case genid.Value_message_fullname:
+		// A Value must have a kind, and a number value must be finite.
+		kind := g.intn(6)
+		if depth >= g.opts.MaxDepth {
+			kind %= 4
+		}
+		switch kind {
+		case 0:
+			m.Set(fds.ByNumber(genid.Value_NullValue_field_number), protoreflect.ValueOfEnum(0))
+		case 1:
+			m.Set(fds.ByNumber(genid.Value_NumberValue_field_number), protoreflect.ValueOfFloat64(g.finiteFloat64()))
+		case 2:
+			m.Set(fds.ByNumber(genid.Value_StringValue_field_number), protoreflect.ValueOfString(g.string()))
+		case 3:
+			m.Set(fds.ByNumber(genid.Value_BoolValue_field_number), protoreflect.ValueOfBool(g.bool()))
+		case 4:
+			g.populateField(m, fds.ByNumber(genid.Value_StructValue_field_number), depth)
+		case 5:
+			g.populateField(m, fds.ByNumber(genid.Value_ListValue_field_number), depth)
+		}
+	default:
+		return false
+	}
+	return true
+}
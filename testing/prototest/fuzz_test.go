@@ -0,0 +1,63 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package prototest_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/prototest"
+
+	testpb "google.golang.org/protobuf/internal/testprotos/test"
+	test3pb "google.golang.org/protobuf/internal/testprotos/test3"
+	testeditionspb "google.golang.org/protobuf/internal/testprotos/testeditions"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestFuzz(t *testing.T) {
+	ms := []proto.Message{
+		(*testpb.TestAllTypes)(nil),
+		(*test3pb.TestAllTypes)(nil),
+		(*testeditionspb.TestAllTypes)(nil),
+		(*testpb.TestRequired)(nil),
+		(*testpb.TestAllExtensions)(nil),
+		(*testeditionspb.TestAllExtensions)(nil),
+		(*structpb.Value)(nil),
+		(*timestamppb.Timestamp)(nil),
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for _, m := range ms {
+		t.Run(fmt.Sprintf("%T", m), func(t *testing.T) {
+			mt := m.ProtoReflect().Type()
+			for i := 0; i < 100; i++ {
+				data := make([]byte, r.Intn(4096))
+				r.Read(data)
+				prototest.Fuzz{}.Test(t, mt, data)
+			}
+		})
+	}
+}
+
+func TestFuzzNewMessage(t *testing.T) {
+	mt := (*testpb.TestAllTypes)(nil).ProtoReflect().Type()
+	data := make([]byte, 1024)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	m1 := prototest.Fuzz{}.NewMessage(mt, data)
+	m2 := prototest.Fuzz{}.NewMessage(mt, data)
+	if !proto.Equal(m1, m2) {
+		t.Errorf("NewMessage is not deterministic:\n%v\n%v", m1, m2)
+	}
+	if proto.Size(m1) == 0 {
+		t.Errorf("NewMessage(%d bytes) returned an empty message", len(data))
+	}
+	if m := (prototest.Fuzz{}).NewMessage(mt, nil); proto.Size(m) != 0 {
+		t.Errorf("NewMessage(nil) = %v, want empty message", m)
+	}
+}
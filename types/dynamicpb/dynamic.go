@@ -7,6 +7,8 @@ package dynamicpb
 
 import (
 	"math"
+	"sync"
+	"sync/atomic"
 
 	"google.golang.org/protobuf/internal/errors"
 	"google.golang.org/protobuf/reflect/protoreflect"
@@ -63,26 +65,36 @@ type extensionType struct {
 // with a compatible type.
 //
 // Operations which modify a Message are not safe for concurrent use.
+//
+// Message records the size computed by [google.golang.org/protobuf/proto.Size]
+// so that it may be reused by operations that set the UseCachedSize option.
+// The recorded size is discarded when the message is modified through any of
+// its own methods, but not when a submessage, list, or map that it contains
+// is modified.
+//
+// Each message refers to its [protoreflect.MessageType], which caches
+// information about the fields of the message type so that it need not be
+// derived from the descriptor on every access. This information is computed
+// once for each descriptor and shared by all messages with that descriptor.
 type Message struct {
-	typ     messageType
-	known   map[protoreflect.FieldNumber]protoreflect.Value
-	ext     map[protoreflect.FieldNumber]protoreflect.FieldDescriptor
-	unknown protoreflect.RawFields
+	typ       *messageType
+	known     map[protoreflect.FieldNumber]protoreflect.Value
+	ext       map[protoreflect.FieldNumber]protoreflect.FieldDescriptor
+	unknown   protoreflect.RawFields
+	sizeCache int32 // cached size plus one; zero if there is none
 }
 
 var (
 	_ protoreflect.Message      = (*Message)(nil)
 	_ protoreflect.ProtoMessage = (*Message)(nil)
 	_ protoiface.MessageV1      = (*Message)(nil)
+	_ protoiface.SizeCacher     = (*Message)(nil)
 )
 
 // NewMessage creates a new message with the provided descriptor.
+// It is equivalent to NewMessageType(desc).New().
 func NewMessage(desc protoreflect.MessageDescriptor) *Message {
-	return &Message{
-		typ:   messageType{desc},
-		known: make(map[protoreflect.FieldNumber]protoreflect.Value),
-		ext:   make(map[protoreflect.FieldNumber]protoreflect.FieldDescriptor),
-	}
+	return loadMessageType(desc).newMessage()
 }
 
 // ProtoMessage implements the legacy message interface.
@@ -100,6 +112,7 @@ func (m *Message) String() string {
 
 // Reset clears the message to be empty, but preserves the dynamic message type.
 func (m *Message) Reset() {
+	m.clearSizeCache()
 	m.known = make(map[protoreflect.FieldNumber]protoreflect.Value)
 	m.ext = make(map[protoreflect.FieldNumber]protoreflect.FieldDescriptor)
 	m.unknown = nil
@@ -133,18 +146,39 @@ func (m *Message) ProtoMethods() *protoiface.Methods {
 	return nil
 }
 
+// ProtoCachedSize is an internal detail of the [protoiface.SizeCacher] interface.
+// Users should never call this directly.
+func (m *Message) ProtoCachedSize() (size int, ok bool) {
+	n := atomic.LoadInt32(&m.sizeCache)
+	return int(n) - 1, n > 0
+}
+
+// ProtoSetCachedSize is an internal detail of the [protoiface.SizeCacher] interface.
+// Users should never call this directly.
+func (m *Message) ProtoSetCachedSize(size int) {
+	if size >= math.MaxInt32 {
+		size = -1 // too large to be cached
+	}
+	atomic.StoreInt32(&m.sizeCache, int32(size+1))
+}
+
+func (m *Message) clearSizeCache() {
+	atomic.StoreInt32(&m.sizeCache, 0)
+}
+
 // Range visits every populated field in undefined order.
 // See [protoreflect.Message] for details.
 func (m *Message) Range(f func(protoreflect.FieldDescriptor, protoreflect.Value) bool) {
+	mt := m.typ.init()
 	for num, v := range m.known {
-		fd := m.ext[num]
-		if fd == nil {
-			fd = m.Descriptor().Fields().ByNumber(num)
-		}
-		if !isSet(fd, v) {
+		if fd := m.ext[num]; fd != nil {
+			if isSet(fd, v) && !f(fd, v) {
+				return
+			}
 			continue
 		}
-		if !f(fd, v) {
+		fi := mt.byNumber[num]
+		if fi.isSet(v) && !f(fi.fd, v) {
 			return
 		}
 	}
@@ -153,21 +187,23 @@ func (m *Message) Range(f func(protoreflect.FieldDescriptor, protoreflect.Value)
 // Has reports whether a field is populated.
 // See [protoreflect.Message] for details.
 func (m *Message) Has(fd protoreflect.FieldDescriptor) bool {
-	m.checkField(fd)
-	if fd.IsExtension() && m.ext[fd.Number()] != fd {
-		return false
-	}
-	v, ok := m.known[fd.Number()]
-	if !ok {
-		return false
+	fi := m.fieldInfo(fd)
+	if fi == nil {
+		num := fd.Number()
+		if m.ext[num] != fd {
+			return false
+		}
+		return isSet(fd, m.known[num])
 	}
-	return isSet(fd, v)
+	v, ok := m.known[fi.num]
+	return ok && fi.isSet(v)
 }
 
 // Clear clears a field.
 // See [protoreflect.Message] for details.
 func (m *Message) Clear(fd protoreflect.FieldDescriptor) {
-	m.checkField(fd)
+	m.fieldInfo(fd)
+	m.clearSizeCache()
 	num := fd.Number()
 	delete(m.known, num)
 	delete(m.ext, num)
@@ -176,21 +212,21 @@ func (m *Message) Clear(fd protoreflect.FieldDescriptor) {
 // Get returns the value of a field.
 // See [protoreflect.Message] for details.
 func (m *Message) Get(fd protoreflect.FieldDescriptor) protoreflect.Value {
-	m.checkField(fd)
-	num := fd.Number()
-	if fd.IsExtension() {
+	fi := m.fieldInfo(fd)
+	if fi == nil {
+		num := fd.Number()
 		if fd != m.ext[num] {
 			return fd.(protoreflect.ExtensionTypeDescriptor).Type().Zero()
 		}
 		return m.known[num]
 	}
-	if v, ok := m.known[num]; ok {
+	if v, ok := m.known[fi.num]; ok {
 		switch {
-		case fd.IsMap():
+		case fi.isMap:
 			if v.Map().Len() > 0 {
 				return v
 			}
-		case fd.IsList():
+		case fi.isList:
 			if v.List().Len() > 0 {
 				return v
 			}
@@ -199,56 +235,56 @@ func (m *Message) Get(fd protoreflect.FieldDescriptor) protoreflect.Value {
 		}
 	}
 	switch {
-	case fd.IsMap():
-		return protoreflect.ValueOfMap(&dynamicMap{desc: fd})
-	case fd.IsList():
-		return protoreflect.ValueOfList(emptyList{desc: fd})
-	case fd.Message() != nil:
-		return protoreflect.ValueOfMessage(&Message{typ: messageType{fd.Message()}})
-	case fd.Kind() == protoreflect.BytesKind:
-		return protoreflect.ValueOfBytes(append([]byte(nil), fd.Default().Bytes()...))
+	case fi.isMap:
+		return protoreflect.ValueOfMap(&dynamicMap{desc: fd, typ: fi.msgType})
+	case fi.isList:
+		return protoreflect.ValueOfList(emptyList{desc: fd, typ: fi.msgType})
+	case fi.msgType != nil:
+		return protoreflect.ValueOfMessage(&Message{typ: fi.msgType})
+	case fi.kind == protoreflect.BytesKind:
+		return protoreflect.ValueOfBytes(append([]byte(nil), fi.def.Bytes()...))
 	default:
-		return fd.Default()
+		return fi.def
 	}
 }
 
 // Mutable returns a mutable reference to a repeated, map, or message field.
 // See [protoreflect.Message] for details.
 func (m *Message) Mutable(fd protoreflect.FieldDescriptor) protoreflect.Value {
-	m.checkField(fd)
+	fi := m.fieldInfo(fd)
 	if !fd.IsMap() && !fd.IsList() && fd.Message() == nil {
 		panic(errors.New("%v: getting mutable reference to non-composite type", fd.FullName()))
 	}
 	if m.known == nil {
 		panic(errors.New("%v: modification of read-only message", fd.FullName()))
 	}
-	num := fd.Number()
-	if fd.IsExtension() {
+	m.clearSizeCache()
+	if fi == nil {
+		num := fd.Number()
 		if fd != m.ext[num] {
 			m.ext[num] = fd
 			m.known[num] = fd.(protoreflect.ExtensionTypeDescriptor).Type().New()
 		}
 		return m.known[num]
 	}
-	if v, ok := m.known[num]; ok {
+	if v, ok := m.known[fi.num]; ok {
 		return v
 	}
-	m.clearOtherOneofFields(fd)
-	m.known[num] = m.NewField(fd)
-	if fd.IsExtension() {
-		m.ext[num] = fd
-	}
-	return m.known[num]
+	m.clearOtherOneofFields(fi)
+	v := fi.newField()
+	m.known[fi.num] = v
+	return v
 }
 
 // Set stores a value in a field.
 // See [protoreflect.Message] for details.
 func (m *Message) Set(fd protoreflect.FieldDescriptor, v protoreflect.Value) {
-	m.checkField(fd)
+	fi := m.fieldInfo(fd)
 	if m.known == nil {
 		panic(errors.New("%v: modification of read-only message", fd.FullName()))
 	}
-	if fd.IsExtension() {
+	m.clearSizeCache()
+	if fi == nil {
 		isValid := true
 		switch {
 		case !fd.(protoreflect.ExtensionTypeDescriptor).Type().IsValidValue(v):
@@ -264,45 +300,28 @@ func (m *Message) Set(fd protoreflect.FieldDescriptor, v protoreflect.Value) {
 			panic(errors.New("%v: assigning invalid type %T", fd.FullName(), v.Interface()))
 		}
 		m.ext[fd.Number()] = fd
-	} else {
-		typecheck(fd, v)
+		m.known[fd.Number()] = v
+		return
 	}
-	m.clearOtherOneofFields(fd)
-	m.known[fd.Number()] = v
+	typecheck(fd, v)
+	m.clearOtherOneofFields(fi)
+	m.known[fi.num] = v
 }
 
-func (m *Message) clearOtherOneofFields(fd protoreflect.FieldDescriptor) {
-	od := fd.ContainingOneof()
-	if od == nil {
-		return
-	}
-	num := fd.Number()
-	for i := 0; i < od.Fields().Len(); i++ {
-		if n := od.Fields().Get(i).Number(); n != num {
-			delete(m.known, n)
-		}
+func (m *Message) clearOtherOneofFields(fi *fieldInfo) {
+	for _, n := range fi.otherOneofFields {
+		delete(m.known, n)
 	}
 }
 
 // NewField returns a new value for assignable to the field of a given descriptor.
 // See [protoreflect.Message] for details.
 func (m *Message) NewField(fd protoreflect.FieldDescriptor) protoreflect.Value {
-	m.checkField(fd)
-	switch {
-	case fd.IsExtension():
+	fi := m.fieldInfo(fd)
+	if fi == nil {
 		return fd.(protoreflect.ExtensionTypeDescriptor).Type().New()
-	case fd.IsMap():
-		return protoreflect.ValueOfMap(&dynamicMap{
-			desc: fd,
-			mapv: make(map[any]protoreflect.Value),
-		})
-	case fd.IsList():
-		return protoreflect.ValueOfList(&dynamicList{desc: fd})
-	case fd.Message() != nil:
-		return protoreflect.ValueOfMessage(NewMessage(fd.Message()).ProtoReflect())
-	default:
-		return fd.Default()
 	}
+	return fi.newField()
 }
 
 // WhichOneof reports which field in a oneof is populated, returning nil if none are populated.
@@ -329,6 +348,7 @@ func (m *Message) SetUnknown(r protoreflect.RawFields) {
 	if m.known == nil {
 		panic(errors.New("%v: modification of read-only message", m.typ.desc.FullName()))
 	}
+	m.clearSizeCache()
 	m.unknown = r
 }
 
@@ -338,53 +358,177 @@ func (m *Message) IsValid() bool {
 	return m.known != nil
 }
 
-func (m *Message) checkField(fd protoreflect.FieldDescriptor) {
-	if fd.IsExtension() && fd.ContainingMessage().FullName() == m.Descriptor().FullName() {
+// fieldInfo returns the cached information for a field of the message,
+// or nil if fd is an extension field. It panics if the field does not
+// belong to the message.
+func (m *Message) fieldInfo(fd protoreflect.FieldDescriptor) *fieldInfo {
+	mt := m.typ.init()
+	if i := fd.Index(); i < len(mt.fields) && mt.fields[i].fd == fd {
+		return &mt.fields[i]
+	}
+	if fd.IsExtension() && fd.ContainingMessage().FullName() == mt.desc.FullName() {
 		if _, ok := fd.(protoreflect.ExtensionTypeDescriptor); !ok {
 			panic(errors.New("%v: extension field descriptor does not implement ExtensionTypeDescriptor", fd.FullName()))
 		}
-		return
-	}
-	if fd.Parent() == m.Descriptor() {
-		return
-	}
-	fields := m.Descriptor().Fields()
-	index := fd.Index()
-	if index >= fields.Len() || fields.Get(index) != fd {
-		panic(errors.New("%v: field descriptor does not belong to this message", fd.FullName()))
+		return nil
 	}
+	panic(errors.New("%v: field descriptor does not belong to this message", fd.FullName()))
 }
 
+// messageType is a dynamic protoreflect.MessageType.
+//
+// It caches information about the fields of the message, which is computed
+// on first use. There is a single messageType for each descriptor.
 type messageType struct {
 	desc protoreflect.MessageDescriptor
+
+	once     sync.Once
+	fields   []fieldInfo // indexed by field index
+	byNumber map[protoreflect.FieldNumber]*fieldInfo
 }
 
+// fieldInfo is the cached information about a field of a message type.
+type fieldInfo struct {
+	fd     protoreflect.FieldDescriptor
+	num    protoreflect.FieldNumber
+	kind   protoreflect.Kind
+	isMap  bool
+	isList bool
+
+	// hasPresence reports whether the field has presence, or is a oneof member.
+	hasPresence bool
+
+	// def is the default value of a singular scalar field.
+	def protoreflect.Value
+
+	// msgType is the message type of a singular or repeated message field,
+	// or of the values of a map field; nil if these are not messages.
+	msgType *messageType
+
+	// otherOneofFields are the other fields in the oneof of the field.
+	otherOneofFields []protoreflect.FieldNumber
+}
+
+// messageTypes maps each message descriptor to its *messageType.
+var messageTypes sync.Map
+
 // NewMessageType creates a new MessageType with the provided descriptor.
 //
 // MessageTypes created by this package are equal if their descriptors are equal.
 // That is, if md1 == md2, then NewMessageType(md1) == NewMessageType(md2).
 func NewMessageType(desc protoreflect.MessageDescriptor) protoreflect.MessageType {
-	return messageType{desc}
+	return loadMessageType(desc)
+}
+
+// loadMessageType returns the message type for md.
+func loadMessageType(md protoreflect.MessageDescriptor) *messageType {
+	if mt, ok := messageTypes.Load(md); ok {
+		return mt.(*messageType)
+	}
+	mt, _ := messageTypes.LoadOrStore(md, &messageType{desc: md})
+	return mt.(*messageType)
+}
+
+// init computes the field information of mt if it has not been computed yet.
+func (mt *messageType) init() *messageType {
+	mt.once.Do(func() {
+		fields := mt.desc.Fields()
+		mt.fields = make([]fieldInfo, fields.Len())
+		mt.byNumber = make(map[protoreflect.FieldNumber]*fieldInfo, fields.Len())
+		for i := range mt.fields {
+			fd := fields.Get(i)
+			fi := &mt.fields[i]
+			*fi = fieldInfo{
+				fd:          fd,
+				num:         fd.Number(),
+				kind:        fd.Kind(),
+				isMap:       fd.IsMap(),
+				isList:      fd.IsList(),
+				hasPresence: fd.HasPresence() || fd.ContainingOneof() != nil,
+			}
+			md := fd.Message()
+			if fi.isMap {
+				md = fd.MapValue().Message()
+			}
+			switch {
+			case md != nil:
+				fi.msgType = loadMessageType(md)
+			case !fi.isMap && !fi.isList:
+				fi.def = fd.Default()
+			}
+			if od := fd.ContainingOneof(); od != nil {
+				for j := 0; j < od.Fields().Len(); j++ {
+					if n := od.Fields().Get(j).Number(); n != fi.num {
+						fi.otherOneofFields = append(fi.otherOneofFields, n)
+					}
+				}
+			}
+			mt.byNumber[fi.num] = fi
+		}
+	})
+	return mt
+}
+
+func (mt *messageType) newMessage() *Message {
+	return &Message{
+		typ:   mt,
+		known: make(map[protoreflect.FieldNumber]protoreflect.Value),
+		ext:   make(map[protoreflect.FieldNumber]protoreflect.FieldDescriptor),
+	}
 }
 
-func (mt messageType) New() protoreflect.Message                  { return NewMessage(mt.desc) }
-func (mt messageType) Zero() protoreflect.Message                 { return &Message{typ: messageType{mt.desc}} }
-func (mt messageType) Descriptor() protoreflect.MessageDescriptor { return mt.desc }
-func (mt messageType) Enum(i int) protoreflect.EnumType {
+func (mt *messageType) New() protoreflect.Message                  { return mt.newMessage() }
+func (mt *messageType) Zero() protoreflect.Message                 { return &Message{typ: mt} }
+func (mt *messageType) Descriptor() protoreflect.MessageDescriptor { return mt.desc }
+func (mt *messageType) Enum(i int) protoreflect.EnumType {
 	if ed := mt.desc.Fields().Get(i).Enum(); ed != nil {
 		return NewEnumType(ed)
 	}
 	return nil
 }
-func (mt messageType) Message(i int) protoreflect.MessageType {
-	if md := mt.desc.Fields().Get(i).Message(); md != nil {
-		return NewMessageType(md)
+func (mt *messageType) Message(i int) protoreflect.MessageType {
+	if fi := &mt.init().fields[i]; fi.isMap {
+		return loadMessageType(fi.fd.Message())
+	} else if fi.msgType != nil {
+		return fi.msgType
 	}
 	return nil
 }
 
+// newField returns a new value for the field.
+func (fi *fieldInfo) newField() protoreflect.Value {
+	switch {
+	case fi.isMap:
+		return protoreflect.ValueOfMap(&dynamicMap{
+			desc: fi.fd,
+			typ:  fi.msgType,
+			mapv: make(map[any]protoreflect.Value),
+		})
+	case fi.isList:
+		return protoreflect.ValueOfList(&dynamicList{desc: fi.fd, typ: fi.msgType})
+	case fi.msgType != nil:
+		return protoreflect.ValueOfMessage(fi.msgType.newMessage())
+	default:
+		return fi.def
+	}
+}
+
+// isSet is equivalent to the isSet function.
+func (fi *fieldInfo) isSet(v protoreflect.Value) bool {
+	switch {
+	case fi.isMap:
+		return v.Map().Len() > 0
+	case fi.isList:
+		return v.List().Len() > 0
+	case fi.hasPresence:
+		return true
+	}
+	return isSet(fi.fd, v)
+}
+
 type emptyList struct {
 	desc protoreflect.FieldDescriptor
+	typ  *messageType // element type, if a message
 }
 
 func (x emptyList) Len() int                     { return 0 }
@@ -397,11 +541,12 @@ func (x emptyList) AppendMutable() protoreflect.Value {
 	panic(errors.New("modification of immutable list"))
 }
 func (x emptyList) Truncate(n int)                 { panic(errors.New("modification of immutable list")) }
-func (x emptyList) NewElement() protoreflect.Value { return newListEntry(x.desc) }
+func (x emptyList) NewElement() protoreflect.Value { return newListEntry(x.desc, x.typ) }
 func (x emptyList) IsValid() bool                  { return false }
 
 type dynamicList struct {
 	desc protoreflect.FieldDescriptor
+	typ  *messageType // element type, if a message
 	list []protoreflect.Value
 }
 
@@ -441,7 +586,7 @@ func (x *dynamicList) Truncate(n int) {
 }
 
 func (x *dynamicList) NewElement() protoreflect.Value {
-	return newListEntry(x.desc)
+	return newListEntry(x.desc, x.typ)
 }
 
 func (x *dynamicList) IsValid() bool {
@@ -450,6 +595,7 @@ func (x *dynamicList) IsValid() bool {
 
 type dynamicMap struct {
 	desc protoreflect.FieldDescriptor
+	typ  *messageType // value type, if a message
 	mapv map[any]protoreflect.Value
 }
 
@@ -474,6 +620,9 @@ func (x *dynamicMap) Mutable(k protoreflect.MapKey) protoreflect.Value {
 }
 func (x *dynamicMap) Len() int { return len(x.mapv) }
 func (x *dynamicMap) NewValue() protoreflect.Value {
+	if x.typ != nil {
+		return protoreflect.ValueOfMessage(x.typ.newMessage())
+	}
 	if md := x.desc.MapValue().Message(); md != nil {
 		return protoreflect.ValueOfMessage(NewMessage(md).ProtoReflect())
 	}
@@ -599,7 +748,9 @@ func singularTypeIsValid(fd protoreflect.FieldDescriptor, v protoreflect.Value)
 	return nil
 }
 
-func newListEntry(fd protoreflect.FieldDescriptor) protoreflect.Value {
+// newListEntry returns a new element for a list field.
+// The message type mt is used for message elements if it is non-nil.
+func newListEntry(fd protoreflect.FieldDescriptor, mt *messageType) protoreflect.Value {
 	switch fd.Kind() {
 	case protoreflect.BoolKind:
 		return protoreflect.ValueOfBool(false)
@@ -622,6 +773,9 @@ func newListEntry(fd protoreflect.FieldDescriptor) protoreflect.Value {
 	case protoreflect.BytesKind:
 		return protoreflect.ValueOfBytes(nil)
 	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if mt != nil {
+			return protoreflect.ValueOfMessage(mt.newMessage())
+		}
 		return protoreflect.ValueOfMessage(NewMessage(fd.Message()).ProtoReflect())
 	}
 	panic(errors.New("%v: unknown kind %v", fd.FullName(), fd.Kind()))
@@ -676,7 +830,7 @@ func (xt extensionType) Zero() protoreflect.Value {
 	case xt.desc.Cardinality() == protoreflect.Repeated:
 		return protoreflect.ValueOfList(emptyList{desc: xt.desc})
 	case xt.desc.Message() != nil:
-		return protoreflect.ValueOfMessage(&Message{typ: messageType{xt.desc.Message()}})
+		return protoreflect.ValueOfMessage(loadMessageType(xt.desc.Message()).Zero())
 	default:
 		return xt.desc.Default()
 	}
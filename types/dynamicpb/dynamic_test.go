@@ -5,9 +5,12 @@
 package dynamicpb_test
 
 import (
+	"bytes"
 	"fmt"
 	"testing"
 
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/reflect/protoregistry"
@@ -79,3 +82,168 @@ func (extResolver) RangeExtensionsByMessage(message protoreflect.FullName, f fun
 		return f(dynamicpb.NewExtensionType(xt.TypeDescriptor().Descriptor()))
 	})
 }
+
+func TestSizeCache(t *testing.T) {
+	md := (*testpb.TestAllTypes)(nil).ProtoReflect().Descriptor()
+	fds := md.Fields()
+	int32Field := fds.ByName("optional_int32")
+	nestedField := fds.ByName("optional_nested_message")
+
+	m := dynamicpb.NewMessage(md)
+	m.Set(int32Field, protoreflect.ValueOfInt32(1))
+	nested := m.Mutable(nestedField).Message()
+	nested.Set(nested.Descriptor().Fields().ByName("a"), protoreflect.ValueOfInt32(2))
+
+	cached := proto.MarshalOptions{UseCachedSize: true}
+	size := proto.Size(m)
+	if got := cached.Size(m); got != size {
+		t.Errorf("Size with UseCachedSize = %v, want %v", got, size)
+	}
+
+	// Modifying a submessage does not discard the cached size of its parent.
+	nested.Set(nested.Descriptor().Fields().ByName("a"), protoreflect.ValueOfInt32(1<<20))
+	if got := cached.Size(m); got != size {
+		t.Errorf("Size with UseCachedSize after modifying submessage = %v, want cached size %v", got, size)
+	}
+	size = proto.Size(m)
+	if got := cached.Size(m); got != size {
+		t.Errorf("Size with UseCachedSize after recomputing = %v, want %v", got, size)
+	}
+
+	// Modifying the message itself discards its cached size.
+	m.Clear(int32Field)
+	if got, want := cached.Size(m), proto.Size(m); got != want {
+		t.Errorf("Size with UseCachedSize after Clear = %v, want %v", got, want)
+	}
+
+	// Marshal takes the length of a submessage from its cached size.
+	proto.Size(m)
+	got, err := cached.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := proto.Marshal(m); !bytes.Equal(got, want) {
+		t.Errorf("Marshal with UseCachedSize = %x, want %x", got, want)
+	}
+	nested.Interface().(*dynamicpb.Message).ProtoSetCachedSize(proto.Size(nested.Interface()) + 1)
+	if _, err := cached.Marshal(m); err == nil {
+		t.Errorf("Marshal with UseCachedSize and an incorrect cached submessage size succeeded, want error")
+	}
+}
+
+func TestNewMessageFromFormat(t *testing.T) {
+	md := (*testpb.TestAllExtensions)(nil).ProtoReflect().Descriptor()
+	want := &testpb.TestAllExtensions{}
+	proto.SetExtension(want, testpb.E_OptionalInt32, int32(1))
+	proto.SetExtension(want, testpb.E_OptionalNestedMessage, &testpb.TestAllExtensions_NestedMessage{A: proto.Int32(2)})
+
+	for _, test := range []struct {
+		desc string
+		new  func() (*dynamicpb.Message, error)
+	}{{
+		desc: "JSON",
+		new: func() (*dynamicpb.Message, error) {
+			b := []byte(`{"[goproto.proto.test.optional_int32]": 1, "[goproto.proto.test.optional_nested_message]": {"a": 2}}`)
+			return dynamicpb.NewMessageFromJSON(md, b, protojson.UnmarshalOptions{})
+		},
+	}, {
+		desc: "text",
+		new: func() (*dynamicpb.Message, error) {
+			b := []byte(`[goproto.proto.test.optional_int32]: 1 [goproto.proto.test.optional_nested_message]: {a: 2}`)
+			return dynamicpb.NewMessageFromText(md, b, prototext.UnmarshalOptions{})
+		},
+	}} {
+		t.Run(test.desc, func(t *testing.T) {
+			m, err := test.new()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.Descriptor() != md {
+				t.Errorf("message descriptor = %v, want %v", m.Descriptor().FullName(), md.FullName())
+			}
+			if !proto.Equal(m, want) {
+				t.Errorf("message = %v, want %v", m, want)
+			}
+		})
+	}
+
+	if _, err := dynamicpb.NewMessageFromJSON(md, []byte(`{"unknown": 1}`), protojson.UnmarshalOptions{}); err == nil {
+		t.Errorf("NewMessageFromJSON with unknown field succeeded, want error")
+	}
+}
+
+func TestMessageTypeCache(t *testing.T) {
+	md := (*testpb.TestAllTypes)(nil).ProtoReflect().Descriptor()
+	fds := md.Fields()
+	nestedField := fds.ByName("optional_nested_message")
+	listField := fds.ByName("repeated_nested_message")
+	mapField := fds.ByName("map_string_nested_message")
+	corecursiveField := nestedField.Message().Fields().ByName("corecursive")
+
+	// Messages created for fields share the types of their parent's type.
+	mt := dynamicpb.NewMessageType(md)
+	m := mt.New()
+	nestedType := m.Mutable(nestedField).Message().Type()
+	if got, want := nestedType, mt.(protoreflect.MessageFieldTypes).Message(nestedField.Index()); got != want {
+		t.Errorf("type of nested message = %p, want %p", got, want)
+	}
+	if got := m.Mutable(listField).List().AppendMutable().Message().Type(); got != nestedType {
+		t.Errorf("type of list element = %p, want %p", got, nestedType)
+	}
+	key := protoreflect.ValueOfString("key").MapKey()
+	if got := m.Mutable(mapField).Map().Mutable(key).Message().Type(); got != nestedType {
+		t.Errorf("type of map value = %p, want %p", got, nestedType)
+	}
+	nested := m.Get(nestedField).Message()
+	if got := nested.Mutable(corecursiveField).Message().Type(); got != mt {
+		t.Errorf("type of recursive message = %p, want %p", got, mt)
+	}
+
+	// Types are shared by all messages with the same descriptor.
+	if got := dynamicpb.NewMessageType(md); got != mt {
+		t.Errorf("NewMessageType(md) = %p, want %p", got, mt)
+	}
+	if got := dynamicpb.NewMessage(md).Type(); got != mt {
+		t.Errorf("type of NewMessage(md) = %p, want %p", got, mt)
+	}
+	other := dynamicpb.NewMessageType(nestedField.Message()).New()
+	if got := other.Type(); got != nestedType {
+		t.Errorf("NewMessageType(%v) = %p, want %p", nestedField.Message().FullName(), got, nestedType)
+	}
+	other.Set(other.Descriptor().Fields().ByName("a"), protoreflect.ValueOfInt32(1))
+	m.Set(nestedField, protoreflect.ValueOfMessage(other))
+	want := &testpb.TestAllTypes{
+		OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{A: proto.Int32(1)},
+		RepeatedNestedMessage: []*testpb.TestAllTypes_NestedMessage{{}},
+		MapStringNestedMessage: map[string]*testpb.TestAllTypes_NestedMessage{
+			"key": {},
+		},
+	}
+	if !proto.Equal(m.Interface(), want) {
+		t.Errorf("message = %v, want %v", m, want)
+	}
+
+	if got := dynamicpb.NewMessageType(md).Descriptor(); got != md {
+		t.Errorf("Descriptor() = %v, want %v", got.FullName(), md.FullName())
+	}
+}
+
+func BenchmarkNewMessageUnmarshal(b *testing.B) {
+	md := (*testpb.TestAllTypes)(nil).ProtoReflect().Descriptor()
+	buf, err := proto.Marshal(&testpb.TestAllTypes{
+		OptionalInt32:         proto.Int32(1),
+		OptionalString:        proto.String("string"),
+		OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{A: proto.Int32(2)},
+		RepeatedInt32:         []int32{1, 2, 3},
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		m := dynamicpb.NewMessage(md)
+		if err := proto.Unmarshal(buf, m); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dynamicpb
+
+import (
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// NewMessageFromJSON creates a new message with the provided descriptor
+// and populates it from the JSON encoding in b.
+//
+// If opts.Resolver is nil, extensions and the contents of
+// google.protobuf.Any messages are resolved as dynamic types using
+// the file of the descriptor and all the files it transitively imports.
+func NewMessageFromJSON(desc protoreflect.MessageDescriptor, b []byte, opts protojson.UnmarshalOptions) (*Message, error) {
+	if opts.Resolver == nil {
+		opts.Resolver = NewTypes(filesOf(desc.ParentFile()))
+	}
+	m := NewMessage(desc)
+	if err := opts.Unmarshal(b, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// NewMessageFromText creates a new message with the provided descriptor
+// and populates it from the text encoding in b.
+//
+// If opts.Resolver is nil, extensions and the contents of
+// google.protobuf.Any messages are resolved as dynamic types using
+// the file of the descriptor and all the files it transitively imports.
+func NewMessageFromText(desc protoreflect.MessageDescriptor, b []byte, opts prototext.UnmarshalOptions) (*Message, error) {
+	if opts.Resolver == nil {
+		opts.Resolver = NewTypes(filesOf(desc.ParentFile()))
+	}
+	m := NewMessage(desc)
+	if err := opts.Unmarshal(b, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// filesOf returns a registry of fd and all the files it transitively imports.
+// Placeholder files and files that conflict with one another are omitted.
+func filesOf(fd protoreflect.FileDescriptor) *protoregistry.Files {
+	files := new(protoregistry.Files)
+	seen := make(map[string]bool)
+	var register func(protoreflect.FileDescriptor)
+	register = func(fd protoreflect.FileDescriptor) {
+		if fd == nil || fd.IsPlaceholder() || seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			register(imports.Get(i).FileDescriptor)
+		}
+		files.RegisterFile(fd) // ignore conflicts
+	}
+	register(fd)
+	return files
+}
@@ -39,12 +39,39 @@ type Types struct {
 // NewTypes creates a new Types registry with the provided files.
 // The Files registry is retained, and changes to Files will be reflected in Types.
 // It is not safe to concurrently change the Files while calling Types methods.
+//
+// A Files registry for the contents of a FileDescriptorSet may be
+// created with [google.golang.org/protobuf/reflect/protodesc.NewFiles].
 func NewTypes(f *protoregistry.Files) *Types {
 	return &Types{
 		files: f,
 	}
 }
 
+// NewExtensionTypesFromFile returns an extension type for every extension
+// declared in the file, including extensions declared within messages.
+// The returned types may be registered with a [protoregistry.Types].
+func NewExtensionTypesFromFile(fd protoreflect.FileDescriptor) []protoreflect.ExtensionType {
+	xts := appendExtensionTypes(nil, fd.Extensions())
+	return appendExtensionTypesInMessages(xts, fd.Messages())
+}
+
+func appendExtensionTypesInMessages(xts []protoreflect.ExtensionType, mds protoreflect.MessageDescriptors) []protoreflect.ExtensionType {
+	for i := 0; i < mds.Len(); i++ {
+		md := mds.Get(i)
+		xts = appendExtensionTypes(xts, md.Extensions())
+		xts = appendExtensionTypesInMessages(xts, md.Messages())
+	}
+	return xts
+}
+
+func appendExtensionTypes(xts []protoreflect.ExtensionType, xds protoreflect.ExtensionDescriptors) []protoreflect.ExtensionType {
+	for i := 0; i < xds.Len(); i++ {
+		xts = append(xts, NewExtensionType(xds.Get(i)))
+	}
+	return xts
+}
+
 // FindEnumByName looks up an enum by its full name;
 // e.g., "google.protobuf.Field.Kind".
 //
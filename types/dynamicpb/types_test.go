@@ -5,9 +5,13 @@
 package dynamicpb_test
 
 import (
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
 
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/reflect/protoregistry"
 	"google.golang.org/protobuf/types/descriptorpb"
@@ -172,3 +176,71 @@ func TestDynamicTypesFilesChangeAfterCreation(t *testing.T) {
 		t.Errorf("types.FindExtensionByNumber(%q, %v) = %v, want nil", message, number, err)
 	}
 }
+
+func TestDynamicTypesFromFileSet(t *testing.T) {
+	fd := registrypb.File_internal_testprotos_registry_test_proto
+	set := &descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{protodesc.ToFileDescriptorProto(fd)},
+	}
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		t.Fatalf("protodesc.NewFiles() = %v", err)
+	}
+	types := dynamicpb.NewTypes(files)
+	const messageName = "testprotos.Message1"
+	mt, err := types.FindMessageByName(messageName)
+	if err != nil {
+		t.Fatalf("types.FindMessageByName(%q) = %v", messageName, err)
+	}
+
+	// Tag 11 (string_field) with the value "hello" and
+	// tag 23 (Message4.string_field) with the value "world".
+	b := []byte("\x5a\x05hello\xba\x01\x05world")
+	m := mt.New().Interface()
+	if err := (proto.UnmarshalOptions{Resolver: types}).Unmarshal(b, m); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if got := m.ProtoReflect().GetUnknown(); len(got) > 0 {
+		t.Errorf("Unmarshal() left unknown fields: %x", got)
+	}
+	var got []string
+	m.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		got = append(got, string(fd.FullName())+"="+v.String())
+		return true
+	})
+	sort.Strings(got)
+	want := []string{"testprotos.Message4.string_field=world", "testprotos.string_field=hello"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("populated fields = %v, want %v", got, want)
+	}
+}
+
+func TestNewExtensionTypesFromFile(t *testing.T) {
+	fd := registrypb.File_internal_testprotos_registry_test_proto
+	var got []string
+	for _, xt := range dynamicpb.NewExtensionTypesFromFile(fd) {
+		got = append(got, string(xt.TypeDescriptor().FullName()))
+	}
+	sort.Strings(got)
+	want := []string{
+		"testprotos.Message4.enum_field",
+		"testprotos.Message4.message_field",
+		"testprotos.Message4.string_field",
+		"testprotos.enum_field",
+		"testprotos.message_field",
+		"testprotos.string_field",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewExtensionTypesFromFile() = %v, want %v", got, want)
+	}
+
+	var types protoregistry.Types
+	for _, xt := range dynamicpb.NewExtensionTypesFromFile(fd) {
+		if err := types.RegisterExtension(xt); err != nil {
+			t.Fatalf("RegisterExtension(%v) = %v", xt.TypeDescriptor().FullName(), err)
+		}
+	}
+	if _, err := types.FindExtensionByNumber("testprotos.Message1", 23); err != nil {
+		t.Errorf("types.FindExtensionByNumber(%q, 23) = %v", "testprotos.Message1", err)
+	}
+}
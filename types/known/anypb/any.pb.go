@@ -47,6 +47,11 @@
 //	}
 //	... // make use of any
 //
+// To control how the message value is serialized, such as to produce
+// deterministic output, use NewWithOptions:
+//
+//	any, err := anypb.NewWithOptions(m, proto.MarshalOptions{Deterministic: true})
+//
 // # Unmarshaling an Any
 //
 // With a populated Any message, the underlying message can be serialized into
@@ -256,12 +261,27 @@ func New(src proto.Message) (*Any, error) {
 	return dst, nil
 }
 
+// NewWithOptions marshals src into a new Any instance
+// using the provided marshal options.
+//
+// This permits control over the serialization of the underlying message,
+// such as setting Deterministic for systems that sign the encoded value.
+func NewWithOptions(src proto.Message, opts proto.MarshalOptions) (*Any, error) {
+	dst := new(Any)
+	if err := MarshalFrom(dst, src, opts); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
 // MarshalFrom marshals src into dst as the underlying message
 // using the provided marshal options.
 //
+// The type URL is formed from the prefix configured for
+// protoregistry.GlobalTypes, which is "type.googleapis.com/" by default.
+//
 // If no options are specified, call dst.MarshalFrom instead.
 func MarshalFrom(dst *Any, src proto.Message, opts proto.MarshalOptions) error {
-	const urlPrefix = "type.googleapis.com/"
 	if src == nil {
 		return protoimpl.X.NewError("invalid nil source message")
 	}
@@ -269,7 +289,7 @@ func MarshalFrom(dst *Any, src proto.Message, opts proto.MarshalOptions) error {
 	if err != nil {
 		return err
 	}
-	dst.TypeUrl = urlPrefix + string(src.ProtoReflect().Descriptor().FullName())
+	dst.TypeUrl = protoregistry.GlobalTypes.TypeURLPrefix() + string(src.ProtoReflect().Descriptor().FullName())
 	dst.Value = b
 	return nil
 }
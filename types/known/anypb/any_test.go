@@ -10,6 +10,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
 	"google.golang.org/protobuf/testing/protocmp"
 
 	testpb "google.golang.org/protobuf/internal/testprotos/test"
@@ -182,3 +183,52 @@ func TestRoundtrip(t *testing.T) {
 		}
 	}
 }
+
+func TestNewTypeURLPrefix(t *testing.T) {
+	protoregistry.GlobalTypes.SetTypeURLPrefix("types.example.com/")
+	defer protoregistry.GlobalTypes.SetTypeURLPrefix("")
+
+	m, err := apb.New(&epb.Empty{})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if want := "types.example.com/google.protobuf.Empty"; m.GetTypeUrl() != want {
+		t.Errorf("New().TypeUrl = %q, want %q", m.GetTypeUrl(), want)
+	}
+	if _, err := m.UnmarshalNew(); err != nil {
+		t.Errorf("UnmarshalNew() error: %v", err)
+	}
+}
+
+func TestNewWithOptions(t *testing.T) {
+	src := &testpb.TestAllTypes{
+		MapStringString: map[string]string{"a": "1", "b": "2", "c": "3", "d": "4"},
+	}
+	opts := proto.MarshalOptions{Deterministic: true}
+	m, err := apb.NewWithOptions(src, opts)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error: %v", err)
+	}
+	want, err := opts.Marshal(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, m.GetValue()); diff != "" {
+		t.Errorf("NewWithOptions().Value mismatch (-want +got):\n%s", diff)
+	}
+	if want := "type.googleapis.com/goproto.proto.test.TestAllTypes"; m.GetTypeUrl() != want {
+		t.Errorf("NewWithOptions().TypeUrl = %q, want %q", m.GetTypeUrl(), want)
+	}
+
+	// Required fields are checked unless AllowPartial is set.
+	partial := &testpb.TestRequired{}
+	if _, err := apb.NewWithOptions(partial, proto.MarshalOptions{}); err == nil {
+		t.Errorf("NewWithOptions() with missing required field succeeded, want error")
+	}
+	if _, err := apb.NewWithOptions(partial, proto.MarshalOptions{AllowPartial: true}); err != nil {
+		t.Errorf("NewWithOptions() with AllowPartial error: %v", err)
+	}
+	if _, err := apb.NewWithOptions(nil, opts); err == nil {
+		t.Errorf("NewWithOptions(nil) succeeded, want error")
+	}
+}
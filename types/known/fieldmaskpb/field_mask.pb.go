@@ -73,6 +73,28 @@
 // IsValid needs to be passed the target message type as an input since the
 // FieldMask message itself does not store the message type that the set of paths
 // are for.
+//
+// The Validate method performs the same check against a message descriptor,
+// but further accepts paths that select the values of a map by key, such as
+// "labels.env", or the elements of a repeated field or map by the wildcard "*",
+// such as "books.*.title". The Canonicalize method converts such a mask to
+// the shortest set of paths that selects the same fields.
+//
+// # Updating messages with a FieldMask
+//
+// The Apply method copies the fields selected by a FieldMask from one message
+// into another, which is the typical way to implement an update operation:
+//
+//	if err := fm.Apply(stored, update); err != nil {
+//		... // handle error
+//	}
+//
+// The Prune method clears all fields of a message that are not selected
+// by the FieldMask:
+//
+//	if err := fm.Prune(m); err != nil {
+//		... // handle error
+//	}
 package fieldmaskpb
 
 import (
@@ -81,6 +103,7 @@ import (
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
 	sort "sort"
+	strconv "strconv"
 	strings "strings"
 	sync "sync"
 	unsafe "unsafe"
@@ -351,6 +374,29 @@ func (x *FieldMask) IsValid(m proto.Message) bool {
 	return x != nil && numValidPaths(m, paths) == len(paths)
 }
 
+// Validate reports an error if any of the paths are not syntactically valid
+// or do not refer to fields reachable from the message type md.
+//
+// Unlike IsValid, Validate also accepts paths that continue past a map or
+// repeated field. The path component following a map field must be either
+// a key of the map or the wildcard "*", which selects all its values.
+// The path component following a repeated field must be the wildcard "*",
+// which selects all its elements. If the selected values are messages, the
+// remainder of the path may refer to fields within them.
+// For example, "labels.env" selects the value for the key "env" of the map
+// field "labels", and "books.*.title" selects the "title" field of every
+// element of the repeated field "books".
+//
+// Such paths are not supported by Apply and Prune.
+func (x *FieldMask) Validate(md protoreflect.MessageDescriptor) error {
+	for _, path := range x.GetPaths() {
+		if !validatePath(md, path) {
+			return protoimpl.X.NewError("invalid path %q for message %q", path, md.FullName())
+		}
+	}
+	return nil
+}
+
 // Append appends a list of paths to the mask and verifies that each one
 // is valid according to the specified message type.
 // An invalid path is not appended and breaks insertion of subsequent paths.
@@ -365,6 +411,140 @@ func (x *FieldMask) Append(m proto.Message, paths ...string) error {
 	return nil
 }
 
+// Apply copies the fields selected by the mask from src into dst,
+// which must be messages with the same descriptor.
+//
+// For every path in the mask, the field it refers to in dst is replaced by
+// a deep copy of the corresponding field in src. If that field is not
+// populated in src, it is cleared in dst. Intermediate messages along the
+// path are created in dst as needed. Repeated and map fields are always
+// replaced in their entirety, since a path cannot select individual
+// list elements or map entries.
+//
+// It reports an error if any of the paths are invalid for the message type.
+func (x *FieldMask) Apply(dst, src proto.Message) error {
+	dm, sm := dst.ProtoReflect(), src.ProtoReflect()
+	if dm.Descriptor() != sm.Descriptor() {
+		return protoimpl.X.NewError("mismatching message types %q and %q", dm.Descriptor().FullName(), sm.Descriptor().FullName())
+	}
+	if err := x.checkValid(dst); err != nil {
+		return err
+	}
+	for _, path := range normalizePaths(append([]string(nil), x.GetPaths()...)) {
+		applyPath(dm, sm, path)
+	}
+	return nil
+}
+
+func applyPath(dst, src protoreflect.Message, path string) {
+	for {
+		var field string
+		if i := strings.IndexByte(path, '.'); i >= 0 {
+			field, path = path[:i], path[i+1:]
+		} else {
+			field, path = path, ""
+		}
+		fd := fieldByName(dst.Descriptor(), field)
+		if path == "" {
+			dst.Clear(fd)
+			if src.Has(fd) {
+				copyField(dst, src, fd)
+			}
+			return
+		}
+		if !src.Has(fd) && !dst.Has(fd) {
+			return
+		}
+		dst, src = dst.Mutable(fd).Message(), src.Get(fd).Message()
+	}
+}
+
+// copyField sets the field in dst to a deep copy of the populated field in src.
+func copyField(dst, src protoreflect.Message, fd protoreflect.FieldDescriptor) {
+	switch v := src.Get(fd); {
+	case fd.IsList():
+		dl, sl := dst.Mutable(fd).List(), v.List()
+		for i := 0; i < sl.Len(); i++ {
+			dl.Append(copyValue(dl.NewElement(), sl.Get(i), fd))
+		}
+	case fd.IsMap():
+		dmap, vd := dst.Mutable(fd).Map(), fd.MapValue()
+		v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			dmap.Set(k, copyValue(dmap.NewValue(), v, vd))
+			return true
+		})
+	default:
+		dst.Set(fd, copyValue(dst.NewField(fd), v, fd))
+	}
+}
+
+// copyValue returns a deep copy of the singular value v,
+// where dv is a new value of the same type.
+func copyValue(dv, v protoreflect.Value, fd protoreflect.FieldDescriptor) protoreflect.Value {
+	switch {
+	case fd.Message() != nil:
+		proto.Merge(dv.Message().Interface(), v.Message().Interface())
+		return dv
+	case fd.Kind() == protoreflect.BytesKind:
+		return protoreflect.ValueOfBytes(append([]byte{}, v.Bytes()...))
+	default:
+		return v
+	}
+}
+
+// Prune clears all fields in m that are not selected by the mask,
+// including unknown fields and fields of nested messages.
+// It reports an error if any of the paths are invalid for the message type.
+func (x *FieldMask) Prune(m proto.Message) error {
+	if err := x.checkValid(m); err != nil {
+		return err
+	}
+	prunePaths(m.ProtoReflect(), normalizePaths(append([]string(nil), x.GetPaths()...)))
+	return nil
+}
+
+func prunePaths(m protoreflect.Message, paths []string) {
+	m.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if fd.IsExtension() {
+			m.Clear(fd) // paths never select extension fields
+			return true
+		}
+		name := string(fd.Name())
+		if fd.Kind() == protoreflect.GroupKind {
+			name = string(fd.Message().Name())
+		}
+		var subpaths []string
+		for _, path := range paths {
+			if path == name {
+				return true // keep the entire field
+			}
+			if hasPathPrefix(path, name) {
+				subpaths = append(subpaths, path[len(name)+len("."):])
+			}
+		}
+		if len(subpaths) == 0 {
+			m.Clear(fd)
+		} else {
+			prunePaths(m.Mutable(fd).Message(), subpaths)
+		}
+		return true
+	})
+	if len(m.GetUnknown()) > 0 {
+		m.SetUnknown(nil)
+	}
+}
+
+// checkValid reports an error if any of the paths are invalid for the
+// specified message type.
+func (x *FieldMask) checkValid(m proto.Message) error {
+	paths := x.GetPaths()
+	if n := numValidPaths(m, paths); n < len(paths) {
+		name := m.ProtoReflect().Descriptor().FullName()
+		return protoimpl.X.NewError("invalid path %q for message %q", paths[n], name)
+	}
+	return nil
+}
+
 func numValidPaths(m proto.Message, paths []string) int {
 	md0 := m.ProtoReflect().Descriptor()
 	for i, path := range paths {
@@ -374,16 +554,7 @@ func numValidPaths(m proto.Message, paths []string) int {
 			if md == nil {
 				return false // not within a message
 			}
-			fd := md.Fields().ByName(protoreflect.Name(field))
-			// The real field name of a group is the message name.
-			if fd == nil {
-				gd := md.Fields().ByName(protoreflect.Name(strings.ToLower(field)))
-				if gd != nil && gd.Kind() == protoreflect.GroupKind && string(gd.Message().Name()) == field {
-					fd = gd
-				}
-			} else if fd.Kind() == protoreflect.GroupKind && string(fd.Message().Name()) != field {
-				fd = nil
-			}
+			fd := fieldByName(md, field)
 			if fd == nil {
 				return false // message has does not have this field
 			}
@@ -404,6 +575,79 @@ func numValidPaths(m proto.Message, paths []string) int {
 	return len(paths)
 }
 
+// fieldByName returns the field in md that is named by the path component,
+// or nil if there is no such field.
+func fieldByName(md protoreflect.MessageDescriptor, field string) protoreflect.FieldDescriptor {
+	fd := md.Fields().ByName(protoreflect.Name(field))
+	// The real field name of a group is the message name.
+	if fd == nil {
+		gd := md.Fields().ByName(protoreflect.Name(strings.ToLower(field)))
+		if gd != nil && gd.Kind() == protoreflect.GroupKind && string(gd.Message().Name()) == field {
+			fd = gd
+		}
+	} else if fd.Kind() == protoreflect.GroupKind && string(fd.Message().Name()) != field {
+		fd = nil
+	}
+	return fd
+}
+
+// validatePath reports whether the path refers to a field reachable from md,
+// where the component following a map field may also be a map key or
+// a wildcard, and the component following a repeated field must be a wildcard.
+func validatePath(md protoreflect.MessageDescriptor, path string) bool {
+	var fd protoreflect.FieldDescriptor // field named by the previous component
+	return rangeFields(path, func(field string) bool {
+		switch {
+		case fd != nil && fd.IsMap():
+			if field != "*" && !isValidMapKey(fd.MapKey(), field) {
+				return false
+			}
+			md, fd = fd.MapValue().Message(), nil
+			return true
+		case fd != nil && fd.IsList():
+			if field != "*" {
+				return false
+			}
+			md, fd = fd.Message(), nil
+			return true
+		}
+
+		// Search the field within the message.
+		if md == nil {
+			return false // not within a message
+		}
+		fd = fieldByName(md, field)
+		if fd == nil {
+			return false // message does not have this field
+		}
+		md = fd.Message() // may be nil
+		return true
+	})
+}
+
+// isValidMapKey reports whether the path component is a valid key
+// for a map with the specified key field.
+func isValidMapKey(fd protoreflect.FieldDescriptor, field string) bool {
+	var err error
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return field != ""
+	case protoreflect.BoolKind:
+		return field == "true" || field == "false"
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		_, err = strconv.ParseInt(field, 10, 32)
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		_, err = strconv.ParseInt(field, 10, 64)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		_, err = strconv.ParseUint(field, 10, 32)
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		_, err = strconv.ParseUint(field, 10, 64)
+	default:
+		return false
+	}
+	return err == nil
+}
+
 // Normalize converts the mask to its canonical form where all paths are sorted
 // and redundant paths are removed.
 func (x *FieldMask) Normalize() {
@@ -426,12 +670,63 @@ func normalizePaths(paths []string) []string {
 	return out
 }
 
+// Canonicalize converts the mask to the shortest set of paths that selects
+// the same fields. In addition to the conversion performed by Normalize,
+// trailing wildcards are removed, since a path ending in "*" selects the
+// same values as the path without it, and paths are removed if a path with
+// a wildcard in place of a map key or another wildcard selects a superset.
+// See Validate for the meaning of wildcards.
+func (x *FieldMask) Canonicalize() {
+	for i, path := range x.Paths {
+		for strings.HasSuffix(path, ".*") {
+			path = strings.TrimSuffix(path, ".*")
+		}
+		x.Paths[i] = path
+	}
+	paths := normalizePaths(x.Paths)
+
+	// Elide any path that is matched by another path with wildcards.
+	out := make([]string, 0, len(paths))
+	for _, path := range paths {
+		var covered bool
+		for _, prefix := range paths {
+			if prefix != path && hasWildcardPrefix(path, prefix) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			out = append(out, path)
+		}
+	}
+	x.Paths = out
+}
+
 // hasPathPrefix is like strings.HasPrefix, but further checks for either
 // an exact matche or that the prefix is delimited by a dot.
 func hasPathPrefix(path, prefix string) bool {
 	return strings.HasPrefix(path, prefix) && (len(path) == len(prefix) || path[len(prefix)] == '.')
 }
 
+// hasWildcardPrefix is like hasPathPrefix, but further treats a wildcard
+// component in the prefix as matching any component in the path.
+func hasWildcardPrefix(path, prefix string) bool {
+	for {
+		field, rest, _ := strings.Cut(path, ".")
+		prefixField, prefixRest, more := strings.Cut(prefix, ".")
+		if field != prefixField && prefixField != "*" {
+			return false
+		}
+		if !more {
+			return true
+		}
+		if rest == "" {
+			return false
+		}
+		path, prefix = rest, prefixRest
+	}
+}
+
 // lessPath is a lexicographical comparison where dot is specially treated
 // as the smallest symbol.
 func lessPath(x, y string) bool {
@@ -465,6 +760,51 @@ func rangeFields(path string, f func(field string) bool) bool {
 	}
 }
 
+// CamelCasePath converts a path from the snake_case field names used
+// by the FieldMask message to the lowerCamelCase field names used
+// by its JSON representation. For example, "foo_bar.baz" is converted
+// to "fooBar.baz". It reports an error if the conversion is not reversible
+// by SnakeCasePath, such as for "foo__bar" or "fooBar".
+func CamelCasePath(path string) (string, error) {
+	var b []byte
+	var wasUnderscore bool
+	for i := 0; i < len(path); i++ { // proto identifiers are always ASCII
+		c := path[i]
+		if c != '_' {
+			if wasUnderscore && 'a' <= c && c <= 'z' {
+				c -= 'a' - 'A' // convert to uppercase
+			}
+			b = append(b, c)
+		}
+		wasUnderscore = c == '_'
+	}
+	if s, err := SnakeCasePath(string(b)); err != nil || s != path {
+		return "", protoimpl.X.NewError("irreversible path %q", path)
+	}
+	return string(b), nil
+}
+
+// SnakeCasePath converts a path from the lowerCamelCase field names used
+// by the JSON representation of the FieldMask message to the snake_case
+// field names used by the message. For example, "fooBar.baz" is converted
+// to "foo_bar.baz". It reports an error if the path contains an underscore,
+// which is not permitted in the JSON representation.
+func SnakeCasePath(path string) (string, error) {
+	var b []byte
+	for i := 0; i < len(path); i++ { // proto identifiers are always ASCII
+		c := path[i]
+		if c == '_' {
+			return "", protoimpl.X.NewError("invalid path %q", path)
+		}
+		if 'A' <= c && c <= 'Z' {
+			b = append(b, '_')
+			c += 'a' - 'A' // convert to lowercase
+		}
+		b = append(b, c)
+	}
+	return string(b), nil
+}
+
 func (x *FieldMask) Reset() {
 	*x = FieldMask{}
 	mi := &file_google_protobuf_field_mask_proto_msgTypes[0]
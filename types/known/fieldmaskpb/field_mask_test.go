@@ -10,6 +10,8 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/testing/protopack"
 
 	testpb "google.golang.org/protobuf/internal/testprotos/test"
 	fmpb "google.golang.org/protobuf/types/known/fieldmaskpb"
@@ -196,6 +198,50 @@ func TestNormalize(t *testing.T) {
 	}
 }
 
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		in   []string
+		want []string
+	}{{
+		in:   []string{},
+		want: []string{},
+	}, {
+		in:   []string{"foo.bar", "foo", "foo.baz"},
+		want: []string{"foo"},
+	}, {
+		in:   []string{"foo.*", "foo.bar"},
+		want: []string{"foo"},
+	}, {
+		in:   []string{"foo.*.*", "bar"},
+		want: []string{"bar", "foo"},
+	}, {
+		in:   []string{"foo.*.a", "foo.key.a", "foo.key.b", "foo.key.a.b"},
+		want: []string{"foo.key.b", "foo.*.a"},
+	}, {
+		in:   []string{"foo.*.a.*.b", "foo.key.a.key.b", "foo.key.a.key.c", "foo.*.a.key"},
+		want: []string{"foo.*.a.key", "foo.*.a.*.b"},
+	}, {
+		in:   []string{"foo.*.a", "foo.key"},
+		want: []string{"foo.key", "foo.*.a"},
+	}, {
+		in:   []string{"foo.*", "foo.*.a"},
+		want: []string{"foo"},
+	}}
+
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			mask := &fmpb.FieldMask{
+				Paths: append([]string(nil), tt.in...),
+			}
+			mask.Canonicalize()
+			got := mask.GetPaths()
+			if diff := cmp.Diff(tt.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Canonicalize(%q) mismatch (-want +got):\n%s", tt.in, diff)
+			}
+		})
+	}
+}
+
 func TestIsValid(t *testing.T) {
 	tests := []struct {
 		message proto.Message
@@ -336,3 +382,275 @@ func TestIsValid(t *testing.T) {
 		})
 	}
 }
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		paths []string
+		want  bool
+	}{{
+		paths: nil,
+		want:  true,
+	}, {
+		paths: []string{
+			"optional_int32",
+			"OptionalGroup.a",
+			"optional_nested_message.corecursive.optional_int32",
+			"repeated_int32",
+			"repeated_int32.*",
+			"repeated_nested_message.*",
+			"repeated_nested_message.*.a",
+			"repeated_nested_message.*.corecursive.repeated_nested_message.*.a",
+			"RepeatedGroup.*.a",
+			"map_string_nested_message",
+			"map_string_nested_message.*",
+			"map_string_nested_message.*.a",
+			"map_string_nested_message.key",
+			"map_string_nested_message.a.corecursive",
+			"map_string_string.key",
+			"map_int32_int32.-1",
+			"map_int64_int64.-9223372036854775808",
+			"map_uint32_uint32.4294967295",
+			"map_uint64_uint64.18446744073709551615",
+			"map_sint32_sint32.1",
+			"map_fixed64_fixed64.1",
+			"map_bool_bool.true",
+			"map_bool_bool.false",
+			"map_bool_bool.*",
+		},
+		want: true,
+	}, {
+		paths: []string{""},
+		want:  false,
+	}, {
+		paths: []string{"no_such_field"},
+		want:  false,
+	}, {
+		paths: []string{"*"},
+		want:  false,
+	}, {
+		paths: []string{"optional_nested_message.*"},
+		want:  false,
+	}, {
+		paths: []string{"optional_int32.*"},
+		want:  false,
+	}, {
+		paths: []string{"repeated_nested_message.a"},
+		want:  false,
+	}, {
+		paths: []string{"repeated_nested_message.0"},
+		want:  false,
+	}, {
+		paths: []string{"repeated_nested_message.*.*"},
+		want:  false,
+	}, {
+		paths: []string{"repeated_int32.*.a"},
+		want:  false,
+	}, {
+		paths: []string{"map_string_string."},
+		want:  false,
+	}, {
+		paths: []string{"map_string_string.key.a"},
+		want:  false,
+	}, {
+		paths: []string{"map_string_nested_message.key.b"},
+		want:  false,
+	}, {
+		paths: []string{"map_int32_int32.a"},
+		want:  false,
+	}, {
+		paths: []string{"map_int32_int32.2147483648"},
+		want:  false,
+	}, {
+		paths: []string{"map_uint32_uint32.-1"},
+		want:  false,
+	}, {
+		paths: []string{"map_bool_bool.1"},
+		want:  false,
+	}, {
+		paths: []string{"optional_int32", "no_such_field"},
+		want:  false,
+	}}
+
+	md := (*testpb.TestAllTypes)(nil).ProtoReflect().Descriptor()
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			mask := &fmpb.FieldMask{Paths: tt.paths}
+			err := mask.Validate(md)
+			if got := err == nil; got != tt.want {
+				t.Errorf("Validate(%q) = %v, want valid %v", tt.paths, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		desc    string
+		paths   []string
+		dst     *testpb.TestAllTypes
+		src     *testpb.TestAllTypes
+		want    *testpb.TestAllTypes
+		wantErr bool
+	}{{
+		desc:  "replace scalars and lists",
+		paths: []string{"optional_int32", "optional_string", "repeated_int32", "map_int32_int32"},
+		dst: &testpb.TestAllTypes{
+			OptionalInt32:  proto.Int32(1),
+			OptionalString: proto.String("keep?"),
+			OptionalInt64:  proto.Int64(2),
+			RepeatedInt32:  []int32{1, 2, 3},
+			MapInt32Int32:  map[int32]int32{1: 1},
+		},
+		src: &testpb.TestAllTypes{
+			OptionalInt32: proto.Int32(10),
+			OptionalInt64: proto.Int64(20),
+			RepeatedInt32: []int32{4},
+			MapInt32Int32: map[int32]int32{2: 2},
+		},
+		want: &testpb.TestAllTypes{
+			OptionalInt32: proto.Int32(10),
+			OptionalInt64: proto.Int64(2),
+			RepeatedInt32: []int32{4},
+			MapInt32Int32: map[int32]int32{2: 2},
+		},
+	}, {
+		desc:  "nested paths",
+		paths: []string{"optional_nested_message.corecursive.optional_int32", "OptionalGroup.a"},
+		dst: &testpb.TestAllTypes{
+			OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{
+				A: proto.Int32(1),
+			},
+		},
+		src: &testpb.TestAllTypes{
+			OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{
+				A:           proto.Int32(2),
+				Corecursive: &testpb.TestAllTypes{OptionalInt32: proto.Int32(3), OptionalInt64: proto.Int64(4)},
+			},
+		},
+		want: &testpb.TestAllTypes{
+			OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{
+				A:           proto.Int32(1),
+				Corecursive: &testpb.TestAllTypes{OptionalInt32: proto.Int32(3)},
+			},
+		},
+	}, {
+		desc:  "replace whole message",
+		paths: []string{"optional_nested_message"},
+		dst: &testpb.TestAllTypes{
+			OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{A: proto.Int32(1)},
+		},
+		src: &testpb.TestAllTypes{
+			OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{
+				Corecursive: &testpb.TestAllTypes{OptionalInt32: proto.Int32(3)},
+			},
+		},
+		want: &testpb.TestAllTypes{
+			OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{
+				Corecursive: &testpb.TestAllTypes{OptionalInt32: proto.Int32(3)},
+			},
+		},
+	}, {
+		desc:    "invalid path",
+		paths:   []string{"optional_int32", "repeated_nested_message.a"},
+		dst:     &testpb.TestAllTypes{},
+		src:     &testpb.TestAllTypes{OptionalInt32: proto.Int32(1)},
+		want:    &testpb.TestAllTypes{},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			src := proto.Clone(tt.src)
+			err := (&fmpb.FieldMask{Paths: tt.paths}).Apply(tt.dst, src)
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Fatalf("Apply() error = %v, want error %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, tt.dst, protocmp.Transform()); diff != "" {
+				t.Errorf("Apply() mismatch (-want +got):\n%s", diff)
+			}
+			if !proto.Equal(src, tt.src) {
+				t.Errorf("Apply() modified the source message")
+			}
+		})
+	}
+}
+
+func TestPrune(t *testing.T) {
+	m := &testpb.TestAllTypes{
+		OptionalInt32:  proto.Int32(1),
+		OptionalString: proto.String("s"),
+		RepeatedInt32:  []int32{1, 2},
+		OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{
+			A: proto.Int32(2),
+			Corecursive: &testpb.TestAllTypes{
+				OptionalInt32: proto.Int32(3),
+				OptionalInt64: proto.Int64(4),
+			},
+		},
+		Optionalgroup: &testpb.TestAllTypes_OptionalGroup{A: proto.Int32(5)},
+	}
+	m.ProtoReflect().SetUnknown(protopack.Message{
+		protopack.Tag{Number: 5000, Type: protopack.VarintType}, protopack.Varint(1),
+	}.Marshal())
+
+	mask := &fmpb.FieldMask{Paths: []string{"optional_int32", "optional_nested_message.corecursive.optional_int64", "OptionalGroup"}}
+	if err := mask.Prune(m); err != nil {
+		t.Fatalf("Prune() error: %v", err)
+	}
+	want := &testpb.TestAllTypes{
+		OptionalInt32: proto.Int32(1),
+		OptionalNestedMessage: &testpb.TestAllTypes_NestedMessage{
+			Corecursive: &testpb.TestAllTypes{
+				OptionalInt64: proto.Int64(4),
+			},
+		},
+		Optionalgroup: &testpb.TestAllTypes_OptionalGroup{A: proto.Int32(5)},
+	}
+	if diff := cmp.Diff(want, m, protocmp.Transform()); diff != "" {
+		t.Errorf("Prune() mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := (&fmpb.FieldMask{Paths: []string{"<INVALID>"}}).Prune(m); err == nil {
+		t.Errorf("Prune() with invalid path succeeded, want error")
+	}
+}
+
+func TestCaseConversion(t *testing.T) {
+	tests := []struct {
+		snake, camel string
+		wantError    bool
+	}{
+		{snake: "", camel: ""},
+		{snake: "foo", camel: "foo"},
+		{snake: "foo_bar", camel: "fooBar"},
+		{snake: "foo_bar.baz_qux", camel: "fooBar.bazQux"},
+		{snake: "OptionalGroup.a", camel: "OptionalGroup.a", wantError: true},
+		{snake: "foo_bar_", camel: "fooBar", wantError: true},
+		{snake: "foo__bar", camel: "fooBar", wantError: true},
+		{snake: "foo_1", camel: "foo1", wantError: true},
+		{snake: "fooBar", camel: "fooBar", wantError: true},
+	}
+
+	for _, tt := range tests {
+		got, err := fmpb.CamelCasePath(tt.snake)
+		switch {
+		case tt.wantError && err == nil:
+			t.Errorf("CamelCasePath(%q) = %q, want error", tt.snake, got)
+		case !tt.wantError && err != nil:
+			t.Errorf("CamelCasePath(%q) error: %v", tt.snake, err)
+		case !tt.wantError && got != tt.camel:
+			t.Errorf("CamelCasePath(%q) = %q, want %q", tt.snake, got, tt.camel)
+		}
+		if tt.wantError {
+			continue
+		}
+		got, err = fmpb.SnakeCasePath(tt.camel)
+		if err != nil || got != tt.snake {
+			t.Errorf("SnakeCasePath(%q) = %q, %v, want %q", tt.camel, got, err, tt.snake)
+		}
+	}
+
+	if got, err := fmpb.SnakeCasePath("foo_bar"); err == nil {
+		t.Errorf("SnakeCasePath(%q) = %q, want error", "foo_bar", got)
+	}
+}
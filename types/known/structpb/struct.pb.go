@@ -126,7 +126,9 @@ import (
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	math "math"
 	reflect "reflect"
+	strconv "strconv"
 	sync "sync"
+	time "time"
 	utf8 "unicode/utf8"
 	unsafe "unsafe"
 )
@@ -292,11 +294,18 @@ type Value struct {
 	sizeCache     protoimpl.SizeCache
 }
 
+// Valuer is implemented by types that can convert themselves to a Value.
+// NewValue uses the ToValue method of values that implement it.
+type Valuer interface {
+	ToValue() (*Value, error)
+}
+
 // NewValue constructs a Value from a general-purpose Go interface.
 //
 //	╔═══════════════════════════════════════╤════════════════════════════════════════════╗
 //	║ Go type                               │ Conversion                                 ║
 //	╠═══════════════════════════════════════╪════════════════════════════════════════════╣
+//	║ Valuer                                │ converted by calling ToValue               ║
 //	║ nil                                   │ stored as NullValue                        ║
 //	║ bool                                  │ stored as BoolValue                        ║
 //	║ int, int8, int16, int32, int64        │ stored as NumberValue                      ║
@@ -305,14 +314,20 @@ type Value struct {
 //	║ json.Number                           │ stored as NumberValue                      ║
 //	║ string                                │ stored as StringValue; must be valid UTF-8 ║
 //	║ []byte                                │ stored as StringValue; base64-encoded      ║
+//	║ time.Time                             │ stored as StringValue; RFC 3339 format     ║
 //	║ map[string]any                        │ stored as StructValue                      ║
 //	║ []any                                 │ stored as ListValue                        ║
 //	╚═══════════════════════════════════════╧════════════════════════════════════════════╝
 //
 // When converting an int64 or uint64 to a NumberValue, numeric precision loss
 // is possible since they are stored as a float64.
+//
+// Values of other types, including named types with one of the
+// underlying types above, are reported as an error.
 func NewValue(v any) (*Value, error) {
 	switch v := v.(type) {
+	case Valuer:
+		return v.ToValue()
 	case nil:
 		return NewNullValue(), nil
 	case bool:
@@ -355,6 +370,8 @@ func NewValue(v any) (*Value, error) {
 	case []byte:
 		s := base64.StdEncoding.EncodeToString(v)
 		return NewStringValue(s), nil
+	case time.Time:
+		return NewStringValue(v.Format(time.RFC3339Nano)), nil
 	case map[string]any:
 		v2, err := NewStruct(v)
 		if err != nil {
@@ -368,10 +385,33 @@ func NewValue(v any) (*Value, error) {
 		}
 		return NewListValue(v2), nil
 	default:
-		return nil, protoimpl.X.NewError("invalid type: %T", v)
+		return nil, invalidValueType(v)
 	}
 }
 
+// invalidValueType returns an error for a value of a type unsupported
+// by NewValue, suggesting a conversion to a supported type if possible.
+func invalidValueType(v any) error {
+	var want string
+	switch reflect.TypeOf(v).Kind() {
+	case reflect.Bool:
+		want = "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		want = "float64"
+	case reflect.String:
+		want = "string"
+	case reflect.Map:
+		want = "map[string]any"
+	case reflect.Slice, reflect.Array:
+		want = "[]any"
+	default:
+		return protoimpl.X.NewError("invalid type: %T", v)
+	}
+	return protoimpl.X.NewError("invalid type: %T (convert to %s or implement structpb.Valuer)", v, want)
+}
+
 // NewNullValue constructs a new null Value.
 func NewNullValue() *Value {
 	return &Value{Kind: &Value_NullValue{NullValue: NullValue_NULL_VALUE}}
@@ -444,6 +484,80 @@ func (x *Value) AsInterface() any {
 	return nil
 }
 
+// InterfaceOptions configures the conversion of Value, Struct, and ListValue
+// messages to general-purpose Go values.
+type InterfaceOptions struct {
+	// UseNumber specifies that numbers are converted to json.Number
+	// rather than float64.
+	UseNumber bool
+
+	// RejectNonFinite specifies that NaN and infinite numbers are reported
+	// as an error rather than converted to the strings "NaN", "Infinity",
+	// and "-Infinity".
+	RejectNonFinite bool
+}
+
+// AsInterface converts x to a general-purpose Go interface
+// using the options in o.
+func (o InterfaceOptions) AsInterface(x *Value) (any, error) {
+	switch v := x.GetKind().(type) {
+	case *Value_NumberValue:
+		if v == nil {
+			break
+		}
+		n := v.NumberValue
+		switch {
+		case math.IsNaN(n) || math.IsInf(n, 0):
+			if o.RejectNonFinite {
+				return nil, protoimpl.X.NewError("invalid number: %v", n)
+			}
+		case o.UseNumber:
+			// Format the number as ECMAScript does, which is also
+			// how it is formatted by MarshalJSON.
+			format := byte('f')
+			if abs := math.Abs(n); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+				format = 'e'
+			}
+			return json.Number(strconv.FormatFloat(n, format, -1, 64)), nil
+		}
+	case *Value_StructValue:
+		if v != nil {
+			return o.AsMap(v.StructValue)
+		}
+	case *Value_ListValue:
+		if v != nil {
+			return o.AsSlice(v.ListValue)
+		}
+	}
+	return x.AsInterface(), nil
+}
+
+// AsMap converts x to a general-purpose Go map using the options in o.
+func (o InterfaceOptions) AsMap(x *Struct) (map[string]any, error) {
+	f := x.GetFields()
+	vs := make(map[string]any, len(f))
+	for k, v := range f {
+		var err error
+		if vs[k], err = o.AsInterface(v); err != nil {
+			return nil, err
+		}
+	}
+	return vs, nil
+}
+
+// AsSlice converts x to a general-purpose Go slice using the options in o.
+func (o InterfaceOptions) AsSlice(x *ListValue) ([]any, error) {
+	vals := x.GetValues()
+	vs := make([]any, len(vals))
+	for i, v := range vals {
+		var err error
+		if vs[i], err = o.AsInterface(v); err != nil {
+			return nil, err
+		}
+	}
+	return vs, nil
+}
+
 func (x *Value) MarshalJSON() ([]byte, error) {
 	return protojson.Marshal(x)
 }
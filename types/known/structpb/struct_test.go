@@ -6,8 +6,11 @@ package structpb_test
 
 import (
 	"encoding/json"
+	"fmt"
 	"math"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -351,6 +354,12 @@ func TestFromListValue(t *testing.T) {
 	}
 }
 
+type point struct{ x, y int }
+
+func (p point) ToValue() (*spb.Value, error) {
+	return spb.NewStringValue(fmt.Sprintf("%d,%d", p.x, p.y)), nil
+}
+
 func TestToValue(t *testing.T) {
 	tests := []struct {
 		in      any
@@ -432,12 +441,29 @@ func TestToValue(t *testing.T) {
 			spb.NewStringValue("two"),
 			spb.NewStringValue("three"),
 		}}),
+	}, {
+		in:     time.Date(2025, 1, 2, 3, 4, 5, 6e8, time.UTC),
+		wantPB: spb.NewStringValue("2025-01-02T03:04:05.6Z"),
+	}, {
+		in:     point{1, 2},
+		wantPB: spb.NewStringValue("1,2"),
+	}, {
+		in: map[string]any{"p": point{3, 4}},
+		wantPB: spb.NewStructValue(&spb.Struct{Fields: map[string]*spb.Value{
+			"p": spb.NewStringValue("3,4"),
+		}}),
 	}, {
 		in:      "\xde\xad\xbe\xef",
 		wantErr: cmpopts.AnyError,
 	}, {
 		in:      protoreflect.Name("named string"),
 		wantErr: cmpopts.AnyError,
+	}, {
+		in:      map[string]string{"k": "v"},
+		wantErr: cmpopts.AnyError,
+	}, {
+		in:      struct{}{},
+		wantErr: cmpopts.AnyError,
 	}}
 
 	for _, tt := range tests {
@@ -451,6 +477,24 @@ func TestToValue(t *testing.T) {
 	}
 }
 
+func TestToValueErrors(t *testing.T) {
+	tests := []struct {
+		in   any
+		want string
+	}{
+		{protoreflect.Name("named string"), "invalid type: protoreflect.Name (convert to string or implement structpb.Valuer)"},
+		{time.Second, "invalid type: time.Duration (convert to float64 or implement structpb.Valuer)"},
+		{[]string{"a"}, "invalid type: []string (convert to []any or implement structpb.Valuer)"},
+		{struct{}{}, "invalid type: struct {}"},
+	}
+	for _, tt := range tests {
+		_, err := spb.NewValue(tt.in)
+		if err == nil || !strings.HasSuffix(err.Error(), tt.want) {
+			t.Errorf("NewValue(%v) error = %v, want %q", tt.in, err, tt.want)
+		}
+	}
+}
+
 func TestFromValue(t *testing.T) {
 	tests := []struct {
 		in   *spb.Value
@@ -573,3 +617,49 @@ func TestFromValue(t *testing.T) {
 		}
 	}
 }
+
+func TestInterfaceOptions(t *testing.T) {
+	in := spb.NewStructValue(&spb.Struct{Fields: map[string]*spb.Value{
+		"int":   spb.NewNumberValue(123),
+		"float": spb.NewNumberValue(0.5),
+		"large": spb.NewNumberValue(1e21),
+		"small": spb.NewNumberValue(-1e-7),
+		"list":  spb.NewListValue(&spb.ListValue{Values: []*spb.Value{spb.NewNumberValue(1), spb.NewStringValue("a")}}),
+		"null":  spb.NewNullValue(),
+	}})
+	got, err := spb.InterfaceOptions{UseNumber: true}.AsInterface(in)
+	if err != nil {
+		t.Fatalf("AsInterface() error: %v", err)
+	}
+	want := map[string]any{
+		"int":   json.Number("123"),
+		"float": json.Number("0.5"),
+		"large": json.Number("1e+21"),
+		"small": json.Number("-1e-07"),
+		"list":  []any{json.Number("1"), "a"},
+		"null":  nil,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("AsInterface() mismatch (-want +got):\n%s", diff)
+	}
+
+	nan := spb.NewListValue(&spb.ListValue{Values: []*spb.Value{spb.NewNumberValue(math.NaN())}})
+	got, err = spb.InterfaceOptions{UseNumber: true}.AsInterface(nan)
+	if err != nil {
+		t.Fatalf("AsInterface() error: %v", err)
+	}
+	if diff := cmp.Diff([]any{"NaN"}, got); diff != "" {
+		t.Errorf("AsInterface() mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := (spb.InterfaceOptions{RejectNonFinite: true}).AsInterface(nan); err == nil {
+		t.Errorf("AsInterface() with RejectNonFinite: want error, got nil")
+	}
+
+	gotMap, err := spb.InterfaceOptions{}.AsMap(in.GetStructValue())
+	if err != nil {
+		t.Fatalf("AsMap() error: %v", err)
+	}
+	if diff := cmp.Diff(in.GetStructValue().AsMap(), gotMap); diff != "" {
+		t.Errorf("AsMap() mismatch (-want +got):\n%s", diff)
+	}
+}
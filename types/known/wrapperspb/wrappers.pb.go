@@ -528,6 +528,73 @@ func (x *BytesValue) GetValue() []byte {
 	return nil
 }
 
+// Scalar is a constraint satisfied by the types of the values
+// held by the wrapper messages.
+type Scalar interface {
+	float64 | float32 | int64 | uint64 | int32 | uint32 | bool | string | []byte
+}
+
+// Message is a constraint satisfied by pointers to the wrapper messages
+// holding a value of type T.
+type Message[T Scalar] interface {
+	*DoubleValue | *FloatValue | *Int64Value | *UInt64Value | *Int32Value | *UInt32Value | *BoolValue | *StringValue | *BytesValue
+	GetValue() T
+}
+
+// New stores v in a new wrapper message of type M and returns it.
+// For example, New[*Int32Value](5) is equivalent to Int32(5).
+func New[M Message[T], T Scalar](v T) M {
+	var m any
+	switch v := any(v).(type) {
+	case float64:
+		m = Double(v)
+	case float32:
+		m = Float(v)
+	case int64:
+		m = Int64(v)
+	case uint64:
+		m = UInt64(v)
+	case int32:
+		m = Int32(v)
+	case uint32:
+		m = UInt32(v)
+	case bool:
+		m = Bool(v)
+	case string:
+		m = String(v)
+	case []byte:
+		m = Bytes(v)
+	}
+	return m.(M)
+}
+
+// FromPointer stores the value pointed to by p in a new wrapper message
+// of type M and returns it. It returns nil if p is nil.
+func FromPointer[M Message[T], T Scalar](p *T) M {
+	if p == nil {
+		return nil
+	}
+	return New[M](*p)
+}
+
+// GetOrDefault returns the value held by m, or def if m is nil.
+func GetOrDefault[M Message[T], T Scalar](m M, def T) T {
+	if m == nil {
+		return def
+	}
+	return m.GetValue()
+}
+
+// Pointer returns a pointer to a copy of the value held by m,
+// or nil if m is nil.
+func Pointer[M Message[T], T Scalar](m M) *T {
+	if m == nil {
+		return nil
+	}
+	v := m.GetValue()
+	return &v
+}
+
 var File_google_protobuf_wrappers_proto protoreflect.FileDescriptor
 
 var file_google_protobuf_wrappers_proto_rawDesc = string([]byte{
@@ -0,0 +1,64 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wrapperspb_test
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestNew(t *testing.T) {
+	for _, tt := range []struct {
+		got, want proto.Message
+	}{
+		{wrapperspb.New[*wrapperspb.DoubleValue](1.5), wrapperspb.Double(1.5)},
+		{wrapperspb.New[*wrapperspb.FloatValue](1.5), wrapperspb.Float(1.5)},
+		{wrapperspb.New[*wrapperspb.Int64Value](-1), wrapperspb.Int64(-1)},
+		{wrapperspb.New[*wrapperspb.UInt64Value](1), wrapperspb.UInt64(1)},
+		{wrapperspb.New[*wrapperspb.Int32Value](-1), wrapperspb.Int32(-1)},
+		{wrapperspb.New[*wrapperspb.UInt32Value](1), wrapperspb.UInt32(1)},
+		{wrapperspb.New[*wrapperspb.BoolValue](true), wrapperspb.Bool(true)},
+		{wrapperspb.New[*wrapperspb.StringValue]("a"), wrapperspb.String("a")},
+		{wrapperspb.New[*wrapperspb.BytesValue]([]byte("a")), wrapperspb.Bytes([]byte("a"))},
+	} {
+		if !proto.Equal(tt.got, tt.want) {
+			t.Errorf("New() = %v, want %v", tt.got, tt.want)
+		}
+	}
+}
+
+func TestGetOrDefault(t *testing.T) {
+	var nilInt32 *wrapperspb.Int32Value
+	if got := wrapperspb.GetOrDefault(nilInt32, 5); got != 5 {
+		t.Errorf("GetOrDefault(nil, 5) = %v, want 5", got)
+	}
+	if got := wrapperspb.GetOrDefault(wrapperspb.Int32(0), 5); got != 0 {
+		t.Errorf("GetOrDefault(Int32(0), 5) = %v, want 0", got)
+	}
+	if got := wrapperspb.GetOrDefault(wrapperspb.String("a"), "b"); got != "a" {
+		t.Errorf("GetOrDefault(String(\"a\"), \"b\") = %q, want \"a\"", got)
+	}
+}
+
+func TestPointer(t *testing.T) {
+	var nilBool *wrapperspb.BoolValue
+	if got := wrapperspb.Pointer(nilBool); got != nil {
+		t.Errorf("Pointer(nil) = %v, want nil", *got)
+	}
+	if got := wrapperspb.Pointer(wrapperspb.UInt64(7)); got == nil || *got != 7 {
+		t.Errorf("Pointer(UInt64(7)) = %v, want pointer to 7", got)
+	}
+
+	if got := wrapperspb.FromPointer[*wrapperspb.StringValue](nil); got != nil {
+		t.Errorf("FromPointer(nil) = %v, want nil", got)
+	}
+	s := "a"
+	if got := wrapperspb.FromPointer[*wrapperspb.StringValue](&s); !proto.Equal(got, wrapperspb.String("a")) {
+		t.Errorf("FromPointer(&%q) = %v, want %v", s, got, wrapperspb.String("a"))
+	}
+}